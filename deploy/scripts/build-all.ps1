@@ -33,7 +33,7 @@ if (Test-Path $DIST_DIR) {
 New-Item -ItemType Directory -Path $DIST_DIR | Out-Null
 
 # Build flags
-$LDFLAGS = "-s -w -X main.Version=$VERSION -X main.BuildTime=$BUILD_TIME -X main.GitCommit=$GIT_COMMIT"
+$LDFLAGS = "-s -w -X boba/internal/version.Version=$VERSION -X boba/internal/version.BuildTime=$BUILD_TIME -X boba/internal/version.GitCommit=$GIT_COMMIT"
 
 # Platforms to build
 $PLATFORMS = @{
@@ -27,7 +27,7 @@ rm -rf "${DIST_DIR}"
 mkdir -p "${DIST_DIR}"
 
 # Build flags
-LDFLAGS="-s -w -X main.Version=${VERSION} -X main.BuildTime=${BUILD_TIME} -X main.GitCommit=${GIT_COMMIT}"
+LDFLAGS="-s -w -X boba/internal/version.Version=${VERSION} -X boba/internal/version.BuildTime=${BUILD_TIME} -X boba/internal/version.GitCommit=${GIT_COMMIT}"
 
 # Platforms to build
 declare -A PLATFORMS=(
@@ -0,0 +1,249 @@
+// Package advisory looks up security advisories for installed tools in the OSV database (https://osv.dev).
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+// DefaultOSVURL is the public OSV API
+const DefaultOSVURL = "https://api.osv.dev"
+
+// Query asks for the advisories affecting one installed tool
+type Query struct {
+	Tool      string
+	Ecosystem string
+	Package   string
+	Version   string
+}
+
+// Advisory is a vulnerability affecting an installed tool
+type Advisory struct {
+	ID       string
+	Summary  string
+	Aliases  []string // Other identifiers, such as CVE IDs
+	Severity string   // CVSS vector or score when published
+	Fixed    []string // Versions fixing the vulnerability
+	URL      string
+}
+
+// Client queries the OSV API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the public OSV API
+func NewClient() *Client {
+	return &Client{BaseURL: DefaultOSVURL, HTTPClient: &http.Client{Timeout: 20 * time.Second}}
+}
+
+// packageManagerEcosystems maps package managers recorded in install provenance to OSV ecosystems.
+// System package managers are left out: their ecosystems need the distribution release.
+var packageManagerEcosystems = map[string]string{
+	"npm":   "npm",
+	"pip":   "PyPI",
+	"pip3":  "PyPI",
+	"pipx":  "PyPI",
+	"cargo": "crates.io",
+	"gem":   "RubyGems",
+	"go":    "Go",
+}
+
+// QueriesFor builds the queries for the installed tools with a known version and ecosystem: the
+// tool's advisory entry, or the package manager that installed it
+func QueriesFor(installed map[string]config.InstalledTool, tools []parser.Tool) []Query {
+	packages := make(map[string]*parser.AdvisoryPackage)
+	for _, tool := range tools {
+		if tool.Advisory != nil {
+			packages[tool.Name] = tool.Advisory
+		}
+	}
+	
+	var queries []Query
+	for name, record := range installed {
+		version := strings.TrimPrefix(record.Version, "v")
+		if version == "" || version == "latest" {
+			continue
+		}
+		
+		query := Query{Tool: name, Package: name, Version: version}
+		if pkg, ok := packages[name]; ok {
+			query.Ecosystem = pkg.Ecosystem
+			if pkg.Package != "" {
+				query.Package = pkg.Package
+			}
+		} else if record.Provenance != nil {
+			query.Ecosystem = packageManagerEcosystems[record.Provenance.PackageManager]
+		}
+		if query.Ecosystem == "" {
+			continue
+		}
+		queries = append(queries, query)
+	}
+	
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Tool < queries[j].Tool })
+	return queries
+}
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+type osvVulnerability struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package osvPackage `json:"package"`
+		Ranges  []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+// Check returns the advisories affecting each queried tool, by tool name
+func (c *Client) Check(ctx context.Context, queries []Query) (map[string][]Advisory, error) {
+	results := make(map[string][]Advisory)
+	if len(queries) == 0 {
+		return results, nil
+	}
+	
+	batch := struct {
+		Queries []osvQuery `json:"queries"`
+	}{}
+	for _, query := range queries {
+		batch.Queries = append(batch.Queries, osvQuery{
+			Package: osvPackage{Name: query.Package, Ecosystem: query.Ecosystem},
+			Version: query.Version,
+		})
+	}
+	
+	var response osvBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/querybatch", batch, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(queries) {
+		return nil, fmt.Errorf("OSV returned %d results for %d queries", len(response.Results), len(queries))
+	}
+	
+	// The batch API only returns IDs: fetch each vulnerability once for its details
+	details := make(map[string]Advisory)
+	for i, result := range response.Results {
+		for _, vuln := range result.Vulns {
+			advisory, ok := details[vuln.ID]
+			if !ok {
+				fetched, err := c.vulnerability(ctx, vuln.ID, queries[i])
+				if err != nil {
+					return nil, err
+				}
+				advisory = fetched
+				details[vuln.ID] = advisory
+			}
+			results[queries[i].Tool] = append(results[queries[i].Tool], advisory)
+		}
+	}
+	return results, nil
+}
+
+// vulnerability fetches the details of one advisory
+func (c *Client) vulnerability(ctx context.Context, id string, query Query) (Advisory, error) {
+	var vuln osvVulnerability
+	if err := c.do(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, &vuln); err != nil {
+		return Advisory{}, err
+	}
+	
+	advisory := Advisory{
+		ID:      vuln.ID,
+		Summary: vuln.Summary,
+		Aliases: vuln.Aliases,
+		URL:     "https://osv.dev/vulnerability/" + vuln.ID,
+	}
+	if advisory.Summary == "" {
+		advisory.Summary = strings.SplitN(strings.TrimSpace(vuln.Details), "\n", 2)[0]
+	}
+	if len(vuln.Severity) > 0 {
+		advisory.Severity = vuln.Severity[0].Score
+	}
+	for _, affected := range vuln.Affected {
+		if affected.Package.Name != query.Package {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					advisory.Fixed = append(advisory.Fixed, event.Fixed)
+				}
+			}
+		}
+	}
+	return advisory, nil
+}
+
+// do sends a JSON request to the OSV API and decodes the JSON response
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	
+	var request *http.Request
+	var err error
+	if reader != nil {
+		request, err = http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	} else {
+		request, err = http.NewRequestWithContext(ctx, method, c.BaseURL+path, nil)
+	}
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to query OSV: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("OSV returned %s for %s", response.Status, path)
+	}
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode OSV response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+func TestQueriesFor(t *testing.T) {
+	installed := map[string]config.InstalledTool{
+		"prettier": {Name: "prettier", Version: "3.0.0", Provenance: &config.ToolProvenance{PackageManager: "npm"}},
+		"ripgrep":  {Name: "ripgrep", Version: "v13.0.0"},
+		"neovim":   {Name: "neovim", Version: "latest", Provenance: &config.ToolProvenance{PackageManager: "npm"}},
+		"htop":     {Name: "htop", Version: "3.2.0", Provenance: &config.ToolProvenance{PackageManager: "apt"}},
+	}
+	tools := []parser.Tool{
+		{Name: "ripgrep", Advisory: &parser.AdvisoryPackage{Ecosystem: "crates.io", Package: "grep"}},
+	}
+	
+	queries := QueriesFor(installed, tools)
+	if len(queries) != 2 {
+		t.Fatalf("Expected queries for prettier and ripgrep only, got %+v", queries)
+	}
+	if queries[0] != (Query{Tool: "prettier", Ecosystem: "npm", Package: "prettier", Version: "3.0.0"}) {
+		t.Errorf("Expected the ecosystem of the package manager, got %+v", queries[0])
+	}
+	if queries[1] != (Query{Tool: "ripgrep", Ecosystem: "crates.io", Package: "grep", Version: "13.0.0"}) {
+		t.Errorf("Expected the tool's advisory entry, got %+v", queries[1])
+	}
+}
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var batch struct {
+				Queries []osvQuery `json:"queries"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil || len(batch.Queries) != 2 {
+				http.Error(w, "bad batch", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-1"}]},{}]}`))
+		case "/v1/vulns/GHSA-1":
+			w.Write([]byte(`{"id":"GHSA-1","summary":"Code execution","aliases":["CVE-2024-0001"],
+				"affected":[{"package":{"name":"prettier","ecosystem":"npm"},"ranges":[{"events":[{"introduced":"0"},{"fixed":"3.0.1"}]}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	advisories, err := client.Check(context.Background(), []Query{
+		{Tool: "prettier", Ecosystem: "npm", Package: "prettier", Version: "3.0.0"},
+		{Tool: "eslint", Ecosystem: "npm", Package: "eslint", Version: "9.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(advisories) != 1 || len(advisories["prettier"]) != 1 {
+		t.Fatalf("Expected one advisory for prettier, got %+v", advisories)
+	}
+	adv := advisories["prettier"][0]
+	if adv.Summary != "Code execution" || adv.Aliases[0] != "CVE-2024-0001" || len(adv.Fixed) != 1 || adv.Fixed[0] != "3.0.1" {
+		t.Errorf("Unexpected advisory details: %+v", adv)
+	}
+	
+	server.Close()
+	if _, err := client.Check(context.Background(), []Query{{Tool: "x", Ecosystem: "npm", Package: "x", Version: "1"}}); err == nil {
+		t.Error("Expected an unreachable OSV API to be reported")
+	}
+}
@@ -0,0 +1,139 @@
+// Package bootstrap checks on startup for the commands everything else depends on (git, curl,
+// bash and Homebrew on macOS) and offers to install the missing ones before the UI starts,
+// while sudo can still ask for a password on the terminal.
+package bootstrap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+)
+
+// Offer lists the missing prerequisites and installs them if the user agrees. It returns
+// once BOBA can continue, whatever the outcome: the UI reports what still fails.
+func Offer(stdin io.Reader, stdout, stderr io.Writer) {
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	engine := installer.NewInstallationEngine(nil)
+	defer engine.Cleanup()
+	engine.ApplySettings(configManager)
+	
+	missing := engine.MissingPrerequisites()
+	if len(missing) == 0 {
+		return
+	}
+	input := bufio.NewReader(stdin)
+	
+	// Homebrew installs the other prerequisites on macOS, so it comes first
+	if missing[0].Command == "brew" {
+		installHomebrew(engine, configManager, input, stdin, stdout, stderr)
+		if missing = engine.MissingPrerequisites(); len(missing) == 0 {
+			return
+		}
+	}
+	
+	commands, err := engine.BootstrapCommands(missing)
+	offer(missing, commands, err, input, stdout, stderr, func() []installer.Prerequisite {
+		if err := installer.RunBootstrap(commands, stdin, stdout, stderr); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+		}
+		return engine.MissingPrerequisites()
+	})
+}
+
+// installHomebrew downloads the Homebrew installer and runs it once its checksum is verified
+// against the pinned one, or reviewed and pinned by the user
+func installHomebrew(engine *installer.InstallationEngine, configManager *config.ConfigManager, input *bufio.Reader, stdin io.Reader, stdout, stderr io.Writer) {
+	fmt.Fprintf(stdout, "Homebrew is not installed. Downloading its installer from %s...\n", installer.HomebrewInstallerURL)
+	homebrew, err := installer.DownloadHomebrewInstaller(installer.HomebrewInstallerURL)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return
+	}
+	
+	pinned := configManager.GetConfig().HomebrewInstallerSHA256
+	checksum, ok := confirmHomebrew(homebrew, pinned, input, stdout)
+	if !ok {
+		return
+	}
+	if checksum != pinned {
+		if err := configManager.PinHomebrewInstaller(checksum); err != nil {
+			fmt.Fprintf(stderr, "Warning: failed to pin the Homebrew installer: %v\n", err)
+		}
+	}
+	if err := engine.InstallHomebrew(homebrew, checksum, stdin, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(stdout, "✓ Homebrew installed")
+}
+
+// confirmHomebrew asks before running the installer and returns the checksum to verify it
+// against: the pinned one, or the one the user reviewed when none is pinned yet
+func confirmHomebrew(homebrew *installer.HomebrewInstaller, pinned string, input *bufio.Reader, stdout io.Writer) (string, bool) {
+	if pinned != "" {
+		if err := homebrew.Verify(pinned); err != nil {
+			fmt.Fprintf(stdout, "%v\nThe installer changed since it was pinned: review it, then update homebrew_installer_sha256 in config.json.\n", err)
+			return "", false
+		}
+		fmt.Fprint(stdout, "✓ The installer matches the pinned checksum. Install Homebrew now? [Y/n] ")
+		answer, _ := input.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return pinned, answer == "" || answer == "y" || answer == "yes"
+	}
+	
+	fmt.Fprintf(stdout, "SHA-256 of the installer: %s\n", homebrew.Checksum)
+	fmt.Fprintf(stdout, "No checksum is pinned yet: review %s, then pin this one and install Homebrew? [y/N] ", homebrew.URL)
+	answer, _ := input.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return homebrew.Checksum, answer == "y" || answer == "yes"
+}
+
+// offer runs the guided step: install runs the commands and returns what is still missing
+func offer(missing []installer.Prerequisite, commands [][]string, commandsErr error, input *bufio.Reader, stdout, stderr io.Writer, install func() []installer.Prerequisite) {
+	fmt.Fprintln(stdout, "BOBA needs a few commands that are missing on this machine:")
+	for _, prerequisite := range missing {
+		fmt.Fprintf(stdout, "  ✗ %s: %s\n", prerequisite.Command, prerequisite.Reason)
+	}
+	
+	if commandsErr != nil {
+		fmt.Fprintf(stdout, "\n%v\n", commandsErr)
+		fmt.Fprint(stdout, "Press Enter to continue without them...")
+		input.ReadString('\n')
+		return
+	}
+	
+	fmt.Fprintf(stdout, "\nBOBA can install them first with:\n  %s\n", commandLine(commands))
+	fmt.Fprint(stdout, "Install them now? [Y/n] ")
+	answer, _ := input.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(answer)); answer != "" && answer != "y" && answer != "yes" {
+		fmt.Fprintln(stdout, "Continuing without them: run boba doctor to see what fails.")
+		return
+	}
+	
+	stillMissing := install()
+	if len(stillMissing) == 0 {
+		fmt.Fprintln(stdout, "✓ Prerequisites installed")
+		return
+	}
+	var names []string
+	for _, prerequisite := range stillMissing {
+		names = append(names, prerequisite.Command)
+	}
+	fmt.Fprintf(stderr, "Still missing: %s. Run boba doctor for the manual steps.\n", strings.Join(names, ", "))
+	fmt.Fprint(stdout, "Press Enter to continue...")
+	input.ReadString('\n')
+}
+
+// commandLine joins the commands as they would be typed in a shell
+func commandLine(commands [][]string) string {
+	var lines []string
+	for _, command := range commands {
+		lines = append(lines, strings.Join(command, " "))
+	}
+	return strings.Join(lines, " && ")
+}
@@ -0,0 +1,68 @@
+package bootstrap
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"boba/internal/installer"
+)
+
+func TestOffer(t *testing.T) {
+	missing := []installer.Prerequisite{{Command: "git", Package: "git", Reason: "clones the repository"}}
+	commands := [][]string{{"sudo", "apt-get", "install", "-y", "git"}}
+	
+	var stdout, stderr bytes.Buffer
+	installed := false
+	offer(missing, commands, nil, bufio.NewReader(strings.NewReader("\n")), &stdout, &stderr, func() []installer.Prerequisite {
+		installed = true
+		return nil
+	})
+	if !installed || !strings.Contains(stdout.String(), "sudo apt-get install -y git") || !strings.Contains(stdout.String(), "✓ Prerequisites installed") {
+		t.Errorf("Expected the default answer to install, got %q", stdout.String())
+	}
+	
+	stdout.Reset()
+	installed = false
+	offer(missing, commands, nil, bufio.NewReader(strings.NewReader("n\n")), &stdout, &stderr, func() []installer.Prerequisite {
+		installed = true
+		return nil
+	})
+	if installed || !strings.Contains(stdout.String(), "boba doctor") {
+		t.Errorf("Expected declining to continue without installing, got %q", stdout.String())
+	}
+	
+	// Nothing to run: the manual steps are shown instead of a prompt
+	stdout.Reset()
+	offer(missing, nil, errors.New("install Homebrew from https://brew.sh"), bufio.NewReader(strings.NewReader("\n")), &stdout, &stderr, func() []installer.Prerequisite {
+		t.Error("Expected nothing to be installed")
+		return nil
+	})
+	if !strings.Contains(stdout.String(), "brew.sh") || strings.Contains(stdout.String(), "[Y/n]") {
+		t.Errorf("Expected the manual steps without a prompt, got %q", stdout.String())
+	}
+}
+
+func TestConfirmHomebrew(t *testing.T) {
+	homebrew := &installer.HomebrewInstaller{URL: "https://example.com/install.sh", Checksum: "abc123"}
+	var stdout bytes.Buffer
+	
+	// Nothing pinned: the checksum is shown and must be accepted explicitly
+	if _, ok := confirmHomebrew(homebrew, "", bufio.NewReader(strings.NewReader("\n")), &stdout); ok {
+		t.Error("Expected an unpinned installer to need an explicit yes")
+	}
+	if checksum, ok := confirmHomebrew(homebrew, "", bufio.NewReader(strings.NewReader("y\n")), &stdout); !ok || checksum != "abc123" || !strings.Contains(stdout.String(), "abc123") {
+		t.Errorf("Expected the reviewed checksum to be accepted, got %q, %v: %s", checksum, ok, stdout.String())
+	}
+	
+	// Pinned: a matching installer runs by default, a changed one never does
+	if checksum, ok := confirmHomebrew(homebrew, "abc123", bufio.NewReader(strings.NewReader("\n")), &stdout); !ok || checksum != "abc123" {
+		t.Errorf("Expected the pinned installer to be accepted, got %q, %v", checksum, ok)
+	}
+	stdout.Reset()
+	if _, ok := confirmHomebrew(homebrew, "def456", bufio.NewReader(strings.NewReader("y\n")), &stdout); ok || !strings.Contains(stdout.String(), "homebrew_installer_sha256") {
+		t.Errorf("Expected a changed installer to be refused, got %v: %s", ok, stdout.String())
+	}
+}
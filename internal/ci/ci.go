@@ -0,0 +1,112 @@
+// Package ci detects the continuous integration service BOBA runs under. In CI there is no one
+// to answer prompts and no terminal for the UI, so BOBA runs non-interactively, and the output
+// of each tool and environment is wrapped in the service's collapsible log sections.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// GitHubActions is the name of the GitHub Actions provider
+const GitHubActions = "GitHub Actions"
+
+// Provider is a CI service, with how it marks collapsible log sections
+type Provider struct {
+	Name       string
+	groupStart func(id, title string) string // Line opening a section, empty when the service has none
+	groupEnd   func(id, title string) string // Line closing a section, empty when sections close by themselves
+}
+
+// providers are checked in order: the generic CI variable last, since most services set it too
+var providers = []struct {
+	env, value string // Variable the service sets, and its value (any value when empty)
+	provider   Provider
+}{
+	{"GITHUB_ACTIONS", "true", Provider{
+		Name:       GitHubActions,
+		groupStart: func(_, title string) string { return "::group::" + title },
+		groupEnd:   func(_, _ string) string { return "::endgroup::" },
+	}},
+	{"GITLAB_CI", "true", Provider{
+		Name: "GitLab CI",
+		groupStart: func(id, title string) string {
+			return fmt.Sprintf("\x1b[0Ksection_start:%d:%s[collapsed=true]\r\x1b[0K%s", time.Now().Unix(), id, title)
+		},
+		groupEnd: func(id, _ string) string {
+			return fmt.Sprintf("\x1b[0Ksection_end:%d:%s\r\x1b[0K", time.Now().Unix(), id)
+		},
+	}},
+	{"TF_BUILD", "", Provider{
+		Name:       "Azure Pipelines",
+		groupStart: func(_, title string) string { return "##[group]" + title },
+		groupEnd:   func(_, _ string) string { return "##[endgroup]" },
+	}},
+	{"BUILDKITE", "true", Provider{
+		Name:       "Buildkite",
+		groupStart: func(_, title string) string { return "--- " + title },
+	}},
+	{"TRAVIS", "true", Provider{
+		Name:       "Travis CI",
+		groupStart: func(id, title string) string { return "travis_fold:start:" + id + "\r\x1b[0K" + title },
+		groupEnd:   func(id, _ string) string { return "travis_fold:end:" + id + "\r\x1b[0K" },
+	}},
+	{"TEAMCITY_VERSION", "", Provider{
+		Name:       "TeamCity",
+		groupStart: func(_, title string) string { return fmt.Sprintf("##teamcity[blockOpened name='%s']", teamCityEscape(title)) },
+		groupEnd:   func(_, title string) string { return fmt.Sprintf("##teamcity[blockClosed name='%s']", teamCityEscape(title)) },
+	}},
+	{"CIRCLECI", "true", Provider{Name: "CircleCI"}},
+	{"JENKINS_URL", "", Provider{Name: "Jenkins"}},
+	{"CI", "true", Provider{Name: "CI"}},
+}
+
+// Detect returns the CI service BOBA runs under, or nil outside CI
+func Detect() *Provider {
+	for _, candidate := range providers {
+		value := os.Getenv(candidate.env)
+		if value == "" || (candidate.value != "" && !strings.EqualFold(value, candidate.value)) {
+			continue
+		}
+		provider := candidate.provider
+		return &provider
+	}
+	return nil
+}
+
+// BeginGroup opens a collapsible log section and returns the function closing it. Outside CI
+// (a nil provider), or when the service has no sections, it writes nothing.
+func (p *Provider) BeginGroup(w io.Writer, title string) func() {
+	if p == nil || p.groupStart == nil {
+		return func() {}
+	}
+	id := sectionID(title)
+	fmt.Fprintln(w, p.groupStart(id, title))
+	return func() {
+		if p.groupEnd != nil {
+			fmt.Fprintln(w, p.groupEnd(id, title))
+		}
+	}
+}
+
+// sectionID turns a title into the identifier the services accept: lowercase letters, digits
+// and underscores
+func sectionID(title string) string {
+	var id strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			id.WriteRune(r)
+		} else {
+			id.WriteRune('_')
+		}
+	}
+	return "boba_" + id.String()
+}
+
+// teamCityEscape escapes the characters TeamCity service messages reserve
+func teamCityEscape(value string) string {
+	return strings.NewReplacer("|", "||", "'", "|'", "[", "|[", "]", "|]", "\n", "|n", "\r", "|r").Replace(value)
+}
@@ -0,0 +1,93 @@
+package ci
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearProviders unsets the variables of every service for the test
+func clearProviders(t *testing.T) {
+	for _, candidate := range providers {
+		t.Setenv(candidate.env, "")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	clearProviders(t)
+	if provider := Detect(); provider != nil {
+		t.Fatalf("Expected no CI, got %s", provider.Name)
+	}
+	
+	// Nothing is written outside CI
+	var out bytes.Buffer
+	Detect().BeginGroup(&out, "Installing git")()
+	if out.Len() != 0 {
+		t.Errorf("Expected no markers outside CI, got %q", out.String())
+	}
+	
+	t.Setenv("CI", "true")
+	if provider := Detect(); provider == nil || provider.Name != "CI" {
+		t.Errorf("Expected generic CI, got %+v", provider)
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+	provider := Detect()
+	if provider == nil || provider.Name != "GitHub Actions" {
+		t.Fatalf("Expected GitHub Actions to take precedence, got %+v", provider)
+	}
+	end := provider.BeginGroup(&out, "Installing git")
+	out.WriteString("output\n")
+	end()
+	if out.String() != "::group::Installing git\noutput\n::endgroup::\n" {
+		t.Errorf("Unexpected GitHub Actions markers: %q", out.String())
+	}
+}
+
+func TestGitLabSections(t *testing.T) {
+	clearProviders(t)
+	t.Setenv("GITLAB_CI", "true")
+	var out bytes.Buffer
+	Detect().BeginGroup(&out, "Applying zsh-dev")()
+	if !strings.Contains(out.String(), "section_start:") || !strings.Contains(out.String(), ":boba_applying_zsh_dev[collapsed=true]") ||
+		!strings.Contains(out.String(), "section_end:") {
+		t.Errorf("Unexpected GitLab sections: %q", out.String())
+	}
+}
+
+func TestGitHubActionsCommands(t *testing.T) {
+	clearProviders(t)
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv(StepSummaryEnv, summary)
+	t.Setenv("RUNNER_TEMP", t.TempDir())
+	
+	// Other providers get no workflow commands
+	t.Setenv("GITLAB_CI", "true")
+	var out bytes.Buffer
+	remove, err := Detect().AddProblemMatcher(&out)
+	remove()
+	if err != nil || out.Len() != 0 {
+		t.Errorf("Expected no problem matcher outside GitHub Actions, got %v: %q", err, out.String())
+	}
+	
+	t.Setenv("GITHUB_ACTIONS", "true")
+	remove, err = Detect().AddProblemMatcher(&out)
+	if err != nil {
+		t.Fatalf("AddProblemMatcher failed: %v", err)
+	}
+	path := strings.TrimSpace(strings.TrimPrefix(out.String(), "::add-matcher::"))
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"owner": "boba"`) {
+		t.Errorf("Expected the matcher file to be registered, got %q: %v", out.String(), err)
+	}
+	remove()
+	if !strings.HasSuffix(out.String(), "::remove-matcher owner=boba::\n") {
+		t.Errorf("Expected the matcher to be removed, got %q", out.String())
+	}
+	
+	AppendStepSummary([]byte("first\n"))
+	AppendStepSummary([]byte("second\n"))
+	if data, _ := os.ReadFile(summary); string(data) != "first\nsecond\n" {
+		t.Errorf("Expected the summary to be appended to, got %q", data)
+	}
+}
@@ -0,0 +1,77 @@
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// StepSummaryEnv names the file GitHub Actions renders as Markdown on the workflow run page
+const StepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+// problemMatcherOwner identifies BOBA's problem matcher, so it can be removed again
+const problemMatcherOwner = "boba"
+
+// problemMatcher turns the failure lines BOBA prints ("✗ node failed" and the error on the next
+// line) into annotations on the workflow run
+const problemMatcher = `{
+  "problemMatcher": [
+    {
+      "owner": "` + problemMatcherOwner + `",
+      "severity": "error",
+      "pattern": [
+        {"regexp": "^✗ (.+) failed$", "code": 1},
+        {"regexp": "^(.+)$", "message": 1}
+      ]
+    }
+  ]
+}
+`
+
+// IsGitHubActions reports whether the provider is GitHub Actions, which understands workflow
+// commands and step summaries
+func (p *Provider) IsGitHubActions() bool {
+	return p != nil && p.Name == GitHubActions
+}
+
+// AddProblemMatcher registers BOBA's problem matcher with GitHub Actions and returns the
+// function removing it. Other providers have no problem matchers, so nothing is written.
+func (p *Provider) AddProblemMatcher(w io.Writer) (func(), error) {
+	if !p.IsGitHubActions() {
+		return func() {}, nil
+	}
+	
+	// The runner reads the matcher from a file, kept in the job's temporary directory
+	dir := os.Getenv("RUNNER_TEMP")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	path := filepath.Join(dir, "boba-problem-matcher.json")
+	if err := os.WriteFile(path, []byte(problemMatcher), 0644); err != nil {
+		return func() {}, fmt.Errorf("failed to write problem matcher: %w", err)
+	}
+	fmt.Fprintf(w, "::add-matcher::%s\n", path)
+	return func() {
+		fmt.Fprintf(w, "::remove-matcher owner=%s::\n", problemMatcherOwner)
+		os.Remove(path)
+	}, nil
+}
+
+// AppendStepSummary adds Markdown to the GitHub Actions step summary. Outside GitHub Actions,
+// where the summary file isn't set, it does nothing.
+func AppendStepSummary(markdown []byte) error {
+	path := os.Getenv(StepSummaryEnv)
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the step summary: %w", err)
+	}
+	if _, err := file.Write(markdown); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the step summary: %w", err)
+	}
+	return file.Close()
+}
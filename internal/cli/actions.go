@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"boba/internal/ci"
+	"boba/internal/config"
+)
+
+// reportToActions runs a command and, under GitHub Actions, turns its failures into annotations
+// with a problem matcher and adds its results to the step summary. Workflow commands go to out.
+func (w *workspace) reportToActions(out, stderr io.Writer, run func() int) int {
+	provider := ci.Detect()
+	if !provider.IsGitHubActions() {
+		return run()
+	}
+	if w.report == nil {
+		w.report = &config.RunReport{}
+	}
+	removeMatcher, err := provider.AddProblemMatcher(out)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	code := run()
+	removeMatcher()
+	
+	if w.report.FinishedAt.IsZero() {
+		w.report.FinishedAt = time.Now()
+	}
+	var summary bytes.Buffer
+	config.WriteMarkdown(&summary, *w.report)
+	if err := ci.AppendStepSummary(summary.Bytes()); err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	return code
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// approvalRequiredMessage refuses a run on a machine that only runs approved plans, telling how to
+// run one
+const approvalRequiredMessage = "Error: this machine only runs plans approved by an admin: export one with boba plan export --output boba-plan.yaml, have an admin approve it with boba plan approve, then run boba apply boba-plan.yaml"
+
+// Apply implements `boba apply [--junit file] <plan file>` and returns the exit code. It runs a
+// plan exported with boba plan export or from Install Everything exactly as written, without the
+// terminal UI: in CI, cloud-init and on managed machines, which only run approved plans.
+func Apply(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	junit := flags.String("junit", "", "write the results as JUnit XML to this `file`")
+	refreshIndex := flags.Bool("refresh-index", true, "refresh the system package index once before installing")
+	edited := flags.String("edited", "", "settle home files edited since they were last applied: mine, repo or merge")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba apply [--junit file] [--refresh-index=false] [--edited mine|repo|merge] <plan file>")
+		fmt.Fprintln(stderr, "Runs a plan exported with boba plan export or Install Everything → Export Plan for Review, exactly as written.")
+		fmt.Fprintln(stderr, "The run is refused when the plan was edited, the repository changed since the export, or the")
+		fmt.Fprintln(stderr, "machine only runs approved plans and the plan is not approved.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	if err := validEditResolution(*edited); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Usage
+	}
+
+	// A plan edited after its export is refused before anything connects
+	plan, err := config.ReadPlanFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+
+	ws.report = &config.RunReport{}
+	code := ws.reportToActions(stdout, stderr, func() int {
+		return ws.applyPlan(plan, *refreshIndex, *edited, stdout, stderr)
+	})
+	ws.report.FinishedAt = time.Now()
+	if len(ws.report.Results) == 0 || ws.engine.DryRun() {
+		return code
+	}
+	ws.configManager.SaveRunReport(*ws.report)
+
+	junitPath := *junit
+	if junitPath == "" {
+		junitPath = ws.configManager.GetJUnitReportPath()
+	}
+	if junitPath != "" {
+		if err := config.WriteJUnitFile(junitPath, *ws.report); err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+		}
+	}
+	return code
+}
+
+// applyPlan runs the tools and environments of a plan in its order. The operations must be
+// the ones the plan was exported with, and approved when the machine only runs approved plans.
+func (w *workspace) applyPlan(plan config.ExecutionPlan, refreshIndex bool, edited string, stdout, stderr io.Writer) int {
+	w.engine.BeginRun()
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	environments, err := w.repoParser.GetEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+
+	planTools, planEnvironments, err := w.engine.ResolvePlan(plan, tools, environments)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.engine.ApprovePlan(plan); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.checkFileConflicts(planEnvironments); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.checkEditedFiles(planEnvironments, edited); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if len(planEnvironments) > 0 {
+		w.saveRestorePoint("Before applying a plan", planEnvironments, stderr)
+	}
+	return w.runAll("Apply", planTools, planEnvironments, 0, refreshIndex, stdout, stderr)
+}
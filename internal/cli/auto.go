@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"boba/internal/ci"
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// Environment variables of the automatic mode, which provisions a container without the UI
+const (
+	AutoEnv         = "BOBA_AUTO"         // 1 runs the automatic mode anywhere, 0 never runs it
+	ToolsEnv        = "BOBA_TOOLS"        // Comma-separated tools to install instead of Install Everything
+	EnvironmentsEnv = "BOBA_ENVIRONMENTS" // Comma-separated environments to apply instead of Install Everything
+)
+
+// AutoReport is the JSON document the automatic mode prints to stdout
+type AutoReport struct {
+	Profile      string             `json:"profile"` // "everything", or "selection" for BOBA_TOOLS/BOBA_ENVIRONMENTS
+	Tools        []string           `json:"tools,omitempty"`
+	Environments []string           `json:"environments,omitempty"`
+	ExitCode     int                `json:"exit_code"`
+	Error        string             `json:"error,omitempty"`
+	FinishedAt   time.Time          `json:"finished_at"`
+	Results      []config.RunResult `json:"results"`
+}
+
+// AutoMode reports whether `boba` without a command runs the automatic mode instead of the
+// UI: when BOBA_AUTO=1, in CI, or in a container without a terminal, unless BOBA_AUTO=0
+func AutoMode(terminal bool) bool {
+	switch os.Getenv(AutoEnv) {
+	case "1", "true":
+		return true
+	case "0", "false":
+		return false
+	}
+	return ci.Detect() != nil || (!terminal && config.IsContainer())
+}
+
+// Auto runs the profile selected by the environment, Install Everything by default, reading the
+// token and repository from BOBA_GITHUB_TOKEN and BOBA_REPO when set. Progress goes to stderr
+// and the results to stdout as an AutoReport.
+func Auto(stdout, stderr io.Writer) int {
+	report := AutoReport{
+		Profile:      "everything",
+		Tools:        splitList(os.Getenv(ToolsEnv)),
+		Environments: splitList(os.Getenv(EnvironmentsEnv)),
+		Results:      []config.RunResult{},
+	}
+	if len(report.Tools) > 0 || len(report.Environments) > 0 {
+		report.Profile = "selection"
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		report.ExitCode = workspaceExitCode(err)
+		report.Error = err.Error()
+		return writeAutoReport(report, stdout, stderr)
+	}
+	defer ws.close()
+	
+	// stdout only carries the JSON document, so workflow commands go to stderr with the progress
+	runReport := &config.RunReport{}
+	ws.report = runReport
+	report.ExitCode = ws.reportToActions(stderr, stderr, func() int {
+		if report.Profile == "everything" {
+			return ws.installAll(everythingOptions{refreshIndex: true}, stderr, stderr)
+		}
+		code := exitcode.OK
+		if len(report.Tools) > 0 {
+			code = ws.install(report.Tools, true, stderr, stderr)
+		}
+		if code == exitcode.OK && len(report.Environments) > 0 {
+			code = ws.applyEnvironments(report.Environments, "", stderr, stderr)
+		}
+		return code
+	})
+	
+	runReport.FinishedAt = time.Now()
+	report.FinishedAt = runReport.FinishedAt
+	report.Results = append(report.Results, runReport.Results...)
+	if !ws.engine.DryRun() {
+		ws.configManager.SaveRunReport(*runReport)
+	}
+	return writeAutoReport(report, stdout, stderr)
+}
+
+// writeAutoReport prints the report as JSON and returns its exit code
+func writeAutoReport(report AutoReport, stdout, stderr io.Writer) int {
+	if report.FinishedAt.IsZero() {
+		report.FinishedAt = time.Now()
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	return report.ExitCode
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
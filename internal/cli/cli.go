@@ -0,0 +1,678 @@
+// Package cli implements the non-interactive subcommands of BOBA: `boba install`, `boba uninstall`,
+// `boba list`, `boba search`, `boba env apply|restore|resolve|show`, `boba plan`, `boba apply`, `boba sync`, `boba status`, `boba metrics`, `boba history`, `boba reset`, `boba validate`, `boba self-update`, `boba migrate` and `boba maintain`, for scripts and provisioning where the terminal UI can't be used. They read
+// the same configuration, overrides and installation records as the UI.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"boba/internal/ci"
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// Install implements `boba install <tool>...` and returns the exit code
+func Install(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	refreshIndex := flags.Bool("refresh-index", true, "refresh the system package index once before installing")
+	all := flags.Bool("all", false, "run Install Everything: the tools, then the environments it includes")
+	yes := flags.Bool("yes", false, "confirm running Install Everything without a prompt")
+	skipFailing := flags.Bool("skip-failing", false, "with --all, leave out the tools on the skip list or failing repeatedly")
+	retryCooldown := flags.Bool("retry-cooldown", false, "with --all, retry the tools still in their install cooldown")
+	edited := flags.String("edited", "", "with --all, settle home files edited since they were last applied: mine, repo or merge")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba install [--refresh-index=false] <tool>...")
+		fmt.Fprintln(stderr, "       boba install --all --yes [--skip-failing] [--retry-cooldown] [--edited mine|repo|merge]")
+		fmt.Fprintln(stderr, "Installs the tools and the tools they depend on, skipping the ones already installed,")
+		fmt.Fprintln(stderr, "or runs Install Everything with --all.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if *all {
+		if flags.NArg() != 0 {
+			flags.Usage()
+			return exitcode.Usage
+		}
+		if !*yes {
+			fmt.Fprintln(stderr, "Error: boba install --all runs every script Install Everything includes: pass --yes to confirm")
+			return exitcode.Usage
+		}
+		if err := validEditResolution(*edited); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Usage
+		}
+		
+		ws, err := openWorkspace()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return workspaceExitCode(err)
+		}
+		defer ws.close()
+		return ws.reportToActions(stdout, stderr, func() int {
+			return ws.installAll(everythingOptions{refreshIndex: *refreshIndex, skipFailing: *skipFailing, retryCooldown: *retryCooldown, edited: *edited}, stdout, stderr)
+		})
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	return ws.reportToActions(stdout, stderr, func() int {
+		return ws.install(flags.Args(), *refreshIndex, stdout, stderr)
+	})
+}
+
+// List implements `boba list [--json]` and returns the exit code
+func List(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the tools and environments as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba list [--json]")
+		fmt.Fprintln(stderr, "Lists the tools and environments of the repository, whether they are installed and whether Install Everything includes them.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	return ws.list(*asJSON, stdout, stderr)
+}
+
+// Env implements `boba env apply [--edited mine|repo|merge] <environment>...`, `boba env restore
+// <environment>...`, `boba env resolve <file> <environment|merge>` and `boba env show [--json]` and
+// returns the exit code
+func Env(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: boba env apply [--edited mine|repo|merge] <environment>...")
+		fmt.Fprintln(stderr, "       boba env restore <environment>...")
+		fmt.Fprintln(stderr, "       boba env resolve <file> <environment|merge>")
+		fmt.Fprintln(stderr, "       boba env show [--json]")
+		fmt.Fprintln(stderr, "apply applies the environments and the environments they depend on, skipping the ones already applied.")
+		fmt.Fprintln(stderr, "  --edited settles the home files you edited since they were last applied: keep mine, take the repo's, or merge both.")
+		fmt.Fprintln(stderr, "restore reverts the environments with their restore scripts.")
+		fmt.Fprintln(stderr, "resolve settles a home file several environments manage: the environment owning it, or merge to keep what each adds.")
+		fmt.Fprintln(stderr, "show lists the aliases, PATH entries and exports BOBA's snippets define, with the tool or environment of each.")
+	}
+	if len(args) > 0 && args[0] == "show" {
+		flags := flag.NewFlagSet("env show", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		asJSON := flags.Bool("json", false, "print the definitions as JSON")
+		flags.Usage = usage
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitcode.Usage
+		}
+		if flags.NArg() != 0 {
+			usage()
+			return exitcode.Usage
+		}
+		return showShellDefinitions(*asJSON, stdout, stderr)
+	}
+	if len(args) < 2 || (args[0] != "apply" && args[0] != "restore" && args[0] != "resolve") || (args[0] == "resolve" && len(args) != 3) {
+		usage()
+		return exitcode.Usage
+	}
+	var edited string
+	names := args[1:]
+	if args[0] == "apply" {
+		flags := flag.NewFlagSet("env apply", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		flags.StringVar(&edited, "edited", "", "settle home files edited since they were last applied: mine, repo or merge")
+		flags.Usage = usage
+		if err := flags.Parse(names); err != nil {
+			return exitcode.Usage
+		}
+		if flags.NArg() == 0 {
+			usage()
+			return exitcode.Usage
+		}
+		if err := validEditResolution(edited); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Usage
+		}
+		names = flags.Args()
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	switch args[0] {
+	case "restore":
+		return ws.restoreEnvironments(args[1:], stdout, stderr)
+	case "resolve":
+		return ws.resolveFileConflict(args[1], args[2], stdout, stderr)
+	}
+	return ws.reportToActions(stdout, stderr, func() int {
+		return ws.applyEnvironments(names, edited, stdout, stderr)
+	})
+}
+
+// showShellDefinitions prints the aliases, PATH entries and exports of the managed snippets in the
+// home directory, which needs no repository
+func showShellDefinitions(asJSON bool, stdout, stderr io.Writer) int {
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	definitions, err := installer.ManagedShellDefinitions(configManager.GetConfig().ManagedFiles)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if definitions == nil {
+			definitions = []installer.ShellDefinition{}
+		}
+		if err := encoder.Encode(definitions); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	if len(definitions) == 0 {
+		fmt.Fprintln(stdout, "No aliases, PATH entries or exports in BOBA's snippets: apply an environment first")
+		return exitcode.OK
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "KIND\tNAME\tVALUE\tSOURCE\tFILE")
+	for _, d := range definitions {
+		file := fmt.Sprintf("~/%s:%d", d.File, d.Line)
+		if d.Overridden {
+			file += " (overridden later in the file)"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", d.Kind, d.Name, dash(d.Value), d.Source, file)
+	}
+	table.Flush()
+	return exitcode.OK
+}
+
+// install installs the named tools and their dependencies in dependency order, stopping at the
+// first failure since the tools after it may depend on it
+func (w *workspace) install(names []string, refreshIndex bool, stdout, stderr io.Writer) int {
+	// Managed machines only run approved plan files
+	if w.engine.ApprovalRequired() {
+		fmt.Fprintln(stderr, approvalRequiredMessage)
+		return exitcode.Failure
+	}
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	selected, err := withDependencies("tool", names, tools, func(t parser.Tool) (string, []string) { return t.Name, t.Dependencies })
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	ordered, err := installer.NewDependencyResolver().ResolveToolDependencies(selected)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	
+	// Refresh the package index once for this batch (non-fatal on failure)
+	w.engine.BeginRun()
+	if err := w.engine.BeginBatch("install " + strings.Join(names, ", ")); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	defer w.engine.EndBatch()
+	if refreshIndex {
+		if _, err := w.engine.EnsurePackageIndexFresh(ordered); err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+		}
+	}
+	
+	for _, tool := range ordered {
+		if w.engine.IsToolInstalled(tool) {
+			fmt.Fprintf(stdout, "✓ %s is already installed\n", tool.Name)
+			w.addResult(tool.Name, "tools", true, "already installed")
+			continue
+		}
+		
+		endGroup := ci.Detect().BeginGroup(stdout, "Installing "+tool.Name)
+		fmt.Fprintf(stdout, "Installing %s...\n", tool.Name)
+		result, err := w.engine.InstallTool(tool)
+		success := err == nil && result.Success
+		if success && reportDryRun(stdout, result) {
+			w.addResult(tool.Name, "tools", true, "dry run")
+			endGroup()
+			continue
+		}
+		w.recordToolRun(tool.Name, success, result)
+		if !success {
+			reportFailure(stderr, tool.Name, result, err)
+			w.addResult(tool.Name, "tools", false, failureMessage(result, err))
+			endGroup()
+			return failureExitCode(result, err)
+		}
+		
+		version := tool.Version
+		if version == "" {
+			version = "latest"
+		}
+		w.configManager.RecordToolInstallationWithProvenance(tool.Name, version, "manual", result.Provenance)
+		w.addResult(tool.Name, "tools", true, "")
+		if result.Satisfied {
+			fmt.Fprintf(stdout, "✓ %s %s\n", tool.Name, installer.SatisfiedOutput)
+		} else {
+			fmt.Fprintf(stdout, "✓ %s installed successfully\n", tool.Name)
+		}
+		reportDetails(stdout, result)
+		endGroup()
+	}
+	return exitcode.OK
+}
+
+// applyEnvironments applies the named environments and their dependencies in dependency order,
+// settling the home files edited since they were last applied with edited (mine, repo or merge)
+func (w *workspace) applyEnvironments(names []string, edited string, stdout, stderr io.Writer) int {
+	// Managed machines only run approved plan files
+	if w.engine.ApprovalRequired() {
+		fmt.Fprintln(stderr, approvalRequiredMessage)
+		return exitcode.Failure
+	}
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	selected, err := withDependencies("environment", names, environments, func(e parser.Environment) (string, []string) { return e.Name, e.Dependencies })
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	ordered, err := installer.NewDependencyResolver().ResolveEnvironmentDependencies(selected)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	
+	var pending []parser.Environment
+	for _, env := range ordered {
+		if !w.engine.IsEnvironmentApplied(env) {
+			pending = append(pending, env)
+		}
+	}
+	if err := w.checkFileConflicts(pending); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.checkEditedFiles(pending, edited); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if len(pending) > 0 {
+		w.saveRestorePoint("Before applying "+strings.Join(names, ", "), pending, stderr)
+	}
+	
+	// Start a fresh run so follow-up actions only reflect this application
+	w.engine.BeginRun()
+	if err := w.engine.BeginBatch("apply " + strings.Join(names, ", ")); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	defer w.engine.EndBatch()
+	
+	for _, env := range ordered {
+		if w.engine.IsEnvironmentApplied(env) {
+			fmt.Fprintf(stdout, "✓ %s is already applied\n", env.Name)
+			w.addResult(env.Name, "environments", true, "already applied")
+			continue
+		}
+		
+		endGroup := ci.Detect().BeginGroup(stdout, "Applying "+env.Name)
+		fmt.Fprintf(stdout, "Applying %s...\n", env.Name)
+		result, err := w.engine.ApplyEnvironment(env)
+		if err != nil || !result.Success {
+			reportFailure(stderr, env.Name, result, err)
+			w.addResult(env.Name, "environments", false, failureMessage(result, err))
+			endGroup()
+			return failureExitCode(result, err)
+		}
+		if reportDryRun(stdout, result) {
+			w.addResult(env.Name, "environments", true, "dry run")
+			endGroup()
+			continue
+		}
+		w.configManager.RecordEnvironmentApplied(env.Name)
+		w.addResult(env.Name, "environments", true, "")
+		fmt.Fprintf(stdout, "✓ %s applied successfully\n", env.Name)
+		reportDetails(stdout, result)
+		endGroup()
+	}
+	return exitcode.OK
+}
+
+// resolveFileConflict sets the environment owning a home file several environments manage, or
+// merges what each of them adds to it
+func (w *workspace) resolveFileConflict(file, owner string, stdout, stderr io.Writer) int {
+	file = strings.TrimPrefix(file, "~/")
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	var managers []string
+	for _, env := range environments {
+		if slices.Contains(parser.HomeFiles([]parser.Environment{env}), file) {
+			managers = append(managers, env.Name)
+		}
+	}
+	if len(managers) == 0 {
+		fmt.Fprintf(stderr, "Error: no environment manages ~/%s\n", file)
+		return exitcode.Usage
+	}
+	if owner != config.FileMergeStrategy && !slices.Contains(managers, owner) {
+		fmt.Fprintf(stderr, "Error: %s does not manage ~/%s: use one of %s, or merge\n", owner, file, strings.Join(managers, ", "))
+		return exitcode.Usage
+	}
+	
+	if err := w.configManager.SetFileOwner(file, owner); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if owner == config.FileMergeStrategy {
+		fmt.Fprintf(stdout, "✓ ~/%s keeps the lines each environment adds\n", file)
+	} else {
+		fmt.Fprintf(stdout, "✓ ~/%s is owned by %s: other environments no longer change it\n", file, owner)
+	}
+	return exitcode.OK
+}
+
+// restoreEnvironments reverts the named environments with their restore scripts, in the order
+// given, and clears their applied records
+func (w *workspace) restoreEnvironments(names []string, stdout, stderr io.Writer) int {
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	byName := make(map[string]parser.Environment, len(environments))
+	for _, env := range environments {
+		byName[env.Name] = env
+	}
+	
+	var selected []parser.Environment
+	for _, name := range names {
+		env, ok := byName[name]
+		if !ok {
+			fmt.Fprintf(stderr, "Error: environment %s is not in the repository\n", name)
+			return exitcode.Failure
+		}
+		if env.RestoreScript == "" && env.RestoreInline == "" {
+			fmt.Fprintf(stderr, "Error: environment %s has no restore script\n", name)
+			return exitcode.Failure
+		}
+		selected = append(selected, env)
+	}
+	
+	w.engine.BeginRun()
+	if err := w.engine.BeginBatch("restore " + strings.Join(names, ", ")); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	defer w.engine.EndBatch()
+	
+	for _, env := range selected {
+		fmt.Fprintf(stdout, "Restoring %s...\n", env.Name)
+		result, err := w.engine.RestoreEnvironment(env)
+		if err != nil || !result.Success {
+			reportFailure(stderr, env.Name, result, err)
+			return failureExitCode(result, err)
+		}
+		if reportDryRun(stdout, result) {
+			continue
+		}
+		w.configManager.RemoveAppliedEnvironment(env.Name)
+		fmt.Fprintf(stdout, "✓ %s restored successfully\n", env.Name)
+		reportDetails(stdout, result)
+	}
+	return exitcode.OK
+}
+
+// listItem is a tool or environment of `boba list --json`
+type listItem struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	Installed         bool   `json:"installed"`
+	Scope             string `json:"scope,omitempty"` // Where an installed tool was found: user or system
+	InstallEverything bool   `json:"install_everything"` // Included by Install Everything, overrides applied
+}
+
+// list prints the tools and environments of the repository
+func (w *workspace) list(asJSON bool, stdout, stderr io.Writer) int {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	var items []listItem
+	for _, tool := range tools {
+		scope, installed := w.engine.FindInstalledScope(tool)
+		items = append(items, listItem{
+			Kind:              "tool",
+			Name:              tool.Name,
+			Description:       tool.Description,
+			Installed:         installed,
+			Scope:             scope.Scope,
+			InstallEverything: w.installEverything(tool),
+		})
+	}
+	for _, env := range environments {
+		items = append(items, listItem{
+			Kind:              "environment",
+			Name:              env.Name,
+			Description:       env.Description,
+			Installed:         w.environmentApplied(env),
+			InstallEverything: w.applyEverything(env),
+		})
+	}
+	
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(items); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "KIND\tNAME\tINSTALLED\tSCOPE\tINSTALL EVERYTHING\tDESCRIPTION")
+	for _, item := range items {
+		scope := item.Scope
+		if scope == "" {
+			scope = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", item.Kind, item.Name, yesNo(item.Installed), scope, yesNo(item.InstallEverything), item.Description)
+	}
+	table.Flush()
+	return exitcode.OK
+}
+
+// environmentApplied reports whether the environment is applied or recorded as applied
+func (w *workspace) environmentApplied(env parser.Environment) bool {
+	_, recorded := w.configManager.GetEnvironmentAppliedDate(env.Name)
+	return recorded || w.engine.IsEnvironmentApplied(env)
+}
+
+// withDependencies returns the named items and the items they depend on, transitively
+func withDependencies[T any](kind string, names []string, all []T, describe func(T) (string, []string)) ([]T, error) {
+	byName := make(map[string]T, len(all))
+	for _, item := range all {
+		name, _ := describe(item)
+		byName[name] = item
+	}
+	
+	selected := make(map[string]bool)
+	var items []T
+	var collect func(name string, dependency bool) error
+	collect = func(name string, dependency bool) error {
+		if selected[name] {
+			return nil
+		}
+		item, ok := byName[name]
+		if !ok && dependency {
+			return fmt.Errorf("%s %w: %s", kind, installer.ErrDependencyNotFound, name)
+		}
+		if !ok {
+			return fmt.Errorf("%s %s is not in the repository", kind, name)
+		}
+		
+		selected[name] = true
+		items = append(items, item)
+		_, dependencies := describe(item)
+		for _, dep := range dependencies {
+			if err := collect(dep, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	
+	for _, name := range names {
+		if err := collect(name, false); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// errorExitCode returns the exit code for an error found before anything ran: missing or
+// circular dependencies, or any other failure
+func errorExitCode(err error) int {
+	if errors.Is(err, installer.ErrDependencyNotFound) || errors.Is(err, installer.ErrCircularDependency) {
+		return exitcode.Dependency
+	}
+	return exitcode.Failure
+}
+
+// failureExitCode returns the exit code for a failed install or environment application:
+// a script that timed out or exited with an error, or a failure before any script ran
+func failureExitCode(result *installer.InstallationResult, err error) int {
+	if result != nil && result.Error != nil {
+		err = result.Error
+	}
+	switch {
+	case errors.Is(err, installer.ErrTimeout):
+		return exitcode.Timeout
+	case result != nil && result.ExitCode != 0:
+		return exitcode.Script
+	}
+	return errorExitCode(err)
+}
+
+// failureMessage summarizes why a tool or environment failed, for reports
+func failureMessage(result *installer.InstallationResult, err error) string {
+	if result != nil && result.Error != nil {
+		err = result.Error
+	}
+	switch {
+	case err != nil:
+		return err.Error()
+	case result != nil && result.ExitCode != 0:
+		return fmt.Sprintf("script exited with code %d", result.ExitCode)
+	}
+	return "failed"
+}
+
+// reportFailure prints why a tool or environment failed
+func reportFailure(stderr io.Writer, name string, result *installer.InstallationResult, err error) {
+	fmt.Fprintf(stderr, "✗ %s failed\n", name)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+	}
+	if result == nil {
+		return
+	}
+	if result.Output != "" {
+		fmt.Fprintln(stderr, result.Output)
+	}
+	if result.TempDir != "" {
+		fmt.Fprintf(stderr, "Temp files kept in %s\n", result.TempDir)
+	}
+}
+
+// recordToolRun records the outcome and the metrics of a tool install
+func (w *workspace) recordToolRun(toolName string, success bool, result *installer.InstallationResult) {
+	if result == nil {
+		w.configManager.RecordScriptResult(toolName, success)
+		return
+	}
+	w.configManager.RecordToolRun(toolName, success, result.Duration, result.DownloadBytes)
+}
+
+// reportDryRun prints what a script would have run in dry-run mode, and reports whether the
+// result is a dry run, in which case nothing ran and nothing is recorded
+func reportDryRun(stdout io.Writer, result *installer.InstallationResult) bool {
+	if !result.DryRun {
+		return false
+	}
+	fmt.Fprintln(stdout, result.Output)
+	return true
+}
+
+// reportDetails prints the steps and follow-up actions of a successful script
+func reportDetails(stdout io.Writer, result *installer.InstallationResult) {
+	if len(result.Steps) > 0 {
+		fmt.Fprintln(stdout, installer.StepSummary(result.Steps))
+	}
+	for _, note := range result.FileNotes {
+		fmt.Fprintf(stdout, "→ %s\n", note)
+	}
+	for _, action := range result.FollowUps {
+		fmt.Fprintf(stdout, "→ %s\n", action.Description())
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,917 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/installer"
+	"boba/internal/parser"
+	"boba/internal/version"
+)
+
+// writeFiles creates files of a test repository
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	base, app, broken := "cli-base-"+suffix, "cli-app-"+suffix, "cli-broken-"+suffix
+	marker := filepath.Join(t.TempDir(), "env-applied")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/" + base + "/tool.yaml":        "name: " + base + "\ndescription: Base tool\n",
+		"tools/" + base + "/install.sh":       "#!/bin/bash\necho base\n",
+		"tools/" + base + "/uninstall.sh":     "#!/bin/bash\necho removed\n",
+		"tools/" + app + "/tool.yaml":         "name: " + app + "\nauto_install: true\ndependencies: [" + base + "]\n",
+		"tools/" + app + "/install.sh":        "#!/bin/bash\necho app\n",
+		"tools/" + broken + "/tool.yaml":      "name: " + broken + "\n",
+		"tools/" + broken + "/install.sh":     "#!/bin/bash\nexit 3\n",
+		"environments/shell/environment.yaml": "name: shell\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\ntouch " + marker + "\n",
+		"environments/shell/restore.sh":       "#!/bin/bash\nrm " + marker + "\n",
+	})
+	defer func() {
+		for _, name := range []string{base, app, broken} {
+			configManager.RemoveInstalledTool(name)
+			configManager.RecordScriptResult(name, true)
+		}
+		configManager.RemoveToolOverride(base)
+		configManager.RemoveAppliedEnvironment("shell")
+	}()
+	
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	// Overrides decide what Install Everything includes, like in the UI
+	configManager.SetToolOverride(base, true)
+	var stdout, stderr bytes.Buffer
+	if code := ws.list(true, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected list to succeed, got %d: %s", code, stderr.String())
+	}
+	var items []listItem
+	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	included := map[string]bool{}
+	for _, item := range items {
+		included[item.Name] = item.InstallEverything
+	}
+	if len(items) != 4 || !included[base] || !included[app] || included[broken] {
+		t.Errorf("Expected the overridden and auto_install tools to be included, got %+v", items)
+	}
+	
+	// A dry run describes the scripts in order and records nothing
+	ws.engine.SetOptions(installer.Options{DryRun: true})
+	stdout.Reset()
+	if code := ws.install([]string{app}, false, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected the dry run to succeed, got %d: %s", code, stderr.String())
+	}
+	if first, second := strings.Index(stdout.String(), "would install "+base), strings.Index(stdout.String(), "would install "+app); first < 0 || second < first {
+		t.Errorf("Expected %s to be described before %s, got:\n%s", base, app, stdout.String())
+	}
+	if _, ok := configManager.GetInstalledTool(base); ok {
+		t.Errorf("Expected the dry run not to record %s", base)
+	}
+	ws.engine.SetOptions(installer.Options{})
+	
+	// Installing a tool installs its dependencies first and records both
+	stdout.Reset()
+	if code := ws.install([]string{app}, false, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected install to succeed, got %d: %s", code, stderr.String())
+	}
+	if first, second := strings.Index(stdout.String(), "Installing "+base), strings.Index(stdout.String(), "Installing "+app); first < 0 || second < first {
+		t.Errorf("Expected %s before %s, got:\n%s", base, app, stdout.String())
+	}
+	for _, name := range []string{base, app} {
+		if _, ok := configManager.GetInstalledTool(name); !ok {
+			t.Errorf("Expected %s to be recorded as installed", name)
+		}
+	}
+	
+	stderr.Reset()
+	if code := ws.install([]string{broken}, false, &stdout, &stderr); code != exitcode.Script || !strings.Contains(stderr.String(), "✗ "+broken+" failed") {
+		t.Errorf("Expected the failing install to be reported, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := ws.install([]string{"missing-" + suffix}, false, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "is not in the repository") {
+		t.Errorf("Expected the unknown tool to be reported, got %d: %s", code, stderr.String())
+	}
+	
+	if code := ws.applyEnvironments([]string{"shell"}, "", &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected the environment to be applied, got %d: %s", code, stderr.String())
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the setup script to run: %v", err)
+	}
+	if _, ok := configManager.GetEnvironmentAppliedDate("shell"); !ok {
+		t.Error("Expected the environment to be recorded as applied")
+	}
+	
+	// Restoring runs restore.sh and clears the applied record
+	if code := ws.restoreEnvironments([]string{"shell"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected the environment to be restored, got %d: %s", code, stderr.String())
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("Expected the restore script to run, got %v", err)
+	}
+	if _, ok := configManager.GetEnvironmentAppliedDate("shell"); ok {
+		t.Error("Expected the applied record to be cleared")
+	}
+	
+	// A tool without uninstall script is refused before anything runs
+	if code := ws.uninstall([]string{base, app}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), app+" can't be uninstalled") {
+		t.Errorf("Expected %s to be refused, got %d: %s", app, code, stderr.String())
+	}
+	if _, ok := configManager.GetInstalledTool(base); !ok {
+		t.Errorf("Expected %s to stay installed", base)
+	}
+	
+	// Uninstalling forgets the record and mentions the installed tools depending on it
+	stdout.Reset()
+	if code := ws.uninstall([]string{base}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected uninstall to succeed, got %d: %s", code, stderr.String())
+	}
+	if _, ok := configManager.GetInstalledTool(base); ok {
+		t.Errorf("Expected %s to be removed from the installation records", base)
+	}
+	if !strings.Contains(stdout.String(), app+" depend on "+base) {
+		t.Errorf("Expected %s to be mentioned as a dependent, got:\n%s", app, stdout.String())
+	}
+	stderr.Reset()
+	if code := ws.uninstall([]string{app}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "no uninstall script") {
+		t.Errorf("Expected a tool without uninstall script to be reported, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Install(nil, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Usage: boba install") {
+		t.Errorf("Expected install without tools to print its usage, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Env([]string{"remove", "shell"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Usage: boba env apply") {
+		t.Errorf("Expected an unknown env command to print its usage, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Install([]string{"--all"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "pass --yes") {
+		t.Errorf("Expected install --all to require --yes, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Apply(nil, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Usage: boba apply") {
+		t.Errorf("Expected apply without a plan to print its usage, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Sync([]string{"tools"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Usage: boba sync") {
+		t.Errorf("Expected sync with arguments to print its usage, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Metrics([]string{"rg"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Usage: boba metrics") {
+		t.Errorf("Expected metrics with arguments to print its usage, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Metrics([]string{"--sort", "size"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "unknown order") {
+		t.Errorf("Expected an unknown metrics order to be rejected, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Reset([]string{"caches"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "pass --yes") {
+		t.Errorf("Expected reset to require --yes, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Reset([]string{"--yes", "history"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "unknown reset scope") {
+		t.Errorf("Expected an unknown reset scope to be rejected, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	metrics := []config.ToolMetrics{{Tool: "rg", Runs: 3, P95Duration: 1500 * time.Millisecond, DownloadBytes: 2048, DiskBytes: 5 << 20}}
+	if code := writeMetrics(metrics, false, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected the table to be written, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "P95 TIME") || !strings.Contains(stdout.String(), "1.5s") || !strings.Contains(stdout.String(), "2.0 KiB") || !strings.Contains(stdout.String(), "5.0 MiB") {
+		t.Errorf("Unexpected metrics table:\n%s", stdout.String())
+	}
+}
+
+func TestInstallAll(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	os.Remove(configManager.GetLastPlanPath())
+	defer os.Remove(configManager.GetLastPlanPath())
+	
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	base, app, broken, manual := "all-base-"+suffix, "all-app-"+suffix, "all-broken-"+suffix, "all-manual-"+suffix
+	marker := filepath.Join(t.TempDir(), "env-applied")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/" + base + "/tool.yaml":        "name: " + base + "\nauto_install: true\n",
+		"tools/" + base + "/install.sh":       "#!/bin/bash\necho base\n",
+		"tools/" + app + "/tool.yaml":         "name: " + app + "\nauto_install: true\ndependencies: [" + base + "]\n",
+		"tools/" + app + "/install.sh":        "#!/bin/bash\necho app\n",
+		"tools/" + broken + "/tool.yaml":      "name: " + broken + "\nauto_install: true\n",
+		"tools/" + broken + "/install.sh":     "#!/bin/bash\nexit 3\n",
+		"tools/" + manual + "/tool.yaml":      "name: " + manual + "\n",
+		"tools/" + manual + "/install.sh":     "#!/bin/bash\nexit 1\n",
+		"environments/shell/environment.yaml": "name: shell\nauto_apply: true\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\ntouch " + marker + "\n",
+	})
+	defer func() {
+		for _, name := range []string{base, app, broken} {
+			configManager.RemoveInstalledTool(name)
+			configManager.RecordScriptResult(name, true)
+		}
+		configManager.RemoveAppliedEnvironment("shell")
+	}()
+	
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	// A failure doesn't stop the run, but makes it exit with 1
+	var stdout, stderr bytes.Buffer
+	if code := ws.installAll(everythingOptions{retryCooldown: true}, &stdout, &stderr); code != exitcode.Script || !strings.Contains(stderr.String(), "✗ "+broken+" failed") {
+		t.Fatalf("Expected the failing tool to fail the run, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	if first, second := strings.Index(output, "Installing "+base), strings.Index(output, "Installing "+app); first < 0 || second < first {
+		t.Errorf("Expected %s before %s, got:\n%s", base, app, output)
+	}
+	if strings.Contains(output, manual) || !strings.Contains(output, "[4/4] Applying shell...") {
+		t.Errorf("Expected the auto_install tools then the environment, got:\n%s", output)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the environment to be applied after the failure: %v", err)
+	}
+	if _, saved, _ := configManager.LoadLastPlan(); saved {
+		t.Error("Expected the plan of a failed run not to be saved")
+	}
+	
+	// Tools in their install cooldown are left out unless retried
+	stdout.Reset()
+	if code := ws.installAll(everythingOptions{}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "- "+broken+": not retried") {
+		t.Fatalf("Expected the failed tool to wait for its cooldown, got %d:\n%s", code, stdout.String())
+	}
+	if _, saved, _ := configManager.LoadLastPlan(); !saved {
+		t.Error("Expected the plan of the successful run to be saved")
+	}
+}
+
+func TestExportPlan(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/base/tool.yaml":                "name: base\nauto_install: true\n",
+		"tools/base/install.sh":               "#!/bin/bash\necho base\n",
+		"tools/app/tool.yaml":                 "name: app\nversion: \"2.1\"\nauto_install: true\ndependencies: [base]\n",
+		"tools/app/install.sh":                "#!/bin/bash\necho app\n",
+		"tools/manual/tool.yaml":              "name: manual\n",
+		"tools/manual/install.sh":             "#!/bin/bash\necho manual\n",
+		"environments/shell/environment.yaml": "name: shell\nauto_apply: true\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\necho shell\n",
+	})
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	// The plan is printed in run order, with versions and scripts, without running anything
+	var stdout, stderr bytes.Buffer
+	if code := ws.exportPlan(everythingOptions{}, "", true, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the plan to be exported, got %d: %s", code, stderr.String())
+	}
+	var plan config.ExecutionPlan
+	if err := json.Unmarshal(stdout.Bytes(), &plan); err != nil {
+		t.Fatalf("Expected only the plan on stdout, got %v:\n%s", err, stdout.String())
+	}
+	var order []string
+	for _, entry := range plan.Entries {
+		order = append(order, entry.Kind+"/"+entry.Name)
+	}
+	if strings.Join(order, ",") != "tool/base,tool/app,environment/shell" {
+		t.Errorf("Unexpected plan order: %v", order)
+	}
+	if app := plan.Entries[1]; app.Version != "2.1" || len(app.Scripts) != 1 || !strings.HasSuffix(app.Scripts[0], "app/install.sh") || app.ScriptHash == "" {
+		t.Errorf("Expected the version and script of app, got %+v", app)
+	}
+	if plan.PlanHash != plan.Hash() {
+		t.Error("Expected the exported plan to carry its hash")
+	}
+	
+	// A plan file can be applied with boba apply
+	path := filepath.Join(t.TempDir(), "boba-plan.yaml")
+	stdout.Reset()
+	if code := ws.exportPlan(everythingOptions{}, path, false, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "Plan of 3 operations written to") {
+		t.Fatalf("Expected the plan file to be written, got %d: %s", code, stdout.String())
+	}
+	if written, err := config.ReadPlanFile(path); err != nil || len(written.Entries) != 3 {
+		t.Errorf("Expected a readable plan file, got %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv(config.HomeEnv, home)
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(t.TempDir(), "summary.md"))
+	t.Setenv("RUNNER_TEMP", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "env-applied")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/base/tool.yaml":                "name: base\nauto_install: true\n",
+		"tools/base/install.sh":               "#!/bin/bash\necho base\n",
+		"tools/app/tool.yaml":                 "name: app\nauto_install: true\ndependencies: [base]\n",
+		"tools/app/install.sh":                "#!/bin/bash\necho app\n",
+		"environments/shell/environment.yaml": "name: shell\nauto_apply: true\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\ntouch " + marker + "\n",
+	})
+	data, _ := json.Marshal(map[string]string{"local_repo_path": dir})
+	os.WriteFile(filepath.Join(home, "config.json"), data, 0644)
+	
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "boba-plan.yaml")
+	var stdout, stderr bytes.Buffer
+	if code := ws.exportPlan(everythingOptions{}, path, false, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Failed to export the plan, got %d: %s", code, stderr.String())
+	}
+	ws.close()
+	
+	// The plan runs in its order without a terminal, even in CI, and the results go to JUnit XML
+	junit := filepath.Join(t.TempDir(), "results.xml")
+	stdout.Reset()
+	if code := Apply([]string{"--junit", junit, path}, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the plan to run, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "[1/3] Installing base...") || !strings.Contains(output, "[2/3] Installing app...") || !strings.Contains(output, "[3/3] Applying shell...") {
+		t.Errorf("Expected the plan order, got:\n%s", output)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the environment to be applied: %v", err)
+	}
+	if data, _ := os.ReadFile(junit); !strings.Contains(string(data), `name="app"`) || !strings.Contains(string(data), `name="shell"`) {
+		t.Errorf("Expected the results as JUnit XML, got:\n%s", data)
+	}
+	
+	// A script changed since the export is refused before anything runs
+	os.WriteFile(filepath.Join(dir, "tools/app/install.sh"), []byte("#!/bin/bash\necho edited\n"), 0755)
+	stderr.Reset()
+	if code := Apply([]string{path}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "changed since the plan was exported") {
+		t.Errorf("Expected the changed script to be refused, got %d: %s", code, stderr.String())
+	}
+	
+	// So is a plan edited after its export
+	plan, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(plan), "name: base", "name: other", 1)), 0644)
+	stderr.Reset()
+	if code := Apply([]string{path}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "edited after it was exported") {
+		t.Errorf("Expected the edited plan to be refused, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestApplyApprovedPlan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/managed/tool.yaml":             "name: managed\nauto_install: true\n",
+		"tools/managed/install.sh":            "#!/bin/bash\necho managed\n",
+		"environments/shell/environment.yaml": "name: shell\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\n",
+	})
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	// The policy file of a managed machine requires plans approved with the admin's key
+	keyPath := filepath.Join(t.TempDir(), "admin.key")
+	publicKey, err := config.GenerateApprovalKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.engine.SetApprovalPolicy(true, []string{publicKey})
+	
+	// Everything but an approved plan is refused, telling how to run one
+	var stdout, stderr bytes.Buffer
+	if code := ws.install([]string{"managed"}, false, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "boba apply boba-plan.yaml") {
+		t.Errorf("Expected the install to be refused, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := ws.installAll(everythingOptions{}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "boba plan approve") {
+		t.Errorf("Expected Install Everything to be refused, got %d: %s", code, stderr.String())
+	}
+	path := filepath.Join(t.TempDir(), "boba-plan.yaml")
+	if code := ws.exportPlan(everythingOptions{}, path, false, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Failed to export the plan, got %d: %s", code, stderr.String())
+	}
+	plan, _ := config.ReadPlanFile(path)
+	stderr.Reset()
+	if code := ws.applyPlan(plan, false, "", &stdout, &stderr); code != exitcode.Failure {
+		t.Errorf("Expected the plan to be refused before its approval, got %d: %s", code, stderr.String())
+	}
+	if _, ok := configManager.GetInstalledTool("managed"); ok {
+		t.Fatal("Expected nothing to be installed without an approved plan")
+	}
+	
+	// The approved plan runs without a terminal
+	if err := config.SignPlan(&plan, keyPath, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if code := ws.applyPlan(plan, false, "", &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "✓ managed installed successfully") {
+		t.Fatalf("Expected the approved plan to run, got %d: %s\n%s", code, stdout.String(), stderr.String())
+	}
+	if _, ok := configManager.GetInstalledTool("managed"); !ok {
+		t.Error("Expected the approved tool to be recorded")
+	}
+}
+
+func TestAuto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv(config.HomeEnv, home)
+	t.Setenv(ToolsEnv, "")
+	t.Setenv(EnvironmentsEnv, "")
+	
+	t.Setenv(AutoEnv, "1")
+	if !AutoMode(true) {
+		t.Error("Expected BOBA_AUTO=1 to run the automatic mode on a terminal")
+	}
+	t.Setenv(AutoEnv, "0")
+	if AutoMode(false) {
+		t.Error("Expected BOBA_AUTO=0 to disable the automatic mode")
+	}
+	t.Setenv(AutoEnv, "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if !AutoMode(true) {
+		t.Error("Expected CI to run the automatic mode even with a terminal")
+	}
+	t.Setenv("GITHUB_ACTIONS", "")
+	
+	// Errors are reported in the JSON document too
+	var stdout, stderr bytes.Buffer
+	var report AutoReport
+	if code := Auto(&stdout, &stderr); code != exitcode.Failure {
+		t.Fatalf("Expected no repository to fail, got %d", code)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || report.Error == "" || report.ExitCode != exitcode.Failure {
+		t.Fatalf("Expected an error report, got %+v: %v", report, err)
+	}
+	
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/auto-tool/tool.yaml":  "name: auto-tool\n",
+		"tools/auto-tool/install.sh": "#!/bin/bash\necho installing auto-tool\n",
+	})
+	data, _ := json.Marshal(map[string]string{"local_repo_path": dir})
+	os.WriteFile(filepath.Join(home, "config.json"), data, 0644)
+	t.Setenv(ToolsEnv, "auto-tool")
+	
+	stdout.Reset()
+	stderr.Reset()
+	if code := Auto(&stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the selection to install, got %d: %s", code, stderr.String())
+	}
+	report = AutoReport{}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Expected only JSON on stdout, got %v:\n%s", err, stdout.String())
+	}
+	if report.Profile != "selection" || len(report.Results) != 1 || report.Results[0].Name != "auto-tool" || !report.Results[0].Success {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if !strings.Contains(stderr.String(), "Installing auto-tool") {
+		t.Errorf("Expected the progress on stderr, got:\n%s", stderr.String())
+	}
+	
+	// In CI the output of each tool is a collapsible section, and GitHub Actions gets the results
+	// as a step summary
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+	t.Setenv("RUNNER_TEMP", t.TempDir())
+	stdout.Reset()
+	stderr.Reset()
+	if code := Auto(&stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the selection to run again, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "::group::Installing auto-tool\n") || !strings.Contains(stderr.String(), "::endgroup::\n") {
+		t.Errorf("Expected CI log groups, got:\n%s", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "::add-matcher::") {
+		t.Errorf("Expected the problem matcher to be registered, got:\n%s", stderr.String())
+	}
+	if data, _ := os.ReadFile(summary); !strings.Contains(string(data), "| ✅ | auto-tool | tools |") {
+		t.Errorf("Expected the results in the step summary, got:\n%s", data)
+	}
+}
+
+func TestSelfUpdateCheck(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	original, originalFetch := version.Version, fetchReleases
+	defer func() { version.Version, fetchReleases = original, originalFetch }()
+	fetchReleases = func() ([]github.Release, error) {
+		return []github.Release{{Version: "v1.3.0"}, {Version: "v1.4.0-rc.1", Prerelease: true}}, nil
+	}
+	
+	var stdout, stderr bytes.Buffer
+	if code := SelfUpdate([]string{"now"}, &stdout, &stderr); code != exitcode.Usage || !strings.Contains(stderr.String(), "Usage: boba self-update") {
+		t.Errorf("Expected arguments to print the usage, got %d: %s", code, stderr.String())
+	}
+	
+	version.Version = "v1.2.0"
+	stdout.Reset()
+	if code := SelfUpdate([]string{"--check"}, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "BOBA v1.3.0 is available") {
+		t.Errorf("Expected the stable release to be reported, got %d: %s", code, stdout.String())
+	}
+	
+	// Up to date, or a development build: nothing is replaced without --force
+	version.Version = "v1.3.0"
+	stdout.Reset()
+	if code := SelfUpdate(nil, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "latest stable release") {
+		t.Errorf("Expected the latest release to be reported, got %d: %s", code, stdout.String())
+	}
+	version.Version = "dev"
+	stdout.Reset()
+	if code := SelfUpdate(nil, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "development build") {
+		t.Errorf("Expected a development build not to be updated, got %d: %s", code, stdout.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/git/tool.yaml":                 "name: git\ndescription: Version control\n",
+		"tools/git/install.sh":                "#!/bin/bash\n",
+		"tools/git/uninstall.sh":              "#!/bin/bash\n",
+		"environments/shell/environment.yaml": "name: shell\ndescription: Shell\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\n",
+		"environments/shell/restore.sh":       "#!/bin/bash\n",
+	})
+	var stdout, stderr bytes.Buffer
+	if code := Validate([]string{dir}, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "0 errors, 0 warnings") {
+		t.Fatalf("Expected a clean repository, got %d:\n%s%s", code, stdout.String(), stderr.String())
+	}
+	
+	writeFiles(t, dir, map[string]string{"tools/node/tool.yaml": "name: node\ndependencies: [git, yarn]\n"})
+	stdout.Reset()
+	if code := Validate([]string{"--json", dir}, &stdout, &stderr); code != exitcode.Failure {
+		t.Fatalf("Expected the errors to fail validation, got %d", code)
+	}
+	var report parser.ValidationReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || report.Count(parser.ValidationError) != 2 {
+		t.Errorf("Expected the missing script and dependency, got %+v: %v", report, err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping PATH lookup test on Windows")
+	}
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/bash/tool.yaml":            "name: bash\nversion: \"5\"\n",
+		"tools/sh/tool.yaml":              "name: sh\n",
+		"tools/status-missing/tool.yaml":  "name: status-missing\n",
+		"tools/status-recorded/tool.yaml": "name: status-recorded\n",
+	})
+	configManager.RecordToolInstallation("bash", "4", "manual")
+	configManager.RecordToolInstallation("status-recorded", "latest", "manual")
+	configManager.RecordToolInstallation("status-orphan", "1.0", "manual")
+	
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	var stdout, stderr bytes.Buffer
+	if code := ws.status(true, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected status to succeed, got %d: %s", code, stderr.String())
+	}
+	var items []statusItem
+	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]string{}
+	for _, item := range items {
+		statuses[item.Name] = item.Status
+	}
+	expected := map[string]string{
+		"bash":            statusOutdated,
+		"sh":              statusInstalled,
+		"status-missing":  statusMissing,
+		"status-recorded": statusMissing,
+		"status-orphan":   statusOrphaned,
+	}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %q", name, status, statuses[name])
+		}
+	}
+	
+	stdout.Reset()
+	if code := ws.status(false, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "recorded as installed but not found on PATH") {
+		t.Errorf("Expected the table to explain the missing recorded tool, got %d:\n%s", code, stdout.String())
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	if code := workspaceExitCode(authError{errors.New("repository access failed")}); code != exitcode.Auth {
+		t.Errorf("Expected a rejected token to exit with %d, got %d", exitcode.Auth, code)
+	}
+	if code := workspaceExitCode(errors.New("no repository is set up yet")); code != exitcode.Failure {
+		t.Errorf("Expected a missing repository to exit with %d, got %d", exitcode.Failure, code)
+	}
+	
+	_, err := withDependencies("tool", []string{"app"}, []string{"app"}, func(name string) (string, []string) { return name, []string{"base"} })
+	if code := errorExitCode(err); code != exitcode.Dependency {
+		t.Errorf("Expected a missing dependency to exit with %d, got %d: %v", exitcode.Dependency, code, err)
+	}
+	
+	timedOut := &installer.InstallationResult{Error: installer.ErrTimeout}
+	if code := failureExitCode(timedOut, timedOut.Error); code != exitcode.Timeout {
+		t.Errorf("Expected a timeout to exit with %d, got %d", exitcode.Timeout, code)
+	}
+	failed := &installer.InstallationResult{ExitCode: 3, Error: errors.New("script execution failed with exit code 3")}
+	if code := failureExitCode(failed, failed.Error); code != exitcode.Script {
+		t.Errorf("Expected a failing script to exit with %d, got %d", exitcode.Script, code)
+	}
+	if code := failureExitCode(nil, errors.New("failed to download install script")); code != exitcode.Failure {
+		t.Errorf("Expected a failure before the script ran to exit with %d, got %d", exitcode.Failure, code)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := History([]string{"--sort", "size"}, &stdout, &stderr); code != exitcode.Usage {
+		t.Errorf("Expected an unknown order to be a usage error, got %d", code)
+	}
+	if code := History(nil, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "No installations recorded yet") {
+		t.Errorf("Expected an empty history, got %d: %s", code, stdout.String())
+	}
+	
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	configManager.RecordToolInstallationWithProvenance("rg", "14.1.0", "auto", nil)
+	configManager.RecordToolInstallationWithProvenance("go", "1.24.0", "manual", nil)
+	records := configManager.GetConfig().InstalledTools
+	older := records["go"]
+	older.InstallDate = older.InstallDate.Add(-48 * time.Hour)
+	records["go"] = older
+	if err := configManager.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	
+	stdout.Reset()
+	if code := History(nil, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the history to be listed, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "LAST UPDATE") || !strings.Contains(output, "14.1.0") || strings.Index(output, "rg") > strings.Index(output, "go ") {
+		t.Errorf("Expected the most recent install first, got:\n%s", output)
+	}
+	stdout.Reset()
+	History([]string{"--reverse", "--json"}, &stdout, &stderr)
+	var history []config.InstalledTool
+	if err := json.Unmarshal(stdout.Bytes(), &history); err != nil || len(history) != 2 || history[0].Name != "go" || history[0].InstallMethod != "manual" {
+		t.Errorf("Expected the oldest install first as JSON, got %+v (%v)", history, err)
+	}
+}
+
+
+func TestEnvShow(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := Env([]string{"show"}, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "apply an environment first") {
+		t.Errorf("Expected no definitions, got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	
+	os.WriteFile(filepath.Join(home, ".bashrc"), []byte("# >>> boba tool nvm >>>\nexport NVM_DIR=\"$HOME/.nvm\"\n# <<< boba tool nvm <<<\n"), 0644)
+	stdout.Reset()
+	if code := Env([]string{"show"}, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "NVM_DIR") || !strings.Contains(stdout.String(), "tool nvm") {
+		t.Errorf("Expected the tool's export, got %d: %s", code, stdout.String())
+	}
+	stdout.Reset()
+	Env([]string{"show", "--json"}, &stdout, &stderr)
+	var definitions []installer.ShellDefinition
+	if err := json.Unmarshal(stdout.Bytes(), &definitions); err != nil || len(definitions) != 1 || definitions[0].File != ".bashrc" || definitions[0].Line != 2 {
+		t.Errorf("Expected the export as JSON, got %+v (%v)", definitions, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := Search(nil, &stdout, &stderr); code != exitcode.Usage || !strings.Contains(stderr.String(), "Usage: boba search") {
+		t.Errorf("Expected a missing query to print the usage, got %d: %s", code, stderr.String())
+	}
+	
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/psql/tool.yaml":             "name: psql\ndescription: PostgreSQL client\ntags: [database]\n",
+		"tools/redis/tool.yaml":            "name: redis\ndescription: Key-value store\ntags: [database, cache]\n",
+		"tools/jq/tool.yaml":               "name: jq\ndescription: JSON processor\n",
+		"environments/db/environment.yaml": "name: db\ndescription: Database shell aliases\n",
+	})
+	ws, err := newLocalWorkspace(configManager, dir)
+	if err != nil {
+		t.Fatalf("Failed to open the workspace: %v", err)
+	}
+	defer ws.close()
+	
+	stdout.Reset()
+	if code := ws.search("database", false, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the search to succeed, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "database,cache") || !strings.Contains(output, "environment") || strings.Contains(output, "jq") {
+		t.Errorf("Expected the tagged tools and the environment, got:\n%s", output)
+	}
+	
+	stdout.Reset()
+	ws.search("postgres client", true, &stdout, &stderr)
+	var results []parser.SearchResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) != 1 || results[0].Name != "psql" {
+		t.Errorf("Expected psql as JSON, got %+v (%v)", results, err)
+	}
+	stdout.Reset()
+	ws.search("kubernetes", false, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "No tools or environments match") {
+		t.Errorf("Expected no matches, got %s", stdout.String())
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	oldHome := t.TempDir()
+	t.Setenv(config.HomeEnv, oldHome)
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	base, app := "migrate-base-"+suffix, "migrate-app-"+suffix
+	marker := filepath.Join(t.TempDir(), "env-applied")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tools/" + base + "/tool.yaml":        "name: " + base + "\n",
+		"tools/" + base + "/install.sh":       "#!/bin/bash\necho base\n",
+		"tools/" + app + "/tool.yaml":         "name: " + app + "\ndependencies: [" + base + "]\n",
+		"tools/" + app + "/install.sh":        "#!/bin/bash\necho app\n",
+		"tools/unused/tool.yaml":              "name: unused\n",
+		"environments/shell/environment.yaml": "name: shell\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\ntouch " + marker + "\n",
+	})
+	data, _ := json.Marshal(map[string]any{"local_repo_path": dir, "repository_url": "acme/boba-config", "script_env": map[string]string{"CORP_PROXY": "http://proxy"}})
+	os.WriteFile(filepath.Join(oldHome, "config.json"), data, 0644)
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	configManager.RecordToolInstallationWithProvenance(app, "latest", "manual", nil)
+	configManager.RecordToolInstallationWithProvenance("retired", "1.0", "auto", nil)
+	configManager.RecordEnvironmentApplied("shell")
+	configManager.Flush()
+	
+	// The lockfile holds the installed tools with their dependencies and the applied environments
+	path := filepath.Join(t.TempDir(), "move.json")
+	var stdout, stderr bytes.Buffer
+	if code := Migrate([]string{"export", "--output", path}, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the export to succeed, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "- retired: recorded as installed, no longer in the repository") {
+		t.Errorf("Expected the retired tool to be reported, got %s", stderr.String())
+	}
+	migration, err := config.ReadMigrationFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, entry := range migration.Lockfile.Entries {
+		entries = append(entries, entry.Name)
+	}
+	if strings.Join(entries, ",") != base+","+app+",shell" {
+		t.Errorf("Expected the lockfile in run order, got %v", entries)
+	}
+	if migration.Settings.LocalRepoPath != "" || migration.Settings.InstalledTools != nil || migration.Settings.ScriptEnv["CORP_PROXY"] != "http://proxy" {
+		t.Errorf("Expected only the portable settings, got %+v", migration.Settings)
+	}
+	
+	// The new machine imports the settings, keeps its own state and installs the lockfile
+	newHome := t.TempDir()
+	t.Setenv(config.HomeEnv, newHome)
+	data, _ = json.Marshal(map[string]string{"local_repo_path": dir})
+	os.WriteFile(filepath.Join(newHome, "config.json"), data, 0644)
+	stdout.Reset()
+	stderr.Reset()
+	if code := Migrate([]string{"import", "--refresh-index=false", path}, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected the import to succeed, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Step 3/3") || !strings.Contains(stdout.String(), "Restore finished: 3 succeeded, 0 failed") {
+		t.Errorf("Expected the restore progress, got:\n%s", stdout.String())
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("Expected the environment to be applied")
+	}
+	imported := config.NewConfigManager()
+	imported.LoadConfig()
+	if cfg := imported.GetConfig(); cfg.RepositoryURL != "acme/boba-config" || cfg.LocalRepoPath != dir || cfg.ScriptEnv["CORP_PROXY"] != "http://proxy" {
+		t.Errorf("Expected the settings imported and local_repo_path kept, got %+v", cfg)
+	}
+	if _, ok := imported.GetInstalledTool(app); !ok {
+		t.Errorf("Expected %s to be recorded as installed", app)
+	}
+	
+	// Scripts changed since the export are only installed when accepted
+	writeFiles(t, dir, map[string]string{"tools/" + app + "/install.sh": "#!/bin/bash\necho app v2\n"})
+	stderr.Reset()
+	if code := Migrate([]string{"import", "--refresh-index=false", path}, &stdout, &stderr); code != exitcode.Failure || !strings.Contains(stderr.String(), "--accept-changes") {
+		t.Errorf("Expected the changed lockfile to be refused, got %d: %s", code, stderr.String())
+	}
+	if code := Migrate([]string{"import", "--refresh-index=false", "--accept-changes", path}, &stdout, &stderr); code != exitcode.OK {
+		t.Errorf("Expected the accepted changes to install, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestMaintain(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := Maintain([]string{"now"}, &stdout, &stderr); code != exitcode.Usage || !strings.Contains(stderr.String(), "Usage: boba maintain") {
+		t.Errorf("Expected maintain with arguments to print its usage, got %d: %s", code, stderr.String())
+	}
+	if code := Maintain(nil, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "🧹 Maintenance: no stale cache files, no drift") {
+		t.Fatalf("Expected the first maintenance to run, got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	
+	stdout.Reset()
+	if code := Maintain(nil, &stdout, &stderr); code != exitcode.OK || !strings.Contains(stdout.String(), "not due until") {
+		t.Errorf("Expected maintenance not to be due again, got %d: %s", code, stdout.String())
+	}
+	stdout.Reset()
+	if code := Maintain([]string{"--force", "--json"}, &stdout, &stderr); code != exitcode.OK {
+		t.Fatalf("Expected a forced run, got %d: %s", code, stderr.String())
+	}
+	var summary config.MaintenanceSummary
+	if err := json.Unmarshal(stdout.Bytes(), &summary); err != nil || summary.At.IsZero() {
+		t.Errorf("Expected a JSON summary, got %q, %v", stdout.String(), err)
+	}
+}
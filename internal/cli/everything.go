@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"boba/internal/ci"
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// everythingOptions are the choices the Install Everything menu offers before a run
+type everythingOptions struct {
+	refreshIndex  bool
+	skipFailing   bool // Leave out the tools on the skip list or failing repeatedly
+	retryCooldown bool // Retry the tools whose install failed less than the cooldown ago
+	edited        string // How to settle home files edited since they were last applied: mine, repo or merge
+}
+
+// resolveEverything returns the tools and environments of an Install Everything run in
+// dependency order, like the UI resolves them, and the reason each left out tool or environment
+// is skipped
+func (w *workspace) resolveEverything(options everythingOptions, now time.Time) ([]parser.Tool, []parser.Environment, map[string]string, error) {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch tools: %w", err)
+	}
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch environments: %w", err)
+	}
+	
+	var selectedTools []parser.Tool
+	for _, tool := range tools {
+		if w.installEverything(tool) {
+			selectedTools = append(selectedTools, tool)
+		}
+	}
+	// Ephemeral sessions only change the shell configuration in $HOME when asked explicitly
+	skippedEnvironments := make(map[string]string)
+	var selectedEnvironments []parser.Environment
+	for _, env := range environments {
+		if w.applyEverything(env) && config.IsEphemeral() {
+			skippedEnvironments[env.Name] = "skipped, ephemeral session: apply it with boba env apply to change your shell configuration"
+		} else if w.applyEverything(env) {
+			selectedEnvironments = append(selectedEnvironments, env)
+		}
+	}
+	
+	skip := make(map[string]string)
+	if !options.retryCooldown {
+		for name, failedAt := range w.configManager.ToolsInCooldown(now) {
+			skip[name] = fmt.Sprintf("not retried, failed %s ago, waiting for the %s install cooldown",
+				now.Sub(failedAt).Round(time.Minute), w.configManager.GetInstallCooldown())
+		}
+	}
+	if options.skipFailing {
+		for _, name := range w.configManager.KnownFailingTools() {
+			skip[name] = "skipped, on the skip list or failing repeatedly"
+		}
+	}
+	for _, tool := range selectedTools {
+		if w.engine.NeedsSudo(tool) {
+			skip[tool.Name] = "skipped, needs a system-wide install (scope: system) and BOBA is set to never use sudo"
+		}
+	}
+	
+	resolver := installer.NewDependencyResolver()
+	selectedTools, skipped := resolver.LeaveOutTools(selectedTools, skip)
+	for name, reason := range skipped {
+		if _, direct := skip[name]; !direct {
+			skipped[name] = "skipped, " + reason
+		}
+	}
+	for name, reason := range skippedEnvironments {
+		skipped[name] = reason
+	}
+	orderedTools, orderedEnvironments, err := resolver.GetInstallationOrder(selectedTools, selectedEnvironments)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	return orderedTools, orderedEnvironments, skipped, nil
+}
+
+// installAll runs Install Everything: the tools, then the environments. Like the UI it keeps going
+// after a failure and reports every result, and it exits with the code of the first failure.
+func (w *workspace) installAll(options everythingOptions, stdout, stderr io.Writer) int {
+	// Managed machines only run approved plan files
+	if w.engine.ApprovalRequired() {
+		fmt.Fprintln(stderr, approvalRequiredMessage)
+		return exitcode.Failure
+	}
+	
+	w.engine.BeginRun()
+	tools, environments, skipped, err := w.resolveEverything(options, time.Now())
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	names := make([]string, 0, len(skipped))
+	for name := range skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(stdout, "- %s: %s\n", name, skipped[name])
+		w.addResult(name, "skipped", false, skipped[name])
+	}
+	
+	if len(tools)+len(environments) == 0 {
+		fmt.Fprintln(stdout, "Nothing to install: Install Everything includes no tools or environments")
+		return exitcode.OK
+	}
+	if err := w.checkFileConflicts(environments); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.checkEditedFiles(environments, options.edited); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	w.saveRestorePoint("Before Install Everything", environments, stderr)
+	return w.runAll("Install Everything", tools, environments, len(skipped), options.refreshIndex, stdout, stderr)
+}
+
+// runAll installs the tools, then applies the environments, in the given order. It keeps going
+// after a failure, reports every result and returns the exit code of the first failure.
+func (w *workspace) runAll(title string, tools []parser.Tool, environments []parser.Environment, skipped int, refreshIndex bool, stdout, stderr io.Writer) int {
+	total := len(tools) + len(environments)
+	if err := w.engine.BeginBatch(title); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	defer w.engine.EndBatch()
+	if refreshIndex && len(tools) > 0 {
+		if _, err := w.engine.EnsurePackageIndexFresh(tools); err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+		}
+	}
+	
+	// The exit code is the one of the first failure
+	failed := 0
+	code := exitcode.OK
+	for i, tool := range tools {
+		endGroup := ci.Detect().BeginGroup(stdout, "Installing "+tool.Name)
+		fmt.Fprintf(stdout, "[%d/%d] Installing %s...\n", i+1, total, tool.Name)
+		result, err := w.engine.InstallTool(tool)
+		success := err == nil && result.Success
+		if success && reportDryRun(stdout, result) {
+			w.addResult(tool.Name, "tools", true, "dry run")
+			endGroup()
+			continue
+		}
+		w.recordToolRun(tool.Name, success, result)
+		if !success {
+			reportFailure(stderr, tool.Name, result, err)
+			w.addResult(tool.Name, "tools", false, failureMessage(result, err))
+			if failed == 0 {
+				code = failureExitCode(result, err)
+			}
+			failed++
+			endGroup()
+			continue
+		}
+		
+		version := tool.Version
+		if version == "" {
+			version = "latest"
+		}
+		w.configManager.RecordToolInstallationWithProvenance(tool.Name, version, "auto", result.Provenance)
+		w.addResult(tool.Name, "tools", true, "")
+		if result.Satisfied {
+			fmt.Fprintf(stdout, "✓ %s %s\n", tool.Name, installer.SatisfiedOutput)
+		} else {
+			fmt.Fprintf(stdout, "✓ %s installed successfully\n", tool.Name)
+		}
+		reportDetails(stdout, result)
+		endGroup()
+	}
+	
+	for i, env := range environments {
+		endGroup := ci.Detect().BeginGroup(stdout, "Applying "+env.Name)
+		fmt.Fprintf(stdout, "[%d/%d] Applying %s...\n", len(tools)+i+1, total, env.Name)
+		result, err := w.engine.ApplyEnvironment(env)
+		if err != nil || !result.Success {
+			reportFailure(stderr, env.Name, result, err)
+			w.addResult(env.Name, "environments", false, failureMessage(result, err))
+			if failed == 0 {
+				code = failureExitCode(result, err)
+			}
+			failed++
+			endGroup()
+			continue
+		}
+		if reportDryRun(stdout, result) {
+			w.addResult(env.Name, "environments", true, "dry run")
+			endGroup()
+			continue
+		}
+		w.configManager.RecordEnvironmentApplied(env.Name)
+		w.addResult(env.Name, "environments", true, "")
+		fmt.Fprintf(stdout, "✓ %s applied successfully\n", env.Name)
+		reportDetails(stdout, result)
+		endGroup()
+	}
+	
+	fmt.Fprintf(stdout, "%s finished: %d succeeded, %d failed, %d skipped\n", title, total-failed, failed, skipped)
+	if failed > 0 || w.engine.DryRun() {
+		return code
+	}
+	
+	// Record the plan of the successful run, so the UI can tell when the next one changes nothing
+	if plan, err := w.engine.BuildPlan(tools, environments); err == nil {
+		w.configManager.SaveLastPlan(plan)
+	}
+	return exitcode.OK
+}
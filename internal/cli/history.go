@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// History implements `boba history [--sort installed|updated|name] [--reverse] [--json]` and returns the exit code
+func History(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	order := flags.String("sort", config.HistoryByInstallDate, "order of the records: installed, updated or name")
+	reverse := flags.Bool("reverse", false, "reverse the order, e.g. oldest first")
+	asJSON := flags.Bool("json", false, "print the records as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba history [--sort installed|updated|name] [--reverse] [--json]")
+		fmt.Fprintln(stderr, "Lists the tools BOBA installed on this machine, most recently installed first.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	history, err := configManager.InstallHistory(*order)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Usage
+	}
+	if *reverse {
+		for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+			history[i], history[j] = history[j], history[i]
+		}
+	}
+	return writeHistory(history, *asJSON, stdout, stderr)
+}
+
+// writeHistory prints the installation records as a table or as JSON
+func writeHistory(history []config.InstalledTool, asJSON bool, stdout, stderr io.Writer) int {
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(history); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	if len(history) == 0 {
+		fmt.Fprintln(stdout, "No installations recorded yet")
+		return exitcode.OK
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tVERSION\tMETHOD\tINSTALLED\tLAST UPDATE")
+	for _, record := range history {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", record.Name, dash(record.Version), dash(record.InstallMethod), historyDate(record.InstallDate), historyDate(record.LastUpdateDate))
+	}
+	table.Flush()
+	return exitcode.OK
+}
+
+// historyDate formats a record date in local time, or "-" when it is unset
+func historyDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// Maintain implements `boba maintain [--force] [--json]` and returns the exit code
+func Maintain(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("maintain", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	force := flags.Bool("force", false, "run even when maintenance is not due or turned off")
+	asJSON := flags.Bool("json", false, "print the summary as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba maintain [--force] [--json]")
+		fmt.Fprintln(stderr, "Prunes stale caches, rotates the log, refreshes the clone and rechecks drift once maintenance is due,")
+		fmt.Fprintln(stderr, "weekly unless maintenance_interval_days says otherwise. Meant to run from cron or a systemd timer.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	configManager.LoadCredentials()
+	if !*force && !configManager.MaintenanceDue(time.Now()) {
+		if interval := configManager.MaintenanceInterval(); interval == 0 {
+			fmt.Fprintln(stdout, "Maintenance is turned off (maintenance_interval_days is negative): pass --force to run it anyway.")
+		} else {
+			next := configManager.GetConfig().LastMaintenance.Add(interval)
+			fmt.Fprintf(stdout, "Maintenance is not due until %s.\n", next.Format("2006-01-02 15:04"))
+		}
+		return exitcode.OK
+	}
+
+	summary := configManager.RunMaintenance(maintenanceCloneDir(configManager))
+	code := exitcode.OK
+	if len(summary.Errors) > 0 {
+		code = exitcode.Failure
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summary); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return code
+	}
+	for _, line := range summary.Lines() {
+		fmt.Fprintln(stdout, line)
+	}
+	return code
+}
+
+// maintenanceCloneDir returns the local clone of the configured repository, or "" in local mode,
+// whose directory is the user's to update, and before a repository is set up
+func maintenanceCloneDir(configManager *config.ConfigManager) string {
+	if configManager.GetConfig().LocalRepoPath != "" || !configManager.IsConfigured() {
+		return ""
+	}
+	client, err := newRepositoryBackend(configManager)
+	if err != nil {
+		return ""
+	}
+	dir, err := client.GetCloneTargetDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// Metrics implements `boba metrics [--sort time|disk] [--json]` and returns the exit code
+func Metrics(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the metrics as JSON")
+	order := flags.String("sort", "time", "order of the tools: time (slowest first) or disk (largest first)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba metrics [--sort time|disk] [--json]")
+		fmt.Fprintln(stderr, "Shows the install time, download volume and retries of each tool across the recorded runs, slowest first,")
+		fmt.Fprintln(stderr, "and the disk space the files recorded for each installed tool take.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	if *order != "time" && *order != "disk" {
+		fmt.Fprintf(stderr, "Error: unknown order %q: use time or disk\n", *order)
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	records, err := configManager.LoadMetrics()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	metrics := config.WithDiskUsage(config.AggregateMetrics(records), configManager.ToolDiskUsage())
+	if *order == "disk" {
+		config.SortMetricsByDisk(metrics)
+	}
+	return writeMetrics(metrics, *asJSON, stdout, stderr)
+}
+
+// writeMetrics prints the aggregated metrics as a table or as JSON
+func writeMetrics(metrics []config.ToolMetrics, asJSON bool, stdout, stderr io.Writer) int {
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(metrics); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	if len(metrics) == 0 {
+		fmt.Fprintln(stdout, "No installs recorded yet")
+		return exitcode.OK
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tRUNS\tFAILURES\tRETRIES\tP95 TIME\tMEAN TIME\tDOWNLOAD\tDISK")
+	for _, m := range metrics {
+		disk := "-"
+		if m.DiskBytes > 0 {
+			disk = config.FormatBytes(m.DiskBytes)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", m.Tool, m.Runs, m.Failures, m.Retries,
+			m.P95Duration.Round(time.Millisecond), m.MeanDuration.Round(time.Millisecond), config.FormatBytes(m.DownloadBytes), disk)
+	}
+	table.Flush()
+	return exitcode.OK
+}
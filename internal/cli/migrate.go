@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// stdin is where boba migrate import --token-stdin reads the token from
+var stdin io.Reader = os.Stdin
+
+// Migrate implements the move to a new machine and returns the exit code:
+//
+//	boba migrate export [--output file]
+//	boba migrate import [--token-stdin] [--accept-changes] [--refresh-index=false] <file>
+func Migrate(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: boba migrate export [--output file]")
+		fmt.Fprintln(stderr, "       boba migrate import [--token-stdin] [--accept-changes] [--refresh-index=false] <file>")
+		fmt.Fprintln(stderr, "export writes the settings of this machine and a lockfile of its installed tools and applied environments.")
+		fmt.Fprintln(stderr, "import, on the new machine, imports the settings, checks the GitHub, GitLab or Bitbucket token and installs the lockfile.")
+		fmt.Fprintln(stderr, "Tokens are never exported.")
+	}
+	if len(args) == 0 {
+		usage()
+		return exitcode.Usage
+	}
+	
+	switch args[0] {
+	case "export":
+		flags := flag.NewFlagSet("migrate export", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		output := flags.String("output", config.MigrationFile, "write the migration to this `file`")
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitcode.Usage
+		}
+		if flags.NArg() != 0 {
+			usage()
+			return exitcode.Usage
+		}
+		
+		ws, err := openWorkspace()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return workspaceExitCode(err)
+		}
+		defer ws.close()
+		return ws.exportMigration(*output, stdout, stderr)
+	
+	case "import":
+		flags := flag.NewFlagSet("migrate import", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		tokenStdin := flags.Bool("token-stdin", false, "read the GitHub, GitLab or Bitbucket token (username:app_password) from stdin and save it")
+		acceptChanges := flags.Bool("accept-changes", false, "install the current scripts of tools and environments that changed since the export")
+		refreshIndex := flags.Bool("refresh-index", true, "refresh the system package index once before installing")
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitcode.Usage
+		}
+		if flags.NArg() != 1 {
+			usage()
+			return exitcode.Usage
+		}
+		return importMigration(flags.Arg(0), *tokenStdin, *acceptChanges, *refreshIndex, stdout, stderr)
+	}
+	
+	usage()
+	return exitcode.Usage
+}
+
+// exportMigration writes the portable settings and the lockfile of this machine to path
+func (w *workspace) exportMigration(path string, stdout, stderr io.Writer) int {
+	lockfile, err := w.lockfile(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	hostname, _ := os.Hostname()
+	migration := config.Migration{Hostname: hostname, Settings: w.configManager.PortableSettings(), Lockfile: lockfile}
+	if err := config.WriteMigrationFile(path, migration); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	tools, environments := countEntries(lockfile)
+	fmt.Fprintf(stdout, "Exported the settings and a lockfile of %d tools and %d environments to %s\n", tools, environments, path)
+	fmt.Fprintf(stdout, "The token is not included. Copy the file to the new machine and run: boba migrate import %s\n", path)
+	return exitcode.OK
+}
+
+// lockfile returns the plan that reinstalls this machine: the repository's tools recorded as
+// installed and environments recorded as applied, with their dependencies, in run order.
+// Recorded names that are no longer in the repository are listed on stderr.
+func (w *workspace) lockfile(stderr io.Writer) (config.ExecutionPlan, error) {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		return config.ExecutionPlan{}, fmt.Errorf("failed to fetch tools: %w", err)
+	}
+	environments, err := w.repoParser.GetEnvironments()
+	if err != nil {
+		return config.ExecutionPlan{}, fmt.Errorf("failed to fetch environments: %w", err)
+	}
+	cfg := w.configManager.GetConfig()
+	
+	inRepository := make(map[string]bool)
+	for _, tool := range tools {
+		inRepository[tool.Name] = true
+	}
+	var toolNames []string
+	for name := range cfg.InstalledTools {
+		if inRepository[name] {
+			toolNames = append(toolNames, name)
+		} else {
+			fmt.Fprintf(stderr, "- %s: recorded as installed, no longer in the repository\n", name)
+		}
+	}
+	for _, env := range environments {
+		inRepository["environment/"+env.Name] = true
+	}
+	var environmentNames []string
+	for name := range cfg.AppliedEnvironments {
+		if inRepository["environment/"+name] {
+			environmentNames = append(environmentNames, name)
+		} else {
+			fmt.Fprintf(stderr, "- %s: recorded as applied, no longer in the repository\n", name)
+		}
+	}
+	sort.Strings(toolNames)
+	sort.Strings(environmentNames)
+	
+	selectedTools, err := withDependencies("tool", toolNames, tools, func(t parser.Tool) (string, []string) { return t.Name, t.Dependencies })
+	if err != nil {
+		return config.ExecutionPlan{}, err
+	}
+	selectedEnvironments, err := withDependencies("environment", environmentNames, environments, func(e parser.Environment) (string, []string) { return e.Name, e.Dependencies })
+	if err != nil {
+		return config.ExecutionPlan{}, err
+	}
+	orderedTools, orderedEnvironments, err := installer.NewDependencyResolver().GetInstallationOrder(selectedTools, selectedEnvironments)
+	if err != nil {
+		return config.ExecutionPlan{}, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	return w.engine.BuildPlan(orderedTools, orderedEnvironments)
+}
+
+// importMigration runs the three steps of a move on the new machine: import the settings,
+// check the GitHub, GitLab or Bitbucket token, then install the lockfile
+func importMigration(path string, tokenStdin, acceptChanges, refreshIndex bool, stdout, stderr io.Writer) int {
+	migration, err := config.ReadMigrationFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	from := migration.Hostname
+	if from == "" {
+		from = "the old machine"
+	}
+	fmt.Fprintf(stdout, "Step 1/3: importing the settings exported from %s on %s\n", from, migration.ExportedAt.Local().Format("2006-01-02 15:04"))
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	configManager.LoadCredentials()
+	if err := configManager.ImportSettings(migration.Settings); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	fmt.Fprintf(stdout, "✓ Settings imported: repository %s\n", dash(migration.Settings.RepositoryURL))
+	
+	host := configManager.RepositoryHostName()
+	fmt.Fprintf(stdout, "Step 2/3: authenticating with %s\n", host)
+	if tokenStdin {
+		token, err := bufio.NewReader(stdin).ReadString('\n')
+		token = strings.TrimSpace(token)
+		if token == "" {
+			fmt.Fprintf(stderr, "Error: no token on stdin (%v)\n", err)
+			return exitcode.Usage
+		}
+		if err := configManager.SetRepositoryToken(token); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+	}
+	if configManager.NeedsRepositoryToken() && !configManager.HasRepositoryToken() {
+		fmt.Fprintf(stderr, "Error: no %s token on this machine: pipe one with --token-stdin, set %s or run boba once to sign in, then run boba migrate import %s again\n", host, configManager.RepositoryTokenEnv(), path)
+		return exitcode.Auth
+	}
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	fmt.Fprintln(stdout, "✓ Repository reachable")
+	
+	fmt.Fprintln(stdout, "Step 3/3: installing the lockfile")
+	return ws.restoreLockfile(migration.Lockfile, acceptChanges, refreshIndex, stdout, stderr)
+}
+
+// restoreLockfile installs the tools and applies the environments of a lockfile in its order.
+// Operations whose version or scripts changed in the repository since the export are refused
+// unless acceptChanges is set.
+func (w *workspace) restoreLockfile(lockfile config.ExecutionPlan, acceptChanges, refreshIndex bool, stdout, stderr io.Writer) int {
+	if len(lockfile.Entries) == 0 {
+		fmt.Fprintln(stdout, "Nothing to install: the lockfile is empty")
+		return exitcode.OK
+	}
+	if w.engine.ApprovalRequired() {
+		fmt.Fprintln(stderr, approvalRequiredMessage)
+		return exitcode.Failure
+	}
+	
+	w.engine.BeginRun()
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	environments, err := w.repoParser.GetEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	var missing []string
+	lockedTools, lockedEnvironments, err := w.engine.ResolvePlan(lockfile, tools, environments)
+	if err != nil && acceptChanges {
+		lockedTools, lockedEnvironments, missing, err = lockedByName(lockfile, tools, environments)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		if !acceptChanges {
+			fmt.Fprintln(stderr, "Run the import again with --accept-changes to install the current scripts instead.")
+		}
+		return exitcode.Failure
+	}
+	for _, name := range missing {
+		fmt.Fprintf(stdout, "- %s: skipped, no longer in the repository\n", name)
+		w.addResult(name, "skipped", false, "no longer in the repository")
+	}
+	if err := w.checkFileConflicts(lockedEnvironments); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	return w.runAll("Restore", lockedTools, lockedEnvironments, len(missing), refreshIndex, stdout, stderr)
+}
+
+// lockedByName looks up the operations of a lockfile by name only. It returns the names of the
+// ones no longer in the repository separately.
+func lockedByName(lockfile config.ExecutionPlan, tools []parser.Tool, environments []parser.Environment) ([]parser.Tool, []parser.Environment, []string, error) {
+	toolsByName := make(map[string]parser.Tool, len(tools))
+	for _, tool := range tools {
+		toolsByName[tool.Name] = tool
+	}
+	environmentsByName := make(map[string]parser.Environment, len(environments))
+	for _, env := range environments {
+		environmentsByName[env.Name] = env
+	}
+	
+	var lockedTools []parser.Tool
+	var lockedEnvironments []parser.Environment
+	var missing []string
+	for _, entry := range lockfile.Entries {
+		tool, isTool := toolsByName[entry.Name]
+		env, isEnvironment := environmentsByName[entry.Name]
+		switch {
+		case entry.Kind == config.PlanEntryTool && isTool:
+			lockedTools = append(lockedTools, tool)
+		case entry.Kind == config.PlanEntryEnvironment && isEnvironment:
+			lockedEnvironments = append(lockedEnvironments, env)
+		default:
+			missing = append(missing, entry.Name)
+		}
+	}
+	if len(lockedTools)+len(lockedEnvironments) == 0 {
+		return nil, nil, nil, fmt.Errorf("none of the tools and environments of the lockfile are in the repository")
+	}
+	return lockedTools, lockedEnvironments, missing, nil
+}
+
+// countEntries counts the tools and environments of a plan
+func countEntries(plan config.ExecutionPlan) (int, int) {
+	tools := 0
+	for _, entry := range plan.Entries {
+		if entry.Kind == config.PlanEntryTool {
+			tools++
+		}
+	}
+	return tools, len(plan.Entries) - tools
+}
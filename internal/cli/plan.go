@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os/user"
+	"sort"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// Plan implements the plan export and approval commands and returns the exit code:
+//
+//	boba plan export [--output file] [--json] [--skip-failing] [--retry-cooldown]
+//	boba plan keygen <key file>
+//	boba plan approve --key <key file> [--by name] <plan file>
+//	boba plan verify <plan file>
+func Plan(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: boba plan export [--output file] [--json] [--skip-failing] [--retry-cooldown]")
+		fmt.Fprintln(stderr, "       boba plan keygen <key file>")
+		fmt.Fprintln(stderr, "       boba plan approve --key <key file> [--by name] <plan file>")
+		fmt.Fprintln(stderr, "       boba plan verify <plan file>")
+		fmt.Fprintln(stderr, "export resolves the next Install Everything run with the current overrides, without running it,")
+		fmt.Fprintln(stderr, "and prints the ordered tools and environments with their versions and scripts for review.")
+		fmt.Fprintln(stderr, "keygen, approve and verify approve exported plans for machines that set require_plan_approval in their policy file.")
+	}
+	if len(args) == 0 {
+		usage()
+		return exitcode.Usage
+	}
+	
+	switch args[0] {
+	case "export":
+		flags := flag.NewFlagSet("plan export", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		output := flags.String("output", "", "write the plan to this `file` (JSON when it ends in .json, YAML otherwise) instead of stdout")
+		asJSON := flags.Bool("json", false, "print the plan as JSON instead of YAML")
+		skipFailing := flags.Bool("skip-failing", false, "leave out the tools on the skip list or failing repeatedly")
+		retryCooldown := flags.Bool("retry-cooldown", false, "include the tools still in their install cooldown")
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitcode.Usage
+		}
+		if flags.NArg() != 0 {
+			usage()
+			return exitcode.Usage
+		}
+		
+		ws, err := openWorkspace()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return workspaceExitCode(err)
+		}
+		defer ws.close()
+		return ws.exportPlan(everythingOptions{skipFailing: *skipFailing, retryCooldown: *retryCooldown}, *output, *asJSON, stdout, stderr)
+	
+	case "keygen":
+		if len(args) != 2 {
+			usage()
+			return exitcode.Usage
+		}
+		publicKey, err := config.GenerateApprovalKey(args[1])
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		fmt.Fprintf(stdout, "Private key written to %s: keep it secret.\n", args[1])
+		fmt.Fprintf(stdout, "Add the public key to plan_approval_keys in %s on managed machines:\n", config.PolicyPath())
+		fmt.Fprintln(stdout, publicKey)
+		return exitcode.OK
+	
+	case "approve":
+		flags := flag.NewFlagSet("plan approve", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		keyPath := flags.String("key", "", "private key `file` created with boba plan keygen")
+		approvedBy := flags.String("by", currentUser(), "`name` recorded as the approver")
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitcode.Usage
+		}
+		if *keyPath == "" || flags.NArg() != 1 {
+			usage()
+			return exitcode.Usage
+		}
+		
+		path := flags.Arg(0)
+		plan, err := config.ReadPlanFile(path)
+		if err == nil {
+			err = config.SignPlan(&plan, *keyPath, *approvedBy)
+		}
+		if err == nil {
+			err = config.WritePlanFile(path, plan)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		fmt.Fprintf(stdout, "Approved %s (%d operations) with key %s\n", path, len(plan.Entries), plan.Approval.KeyID)
+		return exitcode.OK
+	
+	case "verify":
+		if len(args) != 2 {
+			usage()
+			return exitcode.Usage
+		}
+		policy, err := config.LoadPolicy()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		plan, err := config.ReadPlanFile(args[1])
+		if err == nil {
+			err = config.VerifyPlanApproval(plan, policy.PlanApprovalKeys)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		fmt.Fprintf(stdout, "%s is approved with key %s\n", args[1], plan.Approval.KeyID)
+		return exitcode.OK
+	}
+	
+	usage()
+	return exitcode.Usage
+}
+
+// exportPlan resolves the next Install Everything run like boba install --all does and writes
+// its plan to path, or to stdout when path is empty. What is left out is listed on stderr, so
+// stdout only carries the plan.
+func (w *workspace) exportPlan(options everythingOptions, path string, asJSON bool, stdout, stderr io.Writer) int {
+	tools, environments, skipped, err := w.resolveEverything(options, time.Now())
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return errorExitCode(err)
+	}
+	names := make([]string, 0, len(skipped))
+	for name := range skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(stderr, "- %s: %s\n", name, skipped[name])
+	}
+	if err := w.checkFileConflicts(environments); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	plan, err := w.engine.BuildPlan(tools, environments)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if len(plan.Entries) == 0 {
+		fmt.Fprintln(stderr, "Error: nothing to install: the plan is empty")
+		return exitcode.Failure
+	}
+	
+	if path != "" {
+		if err := config.WritePlanFile(path, plan); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		fmt.Fprintf(stdout, "Plan of %d operations written to %s\n", len(plan.Entries), path)
+		return exitcode.OK
+	}
+	data, err := config.EncodePlan(plan, asJSON)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	stdout.Write(data)
+	return exitcode.OK
+}
+
+// currentUser names the approver by default
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+)
+
+// Reset implements `boba reset <scope> --yes` and returns the exit code
+func Reset(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("reset", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	yes := flags.Bool("yes", false, "confirm the reset without a prompt")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba reset credentials|caches|overrides|everything --yes")
+		fmt.Fprintln(stderr, "Backs up the BOBA directory to a .tar.gz archive, then wipes:")
+		for _, scope := range config.WipeScopes {
+			fmt.Fprintf(stderr, "  %-12s %s\n", scope, scope.Description())
+		}
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	scope, err := config.ParseWipeScope(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Usage
+	}
+	if !*yes {
+		fmt.Fprintf(stderr, "Error: boba reset %s removes %s: pass --yes to confirm\n", scope, scope.Description())
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	backupPath, err := configManager.Wipe(scope)
+	if backupPath != "" {
+		fmt.Fprintf(stdout, "Backed up to %s\n", backupPath)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	fmt.Fprintf(stdout, "Removed %s\n", scope.Description())
+	return exitcode.OK
+}
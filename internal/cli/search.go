@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"boba/internal/exitcode"
+	"boba/internal/parser"
+)
+
+// Search implements `boba search [--live] [--json] <query>...` and returns the exit code
+func Search(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	live := flags.Bool("live", false, "read the repository instead of the cached listing")
+	asJSON := flags.Bool("json", false, "print the matches as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba search [--live] [--json] <query>...")
+		fmt.Fprintln(stderr, "Finds the tools and environments whose name, tags or description contain every word of the query, best matches first.")
+		fmt.Fprintln(stderr, "The listing cached by boba sync or the UI is used while it is less than a day old.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	if !*live {
+		ws.repoParser.PreferDiskCache(parser.DefaultCacheMaxAge)
+	}
+	return ws.search(query, *asJSON, stdout, stderr)
+}
+
+// search prints the tools and environments matching the query
+func (w *workspace) search(query string, asJSON bool, stdout, stderr io.Writer) int {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	environments, err := w.repoParser.GetEnvironments()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch environments: %v\n", err)
+		return exitcode.Failure
+	}
+	results := parser.Search(tools, environments, query)
+	
+	if asJSON {
+		if results == nil {
+			results = []parser.SearchResult{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	if len(results) == 0 {
+		fmt.Fprintf(stdout, "No tools or environments match %q\n", query)
+		return exitcode.OK
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "KIND\tNAME\tTAGS\tDESCRIPTION")
+	for _, result := range results {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", result.Kind, result.Name, dash(strings.Join(result.Tags, ",")), result.Description)
+	}
+	table.Flush()
+	return exitcode.OK
+}
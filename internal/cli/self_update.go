@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/installer"
+	"boba/internal/version"
+)
+
+// fetchReleases lists the BOBA releases, replaced in tests
+var fetchReleases = github.FetchBobaReleases
+
+// SelfUpdate implements `boba self-update [--check] [--force]` and returns the exit code. It
+// replaces the running binary with the newest release of the configured update channel.
+func SelfUpdate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	check := flags.Bool("check", false, "only report whether a newer release is available")
+	force := flags.Bool("force", false, "install the latest release even if it isn't newer, e.g. over a development build")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba self-update [--check] [--force]")
+		fmt.Fprintln(stderr, "Replaces this binary with the newest BOBA release of the update channel set in BOBA Updates,")
+		fmt.Fprintln(stderr, "after verifying the release archive against its checksums.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	channel := configManager.GetUpdateChannel()
+	releases, err := fetchReleases()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	release := github.LatestRelease(releases, channel == config.UpdateChannelEdge)
+	if release == nil {
+		fmt.Fprintf(stderr, "Error: no %s release of BOBA was found\n", channel)
+		return exitcode.Failure
+	}
+	
+	// Development builds are never updated unless forced, like in the UI
+	newer := false
+	if version.IsRelease() {
+		cmp, err := version.Compare(release.Version, version.Version)
+		newer = err == nil && cmp > 0
+	}
+	switch {
+	case newer:
+		fmt.Fprintf(stdout, "BOBA %s is available (running %s, %s channel)\n", release.Version, version.Version, channel)
+	case !version.IsRelease():
+		fmt.Fprintf(stdout, "Running a development build (%s): the latest %s release is %s\n", version.Version, channel, release.Version)
+	default:
+		fmt.Fprintf(stdout, "BOBA %s is the latest %s release\n", version.Version, channel)
+	}
+	if *check || (!newer && !*force) {
+		return exitcode.OK
+	}
+	
+	systemInstaller, err := installer.NewSystemInstaller()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	fmt.Fprintf(stdout, "Updating %s to %s...\n", systemInstaller.BinaryPath(), release.Version)
+	if err := systemInstaller.SelfUpdate(release.Assets); err != nil {
+		fmt.Fprintf(stderr, "Error: update failed: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	// Record the new binary so the integrity check doesn't report the update as tampering
+	if checksum, err := installer.FileChecksum(systemInstaller.BinaryPath()); err == nil {
+		configManager.RecordBinaryChecksum(systemInstaller.BinaryPath(), checksum)
+	}
+	fmt.Fprintf(stdout, "✓ Updated to %s\n", release.Version)
+	return exitcode.OK
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"boba/internal/exitcode"
+)
+
+// Tool states of `boba status`
+const (
+	statusInstalled = "installed" // Found on this machine, at the version of the repository
+	statusMissing   = "missing"   // In the repository, not found on this machine
+	statusOutdated  = "outdated"  // Installed at another version than the repository's
+	statusOrphaned  = "orphaned"  // Recorded as installed, no longer in the repository
+)
+
+// statusItem is a tool of `boba status`
+type statusItem struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	RecordedVersion string `json:"recorded_version,omitempty"` // Version in the installation records
+	RepoVersion     string `json:"repo_version,omitempty"`     // Version in tool.yaml
+	Scope           string `json:"scope,omitempty"`            // Where the tool was found: user or system
+	Note            string `json:"note,omitempty"`
+}
+
+// Status implements `boba status [--json]` and returns the exit code
+func Status(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the status as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba status [--json]")
+		fmt.Fprintln(stderr, "Compares the tools of the repository with the tools installed on this machine.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	return ws.status(*asJSON, stdout, stderr)
+}
+
+// status joins the tools of the repository with the installation records and the tools found
+// on this machine
+func (w *workspace) status(asJSON bool, stdout, stderr io.Writer) int {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	
+	records := w.configManager.GetConfig().InstalledTools
+	inRepository := make(map[string]bool, len(tools))
+	var items []statusItem
+	for _, tool := range tools {
+		inRepository[tool.Name] = true
+		item := statusItem{Name: tool.Name, Status: statusMissing, RepoVersion: tool.Version}
+		record, recorded := records[tool.Name]
+		if recorded {
+			item.RecordedVersion = record.Version
+		}
+		
+		scope, found := w.engine.FindInstalledScope(tool)
+		switch {
+		case !found && recorded:
+			item.Note = "recorded as installed but not found on PATH"
+		case !found:
+		case recorded && tool.Version != "" && record.Version != tool.Version:
+			item.Status = statusOutdated
+			item.Scope = scope.Scope
+		default:
+			item.Status = statusInstalled
+			item.Scope = scope.Scope
+			if !recorded {
+				item.Note = "installed outside BOBA"
+			}
+		}
+		items = append(items, item)
+	}
+	
+	var orphaned []string
+	for name := range records {
+		if !inRepository[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	for _, name := range orphaned {
+		items = append(items, statusItem{Name: name, Status: statusOrphaned, RecordedVersion: records[name].Version, Note: "no longer in the repository"})
+	}
+	
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(items); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		return exitcode.OK
+	}
+	
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tSTATUS\tRECORDED\tREPOSITORY\tSCOPE\tNOTE")
+	for _, item := range items {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", item.Name, item.Status, dash(item.RecordedVersion), dash(item.RepoVersion), dash(item.Scope), item.Note)
+	}
+	table.Flush()
+	return exitcode.OK
+}
+
+// dash returns the value, or "-" when it is empty
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"boba/internal/exitcode"
+)
+
+// Sync implements `boba sync` and returns the exit code
+func Sync(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba sync")
+		fmt.Fprintln(stderr, "Fetches the full tools and environments listing of the repository into the cache the UI reads on startup.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	return ws.sync(stdout, stderr)
+}
+
+// sync writes the repository listing to the disk cache and records the sync time
+func (w *workspace) sync(stdout, stderr io.Writer) int {
+	if dir := w.configManager.GetConfig().LocalRepoPath; dir != "" {
+		fmt.Fprintf(stdout, "Local mode reads %s directly, nothing to sync.\n", dir)
+		return exitcode.OK
+	}
+	
+	result, err := w.repoParser.Sync()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := w.configManager.UpdateLastSync(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	fmt.Fprintf(stdout, "Synced %d tools and %d environments to %s\n", result.Tools, result.Environments, result.Path)
+	return exitcode.OK
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"boba/internal/exitcode"
+	"boba/internal/parser"
+)
+
+// Uninstall implements `boba uninstall <tool>...` and returns the exit code
+func Uninstall(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("uninstall", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba uninstall <tool>...")
+		fmt.Fprintln(stderr, "Runs the uninstall script of each tool and forgets its installation record.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	ws, err := openWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return workspaceExitCode(err)
+	}
+	defer ws.close()
+	return ws.uninstall(flags.Args(), stdout, stderr)
+}
+
+// uninstall uninstalls the named tools in order, stopping at the first failure. Tools that
+// depend on one of them are left in place and only mentioned.
+func (w *workspace) uninstall(names []string, stdout, stderr io.Writer) int {
+	tools, err := w.repoParser.GetTools()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to fetch tools: %v\n", err)
+		return exitcode.Failure
+	}
+	byName := make(map[string]parser.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	
+	// Every tool is checked before anything runs
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			fmt.Fprintf(stderr, "Error: tool %s is not in the repository\n", name)
+			return exitcode.Failure
+		}
+		if !tool.HasUninstall() {
+			fmt.Fprintf(stderr, "Error: %s can't be uninstalled: it is marked uninstallable: false or has no uninstall script\n", name)
+			return exitcode.Failure
+		}
+	}
+	
+	if err := w.engine.BeginBatch("uninstall " + strings.Join(names, ", ")); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	defer w.engine.EndBatch()
+	
+	for _, name := range names {
+		tool := byName[name]
+		fmt.Fprintf(stdout, "Uninstalling %s...\n", tool.Name)
+		result, err := w.engine.UninstallTool(tool)
+		if err != nil || !result.Success {
+			reportFailure(stderr, tool.Name, result, err)
+			return failureExitCode(result, err)
+		}
+		if reportDryRun(stdout, result) {
+			continue
+		}
+		if err := w.configManager.RemoveInstalledTool(tool.Name); err != nil {
+			fmt.Fprintf(stderr, "Warning: failed to remove the installation record of %s: %v\n", tool.Name, err)
+		}
+		fmt.Fprintf(stdout, "✓ %s uninstalled successfully\n", tool.Name)
+		reportDetails(stdout, result)
+		
+		if dependents := w.installedDependents(tool.Name, tools); len(dependents) > 0 {
+			fmt.Fprintf(stdout, "  Note: %s depend on %s\n", strings.Join(dependents, ", "), tool.Name)
+		}
+	}
+	return exitcode.OK
+}
+
+// installedDependents returns the installed tools that list name as a dependency
+func (w *workspace) installedDependents(name string, tools []parser.Tool) []string {
+	var dependents []string
+	for _, tool := range tools {
+		if _, installed := w.configManager.GetInstalledTool(tool.Name); !installed {
+			continue
+		}
+		for _, dependency := range tool.Dependencies {
+			if dependency == name {
+				dependents = append(dependents, tool.Name)
+				break
+			}
+		}
+	}
+	return dependents
+}
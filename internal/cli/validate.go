@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/parser"
+)
+
+// Validate implements `boba validate [--json] [dir]` and returns the exit code
+func Validate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba validate [--json] [dir]")
+		fmt.Fprintln(stderr, "Checks every tool and environment of the configured repository, or of the local clone in dir,")
+		fmt.Fprintln(stderr, "for missing fields and scripts, unknown dependencies and dependency cycles.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	var repoParser *parser.RepositoryParser
+	if flags.NArg() == 1 {
+		repoParser = parser.NewRepositoryParserFromSource(github.NewLocalRepository(flags.Arg(0)))
+	} else {
+		// Not openWorkspace: an incompatible repository can still be validated
+		ws, err := connectWorkspace()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return workspaceExitCode(err)
+		}
+		defer ws.close()
+		repoParser = ws.repoParser
+	}
+	
+	report, err := repoParser.Validate()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+	} else {
+		report.Write(stdout)
+	}
+	if report.HasErrors() {
+		return exitcode.Failure
+	}
+	return exitcode.OK
+}
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/installer"
+	"boba/internal/log"
+	"boba/internal/parser"
+)
+
+// workspace is the configured repository, with the parser and installation engine reading it
+type workspace struct {
+	configManager *config.ConfigManager
+	repoParser    *parser.RepositoryParser
+	engine        *installer.InstallationEngine
+	trusted       bool // Tools and environments of a quarantined repository are never installed automatically
+	report        *config.RunReport // Collects the outcome of each tool and environment when set
+}
+
+// addResult adds the outcome of a tool or environment to the report, when one is collected
+func (w *workspace) addResult(name, phase string, success bool, message string) {
+	if w.report == nil {
+		return
+	}
+	w.report.Results = append(w.report.Results, config.RunResult{Name: name, Phase: phase, Success: success, Message: message})
+}
+
+// saveRestorePoint saves the managed state before a run that applies the given environments,
+// unless it is a dry run. A failure is reported and the run goes on without a restore point.
+func (w *workspace) saveRestorePoint(name string, environments []parser.Environment, stderr io.Writer) {
+	if w.engine.DryRun() {
+		return
+	}
+	if _, err := w.configManager.CreateRestorePoint(name, parser.HomeFiles(environments)); err != nil {
+		fmt.Fprintf(stderr, "Warning: no restore point saved: %v\n", err)
+	}
+}
+
+// checkFileConflicts refuses a run whose environments manage the same home files as each other,
+// or as an environment already applied, until an owner or merge is chosen for each file
+func (w *workspace) checkFileConflicts(run []parser.Environment) error {
+	if len(run) == 0 {
+		return nil
+	}
+	environments, err := w.repoParser.FetchEnvironments()
+	if err != nil {
+		return fmt.Errorf("failed to fetch environments: %w", err)
+	}
+	var applied []parser.Environment
+	for _, env := range environments {
+		if _, ok := w.configManager.GetEnvironmentAppliedDate(env.Name); ok {
+			applied = append(applied, env)
+		}
+	}
+	if err := w.engine.CheckFileConflicts(run, applied); err != nil {
+		return fmt.Errorf("%w\nRun boba env resolve <file> <environment|merge> for each file", err)
+	}
+	return nil
+}
+
+// checkEditedFiles refuses a run that would overwrite home files the user edited since they were
+// last applied, unless the resolution (mine, repo or merge) settles them
+func (w *workspace) checkEditedFiles(run []parser.Environment, resolution string) error {
+	var editedErr *installer.EditedFilesError
+	if err := w.engine.CheckEditedFiles(run); !errors.As(err, &editedErr) {
+		return err
+	}
+	if resolution == "" {
+		return fmt.Errorf("%w\nPass --edited mine, repo or merge, or apply from boba to compare the changes", editedErr)
+	}
+	for _, file := range editedErr.Files {
+		w.engine.SetEditResolution(file.File, resolution)
+	}
+	return nil
+}
+
+// validEditResolution checks the value of --edited
+func validEditResolution(resolution string) error {
+	switch resolution {
+	case "", installer.EditKeepMine, installer.EditTakeRepo, installer.EditMerge:
+		return nil
+	}
+	return fmt.Errorf("unknown --edited %q: use %s, %s or %s", resolution, installer.EditKeepMine, installer.EditTakeRepo, installer.EditMerge)
+}
+
+// authError marks a workspace that could not be opened because the repository host rejected the
+// token or the repository can't be reached with it
+type authError struct {
+	err error
+}
+
+func (e authError) Error() string {
+	return e.err.Error()
+}
+
+func (e authError) Unwrap() error {
+	return e.err
+}
+
+// workspaceExitCode returns the exit code for a workspace that could not be opened
+func workspaceExitCode(err error) int {
+	if errors.As(err, &authError{}) {
+		return exitcode.Auth
+	}
+	return exitcode.Failure
+}
+
+// openWorkspace connects to the repository the interactive UI uses and fails early when
+// the repository's boba.yaml requirements do not match this machine
+func openWorkspace() (*workspace, error) {
+	ws, err := connectWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.checkRepository(); err != nil {
+		ws.close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// connectWorkspace opens the directory of local_repo_path when it is set, the configured
+// GitHub, GitLab or Bitbucket repository or the clone of the SSH remote otherwise
+func connectWorkspace() (*workspace, error) {
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		return nil, err
+	}
+	configManager.LoadCredentials()
+	cfg := configManager.GetConfig()
+	
+	if cfg.LocalRepoPath != "" {
+		return newLocalWorkspace(configManager, cfg.LocalRepoPath)
+	}
+	if !configManager.IsConfigured() {
+		return nil, fmt.Errorf("no repository is set up yet: run boba to configure one")
+	}
+	if configManager.NeedsRepositoryToken() {
+		if configManager.GetRepositoryProvider() != config.RepositoryProviderGitHub && !configManager.HasRepositoryToken() {
+			return nil, authError{fmt.Errorf("%s authentication required: %s", configManager.RepositoryHostName(), configManager.RepositoryCredentialsHint())}
+		}
+		if !configManager.HasRepositoryToken() {
+			return nil, authError{fmt.Errorf("GitHub authentication required: run boba to set up your token")}
+		}
+		if !strings.Contains(cfg.RepositoryURL, "/") && configManager.RepositoryFromEnvironment() {
+			return nil, fmt.Errorf("%s=%q has no owner: use owner/repo", config.RepoEnv, cfg.RepositoryURL)
+		}
+		if !strings.Contains(cfg.RepositoryURL, "/") {
+			return nil, fmt.Errorf("repository %q has no owner yet: run boba once to resolve it", cfg.RepositoryURL)
+		}
+	}
+	
+	client, err := newRepositoryBackend(configManager)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.TestConnection(); err != nil {
+		return nil, authError{fmt.Errorf("repository access failed: %w", err)}
+	}
+	
+	// The git source reads the clone: bring it up to date, or keep reading the last one offline
+	if remote, ok := client.(*github.GitRemote); ok {
+		if err := remote.Update(); errors.Is(err, github.ErrStaleClone) {
+			log.Warn("Reading the last clone of the repository", "remote", remote.Remote(), "error", err)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to clone %s: %w", remote.Remote(), err)
+		}
+	}
+	
+	engine := installer.NewInstallationEngine(client)
+	engine.ApplySettings(configManager)
+	if cloneDir, err := client.GetCloneTargetDir(); err == nil {
+		if _, err := os.Stat(cloneDir); err == nil {
+			engine.SetRepositoryDir(cloneDir)
+		}
+	}
+	
+	// Commands always read the repository live, but keep the listing the UI reads up to date
+	repoParser := parser.NewRepositoryParserFromSource(client)
+	repoParser.UseDiskCache(configManager.GetRepositoryCachePath(), client.GetFullRepoName(), 0)
+	
+	return &workspace{
+		configManager: configManager,
+		repoParser:    repoParser,
+		engine:        engine,
+		trusted:       configManager.IsRepositoryTrusted(client.GetFullRepoName()),
+	}, nil
+}
+
+// newRepositoryBackend creates the client for the configured repository on GitHub, GitLab or
+// Bitbucket, or for the clone of the SSH remote
+func newRepositoryBackend(configManager *config.ConfigManager) (github.RepositoryBackend, error) {
+	cfg := configManager.GetConfig()
+	if configManager.UsesGitSource() {
+		remote, err := github.NewGitRemote(cfg.RepositoryURL, cfg.SSHKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		return remote, nil
+	}
+	if provider := configManager.GetRepositoryProvider(); provider != config.RepositoryProviderGitHub {
+		return github.NewHostedBackend(provider, configManager.RepositoryToken(), cfg.RepositoryURL, github.DefaultCloneOptions())
+	}
+	
+	owner, repo, err := github.ParseRepositoryURL(cfg.RepositoryURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL format: %w", err)
+	}
+	return github.NewGitHubClient(configManager.GetCredentials().GitHubToken, owner, repo), nil
+}
+
+// newLocalWorkspace reads the repository from a directory on disk
+func newLocalWorkspace(configManager *config.ConfigManager, dir string) (*workspace, error) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("local repository %s is not a directory: fix local_repo_path in config.json", dir)
+	}
+	
+	localRepo := github.NewLocalRepository(dir)
+	engine := installer.NewInstallationEngine(localRepo)
+	engine.ApplySettings(configManager)
+	engine.SetRepositoryDir(dir)
+	
+	return &workspace{
+		configManager: configManager,
+		repoParser:    parser.NewRepositoryParserFromSource(localRepo),
+		engine:        engine,
+		trusted:       true,
+	}, nil
+}
+
+// checkRepository validates the requirements declared in the repository section of boba.yaml
+func (w *workspace) checkRepository() error {
+	info, err := w.repoParser.RepositoryInfo()
+	if err != nil || info == nil {
+		return nil
+	}
+	if err := w.engine.CheckRepository(info); err != nil {
+		if info.Contact != "" {
+			return fmt.Errorf("incompatible repository: %w (maintainers: %s)", err, info.Contact)
+		}
+		return fmt.Errorf("incompatible repository: %w", err)
+	}
+	return nil
+}
+
+// close removes the engine's temporary files and persists the batched installation records
+func (w *workspace) close() {
+	w.engine.Cleanup()
+	w.configManager.Flush()
+}
+
+// installEverything reports whether Install Everything includes the tool: auto_install tools of a
+// trusted repository, unless an override says otherwise
+func (w *workspace) installEverything(tool parser.Tool) bool {
+	if enabled, ok := w.configManager.GetToolOverride(tool.Name); ok {
+		return enabled
+	}
+	return tool.AutoInstall && w.trusted
+}
+
+// applyEverything reports whether Install Everything applies the environment
+func (w *workspace) applyEverything(env parser.Environment) bool {
+	if enabled, ok := w.configManager.GetEnvironmentOverride(env.Name); ok {
+		return enabled
+	}
+	return env.AutoApply && w.trusted
+}
@@ -0,0 +1,37 @@
+package config
+
+import "time"
+
+// RecordEnvironmentApplied records that an environment has been applied
+func (cm *ConfigManager) RecordEnvironmentApplied(envName string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if cm.config.AppliedEnvironments == nil {
+		cm.config.AppliedEnvironments = make(map[string]time.Time)
+	}
+	cm.config.AppliedEnvironments[envName] = time.Now()
+	return cm.saveDeferred()
+}
+
+// GetEnvironmentAppliedDate returns when the environment was last applied, and whether it is
+// recorded as applied
+func (cm *ConfigManager) GetEnvironmentAppliedDate(envName string) (time.Time, bool) {
+	if cm.config == nil {
+		return time.Time{}, false
+	}
+	applied, ok := cm.config.AppliedEnvironments[envName]
+	return applied, ok
+}
+
+// RemoveAppliedEnvironment clears the applied record of an environment, after it was restored
+func (cm *ConfigManager) RemoveAppliedEnvironment(envName string) error {
+	if cm.config == nil || cm.config.AppliedEnvironments == nil {
+		return nil // Nothing to remove
+	}
+	
+	delete(cm.config.AppliedEnvironments, envName)
+	return cm.saveDeferred()
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// PlanApproval is an admin's signature of the entries of an exported plan
+type PlanApproval struct {
+	KeyID      string    `json:"key_id" yaml:"key_id"`       // Fingerprint of the public key that signed
+	Signature  string    `json:"signature" yaml:"signature"` // Base64 Ed25519 signature of the plan hash
+	ApprovedBy string    `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+	ApprovedAt time.Time `json:"approved_at" yaml:"approved_at"`
+}
+
+// approvalMessage is what an approval signs: the plan hash, bound to its purpose
+func approvalMessage(plan ExecutionPlan) []byte {
+	return []byte("boba-plan-approval:" + plan.Hash())
+}
+
+// KeyFingerprint identifies a base64 public key in approvals and error messages
+func KeyFingerprint(publicKey string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(publicKey)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateApprovalKey writes a new private signing key to path (readable by its owner only) and
+// returns the public key to list in plan_approval_keys
+func GenerateApprovalKey(path string) (string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(privateKey)+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+// SignPlan approves the entries of a plan with the private key read from keyPath
+func SignPlan(plan *ExecutionPlan, keyPath, approvedBy string) error {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("%s is not a BOBA approval key", keyPath)
+	}
+	privateKey := ed25519.PrivateKey(key)
+	publicKey := base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	
+	plan.Approval = &PlanApproval{
+		KeyID:      KeyFingerprint(publicKey),
+		Signature:  base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, approvalMessage(*plan))),
+		ApprovedBy: approvedBy,
+		ApprovedAt: time.Now().UTC(),
+	}
+	return nil
+}
+
+// VerifyPlanApproval checks that the plan carries a signature of its current entries by one of the
+// trusted public keys. Verification is offline: only the keys listed in the machine policy are used.
+func VerifyPlanApproval(plan ExecutionPlan, trustedKeys []string) error {
+	if plan.Approval == nil || plan.Approval.Signature == "" {
+		return fmt.Errorf("the plan is not approved: ask an admin to approve it with boba plan approve")
+	}
+	signature, err := base64.StdEncoding.DecodeString(plan.Approval.Signature)
+	if err != nil {
+		return fmt.Errorf("the plan approval is malformed: %w", err)
+	}
+	
+	for _, trusted := range trustedKeys {
+		if KeyFingerprint(trusted) != plan.Approval.KeyID {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(trusted))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("approval key %s in plan_approval_keys is not a valid public key", plan.Approval.KeyID)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(key), approvalMessage(plan), signature) {
+			return fmt.Errorf("the plan approval does not match its entries: the plan changed after it was approved")
+		}
+		return nil
+	}
+	return fmt.Errorf("the plan was approved with key %s, which is not in plan_approval_keys", plan.Approval.KeyID)
+}
@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// DefaultInstallCooldown is how long automatic runs wait before retrying a tool whose install failed
+const DefaultInstallCooldown = 15 * time.Minute
+
+// GetInstallCooldown returns the configured install cooldown (install_cooldown_minutes; negative disables it)
+func (cm *ConfigManager) GetInstallCooldown() time.Duration {
+	if cm.config == nil || cm.config.CooldownMinutes == 0 {
+		return DefaultInstallCooldown
+	}
+	if cm.config.CooldownMinutes < 0 {
+		return 0
+	}
+	return time.Duration(cm.config.CooldownMinutes) * time.Minute
+}
+
+// ToolsInCooldown returns the tools whose last install failed less than the cooldown ago,
+// with the time of that failure
+func (cm *ConfigManager) ToolsInCooldown(now time.Time) map[string]time.Time {
+	cooling := make(map[string]time.Time)
+	cooldown := cm.GetInstallCooldown()
+	if cm.config == nil || cooldown == 0 {
+		return cooling
+	}
+	for tool, failedAt := range cm.config.Health.LastFailures {
+		if now.Sub(failedAt) < cooldown {
+			cooling[tool] = failedAt
+		}
+	}
+	return cooling
+}
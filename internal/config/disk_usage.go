@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// DiskUsage is the space the files recorded in a tool's provenance take now
+type DiskUsage struct {
+	Bytes   int64 `json:"bytes"`
+	Files   int   `json:"files"`             // Recorded files still present
+	Missing int   `json:"missing,omitempty"` // Recorded files removed since the install
+}
+
+// DiskUsage measures the executables and created files recorded in the provenance. A symlink counts
+// as a link, so an executable linking into a tracked directory is not counted twice.
+func (p *ToolProvenance) DiskUsage() DiskUsage {
+	var usage DiskUsage
+	if p == nil {
+		return usage
+	}
+	seen := make(map[string]bool)
+	for _, path := range append(append([]string{}, p.BinaryPaths...), p.CreatedFiles...) {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		info, err := os.Lstat(path)
+		if err != nil {
+			usage.Missing++
+			continue
+		}
+		usage.Files++
+		usage.Bytes += info.Size()
+	}
+	return usage
+}
+
+// ToolDiskUsage measures the recorded files of each installed tool, by tool. Tools installed
+// before provenance tracking, or without recorded files, are left out.
+func (cm *ConfigManager) ToolDiskUsage() map[string]DiskUsage {
+	usage := make(map[string]DiskUsage)
+	if cm.config == nil {
+		return usage
+	}
+	for name, tool := range cm.config.InstalledTools {
+		if tool.Provenance == nil || len(tool.Provenance.BinaryPaths)+len(tool.Provenance.CreatedFiles) == 0 {
+			continue
+		}
+		usage[name] = tool.Provenance.DiskUsage()
+	}
+	return usage
+}
+
+// WithDiskUsage sets the disk usage of each tool in the metrics, adding the installed tools with
+// recorded files but no install in the metrics history
+func WithDiskUsage(metrics []ToolMetrics, usage map[string]DiskUsage) []ToolMetrics {
+	listed := make(map[string]bool)
+	for i := range metrics {
+		listed[metrics[i].Tool] = true
+		metrics[i].DiskBytes = usage[metrics[i].Tool].Bytes
+	}
+	var added []ToolMetrics
+	for name, tool := range usage {
+		if !listed[name] {
+			added = append(added, ToolMetrics{Tool: name, DiskBytes: tool.Bytes})
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Tool < added[j].Tool })
+	return append(metrics, added...)
+}
+
+// SortMetricsByDisk orders the metrics by the space each tool takes, largest first
+func SortMetricsByDisk(metrics []ToolMetrics) {
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].DiskBytes > metrics[j].DiskBytes
+	})
+}
+
+// FormatBytes formats a byte count, e.g. 2.0 KiB
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,34 @@
+package config
+
+import "os"
+
+// Environment variables that take precedence over the stored configuration, for containers and
+// CI where BOBA can't be set up interactively. Their values are never written to disk.
+const (
+	TokenEnv          = "BOBA_GITHUB_TOKEN"    // GitHub token used instead of credentials.json
+	GitLabTokenEnv    = "BOBA_GITLAB_TOKEN"    // GitLab token used instead of credentials.json
+	BitbucketTokenEnv = "BOBA_BITBUCKET_TOKEN" // Bitbucket username:app_password used instead of credentials.json
+	RepoEnv           = "BOBA_REPO"            // Configuration repository used instead of repository_url
+	ConfigDirEnv      = "BOBA_CONFIG_DIR"      // BOBA directory used instead of ~/.boba; BOBA_HOME still wins
+)
+
+// ActiveEnvironmentOverrides returns the override variables that are set, in a fixed order
+func ActiveEnvironmentOverrides() []string {
+	var active []string
+	for _, name := range []string{TokenEnv, GitLabTokenEnv, BitbucketTokenEnv, RepoEnv, ConfigDirEnv} {
+		if os.Getenv(name) != "" {
+			active = append(active, name)
+		}
+	}
+	return active
+}
+
+// RepositoryFromEnvironment reports whether the repository comes from BOBA_REPO
+func (cm *ConfigManager) RepositoryFromEnvironment() bool {
+	return cm.repoOverride != ""
+}
+
+// TokenFromEnvironment reports whether the GitHub token comes from BOBA_GITHUB_TOKEN
+func (cm *ConfigManager) TokenFromEnvironment() bool {
+	return cm.tokenOverride != ""
+}
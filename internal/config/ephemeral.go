@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// HomeEnv overrides the BOBA directory (~/.boba by default) holding the configuration,
+// credentials, records and repository clones
+const HomeEnv = "BOBA_HOME"
+
+// ephemeralEnv marks an ephemeral session, for BOBA and the processes it starts
+const ephemeralEnv = "BOBA_EPHEMERAL"
+
+// StartEphemeral starts an ephemeral session: all state is kept in a new temporary directory
+// instead of $HOME, and is gone once EndEphemeral removes it
+func StartEphemeral() (string, error) {
+	dir, err := os.MkdirTemp("", "boba-ephemeral-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the ephemeral directory: %w", err)
+	}
+	os.Setenv(HomeEnv, dir)
+	os.Setenv(ephemeralEnv, "1")
+	return dir, nil
+}
+
+// EndEphemeral removes the directory of an ephemeral session
+func EndEphemeral(dir string) error {
+	os.Unsetenv(HomeEnv)
+	os.Unsetenv(ephemeralEnv)
+	return os.RemoveAll(dir)
+}
+
+// IsEphemeral reports whether BOBA runs in an ephemeral session. Environments are not applied
+// by Install Everything then, since they change the shell configuration in $HOME: they only
+// run when applied explicitly.
+func IsEphemeral() bool {
+	return os.Getenv(ephemeralEnv) != ""
+}
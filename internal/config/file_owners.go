@@ -0,0 +1,46 @@
+package config
+
+// FileMergeStrategy resolves a conflict over a home file by keeping what each environment adds
+// to it, instead of one environment owning it
+const FileMergeStrategy = "merge"
+
+// GetFileOwner returns how a conflict over a home file (.zshrc) was resolved: the environment
+// owning it, or FileMergeStrategy
+func (cm *ConfigManager) GetFileOwner(file string) (string, bool) {
+	if cm.config == nil {
+		return "", false
+	}
+	owner, ok := cm.config.FileOwners[file]
+	return owner, ok
+}
+
+// GetFileOwners returns the resolved conflicts, by home file
+func (cm *ConfigManager) GetFileOwners() map[string]string {
+	owners := make(map[string]string)
+	if cm.config != nil {
+		for file, owner := range cm.config.FileOwners {
+			owners[file] = owner
+		}
+	}
+	return owners
+}
+
+// SetFileOwner resolves a conflict over a home file with the environment owning it or
+// FileMergeStrategy. An empty owner clears the resolution.
+func (cm *ConfigManager) SetFileOwner(file, owner string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if owner == "" {
+		delete(cm.config.FileOwners, file)
+		return cm.SaveConfig()
+	}
+	if cm.config.FileOwners == nil {
+		cm.config.FileOwners = make(map[string]string)
+	}
+	
+	cm.config.FileOwners[file] = owner
+	return cm.SaveConfig()
+}
@@ -0,0 +1,98 @@
+package config
+
+import "time"
+
+// HealthStats are local-only counters (never sent anywhere) used to suggest fixes on startup
+type HealthStats struct {
+	RunInProgress         bool                 `json:"run_in_progress,omitempty"`         // Set while BOBA runs; still set on the next start after a crash
+	ConsecutiveFailedRuns int                  `json:"consecutive_failed_runs,omitempty"` // Runs in a row that crashed or exited with an error
+	TokenFailures         int                  `json:"token_failures,omitempty"`          // Consecutive failed GitHub connections with the saved token
+	ScriptFailures        map[string]int       `json:"script_failures,omitempty"`         // Consecutive failed installs per tool
+	LastFailures          map[string]time.Time `json:"last_failures,omitempty"`           // When each failing tool last failed, for the install cooldown
+}
+
+// ensureHealthConfig makes sure the config and its script failure counters exist
+func (cm *ConfigManager) ensureHealthConfig() {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if cm.config.Health.ScriptFailures == nil {
+		cm.config.Health.ScriptFailures = make(map[string]int)
+	}
+	if cm.config.Health.LastFailures == nil {
+		cm.config.Health.LastFailures = make(map[string]time.Time)
+	}
+}
+
+// GetHealth returns a copy of the health counters
+func (cm *ConfigManager) GetHealth() HealthStats {
+	if cm.config == nil {
+		return HealthStats{}
+	}
+	
+	health := cm.config.Health
+	health.ScriptFailures = make(map[string]int, len(cm.config.Health.ScriptFailures))
+	for tool, failures := range cm.config.Health.ScriptFailures {
+		health.ScriptFailures[tool] = failures
+	}
+	health.LastFailures = make(map[string]time.Time, len(cm.config.Health.LastFailures))
+	for tool, failedAt := range cm.config.Health.LastFailures {
+		health.LastFailures[tool] = failedAt
+	}
+	return health
+}
+
+// BeginRun marks the start of a BOBA session. A previous session that never reached EndRun crashed
+// and counts as a failed run.
+func (cm *ConfigManager) BeginRun() error {
+	cm.ensureHealthConfig()
+	if cm.config.Health.RunInProgress {
+		cm.config.Health.ConsecutiveFailedRuns++
+	}
+	cm.config.Health.RunInProgress = true
+	return cm.SaveConfig()
+}
+
+// EndRun marks the end of a BOBA session, resetting the failed run counter after a clean exit
+func (cm *ConfigManager) EndRun(failed bool) error {
+	cm.ensureHealthConfig()
+	cm.config.Health.RunInProgress = false
+	if failed {
+		cm.config.Health.ConsecutiveFailedRuns++
+	} else {
+		cm.config.Health.ConsecutiveFailedRuns = 0
+	}
+	return cm.SaveConfig()
+}
+
+// RecordTokenResult counts consecutive failed GitHub connections with the saved token
+func (cm *ConfigManager) RecordTokenResult(ok bool) error {
+	cm.ensureHealthConfig()
+	if ok {
+		if cm.config.Health.TokenFailures == 0 {
+			return nil
+		}
+		cm.config.Health.TokenFailures = 0
+	} else {
+		cm.config.Health.TokenFailures++
+	}
+	return cm.saveDeferred()
+}
+
+// RecordScriptResult counts consecutive failed installs of a tool
+func (cm *ConfigManager) RecordScriptResult(toolName string, ok bool) error {
+	cm.ensureHealthConfig()
+	if ok {
+		if _, failing := cm.config.Health.ScriptFailures[toolName]; !failing {
+			return nil
+		}
+		delete(cm.config.Health.ScriptFailures, toolName)
+		delete(cm.config.Health.LastFailures, toolName)
+	} else {
+		cm.config.Health.ScriptFailures[toolName]++
+		cm.config.Health.LastFailures[toolName] = time.Now()
+	}
+	return cm.saveDeferred()
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Orders of the installation history
+const (
+	HistoryByInstallDate = "installed" // Most recently installed first
+	HistoryByLastUpdate  = "updated"   // Most recently updated first; never updated tools use their install date
+	HistoryByName        = "name"      // Alphabetical
+)
+
+// HistoryOrders lists the orders of the installation history, in the order the UI cycles through them
+var HistoryOrders = []string{HistoryByInstallDate, HistoryByLastUpdate, HistoryByName}
+
+// LastActivity returns when the tool was last updated, or installed when it never was
+func (t InstalledTool) LastActivity() time.Time {
+	if t.LastUpdateDate.After(t.InstallDate) {
+		return t.LastUpdateDate
+	}
+	return t.InstallDate
+}
+
+// InstallHistory returns the installation records sorted by the given order
+func (cm *ConfigManager) InstallHistory(order string) ([]InstalledTool, error) {
+	records := cm.GetAllInstalledTools()
+	history := make([]InstalledTool, 0, len(records))
+	for name, record := range records {
+		if record.Name == "" {
+			record.Name = name
+		}
+		history = append(history, record)
+	}
+	
+	var less func(a, b InstalledTool) bool
+	switch order {
+	case HistoryByInstallDate, "":
+		less = func(a, b InstalledTool) bool { return a.InstallDate.After(b.InstallDate) }
+	case HistoryByLastUpdate:
+		less = func(a, b InstalledTool) bool { return a.LastActivity().After(b.LastActivity()) }
+	case HistoryByName:
+		less = func(a, b InstalledTool) bool { return a.Name < b.Name }
+	default:
+		return nil, fmt.Errorf("unknown history order %q: use installed, updated or name", order)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		if less(history[i], history[j]) {
+			return true
+		}
+		if less(history[j], history[i]) {
+			return false
+		}
+		return history[i].Name < history[j].Name
+	})
+	return history, nil
+}
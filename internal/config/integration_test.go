@@ -111,6 +111,11 @@ func TestConfigManagerIntegration(t *testing.T) {
 		t.Error("Git should be in enabled tools")
 	}
 	
+	// Override toggles are saved in batches: flush before another instance reads the file
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Failed to flush config: %v", err)
+	}
+	
 	// Test persistence by creating a new ConfigManager instance
 	cm2 := NewConfigManager()
 	
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the results of one phase of the run
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is one tool or environment of the run
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the run report as JUnit XML, one test suite per phase and one test case per
+// tool or environment, so CI systems provisioning agents with BOBA can show per-tool results
+func WriteJUnit(w io.Writer, report RunReport) error {
+	suites := junitTestSuites{Name: "boba"}
+	suiteIndex := make(map[string]int)
+	timestamp := report.FinishedAt.UTC().Format("2006-01-02T15:04:05")
+	
+	for _, result := range report.Results {
+		phase := result.Phase
+		if phase == "" {
+			phase = "results"
+		}
+		index, ok := suiteIndex[phase]
+		if !ok {
+			index = len(suites.Suites)
+			suiteIndex[phase] = index
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: phase, Timestamp: timestamp})
+		}
+		
+		testCase := junitTestCase{Name: result.Name, ClassName: "boba." + phase}
+		if result.Success {
+			testCase.SystemOut = result.Message
+		} else {
+			testCase.Failure = &junitFailure{Message: firstLine(result.Message), Text: result.Message}
+			suites.Suites[index].Failures++
+			suites.Failures++
+		}
+		suites.Suites[index].Cases = append(suites.Suites[index].Cases, testCase)
+		suites.Suites[index].Tests++
+		suites.Tests++
+	}
+	
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJUnitFile writes the run report as JUnit XML to path, creating its directory
+func WriteJUnitFile(path string, report RunReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create JUnit report directory: %w", err)
+	}
+	
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := WriteJUnit(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GetJUnitReportPath returns where runs are exported as JUnit XML, or "" when export is off
+func (cm *ConfigManager) GetJUnitReportPath() string {
+	if cm.config == nil {
+		return ""
+	}
+	return cm.config.JUnitReportPath
+}
+
+// firstLine returns the first non-empty line of a message
+func firstLine(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"boba/internal/github"
+	"boba/internal/log"
+)
+
+// DefaultMaintenanceDays is how often maintenance runs when maintenance_interval_days is not set
+const DefaultMaintenanceDays = 7
+
+// cacheMaxAge is how long a cache file may go unmodified before maintenance removes it
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// MaintenanceSummary is the outcome of a maintenance run, shown as a notification
+type MaintenanceSummary struct {
+	At          time.Time `json:"at"`
+	PrunedFiles int       `json:"pruned_files"`
+	PrunedBytes int64     `json:"pruned_bytes"`
+	LogRotated  bool      `json:"log_rotated"`
+	Clone       string    `json:"clone,omitempty"`  // Outcome of the clone refresh, empty without a clone
+	Drift       []string  `json:"drift,omitempty"`  // Recorded executables and managed files changed outside BOBA
+	Errors      []string  `json:"errors,omitempty"` // Steps that failed; the others still ran
+}
+
+// GetLogsDir returns the directory of the log files
+func (cm *ConfigManager) GetLogsDir() string {
+	return filepath.Join(cm.configDir, "logs")
+}
+
+// MaintenanceInterval returns the time between maintenance runs, or 0 when
+// maintenance_interval_days turns it off
+func (cm *ConfigManager) MaintenanceInterval() time.Duration {
+	days := cm.GetConfig().MaintenanceDays
+	switch {
+	case days < 0:
+		return 0
+	case days == 0:
+		days = DefaultMaintenanceDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// MaintenanceDue reports whether maintenance is on and its interval passed since the last run
+func (cm *ConfigManager) MaintenanceDue(now time.Time) bool {
+	interval := cm.MaintenanceInterval()
+	return interval > 0 && now.Sub(cm.GetConfig().LastMaintenance) >= interval
+}
+
+// RunMaintenance prunes the cache files unused for 30 days, rotates the log file, fast-forwards
+// the clone in cloneDir when there is one and lists the drift, then records the run. A clone with
+// local changes is left alone.
+func (cm *ConfigManager) RunMaintenance(cloneDir string) MaintenanceSummary {
+	summary := MaintenanceSummary{At: time.Now()}
+	fail := func(step string, err error) {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", step, err))
+	}
+
+	var err error
+	if summary.PrunedFiles, summary.PrunedBytes, err = cm.PruneCaches(cacheMaxAge, summary.At); err != nil {
+		fail("cache", err)
+	}
+	if summary.LogRotated, err = log.Rotate(cm.GetLogsDir()); err != nil {
+		fail("logs", err)
+	}
+	if cloneDir != "" {
+		if _, statErr := os.Stat(cloneDir); statErr == nil {
+			result, err := github.SyncRepository(cloneDir, github.SyncFastForward)
+			switch {
+			case errors.Is(err, github.ErrLocalChanges):
+				summary.Clone = fmt.Sprintf("left alone, %d local change(s)", len(result.LocalChanges))
+			case err != nil:
+				fail("clone", err)
+			default:
+				summary.Clone = result.Summary()
+			}
+		}
+	}
+	summary.Drift = cm.CheckDrift()
+
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	cm.config.LastMaintenance = summary.At
+	if err := cm.SaveConfig(); err != nil {
+		fail("config", err)
+	}
+	log.Info("Maintenance finished", "pruned_files", summary.PrunedFiles, "log_rotated", summary.LogRotated,
+		"clone", summary.Clone, "drift", len(summary.Drift), "errors", len(summary.Errors))
+	return summary
+}
+
+// PruneCaches removes the cache files not modified within maxAge and returns how many were
+// removed and the space freed
+func (cm *ConfigManager) PruneCaches(maxAge time.Duration, now time.Time) (int, int64, error) {
+	var files int
+	var freed int64
+	err := filepath.WalkDir(filepath.Join(cm.configDir, "cache"), func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < maxAge {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		files++
+		freed += info.Size()
+		return nil
+	})
+	return files, freed, err
+}
+
+// CheckDrift lists what changed outside BOBA since it was recorded: executables of installed
+// tools that are gone, and managed home files edited or removed since they were applied
+func (cm *ConfigManager) CheckDrift() []string {
+	cfg := cm.GetConfig()
+	var drift []string
+	for name, tool := range cfg.InstalledTools {
+		if tool.Provenance == nil {
+			continue
+		}
+		for _, binary := range tool.Provenance.BinaryPaths {
+			if _, err := os.Lstat(binary); err != nil {
+				drift = append(drift, fmt.Sprintf("%s: %s was removed", name, binary))
+			}
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err == nil {
+		for file, record := range cfg.ManagedFiles {
+			content, err := os.ReadFile(filepath.Join(home, file))
+			switch {
+			case err != nil:
+				drift = append(drift, fmt.Sprintf("~/%s was removed since %s applied it", file, record.Environment))
+			case HashContent(content) != record.Hash:
+				drift = append(drift, fmt.Sprintf("~/%s was edited since %s applied it", file, record.Environment))
+			}
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// Lines describes the run: a headline, then the drift and the failed steps
+func (s MaintenanceSummary) Lines() []string {
+	headline := "🧹 Maintenance: "
+	if s.PrunedFiles > 0 {
+		headline += fmt.Sprintf("%d cache file(s) pruned (%s)", s.PrunedFiles, FormatBytes(s.PrunedBytes))
+	} else {
+		headline += "no stale cache files"
+	}
+	if s.LogRotated {
+		headline += ", log rotated"
+	}
+	if s.Clone != "" {
+		clone, _, _ := strings.Cut(s.Clone, "\n")
+		headline += ", clone: " + clone
+	}
+	if len(s.Drift) == 0 {
+		headline += ", no drift"
+	}
+
+	lines := []string{headline}
+	for _, drift := range s.Drift {
+		lines = append(lines, "⚠️ "+drift)
+	}
+	for _, failure := range s.Errors {
+		lines = append(lines, "❌ "+failure)
+	}
+	return lines
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// managedFilesDir holds the copy of each managed home file as it was last applied, next to config.json
+const managedFilesDir = "managed-files"
+
+// ManagedFile is a home file (.zshrc) as the environment applying it last wrote it. A file whose
+// hash no longer matches was edited by the user since.
+type ManagedFile struct {
+	Environment string    `json:"environment"`
+	Hash        string    `json:"hash"` // SHA-256 of the content the environment wrote
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// HashContent returns the SHA-256 of a file's content, as stored in ManagedFile
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetManagedFile returns the record of a managed home file and its content as last applied, the
+// base of a three-way merge with the user's edits
+func (cm *ConfigManager) GetManagedFile(file string) (ManagedFile, []byte, bool) {
+	if cm.config == nil {
+		return ManagedFile{}, nil, false
+	}
+	record, ok := cm.config.ManagedFiles[file]
+	if !ok {
+		return ManagedFile{}, nil, false
+	}
+	base, err := os.ReadFile(filepath.Join(cm.configDir, managedFilesDir, file))
+	if err != nil || HashContent(base) != record.Hash {
+		return ManagedFile{}, nil, false
+	}
+	return record, base, true
+}
+
+// RecordManagedFile saves a home file as an environment just wrote it
+func (cm *ConfigManager) RecordManagedFile(file, environment string, content []byte) error {
+	if cm.PersistenceDisabled() {
+		return ErrPersistenceDisabled
+	}
+	path := filepath.Join(cm.configDir, managedFilesDir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create managed files directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to save ~/%s as applied: %w", file, err)
+	}
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if cm.config.ManagedFiles == nil {
+		cm.config.ManagedFiles = make(map[string]ManagedFile)
+	}
+	
+	cm.config.ManagedFiles[file] = ManagedFile{Environment: environment, Hash: HashContent(content), AppliedAt: time.Now()}
+	return cm.SaveConfig()
+}
+
+// ForgetManagedFile drops the record of a home file, e.g. once the environment that wrote it is restored
+func (cm *ConfigManager) ForgetManagedFile(file string) error {
+	if cm.config == nil || cm.config.ManagedFiles == nil {
+		return nil
+	}
+	if _, ok := cm.config.ManagedFiles[file]; !ok {
+		return nil
+	}
+	os.Remove(filepath.Join(cm.configDir, managedFilesDir, file))
+	delete(cm.config.ManagedFiles, file)
+	return cm.SaveConfig()
+}
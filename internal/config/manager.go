@@ -2,34 +2,120 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"boba/internal/log"
 )
 
 // InstalledTool represents a tool that has been installed
 type InstalledTool struct {
-	Name            string    `json:"name"`
-	Version         string    `json:"version"`
-	InstallDate     time.Time `json:"install_date"`
-	LastUpdateDate  time.Time `json:"last_update_date,omitempty"`
-	InstallMethod   string    `json:"install_method"` // "auto" or "manual"
+	Name            string          `json:"name"`
+	Version         string          `json:"version"`
+	InstallDate     time.Time       `json:"install_date"`
+	LastUpdateDate  time.Time       `json:"last_update_date,omitempty"`
+	InstallMethod   string          `json:"install_method"` // "auto" or "manual"
+	Provenance      *ToolProvenance `json:"provenance,omitempty"`
+}
+
+// ToolProvenance records how a tool was installed, for uninstalls, drift detection and audits
+type ToolProvenance struct {
+	InstallType    string   `json:"install_type"`              // "script", "inline" or "catalog"
+	ScriptHash     string   `json:"script_hash"`               // SHA-256 of the install script that ran
+	RepoCommit     string   `json:"repo_commit,omitempty"`     // Configuration repository commit the script came from
+	ScriptSource   string   `json:"script_source,omitempty"`   // Pinned repository the script came from (owner/repo/path@ref)
+	PackageManager string   `json:"package_manager,omitempty"` // Package manager detected on the system
+	InstallScope   string   `json:"install_scope,omitempty"`   // "user" or "system", the scope the scripts installed the tool in
+	BinaryPaths    []string `json:"binary_paths,omitempty"`    // Executables that appeared on PATH during the install
+	CreatedFiles   []string `json:"created_files,omitempty"`   // Files created under the tool's track_dirs during the install
 }
 
 // Config represents the main configuration structure
 type Config struct {
 	RepositoryURL        string                    `json:"repository_url"`
+	RepositoryProvider   string                    `json:"repository_provider,omitempty"` // "github", "gitlab" or "bitbucket"; empty picks it from the URL
+	SourceType           string                    `json:"source_type,omitempty"`         // "api" (default) reads through the host's API, "git" reads a clone of an SSH remote
+	SSHKeyPath           string                    `json:"ssh_key_path,omitempty"`        // Private key cloning an SSH remote; empty uses the SSH agent and ~/.ssh defaults
+	GitHubClientID       string                    `json:"github_client_id,omitempty"`    // OAuth App signed in with through the GitHub device flow instead of the built-in one
 	ToolOverrides        map[string]bool           `json:"tool_overrides"`
 	EnvironmentOverrides map[string]bool           `json:"environment_overrides"`
 	InstalledTools       map[string]InstalledTool  `json:"installed_tools"`
+	AppliedEnvironments  map[string]time.Time      `json:"applied_environments,omitempty"` // When each environment was last applied, cleared by a restore
+	FileOwners           map[string]string         `json:"file_owners,omitempty"`          // Environment owning each home file several environments manage (.zshrc), or "merge"
+	ManagedFiles         map[string]ManagedFile    `json:"managed_files,omitempty"`        // Home files as the environment applying them last wrote them, to detect the user's edits
 	LastSync             time.Time                 `json:"last_sync"`
+	
+	// Script environment settings
+	MinimalScriptEnv     bool                      `json:"minimal_script_env,omitempty"` // Run scripts with only essential, BOBA_* and allowlisted variables
+	NoSudo               bool                      `json:"no_sudo,omitempty"`            // Never use sudo: install tools in the user scope, for locked-down machines
+	EnvAllowlist         []string                  `json:"env_allowlist,omitempty"`      // Extra variables passed to scripts in minimal mode
+	EnvDenylist          []string                  `json:"env_denylist,omitempty"`       // Variables never passed to scripts
+	ScriptEnv            map[string]string         `json:"script_env,omitempty"`         // Variables set in every script run (e.g. CORP_PROXY, NPM_REGISTRY)
+	
+	// Package manager settings
+	PackageManager       string                    `json:"package_manager,omitempty"`    // Used instead of the detected one, e.g. brew for Linuxbrew setups
+	HomebrewInstallerSHA256 string                 `json:"homebrew_installer_sha256,omitempty"` // Reviewed SHA-256 of the Homebrew installer BOBA may run
+	
+	// Tool parameter values chosen on install, by tool then parameter name
+	ToolParameters       map[string]map[string]string `json:"tool_parameters,omitempty"`
+	
+	// Repository clone settings
+	FullClone            bool                      `json:"full_clone,omitempty"`         // Clone the full history instead of a shallow --depth=1 clone
+	SparseClone          bool                      `json:"sparse_clone,omitempty"`       // Only check out tools/ and environments/
+	LocalRepoPath        string                    `json:"local_repo_path,omitempty"`    // Local mode: read the repository from this directory instead of GitHub
+	
+	// Repositories the user has confirmed as trusted (owner/repo); tools of other repositories are quarantined
+	TrustedRepositories  []string                  `json:"trusted_repositories"`
+	
+	// Release channel BOBA updates itself from: "stable" (default) or "edge" (includes prereleases)
+	UpdateChannel        string                    `json:"update_channel,omitempty"`
+	
+	// SHA-256 of BOBA binaries written by Install BOBA to System and self-updates, by path
+	BinaryChecksums      map[string]string         `json:"binary_checksums,omitempty"`
+	
+	// Local-only failure counters used to suggest fixes on startup
+	Health               HealthStats               `json:"health"`
+	
+	// Tools the user chose to skip in Install Everything runs
+	SkippedTools         []string                  `json:"skipped_tools,omitempty"`
+	
+	// When usage tracking was turned on: the tools' executables are wrapped in shims recording their last use
+	UsageTrackingSince   time.Time                 `json:"usage_tracking_since,omitempty"`
+	
+	// Days between automatic maintenance runs pruning caches, rotating logs, refreshing the clone and
+	// rechecking drift (0 = weekly, negative = off), and when it last ran
+	MaintenanceDays      int                       `json:"maintenance_interval_days,omitempty"`
+	LastMaintenance      time.Time                 `json:"last_maintenance,omitempty"`
+	
+	// Minutes automatic runs wait before retrying a tool whose install failed (0 = default, negative = off)
+	CooldownMinutes      int                       `json:"install_cooldown_minutes,omitempty"`
+	
+	// Write the results of each run as JUnit XML to this file (overridden by the --junit flag)
+	JUnitReportPath      string                    `json:"junit_report_path,omitempty"`
+	
+	// Public repository (owner/repo or owner/repo@ref) listing the community tools that can be imported
+	CommunityIndex       string                    `json:"community_index,omitempty"`
 }
 
+// Update channels for BOBA itself
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelEdge   = "edge"
+)
+
 // Credentials stores sensitive authentication information separately
 type Credentials struct {
 	GitHubToken string `json:"github_token"`
+	GitLabToken string `json:"gitlab_token,omitempty"`
+	
+	// Bitbucket Cloud authenticates with the username and an app password
+	BitbucketUsername    string `json:"bitbucket_username,omitempty"`
+	BitbucketAppPassword string `json:"bitbucket_app_password,omitempty"`
 }
 
 // ConfigManager handles configuration file operations
@@ -39,8 +125,42 @@ type ConfigManager struct {
 	credPath      string
 	config        *Config
 	credentials   *Credentials
+	
+	// Values of BOBA_REPO and the BOBA_*_TOKEN variables, layered over the stored settings and
+	// never saved
+	repoOverride           string
+	tokenOverride          string
+	gitlabTokenOverride    string
+	bitbucketTokenOverride string
+	
+	// Deferred persistence for frequent changes (override toggles, installation records). The
+	// pending config is marshalled by the goroutine making the change, so the flush timer only
+	// writes bytes and never reads the config while it changes.
+	saveInterval  time.Duration // Minimum time between deferred saves
+	saveMu        sync.Mutex    // Guards the fields below, which the flush timer shares
+	lastSave      time.Time     // When the config was last written
+	pending       []byte        // Config not yet written to disk
+	saveTimer     *time.Timer   // Writes the pending config once the save interval has passed
+	fileModTime   time.Time     // Modification time of config.json when last read or written
+	noPersistence bool          // Nothing is written any more, see DisablePersistence
+	
+	// Change notifications
+	subMu         sync.Mutex
+	subscribers   map[int]func(ConfigChange)
+	nextSubID     int
+}
+
+// ConfigChange describes a configuration change delivered to subscribers
+type ConfigChange struct {
+	External bool // Reloaded from config.json after another process changed it
 }
 
+// defaultSaveInterval limits how often frequent changes are written to config.json
+const defaultSaveInterval = 2 * time.Second
+
+// ErrPersistenceDisabled is returned by writes to the BOBA directory after it was removed for good
+var ErrPersistenceDisabled = errors.New("BOBA data was removed, nothing is saved for the rest of this session")
+
 // NewConfigManager creates a new configuration manager
 func NewConfigManager() *ConfigManager {
 	homeDir, err := os.UserHomeDir()
@@ -56,18 +176,26 @@ func NewConfigManager() *ConfigManager {
 		configDir:   configDir,
 		configPath:  filepath.Join(configDir, "config.json"),
 		credPath:    filepath.Join(configDir, "credentials.json"),
-		config:      &Config{},
-		credentials: &Credentials{},
+		config:       &Config{},
+		credentials:  &Credentials{},
+		saveInterval: defaultSaveInterval,
+		repoOverride:  os.Getenv(RepoEnv),
+		tokenOverride: os.Getenv(TokenEnv),
+		gitlabTokenOverride: os.Getenv(GitLabTokenEnv),
+		bitbucketTokenOverride: os.Getenv(BitbucketTokenEnv),
 	}
 }
 
 // InitConfigDir creates the configuration directory if it doesn't exist
 func (cm *ConfigManager) InitConfigDir() error {
+	if cm.PersistenceDisabled() {
+		return ErrPersistenceDisabled
+	}
 	if err := os.MkdirAll(cm.configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 	
-	// Create cache subdirectory for future use
+	// Create cache subdirectory for the repository listing
 	cacheDir := filepath.Join(cm.configDir, "cache")
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
@@ -92,6 +220,7 @@ func (cm *ConfigManager) LoadConfig() error {
 			EnvironmentOverrides: make(map[string]bool),
 			InstalledTools:       make(map[string]InstalledTool),
 			LastSync:             time.Time{},
+			TrustedRepositories:  []string{},
 		}
 		return cm.SaveConfig()
 	}
@@ -105,6 +234,9 @@ func (cm *ConfigManager) LoadConfig() error {
 	if err := json.Unmarshal(data, cm.config); err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
+	cm.saveMu.Lock()
+	cm.recordFileModTime()
+	cm.saveMu.Unlock()
 	
 	// Initialize ToolOverrides map if it's nil
 	if cm.config.ToolOverrides == nil {
@@ -121,27 +253,262 @@ func (cm *ConfigManager) LoadConfig() error {
 		cm.config.InstalledTools = make(map[string]InstalledTool)
 	}
 	
+	// Configs written before repository trust existed keep trusting their repository, the default
+	// one when none is set. Its owner is only known once the token is checked, so the short name
+	// is trusted and carried over when it is resolved.
+	if cm.config.TrustedRepositories == nil {
+		repo := cm.config.RepositoryURL
+		if repo == "" {
+			repo = "boba-config"
+		}
+		cm.config.TrustedRepositories = []string{repositoryKey(repo)}
+	}
+	
 	return nil
 }
 
 // SaveConfig saves the current configuration to the config file
 func (cm *ConfigManager) SaveConfig() error {
+	if err := cm.writeConfig(); err != nil {
+		return err
+	}
+	cm.notify(ConfigChange{})
+	return nil
+}
+
+// writeConfig writes the current configuration to the config file
+func (cm *ConfigManager) writeConfig() error {
+	if cm.PersistenceDisabled() {
+		return nil
+	}
 	if err := cm.InitConfigDir(); err != nil {
 		return err
 	}
 	
-	data, err := json.MarshalIndent(cm.config, "", "  ")
+	data, err := cm.marshalConfig()
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 	
-	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	return cm.writeConfigData(data)
+}
+
+// marshalConfig encodes the configuration as written to config.json
+func (cm *ConfigManager) marshalConfig() ([]byte, error) {
+	data, err := json.MarshalIndent(cm.config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// writeConfigData replaces config.json with data and drops any pending deferred save; saveMu
+// must be held. The file is written aside and renamed over config.json, so a crash or another
+// process reading it never sees half a config.
+func (cm *ConfigManager) writeConfigData(data []byte) error {
+	if cm.noPersistence {
+		cm.pending = nil
+		return nil
+	}
+	temp, err := os.CreateTemp(cm.configDir, "config-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Chmod(0644)
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), cm.configPath)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	
+	cm.lastSave = time.Now()
+	cm.pending = nil
+	if cm.saveTimer != nil {
+		cm.saveTimer.Stop()
+		cm.saveTimer = nil
+	}
+	cm.recordFileModTime()
 	return nil
 }
 
+// recordFileModTime remembers the modification time of config.json to detect changes by other
+// processes; saveMu must be held
+func (cm *ConfigManager) recordFileModTime() {
+	if info, err := os.Stat(cm.configPath); err == nil {
+		cm.fileModTime = info.ModTime()
+	}
+}
+
+// saveDeferred writes the config at most once per save interval. Changes made within the
+// interval stay in memory, and a timer writes them when the interval has passed, so batch
+// runs don't rewrite config.json for every toggle or installed tool yet nothing waits for the
+// next Flush.
+func (cm *ConfigManager) saveDeferred() error {
+	cm.saveMu.Lock()
+	wait := cm.saveInterval - time.Since(cm.lastSave)
+	off := cm.noPersistence
+	cm.saveMu.Unlock()
+	if off {
+		cm.notify(ConfigChange{})
+		return nil
+	}
+	if wait <= 0 {
+		return cm.SaveConfig()
+	}
+	
+	data, err := cm.marshalConfig()
+	if err != nil {
+		return err
+	}
+	cm.saveMu.Lock()
+	cm.pending = data
+	if cm.saveTimer == nil {
+		cm.saveTimer = time.AfterFunc(wait, cm.flushPending)
+	}
+	cm.saveMu.Unlock()
+	
+	cm.notify(ConfigChange{})
+	return nil
+}
+
+// flushPending writes the pending config when the save timer fires. A failure keeps the
+// changes pending for the next save or Flush.
+func (cm *ConfigManager) flushPending() {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	
+	cm.saveTimer = nil
+	if cm.pending == nil {
+		return
+	}
+	if err := cm.writeConfigData(cm.pending); err != nil {
+		log.Warn("Failed to save deferred config changes", "error", err)
+	}
+}
+
+// DisablePersistence stops every later write to the BOBA directory for the rest of the session and
+// drops the pending deferred changes, so the saves that follow removing the directory for good
+// (flushes, the end of the run, the session state) don't create it again
+func (cm *ConfigManager) DisablePersistence() {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	cm.noPersistence = true
+	cm.pending = nil
+	if cm.saveTimer != nil {
+		cm.saveTimer.Stop()
+		cm.saveTimer = nil
+	}
+}
+
+// EnablePersistence lets writes resume after DisablePersistence, when removing the directory failed
+func (cm *ConfigManager) EnablePersistence() {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	cm.noPersistence = false
+}
+
+// PersistenceDisabled reports whether writes to the BOBA directory are disabled
+func (cm *ConfigManager) PersistenceDisabled() bool {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	return cm.noPersistence
+}
+
+// SetSaveInterval sets the minimum time between deferred saves (0 saves every change immediately)
+func (cm *ConfigManager) SetSaveInterval(interval time.Duration) {
+	cm.saveInterval = interval
+}
+
+// HasPendingChanges reports whether there are changes not yet written to disk
+func (cm *ConfigManager) HasPendingChanges() bool {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+	return cm.pending != nil
+}
+
+// Flush writes pending changes to disk. Call it on exit and on critical transitions
+// (end of a batch, leaving a menu) so deferred changes are not lost.
+func (cm *ConfigManager) Flush() error {
+	if !cm.HasPendingChanges() {
+		return nil
+	}
+	return cm.writeConfig()
+}
+
+// Subscribe registers a function called after every configuration change, whether made
+// through this manager or reloaded from disk. It returns a function that unsubscribes.
+// Subscribers run on the goroutine making the change and must not block.
+func (cm *ConfigManager) Subscribe(fn func(ConfigChange)) func() {
+	cm.subMu.Lock()
+	defer cm.subMu.Unlock()
+	
+	if cm.subscribers == nil {
+		cm.subscribers = make(map[int]func(ConfigChange))
+	}
+	id := cm.nextSubID
+	cm.nextSubID++
+	cm.subscribers[id] = fn
+	
+	return func() {
+		cm.subMu.Lock()
+		defer cm.subMu.Unlock()
+		delete(cm.subscribers, id)
+	}
+}
+
+// notify calls every subscriber with the change
+func (cm *ConfigManager) notify(change ConfigChange) {
+	cm.subMu.Lock()
+	subscribers := make([]func(ConfigChange), 0, len(cm.subscribers))
+	for _, fn := range cm.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	cm.subMu.Unlock()
+	
+	for _, fn := range subscribers {
+		fn(change)
+	}
+}
+
+// ReloadIfChanged reloads config.json when another process changed it since it was last
+// read or written, and notifies subscribers. Pending local changes take precedence: the
+// file is not reloaded while there are unsaved changes.
+func (cm *ConfigManager) ReloadIfChanged() (bool, error) {
+	info, err := os.Stat(cm.configPath)
+	if err != nil {
+		return false, nil
+	}
+	cm.saveMu.Lock()
+	unchanged := cm.pending != nil || info.ModTime().Equal(cm.fileModTime)
+	cm.saveMu.Unlock()
+	if unchanged {
+		return false, nil
+	}
+	
+	previous := cm.config
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		cm.config = previous
+		return false, err
+	}
+	
+	cm.notify(ConfigChange{External: true})
+	return true, nil
+}
+
 // LoadCredentials loads credentials from the credentials file
 func (cm *ConfigManager) LoadCredentials() error {
 	// Initialize config directory if it doesn't exist
@@ -180,15 +547,14 @@ func (cm *ConfigManager) SaveCredentials() error {
 		return fmt.Errorf("failed to marshal credentials: %w", err)
 	}
 	
-	// Debug: Print where we're trying to save credentials
-	fmt.Printf("DEBUG: Saving credentials to: %s\n", cm.credPath)
+	log.Debug("Saving credentials", "path", cm.credPath)
 	
 	// Write with restricted permissions (600) for security
 	if err := os.WriteFile(cm.credPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write credentials file: %w", err)
 	}
 	
-	fmt.Printf("DEBUG: Credentials saved successfully\n")
+	log.Debug("Credentials saved", "path", cm.credPath)
 	return nil
 }
 
@@ -202,6 +568,9 @@ func (cm *ConfigManager) GetConfig() Config {
 	
 	// Return a copy to prevent external modification
 	configCopy := *cm.config
+	if cm.repoOverride != "" {
+		configCopy.RepositoryURL = cm.repoOverride
+	}
 	if configCopy.ToolOverrides == nil {
 		configCopy.ToolOverrides = make(map[string]bool)
 	}
@@ -211,6 +580,11 @@ func (cm *ConfigManager) GetConfig() Config {
 
 // SetRepositoryURL sets the repository URL in the configuration
 func (cm *ConfigManager) SetRepositoryURL(url string) error {
+	// BOBA_REPO is kept for the session, e.g. when the short name is resolved to owner/repo
+	if cm.repoOverride != "" {
+		cm.repoOverride = url
+		return nil
+	}
 	if cm.config == nil {
 		cm.config = &Config{
 			ToolOverrides: make(map[string]bool),
@@ -218,6 +592,48 @@ func (cm *ConfigManager) SetRepositoryURL(url string) error {
 	}
 	
 	cm.config.RepositoryURL = url
+	if cm.config.TrustedRepositories == nil {
+		cm.config.TrustedRepositories = []string{}
+	}
+	return cm.SaveConfig()
+}
+
+// repositoryKey normalizes a repository URL or owner/repo name for trust lookups
+func repositoryKey(repo string) string {
+	repo = strings.TrimSpace(repo)
+	repo = strings.TrimSuffix(repo, ".git")
+	repo = strings.TrimPrefix(repo, "git@github.com:")
+	repo = strings.TrimPrefix(repo, "https://github.com/")
+	return strings.ToLower(repo)
+}
+
+// IsRepositoryTrusted reports whether the user has confirmed the repository as trusted
+func (cm *ConfigManager) IsRepositoryTrusted(repo string) bool {
+	if cm.config == nil {
+		return false
+	}
+	
+	key := repositoryKey(repo)
+	for _, trusted := range cm.config.TrustedRepositories {
+		if trusted == key {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustRepository marks the repository as trusted and saves the config
+func (cm *ConfigManager) TrustRepository(repo string) error {
+	if cm.IsRepositoryTrusted(repo) {
+		return nil
+	}
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.TrustedRepositories = append(cm.config.TrustedRepositories, repositoryKey(repo))
 	return cm.SaveConfig()
 }
 
@@ -245,7 +661,7 @@ func (cm *ConfigManager) SetToolOverride(toolName string, enabled bool) error {
 	}
 	
 	cm.config.ToolOverrides[toolName] = enabled
-	return cm.SaveConfig()
+	return cm.saveDeferred()
 }
 
 // RemoveToolOverride removes the override setting for a specific tool
@@ -255,7 +671,7 @@ func (cm *ConfigManager) RemoveToolOverride(toolName string) error {
 	}
 	
 	delete(cm.config.ToolOverrides, toolName)
-	return cm.SaveConfig()
+	return cm.saveDeferred()
 }
 
 // UpdateLastSync updates the last synchronization timestamp
@@ -272,16 +688,30 @@ func (cm *ConfigManager) UpdateLastSync() error {
 
 // GetCredentials returns a copy of the current credentials
 func (cm *ConfigManager) GetCredentials() Credentials {
-	if cm.credentials == nil {
-		return Credentials{}
-	}
-	
 	// Return a copy to prevent external modification
-	return *cm.credentials
+	var credentials Credentials
+	if cm.credentials != nil {
+		credentials = *cm.credentials
+	}
+	if cm.tokenOverride != "" {
+		credentials.GitHubToken = cm.tokenOverride
+	}
+	if cm.gitlabTokenOverride != "" {
+		credentials.GitLabToken = cm.gitlabTokenOverride
+	}
+	if cm.bitbucketTokenOverride != "" {
+		credentials.BitbucketUsername, credentials.BitbucketAppPassword, _ = strings.Cut(cm.bitbucketTokenOverride, ":")
+	}
+	return credentials
 }
 
 // SetGitHubToken sets the GitHub token in credentials
 func (cm *ConfigManager) SetGitHubToken(token string) error {
+	// BOBA_GITHUB_TOKEN is kept for the session and never written to credentials.json
+	if cm.tokenOverride != "" {
+		cm.tokenOverride = token
+		return nil
+	}
 	if cm.credentials == nil {
 		cm.credentials = &Credentials{}
 	}
@@ -312,6 +742,170 @@ func (cm *ConfigManager) ValidateConfig() error {
 	return nil
 }
 
+// SetScriptEnvironmentPolicy configures which environment variables scripts inherit
+func (cm *ConfigManager) SetScriptEnvironmentPolicy(minimal bool, allowlist, denylist []string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.MinimalScriptEnv = minimal
+	cm.config.EnvAllowlist = allowlist
+	cm.config.EnvDenylist = denylist
+	return cm.SaveConfig()
+}
+
+// SetNoSudo turns the never-use-sudo mode on or off
+func (cm *ConfigManager) SetNoSudo(noSudo bool) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.NoSudo = noSudo
+	return cm.SaveConfig()
+}
+
+// PinHomebrewInstaller records the reviewed SHA-256 of the Homebrew installer BOBA may run
+func (cm *ConfigManager) PinHomebrewInstaller(checksum string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.HomebrewInstallerSHA256 = checksum
+	return cm.SaveConfig()
+}
+
+// GetScriptEnv returns a copy of the variables set in every script run
+func (cm *ConfigManager) GetScriptEnv() map[string]string {
+	if cm.config == nil {
+		return nil
+	}
+	vars := make(map[string]string, len(cm.config.ScriptEnv))
+	for name, value := range cm.config.ScriptEnv {
+		vars[name] = value
+	}
+	return vars
+}
+
+// SetScriptEnv replaces the variables set in every script run and saves the config
+func (cm *ConfigManager) SetScriptEnv(vars map[string]string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.ScriptEnv = vars
+	return cm.SaveConfig()
+}
+
+// GetToolParameters returns a copy of the parameter values chosen for each tool
+func (cm *ConfigManager) GetToolParameters() map[string]map[string]string {
+	if cm.config == nil {
+		return nil
+	}
+	params := make(map[string]map[string]string, len(cm.config.ToolParameters))
+	for tool, values := range cm.config.ToolParameters {
+		params[tool] = make(map[string]string, len(values))
+		for name, value := range values {
+			params[tool][name] = value
+		}
+	}
+	return params
+}
+
+// SetToolParameters records the parameter values chosen for a tool (forgotten when empty) and saves the config
+func (cm *ConfigManager) SetToolParameters(toolName string, values map[string]string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if cm.config.ToolParameters == nil {
+		cm.config.ToolParameters = make(map[string]map[string]string)
+	}
+	
+	if len(values) == 0 {
+		delete(cm.config.ToolParameters, toolName)
+	} else {
+		cm.config.ToolParameters[toolName] = values
+	}
+	return cm.SaveConfig()
+}
+
+// SetCloneSettings updates how the configuration repository is cloned and saves the config
+func (cm *ConfigManager) SetCloneSettings(fullClone, sparseClone bool) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.FullClone = fullClone
+	cm.config.SparseClone = sparseClone
+	return cm.SaveConfig()
+}
+
+// GetUpdateChannel returns the release channel BOBA updates itself from
+func (cm *ConfigManager) GetUpdateChannel() string {
+	if cm.config == nil || cm.config.UpdateChannel != UpdateChannelEdge {
+		return UpdateChannelStable
+	}
+	return UpdateChannelEdge
+}
+
+// SetUpdateChannel sets the release channel BOBA updates itself from and saves the config
+func (cm *ConfigManager) SetUpdateChannel(channel string) error {
+	if channel != UpdateChannelStable && channel != UpdateChannelEdge {
+		return fmt.Errorf("unknown update channel '%s' (expected %s or %s)", channel, UpdateChannelStable, UpdateChannelEdge)
+	}
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.UpdateChannel = channel
+	return cm.SaveConfig()
+}
+
+// GetBinaryChecksum returns the checksum recorded when BOBA wrote the binary at path
+func (cm *ConfigManager) GetBinaryChecksum(path string) (string, bool) {
+	if cm.config == nil || cm.config.BinaryChecksums == nil {
+		return "", false
+	}
+	checksum, ok := cm.config.BinaryChecksums[path]
+	return checksum, ok
+}
+
+// RecordBinaryChecksum records the checksum of a BOBA binary written by an install or self-update and saves the config
+func (cm *ConfigManager) RecordBinaryChecksum(path, checksum string) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if cm.config.BinaryChecksums == nil {
+		cm.config.BinaryChecksums = make(map[string]string)
+	}
+	
+	cm.config.BinaryChecksums[path] = checksum
+	return cm.SaveConfig()
+}
+
+// GetCommunityIndex returns the community tools index repository, or "" for the default one
+func (cm *ConfigManager) GetCommunityIndex() string {
+	if cm.config == nil {
+		return ""
+	}
+	return cm.config.CommunityIndex
+}
+
 // GetConfigDir returns the configuration directory path
 func (cm *ConfigManager) GetConfigDir() string {
 	return cm.configDir
@@ -327,8 +921,19 @@ func (cm *ConfigManager) GetCredentialsPath() string {
 	return cm.credPath
 }
 
+// GetRepositoryCachePath returns the path of the repository listing written by `boba sync`
+func (cm *ConfigManager) GetRepositoryCachePath() string {
+	return filepath.Join(cm.configDir, "cache", "repository.json")
+}
+
 // RecordToolInstallation records that a tool has been installed
 func (cm *ConfigManager) RecordToolInstallation(name, version, method string) error {
+	return cm.RecordToolInstallationWithProvenance(name, version, method, nil)
+}
+
+// RecordToolInstallationWithProvenance records that a tool has been installed and how.
+// A nil provenance keeps the provenance of an existing record.
+func (cm *ConfigManager) RecordToolInstallationWithProvenance(name, version, method string, provenance *ToolProvenance) error {
 	if cm.config == nil {
 		cm.config = &Config{
 			ToolOverrides:  make(map[string]bool),
@@ -348,6 +953,9 @@ func (cm *ConfigManager) RecordToolInstallation(name, version, method string) er
 		existing.Version = version
 		existing.LastUpdateDate = now
 		existing.InstallMethod = method
+		if provenance != nil {
+			existing.Provenance = provenance
+		}
 		cm.config.InstalledTools[name] = existing
 	} else {
 		// Create new record
@@ -356,10 +964,14 @@ func (cm *ConfigManager) RecordToolInstallation(name, version, method string) er
 			Version:       version,
 			InstallDate:   now,
 			InstallMethod: method,
+			Provenance:    provenance,
 		}
 	}
 	
-	return cm.SaveConfig()
+	if provenance != nil {
+		cm.syncShimsQuietly()
+	}
+	return cm.saveDeferred()
 }
 
 // GetInstalledTool returns information about an installed tool
@@ -393,7 +1005,8 @@ func (cm *ConfigManager) RemoveInstalledTool(name string) error {
 	}
 	
 	delete(cm.config.InstalledTools, name)
-	return cm.SaveConfig()
+	cm.syncShimsQuietly()
+	return cm.saveDeferred()
 }
 
 // GetEnvironmentOverride returns the override setting for a specific environment
@@ -422,7 +1035,7 @@ func (cm *ConfigManager) SetEnvironmentOverride(envName string, enabled bool) er
 	}
 	
 	cm.config.EnvironmentOverrides[envName] = enabled
-	return cm.SaveConfig()
+	return cm.saveDeferred()
 }
 
 // RemoveEnvironmentOverride removes the override setting for a specific environment
@@ -432,7 +1045,7 @@ func (cm *ConfigManager) RemoveEnvironmentOverride(envName string) error {
 	}
 	
 	delete(cm.config.EnvironmentOverrides, envName)
-	return cm.SaveConfig()
+	return cm.saveDeferred()
 }
 
 // ResetAllToolOverrides removes all tool overrides, returning to defaults
@@ -457,6 +1070,14 @@ func (cm *ConfigManager) ResetAllEnvironmentOverrides() error {
 
 // getConfigDir determines the best config directory based on environment
 func getConfigDir(homeDir string) string {
+	// An explicit BOBA directory, e.g. the temporary one of an ephemeral session
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir
+	}
+	
 	// Check if we're in a Docker container
 	if isDockerContainer() {
 		// In Docker, try /tmp first as it's always writable
@@ -482,6 +1103,11 @@ func getConfigDir(homeDir string) string {
 	return "/tmp/.boba"
 }
 
+// IsContainer reports whether BOBA runs inside a container
+func IsContainer() bool {
+	return isDockerContainer()
+}
+
 // isDockerContainer detects if we're running inside a Docker container
 func isDockerContainer() bool {
 	// Check for .dockerenv file (most reliable)
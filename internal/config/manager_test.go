@@ -1,9 +1,16 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -534,4 +541,1572 @@ func TestHasGitHubToken(t *testing.T) {
 	if !cm.HasGitHubToken() {
 		t.Error("Expected HasGitHubToken to return true with token set")
 	}
-}
\ No newline at end of file
+}
+func TestRecordToolInstallationProvenance(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	cm := &ConfigManager{
+		configDir:  filepath.Join(tempDir, ".boba"),
+		configPath: filepath.Join(tempDir, ".boba", "config.json"),
+		credPath:   filepath.Join(tempDir, ".boba", "credentials.json"),
+		config:     &Config{},
+		credentials: &Credentials{},
+	}
+	
+	provenance := &ToolProvenance{
+		InstallType:    "script",
+		ScriptHash:     "abc123",
+		RepoCommit:     "def456",
+		PackageManager: "apt",
+		BinaryPaths:    []string{"/usr/local/bin/jq"},
+	}
+	if err := cm.RecordToolInstallationWithProvenance("jq", "1.7", "manual", provenance); err != nil {
+		t.Fatalf("RecordToolInstallationWithProvenance failed: %v", err)
+	}
+	
+	// Recording again without provenance keeps the existing provenance
+	if err := cm.RecordToolInstallation("jq", "1.7.1", "manual"); err != nil {
+		t.Fatalf("RecordToolInstallation failed: %v", err)
+	}
+	
+	// Reload from disk to check the provenance is persisted
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	
+	tool, exists := cm.GetInstalledTool("jq")
+	if !exists {
+		t.Fatal("Expected jq to be recorded")
+	}
+	if tool.Version != "1.7.1" {
+		t.Errorf("Expected version 1.7.1, got %s", tool.Version)
+	}
+	if tool.Provenance == nil || tool.Provenance.ScriptHash != "abc123" || tool.Provenance.RepoCommit != "def456" {
+		t.Errorf("Expected provenance to be kept, got %+v", tool.Provenance)
+	}
+	if len(tool.Provenance.BinaryPaths) != 1 || tool.Provenance.BinaryPaths[0] != "/usr/local/bin/jq" {
+		t.Errorf("Expected binary paths to be kept, got %v", tool.Provenance.BinaryPaths)
+	}
+}
+
+func TestRepositoryTrust(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".boba")
+	configPath := filepath.Join(configDir, "config.json")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	
+	// A config written before repository trust existed keeps trusting its repository
+	legacy := `{"repository_url": "https://github.com/Someone/boba-config.git"}`
+	if err := os.WriteFile(configPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  configPath,
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !cm.IsRepositoryTrusted("someone/boba-config") {
+		t.Error("Expected the existing repository to be trusted after migration")
+	}
+	
+	// A newly configured repository is not trusted until confirmed
+	if err := cm.SetRepositoryURL("someone/boba-confg"); err != nil {
+		t.Fatalf("SetRepositoryURL failed: %v", err)
+	}
+	if cm.IsRepositoryTrusted("someone/boba-confg") {
+		t.Error("Expected a new repository to be quarantined")
+	}
+	if err := cm.TrustRepository("someone/boba-confg"); err != nil {
+		t.Fatalf("TrustRepository failed: %v", err)
+	}
+	
+	// Trust survives a reload, and the migration does not re-run
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !cm.IsRepositoryTrusted("https://github.com/someone/boba-confg") {
+		t.Error("Expected trust to be persisted")
+	}
+	if len(cm.GetConfig().TrustedRepositories) != 2 {
+		t.Errorf("Expected 2 trusted repositories, got %v", cm.GetConfig().TrustedRepositories)
+	}
+	
+	// A legacy config on the default repository keeps trusting it by its short name
+	if err := os.WriteFile(configPath, []byte(`{"tool_overrides": {}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !cm.IsRepositoryTrusted("boba-config") {
+		t.Errorf("Expected the default repository to be trusted after migration, got %v", cm.GetConfig().TrustedRepositories)
+	}
+}
+
+func TestDeferredSaves(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	cm := &ConfigManager{
+		configDir:    filepath.Join(tempDir, ".boba"),
+		configPath:   filepath.Join(tempDir, ".boba", "config.json"),
+		credPath:     filepath.Join(tempDir, ".boba", "credentials.json"),
+		config:       &Config{ToolOverrides: make(map[string]bool)},
+		credentials:  &Credentials{},
+		saveInterval: time.Hour,
+	}
+	
+	readOverrides := func() map[string]bool {
+		data, err := os.ReadFile(cm.configPath)
+		if err != nil {
+			t.Fatalf("Failed to read config: %v", err)
+		}
+		var saved Config
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatalf("Failed to parse config: %v", err)
+		}
+		return saved.ToolOverrides
+	}
+	
+	// The first change is written immediately
+	if err := cm.SetToolOverride("git", true); err != nil {
+		t.Fatalf("SetToolOverride failed: %v", err)
+	}
+	if overrides := readOverrides(); !overrides["git"] {
+		t.Errorf("Expected first change to be saved, got %v", overrides)
+	}
+	
+	// Further changes within the interval stay in memory
+	cm.SetToolOverride("vim", true)
+	cm.RecordToolInstallation("git", "2.43", "manual")
+	if _, saved := readOverrides()["vim"]; saved {
+		t.Error("Expected change within the save interval to be deferred")
+	}
+	if !cm.HasPendingChanges() {
+		t.Error("Expected pending changes")
+	}
+	if enabled, exists := cm.GetToolOverride("vim"); !exists || !enabled {
+		t.Error("Expected deferred change to be visible in memory")
+	}
+	
+	// Flush writes everything
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if !readOverrides()["vim"] || cm.HasPendingChanges() {
+		t.Error("Expected Flush to write pending changes")
+	}
+	
+	// Without a Flush, deferred changes are written once the interval has passed
+	cm.SetSaveInterval(200 * time.Millisecond)
+	cm.SetToolOverride("zsh", true)
+	cm.SetToolOverride("tmux", true)
+	if _, saved := readOverrides()["tmux"]; saved {
+		t.Error("Expected change within the save interval to be deferred")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for cm.HasPendingChanges() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if overrides := readOverrides(); !overrides["tmux"] {
+		t.Errorf("Expected the save timer to write deferred changes, got %v", overrides)
+	}
+	
+	// The config is replaced through a temporary file that does not linger
+	entries, err := os.ReadDir(cm.configDir)
+	if err != nil {
+		t.Fatalf("Failed to read config dir: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("Expected no temporary config files, found %s", entry.Name())
+		}
+	}
+}
+
+func TestDisablePersistence(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".boba")
+	cm := &ConfigManager{
+		configDir:    configDir,
+		configPath:   filepath.Join(configDir, "config.json"),
+		credPath:     filepath.Join(configDir, "credentials.json"),
+		config:       &Config{ToolOverrides: make(map[string]bool)},
+		credentials:  &Credentials{},
+		saveInterval: 50 * time.Millisecond,
+	}
+	if err := cm.SetToolOverride("git", true); err != nil {
+		t.Fatal(err)
+	}
+	cm.SetToolOverride("vim", true)
+	
+	// The BOBA directory is removed for good: nothing written afterwards may create it again
+	cm.DisablePersistence()
+	if err := os.RemoveAll(configDir); err != nil {
+		t.Fatal(err)
+	}
+	cm.SetToolOverride("zsh", true)
+	if err := cm.Flush(); err != nil {
+		t.Errorf("Expected Flush to do nothing, got %v", err)
+	}
+	if err := cm.EndRun(false); err != nil {
+		t.Errorf("Expected EndRun to do nothing, got %v", err)
+	}
+	if err := cm.SaveSession(SessionState{}); !errors.Is(err, ErrPersistenceDisabled) {
+		t.Errorf("Expected the session not to be saved, got %v", err)
+	}
+	if _, err := cm.BeginOperation(context.Background(), "install git"); !errors.Is(err, ErrPersistenceDisabled) {
+		t.Errorf("Expected operations to be refused, got %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // Past the save interval of the deferred changes
+	if _, err := os.Stat(configDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the removed BOBA directory to stay removed, got %v", err)
+	}
+	
+	// Writes resume when the removal failed
+	cm.EnablePersistence()
+	if err := cm.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cm.configPath); err != nil {
+		t.Errorf("Expected the config to be written again: %v", err)
+	}
+}
+
+func TestConfigChangeNotifications(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	cm := &ConfigManager{
+		configDir:   filepath.Join(tempDir, ".boba"),
+		configPath:  filepath.Join(tempDir, ".boba", "config.json"),
+		credPath:    filepath.Join(tempDir, ".boba", "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	
+	var changes []ConfigChange
+	unsubscribe := cm.Subscribe(func(change ConfigChange) {
+		changes = append(changes, change)
+	})
+	
+	// Local changes notify subscribers
+	if err := cm.SetToolOverride("git", true); err != nil {
+		t.Fatalf("SetToolOverride failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].External {
+		t.Fatalf("Expected 1 local change, got %+v", changes)
+	}
+	
+	// Nothing to reload when the file is unchanged
+	if reloaded, err := cm.ReloadIfChanged(); err != nil || reloaded {
+		t.Errorf("Expected no reload for an unchanged file, got %v, %v", reloaded, err)
+	}
+	
+	// Another process rewrites config.json
+	external := `{"repository_url": "someone/boba-config", "tool_overrides": {"vim": true}}`
+	if err := os.WriteFile(cm.configPath, []byte(external), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(cm.configPath, future, future)
+	
+	reloaded, err := cm.ReloadIfChanged()
+	if err != nil || !reloaded {
+		t.Fatalf("Expected external change to be reloaded, got %v, %v", reloaded, err)
+	}
+	if len(changes) != 2 || !changes[1].External {
+		t.Fatalf("Expected an external change notification, got %+v", changes)
+	}
+	if _, exists := cm.GetToolOverride("git"); exists {
+		t.Error("Expected overrides to be replaced by the reloaded file")
+	}
+	if enabled, _ := cm.GetToolOverride("vim"); !enabled || cm.GetConfig().RepositoryURL != "someone/boba-config" {
+		t.Error("Expected the reloaded configuration to be used")
+	}
+	
+	// Unsubscribed functions are no longer called
+	unsubscribe()
+	cm.SetToolOverride("git", false)
+	if len(changes) != 2 {
+		t.Errorf("Expected no notification after unsubscribing, got %+v", changes)
+	}
+}
+
+func TestUpdateChannel(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	if channel := cm.GetUpdateChannel(); channel != UpdateChannelStable {
+		t.Errorf("Expected the stable channel by default, got %s", channel)
+	}
+	if err := cm.SetUpdateChannel("nightly"); err == nil {
+		t.Error("Expected an unknown channel to be rejected")
+	}
+	if err := cm.SetUpdateChannel(UpdateChannelEdge); err != nil {
+		t.Fatalf("SetUpdateChannel failed: %v", err)
+	}
+	
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if channel := cm.GetUpdateChannel(); channel != UpdateChannelEdge {
+		t.Errorf("Expected the edge channel to be persisted, got %s", channel)
+	}
+}
+
+func TestHealthStats(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	// A run that never ends (crash) counts as failed on the next start
+	cm.BeginRun()
+	cm.BeginRun()
+	if health := cm.GetHealth(); health.ConsecutiveFailedRuns != 1 || !health.RunInProgress {
+		t.Errorf("Expected 1 failed run in progress, got %+v", health)
+	}
+	cm.EndRun(true)
+	if health := cm.GetHealth(); health.ConsecutiveFailedRuns != 2 || health.RunInProgress {
+		t.Errorf("Expected 2 failed runs, got %+v", health)
+	}
+	cm.BeginRun()
+	cm.EndRun(false)
+	if health := cm.GetHealth(); health.ConsecutiveFailedRuns != 0 {
+		t.Errorf("Expected a clean exit to reset failed runs, got %+v", health)
+	}
+	
+	cm.RecordTokenResult(false)
+	cm.RecordTokenResult(false)
+	cm.RecordScriptResult("nvim", false)
+	cm.RecordScriptResult("nvim", false)
+	cm.RecordScriptResult("rg", false)
+	cm.RecordScriptResult("rg", true)
+	cm.Flush()
+	
+	cm.config = &Config{}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	health := cm.GetHealth()
+	if health.TokenFailures != 2 || health.ScriptFailures["nvim"] != 2 {
+		t.Errorf("Expected token and script failures to be persisted, got %+v", health)
+	}
+	if _, ok := health.ScriptFailures["rg"]; ok {
+		t.Error("Expected a successful install to clear the failure count")
+	}
+}
+
+func TestSkipList(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	cm.SetToolSkipped("docker", true)
+	for i := 0; i < KnownFailingThreshold; i++ {
+		cm.RecordScriptResult("nvim", false)
+	}
+	cm.RecordScriptResult("rg", false)
+	if known := cm.KnownFailingTools(); len(known) != 2 || known[0] != "docker" || known[1] != "nvim" {
+		t.Errorf("Expected docker and nvim to be known-failing, got %v", known)
+	}
+	
+	cm.ForgetToolFailures("nvim")
+	cm.SetToolSkipped("docker", false)
+	if known := cm.KnownFailingTools(); len(known) != 0 {
+		t.Errorf("Expected an empty skip list, got %v", known)
+	}
+	
+	cm.SetToolSkipped("docker", true)
+	cm.ClearSkipList()
+	if cm.IsToolSkipped("docker") || len(cm.GetHealth().ScriptFailures) != 0 {
+		t.Error("Expected ClearSkipList to remove skipped tools and failure counts")
+	}
+}
+
+func TestAppliedEnvironments(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	cm.RecordEnvironmentApplied("zsh-dev")
+	if applied, ok := cm.GetEnvironmentAppliedDate("zsh-dev"); !ok || applied.IsZero() {
+		t.Errorf("Expected zsh-dev to be recorded as applied, got %v, %v", applied, ok)
+	}
+	
+	cm.RemoveAppliedEnvironment("zsh-dev")
+	if _, ok := cm.GetEnvironmentAppliedDate("zsh-dev"); ok {
+		t.Error("Expected the applied record to be cleared")
+	}
+}
+
+func TestToolRunMetrics(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	cm.RecordToolRun("nvim", false, 2*time.Second, 0)
+	cm.RecordToolRun("nvim", true, 4*time.Second, 2048)
+	records, err := cm.LoadMetrics()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected 2 recorded installs, got %+v, %v", records, err)
+	}
+	if records[0].Retries != 0 || records[1].Retries != 1 || records[1].DownloadBytes != 2048 {
+		t.Errorf("Expected the second install to retry the first, got %+v", records)
+	}
+	if _, failing := cm.GetHealth().ScriptFailures["nvim"]; failing {
+		t.Error("Expected the successful install to clear the failure count")
+	}
+	
+	var history []ToolRunMetric
+	for i := 1; i <= 20; i++ {
+		history = append(history, ToolRunMetric{Tool: "rg", Duration: time.Duration(i) * time.Second, Success: true})
+	}
+	history = append(history, ToolRunMetric{Tool: "jq", Duration: time.Second, Success: false})
+	metrics := AggregateMetrics(history)
+	if len(metrics) != 2 || metrics[0].Tool != "rg" || metrics[0].P95Duration != 19*time.Second || metrics[0].Runs != 20 {
+		t.Errorf("Expected rg first with a p95 of 19s, got %+v", metrics)
+	}
+	if metrics[1].Tool != "jq" || metrics[1].Failures != 1 || metrics[1].MeanDuration != time.Second {
+		t.Errorf("Unexpected jq metrics: %+v", metrics[1])
+	}
+}
+
+func TestToolDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "bin", "rg")
+	data := filepath.Join(dir, "share", "rg", "complete.zsh")
+	os.MkdirAll(filepath.Dir(binary), 0755)
+	os.MkdirAll(filepath.Dir(data), 0755)
+	os.WriteFile(data, make([]byte, 3000), 0644)
+	os.WriteFile(binary, make([]byte, 2048), 0755)
+	
+	cm := &ConfigManager{config: &Config{InstalledTools: map[string]InstalledTool{
+		"rg":  {Name: "rg", Provenance: &ToolProvenance{BinaryPaths: []string{binary}, CreatedFiles: []string{binary, data, filepath.Join(dir, "gone")}}},
+		"old": {Name: "old"},
+	}}}
+	usage := cm.ToolDiskUsage()
+	if len(usage) != 1 || usage["rg"] != (DiskUsage{Bytes: 5048, Files: 2, Missing: 1}) {
+		t.Errorf("Expected the present files of rg, counted once, got %+v", usage)
+	}
+	
+	metrics := WithDiskUsage([]ToolMetrics{{Tool: "jq", Runs: 1}}, map[string]DiskUsage{"rg": usage["rg"]})
+	SortMetricsByDisk(metrics)
+	if len(metrics) != 2 || metrics[0].Tool != "rg" || metrics[0].DiskBytes != 5048 || metrics[0].Runs != 0 || metrics[1].DiskBytes != 0 {
+		t.Errorf("Expected rg added and sorted first, got %+v", metrics)
+	}
+	if got := FormatBytes(5048); got != "4.9 KiB" {
+		t.Errorf("Expected 4.9 KiB, got %s", got)
+	}
+}
+
+func TestUsageTracking(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".boba")
+	binary := filepath.Join(dir, "bin", "rg")
+	os.MkdirAll(filepath.Dir(binary), 0755)
+	os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755)
+	
+	installed := time.Now().Add(-200 * 24 * time.Hour)
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config: &Config{InstalledTools: map[string]InstalledTool{
+			"rg":  {Name: "rg", InstallDate: installed, Provenance: &ToolProvenance{BinaryPaths: []string{binary}}},
+			"old": {Name: "old", InstallDate: installed},
+		}},
+		credentials: &Credentials{},
+	}
+	if cm.UsageTrackingEnabled() || cm.UnusedTools(time.Now()) != nil {
+		t.Fatal("Expected usage tracking to be off by default")
+	}
+	
+	// Turning it on wraps the recorded executables in shims
+	if err := cm.SetUsageTracking(true); err != nil {
+		t.Fatalf("Failed to turn on usage tracking: %v", err)
+	}
+	shim, err := os.ReadFile(filepath.Join(cm.GetShimsDir(), "rg"))
+	if err != nil || !strings.Contains(string(shim), "exec '"+binary+"' \"$@\"") {
+		t.Fatalf("Expected a shim running rg, got %q, %v", shim, err)
+	}
+	if _, err := os.Stat(filepath.Join(cm.GetShimsDir(), "old")); err == nil {
+		t.Error("Expected no shim for a tool without recorded executables")
+	}
+	
+	// Tools are unused once idle for UnusedAfter since the tracking started
+	now := time.Now()
+	if unused := cm.UnusedTools(now); len(unused) != 0 {
+		t.Errorf("Expected no unused tools right after tracking started, got %+v", unused)
+	}
+	later := now.Add(UnusedAfter + time.Hour)
+	if unused := cm.UnusedTools(later); len(unused) != 1 || unused[0].Name != "rg" || !unused[0].LastUsed.IsZero() {
+		t.Errorf("Expected rg unused, never run, got %+v", unused)
+	}
+	usageFile := filepath.Join(configDir, usageDir, "rg")
+	os.MkdirAll(filepath.Dir(usageFile), 0755)
+	os.WriteFile(usageFile, nil, 0644)
+	os.Chtimes(usageFile, later.Add(-time.Hour), later.Add(-time.Hour))
+	if unused := cm.UnusedTools(later); len(unused) != 0 {
+		t.Errorf("Expected a recent use to keep rg, got %+v", unused)
+	}
+	
+	// Forgetting the tool removes its shim, turning tracking off removes the rest
+	cm.RemoveInstalledTool("rg")
+	if _, err := os.Stat(filepath.Join(cm.GetShimsDir(), "rg")); err == nil {
+		t.Error("Expected the shim of an uninstalled tool to be removed")
+	}
+	if err := cm.SetUsageTracking(false); err != nil || cm.UsageTrackingEnabled() {
+		t.Fatalf("Failed to turn off usage tracking: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, usageDir)); err == nil {
+		t.Error("Expected the recorded uses to be removed")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".boba")
+	cm := &ConfigManager{
+		configDir:  configDir,
+		configPath: filepath.Join(configDir, "config.json"),
+		credPath:   filepath.Join(configDir, "credentials.json"),
+		config: &Config{InstalledTools: map[string]InstalledTool{
+			"rg": {Name: "rg", Provenance: &ToolProvenance{BinaryPaths: []string{filepath.Join(home, "bin", "rg")}}},
+		}},
+		credentials: &Credentials{},
+	}
+	if !cm.MaintenanceDue(time.Now()) || cm.MaintenanceInterval() != 7*24*time.Hour {
+		t.Fatal("Expected weekly maintenance to be due before its first run")
+	}
+	
+	// A stale cache file, a fresh one, a log file and an edited managed file
+	stale := filepath.Join(configDir, "cache", "old.json")
+	fresh := filepath.Join(configDir, "cache", "repository.json")
+	os.MkdirAll(filepath.Dir(stale), 0755)
+	os.WriteFile(stale, make([]byte, 2048), 0644)
+	os.WriteFile(fresh, []byte("{}"), 0644)
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	os.Chtimes(stale, old, old)
+	os.MkdirAll(cm.GetLogsDir(), 0700)
+	os.WriteFile(filepath.Join(cm.GetLogsDir(), "boba.log"), []byte("record\n"), 0600)
+	cm.RecordManagedFile(".zshrc", "zsh", []byte("export EDITOR=vim\n"))
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("export EDITOR=nano\n"), 0644)
+	
+	summary := cm.RunMaintenance("")
+	if summary.PrunedFiles != 1 || summary.PrunedBytes != 2048 || !summary.LogRotated || summary.Clone != "" || len(summary.Errors) != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("Expected the fresh cache file to be kept")
+	}
+	want := []string{"rg: " + filepath.Join(home, "bin", "rg") + " was removed", "~/.zshrc was edited since zsh applied it"}
+	if strings.Join(summary.Drift, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected drift %v, got %v", want, summary.Drift)
+	}
+	if lines := summary.Lines(); len(lines) != 3 || !strings.Contains(lines[0], "1 cache file(s) pruned (2.0 KiB), log rotated") {
+		t.Errorf("Unexpected notification: %v", lines)
+	}
+	
+	// The run is recorded, and a negative interval turns maintenance off
+	if cm.MaintenanceDue(time.Now()) || !cm.MaintenanceDue(time.Now().Add(8*24*time.Hour)) {
+		t.Error("Expected maintenance to be due again a week later")
+	}
+	cm.config.MaintenanceDays = -1
+	if cm.MaintenanceDue(time.Now().Add(365 * 24 * time.Hour)) {
+		t.Error("Expected maintenance to be off")
+	}
+}
+
+func TestStartupMarker(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	// Startups that never reach EndStartup count as crashed
+	for expected := 0; expected < SafeModeThreshold+1; expected++ {
+		if crashes := cm.BeginStartup(); crashes != expected {
+			t.Fatalf("Expected %d crashed startups, got %d", expected, crashes)
+		}
+	}
+	cm.EndStartup()
+	if crashes := cm.BeginStartup(); crashes != 0 {
+		t.Errorf("Expected a completed startup to reset the count, got %d", crashes)
+	}
+	
+	if crash, err := cm.LastCrash(); err != nil || crash != "" {
+		t.Errorf("Expected no crash yet, got %q, %v", crash, err)
+	}
+	cm.RecordCrash("first", []byte("stack 1"))
+	cm.RecordCrash("nil map", []byte("goroutine 1 [running]"))
+	if crash, _ := cm.LastCrash(); !strings.Contains(crash, "panic: nil map") || strings.Contains(crash, "first") {
+		t.Errorf("Expected the last crash only, got %q", crash)
+	}
+	
+	cm.SetToolOverride("docker", true)
+	backup, err := cm.ResetConfig()
+	if err != nil || backup == "" {
+		t.Fatalf("Expected a backup of config.json, got %q, %v", backup, err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("Expected the backup to exist: %v", err)
+	}
+	if _, exists := cm.GetToolOverride("docker"); exists {
+		t.Error("Expected the reset configuration to have no overrides")
+	}
+}
+
+func TestWipe(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	if err := cm.InitConfigDir(); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm.SetGitHubToken("ghp_test")
+	cm.SetToolOverride("docker", true)
+	cm.SetEnvironmentOverride("shell", false)
+	os.MkdirAll(filepath.Join(configDir, "cache"), 0755)
+	os.MkdirAll(filepath.Join(configDir, "repos", "dotfiles"), 0755)
+	
+	if _, err := ParseWipeScope("history"); err == nil {
+		t.Error("Expected an unknown scope to be rejected")
+	}
+	
+	backup, err := cm.Wipe(WipeCredentials)
+	if err != nil {
+		t.Fatalf("Failed to wipe credentials: %v", err)
+	}
+	if info, err := os.Stat(backup); err != nil || info.Size() == 0 || filepath.Dir(backup) != cm.GetBackupDir() {
+		t.Errorf("Expected a backup archive in %s, got %q: %v", cm.GetBackupDir(), backup, err)
+	}
+	if _, err := os.Stat(cm.credPath); !os.IsNotExist(err) || cm.GetCredentials().GitHubToken != "" {
+		t.Error("Expected the token to be removed")
+	}
+	if _, exists := cm.GetToolOverride("docker"); !exists {
+		t.Error("Expected wiping credentials to keep the overrides")
+	}
+	
+	if _, err := cm.Wipe(WipeCaches); err != nil {
+		t.Fatalf("Failed to wipe caches: %v", err)
+	}
+	for _, dir := range []string{"cache", "repos"} {
+		if _, err := os.Stat(filepath.Join(configDir, dir)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", dir)
+		}
+	}
+	
+	if _, err := cm.Wipe(WipeOverrides); err != nil {
+		t.Fatalf("Failed to wipe overrides: %v", err)
+	}
+	_, toolOverride := cm.GetToolOverride("docker")
+	_, envOverride := cm.GetEnvironmentOverride("shell")
+	if toolOverride || envOverride {
+		t.Error("Expected all overrides to be removed")
+	}
+	
+	if _, err := cm.Wipe(WipeEverything); err != nil {
+		t.Fatalf("Failed to wipe everything: %v", err)
+	}
+	if _, err := os.Stat(configDir); !os.IsNotExist(err) {
+		t.Error("Expected the BOBA directory to be removed")
+	}
+	if archives, _ := os.ReadDir(cm.GetBackupDir()); len(archives) != 4 {
+		t.Errorf("Expected one archive per wipe, got %d", len(archives))
+	}
+}
+
+func TestInstallCooldown(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	if cooldown := cm.GetInstallCooldown(); cooldown != DefaultInstallCooldown {
+		t.Errorf("Expected the default cooldown, got %v", cooldown)
+	}
+	
+	cm.RecordScriptResult("nvim", false)
+	cm.RecordScriptResult("rg", false)
+	cm.RecordScriptResult("rg", true)
+	now := time.Now()
+	if cooling := cm.ToolsInCooldown(now); len(cooling) != 1 || cooling["nvim"].IsZero() {
+		t.Errorf("Expected only nvim to be cooling down, got %v", cooling)
+	}
+	if cooling := cm.ToolsInCooldown(now.Add(DefaultInstallCooldown)); len(cooling) != 0 {
+		t.Errorf("Expected the cooldown to expire, got %v", cooling)
+	}
+	
+	cm.config.CooldownMinutes = -1
+	if cooling := cm.ToolsInCooldown(now); len(cooling) != 0 {
+		t.Errorf("Expected a negative cooldown to disable it, got %v", cooling)
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	if _, ok, err := cm.LoadRunReport(); ok || err != nil || cm.HasRunReport() {
+		t.Fatalf("Expected no run report yet, got ok=%v err=%v", ok, err)
+	}
+	
+	finished := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := RunReport{
+		FinishedAt: finished,
+		Results: []RunResult{
+			{Name: "git", Phase: "tools", Success: true, Message: "installed"},
+			{Name: "zsh-setup", Phase: "environments", Success: false, Message: "script failed"},
+		},
+	}
+	if err := cm.SaveRunReport(report); err != nil {
+		t.Fatalf("Failed to save run report: %v", err)
+	}
+	
+	loaded, ok, err := cm.LoadRunReport()
+	if !ok || err != nil {
+		t.Fatalf("Expected the run report to load, got ok=%v err=%v", ok, err)
+	}
+	if !loaded.FinishedAt.Equal(finished) || len(loaded.Results) != 2 || loaded.Results[1].Phase != "environments" {
+		t.Errorf("Expected the saved report back, got %+v", loaded)
+	}
+	if loaded.Failed() != 1 {
+		t.Errorf("Expected 1 failed result, got %d", loaded.Failed())
+	}
+	
+	if err := os.WriteFile(cm.GetRunReportPath(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cm.LoadRunReport(); err == nil {
+		t.Error("Expected a corrupt run report to be reported")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := RunReport{
+		FinishedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Results: []RunResult{
+			{Name: "git", Phase: "dependencies", Success: true, Message: "installed"},
+			{Name: "node", Phase: "tools", Success: true},
+			{Name: "yarn", Phase: "tools", Success: false, Message: "\nInstallation failed: exit status 1\nnpm not found"},
+		},
+	}
+	
+	path := filepath.Join(t.TempDir(), "reports", "boba.xml")
+	if err := WriteJUnitFile(path, report); err != nil {
+		t.Fatalf("Failed to write JUnit report: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(data)
+	for _, want := range []string{
+		`<testsuites name="boba" tests="3" failures="1">`,
+		`<testsuite name="tools" tests="2" failures="1" timestamp="2025-03-01T12:00:00">`,
+		`<testcase name="yarn" classname="boba.tools">`,
+		`<failure message="Installation failed: exit status 1">`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("Expected %s in the JUnit report, got:\n%s", want, xml)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	report := RunReport{Results: []RunResult{
+		{Name: "node", Phase: "tools", Success: true},
+		{Name: "yarn", Phase: "tools", Success: false, Message: "\nInstallation failed: exit | 1\nnpm not found"},
+	}}
+	var out strings.Builder
+	if err := WriteMarkdown(&out, report); err != nil {
+		t.Fatalf("Failed to write Markdown report: %v", err)
+	}
+	for _, want := range []string{"1 succeeded, 1 failed", "| ✅ | node | tools |  |", "| ❌ | yarn | tools | Installation failed: exit \\| 1 |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the Markdown report, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestExecutionPlan(t *testing.T) {
+	previous := ExecutionPlan{Entries: []PlanEntry{
+		{Kind: PlanEntryTool, Name: "git", Version: "latest", ScriptHash: "a"},
+		{Kind: PlanEntryTool, Name: "curl", Version: "latest", ScriptHash: "b"},
+		{Kind: PlanEntryTool, Name: "node", Version: "20", ScriptHash: "c"},
+		{Kind: PlanEntryTool, Name: "yarn", Version: "latest", ScriptHash: "d"},
+		{Kind: PlanEntryEnvironment, Name: "zsh", ScriptHash: "e"},
+	}}
+	same := ExecutionPlan{Entries: append([]PlanEntry{}, previous.Entries...), SavedAt: time.Now()}
+	if same.Hash() != previous.Hash() || len(same.Changes(previous)) != 0 {
+		t.Fatal("Expected identical entries to give the same hash and no changes")
+	}
+	
+	current := ExecutionPlan{Entries: []PlanEntry{
+		{Kind: PlanEntryTool, Name: "curl", Version: "latest", ScriptHash: "b"},
+		{Kind: PlanEntryTool, Name: "git", Version: "latest", ScriptHash: "a"},
+		{Kind: PlanEntryTool, Name: "node", Version: "22", ScriptHash: "c"},
+		{Kind: PlanEntryTool, Name: "rust", Version: "latest", ScriptHash: "f"},
+		{Kind: PlanEntryEnvironment, Name: "zsh", ScriptHash: "g"},
+	}}
+	if current.Hash() == previous.Hash() {
+		t.Fatal("Expected a different plan to give a different hash")
+	}
+	
+	var got []string
+	for _, change := range current.Changes(previous) {
+		got = append(got, change.String())
+	}
+	want := []string{
+		"↕ tool curl (moved)",
+		"↕ tool git (moved)",
+		"~ tool node (version 20 → 22)",
+		"+ tool rust (new)",
+		"~ environment zsh (scripts changed)",
+		"- tool yarn (removed)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected changes:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLastPlan(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".boba")
+	cm := &ConfigManager{
+		configDir:   configDir,
+		configPath:  filepath.Join(configDir, "config.json"),
+		credPath:    filepath.Join(configDir, "credentials.json"),
+		config:      &Config{},
+		credentials: &Credentials{},
+	}
+	
+	if _, ok, err := cm.LoadLastPlan(); ok || err != nil {
+		t.Fatalf("Expected no plan before the first run, got ok=%v err=%v", ok, err)
+	}
+	
+	plan := ExecutionPlan{Entries: []PlanEntry{{Kind: PlanEntryTool, Name: "git", Version: "latest", ScriptHash: "a"}}}
+	if err := cm.SaveLastPlan(plan); err != nil {
+		t.Fatalf("Failed to save the plan: %v", err)
+	}
+	loaded, ok, err := cm.LoadLastPlan()
+	if !ok || err != nil || loaded.Hash() != plan.Hash() || loaded.SavedAt.IsZero() {
+		t.Errorf("Expected the saved plan back, got %+v (ok=%v err=%v)", loaded, ok, err)
+	}
+}
+
+func TestPlanFile(t *testing.T) {
+	plan := ExecutionPlan{Entries: []PlanEntry{
+		{Kind: PlanEntryTool, Name: "git", Version: "latest", ScriptHash: "a"},
+		{Kind: PlanEntryEnvironment, Name: "zsh", ScriptHash: "b"},
+	}}
+	
+	for _, name := range []string{"plan.yaml", "plan.json"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := WritePlanFile(path, plan); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		read, err := ReadPlanFile(path)
+		if err != nil || read.Hash() != plan.Hash() || read.PlanHash != plan.Hash() {
+			t.Errorf("Expected %s to read back the same plan, got %+v (%v)", name, read, err)
+		}
+		
+		// Entries edited after the export no longer match plan_hash
+		data, _ := os.ReadFile(path)
+		os.WriteFile(path, []byte(strings.Replace(string(data), "zsh", "bash", 1)), 0644)
+		if _, err := ReadPlanFile(path); err == nil || !strings.Contains(err.Error(), "edited") {
+			t.Errorf("Expected the edited %s to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestPlanApproval(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "admin.key")
+	publicKey, err := GenerateApprovalKey(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+	otherKey, _ := GenerateApprovalKey(filepath.Join(dir, "other.key"))
+	
+	plan := ExecutionPlan{Entries: []PlanEntry{{Kind: PlanEntryTool, Name: "git", Version: "latest", ScriptHash: "a"}}}
+	if err := VerifyPlanApproval(plan, []string{publicKey}); err == nil {
+		t.Error("Expected an unapproved plan to be refused")
+	}
+	
+	if err := SignPlan(&plan, keyPath, "admin"); err != nil {
+		t.Fatalf("Failed to sign the plan: %v", err)
+	}
+	path := filepath.Join(dir, "plan.yaml")
+	if err := WritePlanFile(path, plan); err != nil {
+		t.Fatal(err)
+	}
+	approved, err := ReadPlanFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPlanApproval(approved, []string{otherKey, publicKey}); err != nil {
+		t.Errorf("Expected the approved plan to verify, got %v", err)
+	}
+	if err := VerifyPlanApproval(approved, []string{otherKey}); err == nil || !strings.Contains(err.Error(), "not in plan_approval_keys") {
+		t.Errorf("Expected an approval by an untrusted key to be refused, got %v", err)
+	}
+	
+	approved.Entries[0].ScriptHash = "b"
+	if err := VerifyPlanApproval(approved, []string{publicKey}); err == nil || !strings.Contains(err.Error(), "changed after it was approved") {
+		t.Errorf("Expected a plan changed after its approval to be refused, got %v", err)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("Policy files must be owned by root")
+	}
+	dir := filepath.Join(t.TempDir(), "boba")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	previous := policyPath
+	policyPath = filepath.Join(dir, "policy.json")
+	defer func() { policyPath = previous }()
+	
+	// Without a policy file the machine is not managed
+	if policy, err := LoadPolicy(); err != nil || policy.RequirePlanApproval {
+		t.Errorf("Expected no policy without a policy file, got %+v, %v", policy, err)
+	}
+	
+	// An admin-owned policy file is applied
+	if err := os.WriteFile(policyPath, []byte(`{"require_plan_approval": true, "plan_approval_keys": ["key"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy()
+	if err != nil || !policy.RequirePlanApproval || len(policy.PlanApprovalKeys) != 1 {
+		t.Errorf("Expected the policy to be loaded, got %+v, %v", policy, err)
+	}
+	
+	// A policy file or folder others can write is refused, and nothing can be approved
+	for _, path := range []string{policyPath, dir} {
+		info, _ := os.Stat(path)
+		os.Chmod(path, info.Mode().Perm()|0002)
+		policy, err := LoadPolicy()
+		if err == nil || !policy.RequirePlanApproval || len(policy.PlanApprovalKeys) != 0 {
+			t.Errorf("Expected a policy writable by others at %s to be refused, got %+v, %v", path, policy, err)
+		}
+		os.Chmod(path, info.Mode().Perm())
+	}
+}
+
+func TestEphemeral(t *testing.T) {
+	// Restore the environment of the other tests afterwards
+	t.Setenv(HomeEnv, "")
+	t.Setenv(ephemeralEnv, "")
+	
+	dir, err := StartEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to start the ephemeral session: %v", err)
+	}
+	if !IsEphemeral() {
+		t.Error("Expected the session to be ephemeral")
+	}
+	cm := NewConfigManager()
+	if cm.GetConfigDir() != dir {
+		t.Errorf("Expected the configuration in %s, got %s", dir, cm.GetConfigDir())
+	}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SetToolOverride("ephemeral-tool", true); err != nil {
+		t.Fatal(err)
+	}
+	cm.Flush()
+	if _, err := os.Stat(filepath.Join(dir, "config.json")); err != nil {
+		t.Errorf("Expected config.json in the ephemeral directory: %v", err)
+	}
+	
+	if err := EndEphemeral(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the ephemeral directory to be removed, got %v", err)
+	}
+	if IsEphemeral() {
+		t.Error("Expected the session to end")
+	}
+}
+
+func TestEnvironmentOverrides(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(HomeEnv, "")
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(RepoEnv, "ci/boba-config")
+	t.Setenv(TokenEnv, "ghp_from_env")
+	
+	cm := NewConfigManager()
+	if cm.GetConfigDir() != dir {
+		t.Errorf("Expected the configuration in %s, got %s", dir, cm.GetConfigDir())
+	}
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cm.LoadCredentials()
+	if cm.GetConfig().RepositoryURL != "ci/boba-config" || cm.GetCredentials().GitHubToken != "ghp_from_env" {
+		t.Errorf("Expected the environment to take precedence, got %q and %q", cm.GetConfig().RepositoryURL, cm.GetCredentials().GitHubToken)
+	}
+	if !cm.RepositoryFromEnvironment() || !cm.TokenFromEnvironment() || len(ActiveEnvironmentOverrides()) != 3 {
+		t.Error("Expected all three overrides to be reported")
+	}
+	
+	// Changes made during the session stay in memory
+	cm.SetRepositoryURL("ci/other")
+	cm.SetGitHubToken("ghp_typed")
+	cm.SetToolOverride("docker", true)
+	cm.Flush()
+	if cm.GetConfig().RepositoryURL != "ci/other" {
+		t.Errorf("Expected the session repository, got %q", cm.GetConfig().RepositoryURL)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if strings.Contains(string(data), "ci/") || !strings.Contains(string(data), "docker") {
+		t.Errorf("Expected only the override toggle in config.json, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "credentials.json")); !os.IsNotExist(err) {
+		t.Error("Expected the token not to be saved")
+	}
+	
+	// The BOBA_HOME of an ephemeral session wins
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	if cm := NewConfigManager(); cm.GetConfigDir() != home {
+		t.Errorf("Expected BOBA_HOME to take precedence, got %s", cm.GetConfigDir())
+	}
+}
+
+func TestInstallHistory(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cm := &ConfigManager{config: &Config{InstalledTools: map[string]InstalledTool{
+		"go":   {Name: "go", InstallDate: base, LastUpdateDate: base.Add(72 * time.Hour)},
+		"node": {Name: "node", InstallDate: base.Add(48 * time.Hour)},
+		"rg":   {Name: "rg", InstallDate: base.Add(24 * time.Hour)},
+	}}}
+	
+	names := func(order string) string {
+		history, err := cm.InstallHistory(order)
+		if err != nil {
+			t.Fatalf("InstallHistory(%q): %v", order, err)
+		}
+		var result []string
+		for _, record := range history {
+			result = append(result, record.Name)
+		}
+		return strings.Join(result, ",")
+	}
+	if got := names(HistoryByInstallDate); got != "node,rg,go" {
+		t.Errorf("Expected the most recent install first, got %s", got)
+	}
+	if got := names(HistoryByLastUpdate); got != "go,node,rg" {
+		t.Errorf("Expected the update of go to count as its last activity, got %s", got)
+	}
+	if got := names(HistoryByName); got != "go,node,rg" {
+		t.Errorf("Expected alphabetical order, got %s", got)
+	}
+	if _, err := cm.InstallHistory("size"); err == nil {
+		t.Error("Expected an unknown order to be rejected")
+	}
+}
+
+func TestMigrationSettings(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	installedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := &ConfigManager{config: &Config{
+		RepositoryURL:   "acme/boba-config",
+		ToolOverrides:   map[string]bool{"node": true},
+		InstalledTools:  map[string]InstalledTool{"node": {Name: "node", InstallDate: installedAt}},
+		LocalRepoPath:   "/home/old/boba-config",
+		BinaryChecksums: map[string]string{"/usr/local/bin/boba": "abc"},
+	}}
+	settings := old.PortableSettings()
+	if settings.InstalledTools != nil || settings.LocalRepoPath != "" || settings.BinaryChecksums != nil || !settings.ToolOverrides["node"] {
+		t.Errorf("Expected only the portable settings, got %+v", settings)
+	}
+	
+	// The new machine keeps its own state
+	cm := NewConfigManager()
+	cm.LoadConfig()
+	cm.RecordToolInstallationWithProvenance("git", "2.43", "auto", nil)
+	if err := cm.ImportSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewConfigManager()
+	reloaded.LoadConfig()
+	cfg := reloaded.GetConfig()
+	if cfg.RepositoryURL != "acme/boba-config" || !cfg.ToolOverrides["node"] || len(cfg.InstalledTools) != 1 || cfg.InstalledTools["git"].Version != "2.43" {
+		t.Errorf("Expected the imported settings with the records of this machine, got %+v", cfg)
+	}
+	
+	path := filepath.Join(t.TempDir(), MigrationFile)
+	migration := Migration{Settings: settings, Lockfile: ExecutionPlan{Entries: []PlanEntry{{Kind: PlanEntryTool, Name: "node", ScriptHash: "1"}}}}
+	if err := WriteMigrationFile(path, migration); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the migration to be private, got %v", info.Mode().Perm())
+	}
+	if read, err := ReadMigrationFile(path); err != nil || len(read.Lockfile.Entries) != 1 || read.Settings.RepositoryURL != "acme/boba-config" {
+		t.Errorf("Expected the migration to read back, got %+v (%v)", read, err)
+	}
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), `"name": "node"`, `"name": "rust"`, 1)), 0600)
+	if _, err := ReadMigrationFile(path); err == nil || !strings.Contains(err.Error(), "edited") {
+		t.Errorf("Expected an edited lockfile to be rejected, got %v", err)
+	}
+}
+
+
+func TestRepositoryProvider(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	t.Setenv(RepoEnv, "")
+	t.Setenv(TokenEnv, "")
+	t.Setenv(GitLabTokenEnv, "")
+	t.Setenv(BitbucketTokenEnv, "")
+	
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cm.LoadCredentials()
+	cm.SetGitHubToken("ghp_token")
+	
+	// The provider follows the repository URL until it is set
+	cm.SetRepositoryURL("acme/boba-config")
+	if cm.UsesGitLab() || cm.RepositoryToken() != "ghp_token" || cm.RepositoryTokenEnv() != TokenEnv {
+		t.Errorf("Expected a GitHub repository, got %s", cm.GetRepositoryProvider())
+	}
+	cm.SetRepositoryURL("https://gitlab.com/acme/boba-config")
+	if !cm.UsesGitLab() || cm.HasRepositoryToken() {
+		t.Errorf("Expected a GitLab repository without a token, got %s", cm.GetRepositoryProvider())
+	}
+	
+	// Self-managed instances on other hosts set the provider
+	cm.SetRepositoryURL("https://code.acme.dev/platform/boba-config")
+	if cm.UsesGitLab() {
+		t.Error("Expected an unknown host to default to GitHub")
+	}
+	if err := cm.SetRepositoryProvider(RepositoryProviderGitLab); err != nil || !cm.UsesGitLab() {
+		t.Errorf("Expected the provider to be set, got %v", err)
+	}
+	if err := cm.SetRepositoryProvider("sourcehut"); err == nil {
+		t.Error("Expected an unknown provider to be refused")
+	}
+	
+	// The GitLab token is stored next to the GitHub token
+	if err := cm.SetRepositoryToken("glpat-token"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewConfigManager()
+	reloaded.LoadCredentials()
+	if credentials := reloaded.GetCredentials(); credentials.GitLabToken != "glpat-token" || credentials.GitHubToken != "ghp_token" {
+		t.Errorf("Expected both tokens to be saved, got %+v", credentials)
+	}
+	
+	// BOBA_GITLAB_TOKEN wins and is never saved
+	t.Setenv(GitLabTokenEnv, "glpat-from-env")
+	fromEnv := NewConfigManager()
+	fromEnv.LoadCredentials()
+	fromEnv.SetGitLabToken("glpat-typed")
+	if fromEnv.GetCredentials().GitLabToken != "glpat-typed" {
+		t.Errorf("Expected the session token, got %q", fromEnv.GetCredentials().GitLabToken)
+	}
+	reloaded = NewConfigManager()
+	reloaded.LoadCredentials()
+	if token := reloaded.GetCredentials().GitLabToken; token != "glpat-from-env" {
+		t.Errorf("Expected the environment token, got %q", token)
+	}
+	
+	// Bitbucket takes the username and app password together
+	cm.SetRepositoryProvider("")
+	cm.SetRepositoryURL("https://bitbucket.org/acme/boba-config")
+	if !cm.UsesBitbucket() || cm.HasRepositoryToken() || cm.RepositoryTokenEnv() != BitbucketTokenEnv {
+		t.Errorf("Expected a Bitbucket repository without credentials, got %s", cm.GetRepositoryProvider())
+	}
+	if err := cm.SetRepositoryToken("app-password"); err == nil {
+		t.Error("Expected an app password without a username to be refused")
+	}
+	if err := cm.SetRepositoryToken("someone:app-password"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded = NewConfigManager()
+	reloaded.LoadCredentials()
+	if credentials := reloaded.GetCredentials(); credentials.BitbucketUsername != "someone" || credentials.BitbucketAppPassword != "app-password" {
+		t.Errorf("Expected the Bitbucket credentials to be saved, got %+v", credentials)
+	}
+	t.Setenv(BitbucketTokenEnv, "ci:from-env")
+	fromEnv = NewConfigManager()
+	fromEnv.LoadConfig()
+	fromEnv.LoadCredentials()
+	if token := fromEnv.RepositoryToken(); token != "ci:from-env" {
+		t.Errorf("Expected the environment credentials, got %q", token)
+	}
+	
+	// The git source reads a clone over SSH, without a token
+	if !cm.NeedsRepositoryToken() || cm.UsesGitSource() {
+		t.Error("Expected the hosting API by default")
+	}
+	if err := cm.SetSourceType("svn"); err == nil {
+		t.Error("Expected an unknown source type to be refused")
+	}
+	if err := cm.SetSourceType(SourceTypeGit); err != nil {
+		t.Fatal(err)
+	}
+	reloaded = NewConfigManager()
+	reloaded.LoadConfig()
+	if !reloaded.UsesGitSource() || reloaded.NeedsRepositoryToken() || reloaded.RepositoryHostName() != "the SSH remote" {
+		t.Errorf("Expected the git source to be saved, got %q", reloaded.GetConfig().SourceType)
+	}
+}
+
+func TestManagedFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cm.GetManagedFile(".zshrc"); ok {
+		t.Error("Expected no record before the file is applied")
+	}
+	if err := cm.RecordManagedFile(".zshrc", "zsh", []byte("export EDITOR=vim\n")); err != nil {
+		t.Fatal(err)
+	}
+	
+	reloaded := NewConfigManager()
+	reloaded.LoadConfig()
+	record, base, ok := reloaded.GetManagedFile(".zshrc")
+	if !ok || record.Environment != "zsh" || string(base) != "export EDITOR=vim\n" || record.Hash != HashContent(base) {
+		t.Errorf("Expected the applied file to be remembered, got %+v %q", record, base)
+	}
+	if settings := reloaded.PortableSettings(); settings.ManagedFiles != nil {
+		t.Error("Expected managed files to stay on this machine")
+	}
+	
+	// A base copy that no longer matches its hash can't be trusted for a merge
+	os.WriteFile(filepath.Join(home, managedFilesDir, ".zshrc"), []byte("tampered\n"), 0600)
+	if _, _, ok := reloaded.GetManagedFile(".zshrc"); ok {
+		t.Error("Expected a modified base copy to be ignored")
+	}
+	if err := reloaded.ForgetManagedFile(".zshrc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.GetConfig().ManagedFiles[".zshrc"]; ok {
+		t.Error("Expected the record to be forgotten")
+	}
+}
+
+func TestFileOwners(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cm.SetFileOwner(".zshrc", "zsh-work")
+	cm.SetFileOwner(".profile", FileMergeStrategy)
+	
+	reloaded := NewConfigManager()
+	reloaded.LoadConfig()
+	if owner, ok := reloaded.GetFileOwner(".zshrc"); !ok || owner != "zsh-work" {
+		t.Errorf("Expected the owner to be saved, got %q", owner)
+	}
+	reloaded.SetFileOwner(".profile", "")
+	if owners := reloaded.GetFileOwners(); len(owners) != 1 || owners[".profile"] != "" {
+		t.Errorf("Expected the merge of .profile to be cleared, got %v", owners)
+	}
+}
+
+func TestRestorePoints(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnv, t.TempDir())
+	
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cm.RecordToolInstallation("git", "latest", "auto")
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("export A=1\n"), 0640)
+	
+	point, err := cm.CreateRestorePoint("Before Install Everything", []string{".gitconfig", "../outside", ".zshrc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(point.Files) != len(ShellRCFiles)+1 || len(point.InstalledTools) != 1 {
+		t.Errorf("Expected the rc files, .gitconfig and the installed tool, got %+v", point)
+	}
+	
+	// Changes made by the run
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("export A=2\n"), 0644)
+	os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[user]\n"), 0644)
+	cm.RecordToolInstallation("node", "20", "auto")
+	cm.RecordEnvironmentApplied("zsh")
+	
+	result, err := cm.RollBack(point.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".zshrc")); string(content) != "export A=1\n" {
+		t.Errorf("Expected .zshrc to be restored, got %q", content)
+	}
+	if info, _ := os.Stat(filepath.Join(home, ".zshrc")); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected the mode to be restored, got %v", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(home, ".gitconfig")); !os.IsNotExist(err) {
+		t.Error("Expected .gitconfig created since the restore point to be removed")
+	}
+	if len(result.Restored) != 1 || len(result.Removed) != 1 || len(result.InstalledSince) != 1 || result.InstalledSince[0] != "node" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if _, applied := cm.GetEnvironmentAppliedDate("zsh"); applied {
+		t.Error("Expected the environment applied since to be cleared")
+	}
+	
+	// The state before the roll back is a restore point of its own, listed first
+	points, err := cm.ListRestorePoints()
+	if err != nil || len(points) != 2 || points[0].ID != result.Undo.ID {
+		t.Fatalf("Expected the undo restore point first, got %+v (%v)", points, err)
+	}
+	if _, err := cm.RollBack(result.Undo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".gitconfig")); string(content) != "[user]\n" {
+		t.Errorf("Expected the roll back to be undone, got %q", content)
+	}
+	
+	// Only the newest restore points are kept
+	for i := 0; i < maxRestorePoints; i++ {
+		if _, err := cm.CreateRestorePoint(fmt.Sprintf("run %d", i), nil); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	points, _ = cm.ListRestorePoints()
+	if len(points) != maxRestorePoints || points[0].Name != fmt.Sprintf("run %d", maxRestorePoints-1) {
+		t.Errorf("Expected the %d newest restore points, got %d", maxRestorePoints, len(points))
+	}
+	if err := cm.DeleteRestorePoint(points[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.DeleteRestorePoint("../config.json"); err == nil {
+		t.Error("Expected an invalid restore point to be refused")
+	}
+}
+
+func TestOperationQueue(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	cm := NewConfigManager()
+	
+	end, err := cm.BeginOperation(context.Background(), "install rg")
+	if err != nil {
+		t.Fatalf("Failed to begin an operation: %v", err)
+	}
+	running := cm.RunningOperation()
+	if running == nil || running.Name != "install rg" || running.PID != os.Getpid() || running.StartedAt.IsZero() {
+		t.Fatalf("Expected install rg to be running, got %+v", running)
+	}
+	
+	// The next operations wait in line, and a canceled one fails
+	begin := func(name string) chan error {
+		done := make(chan error, 1)
+		go func() {
+			end, err := cm.BeginOperation(context.Background(), name)
+			if err == nil {
+				end()
+			}
+			done <- err
+		}()
+		return done
+	}
+	waitQueued := func(count int) []Operation {
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if queued := cm.QueuedOperations(); len(queued) == count || time.Now().After(deadline) {
+				return queued
+			}
+		}
+	}
+	first := begin("apply zsh")
+	waitQueued(1)
+	second := begin("install fd")
+	queued := waitQueued(2)
+	if len(queued) != 2 || queued[0].Name != "apply zsh" || queued[1].Name != "install fd" {
+		t.Fatalf("Expected apply zsh then install fd in the queue, got %+v", queued)
+	}
+	if err := cm.CancelOperation(queued[1].ID); err != nil {
+		t.Fatalf("Failed to cancel install fd: %v", err)
+	}
+	if err := <-second; !errors.Is(err, ErrOperationCanceled) {
+		t.Errorf("Expected install fd to be canceled, got %v", err)
+	}
+	if err := cm.CancelOperation(queued[1].ID); err == nil {
+		t.Error("Expected canceling an operation no longer queued to fail")
+	}
+	
+	end()
+	if err := <-first; err != nil {
+		t.Errorf("Expected apply zsh to run once install rg ended, got %v", err)
+	}
+	if running := cm.RunningOperation(); running != nil || len(cm.QueuedOperations()) != 0 {
+		t.Errorf("Expected the queue to be empty, got %+v and %+v", running, cm.QueuedOperations())
+	}
+	
+	// A waiting operation gives up with its context
+	end, _ = cm.BeginOperation(context.Background(), "install rg")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cm.BeginOperation(ctx, "apply zsh"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to end the wait, got %v", err)
+	}
+	if queued := cm.QueuedOperations(); len(queued) != 0 {
+		t.Errorf("Expected the operation to leave the queue, got %+v", queued)
+	}
+	end()
+	
+	// The lock and queue entries of exited processes are removed
+	gone := Operation{ID: "gone", Name: "install bat", PID: 1 << 30, QueuedAt: time.Now()}
+	writeOperation(filepath.Join(cm.configDir, operationLockFile), gone, true)
+	writeOperation(filepath.Join(cm.configDir, operationQueueDir, "gone.json"), gone, false)
+	if running := cm.RunningOperation(); running != nil {
+		t.Errorf("Expected the lock of an exited process to be removed, got %+v", running)
+	}
+	if queued := cm.QueuedOperations(); len(queued) != 0 {
+		t.Errorf("Expected the queue entry of an exited process to be removed, got %+v", queued)
+	}
+	if end, err := cm.BeginOperation(context.Background(), "install bat"); err != nil {
+		t.Errorf("Expected to run after a stale lock, got %v", err)
+	} else {
+		end()
+	}
+	
+	// Taking over a stale lock that another process replaced in the meantime keeps the new lock
+	lock := filepath.Join(cm.configDir, operationLockFile)
+	live := Operation{ID: "live", Name: "install fd", PID: os.Getpid(), QueuedAt: time.Now()}
+	writeOperation(lock, live, true)
+	removeStaleLock(lock, "gone")
+	if running := cm.RunningOperation(); running == nil || running.ID != "live" {
+		t.Errorf("Expected the live lock to be kept, got %+v", running)
+	}
+	if matches, _ := filepath.Glob(lock + ".*"); len(matches) != 0 {
+		t.Errorf("Expected no claim left, got %v", matches)
+	}
+	os.Remove(lock)
+	
+	// Processes finding the same stale lock race to take it over, and the one that gets the lock
+	// keeps it against the takeovers still running
+	for round := 0; round < 50; round++ {
+		writeOperation(lock, gone, true)
+		var wg sync.WaitGroup
+		var holders atomic.Int32
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				removeStaleLock(lock, gone.ID)
+				op := Operation{ID: fmt.Sprintf("live-%d", i), Name: "install fd", PID: os.Getpid(), QueuedAt: time.Now()}
+				if writeOperation(lock, op, true) == nil {
+					holders.Add(1)
+				}
+				removeStaleLock(lock, gone.ID)
+			}(i)
+		}
+		wg.Wait()
+		if holders.Load() != 1 {
+			t.Fatalf("Expected one process to hold the lock, got %d", holders.Load())
+		}
+		if running := cm.RunningOperation(); running == nil {
+			t.Fatal("Expected the live lock to survive the stale lock takeovers")
+		}
+		os.Remove(lock)
+	}
+	if matches, _ := filepath.Glob(lock + ".*"); len(matches) != 0 {
+		t.Errorf("Expected no claim left, got %v", matches)
+	}
+	writeOperation(lock, live, true)
+	
+	// Ending an operation leaves a lock it no longer holds alone
+	releaseLock(lock, "gone")
+	if running := cm.RunningOperation(); running == nil {
+		t.Error("Expected another operation's lock to be kept")
+	}
+	releaseLock(lock, "live")
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes the run report as a Markdown table, one row per tool or environment, for
+// CI job summaries such as the GitHub Actions step summary
+func WriteMarkdown(w io.Writer, report RunReport) error {
+	var out strings.Builder
+	failed := report.Failed()
+	out.WriteString("### BOBA run\n\n")
+	if len(report.Results) == 0 {
+		out.WriteString("Nothing was installed.\n")
+		_, err := io.WriteString(w, out.String())
+		return err
+	}
+	fmt.Fprintf(&out, "%d succeeded, %d failed\n\n", len(report.Results)-failed, failed)
+	out.WriteString("| | Name | Phase | Details |\n|---|---|---|---|\n")
+	for _, result := range report.Results {
+		status := "✅"
+		if !result.Success {
+			status = "❌"
+		}
+		fmt.Fprintf(&out, "| %s | %s | %s | %s |\n", status, markdownCell(result.Name), markdownCell(result.Phase), markdownCell(firstLine(result.Message)))
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// markdownCell escapes the characters that would break a table cell
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ", "\r", "").Replace(value)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// metricsFile holds the metrics of past tool installs, next to config.json
+const metricsFile = "metrics.json"
+
+// MaxMetricsRecords is the number of tool installs kept in the metrics history; older ones are dropped
+const MaxMetricsRecords = 2000
+
+// ToolRunMetric records the cost of installing one tool in one run
+type ToolRunMetric struct {
+	Tool          string        `json:"tool"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Duration      time.Duration `json:"duration_ns"`              // Wall time of the install
+	DownloadBytes int64         `json:"download_bytes,omitempty"` // Script bytes fetched from the repository
+	Retries       int           `json:"retries,omitempty"`        // Consecutive failed installs before this one
+	Success       bool          `json:"success"`
+}
+
+// ToolMetrics aggregates the recorded installs of a tool across runs
+type ToolMetrics struct {
+	Tool          string        `json:"tool"`
+	Runs          int           `json:"runs"`
+	Failures      int           `json:"failures"`
+	Retries       int           `json:"retries"` // Installs that retried a previous failure
+	MeanDuration  time.Duration `json:"mean_duration_ns"`
+	P95Duration   time.Duration `json:"p95_duration_ns"`
+	DownloadBytes int64         `json:"download_bytes"` // Average script bytes fetched per install
+	DiskBytes     int64         `json:"disk_bytes,omitempty"` // Space the installed tool's recorded files take now
+}
+
+// GetMetricsPath returns the path of the metrics history
+func (cm *ConfigManager) GetMetricsPath() string {
+	return filepath.Join(cm.configDir, metricsFile)
+}
+
+// RecordToolRun records the outcome of a tool install: its metrics are added to the history,
+// with the consecutive failures before it as retry count, then its failure count is updated
+func (cm *ConfigManager) RecordToolRun(toolName string, ok bool, duration time.Duration, downloadBytes int64) error {
+	cm.ensureHealthConfig()
+	metric := ToolRunMetric{
+		Tool:          toolName,
+		FinishedAt:    time.Now(),
+		Duration:      duration,
+		DownloadBytes: downloadBytes,
+		Retries:       cm.config.Health.ScriptFailures[toolName],
+		Success:       ok,
+	}
+	err := cm.appendMetric(metric)
+	if recordErr := cm.RecordScriptResult(toolName, ok); recordErr != nil {
+		return recordErr
+	}
+	return err
+}
+
+// appendMetric adds a record to the metrics history, keeping the MaxMetricsRecords most recent
+func (cm *ConfigManager) appendMetric(metric ToolRunMetric) error {
+	records, err := cm.LoadMetrics()
+	if err != nil {
+		records = nil // Start over rather than failing every install on a corrupt file
+	}
+	records = append(records, metric)
+	if len(records) > MaxMetricsRecords {
+		records = records[len(records)-MaxMetricsRecords:]
+	}
+	
+	if err := cm.InitConfigDir(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	tmpPath := cm.GetMetricsPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return os.Rename(tmpPath, cm.GetMetricsPath())
+}
+
+// LoadMetrics reads the metrics history, oldest first; it is empty when nothing was recorded yet
+func (cm *ConfigManager) LoadMetrics() ([]ToolRunMetric, error) {
+	data, err := os.ReadFile(cm.GetMetricsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	
+	var records []ToolRunMetric
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return records, nil
+}
+
+// AggregateMetrics summarizes the history per tool, slowest p95 install time first
+func AggregateMetrics(records []ToolRunMetric) []ToolMetrics {
+	durations := make(map[string][]time.Duration)
+	byTool := make(map[string]*ToolMetrics)
+	var order []string
+	for _, record := range records {
+		metrics, ok := byTool[record.Tool]
+		if !ok {
+			metrics = &ToolMetrics{Tool: record.Tool}
+			byTool[record.Tool] = metrics
+			order = append(order, record.Tool)
+		}
+		metrics.Runs++
+		if !record.Success {
+			metrics.Failures++
+		}
+		if record.Retries > 0 {
+			metrics.Retries++
+		}
+		metrics.DownloadBytes += record.DownloadBytes
+		durations[record.Tool] = append(durations[record.Tool], record.Duration)
+	}
+	
+	aggregated := make([]ToolMetrics, 0, len(order))
+	for _, tool := range order {
+		metrics := byTool[tool]
+		var total time.Duration
+		for _, duration := range durations[tool] {
+			total += duration
+		}
+		metrics.MeanDuration = total / time.Duration(metrics.Runs)
+		metrics.P95Duration = percentile(durations[tool], 95)
+		metrics.DownloadBytes /= int64(metrics.Runs)
+		aggregated = append(aggregated, *metrics)
+	}
+	sort.SliceStable(aggregated, func(i, j int) bool {
+		if aggregated[i].P95Duration != aggregated[j].P95Duration {
+			return aggregated[i].P95Duration > aggregated[j].P95Duration
+		}
+		return aggregated[i].Tool < aggregated[j].Tool
+	})
+	return aggregated
+}
+
+// percentile returns the nearest-rank percentile of the durations
+func percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// migrationVersion is bumped when the migration file layout changes
+const migrationVersion = 1
+
+// MigrationFile is the default name of the file `boba migrate export` writes
+const MigrationFile = "boba-migration.json"
+
+// Migration moves BOBA to a new machine: the portable settings and a lockfile of what is
+// installed. The GitHub token is never part of it.
+type Migration struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Hostname   string        `json:"hostname,omitempty"` // Machine the migration was exported from
+	Settings   Config        `json:"settings"`           // Settings without the state of the old machine, see PortableSettings
+	Lockfile   ExecutionPlan `json:"lockfile"`           // Installed tools and applied environments in run order, with their script hashes
+}
+
+// PortableSettings returns the configuration without the state that only describes this machine:
+// installation records, applied environments, managed files, sync time, local repository path,
+// SSH key path, binary checksums, health counters, usage tracking, the last maintenance run and
+// the JUnit report path
+func (cm *ConfigManager) PortableSettings() Config {
+	settings := cm.GetConfig()
+	settings.InstalledTools = nil
+	settings.AppliedEnvironments = nil
+	settings.ManagedFiles = nil
+	settings.LastSync = time.Time{}
+	settings.LocalRepoPath = ""
+	settings.SSHKeyPath = ""
+	settings.BinaryChecksums = nil
+	settings.Health = HealthStats{}
+	settings.UsageTrackingSince = time.Time{}
+	settings.LastMaintenance = time.Time{}
+	settings.JUnitReportPath = ""
+	return settings
+}
+
+// ImportSettings replaces the settings with the portable settings of another machine, keeping
+// the state of this one, and saves the configuration
+func (cm *ConfigManager) ImportSettings(settings Config) error {
+	current := cm.GetConfig()
+	settings.InstalledTools = current.InstalledTools
+	settings.AppliedEnvironments = current.AppliedEnvironments
+	settings.ManagedFiles = current.ManagedFiles
+	settings.LastSync = current.LastSync
+	settings.LocalRepoPath = current.LocalRepoPath
+	settings.SSHKeyPath = current.SSHKeyPath
+	settings.BinaryChecksums = current.BinaryChecksums
+	settings.Health = current.Health
+	settings.UsageTrackingSince = current.UsageTrackingSince
+	settings.LastMaintenance = current.LastMaintenance
+	settings.JUnitReportPath = current.JUnitReportPath
+	if settings.ToolOverrides == nil {
+		settings.ToolOverrides = make(map[string]bool)
+	}
+	if settings.EnvironmentOverrides == nil {
+		settings.EnvironmentOverrides = make(map[string]bool)
+	}
+	if settings.InstalledTools == nil {
+		settings.InstalledTools = make(map[string]InstalledTool)
+	}
+	cm.config = &settings
+	return cm.SaveConfig()
+}
+
+// WriteMigrationFile writes a migration. The settings may hold script variables such as proxy
+// credentials, so only the owner can read the file.
+func WriteMigrationFile(path string, migration Migration) error {
+	migration.Version = migrationVersion
+	migration.Lockfile.PlanHash = migration.Lockfile.Hash()
+	if migration.ExportedAt.IsZero() {
+		migration.ExportedAt = time.Now()
+	}
+	data, err := json.MarshalIndent(migration, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write migration: %w", err)
+	}
+	return nil
+}
+
+// ReadMigrationFile reads a migration written by WriteMigrationFile. A lockfile edited after the
+// export is rejected like an edited plan.
+func ReadMigrationFile(path string) (Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to read migration: %w", err)
+	}
+	var migration Migration
+	if err := json.Unmarshal(data, &migration); err != nil {
+		return Migration{}, fmt.Errorf("failed to parse migration: %w", err)
+	}
+	if migration.Version != migrationVersion {
+		return Migration{}, fmt.Errorf("migration %s has version %d, this BOBA reads version %d: use the same BOBA release on both machines", path, migration.Version, migrationVersion)
+	}
+	if migration.Lockfile.PlanHash != migration.Lockfile.Hash() {
+		return Migration{}, fmt.Errorf("migration %s was edited after it was exported: its lockfile does not match plan_hash", path)
+	}
+	return migration, nil
+}
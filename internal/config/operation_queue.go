@@ -0,0 +1,268 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"boba/internal/log"
+)
+
+// Files of the operation queue, next to config.json: the lock of the running operation, and a file
+// per operation waiting for its turn
+const (
+	operationLockFile = "operation.lock"
+	operationQueueDir = "queue"
+)
+
+// operationPollInterval is how often a queued operation checks whether its turn came
+const operationPollInterval = 500 * time.Millisecond
+
+// ErrOperationCanceled is returned to an operation removed from the queue before its turn
+var ErrOperationCanceled = errors.New("the operation was canceled while queued")
+
+// Operation is an installation or environment operation, running or waiting for its turn, in
+// any BOBA process of this machine
+type Operation struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`    // What it does, e.g. "install ripgrep"
+	Command   string    `json:"command"` // Command line of the process, "boba" for the UI
+	PID       int       `json:"pid"`
+	QueuedAt  time.Time `json:"queued_at"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+}
+
+// BeginOperation waits until no other operation runs and the operations queued earlier went
+// first, then runs the operation: it returns the function ending it. Queued operations are listed
+// by QueuedOperations and can be canceled with CancelOperation, which returns
+// ErrOperationCanceled here.
+func (cm *ConfigManager) BeginOperation(ctx context.Context, name string) (func(), error) {
+	op := Operation{
+		ID:       fmt.Sprintf("%d-%d", time.Now().UnixNano(), os.Getpid()),
+		Name:     name,
+		Command:  strings.Join(append([]string{"boba"}, os.Args[1:]...), " "),
+		PID:      os.Getpid(),
+		QueuedAt: time.Now(),
+	}
+	if cm.PersistenceDisabled() {
+		return nil, ErrPersistenceDisabled
+	}
+	entry := filepath.Join(cm.configDir, operationQueueDir, op.ID+".json")
+	if err := writeOperation(entry, op, false); err != nil {
+		return nil, fmt.Errorf("failed to queue %s: %w", name, err)
+	}
+
+	for waited := false; ; waited = true {
+		started, err := cm.tryStartOperation(op, entry)
+		if err != nil {
+			os.Remove(entry)
+			return nil, err
+		}
+		if started {
+			lock := filepath.Join(cm.configDir, operationLockFile)
+			return func() { releaseLock(lock, op.ID) }, nil
+		}
+		if running := cm.RunningOperation(); running != nil && !waited {
+			log.Warn("Waiting for another BOBA operation to finish", "operation", running.Name, "command", running.Command, "pid", running.PID)
+		}
+		select {
+		case <-ctx.Done():
+			os.Remove(entry)
+			return nil, ctx.Err()
+		case <-time.After(operationPollInterval):
+		}
+	}
+}
+
+// tryStartOperation takes the lock for the operation when it is first in the queue and no other
+// operation runs
+func (cm *ConfigManager) tryStartOperation(op Operation, entry string) (bool, error) {
+	if _, err := os.Stat(entry); os.IsNotExist(err) {
+		return false, ErrOperationCanceled
+	}
+	if running := cm.RunningOperation(); running != nil {
+		return false, nil
+	}
+	if queued := cm.QueuedOperations(); len(queued) > 0 && queued[0].ID != op.ID {
+		return false, nil
+	}
+
+	op.StartedAt = time.Now()
+	if err := writeOperation(filepath.Join(cm.configDir, operationLockFile), op, true); err != nil {
+		if os.IsExist(err) {
+			return false, nil // Another process took it first
+		}
+		return false, fmt.Errorf("failed to lock %s: %w", op.Name, err)
+	}
+	os.Remove(entry)
+	return true, nil
+}
+
+// RunningOperation returns the operation running now, or nil. The lock of a process that exited
+// without releasing it is removed.
+func (cm *ConfigManager) RunningOperation() *Operation {
+	lock := filepath.Join(cm.configDir, operationLockFile)
+	op, stale := readLock(lock)
+	if stale != "" {
+		removeStaleLock(lock, stale)
+		return nil
+	}
+	return op
+}
+
+// staleLockAge is how old a lock that can't be read, or a claim on a stale lock, must be before
+// its process is taken for dead
+const staleLockAge = 10 * time.Second
+
+// readLock reads the operation lock: the running operation, or nil with the identity of a stale
+// lock, left by a process that exited without releasing it
+func readLock(path string) (*Operation, string) {
+	op, err := readOperation(path)
+	if err != nil {
+		// A lock left empty or half written by a process that died while taking it
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) <= staleLockAge {
+			return nil, ""
+		}
+		return nil, fmt.Sprintf("unreadable-%d", info.ModTime().UnixNano())
+	}
+	if !processAlive(op.PID) {
+		return nil, op.ID
+	}
+	return &op, ""
+}
+
+// lockIdentity returns what readLock identifies the lock by: the ID of its operation, or when it
+// can't be read the time it was written
+func lockIdentity(path string) string {
+	if op, err := readOperation(path); err == nil {
+		return op.ID
+	}
+	if info, err := os.Stat(path); err == nil {
+		return fmt.Sprintf("unreadable-%d", info.ModTime().UnixNano())
+	}
+	return ""
+}
+
+// removeStaleLock removes the stale operation lock with the identity. Several processes may find
+// it stale at once, and one of them may already have removed it and another taken the lock since,
+// so a lock is never moved or replaced: the first process to claim this identity removes the lock
+// only while it still is the stale one. Nobody else removes it meanwhile, as its process exited.
+func removeStaleLock(path, identity string) {
+	claim := path + ".stale-" + filepath.Base(identity)
+	file, err := os.OpenFile(claim, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		// Another process takes it over; the claim of one that died doing so is dropped
+		if info, statErr := os.Stat(claim); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(claim)
+		}
+		return
+	}
+	file.Close()
+	defer os.Remove(claim)
+	if lockIdentity(path) == identity {
+		os.Remove(path)
+	}
+}
+
+// releaseLock removes the operation lock when the operation with the ID still holds it
+func releaseLock(path, id string) {
+	if op, err := readOperation(path); err == nil && op.ID == id {
+		os.Remove(path)
+	}
+}
+
+// QueuedOperations returns the operations waiting for their turn, first in line first. The
+// entries of processes that exited are removed.
+func (cm *ConfigManager) QueuedOperations() []Operation {
+	dir := filepath.Join(cm.configDir, operationQueueDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var queued []Operation
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		op, err := readOperation(path)
+		if err != nil {
+			continue // Being written
+		}
+		if !processAlive(op.PID) {
+			os.Remove(path)
+			continue
+		}
+		queued = append(queued, op)
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		if !queued[i].QueuedAt.Equal(queued[j].QueuedAt) {
+			return queued[i].QueuedAt.Before(queued[j].QueuedAt)
+		}
+		return queued[i].ID < queued[j].ID
+	})
+	return queued
+}
+
+// CancelOperation removes a queued operation, which then fails with ErrOperationCanceled. A
+// running operation can't be canceled this way.
+func (cm *ConfigManager) CancelOperation(id string) error {
+	err := os.Remove(filepath.Join(cm.configDir, operationQueueDir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("operation %s is no longer queued", id)
+	}
+	return err
+}
+
+// writeOperation writes an operation to its file; exclusive fails when the file exists
+func writeOperation(path string, op Operation, exclusive bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if exclusive {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// readOperation reads an operation file
+func readOperation(path string) (Operation, error) {
+	var op Operation
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return op, err
+	}
+	err = json.Unmarshal(data, &op)
+	return op, err
+}
+
+// processAlive reports whether a process with the PID is running
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lastPlanFile holds the plan of the last successful Install Everything run, next to config.json
+const lastPlanFile = "last_plan.json"
+
+// Kinds of plan entries
+const (
+	PlanEntryTool        = "tool"
+	PlanEntryEnvironment = "environment"
+)
+
+// PlanEntry is one operation of a resolved installation plan
+type PlanEntry struct {
+	Kind       string `json:"kind" yaml:"kind"` // tool or environment
+	Name       string `json:"name" yaml:"name"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`
+	ScriptHash string `json:"script_hash" yaml:"script_hash"` // SHA-256 of the scripts (and config files) the operation runs
+	
+	// Repository paths of the scripts and config files, for reviewers; not part of the plan hash
+	Scripts []string `json:"scripts,omitempty" yaml:"scripts,omitempty"`
+}
+
+// key identifies the entry across plans
+func (e PlanEntry) key() string {
+	return e.Kind + "/" + e.Name
+}
+
+// ExecutionPlan is the resolved, ordered list of operations of an Install Everything run
+type ExecutionPlan struct {
+	PlanHash string        `json:"plan_hash,omitempty" yaml:"plan_hash,omitempty"` // Hash of the entries, set in exported plans
+	Entries  []PlanEntry   `json:"entries" yaml:"entries"`
+	SavedAt  time.Time     `json:"saved_at,omitempty" yaml:"saved_at,omitempty"`
+	Approval *PlanApproval `json:"approval,omitempty" yaml:"approval,omitempty"` // Admin signature of the entries, see SignPlan
+}
+
+// Hash returns a digest of the entries and their order, identical for identical plans
+func (p ExecutionPlan) Hash() string {
+	hash := sha256.New()
+	for _, entry := range p.Entries {
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\n", entry.Kind, entry.Name, entry.Version, entry.ScriptHash)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Kinds of plan changes
+const (
+	PlanAdded   = "added"
+	PlanRemoved = "removed"
+	PlanChanged = "changed"
+	PlanMoved   = "moved"
+)
+
+// PlanChange is an entry that differs from the previous plan
+type PlanChange struct {
+	Entry  PlanEntry
+	Change string // added, removed, changed or moved
+	Detail string // What changed, for changed entries
+}
+
+// String describes the change on one line
+func (c PlanChange) String() string {
+	switch c.Change {
+	case PlanAdded:
+		return fmt.Sprintf("+ %s %s (new)", c.Entry.Kind, c.Entry.Name)
+	case PlanRemoved:
+		return fmt.Sprintf("- %s %s (removed)", c.Entry.Kind, c.Entry.Name)
+	case PlanChanged:
+		return fmt.Sprintf("~ %s %s (%s)", c.Entry.Kind, c.Entry.Name, c.Detail)
+	}
+	return fmt.Sprintf("↕ %s %s (moved)", c.Entry.Kind, c.Entry.Name)
+}
+
+// Changes lists the entries added, changed or moved since the previous plan, in plan order,
+// followed by the removed entries
+func (p ExecutionPlan) Changes(previous ExecutionPlan) []PlanChange {
+	before := make(map[string]PlanEntry, len(previous.Entries))
+	for _, entry := range previous.Entries {
+		before[entry.key()] = entry
+	}
+	current := make(map[string]bool, len(p.Entries))
+	for _, entry := range p.Entries {
+		current[entry.key()] = true
+	}
+	
+	// Position of each entry among the entries both plans share
+	position := func(entries []PlanEntry, shared func(string) bool) map[string]int {
+		positions := make(map[string]int)
+		for _, entry := range entries {
+			if shared(entry.key()) {
+				positions[entry.key()] = len(positions)
+			}
+		}
+		return positions
+	}
+	oldPositions := position(previous.Entries, func(key string) bool { return current[key] })
+	newPositions := position(p.Entries, func(key string) bool { _, ok := before[key]; return ok })
+	
+	var changes []PlanChange
+	for _, entry := range p.Entries {
+		old, existed := before[entry.key()]
+		switch {
+		case !existed:
+			changes = append(changes, PlanChange{Entry: entry, Change: PlanAdded})
+		case old.Version != entry.Version:
+			changes = append(changes, PlanChange{Entry: entry, Change: PlanChanged, Detail: fmt.Sprintf("version %s → %s", old.Version, entry.Version)})
+		case old.ScriptHash != entry.ScriptHash:
+			changes = append(changes, PlanChange{Entry: entry, Change: PlanChanged, Detail: "scripts changed"})
+		case oldPositions[entry.key()] != newPositions[entry.key()]:
+			changes = append(changes, PlanChange{Entry: entry, Change: PlanMoved})
+		}
+	}
+	for _, entry := range previous.Entries {
+		if !current[entry.key()] {
+			changes = append(changes, PlanChange{Entry: entry, Change: PlanRemoved})
+		}
+	}
+	return changes
+}
+
+// GetLastPlanPath returns the path of the last successful plan
+func (cm *ConfigManager) GetLastPlanPath() string {
+	return filepath.Join(cm.configDir, lastPlanFile)
+}
+
+// SaveLastPlan records the plan of a successful run
+func (cm *ConfigManager) SaveLastPlan(plan ExecutionPlan) error {
+	if err := cm.InitConfigDir(); err != nil {
+		return err
+	}
+	
+	plan.SavedAt = time.Now()
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	
+	if err := os.WriteFile(cm.GetLastPlanPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// LoadLastPlan reads the plan of the last successful run; ok is false when none was recorded yet
+func (cm *ConfigManager) LoadLastPlan() (plan ExecutionPlan, ok bool, err error) {
+	data, err := os.ReadFile(cm.GetLastPlanPath())
+	if os.IsNotExist(err) {
+		return ExecutionPlan{}, false, nil
+	}
+	if err != nil {
+		return ExecutionPlan{}, false, fmt.Errorf("failed to read plan: %w", err)
+	}
+	
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return ExecutionPlan{}, false, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return plan, true, nil
+}
+
+// WritePlanFile exports a plan for review, as JSON when the path ends in .json and as YAML otherwise.
+// A plan written again, such as after its approval, keeps its export time.
+func WritePlanFile(path string, plan ExecutionPlan) error {
+	data, err := EncodePlan(plan, strings.EqualFold(filepath.Ext(path), ".json"))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// EncodePlan encodes a plan for export, as JSON or YAML, with its plan hash and export time set
+func EncodePlan(plan ExecutionPlan, asJSON bool) ([]byte, error) {
+	plan.PlanHash = plan.Hash()
+	if plan.SavedAt.IsZero() {
+		plan.SavedAt = time.Now()
+	}
+	
+	if !asJSON {
+		data, err := yaml.Marshal(plan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal plan: %w", err)
+		}
+		return data, nil
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ReadPlanFile reads an exported plan. A plan whose entries no longer match its plan_hash was
+// edited after the export and is rejected.
+func ReadPlanFile(path string) (ExecutionPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ExecutionPlan{}, fmt.Errorf("failed to read plan: %w", err)
+	}
+	
+	var plan ExecutionPlan
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &plan)
+	} else {
+		err = yaml.Unmarshal(data, &plan)
+	}
+	if err != nil {
+		return ExecutionPlan{}, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	
+	if len(plan.Entries) == 0 {
+		return ExecutionPlan{}, fmt.Errorf("plan %s has no entries", path)
+	}
+	if plan.PlanHash != "" && plan.PlanHash != plan.Hash() {
+		return ExecutionPlan{}, fmt.Errorf("plan %s was edited after it was exported: its entries do not match plan_hash", path)
+	}
+	return plan, nil
+}
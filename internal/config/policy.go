@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Policy is the machine policy an admin sets on managed machines. It is read from an
+// admin-owned file, never from config.json, so a user can't lift it.
+type Policy struct {
+	// Managed mode: only run plans signed by one of these admin keys (base64 Ed25519 public keys)
+	RequirePlanApproval bool     `json:"require_plan_approval"`
+	PlanApprovalKeys    []string `json:"plan_approval_keys"`
+}
+
+// policyPath is the machine policy file, see PolicyPath
+var policyPath = defaultPolicyPath()
+
+// PolicyPath returns the machine policy file: /etc/boba/policy.json, or
+// %ProgramData%\boba\policy.json on Windows
+func PolicyPath() string {
+	return policyPath
+}
+
+// LoadPolicy reads the machine policy. Without a policy file the machine is not managed. A
+// policy file that users could have written, because it or its folder is not owned by an admin
+// or is writable by others, is refused with an error, and the returned policy then requires
+// approvals that no key can give, so nothing runs until an admin fixes it.
+func LoadPolicy() (Policy, error) {
+	locked := Policy{RequirePlanApproval: true}
+
+	data, err := os.ReadFile(policyPath)
+	if os.IsNotExist(err) {
+		return Policy{}, nil
+	}
+	if err != nil {
+		return locked, fmt.Errorf("failed to read policy %s: %w", policyPath, err)
+	}
+	for _, path := range []string{policyPath, filepath.Dir(policyPath)} {
+		if err := checkAdminOwned(path); err != nil {
+			return locked, fmt.Errorf("policy %s is refused: %w", policyPath, err)
+		}
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return locked, fmt.Errorf("failed to parse policy %s: %w", policyPath, err)
+	}
+	return policy, nil
+}
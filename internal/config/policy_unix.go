@@ -0,0 +1,30 @@
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// defaultPolicyPath is the machine policy file on Linux and macOS
+func defaultPolicyPath() string {
+	return "/etc/boba/policy.json"
+}
+
+// checkAdminOwned refuses a file or folder that is not owned by root or that group or others
+// can write
+func checkAdminOwned(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 {
+		return fmt.Errorf("%s is not owned by root", path)
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("%s is writable by group or others (mode %s)", path, info.Mode().Perm())
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultPolicyPath is the machine policy file on Windows, under ProgramData, which only
+// administrators can write by default
+func defaultPolicyPath() string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	return filepath.Join(programData, "boba", "policy.json")
+}
+
+// checkAdminOwned relies on the permissions ProgramData gives new folders: only administrators
+// can write them
+func checkAdminOwned(path string) error {
+	_, err := os.Stat(path)
+	return err
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+
+	"boba/internal/github"
+)
+
+// Hosts the configuration repository can live on
+const (
+	RepositoryProviderGitHub    = "github"
+	RepositoryProviderGitLab    = github.ProviderGitLab
+	RepositoryProviderBitbucket = github.ProviderBitbucket
+)
+
+// Where the tools and environments are read from, as named by source_type
+const (
+	SourceTypeAPI = "api" // The API of GitHub, GitLab or Bitbucket
+	SourceTypeGit = "git" // A clone of any git remote reached over SSH
+)
+
+// UsesGitSource reports whether the repository is read from a clone of an SSH remote instead of
+// a hosting API
+func (cm *ConfigManager) UsesGitSource() bool {
+	return cm.GetConfig().SourceType == SourceTypeGit
+}
+
+// SetSourceType sets where the tools and environments are read from, "" for the hosting API
+func (cm *ConfigManager) SetSourceType(sourceType string) error {
+	switch sourceType {
+	case "", SourceTypeAPI, SourceTypeGit:
+	default:
+		return fmt.Errorf("unknown source type %q: use %s or %s", sourceType, SourceTypeAPI, SourceTypeGit)
+	}
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	
+	cm.config.SourceType = sourceType
+	return cm.SaveConfig()
+}
+
+// GetRepositoryProvider returns where the configuration repository is hosted: repository_provider
+// when it is set, GitLab for gitlab.com and gitlab.* repository URLs, Bitbucket for bitbucket.org
+// repository URLs, GitHub otherwise
+func (cm *ConfigManager) GetRepositoryProvider() string {
+	cfg := cm.GetConfig()
+	if cfg.RepositoryProvider != "" {
+		return cfg.RepositoryProvider
+	}
+	if github.IsGitLabURL(cfg.RepositoryURL) {
+		return RepositoryProviderGitLab
+	}
+	if github.IsBitbucketURL(cfg.RepositoryURL) {
+		return RepositoryProviderBitbucket
+	}
+	return RepositoryProviderGitHub
+}
+
+// UsesGitLab reports whether the configuration repository is hosted on GitLab
+func (cm *ConfigManager) UsesGitLab() bool {
+	return cm.GetRepositoryProvider() == RepositoryProviderGitLab
+}
+
+// UsesBitbucket reports whether the configuration repository is hosted on Bitbucket Cloud
+func (cm *ConfigManager) UsesBitbucket() bool {
+	return cm.GetRepositoryProvider() == RepositoryProviderBitbucket
+}
+
+// RepositoryHostName names the host of the configuration repository in messages
+func (cm *ConfigManager) RepositoryHostName() string {
+	if cm.UsesGitSource() {
+		return "the SSH remote"
+	}
+	switch cm.GetRepositoryProvider() {
+	case RepositoryProviderGitLab:
+		return "GitLab"
+	case RepositoryProviderBitbucket:
+		return "Bitbucket"
+	}
+	return "GitHub"
+}
+
+// SetRepositoryProvider sets the host of the configuration repository, "" to detect it from the URL
+func (cm *ConfigManager) SetRepositoryProvider(provider string) error {
+	switch provider {
+	case "", RepositoryProviderGitHub, RepositoryProviderGitLab, RepositoryProviderBitbucket:
+	default:
+		return fmt.Errorf("unknown repository provider %q: use %s, %s or %s", provider, RepositoryProviderGitHub, RepositoryProviderGitLab, RepositoryProviderBitbucket)
+	}
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+
+	cm.config.RepositoryProvider = provider
+	return cm.SaveConfig()
+}
+
+// HasGitLabToken checks if a GitLab token is configured
+func (cm *ConfigManager) HasGitLabToken() bool {
+	return cm.GetCredentials().GitLabToken != ""
+}
+
+// SetGitLabToken sets the GitLab token in credentials
+func (cm *ConfigManager) SetGitLabToken(token string) error {
+	// BOBA_GITLAB_TOKEN is kept for the session and never written to credentials.json
+	if cm.gitlabTokenOverride != "" {
+		cm.gitlabTokenOverride = token
+		return nil
+	}
+	if cm.credentials == nil {
+		cm.credentials = &Credentials{}
+	}
+
+	cm.credentials.GitLabToken = token
+	return cm.SaveCredentials()
+}
+
+// HasBitbucketCredentials checks if a Bitbucket username and app password are configured
+func (cm *ConfigManager) HasBitbucketCredentials() bool {
+	credentials := cm.GetCredentials()
+	return credentials.BitbucketUsername != "" && credentials.BitbucketAppPassword != ""
+}
+
+// SetBitbucketCredentials sets the Bitbucket username and app password in credentials
+func (cm *ConfigManager) SetBitbucketCredentials(username, appPassword string) error {
+	// BOBA_BITBUCKET_TOKEN is kept for the session and never written to credentials.json
+	if cm.bitbucketTokenOverride != "" {
+		cm.bitbucketTokenOverride = username + ":" + appPassword
+		return nil
+	}
+	if cm.credentials == nil {
+		cm.credentials = &Credentials{}
+	}
+
+	cm.credentials.BitbucketUsername = username
+	cm.credentials.BitbucketAppPassword = appPassword
+	return cm.SaveCredentials()
+}
+
+// RepositoryToken returns the token for the host of the configuration repository. The token of
+// Bitbucket is the username and app password, as username:app_password.
+func (cm *ConfigManager) RepositoryToken() string {
+	credentials := cm.GetCredentials()
+	switch cm.GetRepositoryProvider() {
+	case RepositoryProviderGitLab:
+		return credentials.GitLabToken
+	case RepositoryProviderBitbucket:
+		if !cm.HasBitbucketCredentials() {
+			return ""
+		}
+		return credentials.BitbucketUsername + ":" + credentials.BitbucketAppPassword
+	}
+	return credentials.GitHubToken
+}
+
+// NeedsRepositoryToken reports whether the repository is read through a hosting API with a token.
+// Local mode reads a directory, and the git source authenticates with SSH keys.
+func (cm *ConfigManager) NeedsRepositoryToken() bool {
+	return cm.GetConfig().LocalRepoPath == "" && !cm.UsesGitSource()
+}
+
+// HasRepositoryToken checks if a token is configured for the host of the configuration repository
+func (cm *ConfigManager) HasRepositoryToken() bool {
+	return cm.RepositoryToken() != ""
+}
+
+// SetRepositoryToken stores the token for the host of the configuration repository
+func (cm *ConfigManager) SetRepositoryToken(token string) error {
+	switch cm.GetRepositoryProvider() {
+	case RepositoryProviderGitLab:
+		return cm.SetGitLabToken(token)
+	case RepositoryProviderBitbucket:
+		username, appPassword := github.SplitBitbucketToken(token)
+		if appPassword == "" {
+			return fmt.Errorf("expected the Bitbucket username and app password as username:app_password")
+		}
+		return cm.SetBitbucketCredentials(username, appPassword)
+	}
+	return cm.SetGitHubToken(token)
+}
+
+// RepositoryTokenEnv returns the variable that overrides the token of the repository's host
+func (cm *ConfigManager) RepositoryTokenEnv() string {
+	switch cm.GetRepositoryProvider() {
+	case RepositoryProviderGitLab:
+		return GitLabTokenEnv
+	case RepositoryProviderBitbucket:
+		return BitbucketTokenEnv
+	}
+	return TokenEnv
+}
+
+// RepositoryCredentialsHint says where to set the credentials of a repository hosted on GitLab or
+// Bitbucket, which BOBA can't sign in to interactively
+func (cm *ConfigManager) RepositoryCredentialsHint() string {
+	if cm.UsesBitbucket() {
+		return fmt.Sprintf("set %s to username:app_password, or bitbucket_username and bitbucket_app_password in credentials.json", BitbucketTokenEnv)
+	}
+	return fmt.Sprintf("set %s or gitlab_token in credentials.json", GitLabTokenEnv)
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WipeScope selects what a reset removes from the BOBA directory
+type WipeScope string
+
+// Reset scopes, from the most targeted to everything
+const (
+	WipeCredentials WipeScope = "credentials" // The saved GitHub token
+	WipeCaches      WipeScope = "caches"      // The repository listing cache and the local repository clones
+	WipeOverrides   WipeScope = "overrides"   // Tool and environment overrides
+	WipeEverything  WipeScope = "everything"  // The whole BOBA directory
+)
+
+// WipeScopes lists the reset scopes in the order they are offered
+var WipeScopes = []WipeScope{WipeCredentials, WipeCaches, WipeOverrides, WipeEverything}
+
+// ParseWipeScope returns the reset scope with the given name
+func ParseWipeScope(name string) (WipeScope, error) {
+	for _, scope := range WipeScopes {
+		if string(scope) == name {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown reset scope %q: use credentials, caches, overrides or everything", name)
+}
+
+// Description says what a reset of the scope removes
+func (s WipeScope) Description() string {
+	switch s {
+	case WipeCredentials:
+		return "the saved GitHub token"
+	case WipeCaches:
+		return "the repository listing cache and the local repository clones"
+	case WipeOverrides:
+		return "all tool and environment overrides"
+	case WipeEverything:
+		return "the whole BOBA directory: configuration, token, records, caches and clones"
+	}
+	return string(s)
+}
+
+// GetBackupDir returns the directory of the archives written before a reset, next to the BOBA
+// directory so that wiping everything keeps them
+func (cm *ConfigManager) GetBackupDir() string {
+	return cm.configDir + "-backups"
+}
+
+// Wipe backs up the BOBA directory to a .tar.gz archive, then removes what the scope covers.
+// It returns the path of the archive.
+func (cm *ConfigManager) Wipe(scope WipeScope) (string, error) {
+	backupPath, err := cm.backupConfigDir(scope)
+	if err != nil {
+		return "", err
+	}
+	
+	switch scope {
+	case WipeCredentials:
+		if err := os.Remove(cm.credPath); err != nil && !os.IsNotExist(err) {
+			return backupPath, fmt.Errorf("failed to remove credentials: %w", err)
+		}
+		cm.credentials = &Credentials{}
+	case WipeCaches:
+		if err := cm.ClearCache(); err != nil {
+			return backupPath, err
+		}
+		if err := os.RemoveAll(filepath.Join(cm.configDir, "repos")); err != nil {
+			return backupPath, fmt.Errorf("failed to remove repository clones: %w", err)
+		}
+	case WipeOverrides:
+		if err := cm.ResetAllToolOverrides(); err != nil {
+			return backupPath, err
+		}
+		if err := cm.ResetAllEnvironmentOverrides(); err != nil {
+			return backupPath, err
+		}
+	case WipeEverything:
+		if err := os.RemoveAll(cm.configDir); err != nil {
+			return backupPath, fmt.Errorf("failed to remove %s: %w", cm.configDir, err)
+		}
+		cm.config = &Config{
+			ToolOverrides:        make(map[string]bool),
+			EnvironmentOverrides: make(map[string]bool),
+			InstalledTools:       make(map[string]InstalledTool),
+		}
+		cm.credentials = &Credentials{}
+		cm.notify(ConfigChange{})
+	default:
+		return "", fmt.Errorf("unknown reset scope %q", scope)
+	}
+	return backupPath, nil
+}
+
+// backupConfigDir writes the BOBA directory to a new archive in the backup directory
+func (cm *ConfigManager) backupConfigDir(scope WipeScope) (string, error) {
+	// Persist batched changes so the archive holds the current state
+	if err := cm.Flush(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(cm.GetBackupDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	
+	backupPath := filepath.Join(cm.GetBackupDir(), fmt.Sprintf("boba-%s-%s.tar.gz", scope, time.Now().Format("20060102-150405.000")))
+	// The archive holds the token: keep it private like credentials.json
+	file, err := os.OpenFile(backupPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup: %w", err)
+	}
+	
+	if err := writeArchive(file, cm.configDir); err != nil {
+		file.Close()
+		os.Remove(backupPath)
+		return "", fmt.Errorf("failed to back up %s: %w", cm.configDir, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(backupPath)
+		return "", fmt.Errorf("failed to back up %s: %w", cm.configDir, err)
+	}
+	return backupPath, nil
+}
+
+// writeArchive writes the regular files and directories under root to w as a gzipped tar
+// archive, with paths relative to root. A missing root gives an empty archive.
+func writeArchive(w io.Writer, root string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == root {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil // Skip symlinks, sockets and other special files
+		}
+		
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// restorePointsDir holds the restore points, next to config.json
+const restorePointsDir = "restore-points"
+
+// maxRestorePoints is how many restore points are kept; older ones are removed when a new one is saved
+const maxRestorePoints = 10
+
+// ShellRCFiles are the shell startup files, relative to $HOME, saved in every restore point
+var ShellRCFiles = []string{".zshrc", ".bashrc", ".profile", ".bash_profile", ".zprofile", ".config/fish/config.fish"}
+
+// RestorePoint is the managed state saved before a major operation: the shell rc files and
+// environment config files in $HOME, and the installed tools and applied environments
+type RestorePoint struct {
+	ID                  string                   `json:"id"`
+	Name                string                   `json:"name"` // The operation it was saved before, e.g. "Before Install Everything"
+	CreatedAt           time.Time                `json:"created_at"`
+	Files               []RestoreFile            `json:"files"`
+	InstalledTools      map[string]InstalledTool `json:"installed_tools"`
+	AppliedEnvironments map[string]time.Time     `json:"applied_environments"`
+}
+
+// RestoreFile is a file of $HOME saved in a restore point
+type RestoreFile struct {
+	Path    string      `json:"path"`    // Relative to $HOME
+	Existed bool        `json:"existed"` // Whether the file existed; restoring removes files created since
+	Mode    os.FileMode `json:"mode,omitempty"`
+	Copy    string      `json:"copy,omitempty"` // Name of the saved copy in the restore point directory
+}
+
+// RestoreResult describes what restoring a restore point changed, and what it could not undo
+type RestoreResult struct {
+	Restored         []string      // Files written back with their content at the restore point
+	Removed          []string      // Files created since the restore point
+	InstalledSince   []string      // Tools installed since: still installed, uninstall them to remove them
+	UninstalledSince []string      // Tools uninstalled since: install them again to get them back
+	Undo             *RestorePoint // Restore point of the state before the restore
+}
+
+// GetRestorePointsDir returns the directory of the restore points
+func (cm *ConfigManager) GetRestorePointsDir() string {
+	return filepath.Join(cm.configDir, restorePointsDir)
+}
+
+// CreateRestorePoint saves the shell rc files, the given files of $HOME (e.g. the config files of
+// the environments about to be applied) and the installation records, then removes the oldest
+// restore points beyond the limit
+func (cm *ConfigManager) CreateRestorePoint(name string, files []string) (*RestorePoint, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	now := time.Now()
+	point := &RestorePoint{
+		ID:                  now.Format("20060102-150405.000"),
+		Name:                name,
+		CreatedAt:           now,
+		InstalledTools:      cm.GetAllInstalledTools(),
+		AppliedEnvironments: make(map[string]time.Time),
+	}
+	if cm.config != nil {
+		for env, applied := range cm.config.AppliedEnvironments {
+			point.AppliedEnvironments[env] = applied
+		}
+	}
+
+	if cm.PersistenceDisabled() {
+		return nil, ErrPersistenceDisabled
+	}
+	if err := os.MkdirAll(cm.GetRestorePointsDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create restore point: %w", err)
+	}
+	// Restore points saved within the same millisecond, like the one saved before a roll back, get a suffix
+	dir := filepath.Join(cm.GetRestorePointsDir(), point.ID)
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create restore point: %w", err)
+		}
+		point.ID = fmt.Sprintf("%s-%d", point.CreatedAt.Format("20060102-150405.000"), n)
+		dir = filepath.Join(cm.GetRestorePointsDir(), point.ID)
+	}
+	for i, path := range restoreFileList(files) {
+		file := RestoreFile{Path: path}
+		info, err := os.Stat(filepath.Join(home, path))
+		if err == nil && info.Mode().IsRegular() {
+			content, err := os.ReadFile(filepath.Join(home, path))
+			if err != nil {
+				os.RemoveAll(dir)
+				return nil, fmt.Errorf("failed to save %s: %w", path, err)
+			}
+			file.Existed = true
+			file.Mode = info.Mode().Perm()
+			file.Copy = fmt.Sprintf("file-%d", i)
+			if err := os.WriteFile(filepath.Join(dir, file.Copy), content, 0600); err != nil {
+				os.RemoveAll(dir)
+				return nil, fmt.Errorf("failed to save %s: %w", path, err)
+			}
+		} else if err == nil {
+			continue // Directories and special files are not managed
+		}
+		point.Files = append(point.Files, file)
+	}
+
+	data, err := json.MarshalIndent(point, "", "  ")
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to marshal restore point: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "restore-point.json"), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write restore point: %w", err)
+	}
+
+	cm.pruneRestorePoints()
+	return point, nil
+}
+
+// restoreFileList returns the shell rc files and the given files, cleaned, without duplicates and
+// without paths leaving $HOME
+func restoreFileList(files []string) []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, path := range append(append([]string{}, ShellRCFiles...), files...) {
+		path = filepath.Clean(path)
+		if path == "." || filepath.IsAbs(path) || strings.HasPrefix(path, "..") || seen[path] {
+			continue
+		}
+		seen[path] = true
+		list = append(list, path)
+	}
+	return list
+}
+
+// ListRestorePoints returns the saved restore points, newest first
+func (cm *ConfigManager) ListRestorePoints() ([]RestorePoint, error) {
+	entries, err := os.ReadDir(cm.GetRestorePointsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restore points: %w", err)
+	}
+
+	var points []RestorePoint
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		point, err := cm.GetRestorePoint(entry.Name())
+		if err != nil {
+			continue // Skip incomplete restore points
+		}
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].CreatedAt.After(points[j].CreatedAt) })
+	return points, nil
+}
+
+// GetRestorePoint reads the restore point with the given ID
+func (cm *ConfigManager) GetRestorePoint(id string) (*RestorePoint, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid restore point %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(cm.GetRestorePointsDir(), id, "restore-point.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restore point %s: %w", id, err)
+	}
+
+	var point RestorePoint
+	if err := json.Unmarshal(data, &point); err != nil {
+		return nil, fmt.Errorf("failed to parse restore point %s: %w", id, err)
+	}
+	point.ID = id
+	return &point, nil
+}
+
+// DeleteRestorePoint removes a restore point
+func (cm *ConfigManager) DeleteRestorePoint(id string) error {
+	if _, err := cm.GetRestorePoint(id); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(cm.GetRestorePointsDir(), id)); err != nil {
+		return fmt.Errorf("failed to remove restore point %s: %w", id, err)
+	}
+	return nil
+}
+
+// pruneRestorePoints removes the oldest restore points beyond the limit
+func (cm *ConfigManager) pruneRestorePoints() {
+	points, err := cm.ListRestorePoints()
+	if err != nil || len(points) <= maxRestorePoints {
+		return
+	}
+	for _, point := range points[maxRestorePoints:] {
+		os.RemoveAll(filepath.Join(cm.GetRestorePointsDir(), point.ID))
+	}
+}
+
+// RollBack rolls the managed state back to a restore point: the saved files are written
+// back, files created since are removed and the applied environments are the ones of the restore
+// point. Tools can't be uninstalled or installed again this way, so the ones installed or
+// uninstalled since are only reported. The current state is saved as a new restore point first.
+func (cm *ConfigManager) RollBack(id string) (*RestoreResult, error) {
+	point, err := cm.GetRestorePoint(id)
+	if err != nil {
+		return nil, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	// Read the saved copies first: saving the current state may prune this restore point
+	dir := filepath.Join(cm.GetRestorePointsDir(), point.ID)
+	saved := make(map[string][]byte)
+	var paths []string
+	for _, file := range point.Files {
+		paths = append(paths, file.Path)
+		if file.Existed {
+			content, err := os.ReadFile(filepath.Join(dir, file.Copy))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the saved %s: %w", file.Path, err)
+			}
+			saved[file.Path] = content
+		}
+	}
+	undo, err := cm.CreateRestorePoint("Before restoring "+point.Name, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{Undo: undo}
+	for _, file := range point.Files {
+		target := filepath.Join(home, file.Path)
+		if !file.Existed {
+			if err := os.Remove(target); err == nil {
+				result.Removed = append(result.Removed, file.Path)
+			} else if !os.IsNotExist(err) {
+				return result, fmt.Errorf("failed to remove %s: %w", file.Path, err)
+			}
+			continue
+		}
+
+		content := saved[file.Path]
+		if current, err := os.ReadFile(target); err == nil && bytes.Equal(current, content) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return result, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(target, content, file.Mode); err != nil {
+			return result, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		result.Restored = append(result.Restored, file.Path)
+	}
+
+	installed := cm.GetAllInstalledTools()
+	for name := range installed {
+		if _, ok := point.InstalledTools[name]; !ok {
+			result.InstalledSince = append(result.InstalledSince, name)
+		}
+	}
+	for name := range point.InstalledTools {
+		if _, ok := installed[name]; !ok {
+			result.UninstalledSince = append(result.UninstalledSince, name)
+		}
+	}
+	sort.Strings(result.InstalledSince)
+	sort.Strings(result.UninstalledSince)
+
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	cm.config.AppliedEnvironments = make(map[string]time.Time)
+	for env, applied := range point.AppliedEnvironments {
+		cm.config.AppliedEnvironments[env] = applied
+	}
+	return result, cm.SaveConfig()
+}
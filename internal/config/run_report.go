@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runReportFile holds the results of the most recent installation run, next to config.json
+const runReportFile = "last_run.json"
+
+// RunReport records the results of an installation run so they can be reviewed after the
+// results screen is dismissed or BOBA is restarted
+type RunReport struct {
+	FinishedAt time.Time   `json:"finished_at"`
+	Results    []RunResult `json:"results"`
+}
+
+// RunResult is the outcome of one tool or environment in a run
+type RunResult struct {
+	Name    string `json:"name"`
+	Phase   string `json:"phase,omitempty"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// Failed returns the number of failed results in the run
+func (r RunReport) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Success {
+			failed++
+		}
+	}
+	return failed
+}
+
+// GetRunReportPath returns the path of the last run report
+func (cm *ConfigManager) GetRunReportPath() string {
+	return filepath.Join(cm.configDir, runReportFile)
+}
+
+// HasRunReport reports whether a run was recorded, without reading the report
+func (cm *ConfigManager) HasRunReport() bool {
+	_, err := os.Stat(cm.GetRunReportPath())
+	return err == nil
+}
+
+// SaveRunReport replaces the last run report
+func (cm *ConfigManager) SaveRunReport(report RunReport) error {
+	if err := cm.InitConfigDir(); err != nil {
+		return err
+	}
+	
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run report: %w", err)
+	}
+	
+	if err := os.WriteFile(cm.GetRunReportPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
+
+// LoadRunReport reads the last run report; ok is false when no run was recorded yet
+func (cm *ConfigManager) LoadRunReport() (report RunReport, ok bool, err error) {
+	data, err := os.ReadFile(cm.GetRunReportPath())
+	if os.IsNotExist(err) {
+		return RunReport{}, false, nil
+	}
+	if err != nil {
+		return RunReport{}, false, fmt.Errorf("failed to read run report: %w", err)
+	}
+	
+	if err := json.Unmarshal(data, &report); err != nil {
+		return RunReport{}, false, fmt.Errorf("failed to parse run report: %w", err)
+	}
+	return report, true, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sessionFile holds the UI navigation state of the last session, next to config.json
+const sessionFile = "session.json"
+
+// SessionState records where the user was in the UI so a restarted BOBA can return there
+type SessionState struct {
+	Menu               string    `json:"menu"`
+	MenuStack          []string  `json:"menu_stack,omitempty"`
+	Cursor             int       `json:"cursor"`
+	InterruptedInstall bool      `json:"interrupted_install,omitempty"`
+	SavedAt            time.Time `json:"saved_at"`
+}
+
+// GetSessionPath returns the path of the saved session state
+func (cm *ConfigManager) GetSessionPath() string {
+	return filepath.Join(cm.configDir, sessionFile)
+}
+
+// SaveSession replaces the saved session state
+func (cm *ConfigManager) SaveSession(state SessionState) error {
+	if err := cm.InitConfigDir(); err != nil {
+		return err
+	}
+	
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session state: %w", err)
+	}
+	
+	if err := os.WriteFile(cm.GetSessionPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	return nil
+}
+
+// LoadSession reads the saved session state; ok is false when no session was saved yet
+func (cm *ConfigManager) LoadSession() (state SessionState, ok bool, err error) {
+	data, err := os.ReadFile(cm.GetSessionPath())
+	if os.IsNotExist(err) {
+		return SessionState{}, false, nil
+	}
+	if err != nil {
+		return SessionState{}, false, fmt.Errorf("failed to read session state: %w", err)
+	}
+	
+	if err := json.Unmarshal(data, &state); err != nil {
+		return SessionState{}, false, fmt.Errorf("failed to parse session state: %w", err)
+	}
+	return state, true, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"sort"
+	"time"
+)
+
+// KnownFailingThreshold is the number of consecutive failed installs after which a tool is known-failing
+const KnownFailingThreshold = 3
+
+// IsToolSkipped reports whether the user asked Install Everything to skip the tool
+func (cm *ConfigManager) IsToolSkipped(toolName string) bool {
+	if cm.config == nil {
+		return false
+	}
+	for _, skipped := range cm.config.SkippedTools {
+		if skipped == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// SetToolSkipped adds the tool to or removes it from the Install Everything skip list
+func (cm *ConfigManager) SetToolSkipped(toolName string, skipped bool) error {
+	if cm.IsToolSkipped(toolName) == skipped {
+		return nil
+	}
+	cm.ensureHealthConfig()
+	
+	if skipped {
+		cm.config.SkippedTools = append(cm.config.SkippedTools, toolName)
+		sort.Strings(cm.config.SkippedTools)
+	} else {
+		var remaining []string
+		for _, name := range cm.config.SkippedTools {
+			if name != toolName {
+				remaining = append(remaining, name)
+			}
+		}
+		cm.config.SkippedTools = remaining
+	}
+	return cm.SaveConfig()
+}
+
+// KnownFailingTools returns the tools Install Everything can skip: tools on the skip list and
+// tools that failed to install KnownFailingThreshold times in a row, sorted by name
+func (cm *ConfigManager) KnownFailingTools() []string {
+	if cm.config == nil {
+		return nil
+	}
+	
+	seen := make(map[string]bool)
+	var tools []string
+	for _, name := range cm.config.SkippedTools {
+		seen[name] = true
+		tools = append(tools, name)
+	}
+	for name, failures := range cm.config.Health.ScriptFailures {
+		if failures >= KnownFailingThreshold && !seen[name] {
+			tools = append(tools, name)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// ForgetToolFailures removes the tool from the skip list and resets its failure count
+func (cm *ConfigManager) ForgetToolFailures(toolName string) error {
+	cm.ensureHealthConfig()
+	delete(cm.config.Health.ScriptFailures, toolName)
+	delete(cm.config.Health.LastFailures, toolName)
+	if cm.IsToolSkipped(toolName) {
+		return cm.SetToolSkipped(toolName, false)
+	}
+	return cm.SaveConfig()
+}
+
+// ClearSkipList empties the skip list and resets all failure counts
+func (cm *ConfigManager) ClearSkipList() error {
+	cm.ensureHealthConfig()
+	cm.config.SkippedTools = nil
+	cm.config.Health.ScriptFailures = make(map[string]int)
+	cm.config.Health.LastFailures = make(map[string]time.Time)
+	return cm.SaveConfig()
+}
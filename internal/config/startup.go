@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// startupMarkerFile exists while BOBA initializes; still there on the next start after a crash
+const startupMarkerFile = "startup.json"
+
+// crashLogFile collects the panics of crashed sessions, next to config.json
+const crashLogFile = "crash.log"
+
+// maxCrashLogSize is the size above which the crash log keeps only its most recent part
+const maxCrashLogSize = 256 * 1024
+
+// SafeModeThreshold is the number of startups in a row that crashed during initialization
+// before BOBA starts in safe mode
+const SafeModeThreshold = 2
+
+// startupMarker counts the startups in a row that never finished initializing
+type startupMarker struct {
+	Attempts  int       `json:"attempts"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// startupMarkerPath returns the path of the startup marker
+func (cm *ConfigManager) startupMarkerPath() string {
+	return filepath.Join(cm.configDir, startupMarkerFile)
+}
+
+// BeginStartup writes the startup marker and returns the number of startups in a row that
+// crashed during initialization before this one
+func (cm *ConfigManager) BeginStartup() int {
+	var marker startupMarker
+	if data, err := os.ReadFile(cm.startupMarkerPath()); err == nil {
+		json.Unmarshal(data, &marker)
+	}
+	crashed := marker.Attempts
+	
+	marker.Attempts++
+	marker.StartedAt = time.Now()
+	if err := os.MkdirAll(cm.configDir, 0755); err == nil {
+		if data, err := json.Marshal(marker); err == nil {
+			os.WriteFile(cm.startupMarkerPath(), data, 0644)
+		}
+	}
+	return crashed
+}
+
+// EndStartup removes the startup marker once initialization completed
+func (cm *ConfigManager) EndStartup() error {
+	if err := os.Remove(cm.startupMarkerPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove startup marker: %w", err)
+	}
+	return nil
+}
+
+// GetCrashLogPath returns the path of the crash log
+func (cm *ConfigManager) GetCrashLogPath() string {
+	return filepath.Join(cm.configDir, crashLogFile)
+}
+
+// RecordCrash appends a panic and its stack trace to the crash log
+func (cm *ConfigManager) RecordCrash(value interface{}, stack []byte) error {
+	if cm.PersistenceDisabled() {
+		return ErrPersistenceDisabled
+	}
+	if err := os.MkdirAll(cm.configDir, 0755); err != nil {
+		return err
+	}
+	
+	existing, _ := os.ReadFile(cm.GetCrashLogPath())
+	if len(existing) > maxCrashLogSize {
+		existing = existing[len(existing)-maxCrashLogSize:]
+	}
+	entry := fmt.Sprintf("=== %s: panic: %v\n%s\n", time.Now().Format(time.RFC3339), value, stack)
+	return os.WriteFile(cm.GetCrashLogPath(), append(existing, entry...), 0644)
+}
+
+// LastCrash returns the most recent entry of the crash log, or "" when nothing crashed yet
+func (cm *ConfigManager) LastCrash() (string, error) {
+	data, err := os.ReadFile(cm.GetCrashLogPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read crash log: %w", err)
+	}
+	
+	log := string(data)
+	if i := strings.LastIndex(log, "=== "); i >= 0 {
+		log = log[i:]
+	}
+	return strings.TrimSpace(log), nil
+}
+
+// ClearCache removes the cached repository listing, so the next start fetches it again
+func (cm *ConfigManager) ClearCache() error {
+	if err := os.RemoveAll(filepath.Join(cm.configDir, "cache")); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// ResetConfig moves config.json aside and starts over with an empty configuration; the
+// credentials are kept. It returns the path of the backup.
+func (cm *ConfigManager) ResetConfig() (string, error) {
+	backupPath := fmt.Sprintf("%s.%s.bak", cm.configPath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(cm.configPath, backupPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to back up config.json: %w", err)
+		}
+		backupPath = ""
+	}
+	
+	cm.config = &Config{
+		ToolOverrides:        make(map[string]bool),
+		EnvironmentOverrides: make(map[string]bool),
+		InstalledTools:       make(map[string]InstalledTool),
+	}
+	return backupPath, cm.SaveConfig()
+}
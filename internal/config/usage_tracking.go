@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"boba/internal/log"
+)
+
+// Directories of usage tracking, next to config.json: the shims put first on PATH, and a file per
+// tool whose modification time is the tool's last use
+const (
+	shimsDir = "shims"
+	usageDir = "usage"
+)
+
+// UnusedAfter is how long a tool goes unused before cleanup suggests uninstalling it
+const UnusedAfter = 90 * 24 * time.Hour
+
+// UnusedTool is an installed tool none of whose executables ran for UnusedAfter
+type UnusedTool struct {
+	Name     string        `json:"name"`
+	LastUsed time.Time     `json:"last_used,omitempty"` // Zero when it was never used since tracking started
+	Idle     time.Duration `json:"idle_ns"`             // Time since the last use, or since tracking or the install started
+}
+
+// UsageTrackingEnabled reports whether the executables of the tools are wrapped in shims
+func (cm *ConfigManager) UsageTrackingEnabled() bool {
+	return cm.config != nil && !cm.config.UsageTrackingSince.IsZero()
+}
+
+// GetShimsDir returns the directory of the shims, which the shell rc files put first on PATH
+func (cm *ConfigManager) GetShimsDir() string {
+	return filepath.Join(cm.configDir, shimsDir)
+}
+
+// SetUsageTracking turns usage tracking on, writing the shims of the installed tools, or off,
+// removing them and the recorded uses
+func (cm *ConfigManager) SetUsageTracking(enabled bool) error {
+	if cm.config == nil {
+		cm.config = &Config{
+			ToolOverrides: make(map[string]bool),
+		}
+	}
+	if enabled == cm.UsageTrackingEnabled() {
+		return nil
+	}
+	if enabled {
+		cm.config.UsageTrackingSince = time.Now()
+		if err := cm.SyncShims(); err != nil {
+			return err
+		}
+	} else {
+		cm.config.UsageTrackingSince = time.Time{}
+		os.RemoveAll(cm.GetShimsDir())
+		os.RemoveAll(filepath.Join(cm.configDir, usageDir))
+	}
+	return cm.SaveConfig()
+}
+
+// SyncShims writes a shim for each executable of the installed tools and removes the shims of the
+// executables no longer installed. Without usage tracking it does nothing.
+func (cm *ConfigManager) SyncShims() error {
+	if !cm.UsageTrackingEnabled() || cm.PersistenceDisabled() {
+		return nil
+	}
+	dir := cm.GetShimsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create shims directory: %w", err)
+	}
+
+	// The first tool by name wins an executable name two tools install
+	names := make([]string, 0, len(cm.config.InstalledTools))
+	for name := range cm.config.InstalledTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		provenance := cm.config.InstalledTools[name].Provenance
+		if provenance == nil {
+			continue
+		}
+		for _, binary := range provenance.BinaryPaths {
+			shim := filepath.Base(binary)
+			if wanted[shim] || filepath.Dir(binary) == dir {
+				continue
+			}
+			if _, err := os.Stat(binary); err != nil {
+				continue
+			}
+			wanted[shim] = true
+			if err := os.WriteFile(filepath.Join(dir, shim), shimScript(name, binary, filepath.Join(cm.configDir, usageDir, name)), 0755); err != nil {
+				return fmt.Errorf("failed to write the shim of %s: %w", shim, err)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !wanted[entry.Name()] {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// syncShimsQuietly updates the shims after an installation record changed; a failure only leaves
+// some uses unrecorded
+func (cm *ConfigManager) syncShimsQuietly() {
+	if err := cm.SyncShims(); err != nil {
+		log.Warn("Failed to update the usage tracking shims", "error", err)
+	}
+}
+
+// shimScript returns a shim recording the use of a tool before running its executable
+func shimScript(tool, binary, usageFile string) []byte {
+	return []byte(fmt.Sprintf(`#!/bin/sh
+# Written by BOBA to record when %s is used, for cleanup suggestions
+mkdir -p %s 2>/dev/null && touch %s 2>/dev/null
+exec %s "$@"
+`, tool, shellQuote(filepath.Dir(usageFile)), shellQuote(usageFile), shellQuote(binary)))
+}
+
+// shellQuote quotes a value for sh
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// ToolLastUsed returns when an executable of the tool last ran through its shim
+func (cm *ConfigManager) ToolLastUsed(name string) (time.Time, bool) {
+	info, err := os.Stat(filepath.Join(cm.configDir, usageDir, name))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// UnusedTools returns the installed tools with shims none of whose executables ran for
+// UnusedAfter, counting from the tracking start or install when they never ran, idlest first
+func (cm *ConfigManager) UnusedTools(now time.Time) []UnusedTool {
+	if !cm.UsageTrackingEnabled() {
+		return nil
+	}
+	var unused []UnusedTool
+	for name, tool := range cm.config.InstalledTools {
+		if tool.Provenance == nil || len(tool.Provenance.BinaryPaths) == 0 {
+			continue
+		}
+		since := cm.config.UsageTrackingSince
+		if tool.InstallDate.After(since) {
+			since = tool.InstallDate
+		}
+		lastUsed, used := cm.ToolLastUsed(name)
+		if used && lastUsed.After(since) {
+			since = lastUsed
+		}
+		if idle := now.Sub(since); idle >= UnusedAfter {
+			entry := UnusedTool{Name: name, Idle: idle}
+			if used {
+				entry.LastUsed = lastUsed
+			}
+			unused = append(unused, entry)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Idle != unused[j].Idle {
+			return unused[i].Idle > unused[j].Idle
+		}
+		return unused[i].Name < unused[j].Name
+	})
+	return unused
+}
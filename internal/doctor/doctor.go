@@ -0,0 +1,515 @@
+// Package doctor implements `boba doctor`, which checks that this machine can run BOBA: platform
+// and package manager detection, git, the GitHub token and repository, the configuration
+// directory and PATH. Each failed check comes with the steps to fix it.
+package doctor
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/installer"
+)
+
+// Outcomes of a check. Warnings point at problems that don't stop BOBA from working.
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusFailed  = "failed"
+)
+
+// Check is the outcome of one diagnostic, with the remediation when it did not pass
+type Check struct {
+	Name   string
+	Status string
+	Detail string
+	Remedy string
+}
+
+// doctor holds what the checks inspect, so that tests can describe a machine
+type doctor struct {
+	platform  installer.Platform
+	cfg       config.Config
+	token     string
+	configDir string
+	home      string
+	path      string // PATH
+	lookPath  func(file string) (string, error)
+	
+	// GitHub checks, given the token (and repository)
+	validateToken   func(token string) error
+	reachRepository func(token, owner, repo string) error
+	
+	// Check of a repository hosted on GitLab or Bitbucket, where the token is checked with the repository
+	provider    string // config.RepositoryProviderGitLab or RepositoryProviderBitbucket, empty on GitHub
+	reachHosted func(token, repoURL string) error
+	
+	// Check of the git source, which clones an SSH remote with a key or the SSH agent
+	gitSource   bool
+	sshAgent    string // SSH_AUTH_SOCK
+	reachRemote func(remote, sshKey string) error
+}
+
+// Run implements `boba doctor` and returns the exit code: 1 when a check failed
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba doctor")
+		fmt.Fprintln(stderr, "Checks that this machine can run BOBA and explains how to fix what is missing.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	configManager.LoadCredentials()
+	engine := installer.NewInstallationEngine(nil)
+	defer engine.Cleanup()
+	engine.ApplySettings(configManager)
+	home, _ := os.UserHomeDir()
+	
+	d := doctor{
+		platform:  engine.GetPlatform(),
+		cfg:       configManager.GetConfig(),
+		token:     configManager.RepositoryToken(),
+		configDir: configManager.GetConfigDir(),
+		home:      home,
+		path:      os.Getenv("PATH"),
+		lookPath:  exec.LookPath,
+		validateToken: func(token string) error {
+			result, err := github.NewGitHubClient(token, "", "").ValidateToken()
+			if err != nil {
+				return err
+			}
+			return result.Error
+		},
+		reachRepository: func(token, owner, repo string) error {
+			return github.NewGitHubClient(token, owner, repo).ValidateRepositoryAccess()
+		},
+		reachHosted: func(token, repoURL string) error {
+			client, err := github.NewHostedBackend(configManager.GetRepositoryProvider(), token, repoURL, github.DefaultCloneOptions())
+			if err != nil {
+				return err
+			}
+			return client.TestConnection()
+		},
+	}
+	if configManager.UsesGitLab() || configManager.UsesBitbucket() {
+		d.provider = configManager.GetRepositoryProvider()
+	}
+	if configManager.UsesGitSource() {
+		d.gitSource = true
+		d.sshAgent = os.Getenv("SSH_AUTH_SOCK")
+		d.reachRemote = func(remote, sshKey string) error {
+			client, err := github.NewGitRemote(remote, sshKey)
+			if err != nil {
+				return err
+			}
+			return client.TestConnection()
+		}
+	}
+	
+	checks := d.run()
+	report(stdout, checks)
+	for _, check := range checks {
+		if check.Status == StatusFailed {
+			return exitcode.Failure
+		}
+	}
+	return exitcode.OK
+}
+
+// run runs every check in order
+func (d doctor) run() []Check {
+	token := d.checkToken()
+	return []Check{
+		d.checkPlatform(),
+		d.checkPackageManager(),
+		d.checkGit(),
+		token,
+		d.checkRepository(token.Status == StatusOK),
+		d.checkConfigDir(),
+		d.checkPath(),
+	}
+}
+
+// report prints the checks, with the remediation of the ones that did not pass, and a summary
+func report(w io.Writer, checks []Check) {
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Status]++
+		icon := "✓"
+		switch check.Status {
+		case StatusWarning:
+			icon = "⚠"
+		case StatusFailed:
+			icon = "✗"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", icon, check.Name, check.Detail)
+		if check.Remedy != "" && check.Status != StatusOK {
+			for _, line := range strings.Split(check.Remedy, "\n") {
+				fmt.Fprintf(w, "  → %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", counts[StatusOK], counts[StatusWarning], counts[StatusFailed])
+}
+
+func (d doctor) checkPlatform() Check {
+	check := Check{Name: "Platform", Status: StatusOK, Detail: fmt.Sprintf("%s/%s", d.platform.OS, d.platform.Arch)}
+	switch d.platform.OS {
+	case "linux":
+		check.Detail += fmt.Sprintf(" (%s)", d.platform.Distribution)
+		if d.platform.Distribution == "" || d.platform.Distribution == "unknown" {
+			check.Status = StatusWarning
+			check.Remedy = "The Linux distribution could not be detected from /etc/os-release: scripts branching on it may pick the wrong commands"
+		}
+	case "darwin":
+	default:
+		check.Status = StatusWarning
+		check.Remedy = "BOBA scripts are written for Linux and macOS: run BOBA inside WSL on Windows"
+	}
+	return check
+}
+
+func (d doctor) checkPackageManager() Check {
+	manager := d.platform.PackageManager
+	if manager == "" || manager == "unknown" {
+		return Check{
+			Name:   "Package manager",
+			Status: StatusFailed,
+			Detail: "none of apt, yum, dnf, pacman, zypper or apk found",
+			Remedy: "Install scripts rely on the system package manager: make sure it is installed and on PATH",
+		}
+	}
+	if _, err := d.lookPath(manager); err != nil {
+		check := Check{Name: "Package manager", Status: StatusFailed, Detail: fmt.Sprintf("%s not found on PATH", manager)}
+		if manager == "brew" {
+			check.Remedy = "Start boba in a terminal to install Homebrew, or install it from https://brew.sh, then open a new terminal"
+		} else {
+			check.Remedy = fmt.Sprintf("Make sure %s is installed and its directory is on PATH", manager)
+		}
+		return check
+	}
+	return Check{Name: "Package manager", Status: StatusOK, Detail: manager}
+}
+
+// installCommands install a package with each package manager
+var installCommands = map[string]string{
+	"apt":    "sudo apt-get install -y %s",
+	"yum":    "sudo yum install -y %s",
+	"dnf":    "sudo dnf install -y %s",
+	"pacman": "sudo pacman -S --noconfirm %s",
+	"zypper": "sudo zypper install -y %s",
+	"apk":    "sudo apk add %s",
+	"brew":   "brew install %s",
+}
+
+func (d doctor) checkGit() Check {
+	path, err := d.lookPath("git")
+	if err == nil {
+		return Check{Name: "Git", Status: StatusOK, Detail: path}
+	}
+	
+	remedy := "Install git from https://git-scm.com/downloads"
+	if command, ok := installCommands[d.platform.PackageManager]; ok {
+		remedy = "Install git: " + fmt.Sprintf(command, "git")
+	}
+	return Check{
+		Name:   "Git",
+		Status: StatusFailed,
+		Detail: "not found on PATH, the repository cannot be cloned or synced",
+		Remedy: remedy,
+	}
+}
+
+func (d doctor) checkToken() Check {
+	if d.cfg.LocalRepoPath != "" {
+		return Check{Name: "GitHub token", Status: StatusOK, Detail: "not needed, the repository is read from local_repo_path"}
+	}
+	if d.gitSource {
+		return d.checkSSHKey()
+	}
+	if d.provider != "" {
+		return d.checkHostedToken()
+	}
+	if d.token == "" {
+		return Check{
+			Name:   "GitHub token",
+			Status: StatusFailed,
+			Detail: "no token saved",
+			Remedy: "Run boba and authenticate with 🔐 GitHub Authentication",
+		}
+	}
+	err := d.validateToken(d.token)
+	if urlErr := networkError(err); urlErr != nil {
+		return Check{
+			Name:   "GitHub token",
+			Status: StatusFailed,
+			Detail: fmt.Sprintf("GitHub could not be reached: %v", urlErr.Err),
+			Remedy: "Check the network connection, and set HTTPS_PROXY when a proxy is required",
+		}
+	}
+	if err != nil {
+		return Check{
+			Name:   "GitHub token",
+			Status: StatusFailed,
+			Detail: err.Error(),
+			Remedy: "The token may have expired or been revoked: create a new one at https://github.com/settings/tokens\nthen run boba and re-authenticate with 🔐 GitHub Authentication",
+		}
+	}
+	return Check{Name: "GitHub token", Status: StatusOK, Detail: "valid"}
+}
+
+// networkError returns the error of a request that failed before GitHub answered, if it did
+func networkError(err error) *url.Error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr
+	}
+	return nil
+}
+
+// checkRepository checks that the configured repository can be read; a GitHub repository is
+// only reached when the token is valid
+func (d doctor) checkRepository(tokenValid bool) Check {
+	if dir := d.cfg.LocalRepoPath; dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return Check{
+				Name:   "Repository",
+				Status: StatusFailed,
+				Detail: fmt.Sprintf("local repository %s is not a directory", dir),
+				Remedy: "Fix local_repo_path in config.json, or remove it to use the GitHub repository",
+			}
+		}
+		return Check{Name: "Repository", Status: StatusOK, Detail: dir}
+	}
+	
+	if d.cfg.RepositoryURL == "" {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: "no repository set up",
+			Remedy: "Run boba to choose a configuration repository",
+		}
+	}
+	if d.gitSource {
+		return d.checkGitRemote(tokenValid)
+	}
+	if d.provider != "" {
+		return d.checkHostedRepository(tokenValid)
+	}
+	owner, repo, err := github.ParseRepositoryURL(d.cfg.RepositoryURL)
+	if err != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: fmt.Sprintf("invalid repository %q", d.cfg.RepositoryURL),
+			Remedy: "Set repository_url in config.json to owner/repo, or choose it again from 📁 Repository Configuration",
+		}
+	}
+	if !tokenValid {
+		return Check{
+			Name:   "Repository",
+			Status: StatusWarning,
+			Detail: fmt.Sprintf("%s/%s not checked without a valid token", owner, repo),
+			Remedy: "Fix the GitHub token first",
+		}
+	}
+	if err := d.reachRepository(d.token, owner, repo); err != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: err.Error(),
+			Remedy: "Check the network connection, the repository name in 📁 Repository Configuration and that the token can read the repository",
+		}
+	}
+	return Check{Name: "Repository", Status: StatusOK, Detail: fmt.Sprintf("%s/%s reachable", owner, repo)}
+}
+
+// checkHostedToken only checks a GitLab token or Bitbucket app password is set: the host
+// validates it with the repository
+func (d doctor) checkHostedToken() Check {
+	name := "GitLab token"
+	remedy := fmt.Sprintf("Create a personal access token with the read_api and read_repository scopes,\nthen set %s or gitlab_token in credentials.json", config.GitLabTokenEnv)
+	if d.provider == config.RepositoryProviderBitbucket {
+		name = "Bitbucket app password"
+		remedy = fmt.Sprintf("Create an app password with the Repositories: Read permission,\nthen set %s to username:app_password, or bitbucket_username and bitbucket_app_password in credentials.json", config.BitbucketTokenEnv)
+	}
+	if d.token == "" {
+		return Check{Name: name, Status: StatusFailed, Detail: "no token saved", Remedy: remedy}
+	}
+	return Check{Name: name, Status: StatusOK, Detail: "set, checked with the repository"}
+}
+
+// checkSSHKey checks the key the git source clones with: ssh_key_path, or the SSH agent and the
+// default keys of ~/.ssh
+func (d doctor) checkSSHKey() Check {
+	if key := d.cfg.SSHKeyPath; key != "" {
+		if info, err := os.Stat(key); err != nil || info.IsDir() {
+			return Check{
+				Name:   "SSH key",
+				Status: StatusFailed,
+				Detail: fmt.Sprintf("%s not found", key),
+				Remedy: "Fix ssh_key_path in config.json, or remove it to use the SSH agent and the keys in ~/.ssh",
+			}
+		}
+		return Check{Name: "SSH key", Status: StatusOK, Detail: key}
+	}
+	if d.sshAgent != "" {
+		return Check{Name: "SSH key", Status: StatusOK, Detail: "SSH agent and the keys in ~/.ssh"}
+	}
+	// Without an agent, keys with a passphrase can't be used: the repository check reports it
+	return Check{Name: "SSH key", Status: StatusOK, Detail: "the keys in ~/.ssh without a passphrase"}
+}
+
+// checkGitRemote checks that the SSH remote of the git source can be reached with the key
+func (d doctor) checkGitRemote(keyValid bool) Check {
+	host, repoPath, err := github.ParseGitRemote(d.cfg.RepositoryURL)
+	if err != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: err.Error(),
+			Remedy: "Set repository_url in config.json to the SSH remote, e.g. git@git.example.com:team/boba-config.git",
+		}
+	}
+	if !keyValid {
+		return Check{
+			Name:   "Repository",
+			Status: StatusWarning,
+			Detail: fmt.Sprintf("%s not checked without a key", repoPath),
+			Remedy: "Fix the SSH key first",
+		}
+	}
+	if err := d.reachRemote(d.cfg.RepositoryURL, d.cfg.SSHKeyPath); err != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: err.Error(),
+			Remedy: fmt.Sprintf("Check that the key can read the repository: ssh -T %s, and that %s is in ~/.ssh/known_hosts", host, host),
+		}
+	}
+	return Check{Name: "Repository", Status: StatusOK, Detail: fmt.Sprintf("%s on %s reachable over SSH", repoPath, host)}
+}
+
+// hostedRepository returns the name of a GitLab or Bitbucket repository and the host it is on
+func hostedRepository(provider, repoURL string) (name, host string, err error) {
+	if provider == config.RepositoryProviderBitbucket {
+		workspace, repo, err := github.ParseBitbucketURL(repoURL)
+		return workspace + "/" + repo, "bitbucket.org", err
+	}
+	baseURL, project, err := github.ParseGitLabURL(repoURL)
+	return project, baseURL, err
+}
+
+func (d doctor) checkHostedRepository(tokenValid bool) Check {
+	name, host, err := hostedRepository(d.provider, d.cfg.RepositoryURL)
+	if err != nil {
+		example := "https://gitlab.com/group/boba-config"
+		if d.provider == config.RepositoryProviderBitbucket {
+			example = "https://bitbucket.org/workspace/boba-config"
+		}
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: fmt.Sprintf("invalid repository %q", d.cfg.RepositoryURL),
+			Remedy: "Set repository_url in config.json to the repository URL, e.g. " + example,
+		}
+	}
+	if !tokenValid {
+		return Check{
+			Name:   "Repository",
+			Status: StatusWarning,
+			Detail: fmt.Sprintf("%s not checked without a token", name),
+			Remedy: "Set the token first",
+		}
+	}
+	err = d.reachHosted(d.token, d.cfg.RepositoryURL)
+	if urlErr := networkError(err); urlErr != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: fmt.Sprintf("%s could not be reached: %v", host, urlErr.Err),
+			Remedy: "Check the network connection, and set HTTPS_PROXY when a proxy is required",
+		}
+	}
+	if err != nil {
+		return Check{
+			Name:   "Repository",
+			Status: StatusFailed,
+			Detail: err.Error(),
+			Remedy: "Check the repository path in repository_url and that the token can read the repository",
+		}
+	}
+	return Check{Name: "Repository", Status: StatusOK, Detail: fmt.Sprintf("%s on %s reachable", name, host)}
+}
+
+func (d doctor) checkConfigDir() Check {
+	failed := func(err error) Check {
+		return Check{
+			Name:   "Configuration directory",
+			Status: StatusFailed,
+			Detail: fmt.Sprintf("%s is not writable: %v", d.configDir, err),
+			Remedy: fmt.Sprintf("Give your user ownership of it (sudo chown -R $USER %s), or set %s to a writable directory", d.configDir, config.HomeEnv),
+		}
+	}
+	if err := os.MkdirAll(d.configDir, 0755); err != nil {
+		return failed(err)
+	}
+	file, err := os.CreateTemp(d.configDir, ".doctor-")
+	if err != nil {
+		return failed(err)
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return Check{Name: "Configuration directory", Status: StatusOK, Detail: d.configDir}
+}
+
+// checkPath checks that the user install prefix and BOBA itself are on PATH
+func (d doctor) checkPath() Check {
+	var problems, remedies []string
+	
+	if d.home != "" {
+		userBin := filepath.Join(d.home, ".local", "bin")
+		onPath := false
+		for _, dir := range filepath.SplitList(d.path) {
+			if filepath.Clean(dir) == userBin {
+				onPath = true
+			}
+		}
+		if !onPath {
+			problems = append(problems, fmt.Sprintf("%s is not on PATH, tools installed for your user only won't be found", userBin))
+			remedies = append(remedies, `Add export PATH="$HOME/.local/bin:$PATH" to your shell rc file (~/.zshrc or ~/.bashrc)`)
+		}
+	}
+	if _, err := d.lookPath("boba"); err != nil {
+		problems = append(problems, "boba is not on PATH")
+		remedies = append(remedies, "Choose 🔧 Install BOBA to System in the main menu, or add the directory of the boba binary to PATH")
+	}
+	
+	if len(problems) == 0 {
+		return Check{Name: "PATH", Status: StatusOK, Detail: "boba and ~/.local/bin found"}
+	}
+	return Check{
+		Name:   "PATH",
+		Status: StatusWarning,
+		Detail: strings.Join(problems, "; "),
+		Remedy: strings.Join(remedies, "\n"),
+	}
+}
@@ -0,0 +1,169 @@
+package doctor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+)
+
+// machine returns a doctor for a healthy machine with the given executables on PATH
+func machine(t *testing.T, executables ...string) doctor {
+	home := t.TempDir()
+	return doctor{
+		platform:  installer.Platform{OS: "linux", Arch: "amd64", Distribution: "ubuntu", PackageManager: "apt"},
+		cfg:       config.Config{RepositoryURL: "acme/dotfiles"},
+		token:     "ghp_token",
+		configDir: filepath.Join(t.TempDir(), ".boba"),
+		home:      home,
+		path:      filepath.Join(home, ".local", "bin") + string(os.PathListSeparator) + "/usr/bin",
+		lookPath: func(file string) (string, error) {
+			for _, executable := range executables {
+				if executable == file {
+					return "/usr/bin/" + file, nil
+				}
+			}
+			return "", fmt.Errorf("%s not found", file)
+		},
+		validateToken:   func(token string) error { return nil },
+		reachRepository: func(token, owner, repo string) error { return nil },
+	}
+}
+
+func statuses(checks []Check) map[string]Check {
+	byName := make(map[string]Check)
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+	return byName
+}
+
+func TestDoctor(t *testing.T) {
+	checks := statuses(machine(t, "apt", "git", "boba").run())
+	if len(checks) != 7 {
+		t.Fatalf("Expected 7 checks, got %+v", checks)
+	}
+	for name, check := range checks {
+		if check.Status != StatusOK {
+			t.Errorf("Expected %s to pass on a healthy machine, got %+v", name, check)
+		}
+	}
+	
+	// Missing tools come with the command installing them
+	d := machine(t, "apt")
+	d.path = "/usr/bin"
+	checks = statuses(d.run())
+	if git := checks["Git"]; git.Status != StatusFailed || git.Remedy != "Install git: sudo apt-get install -y git" {
+		t.Errorf("Expected git to be reported missing, got %+v", git)
+	}
+	if path := checks["PATH"]; path.Status != StatusWarning || !strings.Contains(path.Detail, ".local/bin") || !strings.Contains(path.Detail, "boba is not on PATH") {
+		t.Errorf("Expected the PATH setup to be reported, got %+v", path)
+	}
+	
+	// The repository is only reached with a valid token
+	d = machine(t, "apt", "git", "boba")
+	d.validateToken = func(token string) error { return errors.New("invalid GitHub token: 401 Bad credentials") }
+	d.reachRepository = func(token, owner, repo string) error {
+		t.Error("Expected the repository not to be reached with an invalid token")
+		return nil
+	}
+	checks = statuses(d.run())
+	if token := checks["GitHub token"]; token.Status != StatusFailed || !strings.Contains(token.Remedy, "re-authenticate") {
+		t.Errorf("Expected the invalid token to fail, got %+v", token)
+	}
+	if repo := checks["Repository"]; repo.Status != StatusWarning {
+		t.Errorf("Expected the repository check to be skipped, got %+v", repo)
+	}
+	d.validateToken = func(token string) error {
+		return fmt.Errorf("invalid GitHub token: %w", &url.Error{Op: "Get", URL: "https://api.github.com/user", Err: errors.New("no such host")})
+	}
+	if token := statuses(d.run())["GitHub token"]; token.Status != StatusFailed || !strings.Contains(token.Remedy, "network connection") {
+		t.Errorf("Expected a network failure not to blame the token, got %+v", token)
+	}
+	
+	// Local repositories need no token
+	d = machine(t, "apt", "git", "boba")
+	d.cfg = config.Config{LocalRepoPath: filepath.Join(t.TempDir(), "missing")}
+	d.token = ""
+	checks = statuses(d.run())
+	if token := checks["GitHub token"]; token.Status != StatusOK {
+		t.Errorf("Expected no token to be needed, got %+v", token)
+	}
+	if repo := checks["Repository"]; repo.Status != StatusFailed || !strings.Contains(repo.Remedy, "local_repo_path") {
+		t.Errorf("Expected the missing local repository to fail, got %+v", repo)
+	}
+	
+	var out bytes.Buffer
+	report(&out, d.run())
+	if !strings.Contains(out.String(), "✗ Repository:") || !strings.Contains(out.String(), "  → Fix local_repo_path") || !strings.Contains(out.String(), "6 passed, 0 warnings, 1 failed") {
+		t.Errorf("Unexpected report:\n%s", out.String())
+	}
+	
+	// GitLab tokens are checked with the repository, not against the GitHub API
+	d = machine(t, "apt", "git", "boba")
+	d.cfg = config.Config{RepositoryURL: "https://gitlab.example.com/platform/boba-config"}
+	d.provider = config.RepositoryProviderGitLab
+	d.token = "glpat-token"
+	d.validateToken = func(token string) error {
+		t.Error("Expected a GitLab token not to be checked with GitHub")
+		return nil
+	}
+	d.reachHosted = func(token, repoURL string) error { return errors.New("GitLab API returned 401: 401 Unauthorized") }
+	checks = statuses(d.run())
+	if token := checks["GitLab token"]; token.Status != StatusOK {
+		t.Errorf("Expected the GitLab token to be reported set, got %+v", token)
+	}
+	if repo := checks["Repository"]; repo.Status != StatusFailed || !strings.Contains(repo.Detail, "401") {
+		t.Errorf("Expected the rejected GitLab token to fail the repository check, got %+v", repo)
+	}
+	d.reachHosted = func(token, repoURL string) error { return nil }
+	if repo := statuses(d.run())["Repository"]; repo.Status != StatusOK || repo.Detail != "platform/boba-config on https://gitlab.example.com reachable" {
+		t.Errorf("Expected the GitLab repository to be reachable, got %+v", repo)
+	}
+	d.token = ""
+	if token := statuses(d.run())["GitLab token"]; token.Status != StatusFailed || !strings.Contains(token.Remedy, config.GitLabTokenEnv) {
+		t.Errorf("Expected a missing GitLab token to fail, got %+v", token)
+	}
+	
+	// The git source needs no token, only a key that can read the SSH remote
+	d = machine(t, "apt", "git", "boba")
+	d.cfg = config.Config{RepositoryURL: "git@git.example.com:team/boba-config.git", SSHKeyPath: filepath.Join(t.TempDir(), "id_ed25519")}
+	d.gitSource = true
+	d.validateToken = func(token string) error {
+		t.Error("Expected no token to be checked for the git source")
+		return nil
+	}
+	d.reachRemote = func(remote, sshKey string) error {
+		t.Error("Expected the remote not to be reached without the key")
+		return nil
+	}
+	checks = statuses(d.run())
+	if key := checks["SSH key"]; key.Status != StatusFailed || !strings.Contains(key.Remedy, "ssh_key_path") {
+		t.Errorf("Expected the missing SSH key to fail, got %+v", key)
+	}
+	if repo := checks["Repository"]; repo.Status != StatusWarning {
+		t.Errorf("Expected the remote not to be checked without the key, got %+v", repo)
+	}
+	if err := os.WriteFile(d.cfg.SSHKeyPath, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d.reachRemote = func(remote, sshKey string) error { return errors.New("Permission denied (publickey)") }
+	if repo := statuses(d.run())["Repository"]; repo.Status != StatusFailed || !strings.Contains(repo.Remedy, "known_hosts") {
+		t.Errorf("Expected the unreachable remote to fail, got %+v", repo)
+	}
+	d.reachRemote = func(remote, sshKey string) error { return nil }
+	if repo := statuses(d.run())["Repository"]; repo.Status != StatusOK || repo.Detail != "team/boba-config on git.example.com reachable over SSH" {
+		t.Errorf("Expected the remote to be reachable, got %+v", repo)
+	}
+	d.cfg.RepositoryURL = "https://git.example.com/team/boba-config"
+	if repo := statuses(d.run())["Repository"]; repo.Status != StatusFailed || !strings.Contains(repo.Remedy, "git@") {
+		t.Errorf("Expected an HTTPS remote to fail, got %+v", repo)
+	}
+}
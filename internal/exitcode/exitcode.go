@@ -0,0 +1,13 @@
+// Package exitcode defines the exit codes of the boba commands, so that automation can branch
+// on the kind of failure instead of parsing the output.
+package exitcode
+
+const (
+	OK         = 0
+	Failure    = 1  // Any failure without a more specific code
+	Usage      = 2  // Invalid arguments
+	Auth       = 10 // The GitHub token was rejected or the repository can't be reached with it
+	Dependency = 20 // A dependency is missing or the dependencies are circular
+	Script     = 30 // An install, environment or step script exited with an error
+	Timeout    = 40 // A script ran too long and was killed
+)
@@ -1,6 +1,8 @@
 package github
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -17,6 +19,7 @@ const (
 	AuthStateValidating
 	AuthStateSuccess
 	AuthStateError
+	AuthStateDeviceCode // Waiting for the user to enter the device flow code on github.com
 )
 
 // AuthModel represents the authentication UI model
@@ -30,6 +33,14 @@ type AuthModel struct {
 	client          *GitHubClient
 	onComplete      func(client *GitHubClient, repoURL string) tea.Cmd
 	onCancel        func() tea.Cmd
+	cloneCancel     context.CancelFunc // Cancels the clone in progress
+	cloneProgress   CloneProgress      // Latest progress reported by git clone
+	cloneEvents     chan tea.Msg       // Progress and completion messages from the clone goroutine
+	cloneOptions    CloneOptions       // Options used when cloning the repository
+	deviceFlow      *DeviceFlow        // Signs in without a token when an OAuth App is configured
+	deviceCode      *DeviceCode        // Code shown to the user, nil while it is requested
+	deviceCtx       context.Context    // Ends the device code request and polling
+	deviceCancel    context.CancelFunc // Cancels the device flow when the user leaves it
 }
 
 // AuthMsg represents messages for the authentication flow
@@ -40,40 +51,77 @@ type AuthMsg struct {
 	User     string
 	RepoName string
 	CloneDir string
+	Client   *GitHubClient
+}
+
+// DeviceCodeMsg carries the code the user enters on github.com, or why it could not be requested
+type DeviceCodeMsg struct {
+	Code  *DeviceCode
+	Error error
+}
+
+// DeviceTokenMsg carries the token granted once the user entered the code, or why none was
+type DeviceTokenMsg struct {
+	Token string
+	Error error
+}
+
+// CloneProgressMsg reports git clone progress while authenticating
+type CloneProgressMsg struct {
+	Progress CloneProgress
 }
 
 // NewAuthModel creates a new authentication model
 func NewAuthModel(onComplete func(*GitHubClient, string) tea.Cmd, onCancel func() tea.Cmd) *AuthModel {
 	return &AuthModel{
-		state:      AuthStateTokenInput,
-		repoInput:  "boba-config", // Default repository name
-		onComplete: onComplete,
-		onCancel:   onCancel,
+		state:        AuthStateTokenInput,
+		repoInput:    "boba-config", // Default repository name
+		onComplete:   onComplete,
+		onCancel:     onCancel,
+		cloneOptions: DefaultCloneOptions(),
 	}
 }
 
 // NewAuthModelWithRepo creates a new authentication model with a custom default repository
 func NewAuthModelWithRepo(defaultRepo string, onComplete func(*GitHubClient, string) tea.Cmd, onCancel func() tea.Cmd) *AuthModel {
 	return &AuthModel{
-		state:      AuthStateTokenInput,
-		repoInput:  defaultRepo,
-		onComplete: onComplete,
-		onCancel:   onCancel,
+		state:        AuthStateTokenInput,
+		repoInput:    defaultRepo,
+		onComplete:   onComplete,
+		onCancel:     onCancel,
+		cloneOptions: DefaultCloneOptions(),
 	}
 }
 
 // NewRepoConfigModel creates a new model for repository configuration only (skips token input)
 func NewRepoConfigModel(defaultRepo string, onComplete func(*GitHubClient, string) tea.Cmd, onCancel func() tea.Cmd) *AuthModel {
 	return &AuthModel{
-		state:      AuthStateRepoInput, // Start directly at repository input
-		repoInput:  defaultRepo,
-		onComplete: onComplete,
-		onCancel:   onCancel,
+		state:        AuthStateRepoInput, // Start directly at repository input
+		repoInput:    defaultRepo,
+		onComplete:   onComplete,
+		onCancel:     onCancel,
+		cloneOptions: DefaultCloneOptions(),
 	}
 }
 
-// Init initializes the authentication model
+// SetDeviceClientID offers signing in through the GitHub device flow with the OAuth App, before
+// the personal access token. An empty client ID leaves the token as the only way.
+func (m *AuthModel) SetDeviceClientID(clientID string) {
+	if clientID == "" {
+		m.deviceFlow = nil
+		return
+	}
+	m.deviceFlow = NewDeviceFlow(clientID)
+	if m.state == AuthStateTokenInput {
+		m.state = AuthStateDeviceCode
+	}
+}
+
+// Init initializes the authentication model, requesting a device code when the device flow is used
 func (m *AuthModel) Init() tea.Cmd {
+	if m.state == AuthStateDeviceCode {
+		return m.startDeviceFlow()
+	}
 	return nil
 }
 
@@ -90,9 +138,20 @@ func (m *AuthModel) Update(msg tea.Msg) (*AuthModel, tea.Cmd) {
 			return m.handleErrorState(msg)
 		case AuthStateSuccess:
 			return m.handleSuccessState(msg)
+		case AuthStateValidating:
+			return m.handleValidatingState(msg)
+		case AuthStateDeviceCode:
+			return m.handleDeviceCodeState(msg)
 		}
 	case AuthMsg:
 		return m.handleAuthMsg(msg)
+	case DeviceCodeMsg:
+		return m.handleDeviceCodeMsg(msg)
+	case DeviceTokenMsg:
+		return m.handleDeviceTokenMsg(msg)
+	case CloneProgressMsg:
+		m.cloneProgress = msg.Progress
+		return m, waitForCloneEvent(m.cloneEvents)
 	}
 	return m, nil
 }
@@ -116,6 +175,12 @@ func (m *AuthModel) handleTokenInput(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
 		}
 		// Skip repository input and go directly to validation
 		return m, m.validateCredentials()
+	case "tab":
+		// Sign in through the browser instead of pasting a token
+		if m.deviceFlow != nil {
+			return m, m.startDeviceFlow()
+		}
+		return m, nil
 	case "backspace":
 		if len(m.tokenInput) > 0 {
 			m.tokenInput = m.tokenInput[:len(m.tokenInput)-1]
@@ -227,11 +292,14 @@ func (m *AuthModel) handleErrorState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
 		}
 		return m, tea.Quit
 	case "r":
-		// Retry - go back to token input
+		// Retry - go back to the device flow, or to token input without one
 		m.state = AuthStateTokenInput
 		m.errorMessage = ""
 		m.tokenInput = ""
 		m.repoInput = ""
+		if m.deviceFlow != nil {
+			return m, m.startDeviceFlow()
+		}
 		return m, nil
 	case "enter":
 		// Continue with error - go back to main menu
@@ -243,6 +311,101 @@ func (m *AuthModel) handleErrorState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
 	return m, nil
 }
 
+// handleValidatingState lets the user cancel a running clone without quitting the app
+func (m *AuthModel) handleValidatingState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		if m.cloneCancel != nil {
+			// The clone goroutine reports completion once git has exited
+			m.cloneCancel()
+			m.cloneCancel = nil
+		}
+	}
+	return m, nil
+}
+
+// handleDeviceCodeState lets the user fall back to a personal access token while the device flow waits
+func (m *AuthModel) handleDeviceCodeState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.stopDeviceFlow()
+		return m, tea.Quit
+	case "esc":
+		m.stopDeviceFlow()
+		if m.onCancel != nil {
+			return m, m.onCancel()
+		}
+		return m, tea.Quit
+	case "t", "tab":
+		m.stopDeviceFlow()
+		m.state = AuthStateTokenInput
+		m.errorMessage = ""
+	}
+	return m, nil
+}
+
+// startDeviceFlow requests a code for the user to enter on github.com
+func (m *AuthModel) startDeviceFlow() tea.Cmd {
+	m.stopDeviceFlow()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.deviceCtx = ctx
+	m.deviceCancel = cancel
+	m.deviceCode = nil
+	m.state = AuthStateDeviceCode
+	m.errorMessage = ""
+	
+	flow := m.deviceFlow
+	return func() tea.Msg {
+		code, err := flow.RequestCode(ctx)
+		return DeviceCodeMsg{Code: code, Error: err}
+	}
+}
+
+// stopDeviceFlow cancels the device code request or polling in progress
+func (m *AuthModel) stopDeviceFlow() {
+	if m.deviceCancel != nil {
+		m.deviceCancel()
+		m.deviceCancel = nil
+	}
+	m.deviceCode = nil
+}
+
+// handleDeviceCodeMsg shows the code and polls for the grant while the user enters it
+func (m *AuthModel) handleDeviceCodeMsg(msg DeviceCodeMsg) (*AuthModel, tea.Cmd) {
+	// The user may have switched to a token since the code was requested
+	if m.state != AuthStateDeviceCode || errors.Is(msg.Error, context.Canceled) {
+		return m, nil
+	}
+	if msg.Error != nil {
+		m.stopDeviceFlow()
+		m.state = AuthStateError
+		m.errorMessage = fmt.Sprintf("❌ Signing in with GitHub failed: %s\n   Retry, then press T to paste a personal access token instead", msg.Error.Error())
+		return m, nil
+	}
+	m.deviceCode = msg.Code
+	
+	ctx, flow, code := m.deviceCtx, m.deviceFlow, msg.Code
+	return m, func() tea.Msg {
+		token, err := flow.PollToken(ctx, code)
+		return DeviceTokenMsg{Token: token, Error: err}
+	}
+}
+
+// handleDeviceTokenMsg validates the granted token as if it had been pasted
+func (m *AuthModel) handleDeviceTokenMsg(msg DeviceTokenMsg) (*AuthModel, tea.Cmd) {
+	if m.state != AuthStateDeviceCode || errors.Is(msg.Error, context.Canceled) {
+		return m, nil
+	}
+	m.stopDeviceFlow()
+	if msg.Error != nil {
+		m.state = AuthStateError
+		m.errorMessage = fmt.Sprintf("❌ Signing in with GitHub failed: %s", msg.Error.Error())
+		return m, nil
+	}
+	m.tokenInput = msg.Token
+	return m, m.validateCredentials()
+}
+
 // handleSuccessState handles success display state
 func (m *AuthModel) handleSuccessState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
 	switch msg.String() {
@@ -258,6 +421,23 @@ func (m *AuthModel) handleSuccessState(msg tea.KeyMsg) (*AuthModel, tea.Cmd) {
 // handleAuthMsg handles authentication result messages
 func (m *AuthModel) handleAuthMsg(msg AuthMsg) (*AuthModel, tea.Cmd) {
 	switch msg.Type {
+	case "access_validated":
+		return m, m.startClone(msg)
+	case "clone_complete":
+		m.cloneEvents = nil
+		m.cloneCancel = nil
+		if !msg.Success {
+			m.state = AuthStateError
+			if errors.Is(msg.Error, context.Canceled) {
+				m.errorMessage = "⚠️ Clone cancelled"
+			} else {
+				m.errorMessage = fmt.Sprintf("❌ Clone failed: %s", msg.Error.Error())
+			}
+			return m, nil
+		}
+		// Store the client for later use
+		m.client = msg.Client
+		fallthrough
 	case "validation_complete":
 		if msg.Success {
 			m.state = AuthStateSuccess
@@ -335,6 +515,7 @@ func (m *AuthModel) validateCredentials() tea.Cmd {
 
 		// Create GitHub client with proper owner/repo
 		client := NewGitHubClient(m.tokenInput, repoOwner, repo)
+		client.SetCloneOptions(m.cloneOptions)
 
 		// Validate repository access
 		if err := client.ValidateRepositoryAccess(); err != nil {
@@ -345,7 +526,7 @@ func (m *AuthModel) validateCredentials() tea.Cmd {
 			}
 		}
 
-		// Clone the repository
+		// Determine where the repository will be cloned
 		targetDir, err := client.GetCloneTargetDir()
 		if err != nil {
 			return AuthMsg{
@@ -355,29 +536,61 @@ func (m *AuthModel) validateCredentials() tea.Cmd {
 			}
 		}
 
-		if err := client.CloneRepository(targetDir); err != nil {
-			return AuthMsg{
-				Type:    "validation_complete",
-				Success: false,
-				Error:   fmt.Errorf("failed to clone repository '%s': %w", client.GetFullRepoName(), err),
-			}
+		// Access is validated, the clone runs asynchronously with progress
+		return AuthMsg{
+			Type:     "access_validated",
+			Success:  true,
+			User:     owner,
+			RepoName: client.GetFullRepoName(),
+			CloneDir: targetDir,
+			Client:   client,
 		}
+	}
+}
 
-		// Store the client for later use
-		m.client = client
-
-		userName := "Unknown"
-		if authResult.User != nil && authResult.User.Login != nil {
-			userName = *authResult.User.Login
+// startClone clones the repository in the background, streaming progress messages
+func (m *AuthModel) startClone(validated AuthMsg) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 16)
+	m.cloneCancel = cancel
+	m.cloneEvents = events
+	m.cloneProgress = CloneProgress{}
+	
+	go func() {
+		defer close(events)
+		defer cancel()
+		
+		err := validated.Client.CloneRepositoryWithProgress(ctx, validated.CloneDir, func(progress CloneProgress) {
+			// Drop progress updates rather than block git when the UI is behind
+			select {
+			case events <- CloneProgressMsg{Progress: progress}:
+			default:
+			}
+		})
+		
+		result := validated
+		result.Type = "clone_complete"
+		if err != nil {
+			result.Success = false
+			result.Error = fmt.Errorf("failed to clone repository '%s': %w", validated.RepoName, err)
 		}
+		events <- result
+	}()
+	
+	return waitForCloneEvent(events)
+}
 
-		return AuthMsg{
-			Type:    "validation_complete",
-			Success: true,
-			User:    userName,
-			RepoName: client.GetFullRepoName(),
-			CloneDir: targetDir,
+// waitForCloneEvent waits for the next message from the clone goroutine
+func waitForCloneEvent(events chan tea.Msg) tea.Cmd {
+	if events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
 		}
+		return msg
 	}
 }
 
@@ -412,7 +625,11 @@ func (m *AuthModel) View() string {
 		s.WriteString("💡 You can create a token at: https://github.com/settings/tokens\n")
 		s.WriteString("   Required scopes: repo (for private repositories)\n\n")
 		s.WriteString("📋 Tip: You can paste your token with Ctrl+V\n")
-		s.WriteString("🔧 Shortcuts: Ctrl+A (clear), Ctrl+U (clear line)\n\n")
+		s.WriteString("🔧 Shortcuts: Ctrl+A (clear), Ctrl+U (clear line)\n")
+		if m.deviceFlow != nil {
+			s.WriteString("🌐 Press Tab to sign in with your browser instead\n")
+		}
+		s.WriteString("\n")
 		
 		// Show which repository will be used
 		repoHintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#87CEEB")).Italic(true)
@@ -424,6 +641,26 @@ func (m *AuthModel) View() string {
 		
 		s.WriteString("Press Enter to continue, Esc to go back, Ctrl+C to quit")
 
+	case AuthStateDeviceCode:
+		if m.deviceCode == nil {
+			s.WriteString("🔄 Requesting a sign-in code from GitHub...\n\n")
+		} else {
+			codeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+			s.WriteString("Sign in with GitHub in your browser:\n\n")
+			s.WriteString(fmt.Sprintf("  1. Open %s\n", m.deviceCode.VerificationURI))
+			s.WriteString("  2. Enter the code " + codeStyle.Render(m.deviceCode.UserCode) + "\n\n")
+			s.WriteString("⏳ Waiting for you to authorize BOBA...\n\n")
+		}
+		
+		repoHintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#87CEEB")).Italic(true)
+		if strings.Contains(m.repoInput, "/") {
+			s.WriteString(repoHintStyle.Render(fmt.Sprintf("Will use repository: %s", m.repoInput)) + "\n\n")
+		} else {
+			s.WriteString(repoHintStyle.Render(fmt.Sprintf("Will use repository: <your-username>/%s", m.repoInput)) + "\n\n")
+		}
+		
+		s.WriteString("Press T to paste a personal access token instead, Esc to go back, Ctrl+C to quit")
+
 	case AuthStateRepoInput:
 		s.WriteString("Please enter your repository URL:\n\n")
 		s.WriteString("Repository: " + m.repoInput + "█\n")
@@ -454,6 +691,16 @@ func (m *AuthModel) View() string {
 		s.WriteString("  • Verify your GitHub token\n")
 		s.WriteString("  • Check repository access\n")
 		s.WriteString("  • Clone the repository locally")
+		
+		if m.cloneEvents != nil {
+			s.WriteString("\n\n")
+			s.WriteString(renderCloneProgress(m.cloneProgress))
+			if m.cloneCancel != nil {
+				s.WriteString("\n\nPress Esc to cancel the clone")
+			} else {
+				s.WriteString("\n\nCancelling clone...")
+			}
+		}
 
 	case AuthStateSuccess:
 		successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
@@ -469,6 +716,21 @@ func (m *AuthModel) View() string {
 	return s.String()
 }
 
+// renderCloneProgress renders the current clone phase with a progress bar
+func renderCloneProgress(progress CloneProgress) string {
+	if progress.Phase == "" {
+		return "📦 Starting clone..."
+	}
+	
+	const barWidth = 30
+	filled := progress.Percent * barWidth / 100
+	if filled > barWidth {
+		filled = barWidth
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	return fmt.Sprintf("📦 %s\n   %s %3d%% (%d/%d)", progress.Phase, bar, progress.Percent, progress.Current, progress.Total)
+}
+
 // GetToken returns the entered token
 func (m *AuthModel) GetToken() string {
 	return m.tokenInput
@@ -482,4 +744,13 @@ func (m *AuthModel) GetRepoURL() string {
 // GetClient returns the authenticated GitHub client
 func (m *AuthModel) GetClient() *GitHubClient {
 	return m.client
-}
\ No newline at end of file
+}
+// SetCloneOptions sets the options used when cloning the repository after validation
+func (m *AuthModel) SetCloneOptions(options CloneOptions) {
+	m.cloneOptions = options
+}
+
+// IsValidating reports whether credentials are being validated (and the repository cloned)
+func (m *AuthModel) IsValidating() bool {
+	return m.state == AuthStateValidating
+}
@@ -0,0 +1,58 @@
+package github
+
+import "fmt"
+
+// Hosts of a configuration repository other than GitHub, as named by repository_provider
+const (
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+)
+
+// RepositoryBackend is a hosted configuration repository: the repository on GitHub
+// (*GitHubClient), on GitLab (*GitLabClient), on Bitbucket Cloud (*BitbucketClient), or the
+// clone of any SSH remote (*GitRemote).
+// Commands and the UI read tools and environments through it, so every feature works whichever
+// host the team uses.
+type RepositoryBackend interface {
+	GetRepositoryContents(path string) ([]byte, error)
+	GetDirectoryContents(path string) ([]string, error)
+	GetDirectoryEntries(path string) ([]DirectoryEntry, error)
+	GetFilesRecursive(path string) ([]string, error)
+	CloneRepository(targetDir string) error
+	GetCloneTargetDir() (string, error)
+	GetFullRepoName() string
+	TestConnection() error
+	GetRepositoryInfo() (*RepositoryInfo, error)
+}
+
+var (
+	_ RepositoryBackend = (*GitHubClient)(nil)
+	_ RepositoryBackend = (*GitLabClient)(nil)
+	_ RepositoryBackend = (*BitbucketClient)(nil)
+	_ RepositoryBackend = (*GitRemote)(nil)
+)
+
+// NewHostedBackend creates the client of a repository hosted on GitLab or Bitbucket Cloud. The
+// token of Bitbucket is the username and app password, as username:app_password.
+func NewHostedBackend(provider, token, repoURL string, options CloneOptions) (RepositoryBackend, error) {
+	switch provider {
+	case ProviderGitLab:
+		baseURL, project, err := ParseGitLabURL(repoURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository URL format: %w", err)
+		}
+		client := NewGitLabClient(token, baseURL, project)
+		client.SetCloneOptions(options)
+		return client, nil
+	case ProviderBitbucket:
+		workspace, repo, err := ParseBitbucketURL(repoURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository URL format: %w", err)
+		}
+		username, appPassword := SplitBitbucketToken(token)
+		client := NewBitbucketClient(username, appPassword, workspace, repo)
+		client.SetCloneOptions(options)
+		return client, nil
+	}
+	return nil, fmt.Errorf("unknown repository provider %q", provider)
+}
@@ -0,0 +1,397 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BitbucketAPIURL is the REST API of Bitbucket Cloud
+const BitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketHost is the host of Bitbucket Cloud repositories and clones
+const bitbucketHost = "bitbucket.org"
+
+// BitbucketClient reads a configuration repository hosted on Bitbucket Cloud through the REST
+// API, authenticating with a username and an app password, with the same methods as GitHubClient
+type BitbucketClient struct {
+	httpClient   *http.Client
+	apiURL       string
+	workspace    string
+	repo         string
+	username     string
+	appPassword  string
+	ctx          context.Context
+	cloneOptions CloneOptions
+
+	// Main branch of the repository, read once: the src API needs a branch or commit
+	refMu sync.Mutex
+	ref   string
+}
+
+// BitbucketError is a failed Bitbucket API request
+type BitbucketError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *BitbucketError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Bitbucket API returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Bitbucket API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// NewBitbucketClient creates a client for a repository of a Bitbucket Cloud workspace
+func NewBitbucketClient(username, appPassword, workspace, repo string) *BitbucketClient {
+	return &BitbucketClient{
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		apiURL:       BitbucketAPIURL,
+		workspace:    workspace,
+		repo:         repo,
+		username:     username,
+		appPassword:  appPassword,
+		ctx:          context.Background(),
+		cloneOptions: DefaultCloneOptions(),
+	}
+}
+
+// SplitBitbucketToken splits a Bitbucket token, given as username:app_password
+func SplitBitbucketToken(token string) (username, appPassword string) {
+	username, appPassword, _ = strings.Cut(token, ":")
+	return username, appPassword
+}
+
+// IsBitbucketURL reports whether a repository URL points at Bitbucket Cloud
+func IsBitbucketURL(repoURL string) bool {
+	host, _, ok := splitRepositoryHost(repoURL)
+	return ok && host == bitbucketHost
+}
+
+// ParseBitbucketURL extracts the workspace and repository from a Bitbucket Cloud repository URL
+// (https://bitbucket.org/workspace/repo, https://user@bitbucket.org/workspace/repo.git,
+// git@bitbucket.org:workspace/repo.git or workspace/repo)
+func ParseBitbucketURL(repoURL string) (workspace, repo string, err error) {
+	repoURL = strings.TrimSpace(repoURL)
+	if repoURL == "" {
+		return "", "", fmt.Errorf("repository URL cannot be empty")
+	}
+
+	repoPath := repoURL
+	if _, path, ok := splitRepositoryHost(repoURL); ok {
+		repoPath = path
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(repoPath, ".git"), "/"), "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid repository URL format: expected workspace/repo")
+	}
+
+	workspace = strings.TrimSpace(parts[0])
+	repo = strings.TrimSpace(parts[1])
+	if workspace == "" || repo == "" {
+		return "", "", fmt.Errorf("invalid repository URL: workspace and repo cannot be empty")
+	}
+	return workspace, repo, nil
+}
+
+// SetCloneOptions sets the options used when cloning the repository
+func (bc *BitbucketClient) SetCloneOptions(options CloneOptions) {
+	bc.cloneOptions = options
+}
+
+// GetFullRepoName returns the repository name (workspace/repo)
+func (bc *BitbucketClient) GetFullRepoName() string {
+	return fmt.Sprintf("%s/%s", bc.workspace, bc.repo)
+}
+
+// repositoryURL returns the API URL of a repository endpoint
+func (bc *BitbucketClient) repositoryURL(endpoint string) string {
+	return bc.apiURL + "/repositories/" + url.PathEscape(bc.workspace) + "/" + url.PathEscape(bc.repo) + endpoint
+}
+
+// get sends an authenticated GET request, returning the body and content type of a 2xx response
+func (bc *BitbucketClient) get(address string) ([]byte, string, error) {
+	if bc.workspace == "" || bc.repo == "" {
+		return nil, "", fmt.Errorf("repository workspace and name must be specified")
+	}
+
+	request, err := http.NewRequestWithContext(bc.ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if bc.username != "" || bc.appPassword != "" {
+		request.SetBasicAuth(bc.username, bc.appPassword)
+	}
+
+	response, err := bc.httpClient.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiError := &BitbucketError{StatusCode: response.StatusCode}
+		var payload struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			apiError.Message = payload.Error.Message
+		}
+		return nil, "", apiError
+	}
+	return body, response.Header.Get("Content-Type"), nil
+}
+
+// bitbucketRepository is the part of the repository API response BOBA reads
+type bitbucketRepository struct {
+	FullName   string    `json:"full_name"`
+	IsPrivate  bool      `json:"is_private"`
+	CreatedOn  time.Time `json:"created_on"`
+	Parent     *struct{} `json:"parent"` // Set on forks
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Owner struct {
+		Type string `json:"type"` // "user" or "team"
+	} `json:"owner"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+}
+
+// repository fetches the repository
+func (bc *BitbucketClient) repository() (*bitbucketRepository, error) {
+	body, _, err := bc.get(bc.repositoryURL(""))
+	if err != nil {
+		return nil, err
+	}
+	var repository bitbucketRepository
+	if err := json.Unmarshal(body, &repository); err != nil {
+		return nil, fmt.Errorf("failed to decode repository %s: %w", bc.GetFullRepoName(), err)
+	}
+	return &repository, nil
+}
+
+// mainBranch returns the branch files are read from, the main branch of the repository
+func (bc *BitbucketClient) mainBranch() (string, error) {
+	bc.refMu.Lock()
+	defer bc.refMu.Unlock()
+	if bc.ref != "" {
+		return bc.ref, nil
+	}
+
+	repository, err := bc.repository()
+	if err != nil {
+		return "", err
+	}
+	if repository.MainBranch.Name == "" {
+		return "", fmt.Errorf("repository %s has no main branch yet", bc.GetFullRepoName())
+	}
+	bc.ref = repository.MainBranch.Name
+	return bc.ref, nil
+}
+
+// srcURL returns the API URL of a file or directory of the main branch
+func (bc *BitbucketClient) srcURL(repoPath string, directory bool) (string, error) {
+	ref, err := bc.mainBranch()
+	if err != nil {
+		return "", err
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(repoPath, "/"), "/") {
+		if segment != "" {
+			segments = append(segments, url.PathEscape(segment))
+		}
+	}
+	address := bc.repositoryURL("/src/"+url.PathEscape(ref)+"/") + strings.Join(segments, "/")
+	if directory && len(segments) > 0 {
+		address += "/"
+	}
+	return address, nil
+}
+
+// GetRepositoryContents fetches the contents of a file from the main branch
+func (bc *BitbucketClient) GetRepositoryContents(path string) ([]byte, error) {
+	path = strings.Trim(path, "/")
+	address, err := bc.srcURL(path, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", path, err)
+	}
+	content, _, err := bc.get(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// bitbucketListing is one page of a src directory listing
+type bitbucketListing struct {
+	Values []struct {
+		Path string `json:"path"`
+		Type string `json:"type"` // "commit_file" or "commit_directory"
+	} `json:"values"`
+	Next    string `json:"next"`
+	PageLen int    `json:"pagelen"`
+}
+
+// GetDirectoryEntries lists a repository directory with the type of each entry
+func (bc *BitbucketClient) GetDirectoryEntries(dir string) ([]DirectoryEntry, error) {
+	address, err := bc.srcURL(dir, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", dir, err)
+	}
+
+	var entries []DirectoryEntry
+	for query := "?pagelen=100"; address != ""; query = "" {
+		body, contentType, err := bc.get(address + query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get directory %s: %w", dir, err)
+		}
+		// The src API returns the file itself for a file, where GitHub reports it is not a directory
+		var listing bitbucketListing
+		if !strings.HasPrefix(contentType, "application/json") || json.Unmarshal(body, &listing) != nil || listing.PageLen == 0 {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNotDirectory)
+		}
+
+		for _, value := range listing.Values {
+			entryType := strings.TrimPrefix(value.Type, "commit_")
+			if entryType == "directory" {
+				entryType = "dir"
+			}
+			entries = append(entries, DirectoryEntry{Name: path.Base(value.Path), Path: value.Path, Type: entryType})
+		}
+		address = listing.Next
+	}
+	return entries, nil
+}
+
+// GetDirectoryContents fetches the names of the entries of a repository directory
+func (bc *BitbucketClient) GetDirectoryContents(dir string) ([]string, error) {
+	entries, err := bc.GetDirectoryEntries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names, nil
+}
+
+// GetFilesRecursive returns the repository paths of every file below a directory
+func (bc *BitbucketClient) GetFilesRecursive(dir string) ([]string, error) {
+	entries, err := bc.GetDirectoryEntries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		switch entry.Type {
+		case "file":
+			files = append(files, entry.Path)
+		case "dir":
+			nested, err := bc.GetFilesRecursive(entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, nested...)
+		}
+	}
+	return files, nil
+}
+
+// TestConnection checks the app password and that the repository can be read with it
+func (bc *BitbucketClient) TestConnection() error {
+	if bc.username == "" || bc.appPassword == "" {
+		return fmt.Errorf("no Bitbucket username and app password provided")
+	}
+
+	_, err := bc.repository()
+	var apiError *BitbucketError
+	switch {
+	case errors.As(err, &apiError) && apiError.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("invalid Bitbucket username or app password: %w", err)
+	case err != nil:
+		return fmt.Errorf("cannot access repository %s: %w", bc.GetFullRepoName(), err)
+	}
+	return nil
+}
+
+// GetRepositoryInfo fetches the owner and visibility of the repository
+func (bc *BitbucketClient) GetRepositoryInfo() (*RepositoryInfo, error) {
+	repository, err := bc.repository()
+	if err != nil {
+		return nil, fmt.Errorf("cannot access repository %s: %w", bc.GetFullRepoName(), err)
+	}
+
+	info := &RepositoryInfo{
+		FullName:   repository.FullName,
+		Owner:      repository.Workspace.Slug,
+		OwnerType:  "Workspace",
+		Visibility: "public",
+		Fork:       repository.Parent != nil,
+		CreatedAt:  repository.CreatedOn,
+	}
+	if repository.IsPrivate {
+		info.Visibility = "private"
+	}
+	if repository.Owner.Type == "user" {
+		info.OwnerType = "User"
+	}
+	if info.FullName == "" {
+		info.FullName = bc.GetFullRepoName()
+	}
+	if info.Owner == "" {
+		info.Owner = bc.workspace
+	}
+	return info, nil
+}
+
+// CloneRepository clones the repository to a local directory
+func (bc *BitbucketClient) CloneRepository(targetDir string) error {
+	return bc.CloneRepositoryWithProgress(context.Background(), targetDir, nil)
+}
+
+// CloneRepositoryWithProgress clones the repository, reporting git's progress through the callback
+func (bc *BitbucketClient) CloneRepositoryWithProgress(ctx context.Context, targetDir string, progress func(CloneProgress)) error {
+	if bc.workspace == "" || bc.repo == "" {
+		return fmt.Errorf("repository workspace and name must be specified")
+	}
+
+	// The app password authenticates git over HTTPS with the Bitbucket username
+	cloneURL := url.URL{
+		Scheme: "https",
+		User:   url.UserPassword(bc.username, bc.appPassword),
+		Host:   bitbucketHost,
+		Path:   "/" + bc.GetFullRepoName() + ".git",
+	}
+	return cloneWithProgress(ctx, cloneURL.String(), bc.GetFullRepoName(), targetDir, bc.cloneOptions, progress)
+}
+
+// GetCloneTargetDir returns the default directory where the repository should be cloned, below
+// the host so a Bitbucket repository never shares the clone of a GitHub repository
+func (bc *BitbucketClient) GetCloneTargetDir() (string, error) {
+	reposDir, err := cloneReposDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(reposDir, bitbucketHost, bc.workspace, bc.repo), nil
+}
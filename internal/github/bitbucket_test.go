@@ -0,0 +1,192 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBitbucketURL(t *testing.T) {
+	tests := []struct {
+		repoURL       string
+		wantWorkspace string
+		wantRepo      string
+		wantError     bool
+	}{
+		{"https://bitbucket.org/team/boba-config", "team", "boba-config", false},
+		{"https://someone@bitbucket.org/team/boba-config.git", "team", "boba-config", false},
+		{"git@bitbucket.org:team/boba-config.git", "team", "boba-config", false},
+		{"team/boba-config", "team", "boba-config", false},
+		{"https://bitbucket.org/team/dev/boba-config", "", "", true},
+		{"boba-config", "", "", true},
+		{"", "", "", true},
+	}
+	
+	for _, tt := range tests {
+		workspace, repo, err := ParseBitbucketURL(tt.repoURL)
+		if (err != nil) != tt.wantError {
+			t.Errorf("ParseBitbucketURL(%q) error = %v, wantError %v", tt.repoURL, err, tt.wantError)
+			continue
+		}
+		if workspace != tt.wantWorkspace || repo != tt.wantRepo {
+			t.Errorf("ParseBitbucketURL(%q) = %q, %q, want %q, %q", tt.repoURL, workspace, repo, tt.wantWorkspace, tt.wantRepo)
+		}
+	}
+	
+	for repoURL, want := range map[string]bool{
+		"https://bitbucket.org/team/boba-config":  true,
+		"git@bitbucket.org:team/boba-config.git":  true,
+		"https://gitlab.com/team/boba-config":     false,
+		"https://bitbucket.example.com/team/repo": false,
+		"owner/repo":                              false,
+	} {
+		if got := IsBitbucketURL(repoURL); got != want {
+			t.Errorf("IsBitbucketURL(%q) = %v, want %v", repoURL, got, want)
+		}
+	}
+	
+	if username, appPassword := SplitBitbucketToken("someone:app:password"); username != "someone" || appPassword != "app:password" {
+		t.Errorf("Expected the username and app password, got %q, %q", username, appPassword)
+	}
+}
+
+// fakeBitbucket serves the repository and src endpoints of a repository with one tool
+func fakeBitbucket(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string]string{
+		"tools/git/tool.yaml":  "name: git\n",
+		"tools/git/install.sh": "echo install\n",
+		"boba.yaml":            "min_version: 1.0.0\n",
+	}
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if username, password, ok := r.BasicAuth(); !ok || username != "someone" || password != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		if r.PathValue("workspace") != "team" || r.PathValue("repo") != "boba-config" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"message":"Repository not found"}}`))
+			return false
+		}
+		return true
+	}
+	
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/{workspace}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"full_name":"team/boba-config","is_private":true,"mainbranch":{"name":"main"},"owner":{"type":"team"},"workspace":{"slug":"team"}}`))
+	})
+	mux.HandleFunc("/repositories/{workspace}/{repo}/src/{ref}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.PathValue("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		listing := func(body string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+		switch path := r.PathValue("path"); {
+		case path == "tools/" && r.URL.Query().Get("page") == "":
+			// The second page checks that listings follow next
+			listing(`{"pagelen":1,"values":[{"path":"tools/git","type":"commit_directory"}],"next":"http://` + r.Host + r.URL.Path + `?page=2"}`)
+		case path == "tools/":
+			listing(`{"pagelen":1,"values":[{"path":"tools/README.md","type":"commit_file"}]}`)
+		case path == "tools/git/":
+			listing(`{"pagelen":100,"values":[{"path":"tools/git/tool.yaml","type":"commit_file"},{"path":"tools/git/install.sh","type":"commit_file"}]}`)
+		case files[strings.TrimSuffix(path, "/")] != "":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(files[strings.TrimSuffix(path, "/")]))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"message":"No such file or directory"}}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBitbucketClient(t *testing.T) {
+	server := fakeBitbucket(t)
+	newClient := func(username, appPassword string) *BitbucketClient {
+		client := NewBitbucketClient(username, appPassword, "team", "boba-config")
+		client.apiURL = server.URL
+		return client
+	}
+	client := newClient("someone", "app-password")
+	
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("Expected the repository to be reachable, got %v", err)
+	}
+	if err := newClient("someone", "revoked").TestConnection(); err == nil || !strings.Contains(err.Error(), "invalid Bitbucket username or app password") {
+		t.Errorf("Expected a rejected app password to be reported, got %v", err)
+	}
+	if err := newClient("someone", "").TestConnection(); err == nil {
+		t.Error("Expected a missing app password to fail")
+	}
+	
+	content, err := client.GetRepositoryContents("tools/git/tool.yaml")
+	if err != nil || string(content) != "name: git\n" {
+		t.Errorf("Expected tool.yaml content, got %q, %v", content, err)
+	}
+	if _, err := client.GetRepositoryContents("tools/vim/tool.yaml"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing file, got %v", err)
+	}
+	
+	entries, err := client.GetDirectoryEntries("tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DirectoryEntry{
+		{Name: "git", Path: "tools/git", Type: "dir"},
+		{Name: "README.md", Path: "tools/README.md", Type: "file"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Expected the entries of both pages, got %+v", entries)
+	}
+	if names, err := client.GetDirectoryContents("tools"); err != nil || len(names) != 2 || names[0] != "git" {
+		t.Errorf("Expected the directory names, got %v, %v", names, err)
+	}
+	if _, err := client.GetDirectoryEntries("environments"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing directory, got %v", err)
+	}
+	if _, err := client.GetDirectoryEntries("boba.yaml"); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("Expected a file to be refused as a directory, got %v", err)
+	}
+	
+	files, err := client.GetFilesRecursive("tools")
+	if err != nil || !reflect.DeepEqual(files, []string{"tools/git/tool.yaml", "tools/git/install.sh", "tools/README.md"}) {
+		t.Errorf("Expected the files below tools, got %v, %v", files, err)
+	}
+	
+	info, err := client.GetRepositoryInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FullName != "team/boba-config" || info.Owner != "team" || info.OwnerType != "Workspace" || info.Visibility != "private" || info.Fork {
+		t.Errorf("Unexpected repository info %+v", info)
+	}
+}
+
+func TestBitbucketCloneTargetDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("BOBA_HOME", home)
+	
+	dir, err := NewBitbucketClient("someone", "app-password", "team", "boba-config").GetCloneTargetDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "repos", "bitbucket.org", "team", "boba-config"); dir != want {
+		t.Errorf("Expected the clone in %s, got %s", want, dir)
+	}
+}
@@ -2,11 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v66/github"
 	"golang.org/x/oauth2"
@@ -14,11 +17,12 @@ import (
 
 // GitHubClient handles GitHub API interactions
 type GitHubClient struct {
-	client *github.Client
-	token  string
-	owner  string
-	repo   string
-	ctx    context.Context
+	client       *github.Client
+	token        string
+	owner        string
+	repo         string
+	ctx          context.Context
+	cloneOptions CloneOptions // How CloneRepository clones the repository
 }
 
 // AuthResult represents the result of GitHub authentication
@@ -35,14 +39,19 @@ func NewGitHubClient(token, owner, repo string) *GitHubClient {
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = &retryTransport{base: tc.Transport, backoff: retryBackoff, maxWait: maxRateLimitWait}
+	if dir, err := bobaDir(); err == nil {
+		tc.Transport = &etagTransport{base: tc.Transport, dir: filepath.Join(dir, "cache", "github")}
+	}
 	client := github.NewClient(tc)
 
 	return &GitHubClient{
-		client: client,
-		token:  token,
-		owner:  owner,
-		repo:   repo,
-		ctx:    ctx,
+		client:       client,
+		token:        token,
+		owner:        owner,
+		repo:         repo,
+		ctx:          ctx,
+		cloneOptions: DefaultCloneOptions(),
 	}
 }
 
@@ -86,6 +95,44 @@ func (gc *GitHubClient) ValidateRepositoryAccess() error {
 	return nil
 }
 
+// RepositoryInfo describes a repository for the trust confirmation of first-time repositories
+type RepositoryInfo struct {
+	FullName   string // owner/repo, or group/project on GitLab
+	Owner      string
+	OwnerType  string // "User" or "Organization", "Group" on GitLab
+	Visibility string // "public", "private" or "internal"
+	Fork       bool
+	CreatedAt  time.Time
+}
+
+// GetRepositoryInfo fetches the owner and visibility of the repository
+func (gc *GitHubClient) GetRepositoryInfo() (*RepositoryInfo, error) {
+	if gc.owner == "" || gc.repo == "" {
+		return nil, fmt.Errorf("repository owner and name must be specified")
+	}
+
+	repository, _, err := gc.client.Repositories.Get(gc.ctx, gc.owner, gc.repo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access repository %s/%s: %w", gc.owner, gc.repo, err)
+	}
+
+	info := &RepositoryInfo{
+		FullName:   repository.GetFullName(),
+		Owner:      repository.GetOwner().GetLogin(),
+		OwnerType:  repository.GetOwner().GetType(),
+		Visibility: repository.GetVisibility(),
+		Fork:       repository.GetFork(),
+		CreatedAt:  repository.GetCreatedAt().Time,
+	}
+	if info.Visibility == "" {
+		info.Visibility = "public"
+		if repository.GetPrivate() {
+			info.Visibility = "private"
+		}
+	}
+	return info, nil
+}
+
 // ParseRepositoryURL extracts owner and repo from a GitHub URL
 func ParseRepositoryURL(repoURL string) (owner, repo string, err error) {
 	if repoURL == "" {
@@ -155,6 +202,52 @@ func (gc *GitHubClient) GetRepositoryContents(path string) ([]byte, error) {
 	return []byte(content), nil
 }
 
+// GetContentsAt fetches a file of another repository at a ref, for tools whose scripts are
+// pinned to a fork or a community repository
+func (gc *GitHubClient) GetContentsAt(owner, repo, path, ref string) ([]byte, error) {
+	return getContentsAt(gc.ctx, gc.client, owner, repo, path, ref)
+}
+
+// GetPublicContentsAt fetches a file of a public repository at a ref without authentication,
+// for local mode where no GitHub client is configured
+func GetPublicContentsAt(owner, repo, path, ref string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return getContentsAt(ctx, github.NewClient(nil), owner, repo, path, ref)
+}
+
+func getContentsAt(ctx context.Context, client *github.Client, owner, repo, path, ref string) ([]byte, error) {
+	fileContent, _, _, err := client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s from %s/%s@%s: %w", path, owner, repo, ref, err)
+	}
+	if fileContent == nil {
+		return nil, fmt.Errorf("%s in %s/%s@%s is not a file", path, owner, repo, ref)
+	}
+	
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file content: %w", err)
+	}
+	return []byte(content), nil
+}
+
+// CreateFile commits a new file to the repository
+func (gc *GitHubClient) CreateFile(path string, content []byte, message string) error {
+	if gc.owner == "" || gc.repo == "" {
+		return fmt.Errorf("repository owner and name must be specified")
+	}
+	
+	_, _, err := gc.client.Repositories.CreateFile(gc.ctx, gc.owner, gc.repo, path, &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Content: content,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	return nil
+}
+
 // GetDirectoryContents fetches the contents of a directory from the repository
 func (gc *GitHubClient) GetDirectoryContents(path string) ([]string, error) {
 	if gc.owner == "" || gc.repo == "" {
@@ -176,6 +269,92 @@ func (gc *GitHubClient) GetDirectoryContents(path string) ([]string, error) {
 	return names, nil
 }
 
+// DirectoryEntry describes one entry of a repository directory listing
+type DirectoryEntry struct {
+	Name string
+	Path string
+	Type string // "file", "dir", "symlink" or "submodule"
+}
+
+// ErrNotDirectory is returned when a directory listing is requested for a file
+var ErrNotDirectory = errors.New("path is a file, not a directory")
+
+// IsNotFound reports whether an error is a GitHub, GitLab or Bitbucket 404 response or a missing
+// file of a local repository
+func IsNotFound(err error) bool {
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+	var gitlabError *GitLabError
+	if errors.As(err, &gitlabError) {
+		return gitlabError.StatusCode == http.StatusNotFound
+	}
+	var bitbucketError *BitbucketError
+	if errors.As(err, &bitbucketError) {
+		return bitbucketError.StatusCode == http.StatusNotFound
+	}
+	var errorResponse *github.ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil &&
+		errorResponse.Response.StatusCode == http.StatusNotFound
+}
+
+// GetDirectoryEntries lists a repository directory with the type of each entry
+func (gc *GitHubClient) GetDirectoryEntries(path string) ([]DirectoryEntry, error) {
+	if gc.owner == "" || gc.repo == "" {
+		return nil, fmt.Errorf("repository owner and name must be specified")
+	}
+
+	fileContent, directoryContents, _, err := gc.client.Repositories.GetContents(gc.ctx, gc.owner, gc.repo, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+	if fileContent != nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotDirectory)
+	}
+
+	var entries []DirectoryEntry
+	for _, content := range directoryContents {
+		entries = append(entries, DirectoryEntry{
+			Name: content.GetName(),
+			Path: content.GetPath(),
+			Type: content.GetType(),
+		})
+	}
+
+	return entries, nil
+}
+
+// GetFilesRecursive returns the repository paths of every file below a directory
+func (gc *GitHubClient) GetFilesRecursive(path string) ([]string, error) {
+	if gc.owner == "" || gc.repo == "" {
+		return nil, fmt.Errorf("repository owner and name must be specified")
+	}
+
+	_, directoryContents, _, err := gc.client.Repositories.GetContents(gc.ctx, gc.owner, gc.repo, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+
+	var files []string
+	for _, content := range directoryContents {
+		if content.Path == nil {
+			continue
+		}
+		switch content.GetType() {
+		case "file":
+			files = append(files, *content.Path)
+		case "dir":
+			nested, err := gc.GetFilesRecursive(*content.Path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, nested...)
+		}
+	}
+
+	return files, nil
+}
+
 // TestConnection tests the GitHub connection and repository access
 func (gc *GitHubClient) TestConnection() error {
 	// First validate the token
@@ -218,48 +397,41 @@ func (gc *GitHubClient) GetFullRepoName() string {
 
 // CloneRepository clones the repository to a local directory
 func (gc *GitHubClient) CloneRepository(targetDir string) error {
-	if gc.owner == "" || gc.repo == "" {
-		return fmt.Errorf("repository owner and name must be specified")
-	}
-
-	// Check if git is available
-	if _, err := exec.LookPath("git"); err != nil {
-		return fmt.Errorf("git command not found - please install git: %w", err)
-	}
-
-	// Create target directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(targetDir), 0755); err != nil {
-		return fmt.Errorf("failed to create target directory: %w", err)
-	}
+	return gc.CloneRepositoryWithProgress(context.Background(), targetDir, nil)
+}
 
-	// Remove existing directory if it exists
-	if _, err := os.Stat(targetDir); err == nil {
-		if err := os.RemoveAll(targetDir); err != nil {
-			return fmt.Errorf("failed to remove existing directory: %w", err)
-		}
+// GetCloneTargetDir returns the default directory where the repository should be cloned
+func (gc *GitHubClient) GetCloneTargetDir() (string, error) {
+	reposDir, err := cloneReposDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(reposDir, gc.GetFullRepoName()), nil
+}
 
-	// Construct the clone URL using the token for authentication
-	cloneURL := fmt.Sprintf("https://%s@github.com/%s/%s.git", gc.token, gc.owner, gc.repo)
-	
-	// Execute git clone command
-	cmd := exec.Command("git", "clone", cloneURL, targetDir)
-	
-	// Capture output for debugging
-	output, err := cmd.CombinedOutput()
+// cloneReposDir returns the directory configuration repositories are cloned under
+func cloneReposDir() (string, error) {
+	dir, err := bobaDir()
 	if err != nil {
-		return fmt.Errorf("git clone failed for repository '%s/%s': %w\nOutput: %s", gc.owner, gc.repo, err, string(output))
+		return "", err
 	}
-
-	return nil
+	return filepath.Join(dir, "repos"), nil
 }
 
-// GetCloneTargetDir returns the default directory where the repository should be cloned
-func (gc *GitHubClient) GetCloneTargetDir() (string, error) {
+// bobaDir returns the BOBA directory, ~/.boba unless the environment moves it
+func bobaDir() (string, error) {
+	// BOBA_HOME replaces ~/.boba, e.g. with the temporary directory of an ephemeral session
+	if dir := os.Getenv("BOBA_HOME"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("BOBA_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 	
-	return filepath.Join(homeDir, ".boba", "repos", gc.GetFullRepoName()), nil
+	return filepath.Join(homeDir, ".boba"), nil
 }
\ No newline at end of file
@@ -1,7 +1,16 @@
 package github
 
 import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseRepositoryURL(t *testing.T) {
@@ -147,4 +156,197 @@ func TestUpdateRepository(t *testing.T) {
 	if client.GetRepo() != newRepo {
 		t.Errorf("UpdateRepository() repo = %v, want %v", client.GetRepo(), newRepo)
 	}
-}
\ No newline at end of file
+}
+func TestParseCloneProgress(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		phase   string
+		percent int
+		current int
+		total   int
+	}{
+		{"Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s", true, "Receiving objects", 45, 450, 1000},
+		{"remote: Counting objects: 100% (12/12), done.", true, "Counting objects", 100, 12, 12},
+		{"Resolving deltas:   3% (1/30)", true, "Resolving deltas", 3, 1, 30},
+		{"Cloning into '/home/user/.boba/repos/user/boba-config'...", false, "", 0, 0, 0},
+		{"fatal: repository not found", false, "", 0, 0, 0},
+	}
+	
+	for _, tt := range tests {
+		progress, ok := parseCloneProgress(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseCloneProgress(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if progress.Phase != tt.phase || progress.Percent != tt.percent || progress.Current != tt.current || progress.Total != tt.total {
+			t.Errorf("parseCloneProgress(%q) = %+v", tt.line, progress)
+		}
+	}
+}
+
+func TestScanProgressLines(t *testing.T) {
+	input := "Receiving objects:  10% (1/10)\rReceiving objects:  50% (5/10)\rReceiving objects: 100% (10/10), done.\nResolving deltas: 100% (2/2)"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(scanProgressLines)
+	
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 progress lines, got %d: %q", len(lines), lines)
+	}
+	if lines[1] != "Receiving objects:  50% (5/10)" {
+		t.Errorf("Unexpected second line %q", lines[1])
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	url := "https://github.com/owner/repo.git"
+	
+	args := DefaultCloneOptions().cloneArgs(url, "/tmp/repo")
+	if strings.Join(args, " ") != "clone --progress --depth=1 "+url+" /tmp/repo" {
+		t.Errorf("Unexpected default clone args: %v", args)
+	}
+	
+	args = CloneOptions{}.cloneArgs(url, "/tmp/repo")
+	if strings.Contains(strings.Join(args, " "), "--depth") {
+		t.Errorf("Expected full clone without --depth, got %v", args)
+	}
+	
+	args = CloneOptions{Shallow: true, SparsePaths: DefaultSparsePaths}.cloneArgs(url, "/tmp/repo")
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "--sparse") || !strings.Contains(joined, "--filter=blob:none") {
+		t.Errorf("Expected sparse clone args, got %v", args)
+	}
+	
+	client := NewGitHubClient("token", "owner", "repo")
+	if !client.GetCloneOptions().Shallow {
+		t.Error("Expected new clients to clone shallowly by default")
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	t.Setenv("BOBA_HOME", t.TempDir())
+	content := "name: rg\n"
+	var fetched, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"%x"`, len(content))
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fetched++
+		w.Header().Set("ETag", etag)
+		if r.URL.Path == "/repos/owner/repo/contents/tools" {
+			fmt.Fprint(w, `[{"type":"dir","name":"rg","path":"tools/rg"}]`)
+			return
+		}
+		fmt.Fprintf(w, `{"type":"file","encoding":"base64","name":"tool.yaml","path":"tools/rg/tool.yaml","content":%q}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	}))
+	defer server.Close()
+	
+	// Each new client reads the entries the previous ones kept, as a new run of BOBA would
+	newClient := func() *GitHubClient {
+		client := NewGitHubClient("token", "owner", "repo")
+		client.client.BaseURL, _ = url.Parse(server.URL + "/")
+		return client
+	}
+	for run := 0; run < 3; run++ {
+		file, err := newClient().GetRepositoryContents("tools/rg/tool.yaml")
+		if err != nil || string(file) != content {
+			t.Fatalf("Run %d: expected the file, got %q, %v", run, file, err)
+		}
+		names, err := newClient().GetDirectoryContents("tools")
+		if err != nil || len(names) != 1 || names[0] != "rg" {
+			t.Fatalf("Run %d: expected the directory listing, got %v, %v", run, names, err)
+		}
+	}
+	if fetched != 2 || notModified != 4 {
+		t.Errorf("Expected two fetches then 304s, got %d fetches and %d 304s", fetched, notModified)
+	}
+	
+	// A changed file is fetched again
+	content = "name: ripgrep\n"
+	if file, _ := newClient().GetRepositoryContents("tools/rg/tool.yaml"); string(file) != content || fetched != 3 {
+		t.Errorf("Expected the changed file to be fetched, got %q after %d fetches", file, fetched)
+	}
+}
+
+func TestRateLimitRetries(t *testing.T) {
+	t.Setenv("BOBA_HOME", t.TempDir())
+	var requests int
+	var respond func(w http.ResponseWriter)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		respond(w)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport, backoff: time.Millisecond, maxWait: time.Second}}
+	get := func() (*http.Response, error) {
+		requests = 0
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+	
+	// Transient server errors are retried, then the last one is returned
+	respond = func(w http.ResponseWriter) {
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("Expected two retries then success, got %v after %d requests", err, requests)
+	}
+	respond = func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusServiceUnavailable || requests != maxAttempts {
+		t.Errorf("Expected %d attempts, got %v after %d requests", maxAttempts, err, requests)
+	}
+	
+	// A rate limit resetting soon is waited out
+	respond = func(w http.ResponseWriter) {
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK || requests != 2 {
+		t.Errorf("Expected the rate limit to be waited out, got %v after %d requests", err, requests)
+	}
+	
+	// A denial that isn't a rate limit is returned as is
+	respond = func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusForbidden)
+	}
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusForbidden || requests != 1 {
+		t.Errorf("Expected the 403 to be returned, got %v after %d requests", err, requests)
+	}
+	
+	// A rate limit resetting later fails at once with the reset time
+	reset := time.Now().Add(time.Hour)
+	respond = func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}
+	githubClient := NewGitHubClient("token", "owner", "repo")
+	githubClient.client.BaseURL, _ = url.Parse(server.URL + "/")
+	requests = 0
+	_, err := githubClient.GetRepositoryContents("tools/rg/tool.yaml")
+	message, ok := RateLimitMessage(err)
+	if !ok || requests != 1 || !strings.Contains(message, "rate limited until "+reset.Local().Format("15:04")) {
+		t.Errorf("Expected a rate limited message after one request, got %q from %v after %d requests", message, err, requests)
+	}
+	if _, ok := RateLimitMessage(fmt.Errorf("file not found")); ok {
+		t.Error("Expected other errors not to be rate limits")
+	}
+}
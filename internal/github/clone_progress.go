@@ -0,0 +1,185 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CloneProgress describes the progress reported by git clone
+type CloneProgress struct {
+	Phase   string // e.g. "Receiving objects", "Resolving deltas"
+	Percent int    // Percentage of the current phase
+	Current int    // Objects processed in the current phase
+	Total   int    // Total objects in the current phase
+	Line    string // Raw progress line
+}
+
+// cloneProgressPattern matches git progress lines such as
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s"
+var cloneProgressPattern = regexp.MustCompile(`^(?:remote: )?([A-Za-z ]+):\s+(\d+)% \((\d+)/(\d+)\)`)
+
+// parseCloneProgress parses a git progress line, returning false for other output
+func parseCloneProgress(line string) (CloneProgress, bool) {
+	line = strings.TrimSpace(line)
+	matches := cloneProgressPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return CloneProgress{}, false
+	}
+	
+	percent, _ := strconv.Atoi(matches[2])
+	current, _ := strconv.Atoi(matches[3])
+	total, _ := strconv.Atoi(matches[4])
+	return CloneProgress{
+		Phase:   strings.TrimSpace(matches[1]),
+		Percent: percent,
+		Current: current,
+		Total:   total,
+		Line:    line,
+	}, true
+}
+
+// scanProgressLines splits git output on both \n and the \r git uses to redraw progress
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// CloneOptions controls how the configuration repository is cloned
+type CloneOptions struct {
+	Shallow     bool     // Clone only the latest commit (--depth=1)
+	SparsePaths []string // When set, only check out these directories (sparse-checkout)
+	Env         []string // Extra environment of git, e.g. GIT_SSH_COMMAND with the key of an SSH remote
+}
+
+// DefaultSparsePaths are the directories BOBA needs from a configuration repository
+var DefaultSparsePaths = []string{"tools", "environments"}
+
+// DefaultCloneOptions returns the default clone options: shallow, full checkout
+func DefaultCloneOptions() CloneOptions {
+	return CloneOptions{Shallow: true}
+}
+
+// SetCloneOptions sets the options used when cloning the repository
+func (gc *GitHubClient) SetCloneOptions(options CloneOptions) {
+	gc.cloneOptions = options
+}
+
+// GetCloneOptions returns the options used when cloning the repository
+func (gc *GitHubClient) GetCloneOptions() CloneOptions {
+	return gc.cloneOptions
+}
+
+// cloneArgs builds the git clone arguments for the clone options
+func (options CloneOptions) cloneArgs(cloneURL, targetDir string) []string {
+	// --progress forces progress output even though stderr is not a terminal
+	args := []string{"clone", "--progress"}
+	if options.Shallow {
+		args = append(args, "--depth=1")
+	}
+	if len(options.SparsePaths) > 0 {
+		// Skip downloading blobs outside the sparse paths
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	return append(args, cloneURL, targetDir)
+}
+
+// CloneRepositoryWithProgress clones the repository, reporting git's progress through the
+// callback. Cancelling the context stops the clone and removes the partial checkout.
+func (gc *GitHubClient) CloneRepositoryWithProgress(ctx context.Context, targetDir string, progress func(CloneProgress)) error {
+	if gc.owner == "" || gc.repo == "" {
+		return fmt.Errorf("repository owner and name must be specified")
+	}
+
+	// Construct the clone URL using the token for authentication
+	cloneURL := fmt.Sprintf("https://%s@github.com/%s/%s.git", gc.token, gc.owner, gc.repo)
+	return cloneWithProgress(ctx, cloneURL, gc.GetFullRepoName(), targetDir, gc.cloneOptions, progress)
+}
+
+// cloneWithProgress runs git clone for the repository called name, shared by the GitHub and
+// GitLab clients
+func cloneWithProgress(ctx context.Context, cloneURL, name, targetDir string, options CloneOptions, progress func(CloneProgress)) error {
+	// Check if git is available
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git command not found - restart boba to install it, or install git manually: %w", err)
+	}
+
+	// Create target directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(targetDir), 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	// Remove existing directory if it exists
+	if _, err := os.Stat(targetDir); err == nil {
+		if err := os.RemoveAll(targetDir); err != nil {
+			return fmt.Errorf("failed to remove existing directory: %w", err)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", options.cloneArgs(cloneURL, targetDir)...)
+	if len(options.Env) > 0 {
+		cmd.Env = append(os.Environ(), options.Env...)
+	}
+	
+	// Non-progress stderr lines are kept for error reporting; stdout is collected by exec
+	var stdout, output bytes.Buffer
+	cmd.Stdout = &stdout
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture git output: %w", err)
+	}
+	
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start git clone: %w", err)
+	}
+	
+	scanner := bufio.NewScanner(stderr)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if update, ok := parseCloneProgress(line); ok {
+			if progress != nil {
+				progress(update)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			output.WriteString(line + "\n")
+		}
+	}
+	
+	err = cmd.Wait()
+	output.Write(stdout.Bytes())
+	if err != nil {
+		if ctx.Err() != nil {
+			os.RemoveAll(targetDir)
+			return fmt.Errorf("clone of '%s' cancelled: %w", name, ctx.Err())
+		}
+		return fmt.Errorf("git clone failed for repository '%s': %w\nOutput: %s", name, err, output.String())
+	}
+
+	// Restrict the checkout to the configured directories
+	if len(options.SparsePaths) > 0 {
+		args := append([]string{"-C", targetDir, "sparse-checkout", "set"}, options.SparsePaths...)
+		if output, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("sparse checkout failed for repository '%s': %w\nOutput: %s", name, err, string(output))
+		}
+	}
+
+	return nil
+}
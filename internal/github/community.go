@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCommunityIndex is the curated public repository of community tool definitions
+const DefaultCommunityIndex = "Walter0697/boba-community"
+
+// communityIndexFile lists the tools of a community index repository, at its root
+const communityIndexFile = "index.yaml"
+
+// CommunityTool is an entry of the community index
+type CommunityTool struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Path        string `yaml:"path"` // Tool folder in the index repository (tool.yaml, install.sh, uninstall.sh)
+	Homepage    string `yaml:"homepage,omitempty"`
+}
+
+// CommunityIndex is a community index read at a commit, so imported tools pin that commit
+type CommunityIndex struct {
+	Owner  string
+	Repo   string
+	Commit string
+	Tools  []CommunityTool
+}
+
+// ParseCommunityIndex parses index.yaml, refusing entries without a name or a folder
+func ParseCommunityIndex(data []byte) ([]CommunityTool, error) {
+	var index struct {
+		Tools []CommunityTool `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", communityIndexFile, err)
+	}
+	for i, tool := range index.Tools {
+		if tool.Name == "" || tool.Path == "" {
+			return nil, fmt.Errorf("%s: tools[%d] needs a name and a path", communityIndexFile, i)
+		}
+		if cleaned := path.Clean(tool.Path); strings.HasPrefix(cleaned, "..") || path.IsAbs(cleaned) {
+			return nil, fmt.Errorf("%s: tools[%d] (%s) points outside the repository", communityIndexFile, i, tool.Name)
+		}
+	}
+	return index.Tools, nil
+}
+
+// FetchCommunityIndex reads the index of a public repository (owner/repo, optionally @ref)
+// at the current commit of the ref, without authentication
+func FetchCommunityIndex(reference string) (*CommunityIndex, error) {
+	location, ref, _ := strings.Cut(reference, "@")
+	owner, repo, ok := strings.Cut(strings.Trim(location, "/"), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("community index %q must be owner/repo or owner/repo@ref", reference)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := github.NewClient(nil)
+	commit, _, err := client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s/%s@%s: %w", owner, repo, ref, err)
+	}
+	
+	data, err := getContentsAt(ctx, client, owner, repo, communityIndexFile, commit)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := ParseCommunityIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	return &CommunityIndex{Owner: owner, Repo: repo, Commit: commit, Tools: tools}, nil
+}
+
+// Source returns the pinned source reference of a community tool (owner/repo/path@commit)
+func (ci *CommunityIndex) Source(tool CommunityTool) string {
+	return fmt.Sprintf("%s/%s/%s@%s", ci.Owner, ci.Repo, path.Clean(tool.Path), ci.Commit)
+}
+
+// ImportManifest returns the tool.yaml to add to a configuration repository for a community
+// tool: its own manifest, with its scripts pinned to the index commit
+func (ci *CommunityIndex) ImportManifest(tool CommunityTool) ([]byte, error) {
+	manifest, err := GetPublicContentsAt(ci.Owner, ci.Repo, path.Join(path.Clean(tool.Path), "tool.yaml"), ci.Commit)
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+	return PinnedManifest(manifest, tool, ci.Source(tool))
+}
+
+// PinnedManifest sets the source of a tool manifest, filling in the name and description from the
+// index entry when the manifest lacks them (or is missing)
+func PinnedManifest(manifest []byte, tool CommunityTool, source string) ([]byte, error) {
+	fields := make(map[string]interface{})
+	if len(manifest) > 0 {
+		if err := yaml.Unmarshal(manifest, &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse the tool.yaml of %s: %w", tool.Name, err)
+		}
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+	}
+	
+	defaults := map[string]string{"name": tool.Name, "description": tool.Description, "homepage": tool.Homepage}
+	for key, value := range defaults {
+		if _, ok := fields[key]; !ok && value != "" {
+			fields[key] = value
+		}
+	}
+	fields["source"] = source
+	// Imported tools are installed on demand until the user reviews them
+	delete(fields, "auto_install")
+	
+	return yaml.Marshal(fields)
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseCommunityIndex(t *testing.T) {
+	tools, err := ParseCommunityIndex([]byte(`
+tools:
+  - name: ripgrep
+    description: Fast recursive search
+    path: tools/ripgrep
+  - name: fzf
+    description: Fuzzy finder
+    path: tools/fzf/
+    homepage: https://github.com/junegunn/fzf
+`))
+	if err != nil {
+		t.Fatalf("Expected the index to parse, got %v", err)
+	}
+	if len(tools) != 2 || tools[1].Homepage == "" {
+		t.Fatalf("Unexpected tools: %+v", tools)
+	}
+	
+	index := &CommunityIndex{Owner: "acme", Repo: "community", Commit: "0123abcd"}
+	if source := index.Source(tools[1]); source != "acme/community/tools/fzf@0123abcd" {
+		t.Errorf("Expected a source pinned to the index commit, got %s", source)
+	}
+	
+	for _, invalid := range []string{"tools:\n  - name: x\n", "tools:\n  - name: x\n    path: ../outside\n", "tools: ["} {
+		if _, err := ParseCommunityIndex([]byte(invalid)); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestPinnedManifest(t *testing.T) {
+	tool := CommunityTool{Name: "ripgrep", Description: "From the index", Path: "tools/ripgrep"}
+	manifest, err := PinnedManifest([]byte("name: ripgrep\ndescription: Search\nauto_install: true\nversion: 14.1.0\n"), tool, "acme/community/tools/ripgrep@0123abcd")
+	if err != nil {
+		t.Fatalf("Failed to pin manifest: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(manifest, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["source"] != "acme/community/tools/ripgrep@0123abcd" || fields["description"] != "Search" || fields["version"] != "14.1.0" {
+		t.Errorf("Expected the community manifest with a pinned source, got %v", fields)
+	}
+	if _, ok := fields["auto_install"]; ok {
+		t.Error("Expected imported tools not to be auto-installed")
+	}
+	
+	// Without a manifest the index entry describes the tool
+	manifest, err = PinnedManifest(nil, tool, "acme/community/tools/ripgrep@0123abcd")
+	if err != nil || !strings.Contains(string(manifest), "description: From the index") {
+		t.Errorf("Expected a manifest built from the index entry, got %s, %v", manifest, err)
+	}
+}
+
+func TestLocalRepositoryCreateFile(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewLocalRepository(dir)
+	
+	if err := repo.CreateFile("tools/ripgrep/tool.yaml", []byte("name: ripgrep\n"), "Import ripgrep"); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "tools", "ripgrep", "tool.yaml")); err != nil || string(content) != "name: ripgrep\n" {
+		t.Errorf("Expected the file to be written, got %q, %v", content, err)
+	}
+	if err := repo.CreateFile("tools/ripgrep/tool.yaml", []byte("name: other\n"), "Import again"); err == nil {
+		t.Error("Expected an existing file not to be replaced")
+	}
+	if err := repo.CreateFile("../outside.yaml", nil, "Escape"); err == nil {
+		t.Error("Expected a path outside the repository to be refused")
+	}
+}
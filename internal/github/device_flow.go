@@ -0,0 +1,164 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeviceClientID is the client ID of the OAuth App BOBA signs in with through the device flow, set
+// at build time with -ldflags "-X boba/internal/github.DeviceClientID=...". github_client_id in
+// config.json takes precedence. Without either, only a personal access token can be entered.
+var DeviceClientID = ""
+
+// GitHub endpoints of the OAuth device flow
+const (
+	DeviceCodeURL  = "https://github.com/login/device/code"
+	DeviceTokenURL = "https://github.com/login/oauth/access_token"
+)
+
+// deviceScope is the access the device flow asks for: reading private configuration repositories
+const deviceScope = "repo"
+
+// minPollInterval is the wait between polls, in seconds, when GitHub returns no interval: the
+// default of RFC 8628
+const minPollInterval = 5
+
+// Errors ending the device flow before the user granted access
+var (
+	ErrDeviceCodeExpired  = errors.New("the code expired before it was entered, start again")
+	ErrDeviceAccessDenied = errors.New("access was denied on GitHub")
+)
+
+// DeviceCode is the code the user enters at the verification URL to grant BOBA access
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"` // Seconds
+	Interval        int    `json:"interval"`   // Seconds to wait between polls
+}
+
+// DeviceFlow signs in to GitHub without a personal access token: the user enters a code on
+// github.com while BOBA polls for the grant
+type DeviceFlow struct {
+	httpClient *http.Client
+	clientID   string
+	codeURL    string
+	tokenURL   string
+	second     time.Duration // Length of a second in the intervals GitHub returns, shortened in tests
+}
+
+// NewDeviceFlow creates a device flow for the OAuth App with the client ID
+func NewDeviceFlow(clientID string) *DeviceFlow {
+	return &DeviceFlow{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		clientID:   clientID,
+		codeURL:    DeviceCodeURL,
+		tokenURL:   DeviceTokenURL,
+		second:     time.Second,
+	}
+}
+
+// RequestCode asks GitHub for a code for the user to enter at its verification URL
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	var code DeviceCode
+	if err := f.post(ctx, f.codeURL, url.Values{"client_id": {f.clientID}, "scope": {deviceScope}}, &code); err != nil {
+		return nil, fmt.Errorf("failed to request a device code: %w", err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, fmt.Errorf("failed to request a device code: GitHub returned no code")
+	}
+	return &code, nil
+}
+
+// PollToken waits for the user to enter the code and returns the access token GitHub grants, or
+// ErrDeviceCodeExpired or ErrDeviceAccessDenied
+func (f *DeviceFlow) PollToken(ctx context.Context, code *DeviceCode) (string, error) {
+	seconds := code.Interval
+	if seconds <= 0 {
+		seconds = minPollInterval
+	}
+	interval := time.Duration(seconds) * f.second
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*f.second)
+		defer cancel()
+	}
+	values := url.Values{
+		"client_id":   {f.clientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrDeviceCodeExpired
+			}
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var grant struct {
+			AccessToken      string `json:"access_token"`
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			Interval         int    `json:"interval"`
+		}
+		if err := f.post(ctx, f.tokenURL, values, &grant); err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return "", fmt.Errorf("failed to poll for the access token: %w", err)
+		}
+		switch grant.Error {
+		case "":
+			if grant.AccessToken == "" {
+				return "", fmt.Errorf("GitHub granted no access token")
+			}
+			return grant.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			// GitHub asks for a longer interval, for this poll and the next ones
+			if grant.Interval > 0 {
+				interval = time.Duration(grant.Interval) * f.second
+			} else {
+				interval += 5 * f.second
+			}
+		case "expired_token":
+			return "", ErrDeviceCodeExpired
+		case "access_denied":
+			return "", ErrDeviceAccessDenied
+		default:
+			if grant.ErrorDescription != "" {
+				return "", fmt.Errorf("%s: %s", grant.Error, grant.ErrorDescription)
+			}
+			return "", errors.New(grant.Error)
+		}
+	}
+}
+
+// post sends a form to a device flow endpoint and decodes the JSON answer
+func (f *DeviceFlow) post(ctx context.Context, address string, values url.Values, out any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, address, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	response, err := f.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub returned %d", response.StatusCode)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
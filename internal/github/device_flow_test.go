@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeDeviceFlow serves the device flow endpoints, answering the polls in turn with the errors
+// given and then with a token
+func fakeDeviceFlow(t *testing.T, pollErrors ...string) (*DeviceFlow, *int) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("client_id") != "client" || r.FormValue("scope") != "repo" || r.Header.Get("Accept") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"device_code":"device","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`)
+	})
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("device_code") != "device" || r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		polls++
+		if polls <= len(pollErrors) {
+			fmt.Fprintf(w, `{"error":%q}`, pollErrors[polls-1])
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"gho_granted","token_type":"bearer","scope":"repo"}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	flow := NewDeviceFlow("client")
+	flow.codeURL = server.URL + "/login/device/code"
+	flow.tokenURL = server.URL + "/login/oauth/access_token"
+	flow.second = time.Millisecond
+	return flow, &polls
+}
+
+func TestDeviceFlow(t *testing.T) {
+	flow, polls := fakeDeviceFlow(t, "authorization_pending", "slow_down", "authorization_pending")
+	code, err := flow.RequestCode(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.UserCode != "ABCD-1234" || code.VerificationURI != "https://github.com/login/device" {
+		t.Errorf("Unexpected code %+v", code)
+	}
+	token, err := flow.PollToken(context.Background(), code)
+	if err != nil || token != "gho_granted" {
+		t.Errorf("Expected the granted token, got %q, %v", token, err)
+	}
+	if *polls != 4 {
+		t.Errorf("Expected 4 polls, got %d", *polls)
+	}
+
+	// Without an interval, the polls wait the default 5 seconds instead of hammering GitHub
+	flow, _ = fakeDeviceFlow(t)
+	start := time.Now()
+	if _, err := flow.PollToken(context.Background(), &DeviceCode{DeviceCode: "device"}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("Expected the first poll after 5 seconds, got %v", elapsed)
+	}
+
+	flow, _ = fakeDeviceFlow(t, "access_denied")
+	if _, err := flow.PollToken(context.Background(), code); !errors.Is(err, ErrDeviceAccessDenied) {
+		t.Errorf("Expected ErrDeviceAccessDenied, got %v", err)
+	}
+	flow, _ = fakeDeviceFlow(t, "expired_token")
+	if _, err := flow.PollToken(context.Background(), code); !errors.Is(err, ErrDeviceCodeExpired) {
+		t.Errorf("Expected ErrDeviceCodeExpired, got %v", err)
+	}
+
+	// Cancelling stops the polling
+	flow, _ = fakeDeviceFlow(t, "authorization_pending", "authorization_pending", "authorization_pending")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := flow.PollToken(ctx, code); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the polling to be cancelled, got %v", err)
+	}
+}
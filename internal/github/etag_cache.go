@@ -0,0 +1,120 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// etagTransport keeps the repository contents the GitHub API returns with their ETag, and asks
+// for them again with If-None-Match: an unchanged file or directory is answered with a 304 that
+// doesn't count against the rate limit, and the kept body is served instead. The entries are
+// files under the cache directory, which maintenance prunes once they go unused for a month.
+type etagTransport struct {
+	base http.RoundTripper
+	dir  string
+}
+
+// etagEntry is a kept response of the contents API
+type etagEntry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// RoundTrip makes GETs of repository contents conditional on the kept ETag
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !strings.Contains(req.URL.Path, "/contents") || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	path := t.entryPath(req)
+	entry := t.load(path)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		// The kept headers, updated with the current rate limit and ETag
+		header := entry.Header.Clone()
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+			ContentLength: int64(len(entry.Body)),
+			Request:       req,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.store(path, etagEntry{ETag: resp.Header.Get("ETag"), Header: resp.Header, Body: body})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
+
+// entryPath returns the file keeping the response to a request: the same URL asked for another
+// media type is another entry
+func (t *etagTransport) entryPath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load reads a kept response, or returns nil when there is none or it can't be read
+func (t *etagTransport) load(path string) *etagEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry etagEntry
+	if json.Unmarshal(data, &entry) != nil || entry.ETag == "" {
+		return nil
+	}
+	return &entry
+}
+
+// store keeps a response; a failure only costs the next request its 304
+func (t *etagTransport) store(path string, entry etagEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil || os.MkdirAll(t.dir, 0700) != nil {
+		return
+	}
+	// Written aside then renamed, so concurrent fetches of the same path never read half an entry
+	temp, err := os.CreateTemp(t.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil && closeErr == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+	}
+}
@@ -0,0 +1,248 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrStaleClone is returned by GitRemote.Update when the clone exists but could not be updated:
+// the tools and environments are read from the last clone
+var ErrStaleClone = errors.New("the local clone could not be updated")
+
+// GitRemote reads the configuration repository from a local clone of any git remote reached over
+// SSH, such as a self-hosted Gitea or a bare repository on a server, without a hosting API
+type GitRemote struct {
+	remote       string
+	host         string
+	path         string
+	sshKey       string // Private key used instead of the SSH agent and ~/.ssh defaults when set
+	cloneOptions CloneOptions
+}
+
+// NewGitRemote creates a reader of the clone of an SSH remote (ssh://user@host/path or
+// user@host:path), cloned with the private key at sshKey when it is set
+func NewGitRemote(remote, sshKey string) (*GitRemote, error) {
+	host, repoPath, err := ParseGitRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	return &GitRemote{
+		remote:       strings.TrimSpace(remote),
+		host:         host,
+		path:         repoPath,
+		sshKey:       sshKey,
+		cloneOptions: DefaultCloneOptions(),
+	}, nil
+}
+
+// ParseGitRemote extracts the host and repository path of an SSH remote: ssh://[user@]host[:port]/path
+// or the scp-like [user@]host:path. The path has no .git suffix.
+func ParseGitRemote(remote string) (host, repoPath string, err error) {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return "", "", fmt.Errorf("repository URL cannot be empty")
+	}
+
+	switch {
+	case strings.HasPrefix(remote, "ssh://"):
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid SSH remote %q: %w", remote, err)
+		}
+		host, repoPath = parsed.Hostname(), parsed.Path
+	case !strings.Contains(remote, "://"):
+		address, path, ok := strings.Cut(remote, ":")
+		if !ok {
+			return "", "", fmt.Errorf("invalid SSH remote %q: expected user@host:path or ssh://user@host/path", remote)
+		}
+		host, repoPath = address[strings.LastIndex(address, "@")+1:], path
+	default:
+		return "", "", fmt.Errorf("invalid SSH remote %q: only SSH remotes are supported, use user@host:path or ssh://user@host/path", remote)
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" || !filepath.IsLocal(filepath.FromSlash(repoPath)) {
+		return "", "", fmt.Errorf("invalid SSH remote %q: host and repository path cannot be empty", remote)
+	}
+	return strings.ToLower(host), repoPath, nil
+}
+
+// SetCloneOptions sets the options used when cloning the repository
+func (gr *GitRemote) SetCloneOptions(options CloneOptions) {
+	gr.cloneOptions = options
+}
+
+// GetFullRepoName returns the repository path on its host, e.g. team/boba-config
+func (gr *GitRemote) GetFullRepoName() string {
+	return gr.path
+}
+
+// Remote returns the SSH remote the repository is cloned from
+func (gr *GitRemote) Remote() string {
+	return gr.remote
+}
+
+// sshCommand returns the ssh command git runs with the configured key
+func (gr *GitRemote) sshCommand() string {
+	return fmt.Sprintf("ssh -i '%s' -o IdentitiesOnly=yes -o BatchMode=yes", strings.ReplaceAll(gr.sshKey, "'", `'\''`))
+}
+
+// gitEnv returns the environment of the git commands: never prompt, since BOBA owns the
+// terminal, and use the configured key when set
+func (gr *GitRemote) gitEnv() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if gr.sshKey != "" {
+		env = append(env, "GIT_SSH_COMMAND="+gr.sshCommand())
+	} else if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return env
+}
+
+// git runs a git command with the SSH settings of the remote and returns its trimmed output
+func (gr *GitRemote) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), gr.gitEnv()...)
+	output, err := cmd.CombinedOutput()
+	trimmed := strings.TrimSpace(string(output))
+	if err != nil {
+		return trimmed, fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, trimmed)
+	}
+	return trimmed, nil
+}
+
+// TestConnection checks that the remote can be reached over SSH with the key or agent
+func (gr *GitRemote) TestConnection() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git command not found - restart boba to install it, or install git manually: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := gr.git(ctx, "ls-remote", "--exit-code", gr.remote, "HEAD"); err != nil {
+		return fmt.Errorf("cannot reach %s over SSH: %w", gr.remote, err)
+	}
+	return nil
+}
+
+// Update clones the remote on first use and fast-forwards the clone afterwards, so the tools and
+// environments read from it are current. When an existing clone can't be updated it returns
+// ErrStaleClone with the cause, and the clone can still be read.
+func (gr *GitRemote) Update() error {
+	dir, err := gr.GetCloneTargetDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return gr.CloneRepository(dir)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if _, err := gr.git(ctx, "-C", dir, "pull", "--ff-only"); err != nil {
+		return fmt.Errorf("%w: %v", ErrStaleClone, err)
+	}
+	return nil
+}
+
+// clone returns the reader of the local clone, which Update creates
+func (gr *GitRemote) clone() (*LocalRepository, error) {
+	dir, err := gr.GetCloneTargetDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("%s is not cloned yet: %w", gr.remote, err)
+	}
+	return NewLocalRepository(dir), nil
+}
+
+// GetRepositoryContents reads a file of the clone
+func (gr *GitRemote) GetRepositoryContents(path string) ([]byte, error) {
+	clone, err := gr.clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.GetRepositoryContents(path)
+}
+
+// GetDirectoryEntries lists a directory of the clone with the type of each entry
+func (gr *GitRemote) GetDirectoryEntries(path string) ([]DirectoryEntry, error) {
+	clone, err := gr.clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.GetDirectoryEntries(path)
+}
+
+// GetDirectoryContents returns the names of the entries of a directory of the clone
+func (gr *GitRemote) GetDirectoryContents(path string) ([]string, error) {
+	entries, err := gr.GetDirectoryEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names, nil
+}
+
+// GetFilesRecursive returns the paths of all files under a directory of the clone
+func (gr *GitRemote) GetFilesRecursive(path string) ([]string, error) {
+	clone, err := gr.clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.GetFilesRecursive(path)
+}
+
+// GetRepositoryInfo describes the repository from its remote: a git server has no owner type or
+// visibility to report, so it is reported as a private repository of its host
+func (gr *GitRemote) GetRepositoryInfo() (*RepositoryInfo, error) {
+	return &RepositoryInfo{
+		FullName:   gr.path,
+		Owner:      gr.host,
+		OwnerType:  "Server",
+		Visibility: "private",
+	}, nil
+}
+
+// CloneRepository clones the remote to a local directory over SSH
+func (gr *GitRemote) CloneRepository(targetDir string) error {
+	return gr.CloneRepositoryWithProgress(context.Background(), targetDir, nil)
+}
+
+// CloneRepositoryWithProgress clones the remote, reporting git's progress through the callback
+func (gr *GitRemote) CloneRepositoryWithProgress(ctx context.Context, targetDir string, progress func(CloneProgress)) error {
+	options := gr.cloneOptions
+	options.Env = gr.gitEnv()
+	if err := cloneWithProgress(ctx, gr.remote, gr.path, targetDir, options, progress); err != nil {
+		return err
+	}
+
+	// Keep the key for the pulls of Sync Repository and of scripts using BOBA_REPO_DIR
+	if gr.sshKey != "" {
+		if _, err := gr.git(ctx, "-C", targetDir, "config", "core.sshCommand", gr.sshCommand()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCloneTargetDir returns the directory of the clone, below the host so the clone of a git
+// server never shares the clone of a hosted repository
+func (gr *GitRemote) GetCloneTargetDir() (string, error) {
+	reposDir, err := cloneReposDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(reposDir, gr.host, filepath.FromSlash(gr.path)), nil
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		host   string
+		path   string
+	}{
+		{"git@git.example.com:team/boba-config.git", "git.example.com", "team/boba-config"},
+		{"Git.Example.com:boba-config", "git.example.com", "boba-config"},
+		{"ssh://git@git.example.com:2222/team/boba-config.git", "git.example.com", "team/boba-config"},
+		{"ssh://server/srv/git/boba-config.git/", "server", "srv/git/boba-config"},
+	}
+	for _, tt := range tests {
+		host, path, err := ParseGitRemote(tt.remote)
+		if err != nil {
+			t.Errorf("ParseGitRemote(%q) failed: %v", tt.remote, err)
+			continue
+		}
+		if host != tt.host || path != tt.path {
+			t.Errorf("ParseGitRemote(%q) = %q, %q, want %q, %q", tt.remote, host, path, tt.host, tt.path)
+		}
+	}
+
+	for _, remote := range []string{"", "https://git.example.com/team/boba-config", "/srv/git/boba-config.git", "git@git.example.com:", "git@git.example.com:../boba-config"} {
+		if _, _, err := ParseGitRemote(remote); err == nil {
+			t.Errorf("Expected ParseGitRemote(%q) to fail", remote)
+		}
+	}
+}
+
+func TestGitRemoteSSHCommand(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "")
+	remote, err := NewGitRemote("git@git.example.com:team/boba-config.git", "/home/someone/.ssh/it's_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := strings.Join(remote.gitEnv(), "\n")
+	if !strings.Contains(env, `GIT_SSH_COMMAND=ssh -i '/home/someone/.ssh/it'\''s_key' -o IdentitiesOnly=yes -o BatchMode=yes`) {
+		t.Errorf("Expected the key to be passed to ssh, got %s", env)
+	}
+	if !strings.Contains(env, "GIT_TERMINAL_PROMPT=0") {
+		t.Errorf("Expected git never to prompt, got %s", env)
+	}
+
+	remote, _ = NewGitRemote("git@git.example.com:team/boba-config.git", "")
+	if env := strings.Join(remote.gitEnv(), "\n"); !strings.Contains(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes") {
+		t.Errorf("Expected ssh never to prompt without a key, got %s", env)
+	}
+	t.Setenv("GIT_SSH_COMMAND", "ssh -F /etc/boba/ssh_config")
+	if env := strings.Join(remote.gitEnv(), "\n"); strings.Contains(env, "GIT_SSH_COMMAND") {
+		t.Errorf("Expected the user's GIT_SSH_COMMAND to be kept, got %s", env)
+	}
+}
+
+func TestGitRemoteUpdate(t *testing.T) {
+	origin, _ := setupSyncRepos(t)
+	home := t.TempDir()
+	t.Setenv("BOBA_HOME", home)
+
+	remote, err := NewGitRemote("git@git.example.com:team/boba-config.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The remote is reached over SSH in use; a local path stands in for it
+	remote.remote = origin
+
+	if _, err := remote.GetDirectoryContents("tools"); err == nil {
+		t.Error("Expected reading before the first clone to fail")
+	}
+	if err := remote.Update(); err != nil {
+		t.Fatalf("Expected the remote to be cloned, got %v", err)
+	}
+	dir, _ := remote.GetCloneTargetDir()
+	if want := filepath.Join(home, "repos", "git.example.com", "team", "boba-config"); dir != want {
+		t.Errorf("Expected the clone in %s, got %s", want, dir)
+	}
+	names, err := remote.GetDirectoryContents("tools")
+	if err != nil || len(names) != 1 || names[0] != "git" {
+		t.Errorf("Expected the tools of the clone, got %v, %v", names, err)
+	}
+
+	writeFile(t, filepath.Join(origin, "tools", "vim", "tool.yaml"), "name: vim\n")
+	gitRun(t, origin, "add", ".")
+	gitRun(t, origin, "commit", "-q", "-m", "add vim")
+	if err := remote.Update(); err != nil {
+		t.Fatalf("Expected the clone to be updated, got %v", err)
+	}
+	content, err := remote.GetRepositoryContents("tools/vim/tool.yaml")
+	if err != nil || string(content) != "name: vim\n" {
+		t.Errorf("Expected the pulled tool, got %q, %v", content, err)
+	}
+
+	// An unreachable remote leaves the last clone readable
+	if err := os.RemoveAll(origin); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Update(); !errors.Is(err, ErrStaleClone) {
+		t.Errorf("Expected ErrStaleClone, got %v", err)
+	}
+	if files, err := remote.GetFilesRecursive("tools"); err != nil || len(files) != 2 {
+		t.Errorf("Expected the last clone to be read, got %v, %v", files, err)
+	}
+}
@@ -0,0 +1,378 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultGitLabURL is the GitLab instance used when the repository URL names no host
+const DefaultGitLabURL = "https://gitlab.com"
+
+// GitLabClient reads a configuration repository hosted on GitLab (gitlab.com or a self-managed
+// instance) through the REST API, with the same methods as GitHubClient
+type GitLabClient struct {
+	httpClient   *http.Client
+	baseURL      string // e.g. https://gitlab.example.com
+	project      string // Full project path, e.g. platform/dev/boba-config
+	token        string
+	ctx          context.Context
+	cloneOptions CloneOptions
+}
+
+// GitLabError is a failed GitLab API request
+type GitLabError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GitLabError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitLab API returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("GitLab API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// NewGitLabClient creates a client for a project of a GitLab instance
+func NewGitLabClient(token, baseURL, project string) *GitLabClient {
+	if baseURL == "" {
+		baseURL = DefaultGitLabURL
+	}
+	return &GitLabClient{
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		project:      strings.Trim(project, "/"),
+		token:        token,
+		ctx:          context.Background(),
+		cloneOptions: DefaultCloneOptions(),
+	}
+}
+
+// IsGitLabURL reports whether a repository URL points at a GitLab host (gitlab.com or a
+// gitlab.* instance). Self-managed instances on other hosts set repository_provider instead.
+func IsGitLabURL(repoURL string) bool {
+	host, _, ok := splitRepositoryHost(repoURL)
+	if !ok {
+		return false
+	}
+	return host == "gitlab.com" || strings.HasPrefix(host, "gitlab.")
+}
+
+// ParseGitLabURL extracts the instance URL and the project path from a GitLab repository URL.
+// Projects may sit in nested groups (https://gitlab.example.com/group/subgroup/project, or
+// git@gitlab.com:group/project.git); a bare group/project path is read from gitlab.com.
+func ParseGitLabURL(repoURL string) (baseURL, project string, err error) {
+	repoURL = strings.TrimSpace(repoURL)
+	if repoURL == "" {
+		return "", "", fmt.Errorf("repository URL cannot be empty")
+	}
+	
+	baseURL = DefaultGitLabURL
+	project = repoURL
+	if host, path, ok := splitRepositoryHost(repoURL); ok {
+		scheme := "https"
+		if strings.HasPrefix(repoURL, "http://") {
+			scheme = "http"
+		}
+		baseURL = scheme + "://" + host
+		project = path
+	}
+	
+	project = strings.Trim(strings.TrimSuffix(project, ".git"), "/")
+	parts := strings.Split(project, "/")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid repository URL format: expected group/project")
+	}
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return "", "", fmt.Errorf("invalid repository URL: group and project cannot be empty")
+		}
+	}
+	return baseURL, project, nil
+}
+
+// splitRepositoryHost splits an https or SSH repository URL into its host and path
+func splitRepositoryHost(repoURL string) (host, path string, ok bool) {
+	repoURL = strings.TrimSpace(repoURL)
+	if rest, found := strings.CutPrefix(repoURL, "git@"); found {
+		host, path, ok = strings.Cut(rest, ":")
+		return strings.ToLower(host), path, ok
+	}
+	if !strings.HasPrefix(repoURL, "https://") && !strings.HasPrefix(repoURL, "http://") {
+		return "", "", false
+	}
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Host == "" {
+		return "", "", false
+	}
+	return strings.ToLower(parsed.Host), parsed.Path, true
+}
+
+// SetCloneOptions sets the options used when cloning the repository
+func (gl *GitLabClient) SetCloneOptions(options CloneOptions) {
+	gl.cloneOptions = options
+}
+
+// GetToken returns the GitLab token
+func (gl *GitLabClient) GetToken() string {
+	return gl.token
+}
+
+// GetFullRepoName returns the project path (group/project)
+func (gl *GitLabClient) GetFullRepoName() string {
+	return gl.project
+}
+
+// GetBaseURL returns the URL of the GitLab instance
+func (gl *GitLabClient) GetBaseURL() string {
+	return gl.baseURL
+}
+
+// projectURL returns the API URL of a project endpoint
+func (gl *GitLabClient) projectURL(endpoint string, query url.Values) string {
+	address := gl.baseURL + "/api/v4/projects/" + url.PathEscape(gl.project) + endpoint
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+	return address
+}
+
+// get sends an authenticated GET request, returning the body and headers of a 2xx response
+func (gl *GitLabClient) get(address string) ([]byte, http.Header, error) {
+	if gl.project == "" {
+		return nil, nil, fmt.Errorf("repository group and project must be specified")
+	}
+	
+	request, err := http.NewRequestWithContext(gl.ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if gl.token != "" {
+		request.Header.Set("PRIVATE-TOKEN", gl.token)
+	}
+	
+	response, err := gl.httpClient.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+	
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiError := &GitLabError{StatusCode: response.StatusCode}
+		var payload struct {
+			Message any    `json:"message"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			if payload.Message != nil {
+				apiError.Message = fmt.Sprint(payload.Message)
+			} else {
+				apiError.Message = payload.Error
+			}
+		}
+		return nil, nil, apiError
+	}
+	return body, response.Header, nil
+}
+
+// GetRepositoryContents fetches the contents of a file from the default branch
+func (gl *GitLabClient) GetRepositoryContents(path string) ([]byte, error) {
+	path = strings.Trim(path, "/")
+	content, _, err := gl.get(gl.projectURL("/repository/files/"+url.PathEscape(path)+"/raw", url.Values{"ref": {"HEAD"}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// gitLabTreeEntry is one entry of the repository tree API
+type gitLabTreeEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // "blob", "tree" or "commit" (submodule)
+}
+
+// tree lists a repository directory, following the pages of the tree API
+func (gl *GitLabClient) tree(path string, recursive bool) ([]gitLabTreeEntry, error) {
+	query := url.Values{"ref": {"HEAD"}, "per_page": {"100"}}
+	if path = strings.Trim(path, "/"); path != "" {
+		query.Set("path", path)
+	}
+	if recursive {
+		query.Set("recursive", "true")
+	}
+	
+	var entries []gitLabTreeEntry
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		body, header, err := gl.get(gl.projectURL("/repository/tree", query))
+		if err != nil {
+			return nil, err
+		}
+		var pageEntries []gitLabTreeEntry
+		if err := json.Unmarshal(body, &pageEntries); err != nil {
+			return nil, fmt.Errorf("failed to decode repository tree: %w", err)
+		}
+		entries = append(entries, pageEntries...)
+		page = header.Get("X-Next-Page")
+	}
+	return entries, nil
+}
+
+// GetDirectoryEntries lists a repository directory with the type of each entry
+func (gl *GitLabClient) GetDirectoryEntries(path string) ([]DirectoryEntry, error) {
+	treeEntries, err := gl.tree(path, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+	// The tree API lists nothing for a file, where GitHub reports it is not a directory
+	if len(treeEntries) == 0 && strings.Trim(path, "/") != "" {
+		if _, err := gl.GetRepositoryContents(path); err == nil {
+			return nil, fmt.Errorf("%s: %w", path, ErrNotDirectory)
+		}
+	}
+	
+	var entries []DirectoryEntry
+	for _, entry := range treeEntries {
+		entryType := "file"
+		switch entry.Type {
+		case "tree":
+			entryType = "dir"
+		case "commit":
+			entryType = "submodule"
+		}
+		entries = append(entries, DirectoryEntry{Name: entry.Name, Path: entry.Path, Type: entryType})
+	}
+	return entries, nil
+}
+
+// GetDirectoryContents fetches the names of the entries of a repository directory
+func (gl *GitLabClient) GetDirectoryContents(path string) ([]string, error) {
+	entries, err := gl.GetDirectoryEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names, nil
+}
+
+// GetFilesRecursive returns the repository paths of every file below a directory
+func (gl *GitLabClient) GetFilesRecursive(path string) ([]string, error) {
+	treeEntries, err := gl.tree(path, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+	
+	var files []string
+	for _, entry := range treeEntries {
+		if entry.Type == "blob" {
+			files = append(files, entry.Path)
+		}
+	}
+	return files, nil
+}
+
+// TestConnection checks the token and that the project can be read with it
+func (gl *GitLabClient) TestConnection() error {
+	if gl.token == "" {
+		return fmt.Errorf("no GitLab token provided")
+	}
+	
+	_, _, err := gl.get(gl.projectURL("", nil))
+	var apiError *GitLabError
+	switch {
+	case errors.As(err, &apiError) && apiError.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("invalid GitLab token: %w", err)
+	case err != nil:
+		return fmt.Errorf("cannot access repository %s on %s: %w", gl.project, gl.baseURL, err)
+	}
+	return nil
+}
+
+// GetRepositoryInfo fetches the owner and visibility of the project
+func (gl *GitLabClient) GetRepositoryInfo() (*RepositoryInfo, error) {
+	body, _, err := gl.get(gl.projectURL("", nil))
+	if err != nil {
+		return nil, fmt.Errorf("cannot access repository %s on %s: %w", gl.project, gl.baseURL, err)
+	}
+	
+	var project struct {
+		PathWithNamespace string    `json:"path_with_namespace"`
+		Visibility        string    `json:"visibility"`
+		CreatedAt         time.Time `json:"created_at"`
+		ForkedFrom        *struct{} `json:"forked_from_project"`
+		Namespace         struct {
+			FullPath string `json:"full_path"`
+			Kind     string `json:"kind"` // "user" or "group"
+		} `json:"namespace"`
+	}
+	if err := json.Unmarshal(body, &project); err != nil {
+		return nil, fmt.Errorf("failed to decode project %s: %w", gl.project, err)
+	}
+	
+	info := &RepositoryInfo{
+		FullName:   project.PathWithNamespace,
+		Owner:      project.Namespace.FullPath,
+		OwnerType:  "Group",
+		Visibility: project.Visibility,
+		Fork:       project.ForkedFrom != nil,
+		CreatedAt:  project.CreatedAt,
+	}
+	if project.Namespace.Kind == "user" {
+		info.OwnerType = "User"
+	}
+	if info.FullName == "" {
+		info.FullName = gl.project
+	}
+	return info, nil
+}
+
+// CloneRepository clones the repository to a local directory
+func (gl *GitLabClient) CloneRepository(targetDir string) error {
+	return gl.CloneRepositoryWithProgress(context.Background(), targetDir, nil)
+}
+
+// CloneRepositoryWithProgress clones the repository, reporting git's progress through the callback
+func (gl *GitLabClient) CloneRepositoryWithProgress(ctx context.Context, targetDir string, progress func(CloneProgress)) error {
+	if gl.project == "" {
+		return fmt.Errorf("repository group and project must be specified")
+	}
+	
+	cloneURL, err := url.Parse(gl.baseURL + "/" + gl.project + ".git")
+	if err != nil {
+		return fmt.Errorf("invalid GitLab URL: %w", err)
+	}
+	// GitLab accepts any user name with a personal or project access token as the password
+	cloneURL.User = url.UserPassword("oauth2", gl.token)
+	return cloneWithProgress(ctx, cloneURL.String(), gl.project, targetDir, gl.cloneOptions, progress)
+}
+
+// GetCloneTargetDir returns the default directory where the repository should be cloned,
+// below the host so a GitLab project never shares the clone of a GitHub repository
+func (gl *GitLabClient) GetCloneTargetDir() (string, error) {
+	reposDir, err := cloneReposDir()
+	if err != nil {
+		return "", err
+	}
+	
+	host := gl.baseURL
+	if parsed, err := url.Parse(gl.baseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	return filepath.Join(reposDir, host, filepath.FromSlash(gl.project)), nil
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGitLabURL(t *testing.T) {
+	tests := []struct {
+		repoURL     string
+		wantBaseURL string
+		wantProject string
+		wantError   bool
+	}{
+		{"https://gitlab.com/team/boba-config", "https://gitlab.com", "team/boba-config", false},
+		{"https://gitlab.example.com/platform/dev/boba-config.git", "https://gitlab.example.com", "platform/dev/boba-config", false},
+		{"git@gitlab.com:team/boba-config.git", "https://gitlab.com", "team/boba-config", false},
+		{"http://localhost:8080/team/boba-config", "http://localhost:8080", "team/boba-config", false},
+		{"team/boba-config", "https://gitlab.com", "team/boba-config", false},
+		{"boba-config", "", "", true},
+		{"https://gitlab.com/team//boba-config", "", "", true},
+		{"", "", "", true},
+	}
+	
+	for _, tt := range tests {
+		baseURL, project, err := ParseGitLabURL(tt.repoURL)
+		if (err != nil) != tt.wantError {
+			t.Errorf("ParseGitLabURL(%q) error = %v, wantError %v", tt.repoURL, err, tt.wantError)
+			continue
+		}
+		if baseURL != tt.wantBaseURL || project != tt.wantProject {
+			t.Errorf("ParseGitLabURL(%q) = %q, %q, want %q, %q", tt.repoURL, baseURL, project, tt.wantBaseURL, tt.wantProject)
+		}
+	}
+	
+	for repoURL, want := range map[string]bool{
+		"https://gitlab.com/team/boba-config":     true,
+		"git@gitlab.example.com:team/boba-config": true,
+		"https://github.com/owner/repo":           false,
+		"https://code.example.com/team/repo":      false,
+		"owner/repo":                              false,
+	} {
+		if got := IsGitLabURL(repoURL); got != want {
+			t.Errorf("IsGitLabURL(%q) = %v, want %v", repoURL, got, want)
+		}
+	}
+}
+
+// fakeGitLab serves the project, file and tree endpoints of a project with two tools
+func fakeGitLab(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string]string{
+		"tools/git/tool.yaml":  "name: git\n",
+		"tools/git/install.sh": "echo install\n",
+		"boba.yaml":            "min_version: 1.0.0\n",
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/{project}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"401 Unauthorized"}`))
+			return
+		}
+		if r.PathValue("project") != "platform/boba-config" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Project Not Found"}`))
+			return
+		}
+		w.Write([]byte(`{"path_with_namespace":"platform/boba-config","visibility":"internal","namespace":{"full_path":"platform","kind":"group"}}`))
+	})
+	mux.HandleFunc("/api/v4/projects/{project}/repository/files/{path}/raw", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.PathValue("path")]
+		if !ok || r.URL.Query().Get("ref") == "" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 File Not Found"}`))
+			return
+		}
+		w.Write([]byte(content))
+	})
+	mux.HandleFunc("/api/v4/projects/{project}/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("path") == "tools" && query.Get("recursive") == "true":
+			w.Write([]byte(`[{"name":"git","path":"tools/git","type":"tree"},{"name":"tool.yaml","path":"tools/git/tool.yaml","type":"blob"},{"name":"install.sh","path":"tools/git/install.sh","type":"blob"}]`))
+		case query.Get("path") == "tools" && query.Get("page") == "1":
+			// The second page checks that listings follow X-Next-Page
+			w.Header().Set("X-Next-Page", "2")
+			w.Write([]byte(`[{"name":"git","path":"tools/git","type":"tree"}]`))
+		case query.Get("path") == "tools":
+			w.Write([]byte(`[{"name":"README.md","path":"tools/README.md","type":"blob"},{"name":"vendor","path":"tools/vendor","type":"commit"}]`))
+		case query.Get("path") == "environments":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Tree Not Found"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitLabClient(t *testing.T) {
+	server := fakeGitLab(t)
+	client := NewGitLabClient("glpat-valid", server.URL+"/", "platform/boba-config")
+	
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("Expected the project to be reachable, got %v", err)
+	}
+	if err := NewGitLabClient("glpat-revoked", server.URL, "platform/boba-config").TestConnection(); err == nil || !strings.Contains(err.Error(), "invalid GitLab token") {
+		t.Errorf("Expected a rejected token to be reported, got %v", err)
+	}
+	if err := NewGitLabClient("", server.URL, "platform/boba-config").TestConnection(); err == nil {
+		t.Error("Expected a missing token to fail")
+	}
+	
+	content, err := client.GetRepositoryContents("tools/git/tool.yaml")
+	if err != nil || string(content) != "name: git\n" {
+		t.Errorf("Expected tool.yaml content, got %q, %v", content, err)
+	}
+	if _, err := client.GetRepositoryContents("tools/vim/tool.yaml"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing file, got %v", err)
+	}
+	
+	entries, err := client.GetDirectoryEntries("tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DirectoryEntry{
+		{Name: "git", Path: "tools/git", Type: "dir"},
+		{Name: "README.md", Path: "tools/README.md", Type: "file"},
+		{Name: "vendor", Path: "tools/vendor", Type: "submodule"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Expected the entries of both pages, got %+v", entries)
+	}
+	if names, err := client.GetDirectoryContents("tools"); err != nil || len(names) != 3 || names[0] != "git" {
+		t.Errorf("Expected the directory names, got %v, %v", names, err)
+	}
+	if _, err := client.GetDirectoryEntries("environments"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing directory, got %v", err)
+	}
+	if _, err := client.GetDirectoryEntries("boba.yaml"); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("Expected a file to be refused as a directory, got %v", err)
+	}
+	
+	files, err := client.GetFilesRecursive("tools")
+	if err != nil || !reflect.DeepEqual(files, []string{"tools/git/tool.yaml", "tools/git/install.sh"}) {
+		t.Errorf("Expected the files below tools, got %v, %v", files, err)
+	}
+	
+	info, err := client.GetRepositoryInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FullName != "platform/boba-config" || info.Owner != "platform" || info.OwnerType != "Group" || info.Visibility != "internal" || info.Fork {
+		t.Errorf("Unexpected repository info %+v", info)
+	}
+}
+
+func TestGitLabCloneTargetDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("BOBA_HOME", home)
+	
+	dir, err := NewGitLabClient("token", "https://gitlab.example.com", "platform/dev/boba-config").GetCloneTargetDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "repos", "gitlab.example.com", "platform", "dev", "boba-config"); dir != want {
+		t.Errorf("Expected the clone in %s, got %s", want, dir)
+	}
+}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"testing"
 	
 	tea "github.com/charmbracelet/bubbletea"
@@ -88,4 +89,104 @@ func containsInMiddle(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+// TestAuthModelCloneFlow tests progress and cancellation handling of the asynchronous clone
+func TestAuthModelCloneFlow(t *testing.T) {
+	authModel := NewAuthModel(nil, nil)
+	authModel.state = AuthStateValidating
+	events := make(chan tea.Msg, 1)
+	authModel.cloneEvents = events
+	cancelled := false
+	authModel.cloneCancel = func() { cancelled = true }
+	
+	// Progress updates are rendered on the validating screen
+	authModel, cmd := authModel.Update(CloneProgressMsg{Progress: CloneProgress{Phase: "Receiving objects", Percent: 42, Current: 42, Total: 100}})
+	if cmd == nil {
+		t.Error("Expected progress message to keep waiting for clone events")
+	}
+	if view := authModel.View(); !contains(view, "Receiving objects") || !contains(view, "42%") {
+		t.Errorf("Expected clone progress in view, got: %s", view)
+	}
+	
+	// Esc cancels the clone without leaving the app
+	authModel, cmd = authModel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !cancelled {
+		t.Error("Expected Esc to cancel the clone")
+	}
+	if cmd != nil {
+		t.Error("Expected cancelling the clone not to quit")
+	}
+	
+	// Completion after cancellation shows a cancelled error
+	authModel, _ = authModel.Update(AuthMsg{Type: "clone_complete", Success: false, Error: context.Canceled})
+	if authModel.state != AuthStateError {
+		t.Errorf("Expected error state after cancelled clone, got %v", authModel.state)
+	}
+	if !contains(authModel.errorMessage, "cancelled") {
+		t.Errorf("Expected cancellation message, got %q", authModel.errorMessage)
+	}
+	
+	// Successful completion stores the client
+	authModel.state = AuthStateValidating
+	client := NewGitHubClient("token", "owner", "repo")
+	authModel, _ = authModel.Update(AuthMsg{Type: "clone_complete", Success: true, User: "owner", RepoName: "owner/repo", CloneDir: "/tmp/repo", Client: client})
+	if authModel.state != AuthStateSuccess || authModel.GetClient() != client {
+		t.Error("Expected successful clone to store the client and show success")
+	}
+}
+
+// TestAuthModelDeviceFlow tests signing in through the device flow and falling back to a token
+func TestAuthModelDeviceFlow(t *testing.T) {
+	authModel := NewAuthModel(nil, nil)
+	authModel.SetDeviceClientID("client")
+	if authModel.state != AuthStateDeviceCode {
+		t.Fatalf("Expected the device flow to be offered first, got state %v", authModel.state)
+	}
+	authModel.deviceFlow, _ = fakeDeviceFlow(t, "authorization_pending")
+	
+	cmd := authModel.Init()
+	if cmd == nil {
+		t.Fatal("Expected Init to request a device code")
+	}
+	if !contains(authModel.View(), "Requesting a sign-in code") {
+		t.Errorf("Expected the code to be requested, got: %s", authModel.View())
+	}
+	authModel, cmd = authModel.Update(cmd())
+	if view := authModel.View(); !contains(view, "ABCD-1234") || !contains(view, "https://github.com/login/device") || cmd == nil {
+		t.Fatalf("Expected the code and URL while polling, got: %s", view)
+	}
+	
+	// The granted token is validated as a pasted one would be
+	authModel, cmd = authModel.Update(cmd())
+	if authModel.state != AuthStateValidating || authModel.GetToken() != "gho_granted" || cmd == nil {
+		t.Errorf("Expected the granted token to be validated, got state %v", authModel.state)
+	}
+	
+	// T falls back to pasting a token, and a late grant is ignored
+	authModel = NewAuthModel(nil, nil)
+	authModel.SetDeviceClientID("client")
+	authModel.deviceFlow, _ = fakeDeviceFlow(t)
+	authModel.Init()
+	authModel, _ = authModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if authModel.state != AuthStateTokenInput || !contains(authModel.View(), "Press Tab to sign in with your browser") {
+		t.Fatalf("Expected token input with the device flow offered, got state %v", authModel.state)
+	}
+	authModel, _ = authModel.Update(DeviceTokenMsg{Token: "gho_late"})
+	if authModel.state != AuthStateTokenInput || authModel.GetToken() != "" {
+		t.Error("Expected a grant after leaving the device flow to be ignored")
+	}
+	
+	// A denied grant is an error to retry from
+	authModel.Update(tea.KeyMsg{Type: tea.KeyTab})
+	authModel, _ = authModel.Update(DeviceTokenMsg{Error: ErrDeviceAccessDenied})
+	if authModel.state != AuthStateError || !contains(authModel.errorMessage, "denied") {
+		t.Errorf("Expected the denied grant to be reported, got %q", authModel.errorMessage)
+	}
+	
+	// Without a client ID only the token is offered
+	authModel = NewAuthModel(nil, nil)
+	authModel.SetDeviceClientID("")
+	if authModel.state != AuthStateTokenInput || authModel.Init() != nil || contains(authModel.View(), "browser") {
+		t.Error("Expected only the token input without a client ID")
+	}
+}
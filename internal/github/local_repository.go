@@ -0,0 +1,141 @@
+package github
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LocalRepository reads repository contents from a directory on disk, with the same methods
+// as GitHubClient, so a working copy can be used without going through the GitHub API
+type LocalRepository struct {
+	dir string
+}
+
+// NewLocalRepository creates a repository reader for a directory on disk
+func NewLocalRepository(dir string) *LocalRepository {
+	return &LocalRepository{dir: dir}
+}
+
+// Dir returns the directory the repository is read from
+func (lr *LocalRepository) Dir() string {
+	return lr.dir
+}
+
+// resolve returns the path on disk of a repository path, refusing paths outside the directory
+func (lr *LocalRepository) resolve(path string) (string, error) {
+	path = filepath.FromSlash(strings.Trim(path, "/"))
+	if path == "" {
+		return lr.dir, nil
+	}
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("path %s is outside the repository", path)
+	}
+	return filepath.Join(lr.dir, path), nil
+}
+
+// GetRepositoryContents reads a file of the repository
+func (lr *LocalRepository) GetRepositoryContents(path string) ([]byte, error) {
+	fullPath, err := lr.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// CreateFile writes a new file to the repository, refusing to replace an existing one. The
+// message is ignored: changes to a working copy are left for the user to review and commit.
+func (lr *LocalRepository) CreateFile(path string, content []byte, message string) error {
+	fullPath, err := lr.resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	
+	file, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// GetDirectoryEntries lists a repository directory with the type of each entry
+func (lr *LocalRepository) GetDirectoryEntries(path string) ([]DirectoryEntry, error) {
+	fullPath, err := lr.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotDirectory)
+	}
+	
+	dirEntries, err := os.ReadDir(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory %s: %w", path, err)
+	}
+	
+	var entries []DirectoryEntry
+	for _, entry := range dirEntries {
+		entryType := "file"
+		if entry.IsDir() {
+			entryType = "dir"
+		}
+		entries = append(entries, DirectoryEntry{
+			Name: entry.Name(),
+			Path: filepath.ToSlash(filepath.Join(path, entry.Name())),
+			Type: entryType,
+		})
+	}
+	return entries, nil
+}
+
+// GetFilesRecursive returns the paths of all files under a repository directory
+func (lr *LocalRepository) GetFilesRecursive(path string) ([]string, error) {
+	fullPath, err := lr.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	var files []string
+	err = filepath.WalkDir(fullPath, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(lr.dir, current)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relative))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", path, err)
+	}
+	
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRepoFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	fullPath := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLocalRepository(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFile(t, dir, "tools/git/tool.yaml", "name: git\n")
+	writeRepoFile(t, dir, "tools/git/install.sh", "echo install\n")
+	writeRepoFile(t, dir, "tools/README.md", "# Tools\n")
+	writeRepoFile(t, dir, ".git/HEAD", "ref: refs/heads/main\n")
+	
+	repo := NewLocalRepository(dir)
+	
+	content, err := repo.GetRepositoryContents("tools/git/tool.yaml")
+	if err != nil || string(content) != "name: git\n" {
+		t.Errorf("Expected tool.yaml content, got %q, %v", content, err)
+	}
+	if _, err := repo.GetRepositoryContents("tools/vim/tool.yaml"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing file, got %v", err)
+	}
+	if _, err := repo.GetRepositoryContents("../outside"); err == nil {
+		t.Error("Expected paths outside the repository to be refused")
+	}
+	
+	entries, err := repo.GetDirectoryEntries("tools")
+	if err != nil {
+		t.Fatalf("Expected directory entries, got %v", err)
+	}
+	if len(entries) != 2 || entries[0].Path != "tools/README.md" || entries[0].Type != "file" || entries[1].Path != "tools/git" || entries[1].Type != "dir" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if _, err := repo.GetDirectoryEntries("tools/README.md"); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("Expected ErrNotDirectory for a file, got %v", err)
+	}
+	if _, err := repo.GetDirectoryEntries("environments"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a missing directory, got %v", err)
+	}
+	
+	files, err := repo.GetFilesRecursive("")
+	if err != nil {
+		t.Fatalf("Expected files, got %v", err)
+	}
+	if strings.Join(files, ",") != "tools/README.md,tools/git/install.sh,tools/git/tool.yaml" {
+		t.Errorf("Expected files without .git, got %v", files)
+	}
+}
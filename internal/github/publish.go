@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// CreateRepository creates an empty repository owned by the authenticated user and points the
+// client at it
+func (gc *GitHubClient) CreateRepository(name, description string, private bool) error {
+	repo, _, err := gc.client.Repositories.Create(gc.ctx, "", &github.Repository{
+		Name:        github.String(name),
+		Description: github.String(description),
+		Private:     github.Bool(private),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create repository %s: %w", name, err)
+	}
+	gc.owner = repo.GetOwner().GetLogin()
+	gc.repo = repo.GetName()
+	return nil
+}
+
+// PushNewRepository commits the files of dir, initializing a git repository there if needed,
+// and pushes the commit to the main branch of the client's repository. The token is only used
+// for the push: origin is set to the plain HTTPS URL.
+func (gc *GitHubClient) PushNewRepository(dir, message string) error {
+	if gc.owner == "" || gc.repo == "" {
+		return fmt.Errorf("repository owner and name must be specified")
+	}
+	if gc.token == "" {
+		return fmt.Errorf("no GitHub token provided")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git command not found: %w", err)
+	}
+	ctx := context.Background()
+	
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := runGit(ctx, dir, "init"); err != nil {
+			return err
+		}
+		if _, err := runGit(ctx, dir, "symbolic-ref", "HEAD", "refs/heads/main"); err != nil {
+			return err
+		}
+	}
+	if _, err := runGit(ctx, dir, "add", "-A"); err != nil {
+		return err
+	}
+	if changes, err := localChanges(ctx, dir); err != nil {
+		return err
+	} else if len(changes) > 0 {
+		if _, err := runGit(ctx, dir, "commit", "-m", message); err != nil {
+			return err
+		}
+	}
+	
+	originURL := fmt.Sprintf("https://github.com/%s/%s.git", gc.owner, gc.repo)
+	if _, err := runGit(ctx, dir, "remote", "get-url", "origin"); err != nil {
+		_, err = runGit(ctx, dir, "remote", "add", "origin", originURL)
+		if err != nil {
+			return err
+		}
+	} else if _, err := runGit(ctx, dir, "remote", "set-url", "origin", originURL); err != nil {
+		return err
+	}
+	
+	// Not through runGit: its errors include the arguments, which hold the token here
+	pushURL := fmt.Sprintf("https://%s@github.com/%s/%s.git", gc.token, gc.owner, gc.repo)
+	output, err := exec.CommandContext(ctx, "git", "-C", dir, "push", pushURL, "HEAD:refs/heads/main").CombinedOutput()
+	if err != nil {
+		redacted := strings.ReplaceAll(strings.TrimSpace(string(output)), gc.token, "***")
+		return fmt.Errorf("git push to %s failed: %w\nOutput: %s", gc.GetFullRepoName(), err, redacted)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"boba/internal/log"
+)
+
+// Retries of the GitHub API: how many times a request is sent at most, how long a transient
+// server error waits before the first retry (doubling after each), and how long a rate limit may
+// be waited out before giving up with a RateLimitedError
+const (
+	maxAttempts      = 4
+	retryBackoff     = time.Second
+	maxRateLimitWait = time.Minute
+)
+
+// RateLimitedError is returned when the GitHub API rate limit is used up until Reset
+type RateLimitedError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("GitHub API rate limited until %s", e.Reset.Local().Format("15:04"))
+}
+
+// RateLimitMessage returns "rate limited until HH:MM" when the error comes from the GitHub rate
+// limit, for the UI to show instead of the raw error
+func RateLimitMessage(err error) (string, bool) {
+	var limited *RateLimitedError
+	if !errors.As(err, &limited) {
+		return "", false
+	}
+	return fmt.Sprintf("GitHub rate limited until %s: BOBA can read the repository again then.", limited.Reset.Local().Format("15:04")), true
+}
+
+// retryTransport retries the GETs the GitHub API answers with a transient server error, backing
+// off, and waits out a rate limit that resets within a minute using the X-RateLimit and
+// Retry-After headers. A longer rate limit fails with a RateLimitedError instead of the 403.
+type retryTransport struct {
+	base    http.RoundTripper
+	backoff time.Duration // Wait before the first retry of a server error
+	maxWait time.Duration // Longest rate limit waited out
+}
+
+// RoundTrip sends the request, again while it failed transiently
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only reads are sent again: a retried write could commit twice
+	retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+	backoff := t.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		last := !retryable || attempt == maxAttempts
+
+		if reset, limited := rateLimitReset(resp, time.Now()); limited {
+			wait := time.Until(reset)
+			if last || wait > t.maxWait {
+				drain(resp)
+				return nil, &RateLimitedError{Reset: reset}
+			}
+			drain(resp)
+			log.Warn("GitHub API rate limited, waiting", "until", reset.Format("15:04:05"), "url", req.URL.Path)
+			if err := sleep(req, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch resp.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if last {
+				return resp, nil
+			}
+			wait := backoff
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second > wait {
+				wait = time.Duration(seconds) * time.Second
+			}
+			drain(resp)
+			log.Warn("GitHub API failed, retrying", "status", resp.StatusCode, "attempt", attempt, "url", req.URL.Path)
+			if err := sleep(req, wait); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+		default:
+			return resp, nil
+		}
+	}
+}
+
+// rateLimitReset reports whether a response is a rate limit, primary (X-RateLimit-Remaining is 0)
+// or secondary (Retry-After), and when it resets
+func rateLimitReset(resp *http.Response, now time.Time) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false // Denied for another reason, such as a missing permission
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return now.Add(time.Minute), true
+	}
+	return time.Unix(reset, 0), true
+}
+
+// drain reads the rest of a response that won't be returned, so its connection is reused
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// sleep waits before sending the request again, or returns early when its context ends
+func sleep(req *http.Request, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
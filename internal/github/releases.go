@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+
+	"boba/internal/version"
+)
+
+// Repository BOBA itself is released from
+const (
+	BobaReleaseOwner = "Walter0697"
+	BobaReleaseRepo  = "Boba"
+)
+
+// Release is a published BOBA release
+type Release struct {
+	Version     string            // Tag name, e.g. v1.4.0
+	Name        string            // Release title
+	Notes       string            // Release notes (markdown)
+	Prerelease  bool              // Edge channel release
+	PublishedAt time.Time
+	Assets      map[string]string // Asset name -> download URL
+}
+
+// FetchBobaReleases lists the published (non-draft) BOBA releases
+func FetchBobaReleases() ([]Release, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	
+	// Releases of the public BOBA repository don't need the user's token
+	client := github.NewClient(nil)
+	published, _, err := client.Repositories.ListReleases(ctx, BobaReleaseOwner, BobaReleaseRepo, &github.ListOptions{PerPage: 30})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list BOBA releases: %w", err)
+	}
+	
+	var releases []Release
+	for _, r := range published {
+		if r.GetDraft() {
+			continue
+		}
+		release := Release{
+			Version:     r.GetTagName(),
+			Name:        r.GetName(),
+			Notes:       r.GetBody(),
+			Prerelease:  r.GetPrerelease(),
+			PublishedAt: r.GetPublishedAt().Time,
+			Assets:      make(map[string]string),
+		}
+		for _, asset := range r.Assets {
+			release.Assets[asset.GetName()] = asset.GetBrowserDownloadURL()
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// LatestRelease returns the newest release of a channel: stable releases only,
+// or prereleases too for the edge channel. Releases without a version tag are ignored.
+func LatestRelease(releases []Release, includePrerelease bool) *Release {
+	var latest *Release
+	for i := range releases {
+		release := &releases[i]
+		if release.Prerelease && !includePrerelease {
+			continue
+		}
+		if _, err := version.Parse(release.Version); err != nil {
+			continue
+		}
+		if latest == nil {
+			latest = release
+			continue
+		}
+		if cmp, _ := version.Compare(release.Version, latest.Version); cmp > 0 {
+			latest = release
+		}
+	}
+	return latest
+}
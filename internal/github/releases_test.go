@@ -0,0 +1,22 @@
+package github
+
+import "testing"
+
+func TestLatestRelease(t *testing.T) {
+	releases := []Release{
+		{Version: "v1.2.0"},
+		{Version: "v1.4.0-rc.1", Prerelease: true},
+		{Version: "v1.3.1"},
+		{Version: "nightly", Prerelease: true},
+	}
+	
+	if latest := LatestRelease(releases, false); latest == nil || latest.Version != "v1.3.1" {
+		t.Errorf("Expected stable channel to pick v1.3.1, got %+v", latest)
+	}
+	if latest := LatestRelease(releases, true); latest == nil || latest.Version != "v1.4.0-rc.1" {
+		t.Errorf("Expected edge channel to pick v1.4.0-rc.1, got %+v", latest)
+	}
+	if latest := LatestRelease(nil, true); latest != nil {
+		t.Errorf("Expected no release, got %+v", latest)
+	}
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// SyncStrategy controls how local modifications are handled when syncing the local clone
+type SyncStrategy int
+
+const (
+	SyncFastForward SyncStrategy = iota // Fast-forward only, refuse to touch local modifications
+	SyncStash                           // Stash local modifications, then fast-forward
+	SyncDiscard                         // Discard local modifications, then fast-forward
+)
+
+// ErrLocalChanges is returned when the local clone has modifications and the strategy is SyncFastForward
+var ErrLocalChanges = errors.New("local clone has uncommitted changes")
+
+// SyncResult describes the outcome of a repository sync
+type SyncResult struct {
+	PreviousCommit string   // HEAD before the sync
+	CurrentCommit  string   // HEAD after the sync
+	Updated        bool     // Whether new commits were pulled
+	LocalChanges   []string // Modified files found in the local clone (git status --porcelain lines)
+	Stashed        bool     // Whether local changes were stashed
+	Discarded      bool     // Whether local changes were discarded
+}
+
+// runGit runs a git command in the repository directory and returns its trimmed output
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	trimmed := strings.TrimSpace(string(output))
+	if err != nil {
+		return trimmed, fmt.Errorf("git %s failed: %w\nOutput: %s", strings.Join(args, " "), err, trimmed)
+	}
+	return trimmed, nil
+}
+
+// LocalChanges returns the modified and untracked files in the local clone
+func LocalChanges(dir string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return localChanges(ctx, dir)
+}
+
+func localChanges(ctx context.Context, dir string) ([]string, error) {
+	output, err := runGit(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// HeadCommit returns the commit currently checked out in the local clone
+func HeadCommit(dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return runGit(ctx, dir, "rev-parse", "HEAD")
+}
+
+// SyncRepository fast-forwards the local clone to the latest remote commit.
+// Local modifications are handled according to the strategy: SyncFastForward returns
+// ErrLocalChanges (with the changes in the result) so the caller can ask the user what to do.
+func SyncRepository(dir string, strategy SyncStrategy) (*SyncResult, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git command not found - please install git: %w", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	
+	previous, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git clone: %w", dir, err)
+	}
+	result := &SyncResult{PreviousCommit: previous}
+	
+	changes, err := localChanges(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	result.LocalChanges = changes
+	
+	if len(changes) > 0 {
+		switch strategy {
+		case SyncStash:
+			message := fmt.Sprintf("boba sync %s", time.Now().Format(time.RFC3339))
+			if _, err := runGit(ctx, dir, "stash", "push", "--include-untracked", "-m", message); err != nil {
+				return result, fmt.Errorf("failed to stash local changes: %w", err)
+			}
+			result.Stashed = true
+		case SyncDiscard:
+			if _, err := runGit(ctx, dir, "reset", "--hard", "HEAD"); err != nil {
+				return result, fmt.Errorf("failed to discard local changes: %w", err)
+			}
+			if _, err := runGit(ctx, dir, "clean", "-fd"); err != nil {
+				return result, fmt.Errorf("failed to remove untracked files: %w", err)
+			}
+			result.Discarded = true
+		default:
+			return result, ErrLocalChanges
+		}
+	}
+	
+	if _, err := runGit(ctx, dir, "pull", "--ff-only"); err != nil {
+		return result, fmt.Errorf("cannot fast-forward the local clone (local commits or diverged history): %w", err)
+	}
+	
+	current, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return result, err
+	}
+	result.CurrentCommit = current
+	result.Updated = current != previous
+	return result, nil
+}
+
+// Summary returns a short human readable description of the sync result
+func (r *SyncResult) Summary() string {
+	var s strings.Builder
+	if r.Updated {
+		s.WriteString(fmt.Sprintf("Updated %s → %s", shortCommit(r.PreviousCommit), shortCommit(r.CurrentCommit)))
+	} else {
+		s.WriteString(fmt.Sprintf("Already up to date (%s)", shortCommit(r.CurrentCommit)))
+	}
+	if r.Stashed {
+		s.WriteString(fmt.Sprintf("\nStashed %d local change(s) (restore with 'git stash pop')", len(r.LocalChanges)))
+	}
+	if r.Discarded {
+		s.WriteString(fmt.Sprintf("\nDiscarded %d local change(s)", len(r.LocalChanges)))
+	}
+	return s.String()
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// setupSyncRepos creates an origin repository with one commit and a local clone of it
+func setupSyncRepos(t *testing.T) (origin, clone string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	
+	root := t.TempDir()
+	origin = filepath.Join(root, "origin")
+	clone = filepath.Join(root, "clone")
+	
+	gitRun(t, root, "init", "-q", origin)
+	writeFile(t, filepath.Join(origin, "tools", "git", "tool.yaml"), "name: git\n")
+	gitRun(t, origin, "add", ".")
+	gitRun(t, origin, "commit", "-q", "-m", "initial")
+	gitRun(t, root, "clone", "-q", origin, clone)
+	return origin, clone
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestSyncRepositoryFastForward(t *testing.T) {
+	origin, clone := setupSyncRepos(t)
+	
+	result, err := SyncRepository(clone, SyncFastForward)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Updated {
+		t.Error("Expected clone to already be up to date")
+	}
+	
+	writeFile(t, filepath.Join(origin, "tools", "vim", "tool.yaml"), "name: vim\n")
+	gitRun(t, origin, "add", ".")
+	gitRun(t, origin, "commit", "-q", "-m", "add vim")
+	
+	result, err = SyncRepository(clone, SyncFastForward)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Updated {
+		t.Error("Expected clone to be updated")
+	}
+	if _, err := os.Stat(filepath.Join(clone, "tools", "vim", "tool.yaml")); err != nil {
+		t.Errorf("Expected pulled file in clone: %v", err)
+	}
+}
+
+func TestSyncRepositoryLocalChanges(t *testing.T) {
+	origin, clone := setupSyncRepos(t)
+	writeFile(t, filepath.Join(origin, "tools", "vim", "tool.yaml"), "name: vim\n")
+	gitRun(t, origin, "add", ".")
+	gitRun(t, origin, "commit", "-q", "-m", "add vim")
+	
+	// Local edits block a plain fast-forward
+	writeFile(t, filepath.Join(clone, "tools", "git", "tool.yaml"), "name: git-edited\n")
+	result, err := SyncRepository(clone, SyncFastForward)
+	if !errors.Is(err, ErrLocalChanges) {
+		t.Fatalf("Expected ErrLocalChanges, got %v", err)
+	}
+	if len(result.LocalChanges) != 1 {
+		t.Errorf("Expected 1 local change, got %v", result.LocalChanges)
+	}
+	
+	// Stashing keeps the edits in the stash and syncs
+	result, err = SyncRepository(clone, SyncStash)
+	if err != nil {
+		t.Fatalf("Expected stash sync to succeed, got %v", err)
+	}
+	if !result.Stashed || !result.Updated {
+		t.Errorf("Expected stashed and updated result, got %+v", result)
+	}
+	if changes, _ := LocalChanges(clone); len(changes) != 0 {
+		t.Errorf("Expected clean clone after stash, got %v", changes)
+	}
+	
+	// Discarding removes edits and untracked files
+	writeFile(t, filepath.Join(clone, "tools", "git", "tool.yaml"), "name: git-edited\n")
+	writeFile(t, filepath.Join(clone, "scratch.txt"), "scratch\n")
+	result, err = SyncRepository(clone, SyncDiscard)
+	if err != nil {
+		t.Fatalf("Expected discard sync to succeed, got %v", err)
+	}
+	if !result.Discarded {
+		t.Error("Expected discarded result")
+	}
+	if _, err := os.Stat(filepath.Join(clone, "scratch.txt")); !os.IsNotExist(err) {
+		t.Error("Expected untracked file to be removed")
+	}
+}
@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+
+	"boba/internal/config"
+)
+
+// ErrNotApproved is returned on machines that only run approved plans, for scripts that are not
+// part of the approved plan of the current run
+var ErrNotApproved = errors.New("not part of an approved plan")
+
+// SetApprovalPolicy makes the engine refuse to run scripts outside of a plan approved with one of
+// the keys (managed mode)
+func (ie *InstallationEngine) SetApprovalPolicy(required bool, keys []string) {
+	ie.needApproval = required
+	ie.approvalKeys = keys
+}
+
+// ApprovalRequired reports whether the engine only runs approved plans
+func (ie *InstallationEngine) ApprovalRequired() bool {
+	return ie.needApproval
+}
+
+// ApprovePlan verifies the approval of a plan and lets its operations run until the next run
+// begins. Without managed mode every plan is accepted.
+func (ie *InstallationEngine) ApprovePlan(plan config.ExecutionPlan) error {
+	if ie.needApproval {
+		if err := config.VerifyPlanApproval(plan, ie.approvalKeys); err != nil {
+			return err
+		}
+	}
+	
+	ie.approved = make(map[string]string, len(plan.Entries))
+	for _, entry := range plan.Entries {
+		ie.approved[entry.Kind+"/"+entry.Name] = entry.ScriptHash
+	}
+	return nil
+}
+
+// checkApproved refuses an operation in managed mode unless the approved plan of the run has it,
+// with the same scripts
+func (ie *InstallationEngine) checkApproved(kind, name string, scriptHash func() (string, error)) error {
+	if !ie.needApproval {
+		return nil
+	}
+	approvedHash, ok := ie.approved[kind+"/"+name]
+	if !ok {
+		return fmt.Errorf("%s %s: %w (this machine only runs plans approved by an admin)", kind, name, ErrNotApproved)
+	}
+	hash, err := scriptHash()
+	if err != nil {
+		return err
+	}
+	if hash != approvedHash {
+		return fmt.Errorf("%s %s: %w: its scripts changed since the plan was approved", kind, name, ErrNotApproved)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// folderLister is implemented by repository clients that can list every file below a folder
+type folderLister interface {
+	GetFilesRecursive(path string) ([]string, error)
+}
+
+// stageAssets copies the whole manifest folder (tools/<name> or environments/<name>) into a
+// staging directory so scripts can use companion files shipped alongside them.
+// It returns an empty path when the folder cannot be listed with the current client.
+func (ie *InstallationEngine) stageAssets(folder string) (string, error) {
+	if folder == "" {
+		return "", nil // Catalog entries have no folder to stage
+	}
+	
+	stageDir := filepath.Join(ie.tempDir, "assets", strings.ReplaceAll(folder, string(filepath.Separator), "_"))
+	if err := os.RemoveAll(stageDir); err != nil {
+		return "", fmt.Errorf("failed to clear staging directory: %w", err)
+	}
+	
+	// Prefer the local clone when available, it avoids one API call per file
+	if ie.repoDir != "" {
+		sourceDir := filepath.Join(ie.repoDir, folder)
+		if info, err := os.Stat(sourceDir); err == nil && info.IsDir() {
+			if err := copyDirectory(sourceDir, stageDir); err != nil {
+				return "", fmt.Errorf("failed to stage assets from local clone: %w", err)
+			}
+			return stageDir, nil
+		}
+	}
+	
+	lister, ok := ie.githubClient.(folderLister)
+	if !ok {
+		return "", nil
+	}
+	
+	files, err := lister.GetFilesRecursive(folder)
+	if err != nil {
+		return "", fmt.Errorf("failed to list assets in %s: %w", folder, err)
+	}
+	
+	for _, file := range files {
+		relPath, err := filepath.Rel(folder, file)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			continue // Never write outside the staging directory
+		}
+		
+		content, err := ie.githubClient.GetRepositoryContents(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to download asset %s: %w", file, err)
+		}
+		
+		targetPath := filepath.Join(stageDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create asset directory: %w", err)
+		}
+		
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(file, ".sh") {
+			mode = 0755
+		}
+		if err := os.WriteFile(targetPath, content, mode); err != nil {
+			return "", fmt.Errorf("failed to write asset %s: %w", relPath, err)
+		}
+	}
+	
+	return stageDir, nil
+}
+
+// copyDirectory recursively copies a directory tree, skipping symlinks
+func copyDirectory(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(dst, relPath)
+		
+		if info.IsDir() {
+			return os.MkdirAll(targetPath, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		
+		return copyFile(path, targetPath, info.Mode().Perm())
+	})
+}
+
+// copyFile copies a single file with the given permissions
+func copyFile(src, dst string, mode os.FileMode) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+	
+	destFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+	
+	_, err = io.Copy(destFile, sourceFile)
+	return err
+}
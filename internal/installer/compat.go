@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"boba/internal/parser"
+	"boba/internal/version"
+)
+
+// APIVersion is the version of the script execution contract (the BOBA_* variables, helpers and
+// manifest fields scripts can rely on), exposed to scripts as BOBA_API_VERSION.
+// Bump it whenever that contract gains or changes a feature.
+const APIVersion = 1
+
+// ErrBobaTooOld is returned when a manifest requires a newer BOBA than the running binary
+var ErrBobaTooOld = errors.New("requires a newer BOBA")
+
+// checkBobaVersion returns ErrBobaTooOld when the running binary is older than min_boba_version
+func checkBobaVersion(name, required string) error {
+	if required == "" {
+		return nil
+	}
+	ok, err := version.Satisfies(required)
+	if err != nil {
+		return fmt.Errorf("%s has an invalid min_boba_version: %w", name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s %w (min_boba_version %s, running %s); update BOBA", name, ErrBobaTooOld, required, version.Version)
+	}
+	return nil
+}
+
+// ErrUnsupportedPlatform is returned when the repository does not support this machine's platform
+var ErrUnsupportedPlatform = errors.New("does not support this platform")
+
+// CheckRepository checks the requirements the repository declares in boba.yaml against the
+// running binary and this machine, so an incompatible repository fails on connect instead of
+// in the middle of an install
+func (ie *InstallationEngine) CheckRepository(info *parser.RepositoryInfo) error {
+	if info == nil {
+		return nil
+	}
+	if err := checkBobaVersion("the repository", info.MinBobaVersion); err != nil {
+		return err
+	}
+	if len(info.Platforms) == 0 {
+		return nil
+	}
+	for _, platform := range info.Platforms {
+		os, arch, hasArch := strings.Cut(strings.ToLower(platform), "/")
+		if os == ie.platform.OS && (!hasArch || arch == ie.platform.Arch) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the repository %w: it supports %s, this machine is %s/%s", ErrUnsupportedPlatform, strings.Join(info.Platforms, ", "), ie.platform.OS, ie.platform.Arch)
+}
+
+// checkRequirements checks the BOBA version and script library version a manifest requires
+// before any of its scripts run
+func checkRequirements(name, minBobaVersion string, libVersion int) error {
+	if err := checkBobaVersion(name, minBobaVersion); err != nil {
+		return err
+	}
+	return checkLibraryVersion(name, libVersion)
+}
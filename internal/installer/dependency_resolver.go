@@ -1,10 +1,18 @@
 package installer
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"boba/internal/parser"
 )
 
+// Dependency resolution errors, wrapped with the tool or environment they concern
+var (
+	ErrCircularDependency = errors.New("circular dependency detected")
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
 // DependencyResolver handles dependency resolution for tools and environments
 type DependencyResolver struct{}
 
@@ -34,12 +42,12 @@ func (dr *DependencyResolver) ResolveToolDependencies(tools []parser.Tool) ([]pa
 		}
 		
 		if visiting[toolName] {
-			return fmt.Errorf("circular dependency detected involving tool: %s", toolName)
+			return fmt.Errorf("%w involving tool: %s", ErrCircularDependency, toolName)
 		}
 		
 		tool, exists := toolMap[toolName]
 		if !exists {
-			return fmt.Errorf("dependency not found: %s", toolName)
+			return fmt.Errorf("%w: %s", ErrDependencyNotFound, toolName)
 		}
 		
 		visiting[toolName] = true
@@ -58,8 +66,16 @@ func (dr *DependencyResolver) ResolveToolDependencies(tools []parser.Tool) ([]pa
 		return nil
 	}
 	
-	// Process all tools
-	for _, tool := range tools {
+	// Process all tools, highest priority first so bootstrap tools (and their dependencies)
+	// lead the order; ties are broken by name so the order never depends on the listing
+	byPriority := append([]parser.Tool(nil), tools...)
+	sort.SliceStable(byPriority, func(i, j int) bool {
+		if byPriority[i].Priority != byPriority[j].Priority {
+			return byPriority[i].Priority > byPriority[j].Priority
+		}
+		return byPriority[i].Name < byPriority[j].Name
+	})
+	for _, tool := range byPriority {
 		if err := visit(tool.Name); err != nil {
 			return nil, err
 		}
@@ -89,12 +105,12 @@ func (dr *DependencyResolver) ResolveEnvironmentDependencies(environments []pars
 		}
 		
 		if visiting[envName] {
-			return fmt.Errorf("circular dependency detected involving environment: %s", envName)
+			return fmt.Errorf("%w involving environment: %s", ErrCircularDependency, envName)
 		}
 		
 		env, exists := envMap[envName]
 		if !exists {
-			return fmt.Errorf("environment dependency not found: %s", envName)
+			return fmt.Errorf("environment %w: %s", ErrDependencyNotFound, envName)
 		}
 		
 		visiting[envName] = true
@@ -174,4 +190,43 @@ func (dr *DependencyResolver) GetInstallationOrder(tools []parser.Tool, environm
 	}
 	
 	return orderedTools, orderedEnvironments, nil
-}
\ No newline at end of file
+}
+
+// LeaveOutTools removes the tools to skip and, transitively, the tools depending on them. It
+// returns the remaining tools and, for each tool left out, the reason from skip or, for the tools
+// left out because of a dependency, which dependency.
+func (dr *DependencyResolver) LeaveOutTools(tools []parser.Tool, skip map[string]string) ([]parser.Tool, map[string]string) {
+	reasons := make(map[string]string)
+	if len(skip) == 0 {
+		return tools, reasons
+	}
+	
+	for changed := true; changed; {
+		changed = false
+		for _, tool := range tools {
+			if _, removed := reasons[tool.Name]; removed {
+				continue
+			}
+			if reason, ok := skip[tool.Name]; ok {
+				reasons[tool.Name] = reason
+				changed = true
+				continue
+			}
+			for _, dependency := range tool.Dependencies {
+				if _, removed := reasons[dependency]; removed {
+					reasons[tool.Name] = fmt.Sprintf("depends on %s, which is left out", dependency)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	
+	var kept []parser.Tool
+	for _, tool := range tools {
+		if _, removed := reasons[tool.Name]; !removed {
+			kept = append(kept, tool)
+		}
+	}
+	return kept, reasons
+}
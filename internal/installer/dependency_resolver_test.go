@@ -42,6 +42,35 @@ func TestResolveToolDependencies(t *testing.T) {
 	}
 }
 
+func TestResolveToolDependencies_Priority(t *testing.T) {
+	resolver := NewDependencyResolver()
+	
+	tools := []parser.Tool{
+		{Name: "zsh"},
+		{Name: "node", Dependencies: []string{"nvm"}},
+		{Name: "nvm"},
+		{Name: "curl", Priority: 10},
+		{Name: "build-essential", Priority: 10},
+		{Name: "git", Priority: 20, Dependencies: []string{"openssl"}},
+		{Name: "openssl"},
+		{Name: "cleanup", Priority: -1},
+	}
+	
+	resolved, err := resolver.ResolveToolDependencies(tools)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	
+	var names []string
+	for _, tool := range resolved {
+		names = append(names, tool.Name)
+	}
+	expected := "openssl git build-essential curl nvm node zsh cleanup"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("Expected order %s, got %s", expected, strings.Join(names, " "))
+	}
+}
+
 func TestResolveToolDependencies_CircularDependency(t *testing.T) {
 	resolver := NewDependencyResolver()
 	
@@ -0,0 +1,196 @@
+package installer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"boba/internal/parser"
+)
+
+// DryRunPlan describes what installing a tool would do, without running anything
+type DryRunPlan struct {
+	Script       []byte   // Install script that would run (the matching steps, for a multi-step install)
+	ScriptSource string   // "inline", "steps" or the repository path of the install script
+	WorkingDir   string   // Directory the script would run in
+	Environment  []string // BOBA_* variables passed to the script, sorted
+	Dependencies []string // Tools that would be installed first
+	NotEvaluated []string // satisfied_when checks that were not run; installing skips what they find in place
+}
+
+// DryRunTool resolves the install script, working directory and BOBA_* environment of a tool
+// without staging assets or executing anything
+func (ie *InstallationEngine) DryRunTool(tool parser.Tool) (*DryRunPlan, error) {
+	if ie.githubClient == nil {
+		return nil, fmt.Errorf("GitHub client not initialized")
+	}
+	
+	if err := checkRequirements(tool.Name, tool.MinBobaVersion, tool.LibVersion); err != nil {
+		return nil, err
+	}
+	
+	var scriptContent []byte
+	var notEvaluated []string
+	var err error
+	if len(tool.Steps) > 0 {
+		scriptContent, notEvaluated, err = ie.dryRunSteps(tool)
+	} else {
+		scriptContent, err = ie.toolScriptContent(tool, tool.InstallInline, tool.InstallScript)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install script: %w", err)
+	}
+	source := scriptSourceName(tool.InstallInline, tool.InstallScript)
+	if len(tool.Steps) > 0 {
+		source = "steps"
+	}
+	
+	plan, err := ie.dryRunToolScript(tool, scriptContent, source)
+	if err != nil {
+		return nil, err
+	}
+	// satisfied_when checks run commands of the repository: a dry run only names them
+	if tool.SatisfiedWhen != nil {
+		notEvaluated = append([]string{"satisfied_when"}, notEvaluated...)
+	}
+	plan.NotEvaluated = notEvaluated
+	return plan, nil
+}
+
+// dryRunToolScript describes how a script of a tool would run
+func (ie *InstallationEngine) dryRunToolScript(tool parser.Tool, scriptContent []byte, source string) (*DryRunPlan, error) {
+	if tool.ScriptSource != nil && source != "inline" && source != "steps" {
+		source = fmt.Sprintf("%s (%s)", source, tool.ScriptSource)
+	}
+	folder := manifestFolder("tools", tool.FolderName, tool.Catalog)
+	workingDir, environment, err := ie.dryRunContext(folder, tool.WorkingDir, func(assetsDir string) []string {
+		return append(append([]string{
+			fmt.Sprintf("BOBA_TOOL_NAME=%s", tool.Name),
+		}, ie.repositoryEnvironment(folder, assetsDir)...), append(ie.scopeEnvironment(tool), ie.parameterEnvironment(tool)...)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &DryRunPlan{
+		Script:       scriptContent,
+		ScriptSource: source,
+		WorkingDir:   workingDir,
+		Environment:  environment,
+		Dependencies: tool.Dependencies,
+	}, nil
+}
+
+// dryRunEnvironment describes how the setup or restore script of an environment would run
+func (ie *InstallationEngine) dryRunEnvironment(env parser.Environment, scriptContent []byte, source string) (*DryRunPlan, error) {
+	folder := manifestFolder("environments", env.FolderName, env.Catalog)
+	workingDir, environment, err := ie.dryRunContext(folder, env.WorkingDir, func(assetsDir string) []string {
+		return append([]string{
+			fmt.Sprintf("BOBA_ENV_NAME=%s", env.Name),
+			fmt.Sprintf("BOBA_ENV_SHELL=%s", env.Shell),
+		}, ie.repositoryEnvironment(folder, assetsDir)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &DryRunPlan{
+		Script:       scriptContent,
+		ScriptSource: source,
+		WorkingDir:   workingDir,
+		Environment:  environment,
+		Dependencies: env.Dependencies,
+	}, nil
+}
+
+// dryRunContext resolves the working directory and the BOBA_* variables, sorted, a script of the
+// manifest folder would run with. vars returns the script variables for the assets directory.
+func (ie *InstallationEngine) dryRunContext(folder, manifestWorkingDir string, vars func(assetsDir string) []string) (string, []string, error) {
+	// Scripts get their own directory inside the run directory when they actually run
+	if _, err := ie.ensureRunDir(); err != nil {
+		return "", nil, err
+	}
+	
+	// Assets are only staged when the script runs: describe where they would go
+	assetsDir := ""
+	if folder != "" {
+		assetsDir = filepath.Join(ie.tempDir, "assets", strings.ReplaceAll(folder, string(filepath.Separator), "_"))
+	}
+	workingDir, err := ie.resolveWorkingDir(manifestWorkingDir, folder, assetsDir)
+	if err != nil {
+		return "", nil, err
+	}
+	
+	var environment []string
+	for _, entry := range ie.scriptEnvironment(vars(assetsDir)...) {
+		if strings.HasPrefix(entry, "BOBA_") {
+			environment = append(environment, entry)
+		}
+	}
+	sort.Strings(environment)
+	return workingDir, environment, nil
+}
+
+// scriptSourceName names where a script comes from: "inline" or its repository path
+func scriptSourceName(inline, path string) string {
+	if inline != "" {
+		return "inline"
+	}
+	return filepath.ToSlash(path)
+}
+
+// Describe lists what the plan would run, for the output of dry-run mode
+func (p *DryRunPlan) Describe(action, name string) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "[dry run] would %s %s\n", action, name)
+	fmt.Fprintf(&s, "  Script:      %s (%d bytes)\n", p.ScriptSource, len(p.Script))
+	fmt.Fprintf(&s, "  Working dir: %s\n", p.WorkingDir)
+	if len(p.NotEvaluated) > 0 {
+		fmt.Fprintf(&s, "  Not evaluated: %s\n", strings.Join(p.NotEvaluated, ", "))
+	}
+	if len(p.Environment) > 0 {
+		s.WriteString("  Environment:\n")
+		for _, entry := range p.Environment {
+			fmt.Fprintf(&s, "    %s\n", entry)
+		}
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+// dryRunResult returns the result of an operation in dry-run mode: the description of its plan
+func (ie *InstallationEngine) dryRunResult(action, name string, startTime time.Time, plan func() (*DryRunPlan, error)) (*InstallationResult, error) {
+	p, err := plan()
+	if err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	return &InstallationResult{Success: true, DryRun: true, Output: p.Describe(action, name), Duration: time.Since(startTime)}, nil
+}
+
+// dryRunSteps joins the scripts of the steps that would run on this machine, each under a
+// comment naming it, and lists the skipped steps with the reason. The satisfied_when checks of
+// the steps are not run; they are returned as not evaluated.
+func (ie *InstallationEngine) dryRunSteps(tool parser.Tool) ([]byte, []string, error) {
+	scripts, err := ie.stepScripts(tool)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	env := ie.conditionEnvironment(tool)
+	var plan strings.Builder
+	var notEvaluated []string
+	for i, step := range tool.Steps {
+		if reason := ie.stepSkipReason(step.When, env); reason != "" {
+			fmt.Fprintf(&plan, "# ── %s: skipped (%s)\n", step.Label(i), reason)
+			continue
+		}
+		if step.SatisfiedWhen != nil {
+			fmt.Fprintf(&plan, "# ── %s (skipped if its satisfied_when check holds, not evaluated)\n%s\n", step.Label(i), strings.TrimRight(string(scripts[i]), "\n"))
+			notEvaluated = append(notEvaluated, step.Label(i)+" satisfied_when")
+			continue
+		}
+		fmt.Fprintf(&plan, "# ── %s\n%s\n", step.Label(i), strings.TrimRight(string(scripts[i]), "\n"))
+	}
+	return []byte(plan.String()), notEvaluated, nil
+}
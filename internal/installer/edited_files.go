@@ -0,0 +1,327 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+// Ways to settle a home file the user edited since an environment last wrote it
+const (
+	EditKeepMine = "mine"  // Keep the user's file as it is
+	EditTakeRepo = "repo"  // Take the repository's version, dropping the edits
+	EditMerge    = "merge" // Three-way merge of the edits and the repository's changes
+)
+
+// ManagedFileStore remembers each home file as the environment applying it last wrote it
+type ManagedFileStore interface {
+	GetManagedFile(file string) (config.ManagedFile, []byte, bool)
+	RecordManagedFile(file, environment string, content []byte) error
+	ForgetManagedFile(file string) error
+}
+
+// EditedFile is a home file the user edited since an environment last wrote it, which applying
+// an environment again would overwrite
+type EditedFile struct {
+	File        string // Relative to $HOME
+	Environment string // Environment about to write it
+	AppliedBy   string // Environment that wrote it last
+	Base        []byte // The file as last applied
+	Mine        []byte // The file now, with the user's edits
+	Repo        []byte // The repository's config file, nil when it can't be fetched
+}
+
+// EditedFilesError is returned when a run would overwrite edited home files for which neither
+// keeping, taking the repository's version nor merging was chosen
+type EditedFilesError struct {
+	Files []EditedFile
+}
+
+func (e *EditedFilesError) Error() string {
+	lines := []string{"files were edited since BOBA last applied them, choose to keep yours, take the repository's or merge for each:"}
+	for _, file := range e.Files {
+		lines = append(lines, fmt.Sprintf("  ~/%s: last applied by %s", file.File, file.AppliedBy))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetManagedFiles sets where the home files written by environments are remembered. Without a
+// store, edits are not detected.
+func (ie *InstallationEngine) SetManagedFiles(store ManagedFileStore) {
+	ie.managedFiles = store
+}
+
+// SetEditResolution settles an edited home file for the next application writing it: EditKeepMine,
+// EditTakeRepo or EditMerge
+func (ie *InstallationEngine) SetEditResolution(file, resolution string) {
+	if ie.editResolutions == nil {
+		ie.editResolutions = make(map[string]string)
+	}
+	ie.editResolutions[file] = resolution
+}
+
+// CheckEditedFiles returns an *EditedFilesError for the home files the environments of a run
+// would overwrite although the user edited them, and no resolution was set for
+func (ie *InstallationEngine) CheckEditedFiles(run []parser.Environment) error {
+	var edited []EditedFile
+	seen := make(map[string]bool)
+	for _, env := range run {
+		for _, file := range ie.editedFiles(env) {
+			if !seen[file.File] {
+				seen[file.File] = true
+				edited = append(edited, file)
+			}
+		}
+	}
+	return ie.unresolvedEdits(edited)
+}
+
+// unresolvedEdits returns an *EditedFilesError for the edited files without a resolution
+func (ie *InstallationEngine) unresolvedEdits(edited []EditedFile) error {
+	var unresolved []EditedFile
+	for _, file := range edited {
+		if _, ok := ie.editResolutions[file.File]; !ok {
+			unresolved = append(unresolved, file)
+		}
+	}
+	if len(unresolved) > 0 {
+		return &EditedFilesError{Files: unresolved}
+	}
+	return nil
+}
+
+// writtenFiles returns the home files the setup script of an environment writes over: its config
+// files, unless it merges them as blocks, another environment owns them or they are merged
+func (ie *InstallationEngine) writtenFiles(env parser.Environment) []string {
+	if env.MergeRCFiles {
+		return nil
+	}
+	var files []string
+	for _, name := range parser.HomeFiles([]parser.Environment{env}) {
+		if owner, ok := ie.fileOwners[name]; !ok || owner == env.Name {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// editedFiles returns the files the environment writes that differ from how they were last applied
+func (ie *InstallationEngine) editedFiles(env parser.Environment) []EditedFile {
+	if ie.managedFiles == nil {
+		return nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var edited []EditedFile
+	for _, name := range ie.writtenFiles(env) {
+		record, base, ok := ie.managedFiles.GetManagedFile(name)
+		if !ok {
+			continue
+		}
+		mine, err := os.ReadFile(filepath.Join(home, name))
+		if err != nil || config.HashContent(mine) == record.Hash {
+			continue
+		}
+		file := EditedFile{File: name, Environment: env.Name, AppliedBy: record.Environment, Base: base, Mine: mine}
+		for _, path := range env.ConfigFiles {
+			if filepath.Base(path) == name {
+				file.Repo, _ = ie.githubClient.GetRepositoryContents(path)
+			}
+		}
+		edited = append(edited, file)
+	}
+	return edited
+}
+
+// settleEditedFiles applies the resolution of each edited file once the setup script wrote the
+// repository's version over it, and returns a note for each file
+func (ie *InstallationEngine) settleEditedFiles(edited []EditedFile) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, file := range edited {
+		resolution := ie.editResolutions[file.File]
+		delete(ie.editResolutions, file.File)
+		path := filepath.Join(home, file.File)
+		switch resolution {
+		case EditKeepMine:
+			if err := os.WriteFile(path, file.Mine, 0644); err != nil {
+				return notes, fmt.Errorf("failed to restore ~/%s: %w", file.File, err)
+			}
+			notes = append(notes, fmt.Sprintf("~/%s: your edits kept, the repository's version not applied", file.File))
+		case EditMerge:
+			repo, err := os.ReadFile(path)
+			if err != nil {
+				return notes, fmt.Errorf("failed to read ~/%s: %w", file.File, err)
+			}
+			merged, conflicts, err := ie.mergeEdits(file.Mine, file.Base, repo)
+			if err != nil {
+				return notes, fmt.Errorf("failed to merge ~/%s: %w", file.File, err)
+			}
+			if err := os.WriteFile(path, merged, 0644); err != nil {
+				return notes, fmt.Errorf("failed to merge ~/%s: %w", file.File, err)
+			}
+			if conflicts > 0 {
+				notes = append(notes, fmt.Sprintf("~/%s: merged with %d conflict(s) marked with <<<<<<< and >>>>>>>, edit the file to settle them", file.File, conflicts))
+			} else {
+				notes = append(notes, fmt.Sprintf("~/%s: your edits merged with the repository's changes", file.File))
+			}
+		default:
+			notes = append(notes, fmt.Sprintf("~/%s: your edits replaced by the repository's version", file.File))
+		}
+	}
+	return notes, nil
+}
+
+// restoreEditedFiles writes the user's version of each edited file back when the setup script failed,
+// which may have left the repository's version or a partial file over it. The resolutions are kept
+// for the next attempt.
+func (ie *InstallationEngine) restoreEditedFiles(edited []EditedFile) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, file := range edited {
+		path := filepath.Join(home, file.File)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, file.Mine) {
+			continue
+		}
+		if err := os.WriteFile(path, file.Mine, 0644); err != nil {
+			return notes, fmt.Errorf("failed to restore ~/%s: %w", file.File, err)
+		}
+		notes = append(notes, fmt.Sprintf("~/%s: setup failed, your edits restored", file.File))
+	}
+	return notes, nil
+}
+
+// mergeEdits merges the user's edits and the repository's changes since base with git merge-file,
+// returning the merged content and the number of conflicts marked in it
+func (ie *InstallationEngine) mergeEdits(mine, base, repo []byte) ([]byte, int, error) {
+	dir, err := os.MkdirTemp(ie.tempRoot, "merge-")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	paths := make([]string, 3)
+	for i, content := range [][]byte{mine, base, repo} {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%d", i))
+		if err := os.WriteFile(paths[i], content, 0600); err != nil {
+			return nil, 0, err
+		}
+	}
+	cmd := exec.Command("git", "merge-file", "-p", "-L", "yours", "-L", "last applied", "-L", "repository", paths[0], paths[1], paths[2])
+	merged, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		// The exit code is the number of conflicts
+		return merged, exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return merged, 0, nil
+}
+
+// recordWrittenFiles remembers the files an environment wrote as the setup script left them, so
+// edits made after this application are detected by the next one
+func (ie *InstallationEngine) recordWrittenFiles(env parser.Environment, written map[string][]byte) error {
+	if ie.managedFiles == nil {
+		return nil
+	}
+	for name, content := range written {
+		if err := ie.managedFiles.RecordManagedFile(name, env.Name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWrittenFiles reads the files an environment writes, as its setup script left them
+func (ie *InstallationEngine) readWrittenFiles(env parser.Environment) map[string][]byte {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	written := make(map[string][]byte)
+	for _, name := range ie.writtenFiles(env) {
+		if content, err := os.ReadFile(filepath.Join(home, name)); err == nil {
+			written[name] = content
+		}
+	}
+	return written
+}
+
+// forgetWrittenFiles drops the records of the files an environment wrote last, once it is restored
+func (ie *InstallationEngine) forgetWrittenFiles(env parser.Environment) {
+	if ie.managedFiles == nil {
+		return
+	}
+	for _, name := range ie.writtenFiles(env) {
+		if record, _, ok := ie.managedFiles.GetManagedFile(name); ok && record.Environment == env.Name {
+			ie.managedFiles.ForgetManagedFile(name)
+		}
+	}
+}
+
+// DiffLines returns the lines removed from before (prefixed with "- ") and added in after
+// (prefixed with "+ "), in file order
+func DiffLines(before, after []byte) []string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// Longest common subsequence of the lines, from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	return diff
+}
+
+// splitLines splits a file into its lines, without the final newline
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
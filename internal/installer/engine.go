@@ -1,23 +1,34 @@
 package installer
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
+	"boba/internal/config"
+	"boba/internal/github"
+	"boba/internal/log"
 	"boba/internal/parser"
 )
 
+// ErrNotUninstallable is returned when a tool has no way to be uninstalled
+var ErrNotUninstallable = errors.New("tool cannot be uninstalled")
+
+// ErrTimeout is returned when a script ran too long and was killed
+var ErrTimeout = errors.New("command timed out after 10 minutes")
+
 // Platform represents the target platform information
 type Platform struct {
 	OS             string
+	Arch           string
 	Distribution   string
 	PackageManager string
 }
@@ -29,39 +40,85 @@ type InstallationResult struct {
 	Error      error
 	ExitCode   int
 	Duration   time.Duration
+	FollowUps  []FollowUpAction // Actions the user must take after the script (reboot, re-login, new shell)
+	TempDir    string // Temp directory kept for debugging when the script failed
+	Provenance *config.ToolProvenance // How the tool was installed (set by InstallTool)
+	Steps      []StepResult // Outcome of each step of a multi-step install
+	Satisfied  bool // Nothing ran because the satisfied_when checks already held
+	DryRun     bool // Nothing ran: Output describes the script that would have run
+	DownloadBytes int64 // Script bytes fetched from the repository (set by InstallTool)
+	FileNotes  []string // Home files kept for their owner, merged or with a BOBA block updated or removed
 }
 
 // InstallationEngine handles cross-platform tool installation
 type InstallationEngine struct {
 	platform     Platform
 	githubClient GitHubClientInterface
-	tempDir      string
+	tempRoot     string // Private temp root of this engine instance
+	runDir       string // Temp directory of the current run, inside tempRoot
+	tempDir      string // Temp directory of the script currently running (the run directory between scripts)
+	retainedDirs []string // Temp directories kept after failed scripts
+	indexFresh   bool // Set once the package index has been refreshed during the current run
+	updating     bool // Set for update runs, which ignore satisfied_when checks
+	followUps    map[FollowUpAction][]string // Follow-up actions requested by scripts during the current run
+	envPolicy    EnvironmentPolicy // Controls which parent environment variables scripts inherit
+	runVars      map[string]string // Variables overriding envPolicy.Set for the current run
+	paramValues  map[string]map[string]string // Parameter values chosen by the user, by tool then parameter name
+	repoDir      string // Local clone of the configuration repository (exposed as BOBA_REPO_DIR)
+	needApproval bool // Managed mode: only operations of an approved plan run
+	approvalKeys []string // Public keys trusted to approve plans
+	fileOwners   map[string]string // Environment owning each home file several environments manage, or "merge"
+	managedFiles ManagedFileStore // Home files as environments last wrote them, to detect the user's edits
+	editResolutions map[string]string // How to settle each edited home file at its next application
+	approved     map[string]string // Script hashes of the approved plan of the current run, by kind/name
+	systemWide   bool // System-wide installs are possible: running as root or with sudo available
+	noSudo       bool // Never-use-sudo mode: user scope installs only, sudo unavailable to scripts
+	options      Options // Engine options, such as dry-run mode
+	queue        OperationQueue // Runs one operation at a time across BOBA processes, nil to run right away
+	batchEnd     func() // Ends the batch holding the turn of the queue, nil outside of a batch
 }
 
 // NewInstallationEngine creates a new installation engine instance
 func NewInstallationEngine(githubClient GitHubClientInterface) *InstallationEngine {
-	tempDir := filepath.Join(os.TempDir(), "boba-installer")
-	os.MkdirAll(tempDir, 0755)
+	tempRoot, err := newEngineTempRoot()
+	if err != nil {
+		// Fall back to a per-process directory name, still created with private permissions
+		tempRoot = filepath.Join(os.TempDir(), fmt.Sprintf("boba-installer-%d", os.Getpid()))
+		os.MkdirAll(tempRoot, 0700)
+	}
 	
-	return &InstallationEngine{
+	ie := &InstallationEngine{
 		platform:     detectPlatform(),
 		githubClient: githubClient,
-		tempDir:      tempDir,
+		tempRoot:     tempRoot,
+		systemWide:   canInstallSystemWide(),
+		options:      DefaultOptions(),
 	}
+	ie.tempDir, _ = ie.ensureRunDir()
+	return ie
 }
 
-// createBufferedScanner creates a scanner with increased buffer size to handle long lines
-func createBufferedScanner(reader interface{ Read([]byte) (int, error) }) *bufio.Scanner {
-	scanner := bufio.NewScanner(reader)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-	return scanner
+// BeginRun resets per-run state (package index freshness, pending follow-up actions, run temp directory)
+// before a new batch of installations or environment applications
+func (ie *InstallationEngine) BeginRun() {
+	ie.indexFresh = false
+	ie.updating = false
+	ie.followUps = nil
+	ie.approved = nil
+	
+	// Start a fresh run directory, removing the previous one unless it holds failed script directories
+	if ie.runDir != "" && !ie.hasRetainedDirsIn(ie.runDir) {
+		os.RemoveAll(ie.runDir)
+	}
+	ie.runDir = ""
+	ie.tempDir, _ = ie.ensureRunDir()
 }
 
 // detectPlatform determines the current platform
 func detectPlatform() Platform {
 	platform := Platform{
-		OS: runtime.GOOS,
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
 	}
 	
 	// Detect distribution and package manager for Linux
@@ -103,7 +160,8 @@ func detectLinuxDistribution() string {
 
 // detectPackageManager attempts to detect the available package manager
 func detectPackageManager() string {
-	managers := []string{"apt", "yum", "dnf", "pacman", "zypper", "apk"}
+	// Linuxbrew only when there is no system package manager
+	managers := []string{"apt", "yum", "dnf", "pacman", "zypper", "apk", "brew"}
 	
 	for _, manager := range managers {
 		if _, err := exec.LookPath(manager); err == nil {
@@ -116,9 +174,15 @@ func detectPackageManager() string {
 
 // IsToolInstalled checks if a tool is already installed on the system
 func (ie *InstallationEngine) IsToolInstalled(tool parser.Tool) bool {
+	_, found := lookupTool(tool)
+	return found
+}
+
+// lookupTool returns the path of the executable of a tool found on PATH
+func lookupTool(tool parser.Tool) (string, bool) {
 	// First, try to check if the tool name is available in PATH
-	if _, err := exec.LookPath(tool.Name); err == nil {
-		return true
+	if path, err := exec.LookPath(tool.Name); err == nil {
+		return path, true
 	}
 	
 	// Try common variations of the tool name
@@ -130,12 +194,12 @@ func (ie *InstallationEngine) IsToolInstalled(tool parser.Tool) bool {
 	}
 	
 	for _, variation := range variations {
-		if _, err := exec.LookPath(variation); err == nil {
-			return true
+		if path, err := exec.LookPath(variation); err == nil {
+			return path, true
 		}
 	}
 	
-	return false
+	return "", false
 }
 
 // InstallTool installs a tool using its install script from the repository
@@ -146,32 +210,85 @@ func (ie *InstallationEngine) InstallTool(tool parser.Tool) (*InstallationResult
 	
 	startTime := time.Now()
 	
-	// Download the install script
-	scriptContent, err := ie.githubClient.GetRepositoryContents(tool.InstallScript)
+	if err := checkRequirements(tool.Name, tool.MinBobaVersion, tool.LibVersion); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if err := checkScope(tool.Scope); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if ie.NeedsSudo(tool) {
+		err := fmt.Errorf("%s %w (scope: system)", tool.Name, ErrNeedsSudo)
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if err := ie.checkHomebrew(); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	// The scripts are downloaded once: in managed mode the bytes checked against the approved
+	// plan are the bytes executed
+	scripts := ie.installScriptsOnce(tool)
+	hash := func() (string, error) {
+		content, steps, err := scripts()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the install script of %s: %w", tool.Name, err)
+		}
+		return toolScriptHash(content, steps), nil
+	}
+	if err := ie.checkApproved(config.PlanEntryTool, tool.Name, hash); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	// A dry run describes the install without running the satisfied_when check either
+	if ie.options.DryRun {
+		return ie.dryRunResult("install", tool.Name, startTime, func() (*DryRunPlan, error) { return ie.DryRunTool(tool) })
+	}
+	
+	// Nothing to do when the tool is already in place
+	if ie.isSatisfied(tool.SatisfiedWhen, ie.toolEnvironment(tool)) {
+		return &InstallationResult{Success: true, Satisfied: true, Output: SatisfiedOutput, Duration: time.Since(startTime)}, nil
+	}
+
+	// One operation at a time across the BOBA processes of this machine
+	end, err := ie.beginOperation("install " + tool.Name)
 	if err != nil {
-		return &InstallationResult{
-			Success:  false,
-			Error:    fmt.Errorf("failed to download install script: %w", err),
-			Duration: time.Since(startTime),
-		}, err
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
 	}
+	defer end()
 	
-	// Create a temporary script file
-	scriptPath := filepath.Join(ie.tempDir, fmt.Sprintf("install_%s.sh", tool.FolderName))
-	if err := os.WriteFile(scriptPath, scriptContent, 0755); err != nil {
+	// Download the install script (or use the inline one), or the scripts of every install step
+	scriptContent, stepScripts, err := scripts()
+	if err != nil {
 		return &InstallationResult{
 			Success:  false,
-			Error:    fmt.Errorf("failed to create script file: %w", err),
+			Error:    fmt.Errorf("failed to download install script: %w", err),
 			Duration: time.Since(startTime),
 		}, err
 	}
 	
-	// Ensure cleanup
-	defer os.Remove(scriptPath)
+	provenance := ie.newProvenance(tool, scriptContent)
+	executablesBefore := pathExecutables()
+	filesBefore := snapshotFiles(tool.TrackDirs)
 	
-	// Execute the script with security measures
-	result := ie.executeScriptSecurely(scriptPath, tool.Name)
+	// Execute the script (or the matching steps) with security measures in its own temp directory
+	log.Info("Installing tool", "tool", tool.Name, "steps", len(tool.Steps))
+	var result *InstallationResult
+	if len(tool.Steps) > 0 {
+		result = ie.runSteps(tool, stepScripts)
+	} else {
+		result = ie.runScriptInTempDir("install", tool.FolderName, scriptContent, func(scriptPath string) *InstallationResult {
+			return ie.executeScriptSecurely(scriptPath, tool)
+		})
+	}
 	result.Duration = time.Since(startTime)
+	result.DownloadBytes = downloadedBytes(tool, scriptContent, stepScripts)
+	logResult("Tool install", tool.Name, result)
+	
+	if result.Success {
+		provenance.BinaryPaths = createdFiles(executablesBefore, pathExecutables())
+		if len(tool.TrackDirs) > 0 {
+			provenance.CreatedFiles = createdFiles(filesBefore, snapshotFiles(tool.TrackDirs))
+		}
+		result.Provenance = provenance
+	}
 	
 	return result, result.Error
 }
@@ -184,38 +301,165 @@ func (ie *InstallationEngine) UninstallTool(tool parser.Tool) (*InstallationResu
 	
 	startTime := time.Now()
 	
-	// Download the uninstall script
-	scriptContent, err := ie.githubClient.GetRepositoryContents(tool.UninstallScript)
+	if !tool.CanUninstall() {
+		err := fmt.Errorf("%s: %w", tool.Name, ErrNotUninstallable)
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if !tool.HasUninstall() {
+		err := fmt.Errorf("%s has no uninstall script: %w", tool.Name, ErrNotUninstallable)
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	if err := checkRequirements(tool.Name, tool.MinBobaVersion, tool.LibVersion); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if ie.needApproval {
+		err := fmt.Errorf("uninstall %s: %w (this machine only runs plans approved by an admin)", tool.Name, ErrNotApproved)
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	// Download the uninstall script (or use the inline one)
+	scriptContent, err := ie.toolScriptContent(tool, tool.UninstallInline, tool.UninstallScript)
 	if err != nil {
+		if github.IsNotFound(err) || errors.Is(err, os.ErrNotExist) {
+			err = fmt.Errorf("%s has no uninstall script: %w", tool.Name, ErrNotUninstallable)
+			return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+		}
 		return &InstallationResult{
 			Success:  false,
 			Error:    fmt.Errorf("failed to download uninstall script: %w", err),
 			Duration: time.Since(startTime),
 		}, err
 	}
-	
-	// Create a temporary script file
-	scriptPath := filepath.Join(ie.tempDir, fmt.Sprintf("uninstall_%s.sh", tool.FolderName))
-	if err := os.WriteFile(scriptPath, scriptContent, 0755); err != nil {
-		return &InstallationResult{
-			Success:  false,
-			Error:    fmt.Errorf("failed to create script file: %w", err),
-			Duration: time.Since(startTime),
-		}, err
+	if ie.options.DryRun {
+		source := scriptSourceName(tool.UninstallInline, tool.UninstallScript)
+		return ie.dryRunResult("uninstall", tool.Name, startTime, func() (*DryRunPlan, error) { return ie.dryRunToolScript(tool, scriptContent, source) })
+	}
+
+	// One operation at a time across the BOBA processes of this machine
+	end, err := ie.beginOperation("uninstall " + tool.Name)
+	if err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	defer end()
+	
+	// Execute the script with security measures in its own temp directory
+	result := ie.runScriptInTempDir("uninstall", tool.FolderName, scriptContent, func(scriptPath string) *InstallationResult {
+		return ie.executeScriptSecurely(scriptPath, tool)
+	})
+	if result.Success {
+		// Blocks the install script added with boba_rc_block go with the tool
+		notes, err := removeRCBlocks(rcBlockTool, tool.Name, config.ShellRCFiles)
+		result.FileNotes = notes
+		if err != nil {
+			result.Success = false
+			result.Error = err
+		}
 	}
-	
-	// Ensure cleanup
-	defer os.Remove(scriptPath)
-	
-	// Execute the script with security measures
-	result := ie.executeScriptSecurely(scriptPath, tool.Name)
 	result.Duration = time.Since(startTime)
 	
 	return result, result.Error
 }
 
+// installScripts returns the install script of a tool, or the scripts of its install steps
+// along with their concatenation
+func (ie *InstallationEngine) installScripts(tool parser.Tool) (content []byte, steps [][]byte, err error) {
+	if len(tool.Steps) == 0 {
+		content, err = ie.toolScriptContent(tool, tool.InstallInline, tool.InstallScript)
+		return content, nil, err
+	}
+	steps, err = ie.stepScripts(tool)
+	return bytes.Join(steps, []byte("\n")), steps, err
+}
+
+// installScriptsOnce returns a function downloading the install scripts of a tool on its first
+// call and returning the same bytes on every later call
+func (ie *InstallationEngine) installScriptsOnce(tool parser.Tool) func() ([]byte, [][]byte, error) {
+	var once sync.Once
+	var content []byte
+	var steps [][]byte
+	var err error
+	return func() ([]byte, [][]byte, error) {
+		once.Do(func() { content, steps, err = ie.installScripts(tool) })
+		return content, steps, err
+	}
+}
+
+// downloadedBytes returns the size of the install scripts fetched from the repository; inline
+// scripts come with the manifest and are not counted
+func downloadedBytes(tool parser.Tool, content []byte, steps [][]byte) int64 {
+	if len(tool.Steps) == 0 {
+		if tool.InstallInline != "" {
+			return 0
+		}
+		return int64(len(content))
+	}
+	
+	var total int64
+	for i, step := range tool.Steps {
+		if step.Run == "" && i < len(steps) {
+			total += int64(len(steps[i]))
+		}
+	}
+	return total
+}
+
+// scriptContent returns the inline script when set, otherwise downloads the script from the repository
+func (ie *InstallationEngine) scriptContent(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	return ie.githubClient.GetRepositoryContents(path)
+}
+
+// toolScriptContent returns a tool script, read from the tool's pinned source repository
+// unless the script is inline
+func (ie *InstallationEngine) toolScriptContent(tool parser.Tool, inline, path string) ([]byte, error) {
+	if inline != "" || tool.ScriptSource == nil {
+		return ie.scriptContent(inline, path)
+	}
+	source := tool.ScriptSource
+	if reader, ok := ie.githubClient.(PinnedContentReader); ok {
+		return reader.GetContentsAt(source.Owner, source.Repo, path, source.Ref)
+	}
+	return github.GetPublicContentsAt(source.Owner, source.Repo, path, source.Ref)
+}
+
+// manifestFolder returns the repository folder of a tool or environment.
+// Catalog entries (defined in boba.yaml) have no folder and use the repository root.
+func manifestFolder(section, folderName string, catalog bool) string {
+	if catalog {
+		return ""
+	}
+	return filepath.Join(section, folderName)
+}
+
 // executeScriptSecurely executes a script with proper security measures and output capture
-func (ie *InstallationEngine) executeScriptSecurely(scriptPath, toolName string) *InstallationResult {
+func (ie *InstallationEngine) executeScriptSecurely(scriptPath string, tool parser.Tool) *InstallationResult {
+	toolName := tool.Name
+	folder := manifestFolder("tools", tool.FolderName, tool.Catalog)
+	
+	// Stage the whole manifest folder so scripts can use bundled companion files
+	assetsDir, err := ie.stageAssets(folder)
+	if err != nil {
+		return &InstallationResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if assetsDir != "" {
+		defer os.RemoveAll(assetsDir)
+	}
+	
+	// Resolve the working directory requested by the manifest
+	workingDir, err := ie.resolveWorkingDir(tool.WorkingDir, folder, assetsDir)
+	if err != nil {
+		return &InstallationResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	
 	// Create context with timeout (10 minutes max per installation)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
@@ -224,127 +468,93 @@ func (ie *InstallationEngine) executeScriptSecurely(scriptPath, toolName string)
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
 		// On Windows, use PowerShell or cmd to execute scripts
-		cmd = exec.CommandContext(ctx, "powershell", "-ExecutionPolicy", "Bypass", "-File", scriptPath)
+		cmd = exec.CommandContext(ctx, "powershell", append([]string{"-ExecutionPolicy", "Bypass", "-File", scriptPath}, ie.parameterArgs(tool)...)...)
 	} else {
 		// On Unix-like systems, use bash
-		cmd = exec.CommandContext(ctx, "/bin/bash", scriptPath)
+		cmd = exec.CommandContext(ctx, "/bin/bash", append([]string{scriptPath}, ie.parameterArgs(tool)...)...)
 	}
 	
+	// Scripts can request follow-up actions by writing to this marker file
+	followUpPath := scriptPath + ".followup"
+	os.Remove(followUpPath)
+	defer os.Remove(followUpPath)
+	
 	// Set up environment variables
-	cmd.Env = append(os.Environ(),
+	cmd.Env = ie.scriptEnvironment(append(append([]string{
 		fmt.Sprintf("BOBA_TOOL_NAME=%s", toolName),
-		fmt.Sprintf("BOBA_PLATFORM=%s", ie.platform.OS),
-		fmt.Sprintf("BOBA_PACKAGE_MANAGER=%s", ie.platform.PackageManager),
-		fmt.Sprintf("BOBA_TEMP_DIR=%s", ie.tempDir),
-		fmt.Sprintf("TMPDIR=%s", ie.tempDir),
-		fmt.Sprintf("TEMP=%s", ie.tempDir),
-		fmt.Sprintf("TMP=%s", ie.tempDir),
-	)
-	
-	// Set working directory to temp directory
-	cmd.Dir = ie.tempDir
-	
-	// Create pipes for stdout and stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return &InstallationResult{
-			Success: false,
-			Error:   fmt.Errorf("failed to create stdout pipe: %w", err),
-		}
-	}
+		fmt.Sprintf("BOBA_FOLLOWUP_FILE=%s", followUpPath),
+	}, ie.repositoryEnvironment(folder, assetsDir)...), append(ie.scopeEnvironment(tool), ie.parameterEnvironment(tool)...)...)...)
 	
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return &InstallationResult{
-			Success: false,
-			Error:   fmt.Errorf("failed to create stderr pipe: %w", err),
-		}
-	}
+	// Set working directory (temp directory unless the manifest requests otherwise)
+	cmd.Dir = workingDir
 	
-	// Start the command
-	if err := cmd.Start(); err != nil {
+	// Run the script, capturing its output
+	output, exitCode, err := runScriptCommand(ctx, cmd)
+	if err != nil && !errors.Is(err, ErrTimeout) {
 		return &InstallationResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to start script: %w", err),
 		}
 	}
 	
-	// Capture output
-	var outputBuilder strings.Builder
-	
-	// Use channels to synchronize goroutines
-	stdoutDone := make(chan bool)
-	stderrDone := make(chan bool)
-	
-	// Read stdout and stderr concurrently (capture silently)
-	go func() {
-		scanner := createBufferedScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Capture for result (no real-time printing)
-			outputBuilder.WriteString(line + "\n")
-		}
-		stdoutDone <- true
-	}()
-	
-	go func() {
-		scanner := createBufferedScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Capture for result (no real-time printing)
-			outputBuilder.WriteString("STDERR: " + line + "\n")
-		}
-		stderrDone <- true
-	}()
-	
-	// Create a done channel to signal completion
-	cmdDone := make(chan error, 1)
-	go func() {
-		cmdDone <- cmd.Wait()
-	}()
-	
-	// Wait for either completion or timeout
-	select {
-	case err = <-cmdDone:
-		// Command completed normally
-	case <-ctx.Done():
-		// Timeout occurred
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		err = fmt.Errorf("command timed out after 10 minutes")
-	}
-	
-	// Wait for output readers to finish
-	<-stdoutDone
-	<-stderrDone
-	
-	// Get exit code
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				exitCode = status.ExitStatus()
-			}
-		}
-	}
+	// Collect follow-up actions signaled via marker file or exit code
+	followUps, exitCode := collectFollowUps(followUpPath, exitCode)
+	ie.recordFollowUps(toolName, followUps)
 	
-	output := outputBuilder.String()
-	success := exitCode == 0
+	success := exitCode == 0 && !errors.Is(err, ErrTimeout)
 	
 	result := &InstallationResult{
-		Success:  success,
-		Output:   output,
-		ExitCode: exitCode,
+		Success:   success,
+		Output:    output,
+		ExitCode:  exitCode,
+		FollowUps: followUps,
 	}
 	
-	if !success {
+	if errors.Is(err, ErrTimeout) {
+		result.Error = ErrTimeout
+	} else if !success {
 		result.Error = fmt.Errorf("script execution failed with exit code %d", exitCode)
 	}
 	
 	return result
 }
 
+// scriptEnvironment builds the environment passed to install and environment scripts
+func (ie *InstallationEngine) scriptEnvironment(extra ...string) []string {
+	indexFresh := "0"
+	if ie.indexFresh {
+		indexFresh = "1"
+	}
+	
+	env := append(ie.inheritedEnvironment(), extra...)
+	env = append(env,
+		fmt.Sprintf("BOBA_API_VERSION=%d", APIVersion),
+		fmt.Sprintf("BOBA_PLATFORM=%s", ie.platform.OS),
+		fmt.Sprintf("BOBA_PACKAGE_MANAGER=%s", ie.platform.PackageManager),
+		fmt.Sprintf("BOBA_INDEX_FRESH=%s", indexFresh),
+		fmt.Sprintf("BOBA_TEMP_DIR=%s", ie.tempDir),
+		fmt.Sprintf("TMPDIR=%s", ie.tempDir),
+		fmt.Sprintf("TEMP=%s", ie.tempDir),
+		fmt.Sprintf("TMP=%s", ie.tempDir),
+	)
+	if helperPath := ie.ensureVerifyHelper(); helperPath != "" {
+		env = append(env, fmt.Sprintf("BOBA_VERIFY=%s", helperPath))
+	}
+	if libPath := ie.ensureScriptLibrary(); libPath != "" {
+		env = append(env,
+			fmt.Sprintf("BOBA_LIB=%s", libPath),
+			fmt.Sprintf("BOBA_LIB_VERSION=%d", ScriptLibraryVersion),
+		)
+	}
+	if ie.platform.PackageManager == "brew" {
+		env = homebrewEnvironment(env)
+	}
+	if ie.noSudo {
+		env = ie.noSudoEnvironment(env)
+	}
+	return env
+}
+
 // ExecuteCommand executes a shell command and returns the output
 func (ie *InstallationEngine) ExecuteCommand(command string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -398,8 +608,13 @@ func (ie *InstallationEngine) ApplyEnvironment(env parser.Environment) (*Install
 	
 	startTime := time.Now()
 	
-	// Download the setup script
-	scriptContent, err := ie.githubClient.GetRepositoryContents(env.SetupScript)
+	if err := checkRequirements(env.Name, env.MinBobaVersion, env.LibVersion); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	// Download the setup script (or use the inline one) once: in managed mode the bytes checked
+	// against the approved plan are the bytes executed
+	scriptContent, err := ie.scriptContent(env.SetupInline, env.SetupScript)
 	if err != nil {
 		return &InstallationResult{
 			Success:  false,
@@ -407,25 +622,154 @@ func (ie *InstallationEngine) ApplyEnvironment(env parser.Environment) (*Install
 			Duration: time.Since(startTime),
 		}, err
 	}
+	hash := func() (string, error) { return ie.environmentHash(env, scriptContent) }
+	if err := ie.checkApproved(config.PlanEntryEnvironment, env.Name, hash); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	if ie.options.DryRun {
+		source := scriptSourceName(env.SetupInline, env.SetupScript)
+		return ie.dryRunResult("apply", env.Name, startTime, func() (*DryRunPlan, error) { return ie.dryRunEnvironment(env, scriptContent, source) })
+	}
+
+	// One operation at a time across the BOBA processes of this machine
+	end, err := ie.beginOperation("apply " + env.Name)
+	if err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	defer end()
+	
+	// Execute the setup script with security measures in its own temp directory
+	// Never overwrite a file the user edited since it was last applied without asking first
+	edited := ie.editedFiles(env)
+	if err := ie.unresolvedEdits(edited); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	log.Info("Applying environment", "environment", env.Name)
+	resolvedFiles := ie.snapshotResolvedFiles(env)
+	result := ie.runScriptInTempDir("setup", env.FolderName, scriptContent, func(scriptPath string) *InstallationResult {
+		return ie.executeEnvironmentScriptSecurely(scriptPath, env.Name, env)
+	})
+	settled := false
+	if result.Success {
+		// Files another environment owns get their content back, merged files keep both
+		notes, err := resolveFiles(env.Name, resolvedFiles)
+		result.FileNotes = notes
+		if err == nil && env.MergeRCFiles {
+			notes, err = ie.mergeRCFiles(env)
+			result.FileNotes = append(result.FileNotes, notes...)
+		}
+		if err == nil {
+			// Remember the repository's version, the base of the next three-way merge
+			settled = true
+			written := ie.readWrittenFiles(env)
+			notes, err = ie.settleEditedFiles(edited)
+			result.FileNotes = append(result.FileNotes, notes...)
+			if err == nil {
+				err = ie.recordWrittenFiles(env, written)
+			}
+		}
+		if err != nil {
+			result.Success = false
+			result.Error = err
+		}
+	}
+	if !settled {
+		// The edits were never settled: don't leave them lost under what the script wrote
+		notes, err := ie.restoreEditedFiles(edited)
+		result.FileNotes = append(result.FileNotes, notes...)
+		if err != nil {
+			log.Warn("Failed to restore edited files", "environment", env.Name, "error", err)
+		}
+	}
+	result.Duration = time.Since(startTime)
+	logResult("Environment setup", env.Name, result)
 	
-	// Create a temporary script file
-	scriptPath := filepath.Join(ie.tempDir, fmt.Sprintf("setup_%s.sh", env.FolderName))
-	if err := os.WriteFile(scriptPath, scriptContent, 0755); err != nil {
-		return &InstallationResult{
-			Success:  false,
-			Error:    fmt.Errorf("failed to create setup script file: %w", err),
-			Duration: time.Since(startTime),
-		}, err
+	return result, result.Error
+}
+
+// logResult logs the outcome of a script run, with the script output of a failure for investigating
+// it later. The callers show failures to the user, so they are not repeated on the console.
+func logResult(what, name string, result *InstallationResult) {
+	if result.Success {
+		log.Info(what+" succeeded", "name", name, "duration", result.Duration)
+		return
+	}
+	log.Info(what+" failed", "name", name, "duration", result.Duration, "error", result.Error)
+	log.Debug("Script output", "name", name, "output", result.Output)
+}
+
+// RepositoryFile is a script or config file of a tool or environment, as fetched from the repository
+type RepositoryFile struct {
+	Path    string
+	Content []byte
+	Err     error
+}
+
+// EnvironmentFiles fetches the setup script and config files of an environment so they can be
+// reviewed before applying it. A file that cannot be fetched is returned with its error.
+func (ie *InstallationEngine) EnvironmentFiles(env parser.Environment) ([]RepositoryFile, error) {
+	if ie.githubClient == nil {
+		return nil, fmt.Errorf("GitHub client not initialized")
 	}
 	
-	// Ensure cleanup
-	defer os.Remove(scriptPath)
+	setup := RepositoryFile{Path: setupFilePath(env)}
+	setup.Content, setup.Err = ie.scriptContent(env.SetupInline, env.SetupScript)
+	return append([]RepositoryFile{setup}, ie.configFiles(env)...), nil
+}
+
+// setupFilePath names the setup script of an environment in its list of files
+func setupFilePath(env parser.Environment) string {
+	if env.SetupInline != "" {
+		return "setup (inline in manifest)"
+	}
+	return env.SetupScript
+}
+
+// configFiles fetches the config files of an environment
+func (ie *InstallationEngine) configFiles(env parser.Environment) []RepositoryFile {
+	var files []RepositoryFile
+	for _, path := range env.ConfigFiles {
+		content, err := ie.githubClient.GetRepositoryContents(path)
+		files = append(files, RepositoryFile{Path: path, Content: content, Err: err})
+	}
+	return files
+}
+
+// ToolScripts fetches the install script (or the script of each install step) of a tool, and its
+// uninstall script when it has one, from the tool's pinned source repository when set
+func (ie *InstallationEngine) ToolScripts(tool parser.Tool) ([]RepositoryFile, error) {
+	if ie.githubClient == nil {
+		return nil, fmt.Errorf("GitHub client not initialized")
+	}
 	
-	// Execute the setup script with security measures
-	result := ie.executeEnvironmentScriptSecurely(scriptPath, env.Name, env)
-	result.Duration = time.Since(startTime)
+	var files []RepositoryFile
+	for i, step := range tool.Steps {
+		file := RepositoryFile{Path: fmt.Sprintf("%s (%s)", step.Label(i), step.ScriptPath)}
+		if step.Run != "" {
+			file.Path = fmt.Sprintf("%s (inline in tool.yaml)", step.Label(i))
+		}
+		file.Content, file.Err = ie.toolScriptContent(tool, step.Run, step.ScriptPath)
+		files = append(files, file)
+	}
+	if len(tool.Steps) == 0 {
+		install := RepositoryFile{Path: tool.InstallScript}
+		if tool.InstallInline != "" {
+			install.Path = "install (inline in tool.yaml)"
+		}
+		install.Content, install.Err = ie.toolScriptContent(tool, tool.InstallInline, tool.InstallScript)
+		files = append(files, install)
+	}
 	
-	return result, result.Error
+	uninstall := RepositoryFile{Path: tool.UninstallScript}
+	if tool.UninstallInline != "" {
+		uninstall.Path = "uninstall (inline in tool.yaml)"
+	}
+	uninstall.Content, uninstall.Err = ie.toolScriptContent(tool, tool.UninstallInline, tool.UninstallScript)
+	if uninstall.Err == nil {
+		files = append(files, uninstall)
+	}
+	return files, nil
 }
 
 // RestoreEnvironment restores an environment configuration using its restore script
@@ -436,8 +780,12 @@ func (ie *InstallationEngine) RestoreEnvironment(env parser.Environment) (*Insta
 	
 	startTime := time.Now()
 	
-	// Download the restore script
-	scriptContent, err := ie.githubClient.GetRepositoryContents(env.RestoreScript)
+	if err := checkRequirements(env.Name, env.MinBobaVersion, env.LibVersion); err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	
+	// Download the restore script (or use the inline one)
+	scriptContent, err := ie.scriptContent(env.RestoreInline, env.RestoreScript)
 	if err != nil {
 		return &InstallationResult{
 			Success:  false,
@@ -445,22 +793,34 @@ func (ie *InstallationEngine) RestoreEnvironment(env parser.Environment) (*Insta
 			Duration: time.Since(startTime),
 		}, err
 	}
-	
-	// Create a temporary script file
-	scriptPath := filepath.Join(ie.tempDir, fmt.Sprintf("restore_%s.sh", env.FolderName))
-	if err := os.WriteFile(scriptPath, scriptContent, 0755); err != nil {
-		return &InstallationResult{
-			Success:  false,
-			Error:    fmt.Errorf("failed to create restore script file: %w", err),
-			Duration: time.Since(startTime),
-		}, err
+	if ie.options.DryRun {
+		source := scriptSourceName(env.RestoreInline, env.RestoreScript)
+		return ie.dryRunResult("restore", env.Name, startTime, func() (*DryRunPlan, error) { return ie.dryRunEnvironment(env, scriptContent, source) })
+	}
+
+	// One operation at a time across the BOBA processes of this machine
+	end, err := ie.beginOperation("restore " + env.Name)
+	if err != nil {
+		return &InstallationResult{Success: false, Error: err, Duration: time.Since(startTime)}, err
+	}
+	defer end()
+	
+	// Execute the restore script with security measures in its own temp directory
+	result := ie.runScriptInTempDir("restore", env.FolderName, scriptContent, func(scriptPath string) *InstallationResult {
+		return ie.executeEnvironmentScriptSecurely(scriptPath, env.Name, env)
+	})
+	if result.Success {
+		ie.forgetWrittenFiles(env)
+	}
+	if result.Success && env.MergeRCFiles {
+		// The rest of the files was never the environment's, only its blocks are removed
+		notes, err := removeRCBlocks(rcBlockEnvironment, env.Name, parser.HomeFiles([]parser.Environment{env}))
+		result.FileNotes = notes
+		if err != nil {
+			result.Success = false
+			result.Error = err
+		}
 	}
-	
-	// Ensure cleanup
-	defer os.Remove(scriptPath)
-	
-	// Execute the restore script with security measures
-	result := ie.executeEnvironmentScriptSecurely(scriptPath, env.Name, env)
 	result.Duration = time.Since(startTime)
 	
 	return result, result.Error
@@ -468,6 +828,29 @@ func (ie *InstallationEngine) RestoreEnvironment(env parser.Environment) (*Insta
 
 // executeEnvironmentScriptSecurely executes an environment script with proper security measures and environment-specific variables
 func (ie *InstallationEngine) executeEnvironmentScriptSecurely(scriptPath, envName string, env parser.Environment) *InstallationResult {
+	folder := manifestFolder("environments", env.FolderName, env.Catalog)
+	
+	// Stage the whole manifest folder so scripts can use bundled companion files
+	assetsDir, err := ie.stageAssets(folder)
+	if err != nil {
+		return &InstallationResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	if assetsDir != "" {
+		defer os.RemoveAll(assetsDir)
+	}
+	
+	// Resolve the working directory requested by the manifest
+	workingDir, err := ie.resolveWorkingDir(env.WorkingDir, folder, assetsDir)
+	if err != nil {
+		return &InstallationResult{
+			Success: false,
+			Error:   err,
+		}
+	}
+	
 	// Create context with timeout (10 minutes max per environment setup)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
@@ -478,124 +861,75 @@ func (ie *InstallationEngine) executeEnvironmentScriptSecurely(scriptPath, envNa
 		// On Windows, use PowerShell or cmd to execute scripts
 		cmd = exec.CommandContext(ctx, "powershell", "-ExecutionPolicy", "Bypass", "-File", scriptPath)
 	} else {
-		// On Unix-like systems, use bash
-		cmd = exec.CommandContext(ctx, "/bin/bash", scriptPath)
+		// On Unix-like systems, use bash (optionally as a login/interactive shell)
+		cmd = exec.CommandContext(ctx, "/bin/bash", environmentShellArgs(scriptPath, env)...)
 	}
 	
+	// Scripts can request follow-up actions by writing to this marker file
+	followUpPath := scriptPath + ".followup"
+	os.Remove(followUpPath)
+	defer os.Remove(followUpPath)
+	
 	// Set up environment variables
-	cmd.Env = append(os.Environ(),
+	cmd.Env = ie.scriptEnvironment(append([]string{
 		fmt.Sprintf("BOBA_ENV_NAME=%s", envName),
 		fmt.Sprintf("BOBA_ENV_SHELL=%s", env.Shell),
-		fmt.Sprintf("BOBA_PLATFORM=%s", ie.platform.OS),
-		fmt.Sprintf("BOBA_PACKAGE_MANAGER=%s", ie.platform.PackageManager),
-		fmt.Sprintf("BOBA_TEMP_DIR=%s", ie.tempDir),
-		fmt.Sprintf("TMPDIR=%s", ie.tempDir),
-		fmt.Sprintf("TEMP=%s", ie.tempDir),
-		fmt.Sprintf("TMP=%s", ie.tempDir),
-	)
-	
-	// Set working directory to temp directory
-	cmd.Dir = ie.tempDir
-	
-	// Create pipes for stdout and stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return &InstallationResult{
-			Success: false,
-			Error:   fmt.Errorf("failed to create stdout pipe: %w", err),
-		}
-	}
+		fmt.Sprintf("BOBA_FOLLOWUP_FILE=%s", followUpPath),
+	}, ie.repositoryEnvironment(folder, assetsDir)...)...)
 	
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return &InstallationResult{
-			Success: false,
-			Error:   fmt.Errorf("failed to create stderr pipe: %w", err),
-		}
-	}
+	// Set working directory (temp directory unless the manifest requests otherwise)
+	cmd.Dir = workingDir
 	
-	// Start the command
-	if err := cmd.Start(); err != nil {
+	// Run the script, capturing its output
+	output, exitCode, err := runScriptCommand(ctx, cmd)
+	if err != nil && !errors.Is(err, ErrTimeout) {
 		return &InstallationResult{
 			Success: false,
 			Error:   fmt.Errorf("failed to start environment script: %w", err),
 		}
 	}
 	
-	// Capture output
-	var outputBuilder strings.Builder
+	// Collect follow-up actions signaled via marker file or exit code
+	followUps, exitCode := collectFollowUps(followUpPath, exitCode)
+	ie.recordFollowUps(envName, followUps)
 	
-	// Use channels to synchronize goroutines
-	stdoutDone := make(chan bool)
-	stderrDone := make(chan bool)
+	success := exitCode == 0 && !errors.Is(err, ErrTimeout)
 	
-	// Read stdout and stderr concurrently (capture silently)
-	go func() {
-		scanner := createBufferedScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Capture for result (no real-time printing)
-			outputBuilder.WriteString(line + "\n")
-		}
-		stdoutDone <- true
-	}()
-	
-	go func() {
-		scanner := createBufferedScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Capture for result (no real-time printing)
-			outputBuilder.WriteString("STDERR: " + line + "\n")
-		}
-		stderrDone <- true
-	}()
-	
-	// Create a done channel to signal completion
-	cmdDone := make(chan error, 1)
-	go func() {
-		cmdDone <- cmd.Wait()
-	}()
-	
-	// Wait for either completion or timeout
-	select {
-	case err = <-cmdDone:
-		// Command completed normally
-	case <-ctx.Done():
-		// Timeout occurred
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		err = fmt.Errorf("command timed out after 10 minutes")
+	result := &InstallationResult{
+		Success:   success,
+		Output:    output,
+		ExitCode:  exitCode,
+		FollowUps: followUps,
 	}
 	
-	// Wait for output readers to finish
-	<-stdoutDone
-	<-stderrDone
-	
-	// Get exit code
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				exitCode = status.ExitStatus()
-			}
-		}
+	if errors.Is(err, ErrTimeout) {
+		result.Error = ErrTimeout
+	} else if !success {
+		result.Error = fmt.Errorf("environment script execution failed with exit code %d", exitCode)
 	}
 	
-	output := outputBuilder.String()
-	success := exitCode == 0
-	
-	result := &InstallationResult{
-		Success:  success,
-		Output:   output,
-		ExitCode: exitCode,
+	return result
+}
+
+// environmentShellArgs returns the bash arguments used to run an environment script.
+// Environments requesting a login or interactive shell source the script through
+// `bash -lc` (and -i) so PATH entries and functions from profile/rc files are available.
+func environmentShellArgs(scriptPath string, env parser.Environment) []string {
+	if !env.LoginShell && !env.Interactive {
+		return []string{scriptPath}
 	}
 	
-	if !success {
-		result.Error = fmt.Errorf("environment script execution failed with exit code %d", exitCode)
+	flags := "-"
+	if env.LoginShell {
+		flags += "l"
 	}
+	if env.Interactive {
+		flags += "i"
+	}
+	flags += "c"
 	
-	return result
+	// $0 is set to the script path so the sourced script sees its own location
+	return []string{flags, `source "$0"`, scriptPath}
 }
 
 // IsEnvironmentApplied checks if an environment is already applied (placeholder implementation)
@@ -621,7 +955,41 @@ func (ie *InstallationEngine) VerifyEnvironmentApplication(env parser.Environmen
 	return true, fmt.Sprintf("Environment '%s' appears to be applied successfully", env.Name)
 }
 
-// Cleanup removes temporary files and directories
+// Cleanup removes temporary files and directories, keeping the directories of failed scripts for debugging
 func (ie *InstallationEngine) Cleanup() error {
-	return os.RemoveAll(ie.tempDir)
+	if len(ie.retainedDirs) == 0 {
+		return os.RemoveAll(ie.tempRoot)
+	}
+	
+	// Remove everything except the retained directories and their parents
+	return filepath.Walk(ie.tempRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == ie.tempRoot {
+			return err
+		}
+		for _, dir := range ie.retainedDirs {
+			if path == dir {
+				return filepath.SkipDir
+			}
+		}
+		if ie.hasRetainedDirsIn(path) {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// hasRetainedDirsIn reports whether dir is a retained directory or contains one
+func (ie *InstallationEngine) hasRetainedDirsIn(dir string) bool {
+	for _, retained := range ie.retainedDirs {
+		if retained == dir || strings.HasPrefix(retained, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
 }
\ No newline at end of file
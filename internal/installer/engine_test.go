@@ -1,13 +1,26 @@
 package installer
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"boba/internal/config"
 	"boba/internal/parser"
+	"boba/internal/version"
 )
 
 // MockGitHubClient for testing
@@ -376,4 +389,1439 @@ func TestScriptExecutionTimeout(t *testing.T) {
 	if !result.Success {
 		t.Errorf("Expected successful installation, got error: %v", result.Error)
 	}
-}
\ No newline at end of file
+}
+func TestPackageIndexRefreshCommand(t *testing.T) {
+	tests := map[string]string{
+		"apt":     "apt-get update",
+		"brew":    "brew update",
+		"dnf":     "dnf makecache",
+		"unknown": "",
+	}
+	
+	for manager, expected := range tests {
+		got := strings.Join(packageIndexRefreshCommand(manager), " ")
+		if got != expected {
+			t.Errorf("Expected refresh command for %s to be %q, got %q", manager, expected, got)
+		}
+	}
+}
+
+func TestPackageIndexFreshFlag(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/index-tool/install.sh": []byte("#!/bin/bash\necho \"fresh=$BOBA_INDEX_FRESH\"\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "index-tool",
+		FolderName:    "index-tool",
+		InstallScript: "tools/index-tool/install.sh",
+	}
+	
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "fresh=0") {
+		t.Errorf("Expected BOBA_INDEX_FRESH=0 before refresh, got: %s", result.Output)
+	}
+	
+	// Simulate a completed refresh for this run
+	engine.indexFresh = true
+	result, _ = engine.InstallTool(tool)
+	if !strings.Contains(result.Output, "fresh=1") {
+		t.Errorf("Expected BOBA_INDEX_FRESH=1 after refresh, got: %s", result.Output)
+	}
+	
+	// A new run resets the memoization flag
+	engine.BeginRun()
+	if engine.IsPackageIndexFresh() {
+		t.Error("Expected BeginRun to reset the package index flag")
+	}
+	
+	// A batch whose scripts don't call the package manager skips the refresh
+	engine.platform.PackageManager = "apt"
+	result, err = engine.EnsurePackageIndexFresh([]parser.Tool{tool})
+	if err != nil || !strings.Contains(result.Output, "No tool of this run uses") || engine.IsPackageIndexFresh() {
+		t.Errorf("Expected the refresh to be skipped, got %+v, %v", result, err)
+	}
+	
+	// A refresh that can't run is returned as an error, like a failed one
+	if _, found := FindHomebrew(); !found {
+		engine.platform.PackageManager = "brew"
+		result, err = engine.EnsurePackageIndexFresh([]parser.Tool{tool})
+		if !errors.Is(err, ErrHomebrewMissing) || result.Success {
+			t.Errorf("Expected the missing Homebrew to be returned, got %+v, %v", result, err)
+		}
+	}
+}
+
+func TestScriptBackgroundProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("bash scripts only")
+	}
+	
+	// A daemon started by the script doesn't hold up the install
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/daemon/install.sh": []byte("#!/bin/bash\necho started\nsleep 10 &\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	start := time.Now()
+	result, err := engine.InstallTool(parser.Tool{Name: "daemon", FolderName: "daemon", InstallScript: "tools/daemon/install.sh"})
+	if err != nil || !result.Success || !strings.Contains(result.Output, "started") {
+		t.Fatalf("Expected the install to succeed, got %+v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*scriptWaitDelay {
+		t.Errorf("Expected the install to return once the script exited, took %v", elapsed)
+	}
+	
+	// A timeout kills the children of the script too
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	output, _, err := runScriptCommand(ctx, exec.CommandContext(ctx, "/bin/bash", "-c", "sleep 30 & echo $!; wait"))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	pid := strings.TrimSpace(output)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the background process %s to be killed", pid)
+		}
+	}
+}
+
+func TestFollowUpSignaling(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/marker-tool/install.sh": []byte("#!/bin/bash\necho relogin >> \"$BOBA_FOLLOWUP_FILE\"\nexit 0\n"),
+			"tools/reboot-tool/install.sh": []byte("#!/bin/bash\necho 'needs reboot'\nexit 100\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	engine.BeginRun()
+	
+	markerTool := parser.Tool{Name: "marker-tool", FolderName: "marker-tool", InstallScript: "tools/marker-tool/install.sh"}
+	result, err := engine.InstallTool(markerTool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.FollowUps) != 1 || result.FollowUps[0] != FollowUpRelogin {
+		t.Errorf("Expected relogin follow-up from marker file, got %v", result.FollowUps)
+	}
+	
+	rebootTool := parser.Tool{Name: "reboot-tool", FolderName: "reboot-tool", InstallScript: "tools/reboot-tool/install.sh"}
+	result, err = engine.InstallTool(rebootTool)
+	if err != nil {
+		t.Fatalf("Expected reboot exit code to count as success, got %v", err)
+	}
+	if !result.Success || result.ExitCode != 0 {
+		t.Errorf("Expected success with exit code 0, got success=%v exit=%d", result.Success, result.ExitCode)
+	}
+	
+	pending := engine.PendingFollowUps()
+	if len(pending[FollowUpReboot]) != 1 || pending[FollowUpReboot][0] != "reboot-tool" {
+		t.Errorf("Expected reboot requested by reboot-tool, got %v", pending)
+	}
+	if len(pending[FollowUpRelogin]) != 1 {
+		t.Errorf("Expected relogin to be aggregated, got %v", pending)
+	}
+	
+	engine.BeginRun()
+	if len(engine.PendingFollowUps()) != 0 {
+		t.Error("Expected BeginRun to clear pending follow-ups")
+	}
+}
+
+func TestEnvironmentPolicyFiltering(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/test",
+		"LC_ALL=C",
+		"GITHUB_TOKEN=secret",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"CORP_PROXY=http://proxy",
+		"BOBA_DEBUG=1",
+	}
+	
+	minimal := EnvironmentPolicy{Minimal: true, Allow: []string{"CORP_*"}}
+	filtered := strings.Join(minimal.filterEnvironment(environ), " ")
+	for _, expected := range []string{"PATH=", "HOME=", "LC_ALL=", "CORP_PROXY=", "BOBA_DEBUG="} {
+		if !strings.Contains(filtered, expected) {
+			t.Errorf("Expected %s to be kept in minimal mode, got: %s", expected, filtered)
+		}
+	}
+	if strings.Contains(filtered, "GITHUB_TOKEN") || strings.Contains(filtered, "AWS_SECRET_ACCESS_KEY") {
+		t.Errorf("Expected secrets to be removed in minimal mode, got: %s", filtered)
+	}
+	
+	deny := EnvironmentPolicy{Deny: []string{"GITHUB_TOKEN", "AWS_*"}}
+	filtered = strings.Join(deny.filterEnvironment(environ), " ")
+	if strings.Contains(filtered, "GITHUB_TOKEN") || strings.Contains(filtered, "AWS_SECRET_ACCESS_KEY") {
+		t.Errorf("Expected denied variables to be removed, got: %s", filtered)
+	}
+	if !strings.Contains(filtered, "CORP_PROXY=") {
+		t.Errorf("Expected other variables to be inherited without minimal mode, got: %s", filtered)
+	}
+}
+
+func TestMinimalEnvironmentInScripts(t *testing.T) {
+	t.Setenv("LEAKY_TEST_TOKEN", "secret")
+	
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/env-tool/install.sh": []byte("#!/bin/bash\necho \"token=${LEAKY_TEST_TOKEN:-unset}\"\necho \"tool=$BOBA_TOOL_NAME\"\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	engine.SetEnvironmentPolicy(EnvironmentPolicy{Minimal: true})
+	
+	tool := parser.Tool{Name: "env-tool", FolderName: "env-tool", InstallScript: "tools/env-tool/install.sh"}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "token=unset") {
+		t.Errorf("Expected token to be hidden from script, got: %s", result.Output)
+	}
+	if !strings.Contains(result.Output, "tool=env-tool") {
+		t.Errorf("Expected BOBA_* variables to be passed, got: %s", result.Output)
+	}
+}
+
+func TestInjectedScriptVariables(t *testing.T) {
+	t.Setenv("NPM_REGISTRY", "https://registry.npmjs.org")
+	
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/env-tool/install.sh": []byte("#!/bin/bash\necho \"proxy=$CORP_PROXY registry=$NPM_REGISTRY\"\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	// Configured variables survive minimal mode and replace inherited values
+	engine.SetEnvironmentPolicy(EnvironmentPolicy{Minimal: true, Set: map[string]string{
+		"CORP_PROXY":   "http://proxy:3128",
+		"NPM_REGISTRY": "https://npm.corp.example",
+	}})
+	tool := parser.Tool{Name: "env-tool", FolderName: "env-tool", InstallScript: "tools/env-tool/install.sh"}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "proxy=http://proxy:3128 registry=https://npm.corp.example") {
+		t.Errorf("Expected the configured variables, got: %s", result.Output)
+	}
+	
+	// Run variables override the configured ones until cleared
+	engine.SetRunVariables(map[string]string{"CORP_PROXY": "http://other:8080"})
+	result, _ = engine.InstallTool(tool)
+	if !strings.Contains(result.Output, "proxy=http://other:8080 registry=https://npm.corp.example") {
+		t.Errorf("Expected the run variable to override the configured one, got: %s", result.Output)
+	}
+	engine.SetRunVariables(nil)
+	result, _ = engine.InstallTool(tool)
+	if !strings.Contains(result.Output, "proxy=http://proxy:3128") {
+		t.Errorf("Expected the configured variable once the run variables are cleared, got: %s", result.Output)
+	}
+}
+
+func TestToolParametersInScripts(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/java/install.sh": []byte("#!/bin/bash\necho \"env=$BOBA_PARAM_DISTRIBUTION/$BOBA_PARAM_VERSION args=$*\"\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{Name: "java", FolderName: "java", InstallScript: "tools/java/install.sh", Parameters: []parser.Parameter{
+		{Name: "distribution", Choices: []string{"temurin", "zulu"}},
+		{Name: "version", Type: parser.ParameterInt, Default: "21"},
+	}}
+	engine.SetParameterValues(map[string]map[string]string{"java": {"distribution": "zulu"}})
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "env=zulu/21 args=--distribution=zulu --version=21") {
+		t.Errorf("Expected the chosen and default values as variables and arguments, got: %s", result.Output)
+	}
+}
+
+func TestInstallSteps(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/node/second.sh": []byte("#!/bin/bash\necho second\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	engine.platform = Platform{OS: "linux", Arch: "amd64", PackageManager: "apt"}
+	engine.SetRunVariables(map[string]string{"CHANNEL": "lts"})
+	
+	tool := parser.Tool{Name: "node", FolderName: "node", Steps: []parser.Step{
+		{Name: "first", Run: "echo first"},
+		{Name: "brew", Run: "echo brew", When: &parser.StepCondition{Platform: parser.ValueList{"darwin"}}},
+		{Name: "second", ScriptPath: "tools/node/second.sh", When: &parser.StepCondition{
+			PackageManager: parser.ValueList{"apt", "dnf"},
+			Env:            map[string]string{"CHANNEL": "lts"},
+		}},
+		{Name: "current", Run: "echo current", When: &parser.StepCondition{Env: map[string]string{"CHANNEL": "current"}}},
+	}}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "first") || !strings.Contains(result.Output, "second") || strings.Contains(result.Output, "echo brew") {
+		t.Errorf("Expected only the matching steps to run, got: %s", result.Output)
+	}
+	statuses := []string{StepSucceeded, StepSkipped, StepSucceeded, StepSkipped}
+	for i, status := range statuses {
+		if result.Steps[i].Status != status {
+			t.Errorf("Expected step %s to be %s, got %+v", result.Steps[i].Name, status, result.Steps[i])
+		}
+	}
+	if result.Steps[1].Reason != "platform is linux" || result.Steps[3].Reason != `CHANNEL is "lts"` {
+		t.Errorf("Unexpected skip reasons %+v", result.Steps)
+	}
+	
+	// A failing step stops the install
+	tool.Steps[0].Run = "exit 3"
+	result, err = engine.InstallTool(tool)
+	if err == nil || !strings.Contains(err.Error(), `step "first" failed`) {
+		t.Fatalf("Expected the first step to fail, got %v", err)
+	}
+	if result.Steps[0].Status != StepFailed || result.Steps[2].Status != StepNotRun {
+		t.Errorf("Expected the following steps not to run, got %+v", result.Steps)
+	}
+	
+	// Nothing matching this machine is not a successful install
+	engine.platform.OS = "windows"
+	if _, err := engine.InstallTool(parser.Tool{Name: "brew-only", FolderName: "brew-only", Steps: tool.Steps[1:2]}); err == nil {
+		t.Error("Expected an error when no step matches")
+	}
+}
+
+func TestSatisfiedChecks(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "installed")
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "marker",
+		FolderName:    "marker",
+		InstallInline: "touch " + marker,
+		SatisfiedWhen: &parser.SatisfiedCheck{FileExists: parser.ValueList{marker}, Command: "test \"$BOBA_TOOL_NAME\" = marker"},
+	}
+	result, err := engine.InstallTool(tool)
+	if err != nil || result.Satisfied {
+		t.Fatalf("Expected the first install to run, got %+v (%v)", result, err)
+	}
+	result, err = engine.InstallTool(tool)
+	if err != nil || !result.Satisfied || result.Output != SatisfiedOutput {
+		t.Errorf("Expected the second install to be skipped as satisfied, got %+v (%v)", result, err)
+	}
+	
+	// Updates run the script again
+	engine.BeginUpdateRun()
+	if result, _ := engine.InstallTool(tool); result.Satisfied {
+		t.Error("Expected update runs to ignore satisfied_when")
+	}
+	engine.BeginRun()
+	
+	// Satisfied steps are skipped, the others still run
+	tool = parser.Tool{Name: "steps", FolderName: "steps", Steps: []parser.Step{
+		{Name: "done", Run: "exit 1", SatisfiedWhen: &parser.SatisfiedCheck{FileExists: parser.ValueList{marker}}},
+		{Name: "pending", Run: "echo pending", SatisfiedWhen: &parser.SatisfiedCheck{Command: "false"}},
+	}}
+	result, err = engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if result.Steps[0].Status != StepSatisfied || result.Steps[1].Status != StepSucceeded || result.Satisfied {
+		t.Errorf("Expected only the pending step to run, got %+v", result.Steps)
+	}
+	if !strings.Contains(StepSummary(result.Steps), "done ("+SatisfiedOutput+")") {
+		t.Errorf("Expected the summary to report the satisfied step, got: %s", StepSummary(result.Steps))
+	}
+}
+
+func TestPackageManagerLocks(t *testing.T) {
+	if !usesPackageManager([]byte("sudo apt-get install -y jq"), "apt") || !usesPackageManager([]byte("dpkg -i pkg.deb"), "apt") {
+		t.Error("Expected apt and dpkg calls to use the apt lock")
+	}
+	if usesPackageManager([]byte("curl -fsSL https://example.com/install | sh"), "apt") || usesPackageManager([]byte("apt-get install jq"), "brew") {
+		t.Error("Expected scripts not calling the package manager to run without its lock")
+	}
+	if packageManagerLockName("dnf") != packageManagerLockName("yum") {
+		t.Error("Expected dnf and yum to share the rpm lock")
+	}
+	
+	// Scripts holding the same lock never overlap
+	var locks namedLocks
+	var running, overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock("dpkg")
+			defer unlock()
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	if overlaps > 0 {
+		t.Errorf("Expected scripts sharing a lock to run one at a time, got %d overlaps", overlaps)
+	}
+	
+	// Different locks don't wait for each other
+	unlock := locks.lock("dpkg")
+	locks.lock("brew")()
+	unlock()
+}
+
+func TestStepsPackageManagerLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	dir := t.TempDir()
+	runStep := func(packageManager, script string) chan *InstallationResult {
+		engine := NewInstallationEngine(&MockGitHubClient{})
+		t.Cleanup(func() { engine.Cleanup() })
+		engine.platform.PackageManager = packageManager
+		tool := parser.Tool{Name: "locked-" + packageManager, FolderName: "locked", Steps: []parser.Step{{Name: "install"}}}
+		done := make(chan *InstallationResult, 1)
+		go func() { done <- engine.runSteps(tool, [][]byte{[]byte(script)}) }()
+		return done
+	}
+	
+	// Steps calling the same package manager run one at a time, even from different engines
+	held := "#!/bin/bash\n: apt-get install jq\nmkdir " + dir + "/held || { echo overlap; exit 1; }\nsleep 0.2\nrmdir " + dir + "/held\n"
+	first, second := runStep("apt", held), runStep("apt", held)
+	for _, done := range []chan *InstallationResult{first, second} {
+		if result := <-done; !result.Success {
+			t.Errorf("Expected the apt steps not to overlap, got %s", result.Output)
+		}
+	}
+	
+	// Steps calling different package managers run side by side: each waits for the other to start
+	waits := func(mine, other string) string {
+		return "#!/bin/bash\n: " + mine + " install jq\ntouch " + dir + "/" + mine + "\nfor i in $(seq 100); do [ -f " + dir + "/" + other + " ] && exit 0; sleep 0.02; done\nexit 1\n"
+	}
+	first, second = runStep("apt", waits("apt-get", "brew")), runStep("brew", waits("brew", "apt-get"))
+	for _, done := range []chan *InstallationResult{first, second} {
+		if result := <-done; !result.Success {
+			t.Errorf("Expected the apt and brew steps to run concurrently, got %v", result.Error)
+		}
+	}
+}
+
+// fakeOperationQueue records the operations begun and ended, or refuses them all
+type fakeOperationQueue struct {
+	begun, ended []string
+	err          error
+}
+
+func (q *fakeOperationQueue) BeginOperation(ctx context.Context, name string) (func(), error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	q.begun = append(q.begun, name)
+	return func() { q.ended = append(q.ended, name) }, nil
+}
+
+func TestOperationQueue(t *testing.T) {
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	queue := &fakeOperationQueue{}
+	engine.SetOperationQueue(queue)
+	tool := parser.Tool{Name: "test-tool", FolderName: "test-tool", InstallScript: "tools/test-tool/install.sh", UninstallScript: "tools/test-tool/uninstall.sh"}
+	
+	if _, err := engine.InstallTool(tool); err != nil {
+		t.Fatalf("Failed to install: %v", err)
+	}
+	if _, err := engine.UninstallTool(tool); err != nil {
+		t.Fatalf("Failed to uninstall: %v", err)
+	}
+	want := []string{"install test-tool", "uninstall test-tool"}
+	if fmt.Sprint(queue.begun) != fmt.Sprint(want) || fmt.Sprint(queue.ended) != fmt.Sprint(want) {
+		t.Errorf("Expected each operation to take its turn and end it, got %v and %v", queue.begun, queue.ended)
+	}
+	
+	// The operations of a batch take a single turn
+	queue.begun, queue.ended = nil, nil
+	if err := engine.BeginBatch("Install Everything"); err != nil {
+		t.Fatalf("Failed to begin the batch: %v", err)
+	}
+	engine.InstallTool(tool)
+	engine.UninstallTool(tool)
+	engine.EndBatch()
+	want = []string{"Install Everything"}
+	if fmt.Sprint(queue.begun) != fmt.Sprint(want) || fmt.Sprint(queue.ended) != fmt.Sprint(want) {
+		t.Errorf("Expected the batch to take one turn, got %v and %v", queue.begun, queue.ended)
+	}
+	
+	// An operation canceled while queued never runs its script
+	marker := filepath.Join(t.TempDir(), "ran")
+	engine = NewInstallationEngine(&MockGitHubClient{scriptContent: map[string][]byte{
+		"tools/test-tool/install.sh": []byte("#!/bin/bash\ntouch " + marker + "\n"),
+	}})
+	defer engine.Cleanup()
+	canceled := errors.New("canceled while queued")
+	engine.SetOperationQueue(&fakeOperationQueue{err: canceled})
+	result, err := engine.InstallTool(tool)
+	if !errors.Is(err, canceled) || result == nil || result.Success {
+		t.Errorf("Expected the install to fail with the queue, got %+v and %v", result, err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected the install script not to run")
+	}
+}
+
+func TestScriptWorkingDirectory(t *testing.T) {
+	repoDir := t.TempDir()
+	toolDir := repoDir + "/tools/asset-tool"
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatalf("Failed to create tool folder: %v", err)
+	}
+	if err := os.WriteFile(toolDir+"/config.template", []byte("bundled"), 0644); err != nil {
+		t.Fatalf("Failed to create asset: %v", err)
+	}
+	
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/asset-tool/install.sh": []byte("#!/bin/bash\ncat config.template\necho \"repo=$BOBA_REPO_DIR\"\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "asset-tool",
+		FolderName:    "asset-tool",
+		InstallScript: "tools/asset-tool/install.sh",
+		WorkingDir:    WorkingDirFolder,
+	}
+	
+	// Without a local clone the folder working directory cannot be resolved
+	if _, err := engine.InstallTool(tool); err == nil {
+		t.Error("Expected error when working_dir requires a clone that is not available")
+	}
+	
+	engine.SetRepositoryDir(repoDir)
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "bundled") {
+		t.Errorf("Expected script to read bundled asset from its folder, got: %s", result.Output)
+	}
+	if !strings.Contains(result.Output, "repo="+repoDir) {
+		t.Errorf("Expected BOBA_REPO_DIR to point at the clone, got: %s", result.Output)
+	}
+	
+	tool.WorkingDir = "nowhere"
+	if _, err := engine.InstallTool(tool); err == nil {
+		t.Error("Expected error for unknown working_dir")
+	}
+}
+
+// MockFolderClient is a MockGitHubClient that can also list folder contents
+type MockFolderClient struct {
+	MockGitHubClient
+}
+
+func (m *MockFolderClient) GetFilesRecursive(path string) ([]string, error) {
+	var files []string
+	for file := range m.scriptContent {
+		if strings.HasPrefix(file, path+"/") {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func TestBundledAssetsStaging(t *testing.T) {
+	mockClient := &MockFolderClient{MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/asset-tool/install.sh":         []byte("#!/bin/bash\ncat \"$BOBA_ASSETS_DIR/templates/app.conf\"\ncat desktop.entry\n"),
+			"tools/asset-tool/templates/app.conf": []byte("template-content\n"),
+			"tools/asset-tool/desktop.entry":      []byte("desktop-content\n"),
+			"tools/other-tool/install.sh":         []byte("#!/bin/bash\nexit 0\n"),
+		},
+	}}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "asset-tool",
+		FolderName:    "asset-tool",
+		InstallScript: "tools/asset-tool/install.sh",
+		WorkingDir:    WorkingDirFolder,
+	}
+	
+	// Without a local clone the folder working directory falls back to the staged copy
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "template-content") {
+		t.Errorf("Expected script to read nested asset via BOBA_ASSETS_DIR, got: %s", result.Output)
+	}
+	if !strings.Contains(result.Output, "desktop-content") {
+		t.Errorf("Expected script to read asset from its folder, got: %s", result.Output)
+	}
+	
+	// Staged assets are removed after the script has run
+	if entries, err := os.ReadDir(engine.tempDir + "/assets"); err == nil && len(entries) > 0 {
+		t.Errorf("Expected staging directory to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestVerifyHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("verify helper requires a POSIX shell")
+	}
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+	
+	sourceDir := t.TempDir()
+	sourcePath := sourceDir + "/payload.txt"
+	if err := os.WriteFile(sourcePath, []byte("payload\n"), 0644); err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	checksum := "sha256:" + sha256Hex([]byte("payload\n"))
+	
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	helperPath := engine.ensureVerifyHelper()
+	if helperPath == "" {
+		t.Fatal("Expected verify helper to be written")
+	}
+	
+	dest := sourceDir + "/verified.txt"
+	if output, err := exec.Command(helperPath, "file://"+sourcePath, checksum, dest).CombinedOutput(); err != nil {
+		t.Fatalf("Expected matching checksum to verify, got %v: %s", err, output)
+	}
+	if content, err := os.ReadFile(dest); err != nil || string(content) != "payload\n" {
+		t.Errorf("Expected verified file to be written, got %q (%v)", content, err)
+	}
+	
+	badDest := sourceDir + "/rejected.txt"
+	if err := exec.Command(helperPath, "file://"+sourcePath, "sha256:"+strings.Repeat("0", 64), badDest).Run(); err == nil {
+		t.Error("Expected checksum mismatch to fail")
+	}
+	if _, err := os.Stat(badDest); !os.IsNotExist(err) {
+		t.Error("Expected destination not to be written on checksum mismatch")
+	}
+	
+	// The helper is exposed to scripts as BOBA_VERIFY
+	found := false
+	for _, entry := range engine.scriptEnvironment() {
+		if entry == "BOBA_VERIFY="+helperPath {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected BOBA_VERIFY in the script environment")
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestTempDirIsolation(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/ok-tool/install.sh":  []byte("#!/bin/bash\necho \"dir=$BOBA_TEMP_DIR\"\ntouch \"$BOBA_TEMP_DIR/scratch\"\n"),
+			"tools/bad-tool/install.sh":  []byte("#!/bin/bash\necho \"dir=$BOBA_TEMP_DIR\"\ntouch \"$BOBA_TEMP_DIR/debug.log\"\nexit 1\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	other := NewInstallationEngine(mockClient)
+	defer other.Cleanup()
+	
+	if engine.tempRoot == other.tempRoot {
+		t.Error("Expected each engine to use its own temp root")
+	}
+	if info, err := os.Stat(engine.tempRoot); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected private temp root, got %v (%v)", info, err)
+	}
+	
+	okTool := parser.Tool{Name: "ok-tool", FolderName: "ok-tool", InstallScript: "tools/ok-tool/install.sh"}
+	badTool := parser.Tool{Name: "bad-tool", FolderName: "bad-tool", InstallScript: "tools/bad-tool/install.sh"}
+	
+	okResult, err := engine.InstallTool(okTool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	okDir := strings.TrimSpace(strings.SplitN(okResult.Output, "dir=", 2)[1])
+	if okDir == engine.runDir || !strings.HasPrefix(okDir, engine.runDir) {
+		t.Errorf("Expected a per-tool directory inside the run directory, got %s", okDir)
+	}
+	if _, err := os.Stat(okDir); !os.IsNotExist(err) {
+		t.Error("Expected temp directory to be removed after a successful script")
+	}
+	
+	badResult, _ := engine.InstallTool(badTool)
+	if badResult.Success {
+		t.Fatal("Expected failing script to fail")
+	}
+	if badResult.TempDir == "" {
+		t.Fatal("Expected failed script to report its retained temp directory")
+	}
+	if _, err := os.Stat(badResult.TempDir + "/debug.log"); err != nil {
+		t.Errorf("Expected failed script temp directory to be retained: %v", err)
+	}
+	
+	// A new run uses a new run directory
+	firstRun := engine.runDir
+	engine.BeginRun()
+	if engine.runDir == firstRun {
+		t.Error("Expected BeginRun to create a new run directory")
+	}
+	
+	// Cleanup keeps retained directories for debugging
+	if err := engine.Cleanup(); err != nil {
+		t.Fatalf("Expected no error during cleanup, got %v", err)
+	}
+	if _, err := os.Stat(badResult.TempDir + "/debug.log"); err != nil {
+		t.Errorf("Expected retained directory to survive cleanup: %v", err)
+	}
+	if _, err := os.Stat(engine.runDir); !os.IsNotExist(err) {
+		t.Error("Expected unused run directory to be removed by cleanup")
+	}
+	os.RemoveAll(engine.tempRoot)
+}
+
+func TestCatalogToolInlineScript(t *testing.T) {
+	// No script is served for catalog tools: the inline script must be used
+	engine := NewInstallationEngine(&MockFolderClient{MockGitHubClient{shouldError: true}})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "inline-tool",
+		FolderName:    "inline-tool",
+		Catalog:       true,
+		InstallInline: "echo \"inline install on $BOBA_PLATFORM\"\n",
+	}
+	
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "inline install on") {
+		t.Errorf("Expected inline script output, got: %s", result.Output)
+	}
+}
+
+func TestInlineInstallScript(t *testing.T) {
+	mockClient := &MockGitHubClient{
+		scriptContent: map[string][]byte{
+			"tools/inline-folder-tool/install.sh": []byte("#!/bin/bash\necho 'from install.sh'\n"),
+		},
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "inline-folder-tool",
+		FolderName:    "inline-folder-tool",
+		InstallScript: "tools/inline-folder-tool/install.sh",
+		InstallInline: "echo 'from tool.yaml'\n",
+	}
+	
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "from tool.yaml") || strings.Contains(result.Output, "from install.sh") {
+		t.Errorf("Expected the inline script to take precedence over install.sh, got: %s", result.Output)
+	}
+}
+
+func TestUninstallUnavailable(t *testing.T) {
+	engine := NewInstallationEngine(&MockGitHubClient{shouldError: true})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{Name: "no-uninstall", FolderName: "no-uninstall", UninstallScript: "tools/no-uninstall/uninstall.sh"}
+	result, err := engine.UninstallTool(tool)
+	if !errors.Is(err, ErrNotUninstallable) || result.Success {
+		t.Errorf("Expected ErrNotUninstallable for a missing uninstall script, got %v", err)
+	}
+	
+	removable := false
+	tool = parser.Tool{Name: "kernel", UninstallInline: "echo never", Uninstallable: &removable}
+	if tool.CanUninstall() {
+		t.Error("Expected tool marked uninstallable: false to report CanUninstall() == false")
+	}
+	if _, err := engine.UninstallTool(tool); !errors.Is(err, ErrNotUninstallable) {
+		t.Errorf("Expected ErrNotUninstallable for a tool marked uninstallable: false, got %v", err)
+	}
+}
+
+func TestInstallProvenance(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	
+	binDir := t.TempDir()
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	
+	script := "#!/bin/bash\nprintf '#!/bin/sh\\n' > '" + binDir + "/provenance-tool'\nchmod +x '" + binDir + "/provenance-tool'\n"
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{Name: "provenance-tool", FolderName: "provenance-tool", InstallInline: script}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	
+	provenance := result.Provenance
+	if provenance == nil {
+		t.Fatal("Expected provenance for a successful install")
+	}
+	if provenance.InstallType != InstallTypeInline {
+		t.Errorf("Expected install type %q, got %q", InstallTypeInline, provenance.InstallType)
+	}
+	if provenance.ScriptHash != sha256Hex([]byte(script)) {
+		t.Errorf("Expected script hash %s, got %s", sha256Hex([]byte(script)), provenance.ScriptHash)
+	}
+	if provenance.PackageManager != engine.GetPlatform().PackageManager {
+		t.Errorf("Expected package manager %q, got %q", engine.GetPlatform().PackageManager, provenance.PackageManager)
+	}
+	if len(provenance.BinaryPaths) != 1 || provenance.BinaryPaths[0] != binDir+"/provenance-tool" {
+		t.Errorf("Expected the created executable to be recorded, got %v", provenance.BinaryPaths)
+	}
+}
+
+func TestTrackCreatedFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	
+	trackDir := t.TempDir()
+	if err := os.WriteFile(trackDir+"/existing.conf", []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+	
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{
+		Name:          "tracked-tool",
+		FolderName:    "tracked-tool",
+		TrackDirs:     []string{trackDir, trackDir + "/missing"},
+		InstallInline: "#!/bin/bash\nmkdir -p '" + trackDir + "/sub'\necho new > '" + trackDir + "/sub/new.conf'\necho changed > '" + trackDir + "/existing.conf'\n",
+	}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	
+	created := result.Provenance.CreatedFiles
+	if len(created) != 1 || created[0] != trackDir+"/sub/new.conf" {
+		t.Errorf("Expected only the new file to be recorded, got %v", created)
+	}
+}
+
+func TestScriptLibrary(t *testing.T) {
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	if !strings.Contains(scriptLibrary, fmt.Sprintf("BOBA_LIB_VERSION=%d\n", ScriptLibraryVersion)) {
+		t.Fatal("Expected the library to declare ScriptLibraryVersion")
+	}
+	
+	home := t.TempDir()
+	rcFile := filepath.Join(home, ".toolrc")
+	tool := parser.Tool{
+		Name:       "lib-tool",
+		FolderName: "lib-tool",
+		LibVersion: ScriptLibraryVersion,
+		InstallInline: fmt.Sprintf(`. "$BOBA_LIB"
+boba_require_lib %d
+boba_log_info "installing"
+boba_append_once %q 'export LIB_TOOL=1'
+boba_append_once %q 'export LIB_TOOL=1'
+`, ScriptLibraryVersion, rcFile, rcFile),
+	}
+	
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "[lib-tool] installing") {
+		t.Errorf("Expected boba_log_info output, got: %s", result.Output)
+	}
+	content, _ := os.ReadFile(rcFile)
+	if string(content) != "export LIB_TOOL=1\n" {
+		t.Errorf("Expected boba_append_once to append the line once, got %q", content)
+	}
+	
+	// boba_rc_block keeps one block per tool, which uninstalling the tool removes
+	t.Setenv("HOME", home)
+	zshrc := filepath.Join(home, ".zshrc")
+	os.WriteFile(zshrc, []byte("export EDITOR=vim\n"), 0644)
+	blockTool := parser.Tool{
+		Name:       "block-tool",
+		FolderName: "block-tool",
+		LibVersion: ScriptLibraryVersion,
+		InstallInline: `. "$BOBA_LIB"
+printf 'export PATH="$HOME/.block/bin:$PATH"\n' | boba_rc_block "$HOME/.zshrc"
+printf 'export PATH="$HOME/.block/bin:$PATH"\nexport BLOCK=1\n' | boba_rc_block "$HOME/.zshrc"
+`,
+		UninstallInline: "true\n",
+	}
+	if result, err := engine.InstallTool(blockTool); err != nil {
+		t.Fatalf("Expected no error, got %v (output: %s)", err, result.Output)
+	}
+	want := "export EDITOR=vim\n# >>> boba tool block-tool >>>\nexport PATH=\"$HOME/.block/bin:$PATH\"\nexport BLOCK=1\n# <<< boba tool block-tool <<<\n"
+	if content, _ := os.ReadFile(zshrc); string(content) != want {
+		t.Errorf("Expected the block to be replaced, got %q", content)
+	}
+	if result, err := engine.UninstallTool(blockTool); err != nil || len(result.FileNotes) != 1 {
+		t.Fatalf("Expected the block to be removed on uninstall, got %v, %v", err, result)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != "export EDITOR=vim\n" {
+		t.Errorf("Expected only the block to be removed, got %q", content)
+	}
+	
+	// A block missing its end marker is refused rather than dropping the rest of the file
+	unclosed := "# >>> boba tool block-tool >>>\nexport OLD=1\nexport EDITOR=vim\nalias ll='ls -l'\n"
+	os.WriteFile(zshrc, []byte(unclosed), 0644)
+	if result, err := engine.InstallTool(blockTool); err == nil || !strings.Contains(result.Output, "without its end marker") {
+		t.Errorf("Expected boba_rc_block to refuse the unclosed block, got %v (output: %s)", err, result.Output)
+	}
+	if _, err := setRCBlock(zshrc, rcBlockTool, "block-tool", []byte("export NEW=1\n")); !errors.Is(err, errUnclosedRCBlock) {
+		t.Errorf("Expected setRCBlock to refuse the unclosed block, got %v", err)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != unclosed {
+		t.Errorf("Expected the file with an unclosed block to be left alone, got %q", content)
+	}
+	
+	tool.LibVersion = ScriptLibraryVersion + 1
+	if _, err := engine.InstallTool(tool); !errors.Is(err, ErrLibraryTooOld) {
+		t.Errorf("Expected ErrLibraryTooOld for a newer lib_version, got %v", err)
+	}
+}
+
+func TestVersionContract(t *testing.T) {
+	original := version.Version
+	defer func() { version.Version = original }()
+	version.Version = "v1.4.0"
+	
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{Name: "api-tool", FolderName: "api-tool", MinBobaVersion: "1.4", InstallInline: "echo \"api $BOBA_API_VERSION\"\n"}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, fmt.Sprintf("api %d", APIVersion)) {
+		t.Errorf("Expected BOBA_API_VERSION in the script environment, got: %s", result.Output)
+	}
+	
+	tool.MinBobaVersion = "v1.5.0"
+	result, err = engine.InstallTool(tool)
+	if !errors.Is(err, ErrBobaTooOld) || result.Success || result.Output != "" {
+		t.Errorf("Expected ErrBobaTooOld without running the script, got %v", err)
+	}
+	
+	env := parser.Environment{Name: "api-env", MinBobaVersion: "not-a-version", SetupInline: "true\n"}
+	if _, err := engine.ApplyEnvironment(env); err == nil || !strings.Contains(err.Error(), "min_boba_version") {
+		t.Errorf("Expected an invalid min_boba_version error, got %v", err)
+	}
+}
+
+func TestCheckRepository(t *testing.T) {
+	original := version.Version
+	defer func() { version.Version = original }()
+	version.Version = "v1.4.0"
+	
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	engine.platform = Platform{OS: "linux", Arch: "amd64", PackageManager: "apt"}
+	
+	if err := engine.CheckRepository(nil); err != nil {
+		t.Errorf("Expected a repository without requirements to pass, got %v", err)
+	}
+	if err := engine.CheckRepository(&parser.RepositoryInfo{MinBobaVersion: "1.4", Platforms: []string{"darwin", "linux/amd64"}}); err != nil {
+		t.Errorf("Expected a compatible repository to pass, got %v", err)
+	}
+	if err := engine.CheckRepository(&parser.RepositoryInfo{MinBobaVersion: "2.0"}); !errors.Is(err, ErrBobaTooOld) {
+		t.Errorf("Expected ErrBobaTooOld, got %v", err)
+	}
+	err := engine.CheckRepository(&parser.RepositoryInfo{Platforms: []string{"darwin", "linux/arm64"}})
+	if !errors.Is(err, ErrUnsupportedPlatform) || !strings.Contains(err.Error(), "linux/amd64") {
+		t.Errorf("Expected ErrUnsupportedPlatform naming this machine, got %v", err)
+	}
+}
+
+func TestDryRunOptions(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	engine := NewInstallationEngineWithOptions(&MockGitHubClient{}, Options{DryRun: true})
+	defer engine.Cleanup()
+	if !engine.DryRun() {
+		t.Fatal("Expected the engine to be in dry-run mode")
+	}
+	
+	tool := parser.Tool{Name: "dry-tool", FolderName: "dry-tool", InstallInline: "touch " + marker + "\n"}
+	result, err := engine.InstallTool(tool)
+	if err != nil || !result.Success || !result.DryRun {
+		t.Fatalf("Expected a successful dry run, got %+v, %v", result, err)
+	}
+	for _, want := range []string{"[dry run] would install dry-tool", "Script:      inline", "BOBA_TOOL_NAME=dry-tool"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected %q in the dry run output, got:\n%s", want, result.Output)
+		}
+	}
+	
+	env := parser.Environment{Name: "dry-env", FolderName: "dry-env", SetupInline: "touch " + marker + "\n"}
+	result, err = engine.ApplyEnvironment(env)
+	if err != nil || !result.DryRun || !strings.Contains(result.Output, "BOBA_ENV_NAME=dry-env") {
+		t.Errorf("Expected the environment setup to be described, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("Expected nothing to run in dry-run mode")
+	}
+	
+	// satisfied_when checks run repository commands, so they are reported instead of evaluated
+	checked := tool
+	checked.SatisfiedWhen = &parser.SatisfiedCheck{Command: "touch " + marker}
+	checked.Steps = []parser.Step{{Name: "binary", Run: "true\n", SatisfiedWhen: &parser.SatisfiedCheck{Command: "touch " + marker}}}
+	result, err = engine.InstallTool(checked)
+	if err != nil || !result.DryRun || !strings.Contains(result.Output, "Not evaluated: satisfied_when, ") {
+		t.Errorf("Expected the checks to be reported as not evaluated, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("Expected satisfied_when not to run in dry-run mode")
+	}
+	
+	engine.SetOptions(Options{})
+	if _, err := engine.InstallTool(tool); err != nil {
+		t.Fatalf("Expected the install to run, got %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the install script to run once dry-run mode is off: %v", err)
+	}
+}
+
+// pinnedSourceClient serves the configuration repository and, at pinned refs, other repositories
+type pinnedSourceClient struct {
+	MockGitHubClient
+	pinned map[string][]byte // owner/repo/path@ref -> content
+}
+
+func (c *pinnedSourceClient) GetContentsAt(owner, repo, path, ref string) ([]byte, error) {
+	if content, ok := c.pinned[owner+"/"+repo+"/"+path+"@"+ref]; ok {
+		return content, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func TestPinnedScriptSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	
+	client := &pinnedSourceClient{pinned: map[string][]byte{
+		"audited/tools/ripgrep/install.sh@v1.2.0": []byte("#!/bin/bash\necho 'from the audited fork'\n"),
+	}}
+	engine := NewInstallationEngine(client)
+	defer engine.Cleanup()
+	
+	tool := parser.Tool{Name: "ripgrep", FolderName: "ripgrep", Source: "audited/tools/ripgrep@v1.2.0"}
+	source, err := parser.ParseScriptSource(tool.Source)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	tool.ScriptSource = source
+	tool.InstallScript = "ripgrep/install.sh"
+	tool.UninstallScript = "ripgrep/uninstall.sh"
+	
+	result, err := engine.InstallTool(tool)
+	if err != nil || !strings.Contains(result.Output, "from the audited fork") {
+		t.Fatalf("Expected the pinned install script to run, got %v (output: %s)", err, result.Output)
+	}
+	if result.Provenance.ScriptSource != "audited/tools/ripgrep@v1.2.0" {
+		t.Errorf("Expected the pinned source in the provenance, got %q", result.Provenance.ScriptSource)
+	}
+	
+	// The pinned repository has no uninstall script: the tool cannot be uninstalled
+	if _, err := engine.UninstallTool(tool); !errors.Is(err, ErrNotUninstallable) {
+		t.Errorf("Expected a missing pinned uninstall script to make the tool not uninstallable, got %v", err)
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	engine := NewInstallationEngine(&MockFolderClient{MockGitHubClient{shouldError: true}})
+	defer engine.Cleanup()
+	
+	tools := []parser.Tool{
+		{Name: "git", FolderName: "git", Catalog: true, Version: "2.45", InstallInline: "echo git\n"},
+		{Name: "node", FolderName: "node", Catalog: true, InstallInline: "echo node\n"},
+	}
+	plan, err := engine.BuildPlan(tools, nil)
+	if err != nil {
+		t.Fatalf("Failed to build the plan: %v", err)
+	}
+	if len(plan.Entries) != 2 || plan.Entries[0].Name != "git" || plan.Entries[0].Version != "2.45" || plan.Entries[1].Version != "latest" {
+		t.Fatalf("Expected git 2.45 then node latest, got %+v", plan.Entries)
+	}
+	
+	again, _ := engine.BuildPlan(tools, nil)
+	if again.Hash() != plan.Hash() {
+		t.Error("Expected the same tools to give the same plan hash")
+	}
+	
+	// Editing a script changes its entry
+	tools[1].InstallInline = "echo node 22\n"
+	edited, _ := engine.BuildPlan(tools, nil)
+	if edited.Entries[0].ScriptHash != plan.Entries[0].ScriptHash || edited.Entries[1].ScriptHash == plan.Entries[1].ScriptHash {
+		t.Errorf("Expected only the node script hash to change, got %+v", edited.Entries)
+	}
+	
+	// A script that can't be read leaves no plan
+	tools = append(tools, parser.Tool{Name: "missing", FolderName: "missing", InstallScript: "missing/install.sh"})
+	if _, err := engine.BuildPlan(tools, nil); err == nil {
+		t.Error("Expected an unreadable script to fail the plan")
+	}
+}
+
+func TestResolvePlan(t *testing.T) {
+	engine := NewInstallationEngine(&MockFolderClient{MockGitHubClient{shouldError: true}})
+	defer engine.Cleanup()
+	
+	tools := []parser.Tool{
+		{Name: "git", FolderName: "git", Catalog: true, InstallInline: "echo git\n"},
+		{Name: "node", FolderName: "node", Catalog: true, InstallInline: "echo node\n"},
+	}
+	plan, err := engine.BuildPlan([]parser.Tool{tools[1], tools[0]}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	// The plan order is kept, whatever the repository order
+	resolved, _, err := engine.ResolvePlan(plan, tools, nil)
+	if err != nil || len(resolved) != 2 || resolved[0].Name != "node" || resolved[1].Name != "git" {
+		t.Fatalf("Expected node then git, got %v (%v)", resolved, err)
+	}
+	
+	tools[0].InstallInline = "echo git 2\n"
+	if _, _, err := engine.ResolvePlan(plan, tools, nil); err == nil || !strings.Contains(err.Error(), "~ tool git (scripts changed)") {
+		t.Errorf("Expected the changed script to be refused, got %v", err)
+	}
+	if _, _, err := engine.ResolvePlan(plan, tools[:1], nil); err == nil || !strings.Contains(err.Error(), "node") {
+		t.Errorf("Expected the missing tool to be reported, got %v", err)
+	}
+}
+
+func TestPlanApprovalPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	engine := NewInstallationEngine(&MockFolderClient{MockGitHubClient{shouldError: true}})
+	defer engine.Cleanup()
+	
+	keyPath := filepath.Join(t.TempDir(), "admin.key")
+	publicKey, err := config.GenerateApprovalKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetApprovalPolicy(true, []string{publicKey})
+	
+	tool := parser.Tool{Name: "approved-tool", FolderName: "approved-tool", Catalog: true, InstallInline: "echo approved\n"}
+	if _, err := engine.InstallTool(tool); !errors.Is(err, ErrNotApproved) {
+		t.Fatalf("Expected a tool outside of an approved plan to be refused, got %v", err)
+	}
+	
+	plan, err := engine.BuildPlan([]parser.Tool{tool}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.ApprovePlan(plan); err == nil {
+		t.Fatal("Expected an unsigned plan to be refused")
+	}
+	if err := config.SignPlan(&plan, keyPath, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.ApprovePlan(plan); err != nil {
+		t.Fatalf("Expected the signed plan to be approved, got %v", err)
+	}
+	if result, err := engine.InstallTool(tool); err != nil || !strings.Contains(result.Output, "approved") {
+		t.Fatalf("Expected the approved tool to install, got %v", err)
+	}
+	
+	// Scripts must be the approved ones, and the approval ends with the run
+	edited := tool
+	edited.InstallInline = "echo edited\n"
+	if _, err := engine.InstallTool(edited); !errors.Is(err, ErrNotApproved) {
+		t.Errorf("Expected an edited script to be refused, got %v", err)
+	}
+	engine.BeginRun()
+	if _, err := engine.InstallTool(tool); !errors.Is(err, ErrNotApproved) {
+		t.Errorf("Expected the approval to end with the run, got %v", err)
+	}
+	if _, err := engine.UninstallTool(parser.Tool{Name: "approved-tool", UninstallInline: "true"}); !errors.Is(err, ErrNotApproved) {
+		t.Errorf("Expected uninstalls to be refused in managed mode, got %v", err)
+	}
+	
+	// A script changed in the repository after its approval check never runs: it is downloaded
+	// once, and the bytes checked are the bytes executed
+	client := &swappingClient{approved: 2}
+	swapEngine := NewInstallationEngine(client)
+	defer swapEngine.Cleanup()
+	swapEngine.SetApprovalPolicy(true, []string{publicKey})
+	swapTool := parser.Tool{Name: "swap-tool", FolderName: "swap-tool", InstallScript: "tools/swap-tool/install.sh"}
+	plan, err = swapEngine.BuildPlan([]parser.Tool{swapTool}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SignPlan(&plan, keyPath, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := swapEngine.ApprovePlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	result, err := swapEngine.InstallTool(swapTool)
+	if err != nil || !strings.Contains(result.Output, "approved") || client.reads != 2 {
+		t.Errorf("Expected the approved script to run from a single download, got %v after %d reads (output: %s)", err, client.reads, result.Output)
+	}
+}
+
+// swappingClient serves the approved script for the first reads and another one afterwards, like
+// a repository changed while BOBA runs
+type swappingClient struct {
+	approved int // Reads served the approved script
+	reads    int
+}
+
+func (c *swappingClient) GetRepositoryContents(path string) ([]byte, error) {
+	c.reads++
+	if c.reads <= c.approved {
+		return []byte("echo approved\n"), nil
+	}
+	return []byte("echo swapped\n"), nil
+}
+
+func TestInstallScope(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	
+	// The manifest scope wins, system-wide is the default when it is possible
+	engine.systemWide = true
+	if scope := engine.InstallScope(parser.Tool{Name: "tool"}); scope != ScopeSystem {
+		t.Errorf("Expected system-wide installs by default, got %s", scope)
+	}
+	if scope := engine.InstallScope(parser.Tool{Name: "tool", Scope: ScopeUser}); scope != ScopeUser {
+		t.Errorf("Expected the preferred user scope, got %s", scope)
+	}
+	engine.systemWide = false
+	if scope := engine.InstallScope(parser.Tool{Name: "tool", Scope: ScopeSystem}); scope != ScopeUser {
+		t.Errorf("Expected the user scope without root or sudo, got %s", scope)
+	}
+	
+	// Steps pick the install variant of the chosen scope
+	engine.systemWide = true
+	tool := parser.Tool{Name: "scoped", FolderName: "scoped", Scope: ScopeUser, Steps: []parser.Step{
+		{Name: "user", Run: "echo user variant in $BOBA_INSTALL_SCOPE $BOBA_INSTALL_PREFIX", When: &parser.StepCondition{Scope: parser.ValueList{"user"}}},
+		{Name: "system", Run: "echo system variant", When: &parser.StepCondition{Scope: parser.ValueList{"system"}}},
+	}}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "user variant in user "+filepath.Join(os.Getenv("HOME"), ".local")) || strings.Contains(result.Output, "system variant") {
+		t.Errorf("Expected only the user variant to run, got: %s", result.Output)
+	}
+	if result.Steps[1].Status != StepSkipped || result.Steps[1].Reason != "install scope is user" {
+		t.Errorf("Expected the system variant to be skipped, got %+v", result.Steps[1])
+	}
+	if result.Provenance == nil || result.Provenance.InstallScope != ScopeUser {
+		t.Errorf("Expected the scope to be recorded in the provenance, got %+v", result.Provenance)
+	}
+	
+	if _, err := engine.InstallTool(parser.Tool{Name: "bad-scope", Scope: "global", InstallInline: "true"}); err == nil || !strings.Contains(err.Error(), "unknown scope") {
+		t.Errorf("Expected an unknown scope to be refused, got %v", err)
+	}
+}
+
+func TestFindInstalledScope(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping executable lookup test on Windows")
+	}
+	home := t.TempDir()
+	userBin := filepath.Join(home, ".local", "bin")
+	systemBin := t.TempDir()
+	for dir, name := range map[string]string{userBin: "user-tool", systemBin: "shared-tool"} {
+		os.MkdirAll(dir, 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", userBin+string(os.PathListSeparator)+systemBin)
+	
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	if scope, ok := engine.FindInstalledScope(parser.Tool{Name: "user-tool"}); !ok || scope.Scope != ScopeUser {
+		t.Errorf("Expected a user install, got %+v (found %v)", scope, ok)
+	}
+	if scope, ok := engine.FindInstalledScope(parser.Tool{Name: "shared-tool"}); !ok || scope.Scope != ScopeSystem || scope.Path != filepath.Join(systemBin, "shared-tool") {
+		t.Errorf("Expected a system-wide install, got %+v (found %v)", scope, ok)
+	}
+	if _, ok := engine.FindInstalledScope(parser.Tool{Name: "missing-tool"}); ok {
+		t.Error("Expected a missing tool not to be found")
+	}
+}
+
+func TestNoSudo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	engine := NewInstallationEngine(&MockGitHubClient{})
+	defer engine.Cleanup()
+	engine.systemWide = true
+	engine.SetNoSudo(true)
+	
+	tool := parser.Tool{Name: "no-sudo-tool", InstallInline: "echo no sudo: $BOBA_NO_SUDO $BOBA_INSTALL_SCOPE\nsudo true || echo refused\n"}
+	result, err := engine.InstallTool(tool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result.Output, "no sudo: 1 user") || !strings.Contains(result.Output, "refused") {
+		t.Errorf("Expected a user install without sudo, got: %s", result.Output)
+	}
+	
+	// Tools that must be installed system-wide can't be, unless BOBA runs as root
+	system := parser.Tool{Name: "system-tool", Scope: ScopeSystem, InstallInline: "true"}
+	_, err = engine.InstallTool(system)
+	if os.Geteuid() == 0 {
+		if err != nil || engine.NeedsSudo(system) {
+			t.Errorf("Expected root to need no sudo, got %v", err)
+		}
+	} else if !errors.Is(err, ErrNeedsSudo) || !engine.NeedsSudo(system) {
+		t.Errorf("Expected the system-wide tool to be refused, got %v", err)
+	}
+	
+	engine.SetNoSudo(false)
+	if engine.NeedsSudo(system) {
+		t.Error("Expected sudo to be allowed again")
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	onPath := map[string]bool{"bash": true}
+	lookPath := func(file string) (string, error) {
+		if onPath[file] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	
+	linux := Platform{OS: "linux", PackageManager: "apt"}
+	missing := missingPrerequisites(linux, lookPath)
+	if len(missing) != 2 || missing[0].Command != "git" || missing[1].Command != "curl" {
+		t.Fatalf("Expected git and curl to be missing, got %+v", missing)
+	}
+	
+	commands, err := bootstrapCommands(linux, missing, false, false, true)
+	if err != nil {
+		t.Fatalf("Expected bootstrap commands, got %v", err)
+	}
+	if len(commands) != 2 || strings.Join(commands[0], " ") != "sudo apt-get update" || strings.Join(commands[1], " ") != "sudo apt-get install -y git curl" {
+		t.Errorf("Expected an index refresh and install with sudo, got %v", commands)
+	}
+	if commands, _ := bootstrapCommands(linux, missing, true, false, false); strings.Join(commands[1], " ") != "apt-get install -y git curl" {
+		t.Errorf("Expected no sudo when running as root, got %v", commands)
+	}
+	if _, err := bootstrapCommands(linux, missing, false, true, true); err == nil || !strings.Contains(err.Error(), "never use sudo") {
+		t.Errorf("Expected never-use-sudo mode to refuse, got %v", err)
+	}
+	if _, err := bootstrapCommands(Platform{OS: "linux", PackageManager: "unknown"}, missing, true, false, false); err == nil {
+		t.Error("Expected an error without a supported package manager")
+	}
+	
+	// Homebrew is required on macOS and can't install itself
+	mac := Platform{OS: "darwin", PackageManager: "brew"}
+	missing = missingPrerequisites(mac, lookPath)
+	if len(missing) != 3 || missing[0].Command != "brew" {
+		t.Fatalf("Expected brew, git and curl to be missing on macOS, got %+v", missing)
+	}
+	if _, err := bootstrapCommands(mac, missing, false, false, true); err == nil || !strings.Contains(err.Error(), "brew.sh") {
+		t.Errorf("Expected the Homebrew install instructions, got %v", err)
+	}
+}
+
+func TestHomebrewInstaller(t *testing.T) {
+	script := "#!/bin/bash\necho installing homebrew\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, script)
+	}))
+	defer server.Close()
+	
+	homebrew, err := DownloadHomebrewInstaller(server.URL + "/install.sh")
+	if err != nil {
+		t.Fatalf("Expected the installer to download, got %v", err)
+	}
+	sum := sha256.Sum256([]byte(script))
+	if homebrew.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the SHA-256 of the script, got %s", homebrew.Checksum)
+	}
+	
+	if err := homebrew.Verify(""); !errors.Is(err, ErrHomebrewNotPinned) || !strings.Contains(err.Error(), homebrew.Checksum) {
+		t.Errorf("Expected an unpinned installer to be refused with its checksum, got %v", err)
+	}
+	if err := homebrew.Verify(strings.Repeat("0", 64)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected a changed installer to be refused, got %v", err)
+	}
+	if err := homebrew.Verify(homebrew.Checksum); err != nil {
+		t.Errorf("Expected the pinned installer to verify, got %v", err)
+	}
+	
+	// A fresh install is found in its prefix before it is on PATH
+	prefix := t.TempDir()
+	notOnPath := func(string) (string, error) { return "", exec.ErrNotFound }
+	if _, found := findHomebrew(notOnPath, []string{prefix}); found {
+		t.Error("Expected no brew in an empty prefix")
+	}
+	brew := filepath.Join(prefix, "bin", "brew")
+	os.MkdirAll(filepath.Dir(brew), 0755)
+	os.WriteFile(brew, []byte("#!/bin/sh\n"), 0755)
+	if path, found := findHomebrew(notOnPath, []string{prefix}); !found || path != brew {
+		t.Errorf("Expected brew in the prefix, got %q, %v", path, found)
+	}
+	
+	env := prependPath([]string{"HOME=/home/me", "PATH=/usr/bin"}, filepath.Dir(brew))
+	if env[1] != "PATH="+filepath.Dir(brew)+string(os.PathListSeparator)+"/usr/bin" {
+		t.Errorf("Expected the brew directory first on PATH, got %v", env)
+	}
+}
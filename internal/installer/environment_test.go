@@ -1,9 +1,14 @@
 package installer
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"boba/internal/config"
 	"boba/internal/parser"
 )
 
@@ -11,6 +16,7 @@ import (
 type MockGitHubClientForEnvironment struct {
 	setupScript   []byte
 	restoreScript []byte
+	files         map[string][]byte // Config files by repository path
 	shouldError   bool
 }
 
@@ -33,6 +39,9 @@ func (m *MockGitHubClientForEnvironment) GetRepositoryContents(path string) ([]b
 		return []byte("#!/bin/bash\necho 'Restoring environment'\nexit 0"), nil
 	}
 	
+	if content, ok := m.files[path]; ok {
+		return content, nil
+	}
 	return nil, fmt.Errorf("file not found: %s", path)
 }
 
@@ -162,4 +171,415 @@ func TestVerifyEnvironmentApplication(t *testing.T) {
 	if message == "" {
 		t.Error("Expected non-empty verification message")
 	}
-}
\ No newline at end of file
+}
+func TestEnvironmentShellArgs(t *testing.T) {
+	plain := environmentShellArgs("/tmp/setup.sh", parser.Environment{})
+	if len(plain) != 1 || plain[0] != "/tmp/setup.sh" {
+		t.Errorf("Expected plain script execution, got %v", plain)
+	}
+	
+	login := environmentShellArgs("/tmp/setup.sh", parser.Environment{LoginShell: true})
+	if len(login) != 3 || login[0] != "-lc" || login[2] != "/tmp/setup.sh" {
+		t.Errorf("Expected bash -lc execution, got %v", login)
+	}
+	
+	interactive := environmentShellArgs("/tmp/setup.sh", parser.Environment{LoginShell: true, Interactive: true})
+	if interactive[0] != "-lic" {
+		t.Errorf("Expected bash -lic execution, got %v", interactive)
+	}
+}
+
+func TestApplyEnvironmentWithLoginShell(t *testing.T) {
+	mockClient := &MockGitHubClientForEnvironment{
+		setupScript: []byte("#!/bin/bash\nif shopt -q login_shell; then echo 'login shell'; else echo 'plain shell'; fi\n"),
+	}
+	engine := NewInstallationEngine(mockClient)
+	defer engine.Cleanup()
+	
+	env := parser.Environment{
+		Name:        "login-env",
+		FolderName:  "login-env",
+		SetupScript: "environments/login-env/setup.sh",
+		LoginShell:  true,
+	}
+	
+	result, err := engine.ApplyEnvironment(env)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	
+	if !strings.Contains(result.Output, "login shell") {
+		t.Errorf("Expected script to run in a login shell, got: %s", result.Output)
+	}
+}
+
+func TestEnvironmentFiles(t *testing.T) {
+	mockClient := &MockGitHubClientForEnvironment{setupScript: []byte("#!/bin/bash\ncp .zshrc ~/.zshrc\n")}
+	engine := NewInstallationEngine(mockClient)
+	
+	env := parser.Environment{
+		Name:        "test-env",
+		FolderName:  "test-env",
+		SetupScript: "environments/test-env/setup.sh",
+		ConfigFiles: []string{"environments/test-env/.zshrc"},
+	}
+	
+	files, err := engine.EnvironmentFiles(env)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected the setup script and the config file, got %d files", len(files))
+	}
+	if files[0].Path != env.SetupScript || !strings.Contains(string(files[0].Content), "cp .zshrc") || files[0].Err != nil {
+		t.Errorf("Expected the setup script content, got %+v", files[0])
+	}
+	
+	// A missing config file is reported without hiding the other files
+	if files[1].Path != env.ConfigFiles[0] || files[1].Err == nil {
+		t.Errorf("Expected the missing config file to be reported, got %+v", files[1])
+	}
+	
+	env.SetupInline = "echo inline"
+	files, _ = engine.EnvironmentFiles(env)
+	if string(files[0].Content) != "echo inline" {
+		t.Errorf("Expected the inline setup script, got %q", files[0].Content)
+	}
+}
+
+func TestCheckFileConflicts(t *testing.T) {
+	engine := NewInstallationEngine(&MockGitHubClientForEnvironment{})
+	zsh := parser.Environment{Name: "zsh", ConfigFiles: []string{"environments/zsh/.zshrc"}}
+	work := parser.Environment{Name: "zsh-work", ConfigFiles: []string{"environments/zsh-work/.zshrc", "environments/zsh-work/.profile"}}
+	bash := parser.Environment{Name: "bash", ConfigFiles: []string{"environments/bash/.bashrc", "environments/bash/.profile"}}
+	
+	var conflictErr *FileConflictError
+	err := engine.CheckFileConflicts([]parser.Environment{zsh, work}, nil)
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].File != ".zshrc" {
+		t.Fatalf("Expected the shared .zshrc to be reported, got %v", err)
+	}
+	if got := conflictErr.Conflicts[0].Environments; len(got) != 2 || got[0] != "zsh" || got[1] != "zsh-work" {
+		t.Errorf("Expected both environments in run order, got %v", got)
+	}
+	
+	// An environment already applied conflicts with the run, applied ones alone were settled before
+	if err := engine.CheckFileConflicts([]parser.Environment{bash}, []parser.Environment{work}); !errors.As(err, &conflictErr) || conflictErr.Conflicts[0].File != ".profile" {
+		t.Errorf("Expected the .profile of the applied environment to conflict, got %v", err)
+	}
+	fish := parser.Environment{Name: "fish", ConfigFiles: []string{"environments/fish/.fishrc"}}
+	if err := engine.CheckFileConflicts([]parser.Environment{fish}, []parser.Environment{zsh, work}); err != nil {
+		t.Errorf("Expected conflicts between applied environments to be ignored, got %v", err)
+	}
+	
+	// An owner among the environments or a merge resolves the conflict, an unrelated owner doesn't
+	engine.SetFileOwners(map[string]string{".zshrc": "bash"})
+	if err := engine.CheckFileConflicts([]parser.Environment{zsh, work}, nil); err == nil {
+		t.Error("Expected an owner that doesn't manage the file to leave the conflict")
+	}
+	engine.SetFileOwners(map[string]string{".zshrc": "zsh-work", ".profile": config.FileMergeStrategy})
+	if err := engine.CheckFileConflicts([]parser.Environment{zsh, work, bash}, nil); err != nil {
+		t.Errorf("Expected the resolved conflicts to pass, got %v", err)
+	}
+}
+
+func TestApplyEnvironmentFileOwners(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zshrc := filepath.Join(home, ".zshrc")
+	
+	mockClient := &MockGitHubClientForEnvironment{setupScript: []byte("#!/bin/bash\nprintf 'export EDITOR=vim\\nalias gs=\"git status\"\\n' > \"$HOME/.zshrc\"\n")}
+	engine := NewInstallationEngine(mockClient)
+	env := parser.Environment{
+		Name:        "zsh-work",
+		FolderName:  "zsh-work",
+		SetupScript: "environments/zsh-work/setup.sh",
+		ConfigFiles: []string{"environments/zsh-work/.zshrc"},
+	}
+	
+	// Owned by another environment: the file keeps its content
+	os.WriteFile(zshrc, []byte("export EDITOR=nano\n"), 0600)
+	engine.SetFileOwners(map[string]string{".zshrc": "zsh"})
+	result, err := engine.ApplyEnvironment(env)
+	if err != nil || !result.Success {
+		t.Fatalf("Expected the environment to apply, got %v: %s", err, result.Output)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != "export EDITOR=nano\n" {
+		t.Errorf("Expected the owner's .zshrc to be kept, got %q", content)
+	}
+	if len(result.FileNotes) != 1 || !strings.Contains(result.FileNotes[0], "zsh owns it") {
+		t.Errorf("Expected a note on the kept file, got %v", result.FileNotes)
+	}
+	
+	// Merged: the lines the environment adds go in its block, once however often it is applied
+	engine.SetFileOwners(map[string]string{".zshrc": config.FileMergeStrategy})
+	want := "export EDITOR=nano\n# >>> boba environment zsh-work >>>\nexport EDITOR=vim\nalias gs=\"git status\"\n# <<< boba environment zsh-work <<<\n"
+	for i := 0; i < 2; i++ {
+		if result, err := engine.ApplyEnvironment(env); err != nil || !result.Success {
+			t.Fatalf("Expected the environment to apply, got %v", err)
+		}
+		if content, _ := os.ReadFile(zshrc); string(content) != want {
+			t.Errorf("Run %d: expected the merged .zshrc, got %q", i+1, content)
+		}
+	}
+	
+	// The owner writes the file as usual
+	engine.SetFileOwners(map[string]string{".zshrc": "zsh-work"})
+	if result, err := engine.ApplyEnvironment(env); err != nil || len(result.FileNotes) != 0 {
+		t.Fatalf("Expected the owner to apply without notes, got %v, %v", err, result.FileNotes)
+	}
+	if content, _ := os.ReadFile(zshrc); !strings.HasPrefix(string(content), "export EDITOR=vim") {
+		t.Errorf("Expected the owner's .zshrc, got %q", content)
+	}
+}
+
+func TestMergeRCFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zshrc := filepath.Join(home, ".zshrc")
+	
+	mockClient := &MockGitHubClientForEnvironment{files: map[string][]byte{
+		"environments/zsh/.zshrc":      []byte("export EDITOR=vim\n"),
+		"environments/zsh-work/.zshrc": []byte("export HTTP_PROXY=http://proxy:3128\n"),
+	}}
+	engine := NewInstallationEngine(mockClient)
+	zsh := parser.Environment{Name: "zsh", FolderName: "zsh", SetupScript: "environments/zsh/setup.sh", RestoreScript: "environments/zsh/restore.sh", ConfigFiles: []string{"environments/zsh/.zshrc"}, MergeRCFiles: true}
+	work := parser.Environment{Name: "zsh-work", FolderName: "zsh-work", SetupScript: "environments/zsh-work/setup.sh", ConfigFiles: []string{"environments/zsh-work/.zshrc"}, MergeRCFiles: true}
+	
+	// Environments merging their files only manage their own block, so they don't conflict
+	if err := engine.CheckFileConflicts([]parser.Environment{zsh, work}, nil); err != nil {
+		t.Errorf("Expected merged files not to conflict, got %v", err)
+	}
+	copying := parser.Environment{Name: "dotfiles", ConfigFiles: []string{"environments/dotfiles/.zshrc"}}
+	if err := engine.CheckFileConflicts([]parser.Environment{zsh, copying}, nil); err == nil {
+		t.Error("Expected an environment copying the file to conflict with a merged one")
+	}
+	
+	// The user's lines are kept, each environment gets its block, applying again changes nothing
+	os.WriteFile(zshrc, []byte("source ~/.aliases\n"), 0600)
+	for _, env := range []parser.Environment{zsh, work, zsh} {
+		if result, err := engine.ApplyEnvironment(env); err != nil || !result.Success {
+			t.Fatalf("Expected %s to apply, got %v", env.Name, err)
+		}
+	}
+	want := "source ~/.aliases\n# >>> boba environment zsh >>>\nexport EDITOR=vim\n# <<< boba environment zsh <<<\n# >>> boba environment zsh-work >>>\nexport HTTP_PROXY=http://proxy:3128\n# <<< boba environment zsh-work <<<\n"
+	if content, _ := os.ReadFile(zshrc); string(content) != want {
+		t.Errorf("Expected a block per environment, got %q", content)
+	}
+	
+	// An update replaces the block where it is
+	mockClient.files["environments/zsh/.zshrc"] = []byte("export EDITOR=nvim\n")
+	result, err := engine.ApplyEnvironment(zsh)
+	if err != nil || len(result.FileNotes) != 1 || !strings.Contains(result.FileNotes[0], "block of zsh updated") {
+		t.Fatalf("Expected a note on the updated block, got %v, %v", err, result.FileNotes)
+	}
+	want = strings.Replace(want, "EDITOR=vim", "EDITOR=nvim", 1)
+	if content, _ := os.ReadFile(zshrc); string(content) != want {
+		t.Errorf("Expected the block to be replaced in place, got %q", content)
+	}
+	
+	// Restoring removes only the environment's block
+	if result, err := engine.RestoreEnvironment(zsh); err != nil || len(result.FileNotes) != 1 {
+		t.Fatalf("Expected the block to be removed, got %v", err)
+	}
+	want = "source ~/.aliases\n# >>> boba environment zsh-work >>>\nexport HTTP_PROXY=http://proxy:3128\n# <<< boba environment zsh-work <<<\n"
+	if content, _ := os.ReadFile(zshrc); string(content) != want {
+		t.Errorf("Expected only the block of zsh to be removed, got %q", content)
+	}
+	if result, _ := engine.RestoreEnvironment(zsh); len(result.FileNotes) != 0 {
+		t.Errorf("Expected restoring again to change nothing, got %v", result.FileNotes)
+	}
+	
+	// A file another environment owns is left alone
+	engine.SetFileOwners(map[string]string{".zshrc": "dotfiles"})
+	if result, _ := engine.ApplyEnvironment(zsh); len(result.FileNotes) != 1 || !strings.Contains(result.FileNotes[0], "left to dotfiles") {
+		t.Errorf("Expected the owned file to be skipped, got %v", result.FileNotes)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != want {
+		t.Errorf("Expected the owned file to be unchanged, got %q", content)
+	}
+}
+
+func TestSetShimsPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	shims := filepath.Join(home, ".boba", "shims")
+	
+	// Without rc files, ~/.profile gets the block
+	if notes, err := SetShimsPath(shims, true); err != nil || len(notes) != 1 || !strings.HasPrefix(notes[0], "~/.profile") {
+		t.Fatalf("Expected ~/.profile to be changed, got %v, %v", notes, err)
+	}
+	want := "# >>> boba usage tracking >>>\nexport PATH=\"$HOME/.boba/shims:$PATH\"\n# <<< boba usage tracking <<<\n"
+	if content, _ := os.ReadFile(filepath.Join(home, ".profile")); string(content) != want {
+		t.Errorf("Expected the shims first on PATH, got %q", content)
+	}
+	
+	// The rc files of the shells in use get it instead, fish in its syntax
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("alias ll='ls -la'\n"), 0644)
+	os.MkdirAll(filepath.Join(home, ".config", "fish"), 0755)
+	os.WriteFile(filepath.Join(home, ".config", "fish", "config.fish"), nil, 0644)
+	if notes, err := SetShimsPath(shims, true); err != nil || len(notes) != 2 {
+		t.Fatalf("Expected two rc files to be changed, got %v, %v", notes, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".config", "fish", "config.fish")); !strings.Contains(string(content), `set -gx PATH "$HOME/.boba/shims" $PATH`) {
+		t.Errorf("Expected fish syntax, got %q", content)
+	}
+	
+	// Turning it off removes every block, the user's lines kept
+	if notes, err := SetShimsPath(shims, false); err != nil || len(notes) != 3 {
+		t.Fatalf("Expected three blocks to be removed, got %v, %v", notes, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".zshrc")); string(content) != "alias ll='ls -la'\n" {
+		t.Errorf("Expected only the block to be removed, got %q", content)
+	}
+}
+
+func TestEditedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, t.TempDir())
+	zshrc := filepath.Join(home, ".zshrc")
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	writes := func(content string) []byte {
+		return []byte(fmt.Sprintf("#!/bin/bash\nprintf %q > \"$HOME/.zshrc\"\n", content))
+	}
+	mockClient := &MockGitHubClientForEnvironment{setupScript: writes("export EDITOR=vim\nexport PAGER=less\n")}
+	engine := NewInstallationEngine(mockClient)
+	engine.SetManagedFiles(configManager)
+	env := parser.Environment{Name: "zsh", FolderName: "zsh", SetupScript: "environments/zsh/setup.sh", RestoreScript: "environments/zsh/restore.sh", ConfigFiles: []string{"environments/zsh/.zshrc"}}
+	
+	// The first application overwrites the file and remembers it
+	os.WriteFile(zshrc, []byte("# mine before BOBA\n"), 0644)
+	if result, err := engine.ApplyEnvironment(env); err != nil || !result.Success {
+		t.Fatalf("Expected the environment to apply, got %v", err)
+	}
+	if err := engine.CheckEditedFiles([]parser.Environment{env}); err != nil {
+		t.Errorf("Expected no edits right after applying, got %v", err)
+	}
+	
+	// Edits are detected and never overwritten without a choice
+	os.WriteFile(zshrc, []byte("export EDITOR=vim\nexport PAGER=less\nalias ll='ls -l'\n"), 0644)
+	mockClient.setupScript = writes("export EDITOR=nvim\nexport PAGER=less\n")
+	var editedErr *EditedFilesError
+	if err := engine.CheckEditedFiles([]parser.Environment{env}); !errors.As(err, &editedErr) || len(editedErr.Files) != 1 || editedErr.Files[0].AppliedBy != "zsh" {
+		t.Fatalf("Expected the edited .zshrc, got %v", err)
+	}
+	if _, err := engine.ApplyEnvironment(env); !errors.As(err, &editedErr) {
+		t.Fatalf("Expected the application to stop, got %v", err)
+	}
+	if content, _ := os.ReadFile(zshrc); !strings.Contains(string(content), "alias ll") {
+		t.Errorf("Expected the edits to be kept, got %q", content)
+	}
+	
+	// A failing setup script gives the edits back, and the resolution is kept for the next attempt
+	engine.SetEditResolution(".zshrc", EditMerge)
+	good := mockClient.setupScript
+	mockClient.setupScript = []byte("#!/bin/bash\necho partial > \"$HOME/.zshrc\"\nexit 1\n")
+	if result, err := engine.ApplyEnvironment(env); err == nil || len(result.FileNotes) != 1 || !strings.Contains(result.FileNotes[0], "restored") {
+		t.Fatalf("Expected the edits to be restored, got %v, %v", err, result.FileNotes)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != "export EDITOR=vim\nexport PAGER=less\nalias ll='ls -l'\n" {
+		t.Errorf("Expected the edits back, got %q", content)
+	}
+	mockClient.setupScript = good
+	
+	// A merge keeps the edits and the repository's changes
+	result, err := engine.ApplyEnvironment(env)
+	if err != nil || len(result.FileNotes) != 1 || !strings.Contains(result.FileNotes[0], "merged") {
+		t.Fatalf("Expected the file to be merged, got %v, %v", err, result.FileNotes)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != "export EDITOR=nvim\nexport PAGER=less\nalias ll='ls -l'\n" {
+		t.Errorf("Expected the merged file, got %q", content)
+	}
+	
+	// The merged file still differs from the repository's version, and keeping it leaves it alone
+	engine.SetEditResolution(".zshrc", EditKeepMine)
+	if _, err := engine.ApplyEnvironment(env); err != nil {
+		t.Fatalf("Expected the environment to apply, got %v", err)
+	}
+	if content, _ := os.ReadFile(zshrc); !strings.Contains(string(content), "alias ll") {
+		t.Errorf("Expected the edits to be kept, got %q", content)
+	}
+	
+	// Conflicting changes are marked
+	os.WriteFile(zshrc, []byte("export EDITOR=emacs\nexport PAGER=less\n"), 0644)
+	mockClient.setupScript = writes("export EDITOR=hx\nexport PAGER=less\n")
+	engine.SetEditResolution(".zshrc", EditMerge)
+	if result, err := engine.ApplyEnvironment(env); err != nil || !strings.Contains(result.FileNotes[0], "1 conflict(s)") {
+		t.Fatalf("Expected a conflict, got %v, %v", err, result.FileNotes)
+	}
+	if content, _ := os.ReadFile(zshrc); !strings.Contains(string(content), "<<<<<<< yours") || !strings.Contains(string(content), ">>>>>>> repository") {
+		t.Errorf("Expected conflict markers, got %q", content)
+	}
+	
+	// Taking the repository's version drops the edits, and restoring forgets the file
+	engine.SetEditResolution(".zshrc", EditTakeRepo)
+	if _, err := engine.ApplyEnvironment(env); err != nil {
+		t.Fatalf("Expected the environment to apply, got %v", err)
+	}
+	if content, _ := os.ReadFile(zshrc); string(content) != "export EDITOR=hx\nexport PAGER=less\n" {
+		t.Errorf("Expected the repository's version, got %q", content)
+	}
+	if _, err := engine.RestoreEnvironment(env); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := configManager.GetManagedFile(".zshrc"); ok {
+		t.Error("Expected the restored environment's file to be forgotten")
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines([]byte("a\nb\nc\n"), []byte("a\nB\nc\nd\n"))
+	if want := []string{"- b", "+ B", "+ d"}; strings.Join(diff, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, diff)
+	}
+	if diff := DiffLines([]byte("same\n"), []byte("same\n")); len(diff) != 0 {
+		t.Errorf("Expected no difference, got %v", diff)
+	}
+}
+
+func TestManagedShellDefinitions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zshrc := strings.Join([]string{
+		"alias mine='not managed'",
+		"# >>> boba environment zsh >>>",
+		"alias ll='ls -la'",
+		`export PATH="$HOME/bin:$PATH"`,
+		"export EDITOR=vim",
+		"# <<< boba environment zsh <<<",
+		"# >>> boba tool nvm >>>",
+		`export NVM_DIR="$HOME/.nvm"`,
+		"export EDITOR=nvim",
+		"# <<< boba tool nvm <<<",
+	}, "\n") + "\n"
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte(zshrc), 0644)
+	os.MkdirAll(filepath.Join(home, ".config", "fish"), 0755)
+	os.WriteFile(filepath.Join(home, ".config", "fish", "config.fish"), []byte("set -gx GOPATH ~/go\nfish_add_path -g ~/go/bin\nalias g 'git'\nset count 1\n"), 0644)
+
+	definitions, err := ManagedShellDefinitions(map[string]config.ManagedFile{".config/fish/config.fish": {Environment: "fish"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range definitions {
+		got = append(got, fmt.Sprintf("%s %s=%s (%s, ~/%s:%d, %v)", d.Kind, d.Name, d.Value, d.Source, d.File, d.Line, d.Overridden))
+	}
+	want := []string{
+		"alias ll=ls -la (environment zsh, ~/.zshrc:3, false)",
+		"path $HOME/bin= (environment zsh, ~/.zshrc:4, false)",
+		"export EDITOR=vim (environment zsh, ~/.zshrc:5, true)",
+		"export NVM_DIR=$HOME/.nvm (tool nvm, ~/.zshrc:8, false)",
+		"export EDITOR=nvim (tool nvm, ~/.zshrc:9, false)",
+		"export GOPATH=~/go (environment fish, ~/.config/fish/config.fish:1, false)",
+		"path ~/go/bin= (environment fish, ~/.config/fish/config.fish:2, false)",
+		"alias g=git (environment fish, ~/.config/fish/config.fish:3, false)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,173 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+// FileConflictError is returned when environments of a run manage the same home files and
+// neither an owner nor a merge was chosen for them
+type FileConflictError struct {
+	Conflicts []parser.FileConflict
+}
+
+func (e *FileConflictError) Error() string {
+	lines := []string{"environments manage the same files, choose an owner or merge for each:"}
+	for _, conflict := range e.Conflicts {
+		lines = append(lines, fmt.Sprintf("  ~/%s: %s", conflict.File, strings.Join(conflict.Environments, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetFileOwners sets how conflicts over home files were resolved: the environment owning each
+// file, or config.FileMergeStrategy
+func (ie *InstallationEngine) SetFileOwners(owners map[string]string) {
+	ie.fileOwners = owners
+}
+
+// CheckFileConflicts returns a *FileConflictError for the home files managed by several of the
+// environments of a run, or by one of them and an environment already applied, that have no
+// owner or merge chosen. Conflicts between applied environments only were settled before.
+func (ie *InstallationEngine) CheckFileConflicts(run, applied []parser.Environment) error {
+	inRun := make(map[string]bool, len(run))
+	for _, env := range run {
+		inRun[env.Name] = true
+	}
+	
+	var unresolved []parser.FileConflict
+	for _, conflict := range parser.FileConflicts(append(slices.Clone(run), applied...)) {
+		if !slices.ContainsFunc(conflict.Environments, func(name string) bool { return inRun[name] }) {
+			continue
+		}
+		owner := ie.fileOwners[conflict.File]
+		if owner != config.FileMergeStrategy && !slices.Contains(conflict.Environments, owner) {
+			unresolved = append(unresolved, conflict)
+		}
+	}
+	if len(unresolved) > 0 {
+		return &FileConflictError{Conflicts: unresolved}
+	}
+	return nil
+}
+
+// resolvedFile is a home file with a resolved conflict, as it was before a setup script ran
+type resolvedFile struct {
+	name    string // Relative to $HOME
+	path    string
+	owner   string
+	existed bool
+	mode    os.FileMode
+	content []byte
+}
+
+// snapshotResolvedFiles saves the home files of the environment whose conflict was resolved
+// against it: owned by another environment, or merged
+func (ie *InstallationEngine) snapshotResolvedFiles(env parser.Environment) []resolvedFile {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	
+	var files []resolvedFile
+	for _, name := range parser.HomeFiles([]parser.Environment{env}) {
+		owner, ok := ie.fileOwners[name]
+		if !ok || owner == env.Name {
+			continue
+		}
+		file := resolvedFile{name: name, path: filepath.Join(home, name), owner: owner, mode: 0644}
+		if info, err := os.Stat(file.path); err == nil {
+			file.existed = true
+			file.mode = info.Mode().Perm()
+			if file.content, err = os.ReadFile(file.path); err != nil {
+				continue
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// resolveFiles applies the resolutions after the setup script of an environment ran: a file
+// owned by another environment gets its previous content back, and a merged file keeps its
+// previous content with the lines the environment added in a marked block. It returns a note
+// for each file.
+func resolveFiles(envName string, files []resolvedFile) ([]string, error) {
+	var notes []string
+	for _, file := range files {
+		after, err := os.ReadFile(file.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return notes, fmt.Errorf("failed to read ~/%s: %w", file.name, err)
+		}
+		
+		if file.owner != config.FileMergeStrategy {
+			if bytes.Equal(after, file.content) && (err == nil) == file.existed {
+				continue
+			}
+			if !file.existed {
+				os.Remove(file.path)
+			} else if err := os.WriteFile(file.path, file.content, file.mode); err != nil {
+				return notes, fmt.Errorf("failed to restore ~/%s: %w", file.name, err)
+			}
+			notes = append(notes, fmt.Sprintf("~/%s kept as %s owns it", file.name, file.owner))
+			continue
+		}
+		
+		merged := mergeFileContent(file.content, after, envName)
+		if bytes.Equal(merged, after) {
+			continue
+		}
+		if err := os.WriteFile(file.path, merged, file.mode); err != nil {
+			return notes, fmt.Errorf("failed to merge ~/%s: %w", file.name, err)
+		}
+		notes = append(notes, fmt.Sprintf("~/%s merged: lines added by %s kept in their own block", file.name, envName))
+	}
+	return notes, nil
+}
+
+// mergeFileContent keeps the content of a file before an environment's setup script ran, without
+// the block the environment added last time, and appends the lines the script added in a new
+// block. Lines already in the file are not repeated.
+func mergeFileContent(before, after []byte, envName string) []byte {
+	begin, end := rcBlockMarkers(rcBlockEnvironment, envName)
+	
+	var kept []string
+	existing := make(map[string]bool)
+	inBlock := false
+	for _, line := range strings.Split(strings.TrimSuffix(string(before), "\n"), "\n") {
+		switch {
+		case line == begin:
+			inBlock = true
+		case line == end:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+			existing[line] = true
+		}
+	}
+	
+	var added []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(after), "\n"), "\n") {
+		if strings.TrimSpace(line) == "" || line == begin || line == end || existing[line] {
+			continue
+		}
+		existing[line] = true
+		added = append(added, line)
+	}
+	
+	merged := strings.Join(kept, "\n")
+	if merged != "" {
+		merged += "\n"
+	}
+	if len(added) > 0 {
+		merged += begin + "\n" + strings.Join(added, "\n") + "\n" + end + "\n"
+	}
+	return []byte(merged)
+}
@@ -0,0 +1,47 @@
+package installer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// expandTrackDir expands ~ and environment variables in a tracked directory
+func expandTrackDir(dir string) string {
+	dir = os.ExpandEnv(dir)
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+		}
+	}
+	return filepath.Clean(dir)
+}
+
+// snapshotFiles returns the files currently found under the tracked directories.
+// Missing or unreadable directories are skipped, since scripts may create them.
+func snapshotFiles(dirs []string) map[string]bool {
+	files := make(map[string]bool)
+	for _, dir := range dirs {
+		filepath.WalkDir(expandTrackDir(dir), func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				files[path] = true
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// createdFiles returns the files present in after but not in before, sorted
+func createdFiles(before, after map[string]bool) []string {
+	var created []string
+	for path := range after {
+		if !before[path] {
+			created = append(created, path)
+		}
+	}
+	sort.Strings(created)
+	return created
+}
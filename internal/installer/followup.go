@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"os"
+	"strings"
+)
+
+// FollowUpAction represents an action the user must take after a script has run
+type FollowUpAction string
+
+const (
+	FollowUpReboot   FollowUpAction = "reboot"
+	FollowUpRelogin  FollowUpAction = "relogin"
+	FollowUpNewShell FollowUpAction = "new_shell"
+)
+
+// Exit codes scripts can use to report success together with a required follow-up action
+const (
+	ExitCodeRebootRequired   = 100
+	ExitCodeReloginRequired  = 101
+	ExitCodeNewShellRequired = 102
+)
+
+// FollowUpActionOrder lists follow-up actions from most to least disruptive, for display
+var FollowUpActionOrder = []FollowUpAction{FollowUpReboot, FollowUpRelogin, FollowUpNewShell}
+
+// followUpExitCodes maps the follow-up exit code convention to actions
+var followUpExitCodes = map[int]FollowUpAction{
+	ExitCodeRebootRequired:   FollowUpReboot,
+	ExitCodeReloginRequired:  FollowUpRelogin,
+	ExitCodeNewShellRequired: FollowUpNewShell,
+}
+
+// Description returns a human readable instruction for the follow-up action
+func (a FollowUpAction) Description() string {
+	switch a {
+	case FollowUpReboot:
+		return "Reboot your machine"
+	case FollowUpRelogin:
+		return "Log out and log back in"
+	case FollowUpNewShell:
+		return "Open a new shell (or source your shell rc file)"
+	default:
+		return string(a)
+	}
+}
+
+// parseFollowUpAction converts a marker file line into a follow-up action
+func parseFollowUpAction(value string) (FollowUpAction, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "reboot", "restart":
+		return FollowUpReboot, true
+	case "relogin", "re-login", "logout":
+		return FollowUpRelogin, true
+	case "new_shell", "new-shell", "shell":
+		return FollowUpNewShell, true
+	}
+	return "", false
+}
+
+// collectFollowUps reads follow-up requests signaled by a script, either through the
+// marker file (one action per line) or through the follow-up exit code convention.
+// It returns the actions and the exit code to report (follow-up exit codes count as success).
+func collectFollowUps(markerPath string, exitCode int) ([]FollowUpAction, int) {
+	var actions []FollowUpAction
+	seen := make(map[FollowUpAction]bool)
+
+	if content, err := os.ReadFile(markerPath); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if action, ok := parseFollowUpAction(line); ok && !seen[action] {
+				seen[action] = true
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	if action, ok := followUpExitCodes[exitCode]; ok {
+		if !seen[action] {
+			actions = append(actions, action)
+		}
+		exitCode = 0
+	}
+
+	return actions, exitCode
+}
+
+// recordFollowUps aggregates follow-up actions for the current run
+func (ie *InstallationEngine) recordFollowUps(source string, actions []FollowUpAction) {
+	if len(actions) == 0 {
+		return
+	}
+	if ie.followUps == nil {
+		ie.followUps = make(map[FollowUpAction][]string)
+	}
+	for _, action := range actions {
+		ie.followUps[action] = append(ie.followUps[action], source)
+	}
+}
+
+// PendingFollowUps returns the follow-up actions requested during the current run,
+// mapped to the names of the tools or environments that requested them
+func (ie *InstallationEngine) PendingFollowUps() map[FollowUpAction][]string {
+	result := make(map[FollowUpAction][]string)
+	for action, sources := range ie.followUps {
+		result[action] = append([]string(nil), sources...)
+	}
+	return result
+}
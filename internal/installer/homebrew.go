@@ -0,0 +1,166 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HomebrewInstallerURL is the official Homebrew install script (https://brew.sh)
+const HomebrewInstallerURL = "https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh"
+
+// homebrewPrefixes are where the Homebrew installer puts brew: Apple silicon and Intel Macs, then Linuxbrew
+var homebrewPrefixes = []string{"/opt/homebrew", "/usr/local", "/home/linuxbrew/.linuxbrew"}
+
+// ErrHomebrewMissing is returned when Homebrew is the package manager but is not installed
+var ErrHomebrewMissing = errors.New("Homebrew is the package manager but is not installed: start boba in a terminal to install it")
+
+// ErrHomebrewNotPinned is returned when no reviewed checksum of the Homebrew installer is known
+var ErrHomebrewNotPinned = errors.New("no checksum pinned for the Homebrew installer")
+
+// HomebrewInstaller is a downloaded copy of the Homebrew install script
+type HomebrewInstaller struct {
+	URL      string
+	Script   []byte
+	Checksum string // Hex SHA-256 of Script
+}
+
+// FindHomebrew returns the path of brew, on PATH or in one of the prefixes the installer uses.
+// A fresh install is not on PATH until the shell profile is updated.
+func FindHomebrew() (string, bool) {
+	return findHomebrew(exec.LookPath, homebrewPrefixes)
+}
+
+// findHomebrew looks brew up with lookPath, then in the bin directory of each prefix
+func findHomebrew(lookPath func(file string) (string, error), prefixes []string) (string, bool) {
+	if path, err := lookPath("brew"); err == nil {
+		return path, true
+	}
+	for _, prefix := range prefixes {
+		path := filepath.Join(prefix, "bin", "brew")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// checkHomebrew fails with ErrHomebrewMissing when scripts would call a brew that isn't there
+func (ie *InstallationEngine) checkHomebrew() error {
+	if ie.platform.PackageManager != "brew" {
+		return nil
+	}
+	if _, found := FindHomebrew(); !found {
+		return ErrHomebrewMissing
+	}
+	return nil
+}
+
+// homebrewEnvironment puts the bin directory of a Homebrew install that is not on PATH yet
+// first on the PATH of scripts
+func homebrewEnvironment(env []string) []string {
+	if _, err := exec.LookPath("brew"); err == nil {
+		return env
+	}
+	if path, found := FindHomebrew(); found {
+		return prependPath(env, filepath.Dir(path))
+	}
+	return env
+}
+
+// DownloadHomebrewInstaller downloads the Homebrew install script and computes its checksum
+func DownloadHomebrewInstaller(url string) (*HomebrewInstaller, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the Homebrew installer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the Homebrew installer: %s", resp.Status)
+	}
+	script, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the Homebrew installer: %w", err)
+	}
+	
+	sum := sha256.Sum256(script)
+	return &HomebrewInstaller{URL: url, Script: script, Checksum: hex.EncodeToString(sum[:])}, nil
+}
+
+// Verify checks the installer against the pinned checksum
+func (hi *HomebrewInstaller) Verify(expected string) error {
+	if expected == "" {
+		return fmt.Errorf("%w: review %s and pin its SHA-256 %s", ErrHomebrewNotPinned, hi.URL, hi.Checksum)
+	}
+	if hi.Checksum != expected {
+		return fmt.Errorf("Homebrew installer: %w (expected %.12s, got %.12s)", ErrChecksumMismatch, expected, hi.Checksum)
+	}
+	return nil
+}
+
+// InstallHomebrew verifies the downloaded installer against the pinned checksum and runs it
+// attached to the terminal, since it asks for the administrator password on macOS.
+// NONINTERACTIVE=1 skips its own confirmation prompt: BOBA asked already.
+func (ie *InstallationEngine) InstallHomebrew(installer *HomebrewInstaller, expected string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if err := installer.Verify(expected); err != nil {
+		return err
+	}
+	if ie.noSudo && ie.platform.OS == "darwin" {
+		return fmt.Errorf("installing Homebrew needs an administrator, and BOBA is set to never use sudo")
+	}
+	
+	dir, err := os.MkdirTemp("", "boba-homebrew-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	scriptPath := filepath.Join(dir, "install.sh")
+	if err := os.WriteFile(scriptPath, installer.Script, 0700); err != nil {
+		return err
+	}
+	
+	cmd := exec.Command("/bin/bash", scriptPath)
+	cmd.Env = append(os.Environ(), "NONINTERACTIVE=1")
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Homebrew installer failed: %w", err)
+	}
+	
+	// Make brew usable for the rest of this run, before the shell profile picks it up
+	path, found := FindHomebrew()
+	if !found {
+		return fmt.Errorf("Homebrew installer finished, but brew was not found")
+	}
+	os.Setenv("PATH", filepath.Dir(path)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return nil
+}
+
+// prependPath puts dir first on the PATH of an environment
+func prependPath(env []string, dir string) []string {
+	// The last PATH entry is the one scripts get
+	for i := len(env) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(env[i], "PATH="); ok {
+			env[i] = fmt.Sprintf("PATH=%s%c%s", dir, os.PathListSeparator, value)
+			return env
+		}
+	}
+	return append(env, "PATH="+dir)
+}
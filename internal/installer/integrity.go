@@ -0,0 +1,50 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrChecksumMismatch is returned when a binary no longer matches its recorded checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// FileChecksum returns the hex SHA-256 digest of a file
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyChecksum returns ErrChecksumMismatch when the file does not match the expected digest
+func VerifyChecksum(path, expected string) error {
+	actual, err := FileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("%s: %w (expected %.12s, got %.12s)", path, ErrChecksumMismatch, expected, actual)
+	}
+	return nil
+}
+
+// BinaryPath returns the path of the running BOBA binary
+func (si *SystemInstaller) BinaryPath() string {
+	return si.binaryPath
+}
+
+// InstallPath returns the path BOBA is installed to system-wide
+func (si *SystemInstaller) InstallPath() string {
+	return si.installPath
+}
@@ -7,6 +7,12 @@ type GitHubClientInterface interface {
 	GetRepositoryContents(path string) ([]byte, error)
 }
 
+// PinnedContentReader is implemented by clients that can read other repositories at a ref,
+// used for tools whose scripts are pinned to a fork (source: owner/repo/path@ref)
+type PinnedContentReader interface {
+	GetContentsAt(owner, repo, path, ref string) ([]byte, error)
+}
+
 // InstallationEngineInterface defines the interface for installation operations
 type InstallationEngineInterface interface {
 	// Tool operations
@@ -0,0 +1,115 @@
+package installer
+
+import (
+	"context"
+	"regexp"
+	"sync"
+)
+
+// namedLocks hands out one mutex per name, created on first use
+type namedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock acquires the mutex of the name and returns the function releasing it
+func (l *namedLocks) lock(name string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	mutex, ok := l.locks[name]
+	if !ok {
+		mutex = &sync.Mutex{}
+		l.locks[name] = mutex
+	}
+	l.mu.Unlock()
+	
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// scriptLocks serializes scripts of every engine of the process that use the same system
+// package manager, so concurrent installs don't fail on its lock (dpkg, rpm, brew)
+var scriptLocks namedLocks
+
+// packageManagerCommands matches the commands that take the lock of each package manager
+var packageManagerCommands = map[string]*regexp.Regexp{
+	"apt":    regexp.MustCompile(`\b(apt|apt-get|aptitude|dpkg)\b`),
+	"dnf":    regexp.MustCompile(`\b(dnf|yum|rpm)\b`),
+	"yum":    regexp.MustCompile(`\b(dnf|yum|rpm)\b`),
+	"pacman": regexp.MustCompile(`\bpacman\b`),
+	"zypper": regexp.MustCompile(`\b(zypper|rpm)\b`),
+	"apk":    regexp.MustCompile(`\bapk\b`),
+	"brew":   regexp.MustCompile(`\bbrew\b`),
+}
+
+// packageManagerLockName returns the lock shared by the package managers using the same database
+func packageManagerLockName(packageManager string) string {
+	switch packageManager {
+	case "apt":
+		return "dpkg"
+	case "dnf", "yum", "zypper":
+		return "rpm"
+	}
+	return packageManager
+}
+
+// usesPackageManager reports whether a script calls the system package manager
+func usesPackageManager(script []byte, packageManager string) bool {
+	pattern, ok := packageManagerCommands[packageManager]
+	return ok && pattern.Match(script)
+}
+
+// lockPackageManager holds the package manager lock while a script using it runs. It returns
+// the function releasing the lock, which does nothing when the script does not need it.
+func (ie *InstallationEngine) lockPackageManager(script []byte) func() {
+	if !usesPackageManager(script, ie.platform.PackageManager) {
+		return func() {}
+	}
+	return scriptLocks.lock(packageManagerLockName(ie.platform.PackageManager))
+}
+
+// OperationQueue runs one installation or environment operation at a time across the BOBA
+// processes of the machine: the UI, the commands and the automatic mode
+type OperationQueue interface {
+	BeginOperation(ctx context.Context, name string) (func(), error)
+}
+
+// SetOperationQueue sets the queue installs, uninstalls, applications and restores wait their
+// turn in. Without one, they run right away.
+func (ie *InstallationEngine) SetOperationQueue(queue OperationQueue) {
+	ie.queue = queue
+}
+
+// BeginBatch waits for the turn of a run of operations, such as Install Everything, and holds it
+// until EndBatch: the operations of the run don't wait for their turn again, so no other BOBA
+// process runs its operations between them. It does nothing while a batch runs, or in dry-run mode.
+func (ie *InstallationEngine) BeginBatch(name string) error {
+	if ie.queue == nil || ie.batchEnd != nil || ie.options.DryRun {
+		return nil
+	}
+	end, err := ie.queue.BeginOperation(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	ie.batchEnd = end
+	return nil
+}
+
+// EndBatch ends the run of operations begun by BeginBatch, letting other BOBA processes run theirs
+func (ie *InstallationEngine) EndBatch() {
+	if ie.batchEnd != nil {
+		ie.batchEnd()
+		ie.batchEnd = nil
+	}
+}
+
+// beginOperation waits for the turn of the operation and returns the function ending it. Within a
+// batch, the batch holds the turn already.
+func (ie *InstallationEngine) beginOperation(name string) (func(), error) {
+	if ie.queue == nil || ie.batchEnd != nil {
+		return func() {}, nil
+	}
+	return ie.queue.BeginOperation(context.Background(), name)
+}
@@ -0,0 +1,52 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"boba/internal/parser"
+)
+
+// ErrNeedsSudo is returned for tools that must be installed system-wide while BOBA is set to
+// never use sudo
+var ErrNeedsSudo = errors.New("needs root privileges, and BOBA is set to never use sudo")
+
+// sudoShim replaces sudo on the PATH of scripts in never-use-sudo mode, so that a script calling
+// it fails right away instead of prompting for a password
+const sudoShim = `#!/bin/sh
+echo "sudo: disabled, BOBA is set to never use sudo (install to \$BOBA_INSTALL_PREFIX instead)" >&2
+exit 1
+`
+
+// SetNoSudo turns the never-use-sudo mode on or off. Tools are then installed in the user
+// scope, scripts receive BOBA_NO_SUDO=1 and cannot run sudo, and the package index is not refreshed.
+func (ie *InstallationEngine) SetNoSudo(noSudo bool) {
+	ie.noSudo = noSudo
+}
+
+// NoSudo reports whether BOBA is set to never use sudo
+func (ie *InstallationEngine) NoSudo() bool {
+	return ie.noSudo
+}
+
+// NeedsSudo reports whether a tool can't be installed because its manifest requires a
+// system-wide install (scope: system) while BOBA is set to never use sudo. Running as root
+// needs no sudo.
+func (ie *InstallationEngine) NeedsSudo(tool parser.Tool) bool {
+	return ie.noSudo && tool.Scope == ScopeSystem && os.Geteuid() != 0
+}
+
+// noSudoEnvironment adds BOBA_NO_SUDO=1 and puts the sudo shim first on PATH
+func (ie *InstallationEngine) noSudoEnvironment(env []string) []string {
+	env = append(env, "BOBA_NO_SUDO=1")
+	
+	shimDir := filepath.Join(ie.tempDir, "no-sudo")
+	if err := os.MkdirAll(shimDir, 0700); err != nil {
+		return env
+	}
+	if err := os.WriteFile(filepath.Join(shimDir, "sudo"), []byte(sudoShim), 0755); err != nil {
+		return env
+	}
+	return prependPath(env, shimDir)
+}
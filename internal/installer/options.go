@@ -0,0 +1,38 @@
+package installer
+
+import "os"
+
+// DryRunEnv puts the engines BOBA creates in dry-run mode, set by the global --dry-run flag
+const DryRunEnv = "BOBA_DRY_RUN"
+
+// Options configures an installation engine
+type Options struct {
+	DryRun bool // Download and describe the scripts that would run, in order, instead of running them
+}
+
+// DefaultOptions returns the options of engines created with NewInstallationEngine
+func DefaultOptions() Options {
+	return Options{DryRun: os.Getenv(DryRunEnv) != ""}
+}
+
+// NewInstallationEngineWithOptions creates a new installation engine instance with the given options
+func NewInstallationEngineWithOptions(githubClient GitHubClientInterface, options Options) *InstallationEngine {
+	ie := NewInstallationEngine(githubClient)
+	ie.options = options
+	return ie
+}
+
+// Options returns the options of the engine
+func (ie *InstallationEngine) Options() Options {
+	return ie.options
+}
+
+// SetOptions changes the options of the engine, e.g. when dry-run mode is toggled in the UI
+func (ie *InstallationEngine) SetOptions(options Options) {
+	ie.options = options
+}
+
+// DryRun reports whether the engine describes scripts instead of running them
+func (ie *InstallationEngine) DryRun() bool {
+	return ie.options.DryRun
+}
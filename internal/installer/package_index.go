@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"boba/internal/parser"
+)
+
+// packageIndexRefreshCommand returns the command that refreshes the package index for a package manager.
+// It returns nil for package managers without a separate refresh step.
+func packageIndexRefreshCommand(packageManager string) []string {
+	switch packageManager {
+	case "apt":
+		return []string{"apt-get", "update"}
+	case "dnf":
+		return []string{"dnf", "makecache"}
+	case "yum":
+		return []string{"yum", "makecache"}
+	case "pacman":
+		return []string{"pacman", "-Sy"}
+	case "zypper":
+		return []string{"zypper", "--non-interactive", "refresh"}
+	case "apk":
+		return []string{"apk", "update"}
+	case "brew":
+		return []string{"brew", "update"}
+	default:
+		return nil
+	}
+}
+
+// requiresRoot reports whether the package manager needs root privileges to refresh its index
+func requiresRoot(packageManager string) bool {
+	return packageManager != "brew"
+}
+
+// IsPackageIndexFresh reports whether the package index has been refreshed during the current run
+func (ie *InstallationEngine) IsPackageIndexFresh() bool {
+	return ie.indexFresh
+}
+
+// EnsurePackageIndexFresh refreshes the system package index once per run, when the install
+// scripts of a tool of the batch call the package manager. Scripts receive BOBA_INDEX_FRESH=1
+// after a successful refresh and can skip their own apt-get update / brew update calls. A refresh
+// that fails or can't run is returned as an error, and in the result, but is not fatal: scripts
+// simply see BOBA_INDEX_FRESH=0 and fall back to refreshing on their own.
+func (ie *InstallationEngine) EnsurePackageIndexFresh(tools []parser.Tool) (*InstallationResult, error) {
+	if ie.indexFresh {
+		return &InstallationResult{Success: true, Output: "Package index already refreshed for this run"}, nil
+	}
+
+	if err := ie.checkHomebrew(); err != nil {
+		return &InstallationResult{Success: false, Error: err}, err
+	}
+	args := packageIndexRefreshCommand(ie.platform.PackageManager)
+	if args == nil {
+		return &InstallationResult{Success: true, Output: fmt.Sprintf("No index refresh needed for package manager '%s'", ie.platform.PackageManager)}, nil
+	}
+	if !ie.usesPackageIndex(tools) {
+		return &InstallationResult{Success: true, Output: fmt.Sprintf("No tool of this run uses package manager '%s'", ie.platform.PackageManager)}, nil
+	}
+
+	// Use non-interactive sudo so a password prompt can never block the UI
+	if requiresRoot(ie.platform.PackageManager) && os.Geteuid() != 0 {
+		if ie.noSudo {
+			err := fmt.Errorf("root privileges required to refresh package index, and BOBA is set to never use sudo")
+			return &InstallationResult{Success: false, Error: err}, err
+		}
+		if _, err := exec.LookPath("sudo"); err != nil {
+			err := fmt.Errorf("root privileges required to refresh package index")
+			return &InstallationResult{Success: false, Error: err}, err
+		}
+		args = append([]string{"sudo", "-n"}, args...)
+	}
+
+	if ie.options.DryRun {
+		return &InstallationResult{Success: true, DryRun: true, Output: fmt.Sprintf("Would refresh the package index: %s", strings.Join(args, " "))}, nil
+	}
+
+	unlock := scriptLocks.lock(packageManagerLockName(ie.platform.PackageManager))
+	defer unlock()
+
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	result := &InstallationResult{
+		Success:  err == nil,
+		Output:   string(output),
+		Duration: time.Since(startTime),
+	}
+	if err != nil {
+		result.Error = fmt.Errorf("package index refresh (%s) failed: %w", strings.Join(args, " "), err)
+		return result, result.Error
+	}
+
+	ie.indexFresh = true
+	return result, nil
+}
+
+// usesPackageIndex reports whether the install scripts of a tool call the system package manager;
+// a script that can't be read counts as calling it
+func (ie *InstallationEngine) usesPackageIndex(tools []parser.Tool) bool {
+	for _, tool := range tools {
+		content, _, err := ie.installScripts(tool)
+		if err != nil || usesPackageManager(content, ie.platform.PackageManager) {
+			return true
+		}
+	}
+	return false
+}
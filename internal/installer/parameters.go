@@ -0,0 +1,40 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	
+	"boba/internal/parser"
+)
+
+// SetParameterValues sets the parameter values chosen by the user, by tool name then parameter name.
+// Parameters without a valid chosen value use their manifest default.
+func (ie *InstallationEngine) SetParameterValues(values map[string]map[string]string) {
+	ie.paramValues = values
+}
+
+// toolParameters returns the parameters of a tool with their values, in manifest order
+func (ie *InstallationEngine) toolParameters(tool parser.Tool) ([]parser.Parameter, map[string]string) {
+	return tool.Parameters, tool.ParameterValues(ie.paramValues[tool.Name])
+}
+
+// parameterEnvironment passes each parameter to the scripts as BOBA_PARAM_<NAME>
+func (ie *InstallationEngine) parameterEnvironment(tool parser.Tool) []string {
+	params, values := ie.toolParameters(tool)
+	var env []string
+	for _, param := range params {
+		env = append(env, fmt.Sprintf("%s=%s", param.EnvName(), values[param.Name]))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// parameterArgs passes each parameter to the scripts as a --name=value argument
+func (ie *InstallationEngine) parameterArgs(tool parser.Tool) []string {
+	params, values := ie.toolParameters(tool)
+	var args []string
+	for _, param := range params {
+		args = append(args, fmt.Sprintf("--%s=%s", param.Name, values[param.Name]))
+	}
+	return args
+}
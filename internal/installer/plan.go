@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+// BuildPlan describes ordered tools and environments as an execution plan, hashing the
+// scripts each operation would run so changes to them are detected
+func (ie *InstallationEngine) BuildPlan(tools []parser.Tool, environments []parser.Environment) (config.ExecutionPlan, error) {
+	if ie.githubClient == nil {
+		return config.ExecutionPlan{}, fmt.Errorf("GitHub client not initialized")
+	}
+	
+	var plan config.ExecutionPlan
+	for _, tool := range tools {
+		content, steps, err := ie.installScripts(tool)
+		if err != nil {
+			return config.ExecutionPlan{}, fmt.Errorf("failed to read the install script of %s: %w", tool.Name, err)
+		}
+		version := tool.Version
+		if version == "" {
+			version = "latest"
+		}
+		plan.Entries = append(plan.Entries, config.PlanEntry{
+			Kind:       config.PlanEntryTool,
+			Name:       tool.Name,
+			Version:    version,
+			ScriptHash: toolScriptHash(content, steps),
+			Scripts:    toolScriptSources(tool),
+		})
+	}
+	
+	for _, env := range environments {
+		setup, err := ie.scriptContent(env.SetupInline, env.SetupScript)
+		if err != nil {
+			return config.ExecutionPlan{}, fmt.Errorf("failed to read %s of %s: %w", setupFilePath(env), env.Name, err)
+		}
+		hash, err := ie.environmentHash(env, setup)
+		if err != nil {
+			return config.ExecutionPlan{}, err
+		}
+		plan.Entries = append(plan.Entries, config.PlanEntry{
+			Kind:       config.PlanEntryEnvironment,
+			Name:       env.Name,
+			ScriptHash: hash,
+			Scripts:    append([]string{scriptSourceName(env.SetupInline, env.SetupScript)}, env.ConfigFiles...),
+		})
+	}
+	return plan, nil
+}
+
+// toolScriptSources names the install script of a tool, or the script of each install step
+func toolScriptSources(tool parser.Tool) []string {
+	if len(tool.Steps) == 0 {
+		return []string{scriptSourceName(tool.InstallInline, tool.InstallScript)}
+	}
+	sources := make([]string, len(tool.Steps))
+	for i, step := range tool.Steps {
+		sources[i] = fmt.Sprintf("%s: %s", step.Label(i), scriptSourceName(step.Run, step.ScriptPath))
+	}
+	return sources
+}
+
+// toolScriptHash hashes the install script of a tool, or the scripts of its install steps, as
+// returned by installScripts
+func toolScriptHash(content []byte, steps [][]byte) string {
+	return contentHash(append([][]byte{content}, steps...)...)
+}
+
+// environmentHash hashes the paths and contents of the setup script and config files of an
+// environment. The setup script is passed in, so the bytes hashed are the bytes that run.
+func (ie *InstallationEngine) environmentHash(env parser.Environment, setup []byte) (string, error) {
+	files := append([]RepositoryFile{{Path: setupFilePath(env), Content: setup}}, ie.configFiles(env)...)
+	var contents [][]byte
+	for _, file := range files {
+		if file.Err != nil {
+			return "", fmt.Errorf("failed to read %s of %s: %w", file.Path, env.Name, file.Err)
+		}
+		contents = append(contents, []byte(file.Path), file.Content)
+	}
+	return contentHash(contents...), nil
+}
+
+// contentHash returns the hex SHA-256 of the contents, each prefixed with its length so
+// different splits of the same bytes hash differently
+func contentHash(contents ...[]byte) string {
+	hash := sha256.New()
+	for _, content := range contents {
+		fmt.Fprintf(hash, "%d:", len(content))
+		hash.Write(content)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ResolvePlan looks up the operations of an exported plan among the repository's tools and
+// environments, in plan order. It fails when an operation is no longer in the repository or
+// when its version or scripts changed since the plan was exported, so a reviewed plan runs
+// exactly as reviewed.
+func (ie *InstallationEngine) ResolvePlan(plan config.ExecutionPlan, tools []parser.Tool, environments []parser.Environment) ([]parser.Tool, []parser.Environment, error) {
+	toolsByName := make(map[string]parser.Tool, len(tools))
+	for _, tool := range tools {
+		toolsByName[tool.Name] = tool
+	}
+	environmentsByName := make(map[string]parser.Environment, len(environments))
+	for _, env := range environments {
+		environmentsByName[env.Name] = env
+	}
+	
+	var planTools []parser.Tool
+	var planEnvironments []parser.Environment
+	for _, entry := range plan.Entries {
+		switch entry.Kind {
+		case config.PlanEntryTool:
+			tool, ok := toolsByName[entry.Name]
+			if !ok {
+				return nil, nil, fmt.Errorf("tool %s of the plan is no longer in the repository", entry.Name)
+			}
+			planTools = append(planTools, tool)
+		case config.PlanEntryEnvironment:
+			env, ok := environmentsByName[entry.Name]
+			if !ok {
+				return nil, nil, fmt.Errorf("environment %s of the plan is no longer in the repository", entry.Name)
+			}
+			planEnvironments = append(planEnvironments, env)
+		default:
+			return nil, nil, fmt.Errorf("unknown kind %q of plan entry %s", entry.Kind, entry.Name)
+		}
+	}
+	
+	current, err := ie.BuildPlan(planTools, planEnvironments)
+	if err != nil {
+		return nil, nil, err
+	}
+	if changes := current.Changes(plan); len(changes) > 0 {
+		var lines []string
+		for _, change := range changes {
+			lines = append(lines, change.String())
+		}
+		return nil, nil, fmt.Errorf("the repository changed since the plan was exported, export it again:\n%s", strings.Join(lines, "\n"))
+	}
+	return planTools, planEnvironments, nil
+}
@@ -0,0 +1,137 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Prerequisite is a command BOBA needs before it can clone the repository and run scripts
+type Prerequisite struct {
+	Command string // Executable looked up on PATH
+	Package string // Package providing it, empty when the package manager can't install it
+	Reason  string
+}
+
+// basePrerequisites are needed on every platform
+var basePrerequisites = []Prerequisite{
+	{Command: "git", Package: "git", Reason: "clones and syncs the configuration repository"},
+	{Command: "curl", Package: "curl", Reason: "downloads installers in tool scripts"},
+	{Command: "bash", Package: "bash", Reason: "runs the install scripts"},
+}
+
+// homebrewPrerequisite is the package manager of macOS. The package manager can't install
+// itself: InstallHomebrew runs its installer instead.
+var homebrewPrerequisite = Prerequisite{Command: "brew", Reason: "is the package manager tools are installed with on macOS"}
+
+// MissingPrerequisites returns the prerequisites that are not on PATH
+func (ie *InstallationEngine) MissingPrerequisites() []Prerequisite {
+	return missingPrerequisites(ie.platform, exec.LookPath)
+}
+
+// missingPrerequisites checks the prerequisites of a platform with lookPath
+func missingPrerequisites(platform Platform, lookPath func(file string) (string, error)) []Prerequisite {
+	prerequisites := basePrerequisites
+	if platform.OS == "darwin" || platform.PackageManager == "brew" {
+		prerequisites = append([]Prerequisite{homebrewPrerequisite}, prerequisites...)
+	}
+	
+	var missing []Prerequisite
+	for _, prerequisite := range prerequisites {
+		if prerequisite == homebrewPrerequisite {
+			if _, found := findHomebrew(lookPath, homebrewPrefixes); found {
+				continue
+			}
+		} else if _, err := lookPath(prerequisite.Command); err == nil {
+			continue
+		}
+		missing = append(missing, prerequisite)
+	}
+	return missing
+}
+
+// BootstrapCommands returns the commands that install the missing prerequisites with the
+// system package manager, refreshing its index first. It fails with the manual steps when
+// they can't be installed automatically.
+func (ie *InstallationEngine) BootstrapCommands(missing []Prerequisite) ([][]string, error) {
+	_, sudoErr := exec.LookPath("sudo")
+	return bootstrapCommands(ie.platform, missing, os.Geteuid() == 0, ie.noSudo, sudoErr == nil)
+}
+
+// bootstrapCommands builds the install commands; asRoot, noSudo and hasSudo decide how root
+// privileges are obtained
+func bootstrapCommands(platform Platform, missing []Prerequisite, asRoot, noSudo, hasSudo bool) ([][]string, error) {
+	var packages []string
+	for _, prerequisite := range missing {
+		if prerequisite.Package == "" {
+			return nil, fmt.Errorf("%s must be installed first: install Homebrew from https://brew.sh, then start BOBA again", prerequisite.Command)
+		}
+		packages = append(packages, prerequisite.Package)
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+	
+	install := packageInstallCommand(platform.PackageManager, packages)
+	if install == nil {
+		return nil, fmt.Errorf("no supported package manager found: install %s manually, then start BOBA again", strings.Join(packages, ", "))
+	}
+	commands := [][]string{install}
+	if refresh := packageIndexRefreshCommand(platform.PackageManager); refresh != nil {
+		commands = [][]string{refresh, install}
+	}
+	
+	if !requiresRoot(platform.PackageManager) || asRoot {
+		return commands, nil
+	}
+	if noSudo {
+		return nil, fmt.Errorf("installing %s needs root privileges, and BOBA is set to never use sudo: ask an administrator to install it", strings.Join(packages, ", "))
+	}
+	if !hasSudo {
+		return nil, fmt.Errorf("installing %s needs root privileges and sudo is not available: install it as root, then start BOBA again", strings.Join(packages, ", "))
+	}
+	for i, command := range commands {
+		commands[i] = append([]string{"sudo"}, command...)
+	}
+	return commands, nil
+}
+
+// packageInstallCommand returns the command that installs packages with a package manager
+func packageInstallCommand(packageManager string, packages []string) []string {
+	var command []string
+	switch packageManager {
+	case "apt":
+		command = []string{"apt-get", "install", "-y"}
+	case "dnf":
+		command = []string{"dnf", "install", "-y"}
+	case "yum":
+		command = []string{"yum", "install", "-y"}
+	case "pacman":
+		command = []string{"pacman", "-S", "--noconfirm"}
+	case "zypper":
+		command = []string{"zypper", "--non-interactive", "install"}
+	case "apk":
+		command = []string{"apk", "add"}
+	case "brew":
+		command = []string{"brew", "install"}
+	default:
+		return nil
+	}
+	return append(command, packages...)
+}
+
+// RunBootstrap runs the bootstrap commands attached to the terminal, so sudo can ask for a password
+func RunBootstrap(commands [][]string, stdin io.Reader, stdout, stderr io.Writer) error {
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", strings.Join(args, " "), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"boba/internal/config"
+	"boba/internal/github"
+	"boba/internal/parser"
+)
+
+// Install types recorded in the tool provenance
+const (
+	InstallTypeScript  = "script"  // install.sh from the tool folder
+	InstallTypeInline  = "inline"  // install: script in tool.yaml
+	InstallTypeCatalog = "catalog" // entry in the root boba.yaml catalog
+)
+
+// installType returns how a tool is defined in the repository
+func installType(tool parser.Tool) string {
+	switch {
+	case tool.Catalog:
+		return InstallTypeCatalog
+	case tool.InstallInline != "":
+		return InstallTypeInline
+	default:
+		return InstallTypeScript
+	}
+}
+
+// newProvenance builds the provenance of an install script before it runs
+func (ie *InstallationEngine) newProvenance(tool parser.Tool, scriptContent []byte) *config.ToolProvenance {
+	hash := sha256.Sum256(scriptContent)
+	provenance := &config.ToolProvenance{
+		InstallType:    installType(tool),
+		ScriptHash:     hex.EncodeToString(hash[:]),
+		PackageManager: ie.platform.PackageManager,
+		InstallScope:   ie.InstallScope(tool),
+	}
+	if ie.repoDir != "" {
+		if commit, err := github.HeadCommit(ie.repoDir); err == nil {
+			provenance.RepoCommit = commit
+		}
+	}
+	if tool.ScriptSource != nil && tool.InstallInline == "" {
+		provenance.ScriptSource = tool.ScriptSource.String()
+	}
+	return provenance
+}
+
+// pathExecutables returns the executables currently found in the PATH directories
+func pathExecutables() map[string]bool {
+	executables := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			executables[filepath.Join(dir, entry.Name())] = true
+		}
+	}
+	return executables
+}
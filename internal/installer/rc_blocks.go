@@ -0,0 +1,199 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/parser"
+)
+
+// Kinds of owners of a block in a shell rc file
+const (
+	rcBlockTool        = "tool"
+	rcBlockEnvironment = "environment"
+)
+
+// rcBlockMarkers returns the lines delimiting the block a tool or environment manages in a shell
+// rc file. The script library's boba_rc_block writes the same markers.
+func rcBlockMarkers(kind, name string) (begin, end string) {
+	return fmt.Sprintf("# >>> boba %s %s >>>", kind, name), fmt.Sprintf("# <<< boba %s %s <<<", kind, name)
+}
+
+// errUnclosedRCBlock is returned for a file whose block has a begin marker but no end marker:
+// replacing it would drop the rest of the file
+var errUnclosedRCBlock = errors.New("has a BOBA block without its end marker; fix it by hand")
+
+// replaceRCBlock returns the content with the block between begin and end replaced by the lines,
+// in place, or appended when the content has no such block. No lines removes the block.
+func replaceRCBlock(content []byte, begin, end string, lines []string) ([]byte, error) {
+	var block []string
+	if len(lines) > 0 {
+		block = append(append([]string{begin}, lines...), end)
+	}
+
+	var out []string
+	found, inBlock := false, false
+	if len(content) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			switch {
+			case line == begin && !found:
+				found, inBlock = true, true
+				out = append(out, block...)
+			case inBlock:
+				inBlock = line != end
+			default:
+				out = append(out, line)
+			}
+		}
+	}
+	if inBlock {
+		return nil, fmt.Errorf("%w (%q)", errUnclosedRCBlock, end)
+	}
+	if !found {
+		out = append(out, block...)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+// setRCBlock sets the block of a tool or environment in a file of $HOME to the content, creating
+// the file when needed. The rest of the file is kept as is. It reports whether the file changed.
+func setRCBlock(path, kind, name string, content []byte) (bool, error) {
+	before, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	var lines []string
+	if trimmed := strings.TrimRight(string(content), "\n"); trimmed != "" {
+		lines = strings.Split(trimmed, "\n")
+	}
+	begin, end := rcBlockMarkers(kind, name)
+	after, err := replaceRCBlock(before, begin, end, lines)
+	if err != nil {
+		return false, fmt.Errorf("%s %w", path, err)
+	}
+	if bytes.Equal(before, after) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, after, 0644)
+}
+
+// removeRCBlock removes the block of a tool or environment from a file of $HOME, if it has one
+func removeRCBlock(path, kind, name string) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return setRCBlock(path, kind, name, nil)
+}
+
+// mergeRCFiles writes the config files of an environment declaring merge_rc_files as its blocks in
+// the files of $HOME, instead of leaving the setup script to copy them over. A file another
+// environment owns is left alone. It returns a note for each file it changed or skipped.
+func (ie *InstallationEngine) mergeRCFiles(env parser.Environment) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, path := range env.ConfigFiles {
+		name := filepath.Base(path)
+		if owner, ok := ie.fileOwners[name]; ok && owner != env.Name && owner != config.FileMergeStrategy {
+			notes = append(notes, fmt.Sprintf("~/%s left to %s, which owns it", name, owner))
+			continue
+		}
+		content, err := ie.githubClient.GetRepositoryContents(path)
+		if err != nil {
+			return notes, fmt.Errorf("failed to download %s: %w", path, err)
+		}
+		changed, err := setRCBlock(filepath.Join(home, name), rcBlockEnvironment, env.Name, content)
+		if err != nil {
+			return notes, fmt.Errorf("failed to update ~/%s: %w", name, err)
+		}
+		if changed {
+			notes = append(notes, fmt.Sprintf("~/%s: block of %s updated, the rest of the file kept", name, env.Name))
+		}
+	}
+	return notes, nil
+}
+
+// removeRCBlocks removes the blocks a tool or environment left in the shell rc files of $HOME and
+// returns a note for each file it changed
+func removeRCBlocks(kind, name string, files []string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, file := range files {
+		changed, err := removeRCBlock(filepath.Join(home, file), kind, name)
+		if err != nil {
+			return notes, fmt.Errorf("failed to update ~/%s: %w", file, err)
+		}
+		if changed {
+			notes = append(notes, fmt.Sprintf("~/%s: block of %s removed", file, name))
+		}
+	}
+	return notes, nil
+}
+
+// Kind and name of the block putting the usage tracking shims first on PATH
+const (
+	rcBlockUsage     = "usage"
+	rcBlockUsageName = "tracking"
+)
+
+// SetShimsPath puts the shims directory first on PATH in the shell rc files of $HOME, or takes it
+// out when enabled is false. The files of zsh, bash and fish that exist are changed, ~/.profile
+// when none does. It returns a note for each file it changed.
+func SetShimsPath(dir string, enabled bool) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{".zshrc", ".bashrc", ".config/fish/config.fish"}
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(home, file)); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	if !enabled {
+		return removeRCBlocks(rcBlockUsage, rcBlockUsageName, append(existing, ".profile"))
+	}
+	if len(existing) == 0 {
+		existing = []string{".profile"}
+	}
+
+	if rel, err := filepath.Rel(home, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		dir = "$HOME/" + rel
+	}
+	var notes []string
+	for _, file := range existing {
+		line := fmt.Sprintf(`export PATH="%s:$PATH"`, dir)
+		if strings.HasSuffix(file, ".fish") {
+			line = fmt.Sprintf(`set -gx PATH "%s" $PATH`, dir)
+		}
+		changed, err := setRCBlock(filepath.Join(home, file), rcBlockUsage, rcBlockUsageName, []byte(line+"\n"))
+		if err != nil {
+			return notes, fmt.Errorf("failed to update ~/%s: %w", file, err)
+		}
+		if changed {
+			notes = append(notes, fmt.Sprintf("~/%s: shims put first on PATH, open a new shell to start tracking", file))
+		}
+	}
+	return notes, nil
+}
@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"boba/internal/parser"
+)
+
+// SatisfiedOutput is the output of a tool or step skipped because its satisfied_when check holds
+const SatisfiedOutput = "skipped (already satisfied)"
+
+// BeginUpdateRun starts a run that updates tools: satisfied_when checks are ignored so the
+// install scripts run again
+func (ie *InstallationEngine) BeginUpdateRun() {
+	ie.BeginRun()
+	ie.updating = true
+}
+
+// RecheckTool evaluates the satisfied_when checks of a tool and of its steps matching this machine
+// after it was installed, even during update runs. checked is false when there is no check.
+func (ie *InstallationEngine) RecheckTool(tool parser.Tool) (checked, satisfied bool) {
+	env := ie.toolEnvironment(tool)
+	checks := []*parser.SatisfiedCheck{tool.SatisfiedWhen}
+	conditionEnv := ie.conditionEnvironment(tool)
+	for _, step := range tool.Steps {
+		if ie.stepSkipReason(step.When, conditionEnv) == "" {
+			checks = append(checks, step.SatisfiedWhen)
+		}
+	}
+	
+	satisfied = true
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+		checked = true
+		if !ie.checkSatisfied(check, env) {
+			satisfied = false
+		}
+	}
+	return checked, checked && satisfied
+}
+
+// isSatisfied reports whether a satisfied_when check allows skipping the install; update runs
+// never skip
+func (ie *InstallationEngine) isSatisfied(check *parser.SatisfiedCheck, env []string) bool {
+	if check == nil || ie.updating || ie.options.DryRun {
+		return false
+	}
+	return ie.checkSatisfied(check, env)
+}
+
+// checkSatisfied reports whether every part of a satisfied_when check holds. The command runs
+// with the environment the tool's scripts receive.
+func (ie *InstallationEngine) checkSatisfied(check *parser.SatisfiedCheck, env []string) bool {
+	for _, path := range check.FileExists {
+		if _, err := os.Stat(expandTrackDir(path)); err != nil {
+			return false
+		}
+	}
+	if check.Command == "" {
+		return true
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", check.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/bash", "-c", check.Command)
+	}
+	cmd.Env = env
+	cmd.Dir = ie.tempDir
+	return cmd.Run() == nil
+}
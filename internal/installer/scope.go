@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"boba/internal/parser"
+)
+
+// Install scopes a tool manifest can prefer through `scope:`
+const (
+	ScopeUser   = "user"   // Installed in the user's home directory, without elevated privileges
+	ScopeSystem = "system" // Installed system-wide for every user of the machine (default)
+)
+
+// scopeEnv tells the scripts which scope to install in, and is matched by `when: scope:`
+const scopeEnv = "BOBA_INSTALL_SCOPE"
+
+// canInstallSystemWide reports whether system-wide installs are possible: running as root or
+// with sudo available
+func canInstallSystemWide() bool {
+	if os.Geteuid() == 0 {
+		return true
+	}
+	_, err := exec.LookPath("sudo")
+	return err == nil
+}
+
+// checkScope validates the scope a manifest prefers
+func checkScope(scope string) error {
+	switch scope {
+	case "", ScopeUser, ScopeSystem:
+		return nil
+	default:
+		return fmt.Errorf("unknown scope '%s' (expected user or system)", scope)
+	}
+}
+
+// InstallScope returns the scope the scripts of a tool install in: the one its manifest prefers,
+// system-wide by default, or the user's home when system-wide installs are not possible or
+// BOBA is set to never use sudo
+func (ie *InstallationEngine) InstallScope(tool parser.Tool) string {
+	if tool.Scope == ScopeUser || !ie.systemWide || ie.noSudo {
+		return ScopeUser
+	}
+	return ScopeSystem
+}
+
+// scopeEnvironment returns the BOBA_INSTALL_SCOPE and BOBA_INSTALL_PREFIX variables of a tool:
+// the scope to install in and its conventional prefix, ~/.local or /usr/local
+func (ie *InstallationEngine) scopeEnvironment(tool parser.Tool) []string {
+	scope := ie.InstallScope(tool)
+	prefix := "/usr/local"
+	if scope == ScopeUser {
+		if home, err := os.UserHomeDir(); err == nil {
+			prefix = filepath.Join(home, ".local")
+		}
+	}
+	return []string{
+		fmt.Sprintf("%s=%s", scopeEnv, scope),
+		fmt.Sprintf("BOBA_INSTALL_PREFIX=%s", prefix),
+	}
+}
+
+// InstalledScope is where the executable of an installed tool was found
+type InstalledScope struct {
+	Scope string // ScopeUser under the user's home directory, ScopeSystem elsewhere
+	Path  string
+}
+
+// FindInstalledScope finds the executable of a tool on PATH and reports whether it is installed
+// for the current user only or system-wide, shared with the other users of the machine
+func (ie *InstallationEngine) FindInstalledScope(tool parser.Tool) (InstalledScope, bool) {
+	path, found := lookupTool(tool)
+	if !found {
+		return InstalledScope{}, false
+	}
+	return InstalledScope{Scope: pathScope(path), Path: path}, true
+}
+
+// pathScope classifies an executable path: under the home directory is a user install
+func pathScope(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ScopeSystem
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ScopeUser
+	}
+	return ScopeSystem
+}
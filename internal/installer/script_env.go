@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/log"
+)
+
+// EnvironmentPolicy controls which parent environment variables are passed to scripts
+type EnvironmentPolicy struct {
+	Minimal bool              // Only pass essential variables, BOBA_* variables and the allowlist
+	Allow   []string          // Additional variables passed in minimal mode (supports trailing * wildcards)
+	Deny    []string          // Variables always removed from the script environment (supports trailing * wildcards)
+	Set     map[string]string // Variables set in every script run, replacing inherited values (e.g. CORP_PROXY)
+}
+
+// essentialEnvironment lists variables scripts need to behave like a normal shell session
+var essentialEnvironment = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LANGUAGE", "LC_*", "TERM", "TZ",
+	"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "PROGRAMFILES",
+}
+
+// SetEnvironmentPolicy sets the policy used to filter the environment inherited by scripts
+func (ie *InstallationEngine) SetEnvironmentPolicy(policy EnvironmentPolicy) {
+	ie.envPolicy = policy
+}
+
+// ApplySettings applies the user's script environment settings, tool parameter values and
+// never-use-sudo mode, and the plan approval policy of the machine
+func (ie *InstallationEngine) ApplySettings(configManager *config.ConfigManager) {
+	cfg := configManager.GetConfig()
+	ie.SetEnvironmentPolicy(EnvironmentPolicy{
+		Minimal: cfg.MinimalScriptEnv,
+		Allow:   cfg.EnvAllowlist,
+		Deny:    cfg.EnvDenylist,
+		Set:     configManager.GetScriptEnv(),
+	})
+	ie.SetParameterValues(configManager.GetToolParameters())
+	
+	// The approval policy comes from the admin-owned policy file, never from config.json
+	policy, err := config.LoadPolicy()
+	if err != nil {
+		log.Warn("Machine policy refused, only approved plans can run and no key approves them", "error", err)
+	}
+	ie.SetApprovalPolicy(policy.RequirePlanApproval, policy.PlanApprovalKeys)
+	
+	ie.SetNoSudo(cfg.NoSudo)
+	ie.SetFileOwners(configManager.GetFileOwners())
+	ie.SetManagedFiles(configManager)
+	ie.SetOperationQueue(configManager)
+	if cfg.PackageManager != "" {
+		ie.platform.PackageManager = cfg.PackageManager
+	}
+}
+
+// GetEnvironmentPolicy returns the current script environment policy
+func (ie *InstallationEngine) GetEnvironmentPolicy() EnvironmentPolicy {
+	return ie.envPolicy
+}
+
+// SetRunVariables sets variables for the next runs only, on top of the policy's variables.
+// Pass nil to go back to the policy's variables.
+func (ie *InstallationEngine) SetRunVariables(vars map[string]string) {
+	ie.runVars = vars
+}
+
+// injectedVariables returns the policy's variables overridden by the run variables
+func (ie *InstallationEngine) injectedVariables() map[string]string {
+	vars := make(map[string]string, len(ie.envPolicy.Set)+len(ie.runVars))
+	for name, value := range ie.envPolicy.Set {
+		vars[name] = value
+	}
+	for name, value := range ie.runVars {
+		vars[name] = value
+	}
+	return vars
+}
+
+// matchesEnvPattern reports whether a variable name matches one of the patterns
+func matchesEnvPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// filterEnvironment applies the policy to a list of KEY=VALUE entries
+func (p EnvironmentPolicy) filterEnvironment(environ []string) []string {
+	var filtered []string
+	for _, entry := range environ {
+		name := entry
+		if idx := strings.Index(entry, "="); idx >= 0 {
+			name = entry[:idx]
+		}
+
+		if matchesEnvPattern(name, p.Deny) {
+			continue
+		}
+
+		if p.Minimal {
+			if !strings.HasPrefix(name, "BOBA_") &&
+				!matchesEnvPattern(name, essentialEnvironment) &&
+				!matchesEnvPattern(name, p.Allow) {
+				continue
+			}
+		}
+
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// inheritedEnvironment returns the parent environment filtered by the engine's policy, followed by
+// the injected variables (sorted by name), which replace inherited variables of the same name
+func (ie *InstallationEngine) inheritedEnvironment() []string {
+	vars := ie.injectedVariables()
+	
+	var env []string
+	for _, entry := range ie.envPolicy.filterEnvironment(os.Environ()) {
+		name, _, _ := strings.Cut(entry, "=")
+		if _, injected := vars[name]; !injected {
+			env = append(env, entry)
+		}
+	}
+	
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	return env
+}
@@ -0,0 +1,146 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ScriptLibraryVersion is the version of the shared bash library exposed to scripts as BOBA_LIB.
+// Bump it whenever a function is added or its behavior changes; manifests declare the minimum
+// version they need with lib_version.
+const ScriptLibraryVersion = 2
+
+// scriptLibraryName is the file name of the shared library written to the temp directory
+const scriptLibraryName = "boba-lib.sh"
+
+// ErrLibraryTooOld is returned when a manifest requires a newer script library than this BOBA provides
+var ErrLibraryTooOld = errors.New("requires a newer BOBA script library")
+
+// scriptLibrary is the shared bash library. Scripts load it with: . "$BOBA_LIB"
+const scriptLibrary = `# boba-lib: shared helpers for BOBA install and environment scripts
+# Load with: . "$BOBA_LIB"
+BOBA_LIB_VERSION=2
+
+# boba_log_info/boba_log_warn/boba_log_error <message...>: prefixed log lines (warnings and errors go to stderr)
+boba_log_info() { printf '[%s] %s\n' "${BOBA_TOOL_NAME:-${BOBA_ENV_NAME:-boba}}" "$*"; }
+boba_log_warn() { printf '[%s] warning: %s\n' "${BOBA_TOOL_NAME:-${BOBA_ENV_NAME:-boba}}" "$*" >&2; }
+boba_log_error() { printf '[%s] error: %s\n' "${BOBA_TOOL_NAME:-${BOBA_ENV_NAME:-boba}}" "$*" >&2; }
+
+# boba_die <message...>: log an error and exit the script
+boba_die() { boba_log_error "$@"; exit 1; }
+
+# boba_has <command>: succeed when the command is on PATH
+boba_has() { command -v "$1" >/dev/null 2>&1; }
+
+# boba_detect_pm: print the system package manager (same detection as BOBA_PACKAGE_MANAGER)
+boba_detect_pm() {
+	if [ -n "${BOBA_PACKAGE_MANAGER:-}" ]; then
+		printf '%s\n' "$BOBA_PACKAGE_MANAGER"
+		return 0
+	fi
+	for pm in brew apt dnf yum pacman zypper apk; do
+		if boba_has "$pm"; then
+			printf '%s\n' "$pm"
+			return 0
+		fi
+	done
+	return 1
+}
+
+# boba_download <url> <[sha256:|sha512:]checksum> <destination>: download and verify a file
+boba_download() {
+	if [ -z "${BOBA_VERIFY:-}" ]; then
+		boba_log_error "BOBA_VERIFY is not available"
+		return 1
+	fi
+	sh "$BOBA_VERIFY" "$@"
+}
+
+# boba_append_once <file> <line>: append the line to the file unless it is already present
+boba_append_once() {
+	mkdir -p "$(dirname "$1")"
+	touch "$1"
+	grep -qxF -- "$2" "$1" || printf '%s\n' "$2" >> "$1"
+}
+
+# boba_rc_block <file>: make the lines read from stdin the block of this tool or environment in the
+# file (e.g. ~/.zshrc), replacing its previous block in place and keeping the rest of the file.
+# BOBA removes the block of a tool when it is uninstalled.
+boba_rc_block() {
+	_boba_rc_edit "$1" "$(cat)"
+}
+
+# boba_rc_unblock <file>: remove the block of this tool or environment from the file
+boba_rc_unblock() {
+	[ -f "$1" ] || return 0
+	_boba_rc_edit "$1" ""
+}
+
+_boba_rc_edit() {
+	if [ -n "${BOBA_TOOL_NAME:-}" ]; then
+		_boba_owner="tool $BOBA_TOOL_NAME"
+	else
+		_boba_owner="environment ${BOBA_ENV_NAME:-boba}"
+	fi
+	mkdir -p "$(dirname "$1")"
+	touch "$1"
+	BOBA_RC_BEGIN="# >>> boba $_boba_owner >>>" BOBA_RC_END="# <<< boba $_boba_owner <<<" BOBA_RC_BLOCK="$2" awk '
+		function block() {
+			if (!done && ENVIRON["BOBA_RC_BLOCK"] != "") {
+				print ENVIRON["BOBA_RC_BEGIN"]; print ENVIRON["BOBA_RC_BLOCK"]; print ENVIRON["BOBA_RC_END"]
+			}
+			done = 1
+		}
+		!done && $0 == ENVIRON["BOBA_RC_BEGIN"] { inside = 1; block(); next }
+		inside { if ($0 == ENVIRON["BOBA_RC_END"]) inside = 0; next }
+		{ print }
+		END {
+			if (inside) {
+				print "boba: " FILENAME " has a BOBA block without its end marker; fix it by hand" > "/dev/stderr"
+				exit 1
+			}
+			block()
+		}
+	' "$1" > "$1.boba-tmp" && cat "$1.boba-tmp" > "$1"
+	_boba_status=$?
+	rm -f "$1.boba-tmp"
+	return $_boba_status
+}
+
+# boba_require_lib <version>: fail when the library is older than the given version
+boba_require_lib() {
+	if [ "$BOBA_LIB_VERSION" -lt "$1" ]; then
+		boba_die "requires BOBA script library version $1 (have $BOBA_LIB_VERSION); update BOBA"
+	fi
+}
+`
+
+// ensureScriptLibrary writes the shared library to the temp directory
+// and returns its path, or an empty string if it could not be written
+func (ie *InstallationEngine) ensureScriptLibrary() string {
+	libPath, err := WriteScriptLibrary(ie.tempDir)
+	if err != nil {
+		return ""
+	}
+	return libPath
+}
+
+// WriteScriptLibrary writes the shared library to dir and returns its path, for scripts run
+// outside the engine (e.g. in a preview container) to get the same BOBA_LIB
+func WriteScriptLibrary(dir string) (string, error) {
+	libPath := filepath.Join(dir, scriptLibraryName)
+	if err := os.WriteFile(libPath, []byte(scriptLibrary), 0644); err != nil {
+		return "", err
+	}
+	return libPath, nil
+}
+
+// checkLibraryVersion returns ErrLibraryTooOld when the manifest requires a newer library
+func checkLibraryVersion(name string, required int) error {
+	if required > ScriptLibraryVersion {
+		return fmt.Errorf("%s %w (lib_version %d, this BOBA provides %d); update BOBA", name, ErrLibraryTooOld, required, ScriptLibraryVersion)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scriptWaitDelay is how long the output of a script is still read once it exited: a daemon it
+// started in the background keeps the pipes open, and must not hold up the run
+const scriptWaitDelay = 2 * time.Second
+
+// runScriptCommand runs a script until it exits or its context ends, and returns its output
+// (stderr lines prefixed with "STDERR: ") and exit code. When the context ends, the whole process
+// group of the script is killed and ErrTimeout returned. Any other error means it didn't start.
+func runScriptCommand(ctx context.Context, cmd *exec.Cmd) (string, int, error) {
+	output := &scriptOutput{}
+	stdout := &lineWriter{output: output}
+	stderr := &lineWriter{output: output, prefix: "STDERR: "}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = scriptWaitDelay
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return "", 0, err
+	}
+	err := cmd.Wait()
+	stdout.flush()
+	stderr.flush()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output.String(), 0, ErrTimeout
+	}
+	var exitError *exec.ExitError
+	if err != nil && !errors.As(err, &exitError) && !errors.Is(err, exec.ErrWaitDelay) {
+		return output.String(), 0, err
+	}
+	return output.String(), cmd.ProcessState.ExitCode(), nil
+}
+
+// scriptOutput collects the output lines of a script from both of its streams
+type scriptOutput struct {
+	mu      sync.Mutex
+	builder strings.Builder
+}
+
+func (o *scriptOutput) line(prefix, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.builder.WriteString(prefix + line + "\n")
+}
+
+func (o *scriptOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.builder.String()
+}
+
+// lineWriter splits a stream of a script into lines for its scriptOutput
+type lineWriter struct {
+	output  *scriptOutput
+	prefix  string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.output.line(w.prefix, strings.TrimSuffix(string(w.partial[:i]), "\r"))
+		w.partial = w.partial[i+1:]
+	}
+}
+
+// flush adds the last line when the stream didn't end with a newline
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.output.line(w.prefix, string(w.partial))
+		w.partial = nil
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package installer
+
+import "os/exec"
+
+// setProcessGroup keeps the default of killing the script alone on timeout; WaitDelay still stops
+// its children from holding up the run
+func setProcessGroup(cmd *exec.Cmd) {}
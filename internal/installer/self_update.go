@@ -0,0 +1,212 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ChecksumsAssetName is the release asset listing the SHA-256 of every archive, as produced
+// by deploy/scripts/build-all.sh and the release workflow
+const ChecksumsAssetName = "checksums.txt"
+
+// ReleaseAssetName returns the name of the release binary for this platform,
+// as produced by deploy/scripts/build-all.sh
+func ReleaseAssetName() string {
+	name := fmt.Sprintf("boba-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// ReleaseArchiveName returns the name of the release archive holding the binary for this
+// platform: a .zip on Windows, a .tar.gz elsewhere
+func ReleaseArchiveName() string {
+	name := fmt.Sprintf("boba-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		return name + ".zip"
+	}
+	return name + ".tar.gz"
+}
+
+// SelfUpdate downloads the release archive for this platform from the release assets (asset
+// name -> download URL), verifies it against the release checksums and replaces the running
+// executable with the binary inside. A release without checksums is refused.
+func (si *SystemInstaller) SelfUpdate(assets map[string]string) error {
+	archiveName := ReleaseArchiveName()
+	archiveURL, ok := assets[archiveName]
+	if !ok {
+		return fmt.Errorf("the release has no %s archive", archiveName)
+	}
+	checksumsURL, ok := assets[ChecksumsAssetName]
+	if !ok {
+		return fmt.Errorf("the release has no %s: refusing to install an unverified binary", ChecksumsAssetName)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	
+	checksums, err := download(ctx, checksumsURL)
+	if err != nil {
+		return err
+	}
+	expected, ok := parseChecksums(io.LimitReader(checksums, 1<<20))[archiveName]
+	checksums.Close()
+	if !ok {
+		return fmt.Errorf("%s has no checksum for %s", ChecksumsAssetName, archiveName)
+	}
+	
+	// The archive is kept in a temporary file until its checksum is verified
+	archive, err := os.CreateTemp("", "boba-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	
+	body, err := download(ctx, archiveURL)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(archive, hash), body)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("%s: %w (expected %.12s, got %.12s)", archiveName, ErrChecksumMismatch, expected, actual)
+	}
+	
+	return readArchivedBinary(archive, archiveName, func(binary io.Reader) error {
+		return replaceExecutable(si.binaryPath, binary)
+	})
+}
+
+// download starts a GET request and returns the body of a successful response
+func download(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download update: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", filepath.Base(req.URL.Path), resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// parseChecksums reads sha256sum output ("<digest>  <file>", or "<digest> *<file>" in binary
+// mode) into digests by file name
+func parseChecksums(r io.Reader) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// readArchivedBinary finds the binary for this platform in a release archive and passes its
+// content to use
+func readArchivedBinary(archive *os.File, archiveName string, use func(io.Reader) error) error {
+	binaryName := ReleaseAssetName()
+	if strings.HasSuffix(archiveName, ".zip") {
+		info, err := archive.Stat()
+		if err != nil {
+			return err
+		}
+		reader, err := zip.NewReader(archive, info.Size())
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) != binaryName || file.FileInfo().IsDir() {
+				continue
+			}
+			content, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer content.Close()
+			return use(content)
+		}
+		return fmt.Errorf("%s has no %s binary", archiveName, binaryName)
+	}
+	
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	compressed, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", archiveName, err)
+	}
+	defer compressed.Close()
+	reader := tar.NewReader(compressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s has no %s binary", archiveName, binaryName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binaryName {
+			return use(reader)
+		}
+	}
+}
+
+// replaceExecutable atomically replaces the binary at path with the content read from r
+func replaceExecutable(path string, r io.Reader) error {
+	newPath := path + ".new"
+	file, err := os.OpenFile(newPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s (try running with sudo): %w", filepath.Base(path), err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(newPath)
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	
+	// Windows cannot overwrite a running executable, but it can rename it
+	if runtime.GOOS == "windows" {
+		oldPath := path + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(path, oldPath); err != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("failed to move the current binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("failed to replace the current binary: %w", err)
+	}
+	return nil
+}
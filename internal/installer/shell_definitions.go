@@ -0,0 +1,188 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"boba/internal/config"
+)
+
+// Kinds of shell definitions a managed snippet makes
+const (
+	ShellAlias  = "alias"
+	ShellPath   = "path"
+	ShellExport = "export"
+)
+
+// ShellDefinition is an alias, PATH entry or exported variable defined by a block of a tool or
+// environment in a shell rc file, or by a home file an environment wrote
+type ShellDefinition struct {
+	Kind       string `json:"kind"`            // ShellAlias, ShellPath or ShellExport
+	Name       string `json:"name"`            // The alias or variable, or the directory added to PATH
+	Value      string `json:"value,omitempty"` // Empty for a PATH entry
+	Source     string `json:"source"`          // "environment zsh" or "tool nvm"
+	File       string `json:"file"`            // Relative to $HOME
+	Line       int    `json:"line"`
+	Overridden bool   `json:"overridden"` // A later line of the same file defines the alias or variable again
+}
+
+// rcBlockBegin matches the first line of a block, capturing the kind and name of its owner
+var rcBlockBegin = regexp.MustCompile(`^# >>> boba (tool|environment) (\S+) >>>$`)
+
+// ManagedShellDefinitions reads the aliases, PATH entries and exports the blocks of the shell rc
+// files define, and those of the home files environments wrote (managed, as recorded in config.json),
+// with the tool or environment each comes from. Lines the user added outside blocks are left out.
+func ManagedShellDefinitions(managed map[string]config.ManagedFile) ([]ShellDefinition, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files := append([]string{}, config.ShellRCFiles...)
+	var others []string
+	for file := range managed {
+		if !slices.Contains(files, file) {
+			others = append(others, file)
+		}
+	}
+	sort.Strings(others)
+
+	var definitions []ShellDefinition
+	for _, file := range append(files, others...) {
+		content, err := os.ReadFile(filepath.Join(home, file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return definitions, fmt.Errorf("failed to read ~/%s: %w", file, err)
+		}
+		source := ""
+		if record, ok := managed[file]; ok {
+			source = rcBlockEnvironment + " " + record.Environment
+		}
+		definitions = append(definitions, parseShellDefinitions(file, content, source)...)
+	}
+	return definitions, nil
+}
+
+// parseShellDefinitions returns the definitions of a file made inside blocks, and outside of them
+// when the whole file comes from a source
+func parseShellDefinitions(file string, content []byte, source string) []ShellDefinition {
+	var definitions []ShellDefinition
+	last := make(map[string]int) // Index of the last definition of each alias or variable
+	current, end := source, ""
+	for i, line := range splitLines(content) {
+		line = strings.TrimSpace(line)
+		if match := rcBlockBegin.FindStringSubmatch(line); match != nil {
+			current = match[1] + " " + match[2]
+			_, end = rcBlockMarkers(match[1], match[2])
+			continue
+		}
+		if end != "" && line == end {
+			current, end = source, ""
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		for _, definition := range parseShellLine(line) {
+			definition.Source, definition.File, definition.Line = current, file, i+1
+			if definition.Kind != ShellPath {
+				key := definition.Kind + " " + definition.Name
+				if previous, ok := last[key]; ok {
+					definitions[previous].Overridden = true
+				}
+				last[key] = len(definitions)
+			}
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// parseShellLine returns what one line of sh, bash, zsh or fish defines: alias name=value,
+// export NAME=value, set -gx NAME value and fish_add_path. Setting PATH gives its new entries.
+func parseShellLine(line string) []ShellDefinition {
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch command {
+	case "alias":
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok || strings.Contains(name, " ") {
+			// fish: alias name 'value'
+			name, value, ok = strings.Cut(rest, " ")
+		}
+		if !ok || name == "" {
+			return nil
+		}
+		return []ShellDefinition{{Kind: ShellAlias, Name: name, Value: unquote(strings.TrimSpace(value))}}
+	case "export":
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok || name == "" || strings.Contains(name, " ") {
+			return nil
+		}
+		value = unquote(value)
+		if name == "PATH" {
+			return pathEntries(strings.Split(value, ":"))
+		}
+		return []ShellDefinition{{Kind: ShellExport, Name: name, Value: value}}
+	case "set":
+		fields := strings.Fields(rest)
+		exported := false
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			exported = exported || fields[0] == "--export" || (!strings.HasPrefix(fields[0], "--") && strings.Contains(fields[0], "x"))
+			fields = fields[1:]
+		}
+		if !exported || len(fields) == 0 {
+			return nil
+		}
+		if fields[0] == "PATH" || fields[0] == "fish_user_paths" {
+			return pathEntries(fields[1:])
+		}
+		var values []string
+		for _, field := range fields[1:] {
+			values = append(values, unquote(field))
+		}
+		return []ShellDefinition{{Kind: ShellExport, Name: fields[0], Value: strings.Join(values, " ")}}
+	case "fish_add_path":
+		var dirs []string
+		for _, field := range strings.Fields(rest) {
+			if !strings.HasPrefix(field, "-") {
+				dirs = append(dirs, field)
+			}
+		}
+		return pathEntries(dirs)
+	}
+	return nil
+}
+
+// pathEntries returns the directories added to PATH, leaving out the previous value it extends
+func pathEntries(dirs []string) []ShellDefinition {
+	var definitions []ShellDefinition
+	for _, dir := range dirs {
+		dir = unquote(dir)
+		switch dir {
+		case "", "$PATH", "${PATH}", "$fish_user_paths":
+			continue
+		}
+		definitions = append(definitions, ShellDefinition{Kind: ShellPath, Name: dir})
+	}
+	return definitions
+}
+
+// unquote removes the quotes around a shell word
+func unquote(word string) string {
+	if len(word) >= 2 && (word[0] == '"' || word[0] == '\'') && word[len(word)-1] == word[0] {
+		return word[1 : len(word)-1]
+	}
+	return word
+}
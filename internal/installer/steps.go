@@ -0,0 +1,185 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"boba/internal/parser"
+)
+
+// Outcomes of an install step
+const (
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"   // The step condition does not match this machine
+	StepSatisfied = "satisfied" // The satisfied_when check of the step already holds
+	StepNotRun    = "not run"   // A previous step failed
+)
+
+// StepResult is the outcome of one step of a multi-step install
+type StepResult struct {
+	Name     string
+	Status   string
+	Reason   string // Why a skipped step did not match this machine
+	Duration time.Duration
+}
+
+// StepSummary describes the outcome of each step, one per line
+func StepSummary(steps []StepResult) string {
+	lines := make([]string, 0, len(steps))
+	for _, step := range steps {
+		switch step.Status {
+		case StepSucceeded:
+			lines = append(lines, fmt.Sprintf("  ✓ %s (%s)", step.Name, step.Duration.Round(time.Millisecond)))
+		case StepFailed:
+			lines = append(lines, fmt.Sprintf("  ✗ %s", step.Name))
+		case StepSkipped:
+			lines = append(lines, fmt.Sprintf("  ⏭ %s (skipped: %s)", step.Name, step.Reason))
+		case StepSatisfied:
+			lines = append(lines, fmt.Sprintf("  ⏭ %s (%s)", step.Name, SatisfiedOutput))
+		default:
+			lines = append(lines, fmt.Sprintf("  · %s (%s)", step.Name, step.Status))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stepScripts reads the script of every step, inline or from the tool folder, before any of them runs
+func (ie *InstallationEngine) stepScripts(tool parser.Tool) ([][]byte, error) {
+	scripts := make([][]byte, len(tool.Steps))
+	for i, step := range tool.Steps {
+		content, err := ie.toolScriptContent(tool, step.Run, step.ScriptPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Label(i), err)
+		}
+		scripts[i] = content
+	}
+	return scripts, nil
+}
+
+// toolEnvironment returns the environment the scripts of a tool receive, apart from the
+// variables describing the script being run
+func (ie *InstallationEngine) toolEnvironment(tool parser.Tool) []string {
+	return ie.scriptEnvironment(append(append([]string{
+		fmt.Sprintf("BOBA_TOOL_NAME=%s", tool.Name),
+	}, ie.scopeEnvironment(tool)...), ie.parameterEnvironment(tool)...)...)
+}
+
+// conditionEnvironment returns the variables step conditions are evaluated against: the
+// environment the scripts of the tool receive
+func (ie *InstallationEngine) conditionEnvironment(tool parser.Tool) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range ie.toolEnvironment(tool) {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// stepSkipReason returns why a step condition does not match this machine, or "" when it does
+func (ie *InstallationEngine) stepSkipReason(when *parser.StepCondition, env map[string]string) string {
+	if when == nil {
+		return ""
+	}
+	if !matchesValue(when.Platform, ie.platform.OS) {
+		return fmt.Sprintf("platform is %s", ie.platform.OS)
+	}
+	if !matchesValue(when.Arch, ie.platform.Arch) {
+		return fmt.Sprintf("arch is %s", ie.platform.Arch)
+	}
+	if !matchesValue(when.PackageManager, ie.platform.PackageManager) {
+		return fmt.Sprintf("package manager is %s", ie.platform.PackageManager)
+	}
+	if scope := env[scopeEnv]; !matchesValue(when.Scope, scope) {
+		return fmt.Sprintf("install scope is %s", scope)
+	}
+	
+	names := make([]string, 0, len(when.Env))
+	for name := range when.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := env[name]
+		if !ok {
+			return fmt.Sprintf("%s is not set", name)
+		}
+		if value != when.Env[name] {
+			return fmt.Sprintf("%s is %q", name, value)
+		}
+	}
+	return ""
+}
+
+// matchesValue reports whether a condition list is empty or contains the value
+func matchesValue(values parser.ValueList, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// runSteps runs the steps of a tool whose conditions match in order, each in its own temp
+// directory, stopping at the first failure
+func (ie *InstallationEngine) runSteps(tool parser.Tool, scripts [][]byte) *InstallationResult {
+	env := ie.conditionEnvironment(tool)
+	checkEnv := ie.toolEnvironment(tool)
+	result := &InstallationResult{Success: true}
+	var output strings.Builder
+	matched, ran := 0, 0
+	
+	for i, step := range tool.Steps {
+		stepResult := StepResult{Name: step.Label(i)}
+		if result.Success {
+			stepResult.Reason = ie.stepSkipReason(step.When, env)
+		}
+		switch {
+		case !result.Success:
+			stepResult.Status = StepNotRun
+		case stepResult.Reason != "":
+			stepResult.Status = StepSkipped
+			fmt.Fprintf(&output, "── %s: skipped (%s)\n", stepResult.Name, stepResult.Reason)
+		case ie.isSatisfied(step.SatisfiedWhen, checkEnv):
+			matched++
+			stepResult.Status = StepSatisfied
+			fmt.Fprintf(&output, "── %s: %s\n", stepResult.Name, SatisfiedOutput)
+		default:
+			matched++
+			ran++
+			fmt.Fprintf(&output, "── %s\n", stepResult.Name)
+			startTime := time.Now()
+			stepRun := ie.runScriptInTempDir("install", fmt.Sprintf("%s_step%d", tool.FolderName, i+1), scripts[i], func(scriptPath string) *InstallationResult {
+				return ie.executeScriptSecurely(scriptPath, tool)
+			})
+			stepResult.Duration = time.Since(startTime)
+			output.WriteString(stepRun.Output)
+			result.FollowUps = append(result.FollowUps, stepRun.FollowUps...)
+			
+			stepResult.Status = StepSucceeded
+			if !stepRun.Success {
+				stepResult.Status = StepFailed
+				result.Success = false
+				result.ExitCode = stepRun.ExitCode
+				result.TempDir = stepRun.TempDir
+				result.Error = fmt.Errorf("step %q failed: %w", stepResult.Name, stepRun.Error)
+			}
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+	
+	if matched == 0 {
+		result.Success = false
+		result.Error = fmt.Errorf("none of the %d steps of %s matches this machine", len(tool.Steps), tool.Name)
+	}
+	result.Satisfied = result.Success && ran == 0
+	result.Output = output.String()
+	return result
+}
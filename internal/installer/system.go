@@ -9,6 +9,16 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"boba/internal/log"
+)
+
+// InstallTarget is where Install BOBA to System puts the binary
+type InstallTarget string
+
+const (
+	InstallTargetSystem InstallTarget = "system" // /usr/local/bin, for every user; may need sudo
+	InstallTargetUser   InstallTarget = "user"   // ~/.local/bin, for the current user; never needs sudo
 )
 
 // SystemInstaller handles system-level installation of the BOBA binary
@@ -18,6 +28,8 @@ type SystemInstaller struct {
 	backupPath     string
 	zshrcPath      string
 	zshrcBackupPath string
+	target          InstallTarget
+	targetPaths     map[InstallTarget]string // Binary path of each install target
 }
 
 // SystemInstallationResult represents the result of system installation
@@ -26,9 +38,19 @@ type SystemInstallationResult struct {
 	BinaryInstalled bool
 	ZshrcModified   bool
 	BackupCreated   bool
+	BinaryChecksum  string // SHA-256 of the installed binary, recorded for integrity checks
+	Warning         string // Set when the replaced system copy had changed since it was installed
 	Message         string
 	Error           error
 	Duration        time.Duration
+	Removed         []string // What an uninstall removed
+	Kept            []string // What an uninstall intentionally left in place, and why
+}
+
+// UninstallOptions selects what an uninstall removes besides the binary and the shell integration
+type UninstallOptions struct {
+	RemoveState bool   // Also remove the BOBA directory: configuration, credentials, records, caches, clones and logs
+	StateDir    string // The BOBA directory, ~/.boba unless relocated
 }
 
 // NewSystemInstaller creates a new system installer instance
@@ -45,19 +67,83 @@ func NewSystemInstaller() (*SystemInstaller, error) {
 		return nil, fmt.Errorf("failed to get current user: %w", err)
 	}
 	
-	installPath := "/usr/local/bin/boba"
+	targetPaths := map[InstallTarget]string{
+		InstallTargetSystem: "/usr/local/bin/boba",
+		InstallTargetUser:   filepath.Join(currentUser.HomeDir, ".local", "bin", "boba"),
+	}
 	if runtime.GOOS == "windows" {
 		// On Windows, install to a directory in PATH or create one
-		installPath = filepath.Join(os.Getenv("PROGRAMFILES"), "BOBA", "boba.exe")
+		targetPaths[InstallTargetSystem] = filepath.Join(os.Getenv("PROGRAMFILES"), "BOBA", "boba.exe")
+		targetPaths[InstallTargetUser] = filepath.Join(os.Getenv("USERPROFILE"), "bin", "boba.exe")
 	}
 	
-	return &SystemInstaller{
+	si := &SystemInstaller{
 		binaryPath:      execPath,
-		installPath:     installPath,
-		backupPath:      installPath + ".backup",
 		zshrcPath:       filepath.Join(currentUser.HomeDir, ".zshrc"),
 		zshrcBackupPath: filepath.Join(currentUser.HomeDir, ".zshrc.boba.backup"),
-	}, nil
+		targetPaths:     targetPaths,
+	}
+	si.SetTarget(si.defaultTarget())
+	return si, nil
+}
+
+// defaultTarget keeps an existing installation where it is. Otherwise BOBA installs to
+// /usr/local/bin when it can write there, and to ~/.local/bin instead of asking for sudo.
+func (si *SystemInstaller) defaultTarget() InstallTarget {
+	for _, target := range []InstallTarget{InstallTargetSystem, InstallTargetUser} {
+		if _, err := os.Stat(si.targetPaths[target]); err == nil {
+			return target
+		}
+	}
+	if runtime.GOOS != "windows" && !dirWritable(filepath.Dir(si.targetPaths[InstallTargetSystem])) {
+		return InstallTargetUser
+	}
+	return InstallTargetSystem
+}
+
+// Target returns where the binary is installed to
+func (si *SystemInstaller) Target() InstallTarget {
+	return si.target
+}
+
+// SetTarget chooses where the binary is installed to
+func (si *SystemInstaller) SetTarget(target InstallTarget) {
+	si.target = target
+	si.installPath = si.targetPaths[target]
+	si.backupPath = si.installPath + ".backup"
+}
+
+// InstallDirOnPath reports whether the install directory is already on PATH, so the installed
+// binary can be run without the shell integration
+func (si *SystemInstaller) InstallDirOnPath() bool {
+	installDir := filepath.Clean(filepath.Dir(si.installPath))
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && filepath.Clean(dir) == installDir {
+			return true
+		}
+	}
+	return false
+}
+
+// dirWritable reports whether files can be created in dir, which may not exist yet
+func dirWritable(dir string) bool {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+	testFile, err := os.CreateTemp(dir, ".boba_test_write")
+	if err != nil {
+		return false
+	}
+	testFile.Close()
+	os.Remove(testFile.Name())
+	return true
 }
 
 // IsSystemInstalled checks if BOBA is already installed system-wide
@@ -77,22 +163,12 @@ func (si *SystemInstaller) IsSystemInstalled() bool {
 
 // RequiresSudo checks if sudo privileges are needed for installation
 func (si *SystemInstaller) RequiresSudo() bool {
-	if runtime.GOOS == "windows" {
-		return false // Windows handles elevation differently
+	if runtime.GOOS == "windows" || si.target == InstallTargetUser {
+		return false // Windows handles elevation differently, and the user's own directory never needs it
 	}
 	
 	// Check if we can write to the install directory
-	installDir := filepath.Dir(si.installPath)
-	testFile := filepath.Join(installDir, ".boba_test_write")
-	
-	// Try to create a test file
-	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
-		return true // Need sudo
-	}
-	
-	// Clean up test file
-	os.Remove(testFile)
-	return false
+	return !dirWritable(filepath.Dir(si.installPath))
 }
 
 // InstallToSystem installs BOBA binary to system location and sets up shell integration
@@ -122,9 +198,15 @@ func (si *SystemInstaller) InstallToSystem() (*SystemInstallationResult, error)
 		result.Duration = time.Since(startTime)
 		return result, result.Error
 	}
+	result.BinaryChecksum, _ = FileChecksum(si.installPath)
 	
 	result.Success = true
 	result.Message = "BOBA successfully installed to system. Restart your shell or run 'source ~/.zshrc' to use the 'boba' command."
+	if si.target == InstallTargetUser && si.InstallDirOnPath() {
+		result.Message = fmt.Sprintf("BOBA successfully installed to %s for your user, which is already on PATH.", filepath.Dir(si.installPath))
+	} else if si.target == InstallTargetUser {
+		result.Message = fmt.Sprintf("BOBA successfully installed to %s for your user. ~/.zshrc now adds it to PATH: restart your shell or run 'source ~/.zshrc' to use the 'boba' command.", filepath.Dir(si.installPath))
+	}
 	result.Duration = time.Since(startTime)
 	
 	return result, nil
@@ -142,7 +224,7 @@ func (si *SystemInstaller) installBinary() error {
 	// Ensure install directory exists
 	installDir := filepath.Dir(si.installPath)
 	if err := os.MkdirAll(installDir, 0755); err != nil {
-		if si.RequiresSudo() {
+		if si.target != InstallTargetUser && si.RequiresSudo() {
 			return si.installBinaryWithSudo()
 		}
 		return fmt.Errorf("failed to create install directory: %w", err)
@@ -150,7 +232,7 @@ func (si *SystemInstaller) installBinary() error {
 	
 	// Copy binary
 	if err := si.copyBinary(si.binaryPath, si.installPath); err != nil {
-		if si.RequiresSudo() {
+		if si.target != InstallTargetUser && si.RequiresSudo() {
 			return si.installBinaryWithSudo()
 		}
 		return fmt.Errorf("failed to copy binary: %w", err)
@@ -196,8 +278,8 @@ func (si *SystemInstaller) installBinaryWithSudo() error {
 func (si *SystemInstaller) installBinaryWindows() error {
 	// On Windows, we might need to handle UAC elevation
 	// For now, try to install to a user-accessible location
-	userBinDir := filepath.Join(os.Getenv("USERPROFILE"), "bin")
-	si.installPath = filepath.Join(userBinDir, "boba.exe")
+	si.SetTarget(InstallTargetUser)
+	userBinDir := filepath.Dir(si.installPath)
 	
 	// Create directory
 	if err := os.MkdirAll(userBinDir, 0755); err != nil {
@@ -336,10 +418,17 @@ func (si *SystemInstaller) verifyInstallation() error {
 		return fmt.Errorf("binary not found at %s: %w", si.installPath, err)
 	}
 	
+	// The installed copy must be identical to the running binary
+	if expected, err := FileChecksum(si.binaryPath); err == nil {
+		if err := VerifyChecksum(si.installPath, expected); err != nil {
+			return fmt.Errorf("installed binary differs from the running binary: %w", err)
+		}
+	}
+	
 	// Check if binary is accessible via PATH (this might not work immediately due to shell not being reloaded)
 	if _, err := exec.LookPath("boba"); err != nil {
 		// This is expected if shell hasn't been reloaded, so just warn
-		fmt.Printf("Warning: 'boba' command not immediately available in PATH. Restart your shell or run 'source ~/.zshrc'\n")
+		log.Warn("boba is not on PATH yet: restart your shell or run 'source ~/.zshrc'", "path", si.installPath)
 	}
 	
 	// Verify .zshrc was modified
@@ -354,32 +443,97 @@ func (si *SystemInstaller) verifyInstallation() error {
 	return nil
 }
 
-// UninstallFromSystem removes BOBA from system and reverts shell integration
-func (si *SystemInstaller) UninstallFromSystem() (*SystemInstallationResult, error) {
+// UninstallFromSystem removes BOBA from system and reverts shell integration, and with
+// RemoveState the BOBA directory too. The result lists what was removed and what was kept.
+func (si *SystemInstaller) UninstallFromSystem(options UninstallOptions) (*SystemInstallationResult, error) {
 	startTime := time.Now()
 	result := &SystemInstallationResult{}
+	fail := func(err error) (*SystemInstallationResult, error) {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
 	
 	// Remove binary
-	if err := si.removeBinary(); err != nil {
-		result.Error = fmt.Errorf("failed to remove binary: %w", err)
-		result.Duration = time.Since(startTime)
-		return result, result.Error
+	if _, err := os.Stat(si.installPath); err == nil {
+		if err := si.removeBinary(); err != nil {
+			return fail(fmt.Errorf("failed to remove binary: %w", err))
+		}
+		result.Removed = append(result.Removed, si.installPath)
 	}
 	
 	// Restore .zshrc
-	if err := si.restoreShellConfiguration(); err != nil {
-		result.Error = fmt.Errorf("failed to restore shell configuration: %w", err)
-		result.Duration = time.Since(startTime)
-		return result, result.Error
+	restored, err := si.restoreShellConfiguration()
+	if err != nil {
+		return fail(fmt.Errorf("failed to restore shell configuration: %w", err))
+	}
+	if restored {
+		result.Removed = append(result.Removed, fmt.Sprintf("BOBA configuration in %s (restored from %s)", si.zshrcPath, si.zshrcBackupPath))
+	}
+	
+	// BOBA only writes to .zshrc, but a section copied to another shell would still run the removed binary
+	for _, path := range si.otherShellConfigPaths() {
+		removed, err := si.removeBobaSection(path)
+		if err != nil {
+			return fail(fmt.Errorf("failed to clean %s: %w", path, err))
+		}
+		if removed {
+			result.Removed = append(result.Removed, "BOBA configuration in "+path)
+		}
+	}
+	
+	if options.RemoveState {
+		if _, err := os.Stat(si.zshrcBackupPath); err == nil {
+			if err := os.Remove(si.zshrcBackupPath); err != nil {
+				return fail(fmt.Errorf("failed to remove %s: %w", si.zshrcBackupPath, err))
+			}
+			result.Removed = append(result.Removed, si.zshrcBackupPath)
+		}
+		if options.StateDir != "" {
+			if _, err := os.Stat(options.StateDir); err == nil {
+				// The log file is in the BOBA directory
+				log.Close()
+				if err := os.RemoveAll(options.StateDir); err != nil {
+					return fail(fmt.Errorf("failed to remove %s: %w", options.StateDir, err))
+				}
+				result.Removed = append(result.Removed, options.StateDir+" (configuration, credentials, records, caches, clones and logs)")
+			}
+			if _, err := os.Stat(options.StateDir + "-backups"); err == nil {
+				result.Kept = append(result.Kept, options.StateDir+"-backups: archives written by Reset & Wipe, delete them yourself")
+			}
+		}
+	} else {
+		if _, err := os.Stat(si.zshrcBackupPath); err == nil {
+			result.Kept = append(result.Kept, si.zshrcBackupPath+": your .zshrc from before BOBA")
+		}
+		if _, err := os.Stat(options.StateDir); options.StateDir != "" && err == nil {
+			result.Kept = append(result.Kept, options.StateDir+": configuration and credentials, for a reinstall")
+		}
 	}
+	result.Kept = append(result.Kept, "Tools and environments installed by BOBA: they belong to your system now")
 	
 	result.Success = true
 	result.Message = "BOBA successfully uninstalled from system. Restart your shell to complete the removal."
+	if options.RemoveState {
+		result.Message = "BOBA and its data were removed. Quit BOBA and restart your shell to complete the removal."
+	}
 	result.Duration = time.Since(startTime)
 	
 	return result, nil
 }
 
+// otherShellConfigPaths returns the startup files of the supported shells besides .zshrc
+func (si *SystemInstaller) otherShellConfigPaths() []string {
+	home := filepath.Dir(si.zshrcPath)
+	return []string{
+		filepath.Join(home, ".bashrc"),
+		filepath.Join(home, ".bash_profile"),
+		filepath.Join(home, ".profile"),
+		filepath.Join(home, ".zprofile"),
+		filepath.Join(home, ".config", "fish", "config.fish"),
+	}
+}
+
 // removeBinary removes the installed binary
 func (si *SystemInstaller) removeBinary() error {
 	if _, err := os.Stat(si.installPath); err != nil {
@@ -399,28 +553,36 @@ func (si *SystemInstaller) removeBinary() error {
 	return nil
 }
 
-// restoreShellConfiguration restores the original .zshrc
-func (si *SystemInstaller) restoreShellConfiguration() error {
+// restoreShellConfiguration restores the original .zshrc and reports whether it changed it
+func (si *SystemInstaller) restoreShellConfiguration() (bool, error) {
 	// Check if backup exists
 	if _, err := os.Stat(si.zshrcBackupPath); err != nil {
 		// No backup, try to remove BOBA configuration manually
-		return si.removeBobaConfiguration()
+		return si.removeBobaSection(si.zshrcPath)
 	}
 	
 	// Restore from backup
-	return si.copyBinary(si.zshrcBackupPath, si.zshrcPath)
+	return true, si.copyBinary(si.zshrcBackupPath, si.zshrcPath)
 }
 
-// removeBobaConfiguration removes BOBA configuration from .zshrc
-func (si *SystemInstaller) removeBobaConfiguration() error {
-	content, err := os.ReadFile(si.zshrcPath)
+// removeBobaSection removes the BOBA configuration from a shell startup file and reports
+// whether there was one; a missing file has none
+func (si *SystemInstaller) removeBobaSection(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
 	if err != nil {
-		return err
+		return false, err
+	}
+	if !strings.Contains(string(content), "# BOBA CLI Tool Configuration") {
+		return false, nil
 	}
 	
 	lines := strings.Split(string(content), "\n")
 	var newLines []string
 	inBobaSection := false
+	inCompletionBlock := false
 	
 	for _, line := range lines {
 		if strings.Contains(line, "# BOBA CLI Tool Configuration") {
@@ -428,6 +590,16 @@ func (si *SystemInstaller) removeBobaConfiguration() error {
 			continue
 		}
 		
+		// The completion block follows the section, after a blank line, and ends with its fi
+		if strings.Contains(line, "# BOBA completion") {
+			inCompletionBlock = true
+			continue
+		}
+		if inCompletionBlock {
+			inCompletionBlock = strings.TrimSpace(line) != "fi"
+			continue
+		}
+		
 		if inBobaSection && (strings.TrimSpace(line) == "" || (!strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "export") && !strings.HasPrefix(line, "alias"))) {
 			inBobaSection = false
 		}
@@ -437,7 +609,7 @@ func (si *SystemInstaller) removeBobaConfiguration() error {
 		}
 	}
 	
-	return os.WriteFile(si.zshrcPath, []byte(strings.Join(newLines, "\n")), 0644)
+	return true, os.WriteFile(path, []byte(strings.Join(newLines, "\n")), 0644)
 }
 
 // GetInstallationInfo returns information about the current installation
@@ -448,6 +620,7 @@ func (si *SystemInstaller) GetInstallationInfo() map[string]interface{} {
 		"zshrc_path":        si.zshrcPath,
 		"is_installed":      si.IsSystemInstalled(),
 		"requires_sudo":     si.RequiresSudo(),
+		"install_target":    string(si.target),
 		"platform":          runtime.GOOS,
 	}
 	
@@ -1,9 +1,19 @@
 package installer
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -48,6 +58,58 @@ func TestRequiresSudo(t *testing.T) {
 	_ = installer.RequiresSudo()
 }
 
+func TestUserInstallTarget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no sudo")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "boba-build")
+	if err := os.WriteFile(binary, []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	userBin := filepath.Join(dir, "home", ".local", "bin")
+	si := &SystemInstaller{
+		binaryPath: binary,
+		targetPaths: map[InstallTarget]string{
+			InstallTargetSystem: filepath.Join(dir, "missing-root", "usr", "local", "bin", "boba"),
+			InstallTargetUser:   filepath.Join(userBin, "boba"),
+		},
+	}
+	
+	// Nothing installed yet: the writable system directory is the default
+	if target := si.defaultTarget(); target != InstallTargetSystem {
+		t.Errorf("Expected the writable system directory by default, got %s", target)
+	}
+	
+	// The user target never needs sudo, and its directory is created
+	si.SetTarget(InstallTargetUser)
+	if si.RequiresSudo() || si.InstallPath() != filepath.Join(userBin, "boba") {
+		t.Errorf("Expected ~/.local/bin without sudo, got %s (sudo %v)", si.InstallPath(), si.RequiresSudo())
+	}
+	if err := si.installBinary(); err != nil {
+		t.Fatalf("installBinary failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(userBin, "boba")); string(content) != "binary" {
+		t.Errorf("Expected the binary in ~/.local/bin, got %q", content)
+	}
+	
+	// An existing installation stays where it is
+	si.SetTarget(InstallTargetSystem)
+	if target := si.defaultTarget(); target != InstallTargetUser {
+		t.Errorf("Expected the existing user installation to be kept, got %s", target)
+	}
+	
+	si.SetTarget(InstallTargetUser)
+	t.Setenv("PATH", "/usr/bin")
+	if si.InstallDirOnPath() {
+		t.Error("Expected ~/.local/bin not to be on PATH")
+	}
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+userBin+"/")
+	if !si.InstallDirOnPath() {
+		t.Error("Expected ~/.local/bin to be found on PATH")
+	}
+}
+
 func TestGetInstallationInfo(t *testing.T) {
 	installer, err := NewSystemInstaller()
 	if err != nil {
@@ -140,4 +202,148 @@ func containsAt(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boba")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	
+	if err := replaceExecutable(path, strings.NewReader("new")); err != nil {
+		t.Fatalf("replaceExecutable failed: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "new" {
+		t.Errorf("Expected the binary to be replaced, got %q", content)
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Error("Expected the temporary binary to be renamed away")
+	}
+}
+
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows releases are zip archives")
+	}
+	
+	// A release archive holding the binary for this platform, like the release workflow builds
+	var archive bytes.Buffer
+	compressed := gzip.NewWriter(&archive)
+	writer := tar.NewWriter(compressed)
+	writer.WriteHeader(&tar.Header{Name: ReleaseAssetName(), Mode: 0755, Size: int64(len("new binary")), Typeflag: tar.TypeReg})
+	writer.Write([]byte("new binary"))
+	writer.Close()
+	compressed.Close()
+	digest := sha256.Sum256(archive.Bytes())
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(digest[:]), ReleaseArchiveName())
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/archive":
+			w.Write(archive.Bytes())
+		case "/tampered":
+			w.Write(append(archive.Bytes(), 0))
+		case "/checksums":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	
+	path := filepath.Join(t.TempDir(), "boba")
+	if err := os.WriteFile(path, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	si := &SystemInstaller{binaryPath: path}
+	
+	// A release without checksums or with a tampered archive leaves the binary alone
+	if err := si.SelfUpdate(map[string]string{ReleaseArchiveName(): server.URL + "/archive"}); err == nil || !strings.Contains(err.Error(), ChecksumsAssetName) {
+		t.Errorf("Expected a release without checksums to be refused, got %v", err)
+	}
+	err := si.SelfUpdate(map[string]string{ReleaseArchiveName(): server.URL + "/tampered", ChecksumsAssetName: server.URL + "/checksums"})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "old binary" {
+		t.Fatalf("Expected the binary to be kept after a failed update, got %q", content)
+	}
+	
+	if err := si.SelfUpdate(map[string]string{ReleaseArchiveName(): server.URL + "/archive", ChecksumsAssetName: server.URL + "/checksums"}); err != nil {
+		t.Fatalf("SelfUpdate failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "new binary" {
+		t.Errorf("Expected the binary from the archive, got %q", content)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boba")
+	if err := os.WriteFile(path, []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	
+	checksum, err := FileChecksum(path)
+	if err != nil {
+		t.Fatalf("FileChecksum failed: %v", err)
+	}
+	if err := VerifyChecksum(path, checksum); err != nil {
+		t.Errorf("Expected the checksum to match, got %v", err)
+	}
+	
+	os.WriteFile(path, []byte("tampered"), 0755)
+	if err := VerifyChecksum(path, checksum); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch for a modified binary, got %v", err)
+	}
+}
+
+func TestUninstallRemovesState(t *testing.T) {
+	home := t.TempDir()
+	binary := filepath.Join(home, ".local", "bin", "boba")
+	os.MkdirAll(filepath.Dir(binary), 0755)
+	os.WriteFile(binary, []byte("binary"), 0755)
+	section := "\n\n# BOBA CLI Tool Configuration\n# Added by BOBA installer on 2026-01-01 10:00:00\nexport PATH=\"" + filepath.Dir(binary) + ":$PATH\"\nalias dev-setup=\"boba\"\n\n# BOBA completion (if available)\nif command -v boba >/dev/null 2>&1; then\n    :\nfi\n"
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("export EDITOR=vim"+section), 0644)
+	os.WriteFile(filepath.Join(home, ".bashrc"), []byte("alias ll='ls -l'"+section), 0644)
+	stateDir := filepath.Join(home, ".boba")
+	os.MkdirAll(filepath.Join(stateDir, "logs"), 0700)
+	os.WriteFile(filepath.Join(stateDir, "config.json"), []byte("{}"), 0600)
+	os.MkdirAll(stateDir+"-backups", 0700)
+	
+	si := &SystemInstaller{
+		zshrcPath:       filepath.Join(home, ".zshrc"),
+		zshrcBackupPath: filepath.Join(home, ".zshrc.boba.backup"),
+		targetPaths:     map[InstallTarget]string{InstallTargetUser: binary},
+	}
+	si.SetTarget(InstallTargetUser)
+	
+	// Without RemoveState the BOBA directory is kept and reported
+	result, err := si.UninstallFromSystem(UninstallOptions{StateDir: stateDir})
+	if err != nil || !result.Success {
+		t.Fatalf("UninstallFromSystem failed: %v", err)
+	}
+	if _, err := os.Stat(binary); !os.IsNotExist(err) {
+		t.Error("Expected the binary to be removed")
+	}
+	for _, rc := range []string{".zshrc", ".bashrc"} {
+		content, _ := os.ReadFile(filepath.Join(home, rc))
+		if strings.Contains(string(content), "BOBA") || strings.Contains(string(content), "command -v boba") {
+			t.Errorf("Expected the BOBA configuration to be removed from %s, got %q", rc, content)
+		}
+	}
+	if len(result.Removed) != 3 || !strings.Contains(strings.Join(result.Kept, "\n"), stateDir+": ") {
+		t.Errorf("Expected the binary and two shell files removed and the BOBA directory kept, got %v and %v", result.Removed, result.Kept)
+	}
+	
+	result, err = si.UninstallFromSystem(UninstallOptions{RemoveState: true, StateDir: stateDir})
+	if err != nil || !result.Success {
+		t.Fatalf("UninstallFromSystem failed: %v", err)
+	}
+	if _, err := os.Stat(stateDir); !os.IsNotExist(err) {
+		t.Error("Expected the BOBA directory to be removed")
+	}
+	kept := strings.Join(result.Kept, "\n")
+	if len(result.Removed) != 1 || !strings.Contains(kept, "-backups") || !strings.Contains(kept, "Tools and environments") {
+		t.Errorf("Expected only the BOBA directory removed and the reset backups kept, got %v and %v", result.Removed, result.Kept)
+	}
+}
@@ -0,0 +1,93 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// newEngineTempRoot creates the private temp root for an engine instance.
+// os.MkdirTemp creates a fresh directory with a random name and 0700 permissions,
+// so a pre-created directory or symlink planted in a world-writable /tmp cannot be reused.
+func newEngineTempRoot() (string, error) {
+	return os.MkdirTemp("", "boba-installer-")
+}
+
+// ensureRunDir returns the temp directory of the current run, creating it if needed
+func (ie *InstallationEngine) ensureRunDir() (string, error) {
+	if ie.runDir != "" {
+		if info, err := os.Lstat(ie.runDir); err == nil && info.IsDir() {
+			return ie.runDir, nil
+		}
+	}
+	
+	if err := os.MkdirAll(ie.tempRoot, 0700); err != nil {
+		return "", fmt.Errorf("failed to create temp root: %w", err)
+	}
+	runDir, err := os.MkdirTemp(ie.tempRoot, "run-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create run temp directory: %w", err)
+	}
+	ie.runDir = runDir
+	return runDir, nil
+}
+
+// sanitizeTempName keeps temp directory names readable and free of path separators
+func sanitizeTempName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// runScriptInTempDir writes a script into its own temp directory (one per tool and per run)
+// and runs it with that directory as the engine's temp dir. The directory is removed when
+// the script succeeds and kept for debugging when it fails.
+func (ie *InstallationEngine) runScriptInTempDir(kind, name string, scriptContent []byte, run func(scriptPath string) *InstallationResult) *InstallationResult {
+	runDir, err := ie.ensureRunDir()
+	if err != nil {
+		return &InstallationResult{Success: false, Error: err}
+	}
+	
+	scriptDir, err := os.MkdirTemp(runDir, fmt.Sprintf("%s_%s-", kind, sanitizeTempName(name)))
+	if err != nil {
+		return &InstallationResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to create temp directory: %w", err),
+		}
+	}
+	
+	scriptPath := filepath.Join(scriptDir, fmt.Sprintf("%s_%s.sh", kind, sanitizeTempName(name)))
+	if err := os.WriteFile(scriptPath, scriptContent, 0755); err != nil {
+		os.RemoveAll(scriptDir)
+		return &InstallationResult{
+			Success: false,
+			Error:   fmt.Errorf("failed to create %s script file: %w", kind, err),
+		}
+	}
+	
+	// Scripts using the system package manager wait for each other
+	unlock := ie.lockPackageManager(scriptContent)
+	previousTempDir := ie.tempDir
+	ie.tempDir = scriptDir
+	result := run(scriptPath)
+	ie.tempDir = previousTempDir
+	unlock()
+	
+	if result.Success {
+		os.RemoveAll(scriptDir)
+	} else {
+		result.TempDir = scriptDir
+		ie.retainedDirs = append(ie.retainedDirs, scriptDir)
+	}
+	
+	return result
+}
+
+// RetainedTempDirs returns the temp directories kept for debugging after failed scripts
+func (ie *InstallationEngine) RetainedTempDirs() []string {
+	return append([]string(nil), ie.retainedDirs...)
+}
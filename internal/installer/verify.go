@@ -0,0 +1,77 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// verifyHelperName is the file name of the download-and-verify helper written to the temp directory
+const verifyHelperName = "boba-verify.sh"
+
+// verifyHelperScript downloads a file and verifies it against a declared checksum.
+// Usage: "$BOBA_VERIFY" <url> <checksum> <destination>
+// The checksum is a hex digest, optionally prefixed with the algorithm (sha256:, sha512:).
+// The destination is only written when the checksum matches.
+const verifyHelperScript = `#!/bin/sh
+# boba-verify: download a file and verify it against a declared checksum
+set -eu
+
+if [ "$#" -ne 3 ]; then
+	echo "usage: boba-verify <url> <[sha256:|sha512:]checksum> <destination>" >&2
+	exit 2
+fi
+
+url="$1"
+expected="$2"
+dest="$3"
+
+algo="sha256"
+case "$expected" in
+	sha256:*) expected="${expected#sha256:}" ;;
+	sha512:*) algo="sha512"; expected="${expected#sha512:}" ;;
+	*:*) echo "boba-verify: unsupported checksum algorithm in '$expected'" >&2; exit 2 ;;
+esac
+expected=$(printf '%s' "$expected" | tr 'A-F' 'a-f')
+
+tmp="$dest.boba-download.$$"
+trap 'rm -f "$tmp"' EXIT
+
+if command -v curl >/dev/null 2>&1; then
+	curl -fsSL -o "$tmp" "$url"
+elif command -v wget >/dev/null 2>&1; then
+	wget -q -O "$tmp" "$url"
+else
+	echo "boba-verify: curl or wget is required" >&2
+	exit 1
+fi
+
+if command -v "${algo}sum" >/dev/null 2>&1; then
+	actual=$("${algo}sum" "$tmp" | cut -d' ' -f1)
+elif command -v shasum >/dev/null 2>&1; then
+	actual=$(shasum -a "${algo#sha}" "$tmp" | cut -d' ' -f1)
+else
+	echo "boba-verify: ${algo}sum or shasum is required" >&2
+	exit 1
+fi
+
+if [ "$actual" != "$expected" ]; then
+	echo "boba-verify: checksum mismatch for $url" >&2
+	echo "  expected: $expected" >&2
+	echo "  actual:   $actual" >&2
+	exit 1
+fi
+
+mv "$tmp" "$dest"
+trap - EXIT
+echo "boba-verify: verified $dest"
+`
+
+// ensureVerifyHelper writes the download-and-verify helper to the temp directory
+// and returns its path, or an empty string if it could not be written
+func (ie *InstallationEngine) ensureVerifyHelper() string {
+	helperPath := filepath.Join(ie.tempDir, verifyHelperName)
+	if err := os.WriteFile(helperPath, []byte(verifyHelperScript), 0755); err != nil {
+		return ""
+	}
+	return helperPath
+}
@@ -0,0 +1,76 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Working directory modes a manifest can request through `working_dir:`
+const (
+	WorkingDirTemp   = "temp"   // Shared BOBA temp directory (default)
+	WorkingDirRepo   = "repo"   // Root of the local repository clone
+	WorkingDirFolder = "folder" // The tool or environment folder inside the local clone
+	WorkingDirHome   = "home"   // The user's home directory
+)
+
+// SetRepositoryDir sets the path of the local repository clone exposed to scripts as BOBA_REPO_DIR
+func (ie *InstallationEngine) SetRepositoryDir(dir string) {
+	ie.repoDir = dir
+}
+
+// GetRepositoryDir returns the path of the local repository clone, if known
+func (ie *InstallationEngine) GetRepositoryDir() string {
+	return ie.repoDir
+}
+
+// resolveWorkingDir resolves a manifest working directory mode to an absolute path.
+// folder is the manifest folder relative to the repository root (e.g. tools/git) and
+// assetsDir is its staged copy, used for `folder` when there is no local clone.
+func (ie *InstallationEngine) resolveWorkingDir(mode, folder, assetsDir string) (string, error) {
+	switch mode {
+	case "", WorkingDirTemp:
+		return ie.tempDir, nil
+	case WorkingDirHome:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		return homeDir, nil
+	case WorkingDirRepo, WorkingDirFolder:
+		if mode == WorkingDirFolder && ie.repoDir == "" && assetsDir != "" {
+			return assetsDir, nil
+		}
+		if ie.repoDir == "" {
+			return "", fmt.Errorf("working_dir '%s' requires a local clone of the repository", mode)
+		}
+		dir := ie.repoDir
+		if mode == WorkingDirFolder {
+			dir = filepath.Join(ie.repoDir, folder)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("working directory %s does not exist in the local clone", dir)
+		}
+		return dir, nil
+	default:
+		return "", fmt.Errorf("unknown working_dir '%s' (expected temp, repo, folder or home)", mode)
+	}
+}
+
+// repositoryEnvironment returns the BOBA_REPO_DIR/BOBA_SCRIPT_DIR/BOBA_ASSETS_DIR variables for a manifest folder
+func (ie *InstallationEngine) repositoryEnvironment(folder, assetsDir string) []string {
+	var env []string
+	if assetsDir != "" {
+		env = append(env, fmt.Sprintf("BOBA_ASSETS_DIR=%s", assetsDir))
+	}
+	if ie.repoDir == "" {
+		if assetsDir != "" {
+			env = append(env, fmt.Sprintf("BOBA_SCRIPT_DIR=%s", assetsDir))
+		}
+		return env
+	}
+	return append(env,
+		fmt.Sprintf("BOBA_REPO_DIR=%s", ie.repoDir),
+		fmt.Sprintf("BOBA_SCRIPT_DIR=%s", filepath.Join(ie.repoDir, folder)),
+	)
+}
@@ -0,0 +1,203 @@
+// Package log is BOBA's logger. Every record is written to a file under ~/.boba/logs, so a
+// failed run can be investigated afterwards, and the records at or above the console level
+// are also printed to stderr: warnings by default, everything with --verbose, only errors
+// with --quiet. Records are structured: a message followed by key/value pairs.
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Levels of the records, from the most to the least verbose
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+// FileName is the log file in the logs directory; the previous one is kept as FileName.1
+const FileName = "boba.log"
+
+// maxFileSize is the size above which the log file is rotated when it is opened
+const maxFileSize = 5 << 20
+
+var (
+	mu      sync.Mutex
+	console = sinkHandler(os.Stderr, LevelWarn)
+	file    slog.Handler
+	sink    *os.File
+	path    string // Path of sink, which Rotate reopens
+	logger  = slog.New(fanout{})
+)
+
+// Init opens the log file in dir, creating the directory and rotating a file grown too large,
+// and sets the console level. Records are kept on the console only when the file can't be opened.
+func Init(dir string, consoleLevel slog.Level) error {
+	SetConsole(os.Stderr, consoleLevel)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logPath := filepath.Join(dir, FileName)
+	if info, err := os.Stat(logPath); err == nil && info.Size() > maxFileSize {
+		os.Rename(logPath, logPath+".1")
+	}
+	opened, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	
+	mu.Lock()
+	defer mu.Unlock()
+	if sink != nil {
+		sink.Close()
+	}
+	sink = opened
+	path = logPath
+	file = sinkHandler(opened, LevelDebug)
+	return nil
+}
+
+// Rotate moves the log file in dir to FileName.1 whatever its size, e.g. on a weekly schedule,
+// and reopens it when it is the open log file. It reports whether there was anything to rotate.
+func Rotate(dir string) (bool, error) {
+	logPath := filepath.Join(dir, FileName)
+	if info, err := os.Stat(logPath); err != nil || info.Size() == 0 {
+		return false, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := os.Rename(logPath, logPath+".1"); err != nil {
+		return false, fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if sink == nil || path != logPath {
+		return true, nil
+	}
+	opened, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return true, fmt.Errorf("failed to reopen log file: %w", err)
+	}
+	sink.Close()
+	sink = opened
+	file = sinkHandler(opened, LevelDebug)
+	return true, nil
+}
+
+// SetConsole sets where and from which level records are printed; a nil writer turns the
+// console off, e.g. while the terminal UI owns the screen
+func SetConsole(w io.Writer, level slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	if w == nil {
+		console = nil
+		return
+	}
+	console = sinkHandler(w, level)
+}
+
+// Close closes the log file; later records only go to the console
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	file = nil
+	if sink == nil {
+		return nil
+	}
+	err := sink.Close()
+	sink = nil
+	path = ""
+	return err
+}
+
+// Debug logs details useful when investigating a problem, such as file paths
+func Debug(msg string, args ...any) {
+	logger.Debug(msg, args...)
+}
+
+// Info logs the progress of a run
+func Info(msg string, args ...any) {
+	logger.Info(msg, args...)
+}
+
+// Warn logs a problem BOBA works around
+func Warn(msg string, args ...any) {
+	logger.Warn(msg, args...)
+}
+
+// Error logs a failure
+func Error(msg string, args ...any) {
+	logger.Error(msg, args...)
+}
+
+// sinkHandler writes records from level on as text lines ("time=… level=… msg=… key=value")
+func sinkHandler(w io.Writer, level slog.Level) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+}
+
+// fanout passes each record to the console and the file, reading them under the lock so that
+// Init and SetConsole can replace them at any time. wrap replays the attributes and groups
+// added with With and WithGroup on both.
+type fanout struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (f fanout) handlers() []slog.Handler {
+	mu.Lock()
+	defer mu.Unlock()
+	var handlers []slog.Handler
+	for _, handler := range []slog.Handler{console, file} {
+		if handler == nil {
+			continue
+		}
+		if f.wrap != nil {
+			handler = f.wrap(handler)
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers
+}
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range f.handlers() {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range f.handlers() {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return fanout{wrap: func(handler slog.Handler) slog.Handler {
+		if f.wrap != nil {
+			handler = f.wrap(handler)
+		}
+		return handler.WithAttrs(attrs)
+	}}
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	return fanout{wrap: func(handler slog.Handler) slog.Handler {
+		if f.wrap != nil {
+			handler = f.wrap(handler)
+		}
+		return handler.WithGroup(name)
+	}}
+}
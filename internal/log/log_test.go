@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(dir, LevelWarn); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer Close()
+	var console bytes.Buffer
+	SetConsole(&console, LevelWarn)
+	
+	Debug("Saving credentials", "path", "/home/me/.boba/credentials.json")
+	Warn("Failed to save repository URL", "error", "disk full")
+	if strings.Contains(console.String(), "Saving credentials") || !strings.Contains(console.String(), `msg="Failed to save repository URL" error="disk full"`) {
+		t.Errorf("Expected only the warning on the console, got:\n%s", console.String())
+	}
+	
+	// The file gets every record, even with the console off
+	SetConsole(nil, LevelDebug)
+	Error("Tool install failed", "name", "node")
+	Close()
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"level=DEBUG", "path=/home/me/.boba/credentials.json", "level=WARN", `msg="Tool install failed" name=node`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the log file, got:\n%s", want, data)
+		}
+	}
+	if strings.Contains(console.String(), "Tool install failed") {
+		t.Error("Expected nothing on a disabled console")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), maxFileSize+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(dir, LevelWarn); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	Close()
+	if info, err := os.Stat(path + ".1"); err != nil || info.Size() <= maxFileSize {
+		t.Errorf("Expected the large log to be kept as %s.1, got %v", FileName, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("Expected a new empty log file, got %v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if rotated, err := Rotate(dir); rotated || err != nil {
+		t.Errorf("Expected nothing to rotate without a log file, got %v, %v", rotated, err)
+	}
+	if err := Init(dir, LevelWarn); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer Close()
+	SetConsole(nil, LevelWarn)
+	Info("before")
+	if rotated, err := Rotate(dir); !rotated || err != nil {
+		t.Fatalf("Expected the log file to be rotated, got %v, %v", rotated, err)
+	}
+	Info("after")
+	if before, _ := os.ReadFile(path + ".1"); !bytes.Contains(before, []byte("before")) || bytes.Contains(before, []byte("after")) {
+		t.Errorf("Expected the old records in %s.1, got %q", FileName, before)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Contains(after, []byte("after")) {
+		t.Errorf("Expected new records in the reopened file, got %q", after)
+	}
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"boba/internal/github"
+	"gopkg.in/yaml.v3"
+)
+
+// catalogFileName is the optional single-file catalog at the repository root
+const catalogFileName = "boba.yaml"
+
+// catalogFile is a boba.yaml catalog defining tools and environments inline
+type catalogFile struct {
+	Repository   *RepositoryInfo      `yaml:"repository"`
+	Tools        []catalogTool        `yaml:"tools"`
+	Environments []catalogEnvironment `yaml:"environments"`
+}
+
+// definesEntries reports whether the catalog defines tools or environments, replacing the
+// folder layout, rather than only describing the repository
+func (c *catalogFile) definesEntries() bool {
+	return len(c.Tools) > 0 || len(c.Environments) > 0
+}
+
+// catalogTool is a tool entry of the catalog with embedded (install/uninstall) or referenced scripts
+type catalogTool struct {
+	Tool          `yaml:",inline"`
+	InstallPath   string `yaml:"install_script,omitempty"`   // Install script path relative to the repository root
+	UninstallPath string `yaml:"uninstall_script,omitempty"` // Uninstall script path relative to the repository root
+}
+
+// catalogEnvironment is an environment entry of the catalog with embedded or referenced scripts
+type catalogEnvironment struct {
+	Environment `yaml:",inline"`
+	Setup       string   `yaml:"setup,omitempty"`          // Inline setup script
+	Restore     string   `yaml:"restore,omitempty"`        // Inline restore script
+	SetupPath   string   `yaml:"setup_script,omitempty"`   // Setup script path relative to the repository root
+	RestorePath string   `yaml:"restore_script,omitempty"` // Restore script path relative to the repository root
+	Files       []string `yaml:"config_files,omitempty"`   // Config file paths relative to the repository root
+}
+
+// parseCatalog parses the content of a boba.yaml catalog
+func parseCatalog(content []byte) (*catalogFile, error) {
+	var catalog catalogFile
+	if err := yaml.Unmarshal(content, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", catalogFileName, err)
+	}
+	return &catalog, nil
+}
+
+// loadCatalog fetches the root catalog. It returns nil without error when the repository has none.
+func (rp *RepositoryParser) loadCatalog() (*catalogFile, error) {
+	content, err := rp.source.GetRepositoryContents(catalogFileName)
+	if err != nil {
+		if github.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check for %s: %w", catalogFileName, err)
+	}
+	return parseCatalog(content)
+}
+
+// catalogEntryName validates the name of a catalog entry, recording problems in the report
+func catalogEntryName(kind string, index int, name string, seen map[string]bool, report *StructureReport) (string, bool) {
+	entry := fmt.Sprintf("%s[%d]", kind, index)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		report.add(entry, "has no name", fmt.Sprintf("every entry under '%s:' to have a name", kind))
+		return "", false
+	}
+	if seen[name] {
+		report.add(entry, fmt.Sprintf("duplicate name '%s'", name), "names to be unique within the catalog")
+		return "", false
+	}
+	seen[name] = true
+	return name, true
+}
+
+// toTools converts the catalog tool entries, recording invalid entries in the report
+func (c *catalogFile) toTools(report *StructureReport) []Tool {
+	var tools []Tool
+	seen := make(map[string]bool)
+	for i, entry := range c.Tools {
+		name, ok := catalogEntryName("tools", i, entry.Name, seen, report)
+		if !ok {
+			continue
+		}
+		if entry.InstallInline == "" && entry.InstallPath == "" && entry.Source == "" && len(entry.Steps) == 0 {
+			report.add(fmt.Sprintf("tools[%d] (%s)", i, name), "has no install script",
+				"an inline 'install:' script, an 'install_script:' path relative to the repository root, 'steps:' or a pinned 'source:'")
+			continue
+		}
+		
+		tool := entry.Tool
+		tool.Name = name
+		tool.FolderName = name
+		tool.Catalog = true
+		tool.InstallScript = entry.InstallPath
+		tool.UninstallScript = entry.UninstallPath
+		if err := tool.applySource(); err != nil {
+			report.add(fmt.Sprintf("tools[%d] (%s)", i, name), fmt.Sprintf("has an invalid source: %v", err),
+				"a 'source:' of the form owner/repo/path@ref")
+			continue
+		}
+		tool.MissingUninstall = tool.UninstallScript == ""
+		if err := tool.validateParameters(); err != nil {
+			report.add(fmt.Sprintf("tools[%d] (%s)", i, name), fmt.Sprintf("has invalid parameters: %v", err),
+				"parameters with a unique 'name', a 'type' of string, bool, int or choice, and a valid 'default'")
+			continue
+		}
+		if err := tool.validateSteps(""); err != nil {
+			report.add(fmt.Sprintf("tools[%d] (%s)", i, name), fmt.Sprintf("has invalid steps: %v", err),
+				"steps with either a 'run:' script or a 'script:' path, and no inline 'install:'")
+			continue
+		}
+		if err := tool.validateSatisfiedChecks(); err != nil {
+			report.add(fmt.Sprintf("tools[%d] (%s)", i, name), fmt.Sprintf("has an invalid check: %v", err),
+				"a 'satisfied_when:' with a 'command:' or 'file_exists:' paths")
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// toEnvironments converts the catalog environment entries, recording invalid entries in the report
+func (c *catalogFile) toEnvironments(report *StructureReport) []Environment {
+	var environments []Environment
+	seen := make(map[string]bool)
+	for i, entry := range c.Environments {
+		name, ok := catalogEntryName("environments", i, entry.Name, seen, report)
+		if !ok {
+			continue
+		}
+		if entry.Setup == "" && entry.SetupPath == "" {
+			report.add(fmt.Sprintf("environments[%d] (%s)", i, name), "has no setup script",
+				"an inline 'setup:' script or a 'setup_script:' path relative to the repository root")
+			continue
+		}
+		
+		env := entry.Environment
+		env.Name = name
+		env.FolderName = name
+		env.Catalog = true
+		env.SetupInline = entry.Setup
+		env.RestoreInline = entry.Restore
+		env.SetupScript = entry.SetupPath
+		env.RestoreScript = entry.RestorePath
+		env.ConfigFiles = entry.Files
+		environments = append(environments, env)
+	}
+	return environments
+}
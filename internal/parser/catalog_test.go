@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCatalog = `
+tools:
+  - name: ripgrep
+    description: Fast grep
+    auto_install: true
+    install: |
+      sudo apt-get install -y ripgrep
+  - name: neovim
+    dependencies: [ripgrep]
+    install_script: scripts/neovim.sh
+    uninstall_script: scripts/neovim-uninstall.sh
+  - name: broken
+  - description: no name
+  - name: ripgrep
+    install: echo duplicate
+environments:
+  - name: zsh-dev
+    shell: zsh
+    setup: |
+      cp "$BOBA_REPO_DIR/dotfiles/.zshrc" ~/.zshrc
+    config_files: [dotfiles/.zshrc]
+`
+
+func TestParseCatalog(t *testing.T) {
+	catalog, err := parseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("Expected catalog to parse, got %v", err)
+	}
+	
+	report := &StructureReport{Section: catalogFileName}
+	tools := catalog.toTools(report)
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 valid tools, got %d: %+v", len(tools), tools)
+	}
+	
+	ripgrep := tools[0]
+	if !ripgrep.Catalog || !ripgrep.AutoInstall || !strings.Contains(ripgrep.InstallInline, "apt-get install -y ripgrep") {
+		t.Errorf("Unexpected inline tool: %+v", ripgrep)
+	}
+	neovim := tools[1]
+	if neovim.InstallScript != "scripts/neovim.sh" || neovim.UninstallScript != "scripts/neovim-uninstall.sh" || neovim.InstallInline != "" {
+		t.Errorf("Unexpected referenced tool: %+v", neovim)
+	}
+	if len(neovim.Dependencies) != 1 || neovim.Dependencies[0] != "ripgrep" {
+		t.Errorf("Expected dependencies to be parsed, got %v", neovim.Dependencies)
+	}
+	
+	// Missing script, missing name and duplicate name are reported
+	if len(report.Issues) != 3 {
+		t.Errorf("Expected 3 issues, got %d: %+v", len(report.Issues), report.Issues)
+	}
+	if !strings.Contains(report.String(), "'boba.yaml'") {
+		t.Errorf("Expected report to refer to boba.yaml, got %s", report.String())
+	}
+	
+	envReport := &StructureReport{Section: catalogFileName}
+	environments := catalog.toEnvironments(envReport)
+	if len(environments) != 1 || envReport.HasIssues() {
+		t.Fatalf("Expected 1 valid environment, got %+v (%v)", environments, envReport.Issues)
+	}
+	if environments[0].Shell != "zsh" || environments[0].SetupInline == "" || len(environments[0].ConfigFiles) != 1 {
+		t.Errorf("Unexpected environment: %+v", environments[0])
+	}
+}
+
+func TestParseCatalogInvalid(t *testing.T) {
+	if _, err := parseCatalog([]byte("tools: [")); err == nil {
+		t.Error("Expected invalid YAML to fail")
+	}
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheMaxAge is how long a synced repository listing is used before the source is asked again
+const DefaultCacheMaxAge = 24 * time.Hour
+
+// cacheFileVersion is bumped when the cache layout changes, so files of older releases are refetched
+const cacheFileVersion = 2
+
+// diskCache is the repository listing saved by `boba sync` and every successful fetch
+type diskCache struct {
+	path       string
+	repository string // owner/repo the listing belongs to; a cache of another repository is ignored
+	maxAge     time.Duration
+}
+
+// cacheFile is the on-disk layout of the cache. Each section carries its own fetch time
+// so tools and environments can be refreshed separately.
+type cacheFile struct {
+	Version             int                 `json:"version"`
+	Repository          string              `json:"repository"`
+	Tools               []cachedTool        `json:"tools,omitempty"`
+	ToolsFetched        time.Time           `json:"tools_fetched,omitempty"`
+	Environments        []cachedEnvironment `json:"environments,omitempty"`
+	EnvironmentsFetched time.Time           `json:"environments_fetched,omitempty"`
+}
+
+// cachedTool keeps the internal fields of a tool that its manifest encoding leaves out
+type cachedTool struct {
+	Tool            Tool   `json:"tool"`
+	FolderName      string `json:"folder_name"`
+	InstallScript   string `json:"install_script,omitempty"`
+	UninstallScript string `json:"uninstall_script,omitempty"`
+	Catalog         bool   `json:"catalog,omitempty"`
+	MissingUninstall bool  `json:"missing_uninstall,omitempty"`
+}
+
+// cachedEnvironment keeps the internal fields of an environment that its manifest encoding leaves out
+type cachedEnvironment struct {
+	Environment   Environment `json:"environment"`
+	FolderName    string      `json:"folder_name"`
+	ConfigFiles   []string    `json:"config_files,omitempty"`
+	SetupScript   string      `json:"setup_script,omitempty"`
+	RestoreScript string      `json:"restore_script,omitempty"`
+	SetupInline   string      `json:"setup_inline,omitempty"`
+	RestoreInline string      `json:"restore_inline,omitempty"`
+	Catalog       bool        `json:"catalog,omitempty"`
+}
+
+// SyncResult describes a completed sync
+type SyncResult struct {
+	Tools        int
+	Environments int
+	Path         string
+}
+
+// UseDiskCache makes the parser read the tools and environments of repository from the cache
+// file at path while it is younger than maxAge, and save every successful fetch to it
+func (rp *RepositoryParser) UseDiskCache(path, repository string, maxAge time.Duration) {
+	rp.diskCache = &diskCache{path: path, repository: repository, maxAge: maxAge}
+}
+
+// PreferDiskCache reads the listing from the disk cache while it is younger than maxAge, for
+// commands that only browse the repository
+func (rp *RepositoryParser) PreferDiskCache(maxAge time.Duration) {
+	if rp.diskCache != nil {
+		rp.diskCache.maxAge = maxAge
+	}
+}
+
+// Sync fetches the full tools and environments listing and writes it to the disk cache
+func (rp *RepositoryParser) Sync() (SyncResult, error) {
+	if rp.diskCache == nil {
+		return SyncResult{}, fmt.Errorf("no disk cache configured")
+	}
+	
+	tools, err := rp.fetchTools()
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("failed to fetch tools: %w", err)
+	}
+	environments, err := rp.fetchEnvironments()
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("failed to fetch environments: %w", err)
+	}
+	
+	err = rp.storeDiskCache(func(file *cacheFile) {
+		file.setTools(tools)
+		file.setEnvironments(environments)
+	})
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return SyncResult{Tools: len(tools), Environments: len(environments), Path: rp.diskCache.path}, nil
+}
+
+// cachedTools returns the tools of the disk cache if they are fresh
+func (rp *RepositoryParser) cachedTools() ([]Tool, bool) {
+	file := rp.loadDiskCache()
+	if file == nil || !rp.diskCache.fresh(file.ToolsFetched) {
+		return nil, false
+	}
+	
+	tools := make([]Tool, 0, len(file.Tools))
+	for _, cached := range file.Tools {
+		tool := cached.Tool
+		tool.FolderName = cached.FolderName
+		tool.InstallScript = cached.InstallScript
+		tool.UninstallScript = cached.UninstallScript
+		tool.Catalog = cached.Catalog
+		tool.MissingUninstall = cached.MissingUninstall
+		if err := tool.applySource(); err != nil {
+			return nil, false
+		}
+		tools = append(tools, tool)
+	}
+	return tools, true
+}
+
+// cachedEnvironments returns the environments of the disk cache if they are fresh
+func (rp *RepositoryParser) cachedEnvironments() ([]Environment, bool) {
+	file := rp.loadDiskCache()
+	if file == nil || !rp.diskCache.fresh(file.EnvironmentsFetched) {
+		return nil, false
+	}
+	
+	environments := make([]Environment, 0, len(file.Environments))
+	for _, cached := range file.Environments {
+		env := cached.Environment
+		env.FolderName = cached.FolderName
+		env.ConfigFiles = cached.ConfigFiles
+		env.SetupScript = cached.SetupScript
+		env.RestoreScript = cached.RestoreScript
+		env.SetupInline = cached.SetupInline
+		env.RestoreInline = cached.RestoreInline
+		env.Catalog = cached.Catalog
+		environments = append(environments, env)
+	}
+	return environments, true
+}
+
+// loadDiskCache reads the cache file, or returns nil when there is none for this repository
+func (rp *RepositoryParser) loadDiskCache() *cacheFile {
+	if rp.diskCache == nil {
+		return nil
+	}
+	data, err := os.ReadFile(rp.diskCache.path)
+	if err != nil {
+		return nil
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil
+	}
+	if file.Version != cacheFileVersion || file.Repository != rp.diskCache.repository {
+		return nil
+	}
+	return &file
+}
+
+// storeDiskCache applies update to the cache file and writes it back
+func (rp *RepositoryParser) storeDiskCache(update func(file *cacheFile)) error {
+	if rp.diskCache == nil {
+		return nil
+	}
+	
+	file := rp.loadDiskCache()
+	if file == nil {
+		file = &cacheFile{Version: cacheFileVersion, Repository: rp.diskCache.repository}
+	}
+	update(file)
+	
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode repository cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(rp.diskCache.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	
+	// Write to a temporary file first so a concurrent reader never sees half a listing
+	temp := rp.diskCache.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write repository cache: %w", err)
+	}
+	if err := os.Rename(temp, rp.diskCache.path); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to write repository cache: %w", err)
+	}
+	return nil
+}
+
+// fresh reports whether a section fetched at fetched can still be used
+func (dc *diskCache) fresh(fetched time.Time) bool {
+	return !fetched.IsZero() && time.Since(fetched) < dc.maxAge
+}
+
+// setTools replaces the cached tools
+func (file *cacheFile) setTools(tools []Tool) {
+	file.Tools = make([]cachedTool, 0, len(tools))
+	for _, tool := range tools {
+		file.Tools = append(file.Tools, cachedTool{
+			Tool:            tool,
+			FolderName:      tool.FolderName,
+			InstallScript:   tool.InstallScript,
+			UninstallScript: tool.UninstallScript,
+			Catalog:         tool.Catalog,
+			MissingUninstall: tool.MissingUninstall,
+		})
+	}
+	file.ToolsFetched = time.Now()
+}
+
+// setEnvironments replaces the cached environments
+func (file *cacheFile) setEnvironments(environments []Environment) {
+	file.Environments = make([]cachedEnvironment, 0, len(environments))
+	for _, env := range environments {
+		file.Environments = append(file.Environments, cachedEnvironment{
+			Environment:   env,
+			FolderName:    env.FolderName,
+			ConfigFiles:   env.ConfigFiles,
+			SetupScript:   env.SetupScript,
+			RestoreScript: env.RestoreScript,
+			SetupInline:   env.SetupInline,
+			RestoreInline: env.RestoreInline,
+			Catalog:       env.Catalog,
+		})
+	}
+	file.EnvironmentsFetched = time.Now()
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parameter types of tool manifests
+const (
+	ParameterString = "string"
+	ParameterBool   = "bool"
+	ParameterInt    = "int"
+	ParameterChoice = "choice"
+)
+
+// Parameter is a value the user chooses when installing a tool, such as a JDK distribution or
+// version, passed to the scripts as BOBA_PARAM_<NAME> and as a --name=value argument
+type Parameter struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Type        string   `yaml:"type,omitempty" json:"type,omitempty"`       // string (default), bool, int or choice
+	Default     string   `yaml:"default,omitempty" json:"default,omitempty"` // Value used until the user chooses another one
+	Choices     []string `yaml:"choices,omitempty" json:"choices,omitempty"` // Allowed values of a choice parameter
+}
+
+// Kind returns the parameter type, a parameter listing choices being a choice parameter
+func (p Parameter) Kind() string {
+	if p.Type == "" {
+		if len(p.Choices) > 0 {
+			return ParameterChoice
+		}
+		return ParameterString
+	}
+	return p.Type
+}
+
+// EnvName returns the environment variable the value is passed in
+func (p Parameter) EnvName() string {
+	return "BOBA_PARAM_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, p.Name)
+}
+
+// Validate checks a value against the parameter type
+func (p Parameter) Validate(value string) error {
+	switch p.Kind() {
+	case ParameterString:
+		return nil
+	case ParameterBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false, got %q", p.Name, value)
+		}
+	case ParameterInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", p.Name, value)
+		}
+	case ParameterChoice:
+		for _, choice := range p.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got %q", p.Name, strings.Join(p.Choices, ", "), value)
+	default:
+		return fmt.Errorf("%s has unknown type %q (string, bool, int or choice)", p.Name, p.Type)
+	}
+	return nil
+}
+
+// defaultValue returns the default, or the zero value of the type when the manifest sets none
+func (p Parameter) defaultValue() string {
+	if p.Default != "" {
+		return p.Default
+	}
+	switch p.Kind() {
+	case ParameterBool:
+		return "false"
+	case ParameterInt:
+		return "0"
+	case ParameterChoice:
+		if len(p.Choices) > 0 {
+			return p.Choices[0]
+		}
+	}
+	return ""
+}
+
+// validateParameters checks the parameter declarations of a manifest
+func (t Tool) validateParameters() error {
+	seen := make(map[string]bool)
+	for _, param := range t.Parameters {
+		if param.Name == "" {
+			return fmt.Errorf("a parameter has no name")
+		}
+		if seen[param.EnvName()] {
+			return fmt.Errorf("parameter %s is declared twice", param.Name)
+		}
+		seen[param.EnvName()] = true
+		if param.Kind() == ParameterChoice && len(param.Choices) == 0 {
+			return fmt.Errorf("choice parameter %s lists no choices", param.Name)
+		}
+		if err := param.Validate(param.defaultValue()); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+// ParameterValues returns the value of each parameter: the chosen one when valid, the default otherwise
+func (t Tool) ParameterValues(chosen map[string]string) map[string]string {
+	values := make(map[string]string, len(t.Parameters))
+	for _, param := range t.Parameters {
+		value, ok := chosen[param.Name]
+		if !ok || param.Validate(value) != nil {
+			value = param.defaultValue()
+		}
+		values[param.Name] = value
+	}
+	return values
+}
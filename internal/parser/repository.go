@@ -3,7 +3,10 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,30 +18,135 @@ import (
 type Tool struct {
 	Name         string   `yaml:"name" json:"name"`
 	Description  string   `yaml:"description" json:"description"`
+	Tags         []string `yaml:"tags,omitempty" json:"tags,omitempty"`               // Keywords matched by boba search, e.g. editor or database
 	Version      string   `yaml:"version,omitempty" json:"version,omitempty"`
 	AutoInstall  bool     `yaml:"auto_install" json:"auto_install"`
 	Dependencies []string `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+	Priority     int      `yaml:"priority,omitempty" json:"priority,omitempty"`       // Higher priorities install first, even without dependency edges
 	Homepage     string   `yaml:"homepage,omitempty" json:"homepage,omitempty"`
+	WorkingDir   string   `yaml:"working_dir,omitempty" json:"working_dir,omitempty"` // temp (default), repo, folder or home
+	TrackDirs    []string `yaml:"track_dirs,omitempty" json:"track_dirs,omitempty"`   // Directories snapshotted to record the files the install creates
+	LibVersion   int      `yaml:"lib_version,omitempty" json:"lib_version,omitempty"` // Minimum BOBA_LIB script library version the scripts need
+	MinBobaVersion string `yaml:"min_boba_version,omitempty" json:"min_boba_version,omitempty"` // Minimum BOBA release that can run the scripts
+	Scope        string   `yaml:"scope,omitempty" json:"scope,omitempty"`             // Preferred install scope: user or system (default)
+	
+	// Package the tool is published as, used to look up security advisories for the installed version
+	Advisory *AdvisoryPackage `yaml:"advisory,omitempty" json:"advisory,omitempty"`
+	
+	// Tool folder of another repository to take the scripts from, pinned to a ref (owner/repo/path@ref)
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+	
+	// Values chosen on install and passed to the scripts, e.g. a JDK distribution or version
+	Parameters []Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	
+	// Install steps run in order when their conditions match, used instead of install.sh
+	Steps []Step `yaml:"steps,omitempty" json:"steps,omitempty"`
+	
+	// Check that skips the install when it already holds, e.g. a binary or config file exists
+	SatisfiedWhen *SatisfiedCheck `yaml:"satisfied_when,omitempty" json:"satisfied_when,omitempty"`
+	
+	// Inline scripts, used instead of install.sh/uninstall.sh for trivial tools
+	InstallInline   string `yaml:"install,omitempty" json:"install,omitempty"`
+	UninstallInline string `yaml:"uninstall,omitempty" json:"uninstall,omitempty"`
+	
+	// Uninstallable is set to false for tools that genuinely cannot be removed
+	Uninstallable *bool `yaml:"uninstallable,omitempty" json:"uninstallable,omitempty"`
 	
 	// Internal fields
 	FolderName      string `yaml:"-" json:"-"`
 	InstallScript   string `yaml:"-" json:"-"`
 	UninstallScript string `yaml:"-" json:"-"`
+	Catalog         bool   `yaml:"-" json:"-"` // Defined in the root boba.yaml catalog rather than a tools/<name>/ folder
+	MissingUninstall bool  `yaml:"-" json:"-"` // The repository has no uninstall script for the tool
+	ScriptSource    *ScriptSource `yaml:"-" json:"-"` // Parsed Source, when the scripts come from another repository
+}
+
+// AdvisoryPackage identifies a tool in the OSV vulnerability database (https://osv.dev)
+type AdvisoryPackage struct {
+	Ecosystem string `yaml:"ecosystem" json:"ecosystem"` // OSV ecosystem, e.g. npm, PyPI, Go, crates.io
+	Package   string `yaml:"package,omitempty" json:"package,omitempty"` // Package name in the ecosystem (defaults to the tool name)
+}
+
+// CanUninstall reports whether the tool has not been marked as impossible to uninstall
+func (t Tool) CanUninstall() bool {
+	return t.Uninstallable == nil || *t.Uninstallable
+}
+
+// HasUninstall reports whether the tool can be uninstalled: it is not marked uninstallable: false
+// and has an inline uninstall or an uninstall script
+func (t Tool) HasUninstall() bool {
+	return t.CanUninstall() && (t.UninstallInline != "" || !t.MissingUninstall)
 }
 
 // Environment represents an environment configuration with its metadata and scripts
 type Environment struct {
 	Name         string   `yaml:"name" json:"name"`
 	Description  string   `yaml:"description" json:"description"`
+	Tags         []string `yaml:"tags,omitempty" json:"tags,omitempty"` // Keywords matched by boba search
 	Shell        string   `yaml:"shell,omitempty" json:"shell,omitempty"` // zsh, bash, fish, etc.
 	AutoApply    bool     `yaml:"auto_apply" json:"auto_apply"`
 	Dependencies []string `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+	LoginShell   bool     `yaml:"login_shell,omitempty" json:"login_shell,omitempty"`             // Run scripts through a login shell (bash -l) so profile PATH entries are available
+	Interactive  bool     `yaml:"interactive_shell,omitempty" json:"interactive_shell,omitempty"` // Also load rc files (bash -i), for tools like nvm and pyenv initialized in .bashrc
+	WorkingDir   string   `yaml:"working_dir,omitempty" json:"working_dir,omitempty"`             // temp (default), repo, folder or home
+	LibVersion   int      `yaml:"lib_version,omitempty" json:"lib_version,omitempty"`             // Minimum BOBA_LIB script library version the scripts need
+	MinBobaVersion string `yaml:"min_boba_version,omitempty" json:"min_boba_version,omitempty"` // Minimum BOBA release that can run the scripts
+	MergeRCFiles bool     `yaml:"merge_rc_files,omitempty" json:"merge_rc_files,omitempty"`       // Write the config files into the files of $HOME as BOBA blocks instead of letting the setup script copy them
 	
 	// Internal fields
 	FolderName    string `yaml:"-" json:"-"`
 	ConfigFiles   []string `yaml:"-" json:"-"` // List of config files (.zshrc, .bashrc, etc.)
 	SetupScript   string `yaml:"-" json:"-"`
 	RestoreScript string `yaml:"-" json:"-"`
+	SetupInline   string `yaml:"-" json:"-"` // Inline setup script, used instead of downloading SetupScript
+	RestoreInline string `yaml:"-" json:"-"` // Inline restore script, used instead of downloading RestoreScript
+	Catalog       bool   `yaml:"-" json:"-"` // Defined in the root boba.yaml catalog rather than an environments/<name>/ folder
+}
+
+// HomeFiles returns the names of the environment's config files in $HOME, e.g. .zshrc for
+// environments/zsh/.zshrc, for the restore point saved before applying it
+func HomeFiles(environments []Environment) []string {
+	var files []string
+	for _, env := range environments {
+		for _, path := range env.ConfigFiles {
+			files = append(files, filepath.Base(path))
+		}
+	}
+	return files
+}
+
+// FileConflict is a home file (.zshrc) that several environments manage
+type FileConflict struct {
+	File         string
+	Environments []string // In the order given to FileConflicts
+}
+
+// FileConflicts returns the home files managed by more than one of the environments, sorted by
+// file. Applying them one after the other would leave the file of the last one. Environments
+// declaring merge_rc_files only manage their own block of a file, so they conflict with an
+// environment copying the file but not with each other.
+func FileConflicts(environments []Environment) []FileConflict {
+	managers := make(map[string][]string)
+	copied := make(map[string]bool)
+	for _, env := range environments {
+		for _, file := range HomeFiles([]Environment{env}) {
+			if !slices.Contains(managers[file], env.Name) {
+				managers[file] = append(managers[file], env.Name)
+			}
+			if !env.MergeRCFiles {
+				copied[file] = true
+			}
+		}
+	}
+	
+	var conflicts []FileConflict
+	for file, names := range managers {
+		if len(names) > 1 && copied[file] {
+			conflicts = append(conflicts, FileConflict{File: file, Environments: names})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].File < conflicts[j].File })
+	return conflicts
 }
 
 // RepositoryContents represents the parsed repository structure
@@ -47,29 +155,86 @@ type RepositoryContents struct {
 	LastFetched time.Time `json:"last_fetched"`
 }
 
+// RepositorySource is where the parser reads the repository from: the GitHub API
+// (*github.GitHubClient) or a directory on disk (*github.LocalRepository)
+type RepositorySource interface {
+	GetRepositoryContents(path string) ([]byte, error)
+	GetDirectoryEntries(path string) ([]github.DirectoryEntry, error)
+	GetFilesRecursive(path string) ([]string, error)
+}
+
 // RepositoryParser handles parsing of repository configuration files
 type RepositoryParser struct {
-	github *github.GitHubClient
-	cache  *RepositoryContents
+	source           RepositorySource
+	cache            *RepositoryContents
+	diskCache        *diskCache                  // Synced listing on disk, read before asking the source
+	structureReports map[string]*StructureReport // Layout problems found by the last fetch, by section
 }
 
 // NewRepositoryParser creates a new repository parser instance
 func NewRepositoryParser(githubClient *github.GitHubClient) *RepositoryParser {
+	if githubClient == nil {
+		return &RepositoryParser{}
+	}
+	return NewRepositoryParserFromSource(githubClient)
+}
+
+// NewRepositoryParserFromSource creates a parser reading the repository from any source
+func NewRepositoryParserFromSource(source RepositorySource) *RepositoryParser {
 	return &RepositoryParser{
-		github: githubClient,
+		source: source,
 	}
 }
 
-// FetchTools fetches and parses all tools from the repository
+// InvalidateCache drops cached tools so the next GetTools call fetches them again
+func (rp *RepositoryParser) InvalidateCache() {
+	rp.cache = nil
+	if rp.diskCache != nil {
+		os.Remove(rp.diskCache.path)
+	}
+}
+
+// FetchTools fetches and parses all tools from the repository, refreshing the disk cache
 func (rp *RepositoryParser) FetchTools() ([]Tool, error) {
-	if rp.github == nil {
+	tools, err := rp.fetchTools()
+	if err == nil {
+		// The disk cache is best effort: a failed write only costs a refetch next time
+		rp.storeDiskCache(func(file *cacheFile) { file.setTools(tools) })
+	}
+	return tools, err
+}
+
+// fetchTools fetches and parses all tools from the repository
+func (rp *RepositoryParser) fetchTools() ([]Tool, error) {
+	if rp.source == nil {
 		return nil, fmt.Errorf("GitHub client not initialized")
 	}
 
+	// A root boba.yaml catalog replaces the folder-per-tool layout
+	catalog, err := rp.loadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	if catalog != nil && catalog.definesEntries() {
+		report := &StructureReport{Section: catalogFileName}
+		tools := catalog.toTools(report)
+		rp.setStructureReport(toolsLayout.dir, report)
+		if len(tools) == 0 && report.HasIssues() {
+			return nil, &StructureError{Report: report}
+		}
+		rp.cache = &RepositoryContents{
+			Tools:       tools,
+			LastFetched: time.Now(),
+		}
+		return tools, nil
+	}
+
 	// Get the tools directory listing
-	toolNames, err := rp.github.GetDirectoryContents("tools")
+	report := &StructureReport{Section: toolsLayout.dir}
+	toolNames, err := rp.listSection(toolsLayout, report)
+	rp.setStructureReport(toolsLayout.dir, report)
 	if err != nil {
-		return nil, fmt.Errorf("cannot find 'tools' directory in your repository: %w", err)
+		return nil, err
 	}
 
 	var tools []Tool
@@ -78,12 +243,17 @@ func (rp *RepositoryParser) FetchTools() ([]Tool, error) {
 	for _, toolName := range toolNames {
 		tool, err := rp.fetchTool(toolName)
 		if err != nil {
-			// Tool doesn't exist or has issues, skip it but log the error
-			fmt.Printf("Warning: Failed to fetch tool %s: %v\n", toolName, err)
+			// Tool doesn't exist or has issues, skip it but record the problem
+			recordFolderIssue(toolsLayout, toolName, err, report)
 			continue
 		}
 		tools = append(tools, tool)
 	}
+	
+	// Nothing usable: report every layout problem instead of an empty list
+	if len(tools) == 0 && report.HasIssues() {
+		return nil, &StructureError{Report: report}
+	}
 
 	// Cache the results
 	rp.cache = &RepositoryContents{
@@ -99,12 +269,15 @@ func (rp *RepositoryParser) fetchTool(toolName string) (Tool, error) {
 	// Try to fetch tool.yaml first, then tool.json
 	toolConfigPath := filepath.Join("tools", toolName, "tool.yaml")
 	
-	configContent, err := rp.github.GetRepositoryContents(toolConfigPath)
+	configContent, err := rp.source.GetRepositoryContents(toolConfigPath)
 	if err != nil {
 		// Try JSON format
 		toolConfigPath = filepath.Join("tools", toolName, "tool.json")
-		configContent, err = rp.github.GetRepositoryContents(toolConfigPath)
+		configContent, err = rp.source.GetRepositoryContents(toolConfigPath)
 		if err != nil {
+			if github.IsNotFound(err) {
+				err = errManifestNotFound
+			}
 			return Tool{}, fmt.Errorf("failed to fetch tool config for %s: %w", toolName, err)
 		}
 	}
@@ -125,6 +298,30 @@ func (rp *RepositoryParser) fetchTool(toolName string) (Tool, error) {
 	tool.FolderName = toolName
 	tool.InstallScript = filepath.Join("tools", toolName, "install.sh")
 	tool.UninstallScript = filepath.Join("tools", toolName, "uninstall.sh")
+	if err := tool.applySource(); err != nil {
+		return Tool{}, fmt.Errorf("invalid source for %s: %w", toolName, err)
+	}
+	if err := tool.validateParameters(); err != nil {
+		return Tool{}, fmt.Errorf("invalid parameters for %s: %w", toolName, err)
+	}
+	if err := tool.validateSteps(filepath.Join("tools", toolName)); err != nil {
+		return Tool{}, fmt.Errorf("invalid steps for %s: %w", toolName, err)
+	}
+	if err := tool.validateSatisfiedChecks(); err != nil {
+		return Tool{}, fmt.Errorf("invalid satisfied_when for %s: %w", toolName, err)
+	}
+	
+	// Known up front, so the tools list can tell which tools can't be uninstalled
+	if tool.ScriptSource == nil && tool.UninstallInline == "" {
+		if entries, err := rp.source.GetDirectoryEntries(filepath.Join("tools", toolName)); err == nil {
+			tool.MissingUninstall = true
+			for _, entry := range entries {
+				if entry.Name == "uninstall.sh" {
+					tool.MissingUninstall = false
+				}
+			}
+		}
+	}
 
 	return tool, nil
 }
@@ -134,6 +331,13 @@ func (rp *RepositoryParser) GetTools() ([]Tool, error) {
 	if rp.cache != nil && time.Since(rp.cache.LastFetched) < 5*time.Minute {
 		return rp.cache.Tools, nil
 	}
+	if tools, ok := rp.cachedTools(); ok {
+		rp.cache = &RepositoryContents{
+			Tools:       tools,
+			LastFetched: time.Now(),
+		}
+		return tools, nil
+	}
 	
 	return rp.FetchTools()
 }
@@ -195,16 +399,51 @@ func (rp *RepositoryParser) GetManualInstallTools() ([]Tool, error) {
 	return manualInstallTools, nil
 }
 
-// FetchEnvironments fetches and parses all environment configurations from the repository
+// FetchEnvironments fetches and parses all environment configurations from the repository,
+// refreshing the disk cache
 func (rp *RepositoryParser) FetchEnvironments() ([]Environment, error) {
-	if rp.github == nil {
+	environments, err := rp.fetchEnvironments()
+	if err == nil {
+		rp.storeDiskCache(func(file *cacheFile) { file.setEnvironments(environments) })
+	}
+	return environments, err
+}
+
+// GetEnvironments returns the environments from the disk cache while it is fresh, or fetches them
+func (rp *RepositoryParser) GetEnvironments() ([]Environment, error) {
+	if environments, ok := rp.cachedEnvironments(); ok {
+		return environments, nil
+	}
+	return rp.FetchEnvironments()
+}
+
+// fetchEnvironments fetches and parses all environment configurations from the repository
+func (rp *RepositoryParser) fetchEnvironments() ([]Environment, error) {
+	if rp.source == nil {
 		return nil, fmt.Errorf("GitHub client not initialized")
 	}
 
+	// A root boba.yaml catalog replaces the folder-per-environment layout
+	catalog, err := rp.loadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	if catalog != nil && catalog.definesEntries() {
+		report := &StructureReport{Section: catalogFileName}
+		environments := catalog.toEnvironments(report)
+		rp.setStructureReport(environmentsLayout.dir, report)
+		if len(environments) == 0 && report.HasIssues() {
+			return nil, &StructureError{Report: report}
+		}
+		return environments, nil
+	}
+
 	// Get the environments directory listing
-	envNames, err := rp.github.GetDirectoryContents("environments")
+	report := &StructureReport{Section: environmentsLayout.dir}
+	envNames, err := rp.listSection(environmentsLayout, report)
+	rp.setStructureReport(environmentsLayout.dir, report)
 	if err != nil {
-		return nil, fmt.Errorf("cannot find 'environments' directory in your repository: %w", err)
+		return nil, err
 	}
 
 	var environments []Environment
@@ -213,12 +452,17 @@ func (rp *RepositoryParser) FetchEnvironments() ([]Environment, error) {
 	for _, envName := range envNames {
 		env, err := rp.fetchEnvironment(envName)
 		if err != nil {
-			// Environment doesn't exist or has issues, skip it but log the error
-			fmt.Printf("Warning: Failed to fetch environment %s: %v\n", envName, err)
+			// Environment doesn't exist or has issues, skip it but record the problem
+			recordFolderIssue(environmentsLayout, envName, err, report)
 			continue
 		}
 		environments = append(environments, env)
 	}
+	
+	// Nothing usable: report every layout problem instead of an empty list
+	if len(environments) == 0 && report.HasIssues() {
+		return nil, &StructureError{Report: report}
+	}
 
 	return environments, nil
 }
@@ -228,12 +472,15 @@ func (rp *RepositoryParser) fetchEnvironment(envName string) (Environment, error
 	// Try to fetch environment.yaml first, then environment.json
 	envConfigPath := filepath.Join("environments", envName, "environment.yaml")
 	
-	configContent, err := rp.github.GetRepositoryContents(envConfigPath)
+	configContent, err := rp.source.GetRepositoryContents(envConfigPath)
 	if err != nil {
 		// Try JSON format
 		envConfigPath = filepath.Join("environments", envName, "environment.json")
-		configContent, err = rp.github.GetRepositoryContents(envConfigPath)
+		configContent, err = rp.source.GetRepositoryContents(envConfigPath)
 		if err != nil {
+			if github.IsNotFound(err) {
+				err = errManifestNotFound
+			}
 			return Environment{}, fmt.Errorf("failed to fetch environment config for %s: %w", envName, err)
 		}
 	}
@@ -259,7 +506,7 @@ func (rp *RepositoryParser) fetchEnvironment(envName string) (Environment, error
 	configFiles := []string{".zshrc", ".bashrc", ".profile", ".bash_profile", ".fishrc"}
 	for _, configFile := range configFiles {
 		configPath := filepath.Join("environments", envName, configFile)
-		if _, err := rp.github.GetRepositoryContents(configPath); err == nil {
+		if _, err := rp.source.GetRepositoryContents(configPath); err == nil {
 			env.ConfigFiles = append(env.ConfigFiles, configPath)
 		}
 	}
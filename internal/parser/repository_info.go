@@ -0,0 +1,22 @@
+package parser
+
+// RepositoryInfo is the repository section of boba.yaml, where maintainers declare what their
+// repository needs and who to contact when it doesn't work. It can be the only section of the
+// file: tools and environments then still come from their folders.
+type RepositoryInfo struct {
+	MinBobaVersion string   `yaml:"min_boba_version,omitempty"`
+	Platforms      []string `yaml:"platforms,omitempty"` // Supported platforms: linux, darwin or windows, optionally with an architecture (linux/arm64)
+	Contact        string   `yaml:"contact,omitempty"`   // Email, URL or chat handle of the maintainers
+}
+
+// RepositoryInfo returns the repository section of boba.yaml, or nil when there is none
+func (rp *RepositoryParser) RepositoryInfo() (*RepositoryInfo, error) {
+	if rp.source == nil {
+		return nil, nil
+	}
+	catalog, err := rp.loadCatalog()
+	if err != nil || catalog == nil {
+		return nil, err
+	}
+	return catalog.Repository, nil
+}
@@ -0,0 +1,395 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"boba/internal/github"
+	"gopkg.in/yaml.v3"
+)
+
+func TestToolInlineScripts(t *testing.T) {
+	manifest := `
+name: jq
+description: JSON processor
+install: |
+  sudo apt-get install -y jq
+  jq --version
+uninstall: sudo apt-get remove -y jq
+`
+	var tool Tool
+	if err := yaml.Unmarshal([]byte(manifest), &tool); err != nil {
+		t.Fatalf("Expected manifest to parse, got %v", err)
+	}
+	if !strings.HasPrefix(tool.InstallInline, "sudo apt-get install -y jq\n") || !strings.Contains(tool.InstallInline, "jq --version") {
+		t.Errorf("Expected multi-line inline install script, got %q", tool.InstallInline)
+	}
+	if tool.UninstallInline != "sudo apt-get remove -y jq" {
+		t.Errorf("Expected inline uninstall script, got %q", tool.UninstallInline)
+	}
+}
+
+func TestFetchFromLocalRepository(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tools/git/tool.yaml":                "name: git\ndescription: Version control\nauto_install: true\n",
+		"tools/git/install.sh":               "echo install\n",
+		"environments/zsh/environment.yaml": "name: zsh\ndescription: Shell\nshell: zsh\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	
+	rp := NewRepositoryParserFromSource(github.NewLocalRepository(dir))
+	
+	tools, err := rp.FetchTools()
+	if err != nil {
+		t.Fatalf("Expected tools, got %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "git" || !tools[0].AutoInstall {
+		t.Errorf("Expected the git tool, got %+v", tools)
+	}
+	
+	environments, err := rp.FetchEnvironments()
+	if err != nil {
+		t.Fatalf("Expected environments, got %v", err)
+	}
+	if len(environments) != 1 || environments[0].Name != "zsh" {
+		t.Errorf("Expected the zsh environment, got %+v", environments)
+	}
+	
+	// Edits on disk are picked up once the cache is invalidated
+	if err := os.WriteFile(filepath.Join(dir, "tools", "git", "tool.yaml"), []byte("name: git\ndescription: Edited\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit tool.yaml: %v", err)
+	}
+	rp.InvalidateCache()
+	tools, err = rp.GetTools()
+	if err != nil || len(tools) != 1 || tools[0].Description != "Edited" {
+		t.Errorf("Expected the edited tool after invalidating the cache, got %+v, %v", tools, err)
+	}
+}
+
+func TestRepositoryInfo(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"boba.yaml":           "repository:\n  min_boba_version: 1.2.0\n  platforms: [linux, darwin/arm64]\n  contact: platform-team@example.com\n",
+		"tools/git/tool.yaml": "name: git\ndescription: Version control\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	
+	rp := NewRepositoryParserFromSource(github.NewLocalRepository(dir))
+	info, err := rp.RepositoryInfo()
+	if err != nil || info == nil {
+		t.Fatalf("Expected repository info, got %+v, %v", info, err)
+	}
+	if info.MinBobaVersion != "1.2.0" || len(info.Platforms) != 2 || info.Contact != "platform-team@example.com" {
+		t.Errorf("Unexpected repository info: %+v", info)
+	}
+	
+	// A boba.yaml with only a repository section keeps the folder layout
+	tools, err := rp.FetchTools()
+	if err != nil || len(tools) != 1 || tools[0].Name != "git" {
+		t.Errorf("Expected the git tool from its folder, got %+v, %v", tools, err)
+	}
+	
+	if info, err := NewRepositoryParserFromSource(github.NewLocalRepository(t.TempDir())).RepositoryInfo(); err != nil || info != nil {
+		t.Errorf("Expected no repository info without boba.yaml, got %+v, %v", info, err)
+	}
+}
+
+func TestParseScriptSource(t *testing.T) {
+	source, err := ParseScriptSource("acme/boba-tools/tools/ripgrep@3f2c1a9")
+	if err != nil {
+		t.Fatalf("Expected the source to parse, got %v", err)
+	}
+	if *source != (ScriptSource{Owner: "acme", Repo: "boba-tools", Path: "tools/ripgrep", Ref: "3f2c1a9"}) {
+		t.Errorf("Unexpected source: %+v", source)
+	}
+	if source.String() != "acme/boba-tools/tools/ripgrep@3f2c1a9" {
+		t.Errorf("Expected the source to round-trip, got %s", source)
+	}
+	
+	if source, err := ParseScriptSource("acme/ripgrep@v1.0.0"); err != nil || source.Path != "" {
+		t.Errorf("Expected a repository root source, got %+v, %v", source, err)
+	}
+	for _, invalid := range []string{"acme/boba-tools/tools/ripgrep", "acme/boba-tools@", "acme@main", "acme/repo/../secrets@main"} {
+		if _, err := ParseScriptSource(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+	
+	dir := t.TempDir()
+	manifest := "name: ripgrep\ndescription: Search\nsource: acme/boba-tools/tools/ripgrep@3f2c1a9\n"
+	if err := os.MkdirAll(filepath.Join(dir, "tools", "ripgrep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tools", "ripgrep", "tool.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tools, err := NewRepositoryParserFromSource(github.NewLocalRepository(dir)).FetchTools()
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("Expected the pinned tool, got %+v, %v", tools, err)
+	}
+	if tools[0].ScriptSource == nil || tools[0].InstallScript != "tools/ripgrep/install.sh" {
+		t.Errorf("Expected the install script to come from the pinned source, got %+v", tools[0])
+	}
+}
+
+func TestToolSteps(t *testing.T) {
+	manifest := `name: node
+steps:
+  - name: apt
+    run: sudo apt-get install -y nodejs
+    when:
+      package_manager: apt
+  - name: brew
+    script: install-brew.sh
+    when:
+      platform: [darwin]
+      env:
+        BOBA_PARAM_CHANNEL: lts
+`
+	var tool Tool
+	if err := yaml.Unmarshal([]byte(manifest), &tool); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if err := tool.validateSteps("tools/node"); err != nil {
+		t.Fatalf("Expected valid steps, got %v", err)
+	}
+	if len(tool.Steps) != 2 || tool.Steps[0].When.PackageManager[0] != "apt" || tool.Steps[1].When.Platform[0] != "darwin" {
+		t.Fatalf("Unexpected steps %+v", tool.Steps)
+	}
+	if tool.Steps[1].ScriptPath != "tools/node/install-brew.sh" || tool.Steps[1].When.Env["BOBA_PARAM_CHANNEL"] != "lts" {
+		t.Errorf("Unexpected second step %+v", tool.Steps[1])
+	}
+	
+	invalid := []Tool{
+		{Steps: []Step{{Name: "empty"}}},
+		{Steps: []Step{{Run: "true", Script: "install.sh"}}},
+		{InstallInline: "true", Steps: []Step{{Run: "true"}}},
+	}
+	for _, tool := range invalid {
+		if err := tool.validateSteps("tools/node"); err == nil {
+			t.Errorf("Expected steps %+v to be rejected", tool.Steps)
+		}
+	}
+	
+	if err := (Tool{SatisfiedWhen: &SatisfiedCheck{}}).validateSatisfiedChecks(); err == nil {
+		t.Error("Expected an empty satisfied_when to be rejected")
+	}
+	if err := (Tool{Steps: []Step{{Run: "true", SatisfiedWhen: &SatisfiedCheck{}}}}).validateSatisfiedChecks(); err == nil {
+		t.Error("Expected an empty step satisfied_when to be rejected")
+	}
+}
+
+func TestToolParameters(t *testing.T) {
+	manifest := `name: java
+parameters:
+  - name: distribution
+    description: JDK distribution
+    choices: [temurin, corretto, zulu]
+  - name: version
+    type: int
+    default: 21
+  - name: with-sources
+    type: bool
+`
+	var tool Tool
+	if err := yaml.Unmarshal([]byte(manifest), &tool); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if err := tool.validateParameters(); err != nil {
+		t.Fatalf("Expected valid parameters, got %v", err)
+	}
+	if tool.Parameters[0].Kind() != ParameterChoice || tool.Parameters[2].EnvName() != "BOBA_PARAM_WITH_SOURCES" {
+		t.Errorf("Unexpected parameters %+v", tool.Parameters)
+	}
+	
+	// Invalid chosen values fall back to the defaults
+	values := tool.ParameterValues(map[string]string{"distribution": "zulu", "version": "latest"})
+	if values["distribution"] != "zulu" || values["version"] != "21" || values["with-sources"] != "false" {
+		t.Errorf("Expected the chosen distribution and default version, got %v", values)
+	}
+	
+	invalid := []Tool{
+		{Parameters: []Parameter{{Name: "version", Type: "int", Default: "latest"}}},
+		{Parameters: []Parameter{{Name: "flavor", Type: ParameterChoice}}},
+		{Parameters: []Parameter{{Name: "a-b"}, {Name: "a_b"}}},
+		{Parameters: []Parameter{{Name: "size", Type: "float"}}},
+	}
+	for _, tool := range invalid {
+		if err := tool.validateParameters(); err == nil {
+			t.Errorf("Expected parameters %+v to be rejected", tool.Parameters)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tools/git/tool.yaml":               "name: git\ndescription: Version control\n",
+		"tools/git/install.sh":              "echo install\n",
+		"environments/zsh/environment.yaml": "name: zsh\ndescription: Shell\nshell: zsh\n",
+		"environments/zsh/.zshrc":           "export EDITOR=vim\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	cachePath := filepath.Join(t.TempDir(), "cache", "repository.json")
+	
+	rp := NewRepositoryParserFromSource(github.NewLocalRepository(dir))
+	rp.UseDiskCache(cachePath, "owner/repo", time.Hour)
+	result, err := rp.Sync()
+	if err != nil {
+		t.Fatalf("Expected sync to succeed, got %v", err)
+	}
+	if result.Tools != 1 || result.Environments != 1 || result.Path != cachePath {
+		t.Errorf("Expected 1 tool and 1 environment synced to %s, got %+v", cachePath, result)
+	}
+	
+	// A parser over an empty repository answers from the cache while it is fresh
+	cached := NewRepositoryParserFromSource(github.NewLocalRepository(t.TempDir()))
+	cached.UseDiskCache(cachePath, "owner/repo", time.Hour)
+	tools, err := cached.GetTools()
+	if err != nil || len(tools) != 1 || tools[0].FolderName != "git" || tools[0].InstallScript != filepath.Join("tools", "git", "install.sh") {
+		t.Errorf("Expected the cached git tool with its internal fields, got %+v, %v", tools, err)
+	}
+	environments, err := cached.GetEnvironments()
+	if err != nil || len(environments) != 1 || environments[0].FolderName != "zsh" || len(environments[0].ConfigFiles) != 1 {
+		t.Errorf("Expected the cached zsh environment with its config files, got %+v, %v", environments, err)
+	}
+	
+	// The cache of another repository or a stale cache is not used
+	other := NewRepositoryParserFromSource(github.NewLocalRepository(t.TempDir()))
+	other.UseDiskCache(cachePath, "owner/other", time.Hour)
+	if _, ok := other.cachedTools(); ok {
+		t.Error("Expected the cache of another repository to be ignored")
+	}
+	stale := NewRepositoryParserFromSource(github.NewLocalRepository(t.TempDir()))
+	stale.UseDiskCache(cachePath, "owner/repo", 0)
+	if _, ok := stale.cachedEnvironments(); ok {
+		t.Error("Expected a stale cache to be ignored")
+	}
+	
+	// Invalidating drops the cache file so the next read fetches again
+	cached.InvalidateCache()
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Errorf("Expected the cache file to be removed, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tools/git/tool.yaml":                 "name: git\ndescription: Version control\n",
+		"tools/git/install.sh":                "#!/bin/bash\n",
+		"tools/git/uninstall.sh":              "#!/bin/bash\n",
+		"tools/node/tool.yaml":                "name: node\ndependencies: [yarn, missing]\n",
+		"tools/node/install.sh":               "#!/bin/bash\n",
+		"tools/yarn/tool.yaml":                "name: yarn\ndescription: Package manager\ndependencies: [node]\n",
+		"tools/broken/tool.yaml":              "name: [unclosed\n",
+		"environments/shell/environment.yaml": "description: Shell setup\n",
+		"environments/shell/setup.sh":         "#!/bin/bash\n",
+		"environments/shell/restore.sh":       "#!/bin/bash\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	
+	report, err := NewRepositoryParserFromSource(github.NewLocalRepository(dir)).Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	var lines []string
+	for _, issue := range report.Issues {
+		lines = append(lines, issue.Severity+" "+issue.Path+": "+issue.Message)
+	}
+	joined := strings.Join(lines, "\n")
+	for _, expected := range []string{
+		"error tools/broken",
+		"error tools/node: depends on missing, which is not a tool",
+		"error tools: circular dependency: node → yarn → node",
+		"error tools/yarn: no install script, tools/yarn/install.sh is missing",
+		"error environments/shell: name is required",
+		"warning tools/node: description is empty",
+		"warning tools/node: no uninstall script",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, joined)
+		}
+	}
+	if strings.Contains(joined, "tools/git") {
+		t.Errorf("Expected no issue with git, got:\n%s", joined)
+	}
+	if !report.HasErrors() || report.Issues[0].Severity != ValidationError || report.Tools != 3 {
+		t.Errorf("Expected errors first and 3 parsed tools, got %+v", report)
+	}
+}
+
+func TestMissingUninstall(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tools/git/tool.yaml":       "name: git\n",
+		"tools/git/install.sh":      "echo install\n",
+		"tools/git/uninstall.sh":    "echo uninstall\n",
+		"tools/jq/tool.yaml":        "name: jq\n",
+		"tools/jq/install.sh":       "echo install\n",
+		"tools/node/tool.yaml":      "name: node\nuninstall: echo uninstall\n",
+		"tools/node/install.sh":     "echo install\n",
+		"tools/docker/tool.yaml":    "name: docker\nuninstallable: false\n",
+		"tools/docker/install.sh":   "echo install\n",
+		"tools/docker/uninstall.sh": "echo uninstall\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	
+	tools, err := NewRepositoryParserFromSource(github.NewLocalRepository(dir)).FetchTools()
+	if err != nil {
+		t.Fatalf("Expected tools, got %v", err)
+	}
+	want := map[string]bool{"git": true, "jq": false, "node": true, "docker": false}
+	for _, tool := range tools {
+		if tool.HasUninstall() != want[tool.Name] {
+			t.Errorf("Expected HasUninstall of %s to be %v", tool.Name, want[tool.Name])
+		}
+	}
+	if len(tools) != len(want) {
+		t.Errorf("Expected %d tools, got %d", len(want), len(tools))
+	}
+}
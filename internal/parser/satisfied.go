@@ -0,0 +1,23 @@
+package parser
+
+import "fmt"
+
+// SatisfiedCheck tells whether a tool or step is already in place, so installing it again can be
+// skipped without relying on the script's own guards. Every check that is set must hold.
+type SatisfiedCheck struct {
+	Command    string    `yaml:"command,omitempty" json:"command,omitempty"`         // Command exiting with 0 when satisfied
+	FileExists ValueList `yaml:"file_exists,omitempty" json:"file_exists,omitempty"` // Paths that all exist when satisfied (~ and variables expanded)
+}
+
+// validateSatisfiedChecks rejects satisfied_when checks of the tool or its steps that check nothing
+func (t Tool) validateSatisfiedChecks() error {
+	if t.SatisfiedWhen != nil && t.SatisfiedWhen.Command == "" && len(t.SatisfiedWhen.FileExists) == 0 {
+		return fmt.Errorf("satisfied_when needs a 'command:' or 'file_exists:'")
+	}
+	for i, step := range t.Steps {
+		if step.SatisfiedWhen != nil && step.SatisfiedWhen.Command == "" && len(step.SatisfiedWhen.FileExists) == 0 {
+			return fmt.Errorf("satisfied_when of %s needs a 'command:' or 'file_exists:'", step.Label(i))
+		}
+	}
+	return nil
+}
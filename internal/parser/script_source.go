@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ScriptSource is a tool folder in another GitHub repository, pinned to a ref, whose scripts
+// are used instead of the ones of the configuration repository (source: owner/repo/path@ref)
+type ScriptSource struct {
+	Owner string
+	Repo  string
+	Path  string // Tool folder inside the repository ("" for the repository root)
+	Ref   string // Commit SHA, tag or branch the scripts are read at
+}
+
+// ParseScriptSource parses an owner/repo/path@ref reference. The ref is required so the
+// scripts cannot change under the configuration repository without it being edited.
+func ParseScriptSource(source string) (*ScriptSource, error) {
+	at := strings.LastIndex(source, "@")
+	if at < 0 || at == len(source)-1 {
+		return nil, fmt.Errorf("source %q must be pinned to a ref (owner/repo/path@ref)", source)
+	}
+	location, ref := source[:at], source[at+1:]
+	
+	parts := strings.SplitN(strings.Trim(location, "/"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("source %q must name a repository (owner/repo/path@ref)", source)
+	}
+	
+	scriptSource := &ScriptSource{Owner: parts[0], Repo: parts[1], Ref: ref}
+	if len(parts) == 3 {
+		scriptSource.Path = path.Clean(parts[2])
+		if scriptSource.Path == "." {
+			scriptSource.Path = ""
+		}
+		if strings.HasPrefix(scriptSource.Path, "..") || path.IsAbs(scriptSource.Path) {
+			return nil, fmt.Errorf("source %q points outside the repository", source)
+		}
+	}
+	return scriptSource, nil
+}
+
+// String returns the source in its owner/repo/path@ref form
+func (s ScriptSource) String() string {
+	location := s.Owner + "/" + s.Repo
+	if s.Path != "" {
+		location += "/" + s.Path
+	}
+	return location + "@" + s.Ref
+}
+
+// applySource points the tool's install and uninstall scripts to its pinned source, if any
+func (t *Tool) applySource() error {
+	if t.Source == "" {
+		return nil
+	}
+	scriptSource, err := ParseScriptSource(t.Source)
+	if err != nil {
+		return err
+	}
+	t.ScriptSource = scriptSource
+	t.InstallScript = path.Join(scriptSource.Path, "install.sh")
+	t.UninstallScript = path.Join(scriptSource.Path, "uninstall.sh")
+	return nil
+}
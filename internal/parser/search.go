@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kinds of search results
+const (
+	SearchKindTool        = "tool"
+	SearchKindEnvironment = "environment"
+)
+
+// SearchResult is a tool or environment matching a search
+type SearchResult struct {
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Score       int      `json:"score"` // Higher for better matches: name over tags over description
+}
+
+// Scores of a search term by where it matches
+const (
+	scoreNameExact   = 100
+	scoreNamePrefix  = 50
+	scoreName        = 30
+	scoreTagExact    = 20
+	scoreTag         = 10
+	scoreDescription = 5
+)
+
+// Search returns the tools and environments whose name, tags or description contain every word
+// of the query, ignoring case, best matches first
+func Search(tools []Tool, environments []Environment, query string) []SearchResult {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	
+	var results []SearchResult
+	add := func(kind, name, description string, tags []string) {
+		score := 0
+		for _, term := range terms {
+			termScore := searchScore(term, name, description, tags)
+			if termScore == 0 {
+				return
+			}
+			score += termScore
+		}
+		results = append(results, SearchResult{Kind: kind, Name: name, Description: description, Tags: tags, Score: score})
+	}
+	for _, tool := range tools {
+		add(SearchKindTool, tool.Name, tool.Description, tool.Tags)
+	}
+	for _, env := range environments {
+		add(SearchKindEnvironment, env.Name, env.Description, env.Tags)
+	}
+	
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind == SearchKindTool
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// searchScore scores the best place a lowercase term matches, or 0 when it matches nowhere
+func searchScore(term, name, description string, tags []string) int {
+	name = strings.ToLower(name)
+	switch {
+	case name == term:
+		return scoreNameExact
+	case strings.HasPrefix(name, term):
+		return scoreNamePrefix
+	case strings.Contains(name, term):
+		return scoreName
+	}
+	
+	best := 0
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if tag == term {
+			return scoreTagExact
+		}
+		if strings.Contains(tag, term) {
+			best = scoreTag
+		}
+	}
+	if best == 0 && strings.Contains(strings.ToLower(description), term) {
+		best = scoreDescription
+	}
+	return best
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tools := []Tool{
+		{Name: "neovim", Description: "Hyperextensible text editor", Tags: []string{"editor", "vim"}},
+		{Name: "vim", Description: "The classic editor"},
+		{Name: "postgres", Description: "Relational database", Tags: []string{"database", "sql"}},
+		{Name: "ripgrep", Description: "Searches files with regular expressions"},
+	}
+	environments := []Environment{
+		{Name: "editor-config", Description: "Editor settings for vim and neovim", Tags: []string{"editor"}},
+	}
+	
+	names := func(query string) []string {
+		var result []string
+		for _, r := range Search(tools, environments, query) {
+			result = append(result, r.Kind+":"+r.Name)
+		}
+		return result
+	}
+	
+	// A name match ranks over a tag match, which ranks over the description
+	got := names("VIM")
+	want := []string{"tool:vim", "tool:neovim", "environment:editor-config"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+	
+	// Every word must match somewhere
+	if got := names("editor neovim"); len(got) != 2 || got[0] != "tool:neovim" {
+		t.Errorf("Expected neovim first, then the environment, got %v", got)
+	}
+	if got := names("sql"); len(got) != 1 || got[0] != "tool:postgres" {
+		t.Errorf("Expected the tag to match, got %v", got)
+	}
+	if got := names("  "); got != nil {
+		t.Errorf("Expected no results for an empty query, got %v", got)
+	}
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step is one part of a multi-step tool install, run only when its condition matches the machine
+type Step struct {
+	Name   string         `yaml:"name,omitempty" json:"name,omitempty"`
+	Run    string         `yaml:"run,omitempty" json:"run,omitempty"`       // Inline script
+	Script string         `yaml:"script,omitempty" json:"script,omitempty"` // Script file in the tool folder, used instead of run
+	When   *StepCondition `yaml:"when,omitempty" json:"when,omitempty"`
+	
+	// Check that skips the step when it already holds
+	SatisfiedWhen *SatisfiedCheck `yaml:"satisfied_when,omitempty" json:"satisfied_when,omitempty"`
+	
+	// Internal fields
+	ScriptPath string `yaml:"-" json:"-"` // Repository path of Script
+}
+
+// StepCondition restricts a step to some machines. Every field that is set must match;
+// list fields match when any of their values does.
+type StepCondition struct {
+	Platform       ValueList         `yaml:"platform,omitempty" json:"platform,omitempty"`               // linux, darwin or windows
+	Arch           ValueList         `yaml:"arch,omitempty" json:"arch,omitempty"`                       // amd64, arm64, ...
+	PackageManager ValueList         `yaml:"package_manager,omitempty" json:"package_manager,omitempty"` // apt, dnf, brew, ...
+	Scope          ValueList         `yaml:"scope,omitempty" json:"scope,omitempty"`                     // Install scope chosen for the tool: user or system
+	Env            map[string]string `yaml:"env,omitempty" json:"env,omitempty"`                         // Variables of the script environment and their required value
+}
+
+// ValueList is a list of values that can also be written as a single value
+type ValueList []string
+
+// UnmarshalYAML accepts a single value or a list
+func (v *ValueList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = ValueList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// UnmarshalJSON accepts a single value or a list
+func (v *ValueList) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*v = ValueList{value}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// Label returns the step name, or its position when it has none
+func (s Step) Label(index int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("step %d", index+1)
+}
+
+// validateSteps checks the steps of a manifest and resolves their script paths relative to the
+// tool folder (or to the folder of its pinned source)
+func (t *Tool) validateSteps(folder string) error {
+	if t.ScriptSource != nil {
+		folder = t.ScriptSource.Path
+	}
+	if len(t.Steps) > 0 && t.InstallInline != "" {
+		return fmt.Errorf("use either an inline install script or steps, not both")
+	}
+	for i := range t.Steps {
+		step := &t.Steps[i]
+		if (step.Run == "") == (step.Script == "") {
+			return fmt.Errorf("%s needs either 'run:' or 'script:'", step.Label(i))
+		}
+		if step.Script != "" {
+			step.ScriptPath = path.Join(folder, step.Script)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"boba/internal/github"
+)
+
+// errManifestNotFound is returned when a tool or environment folder has no manifest
+var errManifestNotFound = errors.New("manifest not found")
+
+// StructureIssue describes one problem with the repository layout
+type StructureIssue struct {
+	Path     string // Repository path the issue refers to
+	Problem  string // What is wrong
+	Expected string // What BOBA expects instead
+}
+
+// StructureReport collects the layout problems found while reading a repository section
+type StructureReport struct {
+	Section string // "tools" or "environments"
+	Issues  []StructureIssue
+}
+
+// StructureError is returned when a repository section cannot be used at all
+type StructureError struct {
+	Report *StructureReport
+}
+
+func (e *StructureError) Error() string {
+	return e.Report.String()
+}
+
+// HasIssues reports whether any layout problems were found
+func (r *StructureReport) HasIssues() bool {
+	return r != nil && len(r.Issues) > 0
+}
+
+// String formats the report with the exact expectations for each problem
+func (r *StructureReport) String() string {
+	// Sections are directories, the catalog is a file
+	where := r.Section
+	if path.Ext(where) == "" {
+		where += "/"
+	}
+	
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("repository structure problems in '%s':", where))
+	for _, issue := range r.Issues {
+		s.WriteString(fmt.Sprintf("\n  • %s: %s\n    expected: %s", issue.Path, issue.Problem, issue.Expected))
+	}
+	return s.String()
+}
+
+func (r *StructureReport) add(path, problem, expected string) {
+	r.Issues = append(r.Issues, StructureIssue{Path: path, Problem: problem, Expected: expected})
+}
+
+// sectionLayout describes the expected layout of a repository section
+type sectionLayout struct {
+	dir         string // Top-level directory
+	item        string // What each folder describes
+	manifest    string // Preferred manifest file name
+	altManifest string // Alternative manifest file name
+	script      string // Main script file name
+}
+
+var (
+	toolsLayout        = sectionLayout{dir: "tools", item: "tool", manifest: "tool.yaml", altManifest: "tool.json", script: "install.sh"}
+	environmentsLayout = sectionLayout{dir: "environments", item: "environment", manifest: "environment.yaml", altManifest: "environment.json", script: "setup.sh"}
+)
+
+// example returns the expected files for a folder of this section
+func (l sectionLayout) example(name string) string {
+	return fmt.Sprintf("%s/%s/%s (or %s) and %s/%s/%s", l.dir, name, l.manifest, l.altManifest, l.dir, name, l.script)
+}
+
+// listSection lists the folders of a section, recording layout problems in the report.
+// It returns a *StructureError when the section directory itself is missing or is a file.
+func (rp *RepositoryParser) listSection(layout sectionLayout, report *StructureReport) ([]string, error) {
+	entries, err := rp.source.GetDirectoryEntries(layout.dir)
+	if err != nil {
+		switch {
+		case github.IsNotFound(err):
+			report.add(layout.dir+"/", "directory does not exist",
+				fmt.Sprintf("a top-level '%s' directory with one folder per %s, e.g. %s", layout.dir, layout.item, layout.example("<name>")))
+			return nil, &StructureError{Report: report}
+		case errors.Is(err, github.ErrNotDirectory):
+			report.add(layout.dir, "is a file, not a directory",
+				fmt.Sprintf("'%s' to be a directory with one folder per %s, e.g. %s", layout.dir, layout.item, layout.example("<name>")))
+			return nil, &StructureError{Report: report}
+		default:
+			return nil, fmt.Errorf("failed to list '%s' directory: %w", layout.dir, err)
+		}
+	}
+	
+	return classifyEntries(layout, entries, report), nil
+}
+
+// classifyEntries returns the folder names of a section listing and reports stray entries
+func classifyEntries(layout sectionLayout, entries []github.DirectoryEntry, report *StructureReport) []string {
+	var folders []string
+	for _, entry := range entries {
+		if entry.Type == "dir" {
+			folders = append(folders, entry.Name)
+			continue
+		}
+		// Documentation and dotfiles next to the folders are fine
+		if strings.HasPrefix(entry.Name, ".") || strings.HasPrefix(strings.ToUpper(entry.Name), "README") {
+			continue
+		}
+		
+		name := strings.TrimSuffix(entry.Name, path.Ext(entry.Name))
+		entryPath := entry.Path
+		if entryPath == "" {
+			entryPath = path.Join(layout.dir, entry.Name)
+		}
+		report.add(entryPath, fmt.Sprintf("is a %s, not a folder", entry.Type),
+			fmt.Sprintf("each %s in its own folder, e.g. %s", layout.item, layout.example(name)))
+	}
+	
+	if len(folders) == 0 && len(report.Issues) == 0 {
+		report.add(layout.dir+"/", "directory is empty",
+			fmt.Sprintf("one folder per %s, e.g. %s", layout.item, layout.example("<name>")))
+	}
+	return folders
+}
+
+// recordFolderIssue adds the reason a folder could not be loaded to the report
+func recordFolderIssue(layout sectionLayout, name string, err error, report *StructureReport) {
+	folder := path.Join(layout.dir, name) + "/"
+	if errors.Is(err, errManifestNotFound) {
+		report.add(folder, fmt.Sprintf("missing %s", layout.manifest),
+			fmt.Sprintf("%s describing the %s (at least a name)", layout.example(name), layout.item))
+		return
+	}
+	report.add(folder, err.Error(), fmt.Sprintf("a valid %s or %s", layout.manifest, layout.altManifest))
+}
+
+// setStructureReport stores the report of the last fetch of a section
+func (rp *RepositoryParser) setStructureReport(section string, report *StructureReport) {
+	if rp.structureReports == nil {
+		rp.structureReports = make(map[string]*StructureReport)
+	}
+	rp.structureReports[section] = report
+}
+
+// StructureReport returns the layout problems found by the last fetch of a section
+// ("tools" or "environments"), or nil if the section has not been fetched
+func (rp *RepositoryParser) StructureReport(section string) *StructureReport {
+	return rp.structureReports[section]
+}
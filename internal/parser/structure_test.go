@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"boba/internal/github"
+)
+
+func TestClassifyEntries(t *testing.T) {
+	report := &StructureReport{Section: "tools"}
+	entries := []github.DirectoryEntry{
+		{Name: "git", Path: "tools/git", Type: "dir"},
+		{Name: "README.md", Path: "tools/README.md", Type: "file"},
+		{Name: ".gitkeep", Path: "tools/.gitkeep", Type: "file"},
+		{Name: "vim.yaml", Path: "tools/vim.yaml", Type: "file"},
+	}
+	
+	folders := classifyEntries(toolsLayout, entries, report)
+	if len(folders) != 1 || folders[0] != "git" {
+		t.Errorf("Expected only the git folder, got %v", folders)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("Expected 1 issue for the stray file, got %v", report.Issues)
+	}
+	issue := report.Issues[0]
+	if issue.Path != "tools/vim.yaml" || !strings.Contains(issue.Expected, "tools/vim/tool.yaml") {
+		t.Errorf("Expected stray file issue pointing at tools/vim/tool.yaml, got %+v", issue)
+	}
+}
+
+func TestClassifyEntriesEmpty(t *testing.T) {
+	report := &StructureReport{Section: "environments"}
+	if folders := classifyEntries(environmentsLayout, nil, report); len(folders) != 0 {
+		t.Errorf("Expected no folders, got %v", folders)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Problem != "directory is empty" {
+		t.Errorf("Expected empty directory issue, got %v", report.Issues)
+	}
+}
+
+func TestRecordFolderIssue(t *testing.T) {
+	report := &StructureReport{Section: "tools"}
+	recordFolderIssue(toolsLayout, "git", fmt.Errorf("failed to fetch tool config for git: %w", errManifestNotFound), report)
+	recordFolderIssue(toolsLayout, "vim", errors.New("failed to parse tool config for vim: yaml: line 2"), report)
+	
+	if len(report.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", report.Issues)
+	}
+	if report.Issues[0].Problem != "missing tool.yaml" {
+		t.Errorf("Expected missing manifest issue, got %+v", report.Issues[0])
+	}
+	if !strings.Contains(report.Issues[1].Problem, "yaml: line 2") {
+		t.Errorf("Expected parse error issue, got %+v", report.Issues[1])
+	}
+	
+	err := &StructureError{Report: report}
+	message := err.Error()
+	for _, want := range []string{"'tools/'", "tools/git/", "tools/git/tool.yaml", "tools/vim/"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected report to mention %q, got:\n%s", want, message)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+
+	"boba/internal/github"
+)
+
+// scriptExtensions are the file extensions counted as scripts for the trust confirmation
+var scriptExtensions = map[string]bool{".sh": true, ".bash": true, ".zsh": true, ".ps1": true, ".bat": true, ".cmd": true}
+
+// CountScripts returns the number of scripts the repository can run: script files under
+// tools/ and environments/ plus inline scripts defined in manifests and the boba.yaml catalog
+func (rp *RepositoryParser) CountScripts() (int, error) {
+	var files []string
+	for _, section := range []string{"tools", "environments"} {
+		sectionFiles, err := rp.source.GetFilesRecursive(section)
+		if err != nil && !github.IsNotFound(err) {
+			return 0, err
+		}
+		files = append(files, sectionFiles...)
+	}
+	
+	// Inline scripts are best effort: a broken manifest has no runnable script
+	tools, _ := rp.FetchTools()
+	environments, _ := rp.FetchEnvironments()
+	
+	return countScripts(files, tools, environments), nil
+}
+
+// countScripts counts script files and inline scripts
+func countScripts(files []string, tools []Tool, environments []Environment) int {
+	count := 0
+	for _, file := range files {
+		if scriptExtensions[strings.ToLower(filepath.Ext(file))] {
+			count++
+		}
+	}
+	for _, tool := range tools {
+		for _, inline := range []string{tool.InstallInline, tool.UninstallInline} {
+			if inline != "" {
+				count++
+			}
+		}
+		for _, step := range tool.Steps {
+			if step.Run != "" {
+				count++
+			}
+		}
+	}
+	for _, env := range environments {
+		for _, inline := range []string{env.SetupInline, env.RestoreInline} {
+			if inline != "" {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,25 @@
+package parser
+
+import "testing"
+
+func TestCountScripts(t *testing.T) {
+	files := []string{
+		"tools/git/install.sh",
+		"tools/git/uninstall.sh",
+		"tools/git/tool.yaml",
+		"tools/winget/install.PS1",
+		"environments/zsh/setup.sh",
+		"environments/zsh/.zshrc",
+	}
+	tools := []Tool{
+		{Name: "git"},
+		{Name: "jq", InstallInline: "apt-get install -y jq", UninstallInline: "apt-get remove -y jq"},
+	}
+	environments := []Environment{
+		{Name: "minimal", SetupInline: "echo setup"},
+	}
+	
+	if count := countScripts(files, tools, environments); count != 7 {
+		t.Errorf("Expected 7 scripts (4 files, 3 inline), got %d", count)
+	}
+}
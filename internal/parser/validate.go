@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"boba/internal/github"
+)
+
+// Severities of validation issues: errors break installs, warnings only lose features
+const (
+	ValidationError   = "error"
+	ValidationWarning = "warning"
+)
+
+// ValidationIssue is one problem found by Validate
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"` // Repository path or tool/environment the issue refers to
+	Message  string `json:"message"`
+}
+
+// ValidationReport is the outcome of validating every manifest of a repository
+type ValidationReport struct {
+	Tools        int               `json:"tools"`
+	Environments int               `json:"environments"`
+	Issues       []ValidationIssue `json:"issues"`
+}
+
+func (r *ValidationReport) add(severity, path, message string) {
+	r.Issues = append(r.Issues, ValidationIssue{Severity: severity, Path: path, Message: message})
+}
+
+// Count returns the number of issues of the severity
+func (r *ValidationReport) Count(severity string) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors reports whether any issue is an error
+func (r *ValidationReport) HasErrors() bool {
+	return r.Count(ValidationError) > 0
+}
+
+// Summary returns the one-line outcome of the validation
+func (r *ValidationReport) Summary() string {
+	return fmt.Sprintf("Validated %d tools and %d environments: %d errors, %d warnings",
+		r.Tools, r.Environments, r.Count(ValidationError), r.Count(ValidationWarning))
+}
+
+// Write prints the issues, errors first, then the summary
+func (r *ValidationReport) Write(w io.Writer) {
+	for _, issue := range r.Issues {
+		icon := "⚠️ "
+		if issue.Severity == ValidationError {
+			icon = "❌"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", icon, issue.Path, issue.Message)
+	}
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(w, "✅ Every manifest looks good")
+	}
+	fmt.Fprintln(w, r.Summary())
+}
+
+// Validate checks every tool and environment of the repository: layout and manifest parse
+// problems, required fields, duplicate names, dependencies that don't exist or form a cycle,
+// and missing scripts. It reads the repository directly, bypassing the caches.
+func (rp *RepositoryParser) Validate() (*ValidationReport, error) {
+	if rp.source == nil {
+		return nil, fmt.Errorf("GitHub client not initialized")
+	}
+	report := &ValidationReport{}
+	
+	tools, err := rp.fetchTools()
+	if err != nil && !errors.As(err, new(*StructureError)) {
+		return nil, err
+	}
+	environments, envErr := rp.fetchEnvironments()
+	if envErr != nil && !errors.As(envErr, new(*StructureError)) {
+		return nil, envErr
+	}
+	report.Tools = len(tools)
+	report.Environments = len(environments)
+	
+	// Folders that could not be read at all, or whose manifest doesn't parse
+	for _, section := range []string{toolsLayout.dir, environmentsLayout.dir} {
+		if structure := rp.StructureReport(section); structure.HasIssues() {
+			for _, issue := range structure.Issues {
+				report.add(ValidationError, issue.Path, fmt.Sprintf("%s (expected: %s)", issue.Problem, issue.Expected))
+			}
+		}
+	}
+	
+	toolNames := make(map[string]bool)
+	toolDependencies := make(map[string][]string)
+	for _, tool := range tools {
+		subject := tool.manifestPath()
+		rp.validateEntry(report, subject, tool.Name, tool.Description, toolNames)
+		toolDependencies[tool.Name] = tool.Dependencies
+		
+		if tool.InstallInline == "" && len(tool.Steps) == 0 && tool.ScriptSource == nil {
+			rp.requireScript(report, ValidationError, subject, tool.InstallScript, "install script", "")
+		}
+		if tool.UninstallInline == "" && tool.ScriptSource == nil {
+			rp.requireScript(report, ValidationWarning, subject, tool.UninstallScript, "uninstall script", "the tool can't be uninstalled")
+		}
+	}
+	envNames := make(map[string]bool)
+	envDependencies := make(map[string][]string)
+	for _, env := range environments {
+		subject := env.manifestPath()
+		rp.validateEntry(report, subject, env.Name, env.Description, envNames)
+		envDependencies[env.Name] = env.Dependencies
+		
+		if env.SetupInline == "" {
+			rp.requireScript(report, ValidationError, subject, env.SetupScript, "setup script", "")
+		}
+		if env.RestoreInline == "" {
+			rp.requireScript(report, ValidationWarning, subject, env.RestoreScript, "restore script", "the environment can't be restored")
+		}
+	}
+	
+	checkDependencies(report, "tool", tools, toolNames, func(t Tool) (string, string, []string) { return t.manifestPath(), t.Name, t.Dependencies })
+	checkDependencies(report, "environment", environments, envNames, func(e Environment) (string, string, []string) { return e.manifestPath(), e.Name, e.Dependencies })
+	for _, cycle := range findCycles(toolDependencies) {
+		report.add(ValidationError, "tools", "circular dependency: "+strings.Join(cycle, " → "))
+	}
+	for _, cycle := range findCycles(envDependencies) {
+		report.add(ValidationError, "environments", "circular dependency: "+strings.Join(cycle, " → "))
+	}
+	
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Severity == ValidationError && report.Issues[j].Severity != ValidationError
+	})
+	return report, nil
+}
+
+// validateEntry checks the required fields of a manifest and that its name is unique
+func (rp *RepositoryParser) validateEntry(report *ValidationReport, subject, name, description string, seen map[string]bool) {
+	switch {
+	case name == "":
+		report.add(ValidationError, subject, "name is required")
+	case seen[name]:
+		report.add(ValidationError, subject, fmt.Sprintf("name %q is used more than once", name))
+	}
+	seen[name] = true
+	if description == "" {
+		report.add(ValidationWarning, subject, "description is empty")
+	}
+}
+
+// requireScript adds an issue when the script is missing from the repository, with what that
+// means when it isn't obvious
+func (rp *RepositoryParser) requireScript(report *ValidationReport, severity, subject, script, what, consequence string) {
+	message := "no " + what
+	if script != "" {
+		message = fmt.Sprintf("no %s, %s is missing", what, script)
+	}
+	if consequence != "" {
+		message += ": " + consequence
+	}
+	if script == "" {
+		report.add(severity, subject, message)
+		return
+	}
+	if _, err := rp.source.GetRepositoryContents(script); err != nil {
+		if github.IsNotFound(err) {
+			report.add(severity, subject, message)
+		} else {
+			report.add(ValidationWarning, subject, fmt.Sprintf("could not read %s: %v", script, err))
+		}
+	}
+}
+
+// checkDependencies adds an error for each dependency naming an entry that doesn't exist
+func checkDependencies[T any](report *ValidationReport, kind string, entries []T, names map[string]bool, describe func(T) (string, string, []string)) {
+	for _, entry := range entries {
+		subject, name, dependencies := describe(entry)
+		for _, dependency := range dependencies {
+			if dependency == name {
+				report.add(ValidationError, subject, "depends on itself")
+			} else if !names[dependency] {
+				report.add(ValidationError, subject, fmt.Sprintf("depends on %s, which is not a %s of the repository", dependency, kind))
+			}
+		}
+	}
+}
+
+// findCycles returns each dependency cycle once, as the names along it ending with the first
+func findCycles(dependencies map[string][]string) [][]string {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+	var cycles [][]string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if _, exists := dependencies[dependency]; !exists || dependency == name {
+				continue // Reported as a missing dependency or a self-dependency
+			}
+			switch state[dependency] {
+			case unvisited:
+				visit(dependency)
+			case visiting:
+				for i := range path {
+					if path[i] == dependency {
+						cycle := append(append([]string{}, path[i:]...), dependency)
+						cycles = append(cycles, cycle)
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return cycles
+}
+
+// manifestPath names where the tool is defined, for validation issues
+func (t Tool) manifestPath() string {
+	if t.Catalog {
+		return fmt.Sprintf("%s (tool %s)", catalogFileName, t.FolderName)
+	}
+	return "tools/" + t.FolderName
+}
+
+// manifestPath names where the environment is defined, for validation issues
+func (e Environment) manifestPath() string {
+	if e.Catalog {
+		return fmt.Sprintf("%s (environment %s)", catalogFileName, e.FolderName)
+	}
+	return "environments/" + e.FolderName
+}
@@ -0,0 +1,78 @@
+package preview
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Severity of a preview finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem found while previewing a tool
+type Finding struct {
+	Severity Severity
+	Subject  string // What the finding is about (tool.yaml, install.sh, ...)
+	Message  string
+}
+
+// lintScript checks a shell script for problems that would make it fail or behave differently under BOBA
+func lintScript(name string, content []byte) []Finding {
+	var findings []Finding
+	add := func(severity Severity, message string) {
+		findings = append(findings, Finding{Severity: severity, Subject: name, Message: message})
+	}
+	
+	if len(bytes.TrimSpace(content)) == 0 {
+		add(SeverityError, "script is empty")
+		return findings
+	}
+	if bytes.Contains(content, []byte("\r\n")) {
+		add(SeverityError, "script has Windows (CRLF) line endings, bash will fail on them")
+	}
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		add(SeverityWarning, "script has no shebang line (it runs with /bin/bash)")
+	}
+	
+	// Write the script to disk for the external checkers
+	dir, err := os.MkdirTemp("", "boba-preview-lint-")
+	if err != nil {
+		return findings
+	}
+	defer os.RemoveAll(dir)
+	scriptPath := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(scriptPath, content, 0600); err != nil {
+		return findings
+	}
+	
+	if _, err := exec.LookPath("bash"); err == nil {
+		if output, err := exec.Command("bash", "-n", scriptPath).CombinedOutput(); err != nil {
+			add(SeverityError, "syntax error: "+cleanCheckerOutput(output, scriptPath, name))
+		}
+	}
+	
+	if _, err := exec.LookPath("shellcheck"); err == nil {
+		output, err := exec.Command("shellcheck", "--format=gcc", "--shell=bash", scriptPath).CombinedOutput()
+		if err != nil {
+			for _, line := range strings.Split(cleanCheckerOutput(output, scriptPath, name), "\n") {
+				if strings.TrimSpace(line) != "" {
+					add(SeverityWarning, "shellcheck: "+line)
+				}
+			}
+		}
+	}
+	
+	return findings
+}
+
+// cleanCheckerOutput replaces the temporary script path with the script name
+func cleanCheckerOutput(output []byte, scriptPath, name string) string {
+	return strings.TrimSpace(strings.ReplaceAll(string(output), scriptPath, name))
+}
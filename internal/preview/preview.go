@@ -0,0 +1,366 @@
+// Package preview implements `boba preview`, a tight authoring loop for a single tool folder:
+// it validates the manifest, lints the scripts, shows a dry run and optionally executes the
+// install script locally or in a container.
+package preview
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"boba/internal/exitcode"
+	"boba/internal/github"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// Options controls what a preview does beyond validation and the dry run
+type Options struct {
+	Run       bool   // Execute the install script on this machine
+	Container string // Execute the install script in a container of this image (docker or podman)
+}
+
+// Report is the outcome of previewing a tool
+type Report struct {
+	Dir      string
+	Tool     *parser.Tool
+	Findings []Finding
+	Plan     *installer.DryRunPlan
+	Result   *installer.InstallationResult // Set when the install script was executed
+	Executed string                        // Where the script was executed ("local" or the container image)
+}
+
+// HasErrors reports whether any finding is an error
+func (r *Report) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) add(severity Severity, subject, message string) {
+	r.Findings = append(r.Findings, Finding{Severity: severity, Subject: subject, Message: message})
+}
+
+// Preview validates, lints and dry-runs the tool folder dir, which must be a tools/<name>
+// folder of a configuration repository, then executes it if requested and there are no errors
+func Preview(dir string, options Options) (*Report, error) {
+	toolDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(toolDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if filepath.Base(filepath.Dir(toolDir)) != "tools" {
+		return nil, fmt.Errorf("%s is not a tools/<name> folder of a configuration repository", dir)
+	}
+	
+	root := filepath.Dir(filepath.Dir(toolDir))
+	folder := filepath.Base(toolDir)
+	repo := github.NewLocalRepository(root)
+	report := &Report{Dir: toolDir}
+	
+	// Validate the manifest with the same parser the TUI uses
+	rp := parser.NewRepositoryParserFromSource(repo)
+	tools, _ := rp.FetchTools()
+	for i := range tools {
+		if tools[i].FolderName == folder {
+			report.Tool = &tools[i]
+		}
+	}
+	if report.Tool == nil {
+		found := false
+		if structure := rp.StructureReport("tools"); structure != nil {
+			for _, issue := range structure.Issues {
+				if strings.HasPrefix(issue.Path, "tools/"+folder) {
+					report.add(SeverityError, issue.Path, issue.Problem+" (expected "+issue.Expected+")")
+					found = true
+				}
+			}
+		}
+		if !found {
+			report.add(SeverityError, "tool.yaml", "tool manifest could not be loaded")
+		}
+		return report, nil
+	}
+	
+	validateManifest(report, tools)
+	lintScripts(report, repo)
+	
+	// Dry run: what would run, where, with which BOBA_* variables
+	engine := installer.NewInstallationEngine(repo)
+	defer engine.Cleanup()
+	engine.SetRepositoryDir(root)
+	plan, err := engine.DryRunTool(*report.Tool)
+	if err != nil {
+		report.add(SeverityError, "dry run", err.Error())
+	}
+	report.Plan = plan
+	
+	if report.HasErrors() {
+		return report, nil
+	}
+	switch {
+	case options.Container != "":
+		report.Executed = options.Container
+		report.Result = runInContainer(options.Container, root, *report.Tool, plan)
+	case options.Run:
+		report.Executed = "local"
+		report.Result, _ = engine.InstallTool(*report.Tool)
+	}
+	return report, nil
+}
+
+// validateManifest checks the fields of the manifest that the parser accepts but BOBA needs
+func validateManifest(report *Report, tools []parser.Tool) {
+	tool := report.Tool
+	if strings.TrimSpace(tool.Name) == "" {
+		report.add(SeverityError, "tool.yaml", "name is required")
+	}
+	if strings.TrimSpace(tool.Description) == "" {
+		report.add(SeverityWarning, "tool.yaml", "description is empty (it is shown in the tools list)")
+	}
+	
+	known := make(map[string]bool)
+	for _, other := range tools {
+		known[other.Name] = true
+	}
+	for _, dependency := range tool.Dependencies {
+		if dependency == tool.Name {
+			report.add(SeverityError, "tool.yaml", "tool depends on itself")
+		} else if !known[dependency] {
+			report.add(SeverityWarning, "tool.yaml", fmt.Sprintf("dependency '%s' is not a tool of this repository", dependency))
+		}
+	}
+}
+
+// lintScripts lints the install and uninstall scripts, inline, from the tool folder or from the
+// repository the tool's source is pinned to
+func lintScripts(report *Report, repo *github.LocalRepository) {
+	tool := report.Tool
+	read := repo.GetRepositoryContents
+	if source := tool.ScriptSource; source != nil {
+		read = func(path string) ([]byte, error) {
+			return github.GetPublicContentsAt(source.Owner, source.Repo, path, source.Ref)
+		}
+	}
+	type script struct {
+		name     string
+		inline   string
+		path     string
+		required bool
+	}
+	scripts := []script{{"install", tool.InstallInline, tool.InstallScript, true}}
+	if len(tool.Steps) > 0 {
+		scripts = nil
+		for i, step := range tool.Steps {
+			scripts = append(scripts, script{step.Label(i), step.Run, step.ScriptPath, true})
+		}
+	}
+	scripts = append(scripts, script{"uninstall", tool.UninstallInline, tool.UninstallScript, false})
+	
+	for _, script := range scripts {
+		subject := "inline " + script.name
+		content := []byte(script.inline)
+		if script.inline == "" {
+			subject = filepath.Base(script.path)
+			var err error
+			content, err = read(filepath.ToSlash(script.path))
+			if github.IsNotFound(err) {
+				if script.required {
+					report.add(SeverityError, subject, "missing (add "+subject+" or an inline install: script)")
+				} else if tool.CanUninstall() {
+					report.add(SeverityWarning, subject, "missing: the tool cannot be uninstalled (set uninstallable: false to make this explicit)")
+				}
+				continue
+			}
+			if err != nil {
+				report.add(SeverityError, subject, err.Error())
+				continue
+			}
+		}
+		report.Findings = append(report.Findings, lintScript(subject, content)...)
+	}
+}
+
+// containerRuntime returns the available container runtime
+func containerRuntime() (string, error) {
+	for _, runtime := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(runtime); err == nil {
+			return runtime, nil
+		}
+	}
+	return "", errors.New("neither docker nor podman was found")
+}
+
+// runInContainer executes the install script in a throwaway container with the repository mounted read-only
+func runInContainer(image, root string, tool parser.Tool, plan *installer.DryRunPlan) *installer.InstallationResult {
+	start := time.Now()
+	fail := func(err error) *installer.InstallationResult {
+		return &installer.InstallationResult{Success: false, Error: err, Duration: time.Since(start)}
+	}
+	
+	runtime, err := containerRuntime()
+	if err != nil {
+		return fail(err)
+	}
+	
+	runDir, err := os.MkdirTemp("", "boba-preview-")
+	if err != nil {
+		return fail(err)
+	}
+	defer os.RemoveAll(runDir)
+	args, err := containerArgs(image, root, runDir, tool, plan)
+	if err != nil {
+		return fail(err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, runtime, args...).CombinedOutput()
+	
+	result := &installer.InstallationResult{Success: err == nil, Output: string(output), Duration: time.Since(start)}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		result.Error = fmt.Errorf("%s run failed: %w", runtime, err)
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Error = installer.ErrTimeout
+		}
+	}
+	return result
+}
+
+// containerArgs writes the install script and the shared script library to runDir, mounted in
+// the container as /boba/run, and returns the container runtime arguments that run the script
+// with the variables the engine would set
+func containerArgs(image, root, runDir string, tool parser.Tool, plan *installer.DryRunPlan) ([]string, error) {
+	if err := os.WriteFile(filepath.Join(runDir, "install.sh"), plan.Script, 0755); err != nil {
+		return nil, err
+	}
+	libPath, err := installer.WriteScriptLibrary(runDir)
+	if err != nil {
+		return nil, err
+	}
+	
+	return []string{"run", "--rm",
+		"-v", root + ":/boba/repo:ro",
+		"-v", runDir + ":/boba/run",
+		"-w", "/boba/run",
+		"-e", "BOBA_TOOL_NAME=" + tool.Name,
+		"-e", fmt.Sprintf("BOBA_API_VERSION=%d", installer.APIVersion),
+		"-e", "BOBA_PLATFORM=linux",
+		"-e", "BOBA_INDEX_FRESH=0",
+		"-e", "BOBA_REPO_DIR=/boba/repo",
+		"-e", "BOBA_SCRIPT_DIR=/boba/repo/tools/" + tool.FolderName,
+		"-e", "BOBA_TEMP_DIR=/boba/run",
+		"-e", "BOBA_LIB=/boba/run/" + filepath.Base(libPath),
+		"-e", fmt.Sprintf("BOBA_LIB_VERSION=%d", installer.ScriptLibraryVersion),
+		image, "bash", "/boba/run/install.sh",
+	}, nil
+}
+
+// Write prints the report for the terminal
+func (r *Report) Write(w io.Writer) {
+	name := filepath.Base(r.Dir)
+	if r.Tool != nil && r.Tool.Name != "" {
+		name = r.Tool.Name
+	}
+	fmt.Fprintf(w, "Preview of %s (%s)\n\n", name, r.Dir)
+	
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, "✅ Manifest and scripts look good")
+	}
+	for _, finding := range r.Findings {
+		icon := "⚠️ "
+		if finding.Severity == SeverityError {
+			icon = "❌"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", icon, finding.Subject, finding.Message)
+	}
+	
+	if r.Plan != nil {
+		fmt.Fprintf(w, "\nDry run\n")
+		fmt.Fprintf(w, "  Script:      %s (%d bytes)\n", r.Plan.ScriptSource, len(r.Plan.Script))
+		fmt.Fprintf(w, "  Working dir: %s\n", r.Plan.WorkingDir)
+		if len(r.Plan.NotEvaluated) > 0 {
+			fmt.Fprintf(w, "  Not evaluated: %s (installing skips what they find in place)\n", strings.Join(r.Plan.NotEvaluated, ", "))
+		}
+		if len(r.Plan.Dependencies) > 0 {
+			fmt.Fprintf(w, "  Installs first: %s\n", strings.Join(r.Plan.Dependencies, ", "))
+		}
+		fmt.Fprintln(w, "  Environment:")
+		for _, entry := range r.Plan.Environment {
+			fmt.Fprintf(w, "    %s\n", entry)
+		}
+	}
+	
+	if r.Result != nil {
+		status := "✅ succeeded"
+		if !r.Result.Success {
+			status = fmt.Sprintf("❌ failed (exit code %d)", r.Result.ExitCode)
+		}
+		fmt.Fprintf(w, "\nExecution (%s) %s in %s\n", r.Executed, status, r.Result.Duration.Round(time.Millisecond))
+		if output := strings.TrimSpace(r.Result.Output); output != "" {
+			fmt.Fprintln(w, output)
+		}
+		if r.Result.Error != nil && !r.Result.Success {
+			fmt.Fprintf(w, "Error: %v\n", r.Result.Error)
+		}
+	} else if r.HasErrors() && r.Plan != nil {
+		fmt.Fprintln(w, "\nNot executed: fix the errors above first")
+	}
+}
+
+// Run implements the `boba preview` command and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	run := flags.Bool("run", false, "execute the install script on this machine")
+	container := flags.String("container", "", "execute the install script in a container of this `image` (docker or podman)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba preview [--run | --container image] <tools/name>")
+		fmt.Fprintln(stderr, "Validates the manifest, lints the scripts and shows a dry run of a tool folder.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	report, err := Preview(flags.Arg(0), Options{Run: *run, Container: *container})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	report.Write(stdout)
+	
+	if report.HasErrors() {
+		return exitcode.Failure
+	}
+	if report.Result != nil && !report.Result.Success {
+		switch {
+		case errors.Is(report.Result.Error, installer.ErrTimeout):
+			return exitcode.Timeout
+		case report.Result.ExitCode != 0:
+			return exitcode.Script
+		}
+		return exitcode.Failure
+	}
+	return exitcode.OK
+}
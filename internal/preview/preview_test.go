@@ -0,0 +1,100 @@
+package preview
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPreviewTool(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "tools", "hello", "tool.yaml"), "name: hello\ndescription: Says hello\ndependencies:\n  - missing-tool\n")
+	writeFile(t, filepath.Join(root, "tools", "hello", "install.sh"), "#!/bin/bash\necho \"hello from $BOBA_TOOL_NAME\"\n")
+	
+	report, err := Preview(filepath.Join(root, "tools", "hello"), Options{Run: true})
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if report.HasErrors() {
+		t.Fatalf("Unexpected errors: %+v", report.Findings)
+	}
+	
+	var warnings []string
+	for _, finding := range report.Findings {
+		warnings = append(warnings, finding.Subject+": "+finding.Message)
+	}
+	joined := strings.Join(warnings, "\n")
+	if !strings.Contains(joined, "missing-tool") || !strings.Contains(joined, "uninstall.sh") {
+		t.Errorf("Expected dependency and uninstall warnings, got:\n%s", joined)
+	}
+	
+	if report.Plan == nil || !strings.Contains(strings.Join(report.Plan.Environment, " "), "BOBA_TOOL_NAME=hello") {
+		t.Errorf("Expected dry run plan with BOBA_TOOL_NAME, got %+v", report.Plan)
+	}
+	if report.Result == nil || !report.Result.Success || !strings.Contains(report.Result.Output, "hello from hello") {
+		t.Errorf("Expected successful local run, got %+v", report.Result)
+	}
+	
+	var out bytes.Buffer
+	report.Write(&out)
+	if !strings.Contains(out.String(), "Dry run") {
+		t.Errorf("Expected dry run section in output:\n%s", out.String())
+	}
+}
+
+func TestPreviewReportsErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "tools", "broken", "tool.yaml"), "name: broken\ndescription: Broken tool\n")
+	writeFile(t, filepath.Join(root, "tools", "broken", "install.sh"), "#!/bin/bash\nif true; then\necho missing fi\n")
+	
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--run", filepath.Join(root, "tools", "broken")}, &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1, got %d\n%s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "install.sh") || strings.Contains(stdout.String(), "Execution") {
+		t.Errorf("Expected a syntax error and no execution:\n%s", stdout.String())
+	}
+	
+	if code := Run([]string{root}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected a non-tool folder to be rejected, got exit code %d", code)
+	}
+}
+
+func TestContainerArgs(t *testing.T) {
+	runDir := t.TempDir()
+	tool := parser.Tool{Name: "jq", FolderName: "jq"}
+	plan := &installer.DryRunPlan{Script: []byte(". \"$BOBA_LIB\"\nboba_log installing\n")}
+	
+	args, err := containerArgs("ubuntu:24.04", "/repo", runDir, tool, plan)
+	if err != nil {
+		t.Fatalf("containerArgs failed: %v", err)
+	}
+	
+	// The script library is mounted with the install script and named by BOBA_LIB
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"-e BOBA_LIB=/boba/run/boba-lib.sh", "-e BOBA_LIB_VERSION=", "-e BOBA_API_VERSION=", "-e BOBA_TOOL_NAME=jq"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q in the container arguments, got %s", want, joined)
+		}
+	}
+	for _, name := range []string{"install.sh", "boba-lib.sh"} {
+		if _, err := os.Stat(filepath.Join(runDir, name)); err != nil {
+			t.Errorf("Expected %s in the run directory: %v", name, err)
+		}
+	}
+}
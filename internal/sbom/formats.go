@@ -0,0 +1,218 @@
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"boba/internal/version"
+)
+
+// CycloneDX 1.5 JSON document (https://cyclonedx.org/docs/1.5/json/)
+type cdxDocument struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string        `json:"type"`
+	BOMRef             string        `json:"bom-ref,omitempty"`
+	Name               string        `json:"name"`
+	Version            string        `json:"version,omitempty"`
+	Hashes             []cdxHash     `json:"hashes,omitempty"`
+	ExternalReferences []cdxRef      `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxRef struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func cycloneDX(components []Component, generatedAt time.Time) cdxDocument {
+	document := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: generatedAt.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "boba", Version: version.Version}}},
+			Component: cdxComponent{Type: "device", Name: hostname()},
+		},
+		Components: []cdxComponent{},
+	}
+	
+	for _, c := range components {
+		component := cdxComponent{Type: "application", BOMRef: "boba-tool:" + c.Name, Name: c.Name, Version: c.Version}
+		if hash := c.hash(); hash != "" {
+			component.Hashes = []cdxHash{{Alg: "SHA-256", Content: hash}}
+		}
+		if c.Source != "" {
+			comment := "BOBA configuration repository"
+			if c.RepoCommit != "" {
+				comment += " at " + c.RepoCommit
+			}
+			component.ExternalReferences = []cdxRef{{Type: "vcs", URL: c.Source, Comment: comment}}
+		}
+		
+		property := func(name, value string) {
+			if value != "" {
+				component.Properties = append(component.Properties, cdxProperty{Name: "boba:" + name, Value: value})
+			}
+		}
+		property("install_method", c.InstallMethod)
+		property("install_type", c.InstallType)
+		property("package_manager", c.PackageManager)
+		property("repo_commit", c.RepoCommit)
+		property("script_sha256", c.ScriptHash)
+		if !c.InstallDate.IsZero() {
+			property("install_date", c.InstallDate.UTC().Format(time.RFC3339))
+		}
+		for _, binary := range c.Binaries {
+			property("binary", binary.Path)
+			property("binary_sha256:"+binary.Path, binary.SHA256)
+		}
+		document.Components = append(document.Components, component)
+	}
+	return document
+}
+
+// spdxIDUnsafe matches the characters SPDX identifiers may not contain
+var spdxIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// SPDX 2.3 JSON document (https://spdx.github.io/spdx-spec/v2.3/)
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string         `json:"name"`
+	SPDXID           string         `json:"SPDXID"`
+	VersionInfo      string         `json:"versionInfo,omitempty"`
+	DownloadLocation string         `json:"downloadLocation"`
+	FilesAnalyzed    bool           `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	LicenseDeclared  string         `json:"licenseDeclared"`
+	CopyrightText    string         `json:"copyrightText"`
+	Comment          string         `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdx(components []Component, generatedAt time.Time) spdxDocument {
+	host := hostname()
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "boba-" + host,
+		DocumentNamespace: fmt.Sprintf("https://boba.invalid/spdx/%s-%s", spdxIDUnsafe.ReplaceAllString(host, "-"), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  generatedAt.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: boba-" + version.Version},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+	
+	for _, c := range components {
+		id := "SPDXRef-Package-" + spdxIDUnsafe.ReplaceAllString(c.Name, "-")
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           id,
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			Comment:          spdxComment(c),
+		}
+		if c.Source != "" {
+			pkg.DownloadLocation = c.Source
+		}
+		if hash := c.hash(); hash != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: hash}}
+		}
+		document.Packages = append(document.Packages, pkg)
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+	return document
+}
+
+// spdxComment describes how BOBA installed the package, since SPDX has no fields for it
+func spdxComment(c Component) string {
+	comment := "Installed by BOBA"
+	if c.InstallType != "" {
+		comment += fmt.Sprintf(" (%s install", c.InstallType)
+		if c.PackageManager != "" {
+			comment += " via " + c.PackageManager
+		}
+		comment += ")"
+	}
+	if c.RepoCommit != "" {
+		comment += "; configuration repository commit " + c.RepoCommit
+	}
+	if c.ScriptHash != "" {
+		comment += "; install script SHA-256 " + c.ScriptHash
+	}
+	for _, binary := range c.Binaries {
+		if binary.SHA256 != "" {
+			comment += fmt.Sprintf("; %s SHA-256 %s", binary.Path, binary.SHA256)
+		} else {
+			comment += fmt.Sprintf("; %s (missing)", binary.Path)
+		}
+	}
+	return comment
+}
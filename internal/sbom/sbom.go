@@ -0,0 +1,164 @@
+// Package sbom implements `boba sbom`, a software bill of materials of the tools BOBA installed
+// on this workstation, in CycloneDX or SPDX JSON, for security reviews of developer machines.
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/installer"
+)
+
+// Supported output formats
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Component is a tool BOBA installed, with what is known about where it came from
+type Component struct {
+	Name           string
+	Version        string
+	InstallDate    time.Time
+	InstallMethod  string // "auto" or "manual"
+	InstallType    string // "script", "inline" or "catalog"; empty for tools installed before provenance was recorded
+	Source         string // Configuration repository the install script came from
+	RepoCommit     string
+	PackageManager string
+	ScriptHash     string // SHA-256 of the install script that ran
+	Binaries       []Binary
+}
+
+// Binary is an executable the tool's install put on PATH
+type Binary struct {
+	Path   string
+	SHA256 string // Empty when the file is gone or unreadable
+}
+
+// Collect builds the components from the installation records, hashing the recorded binaries
+// that still exist
+func Collect(installed map[string]config.InstalledTool, repositoryURL string) []Component {
+	var components []Component
+	for _, tool := range installed {
+		component := Component{
+			Name:          tool.Name,
+			Version:       tool.Version,
+			InstallDate:   tool.InstallDate,
+			InstallMethod: tool.InstallMethod,
+			Source:        repositoryURL,
+		}
+		if provenance := tool.Provenance; provenance != nil {
+			component.InstallType = provenance.InstallType
+			component.RepoCommit = provenance.RepoCommit
+			component.PackageManager = provenance.PackageManager
+			component.ScriptHash = provenance.ScriptHash
+			for _, path := range provenance.BinaryPaths {
+				checksum, _ := installer.FileChecksum(path)
+				component.Binaries = append(component.Binaries, Binary{Path: path, SHA256: checksum})
+			}
+		}
+		components = append(components, component)
+	}
+	
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return components
+}
+
+// hash returns the SHA-256 identifying the component: its binary when it installed exactly one
+func (c Component) hash() string {
+	if len(c.Binaries) == 1 {
+		return c.Binaries[0].SHA256
+	}
+	return ""
+}
+
+// Write writes the components in the given format
+func Write(w io.Writer, format string, components []Component, generatedAt time.Time) error {
+	var document interface{}
+	switch format {
+	case FormatCycloneDX:
+		document = cycloneDX(components, generatedAt)
+	case FormatSPDX:
+		document = spdx(components, generatedAt)
+	default:
+		return fmt.Errorf("unknown SBOM format %q (use %s or %s)", format, FormatCycloneDX, FormatSPDX)
+	}
+	
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// newUUID returns a random (version 4) UUID for document serial numbers and namespaces
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "00000000-0000-4000-8000-000000000000"
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// hostname names the workstation the SBOM describes
+func hostname() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "workstation"
+}
+
+// Run implements `boba sbom [--format cyclonedx|spdx] [--output file]` and returns the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", FormatCycloneDX, "output `format`: cyclonedx or spdx")
+	output := flags.String("output", "", "write the SBOM to this `file` instead of standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba sbom [--format cyclonedx|spdx] [--output file]")
+		fmt.Fprintln(stderr, "Writes a software bill of materials of the tools BOBA installed on this machine.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadConfig(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	cfg := configManager.GetConfig()
+	components := Collect(cfg.InstalledTools, cfg.RepositoryURL)
+	
+	w := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := Write(w, *format, components, time.Now()); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if *output != "" {
+		fmt.Fprintf(stdout, "Wrote %s SBOM of %d tools to %s\n", *format, len(components), *output)
+	}
+	return exitcode.OK
+}
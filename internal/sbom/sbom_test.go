@@ -0,0 +1,82 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"boba/internal/config"
+)
+
+func TestSBOM(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "rg")
+	if err := os.WriteFile(binary, []byte("ripgrep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	installed := map[string]config.InstalledTool{
+		"ripgrep": {
+			Name:          "ripgrep",
+			Version:       "14.1.0",
+			InstallMethod: "auto",
+			Provenance: &config.ToolProvenance{
+				InstallType:    "script",
+				ScriptHash:     "abc123",
+				RepoCommit:     "deadbeef",
+				PackageManager: "apt",
+				BinaryPaths:    []string{binary},
+			},
+		},
+		"fzf": {Name: "fzf", Version: "latest", InstallMethod: "manual"},
+	}
+	
+	components := Collect(installed, "https://github.com/acme/dotfiles")
+	if len(components) != 2 || components[0].Name != "fzf" || components[1].Name != "ripgrep" {
+		t.Fatalf("Expected components sorted by name, got %+v", components)
+	}
+	if hash := components[1].Binaries[0].SHA256; len(hash) != 64 {
+		t.Fatalf("Expected the binary to be hashed, got %q", hash)
+	}
+	
+	generatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var cdx bytes.Buffer
+	if err := Write(&cdx, FormatCycloneDX, components, generatedAt); err != nil {
+		t.Fatalf("Failed to write CycloneDX: %v", err)
+	}
+	var cdxDoc cdxDocument
+	if err := json.Unmarshal(cdx.Bytes(), &cdxDoc); err != nil {
+		t.Fatalf("Invalid CycloneDX JSON: %v", err)
+	}
+	rg := cdxDoc.Components[1]
+	if cdxDoc.BOMFormat != "CycloneDX" || rg.Version != "14.1.0" || len(rg.Hashes) != 1 || rg.Hashes[0].Content != components[1].Binaries[0].SHA256 {
+		t.Errorf("Unexpected CycloneDX component: %+v", rg)
+	}
+	if len(rg.ExternalReferences) != 1 || !strings.Contains(rg.ExternalReferences[0].Comment, "deadbeef") {
+		t.Errorf("Expected the configuration repository as source, got %+v", rg.ExternalReferences)
+	}
+	
+	var spdxOut bytes.Buffer
+	if err := Write(&spdxOut, FormatSPDX, components, generatedAt); err != nil {
+		t.Fatalf("Failed to write SPDX: %v", err)
+	}
+	var spdxDoc spdxDocument
+	if err := json.Unmarshal(spdxOut.Bytes(), &spdxDoc); err != nil {
+		t.Fatalf("Invalid SPDX JSON: %v", err)
+	}
+	if spdxDoc.SPDXVersion != "SPDX-2.3" || len(spdxDoc.Packages) != 2 || len(spdxDoc.Relationships) != 2 {
+		t.Fatalf("Unexpected SPDX document: %+v", spdxDoc)
+	}
+	if pkg := spdxDoc.Packages[1]; pkg.SPDXID != "SPDXRef-Package-ripgrep" || len(pkg.Checksums) != 1 || !strings.Contains(pkg.Comment, "script install via apt") {
+		t.Errorf("Unexpected SPDX package: %+v", pkg)
+	}
+	if pkg := spdxDoc.Packages[0]; len(pkg.Checksums) != 0 || pkg.DownloadLocation != "https://github.com/acme/dotfiles" {
+		t.Errorf("Expected no checksum for a tool without provenance, got %+v", pkg)
+	}
+	
+	if err := Write(&bytes.Buffer{}, "xml", components, generatedAt); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,87 @@
+package scaffold
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"boba/internal/exitcode"
+)
+
+// FindRepositoryRoot returns the configuration repository containing dir: the nearest folder,
+// from dir upwards, holding tools/, environments/ or a boba.yaml catalog
+func FindRepositoryRoot(dir string) (string, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, marker := range []string{"tools", "environments", "boba.yaml"} {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current, nil
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("%s is not inside a BOBA configuration repository: run boba init first", dir)
+		}
+		current = parent
+	}
+}
+
+// NewTool creates the tools/<name> folder of the repository at root and returns the files it
+// wrote, relative to root. It refuses to touch an existing tool folder.
+func NewTool(root, name string) ([]string, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(root, "tools", name)); err == nil {
+		return nil, fmt.Errorf("tools/%s already exists", name)
+	}
+	files := make(map[string]string)
+	for file, content := range toolFiles(name) {
+		files[filepath.Join("tools", name, file)] = content
+	}
+	return writeFiles(root, files)
+}
+
+// RunNew implements `boba new tool [--repo dir] <name>` and returns the exit code
+func RunNew(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := flags.String("repo", ".", "a folder inside the local clone of the configuration repository")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba new tool [--repo dir] <name>")
+		fmt.Fprintln(stderr, "Creates tools/<name> with a tool.yaml and install and uninstall scripts to edit.")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "tool" {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	
+	root, err := FindRepositoryRoot(*repo)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	written, err := NewTool(root, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	for _, path := range written {
+		fmt.Fprintf(stdout, "created %s\n", filepath.Join(root, path))
+	}
+	fmt.Fprintf(stdout, "Check it with: boba preview %s\n", filepath.Join(root, "tools", flags.Arg(0)))
+	return exitcode.OK
+}
@@ -0,0 +1,170 @@
+// Package scaffold implements `boba init`, which creates a configuration repository with the
+// layout BOBA expects: tools/<name>/ with tool.yaml, install.sh and uninstall.sh, and
+// environments/<name>/ with environment.yaml, setup.sh and restore.sh. It can also create the
+// repository on GitHub and push the first commit. `boba new tool` adds a tool folder to an
+// existing repository.
+package scaffold
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/exitcode"
+	"boba/internal/github"
+)
+
+// DefaultRepositoryName is the repository BOBA looks for when none is configured
+const DefaultRepositoryName = "boba-config"
+
+// Options selects what Init generates
+type Options struct {
+	Tools        []string // Tool folders to create
+	Environments []string // Environment folders to create
+}
+
+// DefaultOptions generates one example tool and one example environment
+func DefaultOptions() Options {
+	return Options{Tools: []string{"hello"}, Environments: []string{"shell"}}
+}
+
+// Init creates the repository layout in dir and returns the files it wrote, relative to dir.
+// Existing files are kept, so it can add folders to an existing repository.
+func Init(dir string, options Options) ([]string, error) {
+	files := make(map[string]string)
+	for _, name := range options.Tools {
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		for file, content := range toolFiles(name) {
+			files[filepath.Join("tools", name, file)] = content
+		}
+	}
+	for _, name := range options.Environments {
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		for file, content := range environmentFiles(name) {
+			files[filepath.Join("environments", name, file)] = content
+		}
+	}
+	files["README.md"] = readme
+	return writeFiles(dir, files)
+}
+
+// writeFiles writes the files, keyed by their path relative to dir, skipping the existing ones
+func writeFiles(dir string, files map[string]string) ([]string, error) {
+	var written []string
+	for _, path := range sortedKeys(files) {
+		target := filepath.Join(dir, path)
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return written, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(path, ".sh") {
+			mode = 0755
+		}
+		if err := os.WriteFile(target, []byte(files[path]), mode); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// validateName refuses folder names that are empty or leave their parent folder
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid folder name %q", name)
+	}
+	return nil
+}
+
+// Run implements `boba init [--tools a,b] [--envs a,b] [--create [--private]] [dir]` and returns
+// the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	defaults := DefaultOptions()
+	tools := flags.String("tools", strings.Join(defaults.Tools, ","), "comma-separated tool folders to create")
+	envs := flags.String("envs", strings.Join(defaults.Environments, ","), "comma-separated environment folders to create")
+	create := flags.Bool("create", false, "create the repository on GitHub with the saved token and push the first commit")
+	private := flags.Bool("private", true, "with --create, make the GitHub repository private")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: boba init [--tools a,b] [--envs a,b] [--create [--private=false]] [dir]")
+		fmt.Fprintf(stderr, "Creates a BOBA configuration repository in dir (default ./%s).\n", DefaultRepositoryName)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return exitcode.Usage
+	}
+	dir := DefaultRepositoryName
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	
+	written, err := Init(dir, Options{Tools: splitList(*tools), Environments: splitList(*envs)})
+	for _, path := range written {
+		fmt.Fprintf(stdout, "created %s\n", filepath.Join(dir, path))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if !*create {
+		return exitcode.OK
+	}
+	
+	configManager := config.NewConfigManager()
+	if err := configManager.LoadCredentials(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	token := configManager.GetCredentials().GitHubToken
+	if token == "" {
+		fmt.Fprintln(stderr, "Error: no GitHub token saved: run boba to authenticate first")
+		return exitcode.Auth
+	}
+	
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	client := github.NewGitHubClient(token, "", "")
+	if err := client.CreateRepository(filepath.Base(absDir), "BOBA tools and environments", *private); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	if err := client.PushNewRepository(absDir, "Initial BOBA configuration"); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Failure
+	}
+	fmt.Fprintf(stdout, "pushed to https://github.com/%s\n", client.GetFullRepoName())
+	if client.GetRepo() != DefaultRepositoryName {
+		fmt.Fprintf(stdout, "Select it in BOBA under Configuration → Repository Configuration (%s)\n", client.GetRepo())
+	}
+	return exitcode.OK
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,112 @@
+package scaffold
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boba/internal/github"
+	"boba/internal/parser"
+)
+
+func TestInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "boba-config")
+	written, err := Init(dir, Options{Tools: []string{"hello", "git"}, Environments: []string{"shell"}})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if len(written) != 10 {
+		t.Errorf("Expected 10 files, got %v", written)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "tools", "hello", "install.sh")); err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("Expected an executable install.sh: %v", err)
+	}
+	
+	// The generated layout parses like any repository
+	rp := parser.NewRepositoryParserFromSource(github.NewLocalRepository(dir))
+	tools, err := rp.FetchTools()
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d: %v", len(tools), err)
+	}
+	envs, err := rp.FetchEnvironments()
+	if err != nil || len(envs) != 1 || envs[0].Name != "shell" || envs[0].Shell != "bash" {
+		t.Fatalf("Expected the shell environment, got %+v: %v", envs, err)
+	}
+	
+	// Existing files are kept
+	os.WriteFile(filepath.Join(dir, "tools", "hello", "tool.yaml"), []byte("name: mine\n"), 0644)
+	written, err = Init(dir, Options{Tools: []string{"hello", "node"}})
+	if err != nil || strings.Join(written, " ") != strings.Join([]string{
+		filepath.Join("tools", "node", "install.sh"),
+		filepath.Join("tools", "node", "tool.yaml"),
+		filepath.Join("tools", "node", "uninstall.sh"),
+	}, " ") {
+		t.Errorf("Expected only the new tool to be written, got %v: %v", written, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "tools", "hello", "tool.yaml")); string(data) != "name: mine\n" {
+		t.Errorf("Expected the existing tool.yaml to be kept, got %q", data)
+	}
+	
+	if _, err := Init(dir, Options{Tools: []string{"../escape"}}); err == nil {
+		t.Error("Expected a folder name leaving tools/ to be refused")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--tools", "jq", "--envs", "", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected init to succeed, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), filepath.Join(dir, "tools", "jq", "tool.yaml")) {
+		t.Errorf("Expected the created files to be listed, got:\n%s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "environments")); !os.IsNotExist(err) {
+		t.Error("Expected no environments with --envs \"\"")
+	}
+	
+	if code := Run([]string{"a", "b"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected two directories to be a usage error, got %d", code)
+	}
+}
+
+func TestNewTool(t *testing.T) {
+	root := t.TempDir()
+	if _, err := FindRepositoryRoot(root); err == nil {
+		t.Error("Expected a folder without tools/ to be refused")
+	}
+	os.MkdirAll(filepath.Join(root, "tools"), 0755)
+	nested := filepath.Join(root, "tools", "nested")
+	os.MkdirAll(nested, 0755)
+	
+	var stdout, stderr bytes.Buffer
+	if code := RunNew([]string{"tool", "--repo", nested, "ripgrep"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected the tool to be created, got %d: %s", code, stderr.String())
+	}
+	install, err := os.ReadFile(filepath.Join(root, "tools", "ripgrep", "install.sh"))
+	if err != nil || !strings.Contains(string(install), `case "$BOBA_PACKAGE_MANAGER" in`) || !strings.Contains(string(install), "apt-get install -y ripgrep") {
+		t.Errorf("Expected an install script per package manager, got:\n%s", install)
+	}
+	if _, err := os.Stat(filepath.Join(root, "README.md")); !os.IsNotExist(err) {
+		t.Error("Expected only the tool folder to be written")
+	}
+	
+	rp := parser.NewRepositoryParserFromSource(github.NewLocalRepository(root))
+	tools, _ := rp.FetchTools()
+	found := false
+	for _, tool := range tools {
+		found = found || (tool.Name == "ripgrep" && tool.LibVersion == 1)
+	}
+	if !found {
+		t.Errorf("Expected the new tool to parse, got %+v", tools)
+	}
+	
+	if code := RunNew([]string{"tool", "--repo", root, "ripgrep"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "already exists") {
+		t.Errorf("Expected an existing tool to be refused, got %d: %s", code, stderr.String())
+	}
+	if code := RunNew([]string{"env", "shell"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected only tools to be supported, got %d", code)
+	}
+}
@@ -0,0 +1,111 @@
+package scaffold
+
+import (
+	"fmt"
+	"sort"
+)
+
+// toolFiles returns the files of a tools/<name> folder. The scripts install and remove the
+// package of the same name with the system package manager, as a starting point to edit.
+func toolFiles(name string) map[string]string {
+	return map[string]string{
+		"tool.yaml": fmt.Sprintf(`name: %s
+description: Describe what %s is for
+auto_install: false
+lib_version: 1
+# dependencies:
+#   - git
+# homepage: https://example.com/%s
+`, name, name, name),
+		"install.sh": fmt.Sprintf(`#!/bin/bash
+# Installs %[1]s. BOBA sets BOBA_PLATFORM (linux, darwin) and BOBA_PACKAGE_MANAGER
+# (apt, dnf, yum, pacman, zypper, apk or brew) before running it.
+set -euo pipefail
+. "$BOBA_LIB"
+
+if boba_has %[1]s; then
+	boba_log_info "already installed"
+	exit 0
+fi
+
+SUDO=sudo
+if [ "$(id -u)" -eq 0 ]; then
+	SUDO=
+fi
+
+case "$BOBA_PACKAGE_MANAGER" in
+	apt)     $SUDO apt-get install -y %[1]s ;;
+	dnf|yum) $SUDO "$BOBA_PACKAGE_MANAGER" install -y %[1]s ;;
+	pacman)  $SUDO pacman -S --noconfirm %[1]s ;;
+	zypper)  $SUDO zypper install -y %[1]s ;;
+	apk)     $SUDO apk add %[1]s ;;
+	brew)    brew install %[1]s ;;
+	*)       boba_die "no install steps for $BOBA_PACKAGE_MANAGER on $BOBA_PLATFORM" ;;
+esac
+`, name),
+		"uninstall.sh": fmt.Sprintf(`#!/bin/bash
+# Removes %[1]s with the package manager that installed it
+set -euo pipefail
+. "$BOBA_LIB"
+
+SUDO=sudo
+if [ "$(id -u)" -eq 0 ]; then
+	SUDO=
+fi
+
+case "$BOBA_PACKAGE_MANAGER" in
+	apt)     $SUDO apt-get remove -y %[1]s ;;
+	dnf|yum) $SUDO "$BOBA_PACKAGE_MANAGER" remove -y %[1]s ;;
+	pacman)  $SUDO pacman -R --noconfirm %[1]s ;;
+	zypper)  $SUDO zypper remove -y %[1]s ;;
+	apk)     $SUDO apk del %[1]s ;;
+	brew)    brew uninstall %[1]s ;;
+	*)       boba_die "no uninstall steps for $BOBA_PACKAGE_MANAGER on $BOBA_PLATFORM" ;;
+esac
+`, name),
+	}
+}
+
+// environmentFiles returns the files of an environments/<name> folder
+func environmentFiles(name string) map[string]string {
+	return map[string]string{
+		"environment.yaml": fmt.Sprintf(`name: %s
+description: Describe what %s sets up
+shell: bash
+auto_apply: false
+`, name, name),
+		"setup.sh": fmt.Sprintf(`#!/bin/bash
+# Applies %s, e.g. by copying dotfiles from $BOBA_SCRIPT_DIR into $HOME
+set -euo pipefail
+
+echo "Applying $BOBA_ENV_NAME for $BOBA_ENV_SHELL"
+`, name),
+		"restore.sh": fmt.Sprintf(`#!/bin/bash
+# Reverts what setup.sh of %s changed
+set -euo pipefail
+
+echo "Restoring $BOBA_ENV_NAME"
+`, name),
+	}
+}
+
+// readme introduces the repository layout
+const readme = `# BOBA configuration
+
+Tools and environments installed by [BOBA](https://github.com/Walter0697/Boba).
+
+- ` + "`tools/<name>/`" + `: ` + "`tool.yaml`" + `, ` + "`install.sh`" + ` and ` + "`uninstall.sh`" + `
+- ` + "`environments/<name>/`" + `: ` + "`environment.yaml`" + `, ` + "`setup.sh`" + ` and ` + "`restore.sh`" + `
+
+Check a tool with ` + "`boba preview tools/<name>`" + ` before pushing it.
+`
+
+// sortedKeys returns the keys of files in order, so files are written and reported predictably
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
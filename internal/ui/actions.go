@@ -1,15 +1,28 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/config"
 	"boba/internal/github"
 	"boba/internal/installer"
+	"boba/internal/log"
 	"boba/internal/parser"
 )
 
+// describeError returns the message shown for an error: when the GitHub rate limit is used up,
+// the time it resets rather than the API error
+func describeError(err error) string {
+	if message, ok := github.RateLimitMessage(err); ok {
+		return message
+	}
+	return err.Error()
+}
+
 // startAuthentication initiates the GitHub authentication flow
 func (m MenuModel) startAuthentication() (tea.Model, tea.Cmd) {
 	// Get configured repository or use default
@@ -33,6 +46,8 @@ func (m MenuModel) startAuthentication() (tea.Model, tea.Cmd) {
 	}
 	
 	authModel := github.NewAuthModelWithRepo(repoURL, onComplete, onCancel)
+	authModel.SetCloneOptions(cloneOptionsFromConfig(m.configManager))
+	authModel.SetDeviceClientID(deviceClientID(m.configManager))
 	m.authModel = authModel
 	m.navigateToMenu(GitHubAuthMenu)
 	
@@ -62,6 +77,7 @@ func (m MenuModel) startRepositoryConfiguration() (tea.Model, tea.Cmd) {
 	}
 	
 	repoConfigModel := github.NewRepoConfigModel(currentRepo, onComplete, onCancel)
+	repoConfigModel.SetCloneOptions(cloneOptionsFromConfig(m.configManager))
 	m.authModel = repoConfigModel
 	
 	// Navigate to repository configuration
@@ -87,7 +103,7 @@ func (m MenuModel) fetchAndDisplayTools() (tea.Model, tea.Cmd) {
 	return m, func() tea.Msg {
 		tools, err := m.repoParser.GetTools()
 		if err != nil {
-			return fmt.Sprintf("error_fetching_tools: %v", err)
+			return fmt.Sprintf("error_fetching_tools: %s", describeError(err))
 		}
 		return ToolsListMsg{Tools: tools}
 	}
@@ -108,9 +124,9 @@ func (m MenuModel) fetchAndDisplayEnvironments() (tea.Model, tea.Cmd) {
 	m.choices = m.getMenuChoices()
 	
 	return m, func() tea.Msg {
-		environments, err := m.repoParser.FetchEnvironments()
+		environments, err := m.repoParser.GetEnvironments()
 		if err != nil {
-			return fmt.Sprintf("error_fetching_environments: %v", err)
+			return fmt.Sprintf("error_fetching_environments: %s", describeError(err))
 		}
 		return EnvironmentsListMsg{Environments: environments}
 	}
@@ -127,6 +143,8 @@ func (m MenuModel) startInstallEverything() (tea.Model, tea.Cmd) {
 	// Set installation in progress
 	m.installationInProgress = true
 	m.loadingMessage = "Preparing installation..."
+	m.sessionNotice = ""
+	m.planNotice = ""
 	m.choices = m.getMenuChoices()
 	
 	return m, m.runInstallEverythingWithProgress()
@@ -226,12 +244,31 @@ func (m MenuModel) installSingleTool(tool parser.Tool) (tea.Model, tea.Cmd) {
 			}
 		}
 		
+		// Refresh the package index once for this batch (non-fatal on failure)
+		m.installEngine.BeginRun()
+		if err := m.installEngine.BeginBatch("install " + tool.Name); err != nil {
+			return InstallationProgressMsg{
+				ToolName: tool.Name,
+				Status:   fmt.Sprintf("Installation failed: %s", describeError(err)),
+				Success:  false,
+			}
+		}
+		defer m.installEngine.EndBatch()
+		if _, err := m.installEngine.EnsurePackageIndexFresh(toolsToInstall); err != nil {
+			log.Warn("Failed to refresh the package index", "error", err)
+		}
+		
 		// Install tools in dependency order
 		var results []string
 		for _, toolToInstall := range toolsToInstall {
 			result, err := m.installEngine.InstallTool(toolToInstall)
 			
 			success := result.Success && err == nil
+			if success && result.DryRun {
+				results = append(results, result.Output)
+				continue
+			}
+			m.recordToolRun(toolToInstall.Name, success, result)
 			if success {
 				m.toolInstallStatus[toolToInstall.Name] = true
 				
@@ -240,14 +277,23 @@ func (m MenuModel) installSingleTool(tool parser.Tool) (tea.Model, tea.Cmd) {
 				if version == "" {
 					version = "latest"
 				}
-				m.configManager.RecordToolInstallation(toolToInstall.Name, version, "manual")
+				m.configManager.RecordToolInstallationWithProvenance(toolToInstall.Name, version, "manual", result.Provenance)
 				results = append(results, fmt.Sprintf("✓ %s installed successfully", toolToInstall.Name))
+				if len(result.Steps) > 0 {
+					results = append(results, installer.StepSummary(result.Steps))
+				}
 			} else {
 				message := result.Output
 				if err != nil {
-					message = fmt.Sprintf("Installation failed: %v", err)
+					message = fmt.Sprintf("Installation failed: %s", describeError(err))
+				}
+				if result.TempDir != "" {
+					message += fmt.Sprintf(" (temp files kept in %s)", result.TempDir)
 				}
 				results = append(results, fmt.Sprintf("✗ %s failed: %s", toolToInstall.Name, message))
+				if len(result.Steps) > 0 {
+					results = append(results, installer.StepSummary(result.Steps))
+				}
 				
 				// If a dependency fails, stop the installation
 				return InstallationProgressMsg{
@@ -285,7 +331,7 @@ func (m MenuModel) applyEnvironment(env parser.Environment) (tea.Model, tea.Cmd)
 	
 	return m, func() tea.Msg {
 		// Get all available environments to resolve dependencies
-		allEnvironments, err := m.repoParser.FetchEnvironments()
+		allEnvironments, err := m.repoParser.GetEnvironments()
 		if err != nil {
 			return InstallationProgressMsg{
 				ToolName: env.Name,
@@ -348,19 +394,54 @@ func (m MenuModel) applyEnvironment(env parser.Environment) (tea.Model, tea.Cmd)
 			}
 		}
 		
+		if conflicts := m.unresolvedFileConflicts(environmentsToApply); len(conflicts) > 0 {
+			_, resume := m.applyEnvironment(env)
+			return FileConflictsMsg{Conflicts: conflicts, Resume: resume}
+		}
+		if edited := m.unresolvedEditedFiles(environmentsToApply); len(edited) > 0 {
+			_, resume := m.applyEnvironment(env)
+			return EditedFilesMsg{Files: edited, Resume: resume}
+		}
+		
+		m.saveRestorePoint(fmt.Sprintf("Before applying %s", env.Name), environmentsToApply)
+		
+		// Start a fresh run so follow-up actions only reflect this application
+		m.installEngine.BeginRun()
+		if err := m.installEngine.BeginBatch("apply " + env.Name); err != nil {
+			return InstallationProgressMsg{
+				ToolName: env.Name,
+				Status:   fmt.Sprintf("Application failed: %s", describeError(err)),
+				Success:  false,
+			}
+		}
+		defer m.installEngine.EndBatch()
+		
 		// Apply environments in dependency order
 		var results []string
 		for _, envToApply := range environmentsToApply {
 			result, err := m.installEngine.ApplyEnvironment(envToApply)
 			
 			success := result.Success && err == nil
+			if success && result.DryRun {
+				results = append(results, result.Output)
+				continue
+			}
 			if success {
 				// Record successful application
+				if m.configManager != nil {
+					m.configManager.RecordEnvironmentApplied(envToApply.Name)
+				}
 				results = append(results, fmt.Sprintf("✓ %s applied successfully", envToApply.Name))
+				for _, note := range result.FileNotes {
+					results = append(results, "  → "+note)
+				}
 			} else {
 				message := result.Output
 				if err != nil {
-					message = fmt.Sprintf("Application failed: %v", err)
+					message = fmt.Sprintf("Application failed: %s", describeError(err))
+				}
+				if result.TempDir != "" {
+					message += fmt.Sprintf(" (temp files kept in %s)", result.TempDir)
 				}
 				results = append(results, fmt.Sprintf("✗ %s failed: %s", envToApply.Name, message))
 				
@@ -381,26 +462,68 @@ func (m MenuModel) applyEnvironment(env parser.Environment) (tea.Model, tea.Cmd)
 	}
 }
 
-// toggleToolOverride toggles the override setting for a tool
-func (m MenuModel) toggleToolOverride(tool parser.Tool) (tea.Model, tea.Cmd) {
-	config := m.configManager.GetConfig()
-	
-	// Initialize ToolOverrides map if it doesn't exist
-	if config.ToolOverrides == nil {
-		config.ToolOverrides = make(map[string]bool)
+// restoreEnvironment reverts an environment with its restore script and clears its applied record
+func (m MenuModel) restoreEnvironment(env parser.Environment) (tea.Model, tea.Cmd) {
+	if m.installEngine == nil {
+		return m, func() tea.Msg {
+			return InstallationProgressMsg{
+				ToolName: env.Name,
+				Status:   "Installation engine not initialized",
+				Success:  false,
+			}
+		}
 	}
 	
+	m.isLoading = true
+	m.loadingMessage = fmt.Sprintf("Restoring environment: %s", env.Name)
+	m.choices = m.getMenuChoices()
+	
+	return m, func() tea.Msg {
+		m.installEngine.BeginRun()
+		result, err := m.installEngine.RestoreEnvironment(env)
+		if err != nil || result == nil || !result.Success {
+			message := ""
+			if result != nil {
+				message = result.Output
+			}
+			if err != nil {
+				message = fmt.Sprintf("Restore failed: %s", describeError(err))
+			}
+			if result != nil && result.TempDir != "" {
+				message += fmt.Sprintf(" (temp files kept in %s)", result.TempDir)
+			}
+			return InstallationProgressMsg{
+				ToolName: env.Name,
+				Status:   fmt.Sprintf("✗ %s restore failed: %s", env.Name, message),
+				Success:  false,
+			}
+		}
+		
+		if result.DryRun {
+			return InstallationProgressMsg{ToolName: env.Name, Status: result.Output, Success: true}
+		}
+		if m.configManager != nil {
+			m.configManager.RemoveAppliedEnvironment(env.Name)
+		}
+		return InstallationProgressMsg{
+			ToolName: env.Name,
+			Status:   fmt.Sprintf("✓ %s restored successfully", env.Name),
+			Success:  true,
+		}
+	}
+}
+
+// toggleToolOverride toggles the override setting for a tool
+func (m MenuModel) toggleToolOverride(tool parser.Tool) (tea.Model, tea.Cmd) {
 	// Check current override state
-	if enabled, exists := config.ToolOverrides[tool.Name]; exists {
-		// Toggle the existing override
-		config.ToolOverrides[tool.Name] = !enabled
-	} else {
+	enabled, exists := m.configManager.GetToolOverride(tool.Name)
+	if !exists {
 		// No override exists, create one with opposite of default
-		config.ToolOverrides[tool.Name] = !tool.AutoInstall
+		enabled = tool.AutoInstall
 	}
 	
-	// Save the configuration
-	err := m.configManager.SaveConfig()
+	// Toggle the override (saved in batches, flushed when leaving the menu)
+	err := m.configManager.SetToolOverride(tool.Name, !enabled)
 	if err != nil {
 		return m, func() tea.Msg {
 			return fmt.Sprintf("error_config: Failed to save configuration: %v", err)
@@ -415,24 +538,15 @@ func (m MenuModel) toggleToolOverride(tool parser.Tool) (tea.Model, tea.Cmd) {
 
 // toggleEnvironmentOverride toggles the override setting for an environment
 func (m MenuModel) toggleEnvironmentOverride(env parser.Environment) (tea.Model, tea.Cmd) {
-	config := m.configManager.GetConfig()
-	
-	// Initialize EnvironmentOverrides map if it doesn't exist
-	if config.EnvironmentOverrides == nil {
-		config.EnvironmentOverrides = make(map[string]bool)
-	}
-	
 	// Check current override state
-	if enabled, exists := config.EnvironmentOverrides[env.Name]; exists {
-		// Toggle the existing override
-		config.EnvironmentOverrides[env.Name] = !enabled
-	} else {
+	enabled, exists := m.configManager.GetEnvironmentOverride(env.Name)
+	if !exists {
 		// No override exists, create one with opposite of default
-		config.EnvironmentOverrides[env.Name] = !env.AutoApply
+		enabled = env.AutoApply
 	}
 	
-	// Save the configuration
-	err := m.configManager.SaveConfig()
+	// Toggle the override (saved in batches, flushed when leaving the menu)
+	err := m.configManager.SetEnvironmentOverride(env.Name, !enabled)
 	if err != nil {
 		return m, func() tea.Msg {
 			return fmt.Sprintf("error_config: Failed to save configuration: %v", err)
@@ -475,6 +589,25 @@ func (m MenuModel) resetAllEnvironmentOverrides() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// installTargetChoice offers the install target Install BOBA to System doesn't use yet
+func (m MenuModel) installTargetChoice() string {
+	if m.systemInstaller != nil && m.systemInstaller.Target() == installer.InstallTargetUser {
+		return "🔀 Install to /usr/local/bin instead (all users)"
+	}
+	return "🔀 Install to ~/.local/bin instead (no sudo)"
+}
+
+// switchInstallTarget installs to the other target: ~/.local/bin or /usr/local/bin
+func (m MenuModel) switchInstallTarget() (tea.Model, tea.Cmd) {
+	if m.systemInstaller.Target() == installer.InstallTargetUser {
+		m.systemInstaller.SetTarget(installer.InstallTargetSystem)
+	} else {
+		m.systemInstaller.SetTarget(installer.InstallTargetUser)
+	}
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
 // startSystemInstallation initiates the system installation process
 func (m MenuModel) startSystemInstallation() (tea.Model, tea.Cmd) {
 	if m.systemInstaller == nil {
@@ -495,7 +628,21 @@ func (m MenuModel) startSystemInstallation() (tea.Model, tea.Cmd) {
 	m.systemInstallResult = nil // Clear any previous result
 	m.choices = m.getMenuChoices()
 	
+	// A reinstall re-verifies the system copy against the checksum recorded when it was installed
+	installPath := m.systemInstaller.InstallPath()
+	expected, recorded := "", false
+	if m.configManager != nil {
+		expected, recorded = m.configManager.GetBinaryChecksum(installPath)
+	}
+	
 	return m, func() tea.Msg {
+		warning := ""
+		if recorded {
+			if err := installer.VerifyChecksum(installPath, expected); errors.Is(err, installer.ErrChecksumMismatch) {
+				warning = fmt.Sprintf("The previous copy at %s had changed since BOBA installed it", installPath)
+			}
+		}
+		
 		result, err := m.systemInstaller.InstallToSystem()
 		if err != nil && result == nil {
 			result = &installer.SystemInstallationResult{
@@ -504,12 +651,29 @@ func (m MenuModel) startSystemInstallation() (tea.Model, tea.Cmd) {
 				Message: "System installation failed",
 			}
 		}
+		result.Warning = warning
 		return SystemInstallationCompleteMsg{Result: result}
 	}
 }
 
-// startSystemUninstallation initiates the system uninstallation process
-func (m MenuModel) startSystemUninstallation() (tea.Model, tea.Cmd) {
+// purgeChoice uninstalls BOBA along with the BOBA directory
+const purgeChoice = "🧨 Uninstall and Remove All BOBA Data"
+
+// purgeConfirmationChoices asks to confirm removing the BOBA directory
+func (m MenuModel) purgeConfirmationChoices() []string {
+	dir := "~/.boba"
+	if m.configManager != nil {
+		dir = m.configManager.GetConfigDir()
+	}
+	return []string{
+		fmt.Sprintf("✅ Yes, uninstall and delete %s, including the saved token (no backup)", dir),
+		"❌ Cancel",
+	}
+}
+
+// startSystemUninstallation initiates the system uninstallation process; removeState also
+// removes the BOBA directory
+func (m MenuModel) startSystemUninstallation(removeState bool) (tea.Model, tea.Cmd) {
 	if m.systemInstaller == nil {
 		return m, func() tea.Msg {
 			return SystemInstallationCompleteMsg{
@@ -528,8 +692,19 @@ func (m MenuModel) startSystemUninstallation() (tea.Model, tea.Cmd) {
 	m.systemInstallResult = nil // Clear any previous result
 	m.choices = m.getMenuChoices()
 	
+	options := installer.UninstallOptions{RemoveState: removeState}
+	m.purging = removeState
+	if m.configManager != nil {
+		options.StateDir = m.configManager.GetConfigDir()
+		// Persist batched changes now: the BOBA directory may be about to go
+		m.configManager.Flush()
+		if removeState {
+			// Nothing may write the directory again once it is removed
+			m.configManager.DisablePersistence()
+		}
+	}
 	return m, func() tea.Msg {
-		result, err := m.systemInstaller.UninstallFromSystem()
+		result, err := m.systemInstaller.UninstallFromSystem(options)
 		if err != nil && result == nil {
 			result = &installer.SystemInstallationResult{
 				Success: false,
@@ -551,4 +726,68 @@ func (m MenuModel) showSystemInstallationDetails() (tea.Model, tea.Cmd) {
 	// In a more advanced implementation, this could show a detailed view
 	m.choices = m.getMenuChoices()
 	return m, nil
-}
\ No newline at end of file
+}
+
+// syncRepository fast-forwards the local clone of the configuration repository
+func (m MenuModel) syncRepository(strategy github.SyncStrategy) (tea.Model, tea.Cmd) {
+	backend := m.repositoryBackend()
+	if backend == nil {
+		return m.startAuthentication()
+	}
+	
+	cloneDir, err := backend.GetCloneTargetDir()
+	if err != nil {
+		m.loadingMessage = fmt.Sprintf("Error: %v", err)
+		return m, nil
+	}
+	
+	m.isLoading = true
+	m.loadingMessage = "Syncing repository..."
+	
+	return m, func() tea.Msg {
+		if _, err := os.Stat(cloneDir); err != nil {
+			return RepoSyncMsg{Err: fmt.Errorf("no local clone at %s - authenticate again to clone the repository", cloneDir)}
+		}
+		result, err := github.SyncRepository(cloneDir, strategy)
+		return RepoSyncMsg{Result: result, Err: err}
+	}
+}
+
+// showToolFiles shows the files a tool put on the system, as recorded in its install provenance
+func (m MenuModel) showToolFiles(tool parser.Tool) (tea.Model, tea.Cmd) {
+	result := InstallationResult{ToolName: tool.Name, Success: true}
+	
+	installed, exists := m.configManager.GetInstalledTool(tool.Name)
+	switch {
+	case !exists:
+		result.Message = "Not installed by BOBA"
+	case installed.Provenance == nil:
+		result.Message = "Installed before provenance tracking, no files recorded"
+	default:
+		var lines []string
+		if usage := installed.Provenance.DiskUsage(); usage.Files > 0 || usage.Missing > 0 {
+			line := fmt.Sprintf("💾 %s on disk in %d file(s)", config.FormatBytes(usage.Bytes), usage.Files)
+			if usage.Missing > 0 {
+				line += fmt.Sprintf(", %d recorded file(s) removed since", usage.Missing)
+			}
+			lines = append(lines, line)
+		}
+		for _, path := range installed.Provenance.BinaryPaths {
+			lines = append(lines, "⚙️ "+path)
+		}
+		for _, path := range installed.Provenance.CreatedFiles {
+			lines = append(lines, "📄 "+path)
+		}
+		if len(tool.TrackDirs) == 0 {
+			lines = append(lines, "Add track_dirs to tool.yaml to record the files this tool creates")
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No files recorded")
+		}
+		result.Message = strings.Join(lines, "\n")
+	}
+	
+	m.installationResults = []InstallationResult{result}
+	m.showingResults = true
+	return m, nil
+}
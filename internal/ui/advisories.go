@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/advisory"
+)
+
+// advisoriesChoicePrefix starts the main menu badge listing the security advisories
+const advisoriesChoicePrefix = "🛡️ Security Advisories"
+
+// AdvisoryCheckMsg carries the security advisories affecting the installed tools, by tool name
+type AdvisoryCheckMsg struct {
+	Advisories map[string][]advisory.Advisory
+	Err        error
+}
+
+// checkAdvisories looks up the installed tools with a known version and ecosystem in OSV
+func (m MenuModel) checkAdvisories() tea.Cmd {
+	if m.configManager == nil {
+		return nil
+	}
+	queries := advisory.QueriesFor(m.configManager.GetAllInstalledTools(), m.availableTools)
+	if len(queries) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		advisories, err := advisory.NewClient().Check(ctx, queries)
+		return AdvisoryCheckMsg{Advisories: advisories, Err: err}
+	}
+}
+
+// handleAdvisoryCheckMsg stores the advisories for the main menu badge and the advisories screen
+func (m MenuModel) handleAdvisoryCheckMsg(msg AdvisoryCheckMsg) (tea.Model, tea.Cmd) {
+	m.advisories = &msg
+	if m.currentMenu == AdvisoriesMenu {
+		m.isLoading = false
+		m.loadingMessage = ""
+	}
+	m.choices = m.getMenuChoices()
+	if m.cursor >= len(m.choices) {
+		m.cursor = len(m.choices) - 1
+	}
+	return m, nil
+}
+
+// affectedTools returns the tools with advisories, sorted by name
+func (m MenuModel) affectedTools() []string {
+	if m.advisories == nil {
+		return nil
+	}
+	var tools []string
+	for name, advisories := range m.advisories.Advisories {
+		if len(advisories) > 0 {
+			tools = append(tools, name)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// advisoryCount returns the number of advisories affecting a tool
+func (m MenuModel) advisoryCount(toolName string) int {
+	if m.advisories == nil {
+		return 0
+	}
+	return len(m.advisories.Advisories[toolName])
+}
+
+// advisoriesChoice is the main menu badge, or "" when no installed tool is affected
+func (m MenuModel) advisoriesChoice() string {
+	total := 0
+	for _, name := range m.affectedTools() {
+		total += m.advisoryCount(name)
+	}
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d)", advisoriesChoicePrefix, total)
+}
+
+// installedVersion returns the recorded version of an installed tool
+func (m MenuModel) installedVersion(toolName string) string {
+	if m.configManager == nil {
+		return ""
+	}
+	record, _ := m.configManager.GetInstalledTool(toolName)
+	return record.Version
+}
+
+func (m MenuModel) getAdvisoriesChoices() []string {
+	var choices []string
+	for _, name := range m.affectedTools() {
+		choices = append(choices, fmt.Sprintf("⬆️ Update %s %s - %d advisories", name, m.installedVersion(name), m.advisoryCount(name)))
+	}
+	choices = append(choices, "🔄 Check Again", "← Back")
+	return choices
+}
+
+// getAdvisoriesTitle lists the advisories of each affected tool with the versions fixing them
+func (m MenuModel) getAdvisoriesTitle() string {
+	var s strings.Builder
+	s.WriteString("🛡️ Security Advisories (OSV)")
+	
+	switch {
+	case m.advisories == nil:
+		s.WriteString("\n   Not checked yet")
+		return s.String()
+	case m.advisories.Err != nil:
+		s.WriteString(fmt.Sprintf("\n❌ Could not check advisories: %v", m.advisories.Err))
+		return s.String()
+	case len(m.affectedTools()) == 0:
+		s.WriteString("\n✅ No known advisories for the installed tool versions")
+		return s.String()
+	}
+	
+	for _, name := range m.affectedTools() {
+		s.WriteString(fmt.Sprintf("\n\n%s %s", name, m.installedVersion(name)))
+		for _, adv := range m.advisories.Advisories[name] {
+			id := adv.ID
+			if len(adv.Aliases) > 0 {
+				id += " (" + strings.Join(adv.Aliases, ", ") + ")"
+			}
+			s.WriteString(fmt.Sprintf("\n   ⚠️ %s: %s", id, adv.Summary))
+			if len(adv.Fixed) > 0 {
+				s.WriteString(fmt.Sprintf("\n      Fixed in %s", strings.Join(adv.Fixed, ", ")))
+			}
+		}
+	}
+	s.WriteString("\n\nSelect a tool to update it by running its install script again.")
+	return s.String()
+}
+
+// handleAdvisoriesSelection updates the selected affected tool or checks the advisories again
+func (m MenuModel) handleAdvisoriesSelection() (tea.Model, tea.Cmd) {
+	currentChoices := m.getMenuChoices()
+	if m.cursor == len(currentChoices)-1 {
+		m.navigateBack()
+		return m, nil
+	}
+	
+	tools := m.affectedTools()
+	if m.cursor == len(tools) {
+		m.isLoading = true
+		m.loadingMessage = "Checking security advisories..."
+		return m, m.checkAdvisories()
+	}
+	if m.cursor < len(tools) {
+		for _, tool := range m.availableTools {
+			if tool.Name == tools[m.cursor] {
+				return m.installSingleTool(tool)
+			}
+		}
+		m.loadingMessage = fmt.Sprintf("Error: %s is no longer in the configuration repository", tools[m.cursor])
+	}
+	return m, nil
+}
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// cleanupChoice opens the cleanup suggestions from Installation Configuration
+const cleanupChoice = "🧹 Cleanup Suggestions"
+
+// noUninstallLabel marks the tools that are marked uninstallable: false or have no uninstall script
+const noUninstallLabel = " 🚫 can't be uninstalled"
+
+// CleanupUninstallMsg reports the uninstall of a tool suggested for cleanup
+type CleanupUninstallMsg struct {
+	Tool   string
+	Result *installer.InstallationResult
+	Err    error
+}
+
+// unusedTools returns the tools not used for config.UnusedAfter
+func (m MenuModel) unusedTools() []config.UnusedTool {
+	if m.configManager == nil {
+		return nil
+	}
+	return m.configManager.UnusedTools(time.Now())
+}
+
+// unusedToolLine describes a tool suggested for cleanup
+func unusedToolLine(tool config.UnusedTool) string {
+	days := int(tool.Idle.Hours() / 24)
+	if tool.LastUsed.IsZero() {
+		return fmt.Sprintf("🗑️ %s · not used in the %d days tracked", tool.Name, days)
+	}
+	return fmt.Sprintf("🗑️ %s · last used %d days ago", tool.Name, days)
+}
+
+// getCleanupTitle explains usage tracking, or asks to confirm uninstalling the selected tool
+func (m MenuModel) getCleanupTitle() string {
+	title := "🧹 Cleanup Suggestions"
+	if m.configManager == nil {
+		return title
+	}
+	switch {
+	case m.pendingCleanup != "":
+		title = fmt.Sprintf("🗑️ Uninstall %s?\n   Its uninstall script runs and its installation record is removed.", m.pendingCleanup)
+	case !m.configManager.UsageTrackingEnabled():
+		title += "\n   Usage tracking is off. Turned on, it wraps the executables recorded for each tool in shims in " +
+			m.configManager.GetShimsDir() + ", put first on PATH by your shell rc files, which note when each tool last ran." +
+			fmt.Sprintf("\n   Tools not used in %d days are then suggested for uninstall here. Nothing leaves this machine.", int(config.UnusedAfter.Hours()/24))
+	default:
+		title += fmt.Sprintf("\n   Usage tracked since %s.", historyDate(m.configManager.GetConfig().UsageTrackingSince))
+		if len(m.unusedTools()) == 0 {
+			title += fmt.Sprintf("\n   Every tracked tool was used in the last %d days.", int(config.UnusedAfter.Hours()/24))
+		} else {
+			title += fmt.Sprintf("\n   Not used in %d days, select one to uninstall it:", int(config.UnusedAfter.Hours()/24))
+		}
+	}
+	if m.cleanupStatus != "" {
+		title += "\n\n" + m.cleanupStatus
+	}
+	return title
+}
+
+// getCleanupChoices lists the unused tools, or confirms uninstalling the selected one
+func (m MenuModel) getCleanupChoices() []string {
+	if m.pendingCleanup != "" {
+		return []string{"✅ Yes, uninstall " + m.pendingCleanup, "❌ Cancel"}
+	}
+	if m.configManager == nil || !m.configManager.UsageTrackingEnabled() {
+		return []string{"▶️ Turn on usage tracking", "← Back"}
+	}
+	var choices []string
+	for _, tool := range m.unusedTools() {
+		line := unusedToolLine(tool)
+		if !m.toolHasUninstall(tool.Name) {
+			line += noUninstallLabel
+		}
+		choices = append(choices, line)
+	}
+	return append(choices, "⏸️ Turn off usage tracking", "← Back")
+}
+
+// handleCleanupSelection turns usage tracking on or off, or uninstalls a suggested tool once confirmed
+func (m MenuModel) handleCleanupSelection() (tea.Model, tea.Cmd) {
+	if name := m.pendingCleanup; name != "" {
+		m.pendingCleanup = ""
+		if m.cursor != 0 {
+			m.choices = m.getMenuChoices()
+			m.cursor = 0
+			return m, nil
+		}
+		m.isLoading = true
+		m.loadingMessage = fmt.Sprintf("Uninstalling %s...", name)
+		return m, m.uninstallUnusedTool(name)
+	}
+
+	choices := m.getMenuChoices()
+	unused := m.unusedTools()
+	switch {
+	case m.cursor == len(choices)-1:
+		m.cleanupStatus = ""
+		m.navigateBack()
+		return m, nil
+	case m.cursor == len(choices)-2 || !m.configManager.UsageTrackingEnabled():
+		m.cleanupStatus = m.toggleUsageTracking()
+	case m.cursor < len(unused) && !m.toolHasUninstall(unused[m.cursor].Name):
+		m.cleanupStatus = errorStyle.Render(fmt.Sprintf("❌ %s can't be uninstalled: it is marked uninstallable: false or has no uninstall script.", unused[m.cursor].Name))
+	case m.cursor < len(unused):
+		m.pendingCleanup = unused[m.cursor].Name
+		m.cleanupStatus = ""
+		m.choices = m.getMenuChoices()
+		m.cursor = 1 // Default to Cancel
+		return m, nil
+	}
+	m.choices = m.getMenuChoices()
+	m.cursor = 0
+	return m, nil
+}
+
+// toolHasUninstall reports whether a tool of the loaded tools list can be uninstalled; a tool not
+// loaded yet is found out when its uninstall runs
+func (m MenuModel) toolHasUninstall(name string) bool {
+	for _, tool := range m.availableTools {
+		if tool.Name == name {
+			return tool.HasUninstall()
+		}
+	}
+	return true
+}
+
+// toggleUsageTracking turns usage tracking on or off, with the shims directory on PATH, and
+// returns the outcome
+func (m MenuModel) toggleUsageTracking() string {
+	enable := !m.configManager.UsageTrackingEnabled()
+	if err := m.configManager.SetUsageTracking(enable); err != nil {
+		return errorStyle.Render(fmt.Sprintf("❌ %v", err))
+	}
+	notes, err := installer.SetShimsPath(m.configManager.GetShimsDir(), enable)
+	if err != nil {
+		return errorStyle.Render(fmt.Sprintf("❌ %v", err))
+	}
+	lines := []string{"⏸️ Usage tracking turned off, the shims and recorded uses removed."}
+	if enable {
+		lines = []string{"▶️ Usage tracking turned on."}
+	}
+	for _, note := range notes {
+		lines = append(lines, "   "+note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// uninstallUnusedTool runs the uninstall script of a tool and forgets its installation record
+func (m MenuModel) uninstallUnusedTool(name string) tea.Cmd {
+	repoParser, engine, configManager := m.repoParser, m.installEngine, m.configManager
+	return func() tea.Msg {
+		if repoParser == nil || engine == nil {
+			return CleanupUninstallMsg{Tool: name, Err: fmt.Errorf("no repository is set up yet")}
+		}
+		tools, err := repoParser.GetTools()
+		if err != nil {
+			return CleanupUninstallMsg{Tool: name, Err: fmt.Errorf("failed to fetch tools: %w", err)}
+		}
+		var tool *parser.Tool
+		for i := range tools {
+			if tools[i].Name == name {
+				tool = &tools[i]
+			}
+		}
+		if tool == nil {
+			return CleanupUninstallMsg{Tool: name, Err: fmt.Errorf("%s is no longer in the repository: uninstall it by hand", name)}
+		}
+		result, err := engine.UninstallTool(*tool)
+		if err == nil && result.Success && !result.DryRun {
+			err = configManager.RemoveInstalledTool(name)
+		}
+		return CleanupUninstallMsg{Tool: name, Result: result, Err: err}
+	}
+}
+
+// handleCleanupUninstallMsg shows the outcome of the uninstall on the cleanup screen
+func (m MenuModel) handleCleanupUninstallMsg(msg CleanupUninstallMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	switch {
+	case msg.Err != nil:
+		m.cleanupStatus = errorStyle.Render(fmt.Sprintf("❌ Failed to uninstall %s: %v", msg.Tool, msg.Err))
+	case msg.Result != nil && msg.Result.DryRun:
+		m.cleanupStatus = fmt.Sprintf("🧪 Dry run: %s was not uninstalled.", msg.Tool)
+	default:
+		m.cleanupStatus = fmt.Sprintf("✅ %s uninstalled.", msg.Tool)
+	}
+	if m.currentMenu == CleanupMenu {
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+	}
+	return m, nil
+}
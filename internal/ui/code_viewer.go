@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"boba/internal/installer"
+)
+
+// codeViewerHeight is the number of lines a code viewer shows at once
+const codeViewerHeight = 20
+
+var (
+	lineNumberStyle      = lipgloss.NewStyle().Foreground(mutedColor)
+	matchLineNumberStyle = lipgloss.NewStyle().Foreground(warningColor).Bold(true)
+)
+
+// codeViewerLine is one line of a code viewer: a file header, an error or a numbered file line
+type codeViewerLine struct {
+	raw         string // Text searched
+	highlighted string // Text shown
+	number      int    // Line number in the file, 0 for headers and errors
+}
+
+// codeViewer is a read-only, scrollable view of files with line numbers, syntax highlighting and search
+type codeViewer struct {
+	lines       []codeViewerLine
+	numberWidth int
+	offset      int
+	searching   bool   // The search query is being typed
+	query       string // Last search query
+	matches     []int  // Lines matching the query
+	match       int    // Current match in matches
+}
+
+// newCodeViewer renders files fetched from the repository, a file that could not be fetched
+// shows its error
+func newCodeViewer(files []installer.RepositoryFile) codeViewer {
+	var v codeViewer
+	for i, file := range files {
+		if i > 0 {
+			v.lines = append(v.lines, codeViewerLine{})
+		}
+		header := "── " + file.Path + " ──"
+		v.lines = append(v.lines, codeViewerLine{raw: header, highlighted: titleStyle.Render(header)})
+		if file.Err != nil {
+			message := fmt.Sprintf("❌ Could not fetch this file: %v", file.Err)
+			v.lines = append(v.lines, codeViewerLine{raw: message, highlighted: errorStyle.Render(message)})
+			continue
+		}
+		
+		syn := syntaxFor(file.Path)
+		content := strings.TrimRight(strings.ReplaceAll(string(file.Content), "\r\n", "\n"), "\n")
+		for number, line := range strings.Split(content, "\n") {
+			line = strings.ReplaceAll(line, "\t", "    ")
+			v.lines = append(v.lines, codeViewerLine{raw: line, highlighted: highlightLine(line, syn), number: number + 1})
+			v.numberWidth = max(v.numberWidth, len(fmt.Sprint(number+1)))
+		}
+	}
+	return v
+}
+
+// scroll moves the visible window by delta lines
+func (v codeViewer) scroll(delta int) codeViewer {
+	maxOffset := max(len(v.lines)-codeViewerHeight, 0)
+	v.offset = min(max(v.offset+delta, 0), maxOffset)
+	return v
+}
+
+// search finds the lines containing the query, case-insensitively, and shows the first one
+func (v codeViewer) search(query string) codeViewer {
+	v.query = query
+	v.matches = nil
+	v.match = 0
+	if query == "" {
+		return v
+	}
+	
+	query = strings.ToLower(query)
+	for i, line := range v.lines {
+		if strings.Contains(strings.ToLower(line.raw), query) {
+			v.matches = append(v.matches, i)
+		}
+	}
+	return v.showMatch()
+}
+
+// nextMatch shows the next (delta 1) or previous (delta -1) search match, wrapping around
+func (v codeViewer) nextMatch(delta int) codeViewer {
+	if len(v.matches) == 0 {
+		return v
+	}
+	v.match = (v.match + delta + len(v.matches)) % len(v.matches)
+	return v.showMatch()
+}
+
+// showMatch scrolls the current match into view, a few lines from the top
+func (v codeViewer) showMatch() codeViewer {
+	if len(v.matches) == 0 {
+		return v
+	}
+	line := v.matches[v.match]
+	if line < v.offset || line >= v.offset+codeViewerHeight {
+		v.offset = 0
+		v = v.scroll(line - 3)
+	}
+	return v
+}
+
+// isMatch reports whether a line matches the search query
+func (v codeViewer) isMatch(line int) bool {
+	for _, match := range v.matches {
+		if match == line {
+			return true
+		}
+	}
+	return false
+}
+
+// update handles the viewer keys: "/" to search, n/N for the next and previous match and
+// PgUp/PgDn to scroll. handled is false for keys left to the menu.
+func (v codeViewer) update(msg tea.KeyMsg) (viewer codeViewer, handled bool) {
+	if v.searching {
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			return v, false
+		case tea.KeyEnter:
+			v.searching = false
+			return v.search(v.query), true
+		case tea.KeyEsc:
+			v.searching = false
+			return v.search(""), true
+		case tea.KeyBackspace:
+			if query := []rune(v.query); len(query) > 0 {
+				v.query = string(query[:len(query)-1])
+			}
+		case tea.KeyRunes:
+			v.query += string(msg.Runes)
+		case tea.KeySpace:
+			v.query += " "
+		}
+		return v, true
+	}
+	
+	switch msg.String() {
+	case "/":
+		v.searching = true
+		v.query = ""
+	case "n":
+		return v.nextMatch(1), true
+	case "N":
+		return v.nextMatch(-1), true
+	case "pgdown", "ctrl+d":
+		return v.scroll(codeViewerHeight / 2), true
+	case "pgup", "ctrl+u":
+		return v.scroll(-codeViewerHeight / 2), true
+	default:
+		return v, false
+	}
+	return v, true
+}
+
+// View renders the visible lines with their line numbers, and the search status
+func (v codeViewer) View() string {
+	var s strings.Builder
+	end := min(v.offset+codeViewerHeight, len(v.lines))
+	for i := v.offset; i < end; i++ {
+		line := v.lines[i]
+		gutter := strings.Repeat(" ", v.numberWidth)
+		if line.number > 0 {
+			gutter = fmt.Sprintf("%*d", v.numberWidth, line.number)
+		}
+		if v.isMatch(i) {
+			s.WriteString(matchLineNumberStyle.Render("▶" + gutter))
+		} else {
+			s.WriteString(lineNumberStyle.Render(" " + gutter))
+		}
+		s.WriteString(" │ " + line.highlighted + "\n")
+	}
+	
+	var status []string
+	if len(v.lines) > codeViewerHeight {
+		status = append(status, fmt.Sprintf("Lines %d-%d of %d", v.offset+1, end, len(v.lines)))
+	}
+	switch {
+	case v.searching:
+		status = append(status, "Search: "+v.query+"▌")
+	case v.query != "" && len(v.matches) == 0:
+		status = append(status, fmt.Sprintf("No match for %q", v.query))
+	case v.query != "":
+		status = append(status, fmt.Sprintf("Match %d of %d for %q", v.match+1, len(v.matches), v.query))
+	}
+	if len(status) > 0 {
+		s.WriteString("\n   " + strings.Join(status, " • "))
+	}
+	return s.String()
+}
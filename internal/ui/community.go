@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/github"
+)
+
+// CommunityIndexMsg carries the community tools index
+type CommunityIndexMsg struct {
+	Index *github.CommunityIndex
+	Err   error
+}
+
+// CommunityImportMsg reports the outcome of importing a community tool
+type CommunityImportMsg struct {
+	ToolName string
+	Path     string // tool.yaml written to the configuration repository
+	Source   string // Pinned source of the imported scripts
+	Err      error
+}
+
+// repositoryWriter adds files to the configuration repository: the working copy in local mode,
+// a commit through the GitHub API otherwise
+type repositoryWriter interface {
+	CreateFile(path string, content []byte, message string) error
+}
+
+// communityIndexReference returns the configured community index, or the default one
+func (m MenuModel) communityIndexReference() string {
+	if m.configManager != nil && m.configManager.GetCommunityIndex() != "" {
+		return m.configManager.GetCommunityIndex()
+	}
+	return github.DefaultCommunityIndex
+}
+
+// browseCommunityTools opens the community tools screen and fetches the index
+func (m MenuModel) browseCommunityTools() (tea.Model, tea.Cmd) {
+	m.navigateToMenu(CommunityMenu)
+	m.isLoading = true
+	m.loadingMessage = "Fetching community tools..."
+	reference := m.communityIndexReference()
+	return m, func() tea.Msg {
+		index, err := github.FetchCommunityIndex(reference)
+		return CommunityIndexMsg{Index: index, Err: err}
+	}
+}
+
+// handleCommunityIndexMsg stores the index for the community tools screen
+func (m MenuModel) handleCommunityIndexMsg(msg CommunityIndexMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.communityIndex = &msg
+	m.choices = m.getMenuChoices()
+	m.cursor = 0
+	return m, nil
+}
+
+// repositoryWriter returns where imported tools are written, or nil when no repository is configured
+func (m MenuModel) repositoryWriter() repositoryWriter {
+	if m.localRepo != nil {
+		return m.localRepo
+	}
+	if m.githubClient != nil {
+		return m.githubClient
+	}
+	return nil
+}
+
+// hasTool reports whether the configuration repository already defines a tool
+func (m MenuModel) hasTool(name string) bool {
+	for _, tool := range m.availableTools {
+		if tool.Name == name || tool.FolderName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// communityTools returns the tools of the fetched index
+func (m MenuModel) communityTools() []github.CommunityTool {
+	if m.communityIndex == nil || m.communityIndex.Index == nil {
+		return nil
+	}
+	return m.communityIndex.Index.Tools
+}
+
+func (m MenuModel) getCommunityChoices() []string {
+	var choices []string
+	for _, tool := range m.communityTools() {
+		if m.hasTool(tool.Name) {
+			choices = append(choices, fmt.Sprintf("✅ %s - already in your repository", tool.Name))
+		} else {
+			choices = append(choices, fmt.Sprintf("📥 %s - %s", tool.Name, tool.Description))
+		}
+	}
+	choices = append(choices, "🔄 Refresh", "← Back")
+	return choices
+}
+
+// getCommunityTitle shows the index the tools come from
+func (m MenuModel) getCommunityTitle() string {
+	title := "🌐 Community Tools\n   Index: " + m.communityIndexReference()
+	switch {
+	case m.communityIndex == nil:
+		return title
+	case m.communityIndex.Err != nil:
+		return title + fmt.Sprintf("\n❌ Could not fetch the community index: %v", m.communityIndex.Err)
+	case len(m.communityTools()) == 0:
+		return title + "\n   The index lists no tools"
+	}
+	return title + fmt.Sprintf(" at %.12s\n   Select a tool to import it into your repository, with its scripts pinned to this commit.",
+		m.communityIndex.Index.Commit)
+}
+
+// handleCommunitySelection imports the selected tool or fetches the index again
+func (m MenuModel) handleCommunitySelection() (tea.Model, tea.Cmd) {
+	currentChoices := m.getMenuChoices()
+	if m.cursor == len(currentChoices)-1 {
+		m.navigateBack()
+		return m, nil
+	}
+	
+	tools := m.communityTools()
+	if m.cursor == len(tools) {
+		m.navigateBack()
+		return m.browseCommunityTools()
+	}
+	if m.cursor < len(tools) && !m.hasTool(tools[m.cursor].Name) {
+		return m.importCommunityTool(tools[m.cursor])
+	}
+	return m, nil
+}
+
+// importCommunityTool adds tools/<name>/tool.yaml to the configuration repository, pointing to
+// the community scripts pinned to the index commit
+func (m MenuModel) importCommunityTool(tool github.CommunityTool) (tea.Model, tea.Cmd) {
+	writer := m.repositoryWriter()
+	if writer == nil {
+		m.loadingMessage = "Error: no configuration repository to import into"
+		return m, nil
+	}
+	for _, existing := range m.availableTools {
+		if existing.Catalog {
+			m.loadingMessage = fmt.Sprintf("Error: your repository uses a boba.yaml catalog; add an entry with source: %s", m.communityIndex.Index.Source(tool))
+			return m, nil
+		}
+	}
+	
+	index := m.communityIndex.Index
+	m.isLoading = true
+	m.loadingMessage = fmt.Sprintf("Importing %s...", tool.Name)
+	return m, func() tea.Msg {
+		manifest, err := index.ImportManifest(tool)
+		if err != nil {
+			return CommunityImportMsg{ToolName: tool.Name, Err: err}
+		}
+		return writeImportedTool(writer, tool, manifest, index.Source(tool))
+	}
+}
+
+// writeImportedTool writes the manifest of an imported community tool
+func writeImportedTool(writer repositoryWriter, tool github.CommunityTool, manifest []byte, source string) CommunityImportMsg {
+	manifestPath := path.Join("tools", tool.Name, "tool.yaml")
+	err := writer.CreateFile(manifestPath, manifest, fmt.Sprintf("Import %s from the BOBA community tools (%s)", tool.Name, source))
+	return CommunityImportMsg{ToolName: tool.Name, Path: manifestPath, Source: source, Err: err}
+}
+
+// handleCommunityImportMsg shows the outcome of an import and reloads the tools
+func (m MenuModel) handleCommunityImportMsg(msg CommunityImportMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	
+	result := InstallationResult{ToolName: msg.ToolName, Success: msg.Err == nil}
+	if msg.Err != nil {
+		result.Message = fmt.Sprintf("Import failed: %v", msg.Err)
+	} else {
+		result.Message = fmt.Sprintf("Added %s with its scripts pinned to %s", msg.Path, msg.Source)
+		if m.localRepo != nil {
+			result.Message += "\nReview and commit the new file in your repository"
+		}
+		if m.repoParser != nil {
+			m.repoParser.InvalidateCache()
+			m.availableTools = nil
+		}
+	}
+	m.installationResults = []InstallationResult{result}
+	m.showingResults = true
+	m.choices = m.getMenuChoices()
+	
+	if msg.Err == nil && m.repoParser != nil {
+		return m, func() tea.Msg {
+			tools, err := m.repoParser.GetTools()
+			if err != nil {
+				return fmt.Sprintf("error_fetching_tools: %s", describeError(err))
+			}
+			return ToolsListMsg{Tools: tools}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// configWatchInterval is how often config.json is checked for changes made by other processes
+const configWatchInterval = 2 * time.Second
+
+// configWatchMsg is delivered to Update on every config file check
+type configWatchMsg time.Time
+
+// ConfigChangedMsg is sent when the configuration changed, locally or in another process
+type ConfigChangedMsg struct {
+	Change config.ConfigChange
+}
+
+// watchConfig schedules the next config file check
+func watchConfig() tea.Cmd {
+	return tea.Tick(configWatchInterval, func(t time.Time) tea.Msg {
+		return configWatchMsg(t)
+	})
+}
+
+// subscribeToConfig forwards configuration changes to the program as ConfigChangedMsg
+func subscribeToConfig(p *tea.Program, configManager *config.ConfigManager) (unsubscribe func()) {
+	return configManager.Subscribe(func(change config.ConfigChange) {
+		// Changes are usually made inside Update, where a blocking Send would deadlock
+		go p.Send(ConfigChangedMsg{Change: change})
+	})
+}
+
+// handleConfigMsg reloads config.json when it changed on disk and refreshes the menu on changes
+func (m MenuModel) handleConfigMsg(msg tea.Msg) (MenuModel, tea.Cmd, bool) {
+	switch msg.(type) {
+	case configWatchMsg:
+		if m.configManager != nil {
+			m.configManager.ReloadIfChanged()
+		}
+		return m, watchConfig(), true
+	case ConfigChangedMsg:
+		// Scripts pick up edited environment settings from the next run
+		if m.installEngine != nil && m.configManager != nil {
+			m.installEngine.ApplySettings(m.configManager)
+		}
+		
+		// Menus derive their choices from the config (overrides, repository, trust)
+		m.choices = m.getMenuChoices()
+		if m.cursor >= len(m.choices) && len(m.choices) > 0 {
+			m.cursor = len(m.choices) - 1
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
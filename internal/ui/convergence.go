@@ -0,0 +1,38 @@
+package ui
+
+import "boba/internal/parser"
+
+// notConvergedMessage is appended to tools whose script succeeded but whose check still fails
+const notConvergedMessage = "⚠️ The script reported success, but satisfied_when still fails: nothing was installed"
+
+// recheckConvergence re-evaluates the satisfied_when checks of the tools of the run that reported
+// success, flagging the ones whose script exited with 0 without installing anything. Flagged tools
+// are reported as failed and removed from the installation records.
+func (m MenuModel) recheckConvergence(tools []parser.Tool, results []InstallationResult) []InstallationResult {
+	if m.dryRun() {
+		return results // Nothing ran
+	}
+	byName := make(map[string]parser.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	
+	rechecked := append([]InstallationResult{}, results...)
+	for i, result := range rechecked {
+		tool, ok := byName[result.ToolName]
+		if !ok || !result.Success {
+			continue
+		}
+		if checked, satisfied := m.installEngine.RecheckTool(tool); !checked || satisfied {
+			continue
+		}
+		
+		rechecked[i].Success = false
+		rechecked[i].Message += "\n" + notConvergedMessage
+		m.recordScriptResult(tool.Name, false)
+		if m.configManager != nil {
+			m.configManager.RemoveInstalledTool(tool.Name)
+		}
+	}
+	return rechecked
+}
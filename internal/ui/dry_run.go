@@ -0,0 +1,32 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// dryRunBadge is shown above the main menu while nothing is executed
+const dryRunBadge = "🧪 Dry run: scripts are downloaded and described, nothing is executed"
+
+// dryRun reports whether the engine describes scripts instead of running them. Nothing is
+// recorded then: no installations, applied environments, failures or run plans.
+func (m MenuModel) dryRun() bool {
+	return m.installEngine != nil && m.installEngine.DryRun()
+}
+
+// dryRunChoice is the Installation Configuration choice toggling dry-run mode
+func (m MenuModel) dryRunChoice() string {
+	if m.dryRun() {
+		return "🧪 Dry Run: On"
+	}
+	return "🧪 Dry Run: Off"
+}
+
+// toggleDryRun turns dry-run mode on or off for this session
+func (m MenuModel) toggleDryRun() (tea.Model, tea.Cmd) {
+	if m.installEngine == nil {
+		return m, nil
+	}
+	options := m.installEngine.Options()
+	options.DryRun = !options.DryRun
+	m.installEngine.SetOptions(options)
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
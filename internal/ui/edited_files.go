@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// maxDiffLines is how many changed lines of a file the edited files screen shows per side
+const maxDiffLines = 8
+
+// EditedFilesMsg is sent instead of starting a run that would overwrite home files the user
+// edited since they were last applied, so the user keeps, replaces or merges each file first
+type EditedFilesMsg struct {
+	Files      []installer.EditedFile
+	Everything bool    // The run is Install Everything, not the application of one environment
+	Resume     tea.Cmd // Starts the run again once every file is settled
+}
+
+// unresolvedEditedFiles returns the home files the environments of a run would overwrite although
+// the user edited them since they were last applied
+func (m MenuModel) unresolvedEditedFiles(run []parser.Environment) []installer.EditedFile {
+	if m.installEngine == nil || len(run) == 0 {
+		return nil
+	}
+	var editedErr *installer.EditedFilesError
+	if errors.As(m.installEngine.CheckEditedFiles(run), &editedErr) {
+		return editedErr.Files
+	}
+	return nil
+}
+
+// handleEditedFilesMsg asks how to settle each edited file before the run starts
+func (m MenuModel) handleEditedFilesMsg(msg EditedFilesMsg) (tea.Model, tea.Cmd) {
+	m.installationInProgress = false
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.editedFiles = &msg
+	m.navigateToMenu(EditedFilesMenu)
+	return m, nil
+}
+
+// getEditedFilesTitle compares the first edited file left with the version it was last applied
+// at: the user's edits on one side, the repository's changes on the other
+func (m MenuModel) getEditedFilesTitle() string {
+	if m.editedFiles == nil || len(m.editedFiles.Files) == 0 {
+		return "✏️ Edited Files"
+	}
+	file := m.editedFiles.Files[0]
+	lines := []string{
+		fmt.Sprintf("✏️ ~/%s was edited since %s applied it", file.File, file.AppliedBy),
+		fmt.Sprintf("   Applying %s would overwrite your edits.", file.Environment),
+		"",
+		"   Your edits:",
+	}
+	lines = append(lines, diffView(installer.DiffLines(file.Base, file.Mine))...)
+	lines = append(lines, "", "   Repository changes:")
+	if file.Repo == nil {
+		lines = append(lines, "   (the repository's version could not be fetched)")
+	} else {
+		lines = append(lines, diffView(installer.DiffLines(file.Base, file.Repo))...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffView renders changed lines, removed in red and added in green
+func diffView(diff []string) []string {
+	if len(diff) == 0 {
+		return []string{"   (no changes)"}
+	}
+	var lines []string
+	for i, line := range diff {
+		if i == maxDiffLines {
+			lines = append(lines, fmt.Sprintf("   … %d more line(s)", len(diff)-maxDiffLines))
+			break
+		}
+		if strings.HasPrefix(line, "-") {
+			lines = append(lines, "   "+errorStyle.Render(line))
+		} else {
+			lines = append(lines, "   "+successStyle.Render(line))
+		}
+	}
+	return lines
+}
+
+// getEditedFilesChoices offers keeping the edits, taking the repository's version, merging both,
+// or cancelling the run
+func (m MenuModel) getEditedFilesChoices() []string {
+	if m.editedFiles == nil || len(m.editedFiles.Files) == 0 {
+		return []string{"← Back"}
+	}
+	return []string{
+		"👤 Keep mine",
+		"📦 Take the repository's version",
+		"🔀 Merge both (conflicts are marked in the file)",
+		"❌ Cancel",
+	}
+}
+
+// handleEditedFilesSelection settles a file, then asks about the next one or starts the run once
+// none is left
+func (m MenuModel) handleEditedFilesSelection() (tea.Model, tea.Cmd) {
+	pending := m.editedFiles
+	resolutions := []string{installer.EditKeepMine, installer.EditTakeRepo, installer.EditMerge}
+	if pending == nil || len(pending.Files) == 0 || m.cursor >= len(resolutions) {
+		m.editedFiles = nil
+		m.navigateBack()
+		return m, nil
+	}
+	m.installEngine.SetEditResolution(pending.Files[0].File, resolutions[m.cursor])
+
+	remaining := *pending
+	remaining.Files = pending.Files[1:]
+	if len(remaining.Files) > 0 {
+		m.editedFiles = &remaining
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+		return m, nil
+	}
+
+	m.editedFiles = nil
+	m.navigateBack()
+	if pending.Everything {
+		m.installationInProgress = true
+		m.loadingMessage = "Installing tools..."
+	} else {
+		m.isLoading = true
+		m.loadingMessage = "Applying environment..."
+	}
+	return m, pending.Resume
+}
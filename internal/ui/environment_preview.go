@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// EnvironmentPreviewMsg carries the setup script and config files of an environment
+type EnvironmentPreviewMsg struct {
+	Environment parser.Environment
+	Files       []installer.RepositoryFile
+	Err         error
+}
+
+// previewEnvironment opens the read-only preview of an environment and fetches its files
+func (m MenuModel) previewEnvironment(env parser.Environment) (tea.Model, tea.Cmd) {
+	m.environmentPreview = &EnvironmentPreviewMsg{Environment: env}
+	m.viewer = codeViewer{}
+	m.navigateToMenu(EnvironmentPreviewMenu)
+	if m.installEngine == nil {
+		m.environmentPreview.Err = fmt.Errorf("installation engine not initialized")
+		return m, nil
+	}
+	
+	m.isLoading = true
+	m.loadingMessage = fmt.Sprintf("Fetching the files of %s...", env.Name)
+	engine := m.installEngine
+	return m, func() tea.Msg {
+		files, err := engine.EnvironmentFiles(env)
+		return EnvironmentPreviewMsg{Environment: env, Files: files, Err: err}
+	}
+}
+
+// handleEnvironmentPreviewMsg shows the fetched files
+func (m MenuModel) handleEnvironmentPreviewMsg(msg EnvironmentPreviewMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.environmentPreview = &msg
+	m.viewer = newCodeViewer(msg.Files)
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
+// getEnvironmentPreviewTitle shows the visible part of the previewed files
+func (m MenuModel) getEnvironmentPreviewTitle() string {
+	if m.environmentPreview == nil {
+		return "🔍 Environment Preview"
+	}
+	env := m.environmentPreview.Environment
+	
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("🔍 Preview: %s\n", env.Name))
+	if env.Description != "" {
+		s.WriteString("   " + env.Description + "\n")
+	}
+	if m.environmentPreview.Err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("❌ Could not fetch the environment files: %v", m.environmentPreview.Err)))
+		return s.String()
+	}
+	
+	s.WriteString("\n")
+	s.WriteString(m.viewer.View())
+	return s.String()
+}
+
+func (m MenuModel) getEnvironmentPreviewChoices() []string {
+	if m.environmentPreview == nil {
+		return []string{"← Back to Environments"}
+	}
+	env := m.environmentPreview.Environment
+	choices := []string{fmt.Sprintf("✅ Apply %s", env.Name)}
+	if hasRestoreScript(env) {
+		choices = append(choices, fmt.Sprintf("↩️  Restore %s", env.Name))
+	}
+	return append(choices, "← Back to Environments")
+}
+
+// hasRestoreScript reports whether the environment can be reverted
+func hasRestoreScript(env parser.Environment) bool {
+	return env.RestoreScript != "" || env.RestoreInline != ""
+}
+
+// handleEnvironmentPreviewSelection applies or restores the previewed environment, or returns to the list
+func (m MenuModel) handleEnvironmentPreviewSelection() (tea.Model, tea.Cmd) {
+	choices := m.getMenuChoices()
+	if m.cursor >= len(choices)-1 || m.environmentPreview == nil {
+		m.navigateBack()
+		return m, nil
+	}
+	
+	env := m.environmentPreview.Environment
+	restore := m.cursor == 1
+	m.navigateBack()
+	if restore {
+		return m.restoreEnvironment(env)
+	}
+	return m.applyEnvironment(env)
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// FileConflictsMsg is sent instead of starting a run whose environments manage the same home
+// files, so the user chooses an owner or a merge for each file first
+type FileConflictsMsg struct {
+	Conflicts  []parser.FileConflict
+	Everything bool    // The run is Install Everything, not the application of one environment
+	Resume     tea.Cmd // Starts the run again once every conflict is resolved
+}
+
+// unresolvedFileConflicts returns the home files managed by several environments of a run, or
+// by one of them and an environment already applied, without an owner or merge chosen
+func (m MenuModel) unresolvedFileConflicts(run []parser.Environment) []parser.FileConflict {
+	if m.configManager == nil || m.repoParser == nil || len(run) == 0 {
+		return nil
+	}
+	environments, err := m.repoParser.GetEnvironments()
+	if err != nil {
+		return nil
+	}
+	var applied []parser.Environment
+	for _, env := range environments {
+		if _, ok := m.configManager.GetEnvironmentAppliedDate(env.Name); ok {
+			applied = append(applied, env)
+		}
+	}
+	
+	var conflictErr *installer.FileConflictError
+	if errors.As(m.installEngine.CheckFileConflicts(run, applied), &conflictErr) {
+		return conflictErr.Conflicts
+	}
+	return nil
+}
+
+// handleFileConflictsMsg asks for the owner of each conflicting file before the run starts
+func (m MenuModel) handleFileConflictsMsg(msg FileConflictsMsg) (tea.Model, tea.Cmd) {
+	m.installationInProgress = false
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.fileConflicts = &msg
+	m.navigateToMenu(FileConflictsMenu)
+	return m, nil
+}
+
+// getFileConflictsTitle describes the first conflict left to resolve
+func (m MenuModel) getFileConflictsTitle() string {
+	if m.fileConflicts == nil || len(m.fileConflicts.Conflicts) == 0 {
+		return "⚠️ File Conflicts"
+	}
+	conflict := m.fileConflicts.Conflicts[0]
+	return fmt.Sprintf("⚠️ ~/%s is managed by %s\n   Applied one after the other, only the last one's version would be kept.\n   Choose the environment owning it, whose version is kept, or merge the lines each environment adds.\n   The choice is saved in config.json (file_owners) and used by every later run.",
+		conflict.File, strings.Join(conflict.Environments, ", "))
+}
+
+// getFileConflictsChoices offers the owners of the first conflict left, a merge, or cancelling the run
+func (m MenuModel) getFileConflictsChoices() []string {
+	if m.fileConflicts == nil || len(m.fileConflicts.Conflicts) == 0 {
+		return []string{"← Back"}
+	}
+	conflict := m.fileConflicts.Conflicts[0]
+	var choices []string
+	for _, name := range conflict.Environments {
+		choices = append(choices, fmt.Sprintf("📄 %s owns ~/%s", name, conflict.File))
+	}
+	return append(choices, "🔀 Merge what each environment adds", "❌ Cancel")
+}
+
+// handleFileConflictsSelection saves the owner or merge of a file, then asks about the next
+// conflict or starts the run once none is left
+func (m MenuModel) handleFileConflictsSelection() (tea.Model, tea.Cmd) {
+	pending := m.fileConflicts
+	if pending == nil || len(pending.Conflicts) == 0 || m.cursor > len(pending.Conflicts[0].Environments) {
+		m.fileConflicts = nil
+		m.navigateBack()
+		return m, nil
+	}
+	
+	conflict := pending.Conflicts[0]
+	owner := config.FileMergeStrategy
+	if m.cursor < len(conflict.Environments) {
+		owner = conflict.Environments[m.cursor]
+	}
+	if err := m.configManager.SetFileOwner(conflict.File, owner); err != nil {
+		m.fileConflicts = nil
+		m.navigateBack()
+		m.loadingMessage = fmt.Sprintf("Error: %v", err)
+		return m, nil
+	}
+	m.installEngine.SetFileOwners(m.configManager.GetFileOwners())
+	
+	remaining := *pending
+	remaining.Conflicts = pending.Conflicts[1:]
+	if len(remaining.Conflicts) > 0 {
+		m.fileConflicts = &remaining
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+		return m, nil
+	}
+	
+	m.fileConflicts = nil
+	m.navigateBack()
+	if pending.Everything {
+		m.installationInProgress = true
+		m.loadingMessage = "Installing tools..."
+	} else {
+		m.isLoading = true
+		m.loadingMessage = "Applying environment..."
+	}
+	return m, pending.Resume
+}
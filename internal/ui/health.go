@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+)
+
+// Thresholds before a repeated failure turns into a startup suggestion
+const (
+	failedRunsThreshold     = 2
+	tokenFailuresThreshold  = 2
+	scriptFailuresThreshold = config.KnownFailingThreshold
+	maxScriptSuggestions    = 3
+)
+
+// healthSuggestions turns the local failure counters into suggested fixes
+func healthSuggestions(health config.HealthStats) []string {
+	var suggestions []string
+	
+	if health.ConsecutiveFailedRuns >= failedRunsThreshold {
+		suggestions = append(suggestions, fmt.Sprintf("💡 BOBA did not exit cleanly the last %d runs. Try 🔄 Sync Repository (Installation Configuration → Repository Configuration) to refresh the cached repository clone.", health.ConsecutiveFailedRuns))
+	}
+	if health.TokenFailures >= tokenFailuresThreshold {
+		suggestions = append(suggestions, fmt.Sprintf("💡 Connecting to GitHub failed %d times in a row with the saved token. It may have expired or been revoked: re-authenticate with 🔐 GitHub Authentication.", health.TokenFailures))
+	}
+	
+	var failing []string
+	for tool, failures := range health.ScriptFailures {
+		if failures >= scriptFailuresThreshold {
+			failing = append(failing, tool)
+		}
+	}
+	sort.Strings(failing)
+	for i, tool := range failing {
+		if i == maxScriptSuggestions {
+			suggestions = append(suggestions, fmt.Sprintf("💡 ... and %d more tools keep failing to install.", len(failing)-maxScriptSuggestions))
+			break
+		}
+		suggestions = append(suggestions, fmt.Sprintf("💡 %s failed to install %d times in a row. Check its install script, or skip it in Install Everything.", tool, health.ScriptFailures[tool]))
+	}
+	return suggestions
+}
+
+// beginHealthTracking starts counting this session and shows fixes for repeated failures under the main menu
+func beginHealthTracking(model MenuModel) MenuModel {
+	if model.configManager == nil {
+		return model
+	}
+	model.configManager.BeginRun()
+	
+	suggestions := healthSuggestions(model.configManager.GetHealth())
+	if len(suggestions) == 0 {
+		return model
+	}
+	if model.startupWarning != "" {
+		suggestions = append([]string{model.startupWarning}, suggestions...)
+	}
+	model.startupWarning = strings.Join(suggestions, "\n")
+	return model
+}
+
+// recordTokenResult counts failed GitHub connections with the saved token
+func (m MenuModel) recordTokenResult(ok bool) {
+	if m.configManager != nil {
+		m.configManager.RecordTokenResult(ok)
+	}
+}
+
+// recordToolRun counts consecutive failed installs of a tool and records the metrics of the install
+func (m MenuModel) recordToolRun(toolName string, ok bool, result *installer.InstallationResult) {
+	if m.configManager == nil {
+		return
+	}
+	if result == nil {
+		m.configManager.RecordScriptResult(toolName, ok)
+		return
+	}
+	m.configManager.RecordToolRun(toolName, ok, result.Duration, result.DownloadBytes)
+}
+
+// recordScriptResult counts consecutive failed installs of a tool
+func (m MenuModel) recordScriptResult(toolName string, ok bool) {
+	if m.configManager != nil {
+		m.configManager.RecordScriptResult(toolName, ok)
+	}
+}
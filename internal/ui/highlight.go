@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Syntax highlighting styles of the read-only file previews
+var (
+	commentStyle  = lipgloss.NewStyle().Foreground(mutedColor).Italic(true)
+	stringStyle   = lipgloss.NewStyle().Foreground(secondaryColor)
+	keywordStyle  = lipgloss.NewStyle().Foreground(primaryColor).Bold(true)
+	variableStyle = lipgloss.NewStyle().Foreground(warningColor)
+	keyStyle      = lipgloss.NewStyle().Foreground(accentColor)
+)
+
+// syntax describes the tokens highlighted in a file
+type syntax struct {
+	comment   string          // Line comment prefix, empty when the format has none
+	keywords  map[string]bool // Words highlighted as keywords
+	variables bool            // Highlight $NAME and ${NAME} references
+	keys      bool            // Highlight the key of "key: value" and "key = value" lines
+}
+
+var shellSyntax = syntax{
+	comment:   "#",
+	variables: true,
+	keywords: keywordSet("if then else elif fi for while until do done case esac in function return " +
+		"export local readonly source alias unset set exit shift break continue eval exec trap"),
+}
+
+var yamlSyntax = syntax{
+	comment:  "#",
+	keys:     true,
+	keywords: keywordSet("true false null yes no on off"),
+}
+
+var jsonSyntax = syntax{
+	keywords: keywordSet("true false null"),
+}
+
+var iniSyntax = syntax{
+	comment:  "#",
+	keys:     true,
+	keywords: keywordSet("true false"),
+}
+
+var plainSyntax = syntax{comment: "#"}
+
+// keywordSet splits a space separated keyword list
+func keywordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// syntaxFor picks the highlighting of a file from its name: scripts and shell dotfiles are
+// highlighted as shell
+func syntaxFor(filePath string) syntax {
+	name := path.Base(filePath)
+	switch path.Ext(name) {
+	case ".sh", ".bash", ".zsh", ".fish":
+		return shellSyntax
+	case ".yaml", ".yml":
+		return yamlSyntax
+	case ".json":
+		return jsonSyntax
+	case ".toml", ".ini", ".conf", ".cfg":
+		return iniSyntax
+	}
+	switch name {
+	case ".zshrc", ".bashrc", ".profile", ".bash_profile", ".zprofile", ".fishrc":
+		return shellSyntax
+	case ".gitconfig", ".editorconfig":
+		return iniSyntax
+	}
+	for _, script := range []string{"setup", "restore", "install", "uninstall"} {
+		if strings.HasPrefix(name, script) {
+			return shellSyntax
+		}
+	}
+	return plainSyntax
+}
+
+// isWordRune reports whether a rune can be part of a keyword or variable name
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// highlightLine renders one line of a file with its comments, strings, keywords, variables and keys styled
+func highlightLine(line string, syn syntax) string {
+	runes := []rune(line)
+	var s strings.Builder
+	
+	i := 0
+	if syn.keys {
+		// Key of "key: value" or "key = value", after the indentation
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+		s.WriteString(string(runes[:i]))
+		end := i
+		for end < len(runes) && runes[end] != ':' && runes[end] != '=' && runes[end] != '#' {
+			end++
+		}
+		key := strings.TrimSpace(string(runes[i:end]))
+		if end < len(runes) && runes[end] != '#' && key != "" && !strings.ContainsAny(key, "\"' ") {
+			s.WriteString(keyStyle.Render(string(runes[i:end])))
+			i = end
+		}
+	}
+	
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case syn.comment != "" && strings.HasPrefix(string(runes[i:]), syn.comment) && (i == 0 || unicode.IsSpace(runes[i-1])):
+			s.WriteString(commentStyle.Render(string(runes[i:])))
+			return s.String()
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if r == '"' && runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			s.WriteString(stringStyle.Render(string(runes[i:end])))
+			i = end
+		case syn.variables && r == '$' && i+1 < len(runes):
+			end := i + 1
+			if runes[end] == '{' {
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+				end = min(end+1, len(runes))
+			} else {
+				for end < len(runes) && isWordRune(runes[end]) {
+					end++
+				}
+			}
+			if end == i+1 {
+				s.WriteRune(r)
+				i++
+				continue
+			}
+			s.WriteString(variableStyle.Render(string(runes[i:end])))
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && (isWordRune(runes[end]) || runes[end] == '-') {
+				end++
+			}
+			word := string(runes[i:end])
+			if syn.keywords[word] {
+				s.WriteString(keywordStyle.Render(word))
+			} else {
+				s.WriteString(word)
+			}
+			i = end
+		default:
+			s.WriteRune(r)
+			i++
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+	
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// historyChoice opens the installation history from the main menu
+const historyChoice = "🕘 Installation History"
+
+// historyOrderLabels describes the orders of the installation history
+var historyOrderLabels = map[string]string{
+	config.HistoryByInstallDate: "install date",
+	config.HistoryByLastUpdate:  "last update",
+	config.HistoryByName:        "name",
+}
+
+// hasHistory reports whether any installation is recorded
+func (m MenuModel) hasHistory() bool {
+	if m.configManager == nil {
+		return false
+	}
+	return len(m.configManager.GetAllInstalledTools()) > 0
+}
+
+// currentHistoryOrder returns the order of the history screen, by install date by default
+func (m MenuModel) currentHistoryOrder() string {
+	if m.historyOrder == "" {
+		return config.HistoryByInstallDate
+	}
+	return m.historyOrder
+}
+
+// getHistoryChoices lists the installation records, then the sort toggle and Back
+func (m MenuModel) getHistoryChoices() []string {
+	var choices []string
+	if m.configManager != nil {
+		history, _ := m.configManager.InstallHistory(m.currentHistoryOrder())
+		for _, record := range history {
+			choices = append(choices, historyLine(record))
+		}
+	}
+	return append(choices, fmt.Sprintf("↕️ Sort by %s", historyOrderLabels[m.nextHistoryOrder()]), "← Back")
+}
+
+// historyLine describes one installation record
+func historyLine(record config.InstalledTool) string {
+	line := record.Name
+	if record.Version != "" {
+		line += " " + record.Version
+	}
+	if record.InstallMethod != "" {
+		line += fmt.Sprintf(" (%s)", record.InstallMethod)
+	}
+	line += " · installed " + historyDate(record.InstallDate)
+	if !record.LastUpdateDate.IsZero() && record.LastUpdateDate.After(record.InstallDate) {
+		line += " · updated " + historyDate(record.LastUpdateDate)
+	}
+	return line
+}
+
+// historyDate formats a record date in local time
+func historyDate(date time.Time) string {
+	if date.IsZero() {
+		return "unknown"
+	}
+	return date.Local().Format("2006-01-02 15:04")
+}
+
+// nextHistoryOrder returns the order the sort toggle switches to
+func (m MenuModel) nextHistoryOrder() string {
+	current := m.currentHistoryOrder()
+	for i, order := range config.HistoryOrders {
+		if order == current {
+			return config.HistoryOrders[(i+1)%len(config.HistoryOrders)]
+		}
+	}
+	return config.HistoryByInstallDate
+}
+
+// getHistoryTitle counts the installation records and names the current order
+func (m MenuModel) getHistoryTitle() string {
+	count := 0
+	if m.configManager != nil {
+		count = len(m.configManager.GetAllInstalledTools())
+	}
+	return fmt.Sprintf("🕘 Installation History\n   %d tool(s) installed by BOBA, by %s", count, historyOrderLabels[m.currentHistoryOrder()])
+}
+
+// handleHistorySelection switches the order or goes back; the records are only listed
+func (m MenuModel) handleHistorySelection() (tea.Model, tea.Cmd) {
+	choices := m.getMenuChoices()
+	switch m.cursor {
+	case len(choices) - 1:
+		m.navigateBack()
+	case len(choices) - 2:
+		m.historyOrder = m.nextHistoryOrder()
+		m.choices = m.getMenuChoices()
+	}
+	return m, nil
+}
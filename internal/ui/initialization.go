@@ -1,12 +1,15 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	
 	"boba/internal/config"
 	"boba/internal/github"
 	"boba/internal/installer"
+	"boba/internal/log"
 	"boba/internal/parser"
 )
 
@@ -22,7 +25,7 @@ func InitialModel() MenuModel {
 	systemInstaller, err := installer.NewSystemInstaller()
 	if err != nil {
 		// Log error but don't fail initialization
-		fmt.Printf("Warning: Failed to initialize system installer: %v\n", err)
+		log.Warn("Failed to initialize system installer", "error", err)
 	}
 
 	model := MenuModel{
@@ -47,10 +50,14 @@ func InitialModel() MenuModel {
 		installEverythingMode: false,
 		pendingEnvironments: []parser.Environment{},
 		authError: "",
+		watchdog: NewWatchdog(),
+		repoWatcher: &RepoWatcher{},
 	}
 	
 	// Perform initial setup validation
 	model = performInitialSetup(model)
+	model = checkRepositoryCompatibility(model)
+	model = checkBinaryIntegrity(model)
 	
 	return model
 }
@@ -60,12 +67,27 @@ func performInitialSetup(model MenuModel) MenuModel {
 	credentials := model.configManager.GetCredentials()
 	config := model.configManager.GetConfig()
 	
+	// Local mode reads the repository from a directory on disk and needs no GitHub access
+	if config.LocalRepoPath != "" {
+		return initializeLocalRepository(model, config.LocalRepoPath)
+	}
+	
 	// Check if this is the first run
 	if !model.configManager.IsConfigured() {
 		model.authError = "Welcome to BOBA! Please configure your GitHub repository in 'Installation Configuration' to get started."
 		return model
 	}
 	
+	// The git source clones an SSH remote with the user's SSH keys and needs no token
+	if model.configManager.UsesGitSource() {
+		return initializeGitRemote(model, config)
+	}
+	
+	// GitLab and Bitbucket repositories authenticate with credentials.json or a BOBA_*_TOKEN variable
+	if model.configManager.UsesGitLab() || model.configManager.UsesBitbucket() {
+		return initializeHostedRepository(model, config)
+	}
+	
 	// Validate existing configuration
 	if credentials.GitHubToken == "" {
 		model.authError = "GitHub authentication required. Please set up your token in 'Installation Configuration'."
@@ -109,18 +131,154 @@ func initializeGitHubIntegration(model MenuModel, credentials config.Credentials
 	
 	// Test connection and initialize components if successful
 	if err := model.githubClient.TestConnection(); err != nil {
+		if message, ok := github.RateLimitMessage(err); ok {
+			model.authError = message
+			return model
+		}
+		model.recordTokenResult(false)
 		model.authError = fmt.Sprintf("Repository access failed: %v\nPlease check your token and repository settings.", err)
 		return model
 	}
+	model.recordTokenResult(true)
 	
 	// Initialize parser, installation engine, and dependency resolver
-	model.repoParser = parser.NewRepositoryParser(model.githubClient)
-	model.installEngine = installer.NewInstallationEngine(model.githubClient)
+	model.repoParser = newRepositoryParser(model.githubClient, model.configManager)
+	model.installEngine = newInstallationEngine(model.githubClient, model.configManager)
 	model.dependencyResolver = installer.NewDependencyResolver()
 	
 	return model
 }
 
+// initializeHostedRepository sets up the parser and installation engine for a repository on
+// GitLab or Bitbucket
+func initializeHostedRepository(model MenuModel, cfg config.Config) MenuModel {
+	host := model.configManager.RepositoryHostName()
+	if !model.configManager.HasRepositoryToken() {
+		model.authError = fmt.Sprintf("%s authentication required. Please %s.", host, model.configManager.RepositoryCredentialsHint())
+		return model
+	}
+	
+	client, err := github.NewHostedBackend(model.configManager.GetRepositoryProvider(), model.configManager.RepositoryToken(), cfg.RepositoryURL, cloneOptionsFromConfig(model.configManager))
+	if err != nil {
+		model.authError = err.Error()
+		return model
+	}
+	if err := client.TestConnection(); err != nil {
+		model.authError = fmt.Sprintf("Repository access failed: %v\nPlease check your %s credentials and repository settings.", err, host)
+		return model
+	}
+	
+	// Clone on first use so scripts can read the repository's files, as GitHub authentication does
+	if cloneDir, err := client.GetCloneTargetDir(); err == nil {
+		if _, err := os.Stat(cloneDir); os.IsNotExist(err) {
+			if err := client.CloneRepository(cloneDir); err != nil {
+				log.Warn("Failed to clone the repository", "host", host, "error", err)
+			}
+		}
+	}
+	
+	model.hostedClient = client
+	model.repoParser = newRepositoryParser(client, model.configManager)
+	model.installEngine = newInstallationEngine(client, model.configManager)
+	model.dependencyResolver = installer.NewDependencyResolver()
+	
+	return model
+}
+
+// initializeGitRemote clones or updates the SSH remote and sets up the parser and installation
+// engine reading the clone
+func initializeGitRemote(model MenuModel, cfg config.Config) MenuModel {
+	remote, err := github.NewGitRemote(cfg.RepositoryURL, cfg.SSHKeyPath)
+	if err != nil {
+		model.authError = fmt.Sprintf("%v\nFix repository_url in config.json.", err)
+		return model
+	}
+	remote.SetCloneOptions(cloneOptionsFromConfig(model.configManager))
+	if err := remote.TestConnection(); err != nil {
+		model.authError = fmt.Sprintf("Repository access failed: %v\nPlease check that your SSH key (ssh_key_path in config.json, or the SSH agent) can read the repository.", err)
+		return model
+	}
+	if err := remote.Update(); errors.Is(err, github.ErrStaleClone) {
+		log.Warn("Reading the last clone of the repository", "remote", remote.Remote(), "error", err)
+	} else if err != nil {
+		model.authError = fmt.Sprintf("Failed to clone %s: %v", remote.Remote(), err)
+		return model
+	}
+	
+	// The clone is read directly, so no listing is cached
+	model.hostedClient = remote
+	model.repoParser = parser.NewRepositoryParserFromSource(remote)
+	model.installEngine = newInstallationEngine(remote, model.configManager)
+	model.dependencyResolver = installer.NewDependencyResolver()
+	
+	return model
+}
+
+// initializeLocalRepository sets up the parser and installation engine for local mode
+func initializeLocalRepository(model MenuModel, dir string) MenuModel {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		model.authError = fmt.Sprintf("Local repository %s is not a directory. Fix local_repo_path in config.json.", dir)
+		return model
+	}
+	
+	model.localRepo = github.NewLocalRepository(dir)
+	model.repoParser = parser.NewRepositoryParserFromSource(model.localRepo)
+	model.installEngine = installer.NewInstallationEngine(model.localRepo)
+	model.installEngine.ApplySettings(model.configManager)
+	model.installEngine.SetRepositoryDir(dir)
+	model.dependencyResolver = installer.NewDependencyResolver()
+	
+	return model
+}
+
+// newRepositoryParser creates a parser for the GitHub or GitLab repository that reads the listing
+// saved by `boba sync` first and only asks the API once it is stale
+func newRepositoryParser(client github.RepositoryBackend, configManager *config.ConfigManager) *parser.RepositoryParser {
+	repoParser := parser.NewRepositoryParserFromSource(client)
+	if client != nil && configManager != nil {
+		repoParser.UseDiskCache(configManager.GetRepositoryCachePath(), client.GetFullRepoName(), parser.DefaultCacheMaxAge)
+	}
+	return repoParser
+}
+
+// newInstallationEngine creates an installation engine configured from the user's settings
+func newInstallationEngine(client github.RepositoryBackend, configManager *config.ConfigManager) *installer.InstallationEngine {
+	engine := installer.NewInstallationEngine(client)
+	engine.ApplySettings(configManager)
+	
+	// Expose the local clone (created during authentication) to scripts
+	if client != nil {
+		if cloneDir, err := client.GetCloneTargetDir(); err == nil {
+			if _, err := os.Stat(cloneDir); err == nil {
+				engine.SetRepositoryDir(cloneDir)
+			}
+		}
+	}
+	
+	return engine
+}
+
+// cloneOptionsFromConfig returns the repository clone options from the user's settings
+func cloneOptionsFromConfig(configManager *config.ConfigManager) github.CloneOptions {
+	cfg := configManager.GetConfig()
+	options := github.DefaultCloneOptions()
+	options.Shallow = !cfg.FullClone
+	if cfg.SparseClone {
+		options.SparsePaths = github.DefaultSparsePaths
+	}
+	return options
+}
+
+// deviceClientID returns the OAuth App signing in through the GitHub device flow: github_client_id,
+// or the one BOBA was built with. Empty leaves only the personal access token.
+func deviceClientID(configManager *config.ConfigManager) string {
+	if id := configManager.GetConfig().GitHubClientID; id != "" {
+		return id
+	}
+	return github.DeviceClientID
+}
+
 // resolveRepositoryURL attempts to resolve a short repository name to full URL
 func resolveRepositoryURL(model MenuModel, token, repoName string) MenuModel {
 	// Create a temporary client to get the username
@@ -151,5 +309,13 @@ func resolveRepositoryURL(model MenuModel, token, repoName string) MenuModel {
 		return model
 	}
 	
+	// A trusted short name stays trusted as the user's own repository
+	if model.configManager.IsRepositoryTrusted(repoName) {
+		if err := model.configManager.TrustRepository(fullRepoURL); err != nil {
+			model.authError = fmt.Sprintf("Failed to save repository trust: %v", err)
+			return model
+		}
+	}
+	
 	return model
 }
\ No newline at end of file
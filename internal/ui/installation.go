@@ -2,76 +2,135 @@ package ui
 
 import (
 	"fmt"
+	"time"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/config"
+	"boba/internal/installer"
+	"boba/internal/log"
 	"boba/internal/parser"
 )
 
 // runInstallEverythingWithProgress runs the installation process with real-time progress updates
 func (m MenuModel) runInstallEverythingWithProgress() tea.Cmd {
 	return func() tea.Msg {
-		// Fetch tools from repository with retry logic
-		tools, err := m.repoParser.GetTools()
-		if err != nil {
-			return fmt.Sprintf("error_installation: Failed to fetch tools: %v", err)
+		// Managed machines only run approved plan files
+		if m.installEngine.ApprovalRequired() {
+			return "error_installation: This machine only runs plans approved by an admin: export the plan, have it approved, then run it with boba apply"
 		}
 		
-		// Fetch environments from repository
-		environments, err := m.repoParser.FetchEnvironments()
+		// Start a fresh run so the package index is refreshed once for this batch
+		m.installEngine.BeginRun()
+		
+		phase, err := m.resolveInstallEverything()
 		if err != nil {
-			return fmt.Sprintf("error_installation: Failed to fetch environments: %v", err)
+			return fmt.Sprintf("error_installation: %v", err)
+		}
+		if conflicts := m.unresolvedFileConflicts(phase.Environments); len(conflicts) > 0 {
+			return FileConflictsMsg{Conflicts: conflicts, Everything: true, Resume: func() tea.Msg { return m.comparePlan(phase) }}
+		}
+		if edited := m.unresolvedEditedFiles(phase.Environments); len(edited) > 0 {
+			return EditedFilesMsg{Files: edited, Everything: true, Resume: func() tea.Msg { return m.comparePlan(phase) }}
 		}
 		
-		// Filter tools and environments based on configuration
-		config := m.configManager.GetConfig()
+		// Start with tools phase, unless the plan is the one of the last successful run
+		return m.comparePlan(phase)
+	}
+}
+
+// resolveInstallEverything fetches the tools and environments enabled by the configuration and
+// overrides, leaves out the skipped ones and orders them by dependencies
+func (m MenuModel) resolveInstallEverything() (InstallEverythingPhaseMsg, error) {
+	// Fetch tools from repository with retry logic
+	tools, err := m.repoParser.GetTools()
+	if err != nil {
+		return InstallEverythingPhaseMsg{}, fmt.Errorf("Failed to fetch tools: %v", err)
+	}
+	
+	// Fetch environments from repository
+	environments, err := m.repoParser.GetEnvironments()
+	if err != nil {
+		return InstallEverythingPhaseMsg{}, fmt.Errorf("Failed to fetch environments: %v", err)
+	}
+	
+	// Ephemeral sessions only change the shell configuration in $HOME when asked explicitly
+	ephemeral := config.IsEphemeral()
+	
+	// Filter tools and environments based on configuration
+	config := m.configManager.GetConfig()
+	
+	// Tools and environments of a quarantined repository only run when explicitly enabled
+	trusted := m.isRepositoryTrusted()
+	
+	var toolsToInstall []parser.Tool
+	for _, tool := range tools {
+		shouldInstall := tool.AutoInstall && trusted
 		
-		var toolsToInstall []parser.Tool
-		for _, tool := range tools {
-			shouldInstall := tool.AutoInstall
-			
-			// Check for override
-			if override, exists := config.ToolOverrides[tool.Name]; exists {
-				shouldInstall = override
-			}
-			
-			if shouldInstall {
-				toolsToInstall = append(toolsToInstall, tool)
-			}
+		// Check for override
+		if override, exists := config.ToolOverrides[tool.Name]; exists {
+			shouldInstall = override
 		}
 		
-		var environmentsToApply []parser.Environment
-		for _, env := range environments {
-			shouldApply := env.AutoApply
-			
-			// Check for override
-			if override, exists := config.EnvironmentOverrides[env.Name]; exists {
-				shouldApply = override
-			}
-			
-			if shouldApply {
-				environmentsToApply = append(environmentsToApply, env)
-			}
+		if shouldInstall {
+			toolsToInstall = append(toolsToInstall, tool)
 		}
+	}
+	
+	var environmentsToApply []parser.Environment
+	var ephemeralSkipped []InstallationResult
+	for _, env := range environments {
+		shouldApply := env.AutoApply && trusted
 		
-		// Resolve dependencies and get installation order
-		resolver := m.dependencyResolver
-		orderedTools, orderedEnvironments, err := resolver.GetInstallationOrder(toolsToInstall, environmentsToApply)
-		if err != nil {
-			return fmt.Sprintf("error_installation: Failed to resolve dependencies: %v", err)
+		// Check for override
+		if override, exists := config.EnvironmentOverrides[env.Name]; exists {
+			shouldApply = override
 		}
 		
-		// Start with tools phase
-		return InstallEverythingPhaseMsg{
-			Phase:        "tools",
-			Tools:        orderedTools,
-			Environments: orderedEnvironments,
+		if shouldApply && ephemeral {
+			ephemeralSkipped = append(ephemeralSkipped, InstallationResult{
+				ToolName: env.Name,
+				Success:  false,
+				Message:  "⏭️ Skipped: ephemeral session, apply it from Setup Environment to change your shell configuration",
+				Phase:    ResultPhaseEnvironments,
+			})
+		} else if shouldApply {
+			environmentsToApply = append(environmentsToApply, env)
 		}
 	}
+	
+	// Leave out skipped, known-failing and cooling-down tools, the tools needing sudo in
+	// never-use-sudo mode, and the tools depending on them
+	skip := m.toolsToSkip(time.Now())
+	for _, tool := range toolsToInstall {
+		if m.installEngine.NeedsSudo(tool) {
+			skip[tool.Name] = "🔒 Skipped: needs a system-wide install (scope: system), and BOBA is set to never use sudo"
+		}
+	}
+	toolsToInstall, skipped := filterSkippedTools(toolsToInstall, skip)
+	
+	// Resolve dependencies and get installation order
+	resolver := m.dependencyResolver
+	orderedTools, orderedEnvironments, err := resolver.GetInstallationOrder(toolsToInstall, environmentsToApply)
+	if err != nil {
+		return InstallEverythingPhaseMsg{}, fmt.Errorf("Failed to resolve dependencies: %v", err)
+	}
+	
+	return InstallEverythingPhaseMsg{
+		Phase:        "tools",
+		Tools:        orderedTools,
+		Environments: orderedEnvironments,
+		Skipped:      append(skipped, ephemeralSkipped...),
+	}, nil
 }
 
+
 // runUpdateEverythingWithProgress runs the update process for installed tools
 func (m MenuModel) runUpdateEverythingWithProgress() tea.Cmd {
 	return func() tea.Msg {
+		// Start a fresh update run so the package index is refreshed once for this batch
+		// and satisfied tools are updated too
+		m.installEngine.BeginUpdateRun()
+		
 		// Get list of installed tools
 		tools, err := m.repoParser.GetTools()
 		if err != nil {
@@ -116,35 +175,56 @@ func (m MenuModel) installNextTool(tools []parser.Tool, currentIndex int, result
 					Phase:        "environments",
 					Tools:        tools,
 					Environments: m.pendingEnvironments,
+					ToolResults:  m.recheckConvergence(tools, results),
 				}
 			}
 		}
 		
 		// Complete the installation
 		return func() tea.Msg {
-			return InstallationCompleteMsg{Results: results}
+			return InstallationCompleteMsg{Results: m.recheckConvergence(tools, results)}
 		}
 	}
 	
 	return func() tea.Msg {
 		currentTool := tools[currentIndex]
 		
+		// Take the turn of the operation queue for the whole batch, and refresh the package index
+		// once before its first tool; failures are non-fatal since scripts fall back to refreshing themselves
+		if currentIndex == 0 {
+			if err := m.installEngine.BeginBatch(m.batchName(fmt.Sprintf("install %d tool(s)", len(tools)))); err != nil {
+				return InstallationCompleteMsg{Results: append(results, batchFailure(err))}
+			}
+			if _, err := m.installEngine.EnsurePackageIndexFresh(tools); err != nil {
+				log.Warn("Failed to refresh the package index", "error", err)
+			}
+		}
+		
 		// Install the tool (this call blocks until complete)
 		result, err := m.installEngine.InstallTool(currentTool)
 		
 		success := result.Success && err == nil
 		message := result.Output
 		if err != nil {
-			message = fmt.Sprintf("Installation failed: %v", err)
+			message = fmt.Sprintf("Installation failed: %s", describeError(err))
+		}
+		if result.TempDir != "" {
+			message += fmt.Sprintf(" (temp files kept in %s)", result.TempDir)
+		}
+		if len(result.Steps) > 0 {
+			message += "\n" + installer.StepSummary(result.Steps)
 		}
 		
 		// Record successful installation
-		if success {
+		if !result.DryRun {
+			m.recordToolRun(currentTool.Name, success, result)
+		}
+		if success && !result.DryRun {
 			version := currentTool.Version
 			if version == "" {
 				version = "latest"
 			}
-			m.configManager.RecordToolInstallation(currentTool.Name, version, "auto")
+			m.configManager.RecordToolInstallationWithProvenance(currentTool.Name, version, "auto", result.Provenance)
 		}
 		
 		// Add result to the list
@@ -153,6 +233,7 @@ func (m MenuModel) installNextTool(tools []parser.Tool, currentIndex int, result
 			Success:  success,
 			Message:  message,
 			Error:    err,
+			Phase:    toolResultPhase(tools, currentTool.Name),
 		})
 		
 		// Continue with next tool
@@ -175,6 +256,7 @@ func (m MenuModel) applyNextEnvironment(environments []parser.Environment, curre
 				Success:  result.Success,
 				Message:  result.Message,
 				Error:    result.Error,
+				Phase:    ResultPhaseEnvironments,
 			})
 		}
 		
@@ -186,13 +268,26 @@ func (m MenuModel) applyNextEnvironment(environments []parser.Environment, curre
 	return func() tea.Msg {
 		currentEnv := environments[currentIndex]
 		
+		// Install Everything holds the turn of the operation queue since its tools already
+		if currentIndex == 0 {
+			if err := m.installEngine.BeginBatch(m.batchName(fmt.Sprintf("apply %d environment(s)", len(environments)))); err != nil {
+				return InstallationCompleteMsg{Results: []InstallationResult{batchFailure(err)}}
+			}
+		}
+		
 		// Apply the environment configuration (this call blocks until complete)
 		installResult, err := m.installEngine.ApplyEnvironment(currentEnv)
 		
 		success := installResult.Success && err == nil
 		message := installResult.Output
 		if err != nil {
-			message = fmt.Sprintf("Environment application failed: %v", err)
+			message = fmt.Sprintf("Environment application failed: %s", describeError(err))
+		}
+		for _, note := range installResult.FileNotes {
+			message += "\n→ " + note
+		}
+		if success && !installResult.DryRun && m.configManager != nil {
+			m.configManager.RecordEnvironmentApplied(currentEnv.Name)
 		}
 		
 		result := EnvironmentApplicationResult{
@@ -212,4 +307,21 @@ func (m MenuModel) applyNextEnvironment(environments []parser.Environment, curre
 			Results:      newResults,
 		}
 	}
-}
\ No newline at end of file
+}
+// batchName names a batch in the operation queue other BOBA processes see
+func (m MenuModel) batchName(name string) string {
+	if m.installEverythingMode {
+		return "Install Everything"
+	}
+	return name
+}
+
+// batchFailure is the result of a batch that never got its turn in the operation queue
+func batchFailure(err error) InstallationResult {
+	return InstallationResult{
+		ToolName: "Operation queue",
+		Success:  false,
+		Message:  fmt.Sprintf("The batch did not run: %s", describeError(err)),
+		Error:    err,
+	}
+}
@@ -67,6 +67,9 @@ func TestCompleteUserWorkflow(t *testing.T) {
 			"Repository Configuration",
 			"Tool Override Management",
 			"Environment Override Management",
+			"⬆️ BOBA Updates",
+			"⏭️ Skip List Management",
+			"🌐 Browse Community Tools",
 			"← Back to Main Menu",
 		}
 		
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"boba/internal/installer"
+)
+
+// checkBinaryIntegrity warns when the running binary no longer matches the checksum
+// recorded when Install BOBA to System or a self-update wrote it
+func checkBinaryIntegrity(model MenuModel) MenuModel {
+	if model.systemInstaller == nil || model.configManager == nil {
+		return model
+	}
+	
+	path := model.systemInstaller.BinaryPath()
+	expected, ok := model.configManager.GetBinaryChecksum(path)
+	if !ok {
+		return model
+	}
+	if err := installer.VerifyChecksum(path, expected); errors.Is(err, installer.ErrChecksumMismatch) {
+		model.startupWarning = fmt.Sprintf("⚠️ The BOBA binary at %s changed since BOBA installed it. If you did not rebuild or replace it yourself, reinstall BOBA from a trusted release.", path)
+	}
+	return model
+}
+
+// recordBinaryChecksum remembers the checksum of a binary BOBA just wrote, for the startup check
+func (m MenuModel) recordBinaryChecksum(path, checksum string) {
+	if m.configManager == nil || path == "" || checksum == "" {
+		return
+	}
+	m.configManager.RecordBinaryChecksum(path, checksum)
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// lastRunChoice opens the results of the most recent run from the main menu
+const lastRunChoice = "📋 Last Run Results"
+
+// hasLastRun reports whether a run report is available to review
+func (m MenuModel) hasLastRun() bool {
+	if m.configManager == nil {
+		return false
+	}
+	return m.configManager.HasRunReport()
+}
+
+// saveRunReport records the results of a completed run so they survive dismissing the results screen,
+// and exports them as JUnit XML when requested; only a failed export is reported
+func (m MenuModel) saveRunReport(results []InstallationResult) error {
+	if m.configManager == nil || len(results) == 0 {
+		return nil
+	}
+	
+	report := config.RunReport{FinishedAt: time.Now()}
+	for _, result := range results {
+		message := result.Message
+		if message == "" && result.Error != nil {
+			message = result.Error.Error()
+		}
+		report.Results = append(report.Results, config.RunResult{
+			Name:    result.ToolName,
+			Phase:   result.Phase,
+			Success: result.Success,
+			Message: message,
+		})
+	}
+	
+	// The report is informational: failing to write it must not affect the run
+	_ = m.configManager.SaveRunReport(report)
+	
+	junitPath := m.junitReportPath
+	if junitPath == "" {
+		junitPath = m.configManager.GetJUnitReportPath()
+	}
+	if junitPath == "" {
+		return nil
+	}
+	return config.WriteJUnitFile(junitPath, report)
+}
+
+// showLastRun reopens the results screen with the results of the most recent run
+func (m MenuModel) showLastRun() (tea.Model, tea.Cmd) {
+	report, ok, err := m.configManager.LoadRunReport()
+	if err != nil || !ok {
+		message := "No run has been recorded yet"
+		if err != nil {
+			message = err.Error()
+		}
+		m.installationResults = []InstallationResult{{ToolName: "Last Run", Success: false, Message: message}}
+		m.showingResults = true
+		return m, nil
+	}
+	
+	var results []InstallationResult
+	for _, result := range report.Results {
+		results = append(results, InstallationResult{
+			ToolName: result.Name,
+			Success:  result.Success,
+			Message:  result.Message,
+			Phase:    result.Phase,
+		})
+	}
+	m.installationResults = results
+	m.resultsTitle = fmt.Sprintf("📋 Last Run - finished %s, %d of %d failed",
+		report.FinishedAt.Local().Format("2006-01-02 15:04"), report.Failed(), len(report.Results))
+	m.showingResults = true
+	return m, nil
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// MaintenanceMsg reports a maintenance run started with the UI
+type MaintenanceMsg struct {
+	Summary config.MaintenanceSummary
+}
+
+// startMaintenance runs the weekly maintenance in the background once it is due, or returns nil
+func (m MenuModel) startMaintenance() tea.Cmd {
+	if m.configManager == nil || !m.configManager.MaintenanceDue(time.Now()) {
+		return nil
+	}
+	configManager, cloneDir := m.configManager, m.maintenanceCloneDir()
+	return func() tea.Msg {
+		return MaintenanceMsg{Summary: configManager.RunMaintenance(cloneDir)}
+	}
+}
+
+// maintenanceCloneDir returns the local clone of the repository, or "" in local mode, whose
+// directory is the user's to update, and before a repository is connected
+func (m MenuModel) maintenanceCloneDir() string {
+	backend := m.repositoryBackend()
+	if backend == nil || m.configManager.GetConfig().LocalRepoPath != "" {
+		return ""
+	}
+	dir, err := backend.GetCloneTargetDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// handleMaintenanceMsg shows the summary of the run on the main menu
+func (m MenuModel) handleMaintenanceMsg(msg MaintenanceMsg) (tea.Model, tea.Cmd) {
+	m.maintenanceNotice = strings.Join(msg.Summary.Lines(), "\n")
+	return m, nil
+}
@@ -1,12 +1,39 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/config"
 )
 
 // UIManager handles the interactive terminal interface
 type UIManager struct {
 	program *tea.Program
+	
+	// JUnitReportPath overrides the configured JUnit XML export path for this session
+	JUnitReportPath string
+}
+
+// ErrNotTerminal is returned by Start when stdin or stdout isn't a terminal, such as in a pipe
+// or a CI job, where the UI would wait for keys that never come and garble the output
+var ErrNotTerminal = errors.New("the terminal UI needs an interactive terminal")
+
+// checkTerminal fails with ErrNotTerminal, naming the stream, when stdin or stdout isn't a terminal
+func checkTerminal(stdin, stdout *os.File) error {
+	for _, stream := range []struct {
+		name string
+		file *os.File
+	}{{"stdin", stdin}, {"stdout", stdout}} {
+		info, err := stream.file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return fmt.Errorf("%w, but %s is redirected: use boba install, boba list, boba env apply or BOBA_AUTO=1 instead", ErrNotTerminal, stream.name)
+		}
+	}
+	return nil
 }
 
 // NewUIManager creates a new UI manager
@@ -14,10 +41,54 @@ func NewUIManager() *UIManager {
 	return &UIManager{}
 }
 
-// Start initializes and runs the UI
+// Start initializes and runs the UI. After SafeModeThreshold startups in a row crashed during
+// initialization, it starts in safe mode instead. Without a terminal it fails with ErrNotTerminal.
 func (ui *UIManager) Start() error {
-	p := tea.NewProgram(InitialModel(), tea.WithAltScreen())
+	if err := checkTerminal(os.Stdin, os.Stdout); err != nil {
+		return err
+	}
+	
+	startup := config.NewConfigManager()
+	crashes := startup.BeginStartup()
+	defer func() {
+		if r := recover(); r != nil {
+			startup.RecordCrash(r, debug.Stack())
+			panic(r)
+		}
+	}()
+	
+	var model MenuModel
+	if crashes >= config.SafeModeThreshold {
+		model = beginHealthTracking(SafeModeModel(crashes))
+	} else {
+		model = beginHealthTracking(InitialModel())
+		var startupCmd tea.Cmd
+		model, startupCmd = restoreSession(model)
+		model.startupCmd = startupCmd
+	}
+	model.junitReportPath = ui.JUnitReportPath
+	startup.EndStartup()
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	ui.program = p
+	
+	// Recover from screens that stop updating during long background operations
+	stopWatchdog := model.watchdog.Start(p)
+	defer stopWatchdog()
+	
+	// Refresh menus automatically when the configuration changes
+	if model.configManager != nil {
+		unsubscribe := subscribeToConfig(p, model.configManager)
+		defer unsubscribe()
+	}
+	
 	_, err := p.Run()
+	
+	// Persist config changes still batched in memory
+	if model.configManager != nil {
+		if flushErr := model.configManager.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		model.configManager.EndRun(err != nil)
+	}
 	return err
 }
\ No newline at end of file
@@ -2,14 +2,16 @@ package ui
 
 import (
 	"fmt"
+	"time"
 )
 
 // getMenuChoices returns the choices for the current menu
 func (m MenuModel) getMenuChoices() []string {
 	switch m.currentMenu {
 	case MainMenu:
+		var choices []string
 		if !m.isGitHubAuthenticated() {
-			return []string{
+			choices = []string{
 				"Install Everything",
 				"List of Available Tools", 
 				"Setup Environment",
@@ -18,7 +20,7 @@ func (m MenuModel) getMenuChoices() []string {
 				"🔐 GitHub Authentication",
 			}
 		} else {
-			return []string{
+			choices = []string{
 				"Install Everything",
 				"List of Available Tools", 
 				"Setup Environment",
@@ -26,6 +28,16 @@ func (m MenuModel) getMenuChoices() []string {
 				"🔧 Install BOBA to System",
 			}
 		}
+		if badge := m.advisoriesChoice(); badge != "" {
+			choices = append(choices, badge)
+		}
+		if m.hasHistory() {
+			choices = append(choices, historyChoice)
+		}
+		if m.hasLastRun() {
+			choices = append(choices, lastRunChoice)
+		}
+		return choices
 	case InstallEverythingMenu:
 		return m.getInstallEverythingChoices()
 	case ToolsListMenu:
@@ -37,6 +49,15 @@ func (m MenuModel) getMenuChoices() []string {
 			"Repository Configuration",
 			"Tool Override Management",
 			"Environment Override Management",
+			"⬆️ BOBA Updates",
+			"⏭️ Skip List Management",
+			"🌐 Browse Community Tools",
+			m.noSudoChoice(),
+			m.dryRunChoice(),
+			"🧨 Reset & Wipe",
+			restorePointsChoice,
+			cleanupChoice,
+			operationQueueChoice,
 			"← Back to Main Menu",
 		}
 	case RepositoryConfigMenu:
@@ -49,6 +70,48 @@ func (m MenuModel) getMenuChoices() []string {
 		return []string{} // Auth model handles its own display
 	case SystemInstallMenu:
 		return m.getSystemInstallChoices()
+	case RepoSyncConflictMenu:
+		return m.getRepoSyncConflictChoices()
+	case RepoTrustMenu:
+		return m.getRepoTrustChoices()
+	case SelfUpdateMenu:
+		return m.getSelfUpdateChoices()
+	case SkipListMenu:
+		return m.getSkipListChoices()
+	case AdvisoriesMenu:
+		return m.getAdvisoriesChoices()
+	case EnvironmentPreviewMenu:
+		return m.getEnvironmentPreviewChoices()
+	case ToolScriptsMenu:
+		return m.getToolScriptsChoices()
+	case RunVariablesMenu:
+		return m.getRunVariablesChoices()
+	case ToolParametersMenu:
+		return m.getToolParametersChoices()
+	case SafeModeMenu:
+		return m.getSafeModeChoices()
+	case ResetMenu:
+		return m.getResetChoices()
+	case ValidationMenu:
+		return m.getValidationChoices()
+	case HistoryMenu:
+		return m.getHistoryChoices()
+	case RestorePointsMenu:
+		return m.getRestorePointsChoices()
+	case PlanUnchangedMenu:
+		return m.getPlanUnchangedChoices()
+	case FileConflictsMenu:
+		return m.getFileConflictsChoices()
+	case EditedFilesMenu:
+		return m.getEditedFilesChoices()
+	case ShellDefinitionsMenu:
+		return m.getShellDefinitionsChoices()
+	case CleanupMenu:
+		return m.getCleanupChoices()
+	case OperationQueueMenu:
+		return m.getOperationQueueChoices()
+	case CommunityMenu:
+		return m.getCommunityChoices()
 	default:
 		return []string{"← Back to Main Menu"}
 	}
@@ -115,12 +178,20 @@ func (m MenuModel) getInstallEverythingChoices() []string {
 				description = "Will install all auto-install tools from your repository"
 			}
 			
-			return []string{
-				"🚀 Start Installation Process",
+			choices := []string{"🚀 Start Installation Process"}
+			if known := m.knownFailingTools(); len(known) > 0 {
+				choices = append(choices, fmt.Sprintf("⏭️ Start, skipping %d known-failing tool(s)", len(known)))
+			}
+			if cooling := m.toolsInCooldown(time.Now()); len(cooling) > 0 {
+				choices = append(choices, fmt.Sprintf("⏳ Start, retrying %d tool(s) that failed in the last %s", len(cooling), shortDuration(m.configManager.GetInstallCooldown())))
+			}
+			return append(choices,
+				runVariablesChoice,
+				exportPlanChoice,
 				"🔄 Update Everything",
 				description,
 				"← Back to Main Menu",
-			}
+			)
 		}
 	} else {
 		return []string{
@@ -154,14 +225,27 @@ func (m MenuModel) getToolsListChoices() []string {
 					statusIcon = "⬜"
 				}
 				
-				// Check auto-install setting
-				if tool.AutoInstall {
+				// Check auto-install setting (quarantined repositories are manual install only)
+				if tool.AutoInstall && m.isRepositoryTrusted() {
 					autoIcon = "⚡" // Auto-install tools get a lightning bolt
 				} else {
 					autoIcon = "🔧" // Manual-install tools get a wrench
 				}
 				
 				toolDisplay := fmt.Sprintf("%s %s %s - %s", statusIcon, autoIcon, tool.Name, tool.Description)
+				toolDisplay += m.toolScopeLabel(tool.Name)
+				if m.installEngine != nil && m.installEngine.NeedsSudo(tool) {
+					toolDisplay += " 🔒 needs sudo"
+				}
+				if m.isToolSkipped(tool.Name) {
+					toolDisplay += " ⏭️ skipped"
+				}
+				if count := m.advisoryCount(tool.Name); count > 0 {
+					toolDisplay += fmt.Sprintf(" 🛡️ %d advisories", count)
+				}
+				if !tool.HasUninstall() {
+					toolDisplay += noUninstallLabel
+				}
 				choices = append(choices, toolDisplay)
 			}
 			choices = append(choices, "🔄 Refresh Tools List")
@@ -228,9 +312,15 @@ func (m MenuModel) getEnvironmentChoices() []string {
 				}
 				
 				envDisplay := fmt.Sprintf("%s %s %s - %s", shellIcon, autoIcon, env.Name, env.Description)
+				if m.configManager != nil {
+					if _, applied := m.configManager.GetEnvironmentAppliedDate(env.Name); applied {
+						envDisplay += " ✅"
+					}
+				}
 				choices = append(choices, envDisplay)
 			}
 			choices = append(choices, "🔄 Refresh Environments List")
+			choices = append(choices, shellDefinitionsChoice)
 			choices = append(choices, "← Back to Main Menu")
 			return choices
 		} else if m.loadingMessage != "" {
@@ -263,14 +353,32 @@ func (m MenuModel) getRepositoryConfigChoices() []string {
 	if currentRepo == "" {
 		currentRepo = "boba-config (default)"
 	}
+	if m.configManager.RepositoryFromEnvironment() {
+		currentRepo += " (from BOBA_REPO, not saved)"
+	}
+	trust := "🛡️ Repository Trust: trusted"
+	if !m.isRepositoryTrusted() {
+		trust = "🛡️ Repository Trust: quarantined (review)"
+	}
 	return []string{
 		fmt.Sprintf("Current Repository: %s", currentRepo),
 		"Change Repository Name",
 		"Reset to Default (boba-config)",
+		"🔄 Sync Repository",
+		trust,
+		"🔎 Validate Repository",
 		"← Back to Configuration Menu",
 	}
 }
 
+func (m MenuModel) getRepoSyncConflictChoices() []string {
+	return []string{
+		"Stash local changes and sync",
+		"Discard local changes and sync",
+		"← Cancel",
+	}
+}
+
 func (m MenuModel) getToolOverrideChoices() []string {
 	if m.isGitHubAuthenticated() {
 		if m.isLoading {
@@ -300,7 +408,10 @@ func (m MenuModel) getToolOverrideChoices() []string {
 					}
 				} else {
 					// No override, use default auto_install setting
-					if tool.AutoInstall {
+					if !m.isRepositoryTrusted() {
+						statusIcon = "🔒"
+						overrideStatus = "Manual-install (Quarantined)"
+					} else if tool.AutoInstall {
 						statusIcon = "⚡"
 						overrideStatus = "Auto-install (Default)"
 					} else {
@@ -310,6 +421,9 @@ func (m MenuModel) getToolOverrideChoices() []string {
 				}
 				
 				toolDisplay := fmt.Sprintf("%s %s - %s", statusIcon, tool.Name, overrideStatus)
+				if !tool.HasUninstall() {
+					toolDisplay += noUninstallLabel
+				}
 				choices = append(choices, toolDisplay)
 			}
 			choices = append(choices, "🔄 Refresh Tools List")
@@ -428,6 +542,11 @@ func (m *MenuModel) isGitHubAuthenticated() bool {
 		return false
 	}
 	
+	// Local mode reads the repository from disk, GitLab and Bitbucket repositories authenticate with their own credentials
+	if m.localRepo != nil || m.hostedClient != nil {
+		return true
+	}
+	
 	// If there's an authentication error, we're not authenticated
 	if m.authError != "" {
 		return false
@@ -470,6 +589,10 @@ func (m MenuModel) getSystemInstallChoices() []string {
 		}
 	}
 	
+	if m.confirmPurge {
+		return m.purgeConfirmationChoices()
+	}
+	
 	if m.systemInstallResult != nil {
 		// Show installation result
 		var choices []string
@@ -479,9 +602,21 @@ func (m MenuModel) getSystemInstallChoices() []string {
 			if m.systemInstallResult.ZshrcModified {
 				choices = append(choices, "🐚 Shell integration configured")
 			}
+			if m.systemInstallResult.Warning != "" {
+				choices = append(choices, "⚠️ "+m.systemInstallResult.Warning)
+			}
+			for _, removed := range m.systemInstallResult.Removed {
+				choices = append(choices, "🗑️ Removed "+removed)
+			}
+			for _, kept := range m.systemInstallResult.Kept {
+				choices = append(choices, "📦 Kept "+kept)
+			}
 			choices = append(choices, "")
 			choices = append(choices, m.systemInstallResult.Message)
 			choices = append(choices, "")
+			if m.dataRemoved {
+				return append(choices, "Press any key to quit BOBA")
+			}
 			choices = append(choices, "🗑️ Uninstall from System")
 		} else {
 			choices = append(choices, "❌ System Installation Failed")
@@ -508,12 +643,14 @@ func (m MenuModel) getSystemInstallChoices() []string {
 		choices = append(choices, "")
 		choices = append(choices, "🔄 Reinstall BOBA")
 		choices = append(choices, "🗑️ Uninstall from System")
+		choices = append(choices, purgeChoice)
 	} else {
 		choices = append(choices, "🔧 Install BOBA to System")
 		choices = append(choices, fmt.Sprintf("📍 Will install to: %s", info["install_path"]))
 		if requiresSudo {
 			choices = append(choices, "⚠️  Requires sudo privileges")
 		}
+		choices = append(choices, m.installTargetChoice())
 		choices = append(choices, "🐚 Will configure zsh shell integration")
 		choices = append(choices, "")
 		choices = append(choices, "▶️ Start System Installation")
@@ -4,6 +4,7 @@ import (
 	"strings"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/github"
 )
 
 // handleMenuSelection handles menu item selection
@@ -19,11 +20,66 @@ func (m MenuModel) handleMenuSelection() (tea.Model, tea.Cmd) {
 		return m.handleRepositoryConfigMenuSelection()
 	case SystemInstallMenu:
 		return m.handleSystemInstallMenuSelection()
+	case RepoSyncConflictMenu:
+		return m.handleRepoSyncConflictSelection()
+	case RepoTrustMenu:
+		return m.handleRepoTrustSelection()
+	case SelfUpdateMenu:
+		return m.handleSelfUpdateSelection()
+	case SkipListMenu:
+		return m.handleSkipListSelection()
+	case AdvisoriesMenu:
+		return m.handleAdvisoriesSelection()
+	case EnvironmentPreviewMenu:
+		return m.handleEnvironmentPreviewSelection()
+	case ToolScriptsMenu:
+		return m.handleToolScriptsSelection()
+	case RunVariablesMenu:
+		return m.handleRunVariablesSelection()
+	case ToolParametersMenu:
+		return m.handleToolParametersSelection()
+	case PlanUnchangedMenu:
+		return m.handlePlanUnchangedSelection()
+	case FileConflictsMenu:
+		return m.handleFileConflictsSelection()
+	case EditedFilesMenu:
+		return m.handleEditedFilesSelection()
+	case ShellDefinitionsMenu:
+		return m.handleShellDefinitionsSelection()
+	case CleanupMenu:
+		return m.handleCleanupSelection()
+	case OperationQueueMenu:
+		return m.handleOperationQueueSelection()
+	case SafeModeMenu:
+		return m.handleSafeModeSelection()
+	case ResetMenu:
+		return m.handleResetSelection()
+	case ValidationMenu:
+		return m.handleValidationSelection()
+	case HistoryMenu:
+		return m.handleHistorySelection()
+	case RestorePointsMenu:
+		return m.handleRestorePointsSelection()
+	case CommunityMenu:
+		return m.handleCommunitySelection()
 	}
 	return m, nil
 }
 
 func (m MenuModel) handleMainMenuSelection() (tea.Model, tea.Cmd) {
+	if choices := m.getMenuChoices(); m.cursor < len(choices) {
+		switch {
+		case choices[m.cursor] == lastRunChoice:
+			return m.showLastRun()
+		case choices[m.cursor] == historyChoice:
+			m.navigateToMenu(HistoryMenu)
+			return m, nil
+		case strings.HasPrefix(choices[m.cursor], advisoriesChoicePrefix):
+			m.navigateToMenu(AdvisoriesMenu)
+			return m, nil
+		}
+	}
+	
 	if !m.isGitHubAuthenticated() {
 		// When not authenticated, handle the extra auth option
 		switch m.cursor {
@@ -93,6 +149,43 @@ func (m MenuModel) handleConfigurationMenuSelection() (tea.Model, tea.Cmd) {
 			if m.isGitHubAuthenticated() {
 				return m.fetchAndDisplayEnvironments()
 			}
+		case 3:
+			// BOBA Updates - check the release channel and show the notes of a newer release
+			m.navigateToMenu(SelfUpdateMenu)
+			m.isLoading = true
+			m.loadingMessage = "Checking for BOBA updates..."
+			return m, m.checkForBobaUpdate()
+		case 4:
+			// Skip List Management
+			m.navigateToMenu(SkipListMenu)
+		case 5:
+			// Browse Community Tools - fetch the index of the community repository
+			return m.browseCommunityTools()
+		case 6:
+			// Never use sudo - toggle the user-scope-only mode
+			return m.toggleNoSudo()
+		case 7:
+			// Dry run - describe the scripts instead of running them, for this session
+			return m.toggleDryRun()
+		case 8:
+			// Reset & Wipe - remove credentials, caches, overrides or everything after a backup
+			m.pendingWipe = ""
+			m.resetStatus = ""
+			m.navigateToMenu(ResetMenu)
+		case 9:
+			// Restore Points - roll back to the state saved before Install Everything or an environment
+			m.pendingRestorePoint = nil
+			m.restoreStatus = ""
+			m.navigateToMenu(RestorePointsMenu)
+		case 10:
+			// Cleanup Suggestions - tools not used for a while, with usage tracking on
+			m.pendingCleanup = ""
+			m.cleanupStatus = ""
+			m.navigateToMenu(CleanupMenu)
+		case 11:
+			// Operation Queue - the running operation and the ones waiting, from any BOBA process
+			m.operationQueueStatus = ""
+			return m.showOperationQueue()
 		}
 	}
 	return m, nil
@@ -116,11 +209,39 @@ func (m MenuModel) handleRepositoryConfigMenuSelection() (tea.Model, tea.Cmd) {
 			m.configManager.SetRepositoryURL("boba-config")
 			// Refresh the menu to show updated repository
 			m.choices = m.getMenuChoices()
+		case 3:
+			// Sync Repository
+			return m.syncRepository(github.SyncFastForward)
+		case 4:
+			// Review Repository Trust
+			backend := m.repositoryBackend()
+			if backend == nil {
+				return m.startAuthentication()
+			}
+			m.isLoading = true
+			m.loadingMessage = "Inspecting repository..."
+			return m, m.reviewRepositoryTrust(backend)
+		case 5:
+			// Validate Repository - check every manifest, its scripts and dependencies
+			return m.validateRepository()
 		}
 	}
 	return m, nil
 }
 
+func (m MenuModel) handleRepoSyncConflictSelection() (tea.Model, tea.Cmd) {
+	switch m.cursor {
+	case 0:
+		return m.syncRepository(github.SyncStash)
+	case 1:
+		return m.syncRepository(github.SyncDiscard)
+	default:
+		m.syncLocalChanges = nil
+		m.navigateBack()
+	}
+	return m, nil
+}
+
 func (m MenuModel) handleComplexMenuSelection() (tea.Model, tea.Cmd) {
 	currentChoices := m.getMenuChoices()
 	if m.cursor == len(currentChoices)-1 {
@@ -153,7 +274,7 @@ func (m MenuModel) handleFirstOptionForAuthenticatedUser() (tea.Model, tea.Cmd)
 		if len(m.availableTools) > 0 {
 			// First tool selection
 			selectedTool := m.availableTools[0]
-			return m.installSingleTool(selectedTool)
+			return m.requestToolInstall(selectedTool)
 		} else if m.loadingMessage != "" {
 			// Retry fetching tools on error
 			m.loadingMessage = "" // Clear error message
@@ -167,9 +288,9 @@ func (m MenuModel) handleFirstOptionForAuthenticatedUser() (tea.Model, tea.Cmd)
 		}
 		// Since we auto-fetch, cursor 0 should be first environment or error retry
 		if len(m.availableEnvironments) > 0 {
-			// First environment selection
+			// First environment selection: review its files before applying it
 			selectedEnv := m.availableEnvironments[0]
-			return m.applyEnvironment(selectedEnv)
+			return m.previewEnvironment(selectedEnv)
 		} else if m.loadingMessage != "" {
 			// Retry fetching environments on error
 			m.loadingMessage = "" // Clear error message
@@ -213,7 +334,7 @@ func (m MenuModel) handleFirstOptionForAuthenticatedUser() (tea.Model, tea.Cmd)
 			// Don't allow action while installation is in progress
 			return m, nil
 		}
-		return m.startInstallEverything()
+		return m.startInstallEverythingWith(false, false)
 	}
 	return m, nil
 }
@@ -246,8 +367,18 @@ func (m MenuModel) handleInstallEverythingOptions() (tea.Model, tea.Cmd) {
 			return m.startUpdateEverything()
 		}
 	} else {
-		// When no results are shown, check for Update Everything option
-		if m.cursor == 1 { // "Update Everything"
+		// When no results are shown, the optional start entries sit between Start and Update Everything
+		switch choice := currentChoices[m.cursor]; {
+		case strings.HasPrefix(choice, "⏭️ Start"):
+			return m.startInstallEverythingWith(true, false)
+		case strings.HasPrefix(choice, "⏳ Start"):
+			return m.startInstallEverythingWith(false, true)
+		case choice == runVariablesChoice:
+			m.navigateToMenu(RunVariablesMenu)
+			return m, nil
+		case choice == exportPlanChoice:
+			return m.exportPlan()
+		case choice == "🔄 Update Everything":
 			return m.startUpdateEverything()
 		}
 	}
@@ -266,7 +397,7 @@ func (m MenuModel) handleToolsListOptions() (tea.Model, tea.Cmd) {
 		} else if m.cursor < len(m.availableTools) {
 			// Individual tool selection
 			selectedTool := m.availableTools[m.cursor]
-			return m.installSingleTool(selectedTool)
+			return m.requestToolInstall(selectedTool)
 		}
 	} else if m.loadingMessage != "" && m.cursor == 1 { // "Retry Fetching Tools"
 		m.loadingMessage = "" // Clear error message
@@ -279,13 +410,15 @@ func (m MenuModel) handleEnvironmentOptions() (tea.Model, tea.Cmd) {
 	// Handle other options in environment menu
 	currentChoices := m.getMenuChoices()
 	if len(m.availableEnvironments) > 0 {
-		// When environments are loaded, check for refresh option
-		if m.cursor == len(currentChoices)-2 { // "Refresh Environments List"
+		// When environments are loaded, check for refresh and shell definitions options
+		if m.cursor == len(currentChoices)-3 { // "Refresh Environments List"
 			return m.fetchAndDisplayEnvironments()
+		} else if m.cursor == len(currentChoices)-2 { // "Shell Definitions"
+			return m.showShellDefinitions()
 		} else if m.cursor < len(m.availableEnvironments) {
-			// Individual environment selection
+			// Individual environment selection: review its files before applying it
 			selectedEnv := m.availableEnvironments[m.cursor]
-			return m.applyEnvironment(selectedEnv)
+			return m.previewEnvironment(selectedEnv)
 		}
 	} else if m.loadingMessage != "" && m.cursor == 1 { // "Retry Fetching Environments"
 		m.loadingMessage = "" // Clear error message
@@ -338,6 +471,15 @@ func (m MenuModel) handleEnvironmentOverrideOptions() (tea.Model, tea.Cmd) {
 
 func (m MenuModel) handleSystemInstallMenuSelection() (tea.Model, tea.Cmd) {
 	currentChoices := m.getMenuChoices()
+	if m.confirmPurge {
+		m.confirmPurge = false
+		if m.cursor == 0 {
+			return m.startSystemUninstallation(true)
+		}
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+		return m, nil
+	}
 	if m.cursor == len(currentChoices)-1 {
 		// Back option
 		m.navigateBack()
@@ -359,7 +501,7 @@ func (m MenuModel) handleSystemInstallMenuSelection() (tea.Model, tea.Cmd) {
 		if m.systemInstallResult.Success {
 			// Successful installation - check for uninstall option
 			if strings.Contains(currentChoices[m.cursor], "Uninstall from System") {
-				return m.startSystemUninstallation()
+				return m.startSystemUninstallation(false)
 			}
 		} else {
 			// Failed installation - check for retry option
@@ -381,7 +523,12 @@ func (m MenuModel) handleSystemInstallMenuSelection() (tea.Model, tea.Cmd) {
 		case strings.Contains(currentChoices[m.cursor], "Reinstall BOBA"):
 			return m.startSystemInstallation()
 		case strings.Contains(currentChoices[m.cursor], "Uninstall from System"):
-			return m.startSystemUninstallation()
+			return m.startSystemUninstallation(false)
+		case currentChoices[m.cursor] == purgeChoice:
+			m.confirmPurge = true
+			m.choices = m.getMenuChoices()
+			m.cursor = 0
+			return m, nil
 		case strings.Contains(currentChoices[m.cursor], "View Installation Details"):
 			return m.showSystemInstallationDetails()
 		}
@@ -390,6 +537,8 @@ func (m MenuModel) handleSystemInstallMenuSelection() (tea.Model, tea.Cmd) {
 		switch {
 		case strings.Contains(currentChoices[m.cursor], "Start System Installation"):
 			return m.startSystemInstallation()
+		case currentChoices[m.cursor] == m.installTargetChoice():
+			return m.switchInstallTarget()
 		case strings.Contains(currentChoices[m.cursor], "View Installation Details"):
 			return m.showSystemInstallationDetails()
 		}
@@ -23,6 +23,27 @@ const (
 	EnvironmentOverrideMenu
 	GitHubAuthMenu
 	SystemInstallMenu
+	RepoSyncConflictMenu
+	RepoTrustMenu
+	SelfUpdateMenu
+	SkipListMenu
+	AdvisoriesMenu
+	CommunityMenu
+	EnvironmentPreviewMenu
+	ToolScriptsMenu
+	RunVariablesMenu
+	ToolParametersMenu
+	PlanUnchangedMenu
+	SafeModeMenu
+	ResetMenu
+	ValidationMenu
+	HistoryMenu
+	RestorePointsMenu
+	FileConflictsMenu
+	EditedFilesMenu
+	ShellDefinitionsMenu
+	CleanupMenu
+	OperationQueueMenu
 )
 
 // MenuModel represents the state of our menu system
@@ -41,6 +62,7 @@ type MenuModel struct {
 	dependencyResolver *installer.DependencyResolver
 	availableTools   []parser.Tool
 	toolInstallStatus map[string]bool // Cache for tool installation status
+	toolScopes        map[string]installer.InstalledScope // Where the installed tools were found, user or system-wide
 	availableEnvironments []parser.Environment // Available environment configurations
 	isLoading        bool
 	loadingMessage   string
@@ -50,7 +72,65 @@ type MenuModel struct {
 	installEverythingMode  bool // Flag to track if we're in "Install Everything" mode
 	pendingEnvironments    []parser.Environment // Environments to apply after tools
 	authError              string // Store authentication error for display
+	repoBadge              string // Compatibility of the repository with this machine, from boba.yaml
+	safeModeCrashes        int    // Crashed startups that made this session start in safe mode
+	safeModeStatus         string // Outcome of the last recovery action of the safe mode screen
+	pendingWipe            config.WipeScope // Reset scope awaiting confirmation on the reset screen
+	resetStatus            string // Outcome of the last reset
 	systemInstallResult    *installer.SystemInstallationResult // Result of system installation
+	confirmPurge           bool // Install BOBA to System asks to confirm removing all BOBA data
+	purging                bool // The running uninstall also removes all BOBA data
+	dataRemoved            bool // All BOBA data was removed: nothing is saved and any key quits
+	watchdog               *Watchdog // Detects a stalled UI during background operations
+	minimalStatus          bool // Render the plain status view after repeated UI stalls
+	syncLocalChanges       []string // Local modifications blocking a repository sync
+	repoTrustInfo          *RepoTrustInfoMsg // Repository awaiting the trust confirmation
+	localRepo              *github.LocalRepository // Repository directory in local mode (nil when using GitHub)
+	hostedClient           github.RepositoryBackend // Repository hosted on GitLab or Bitbucket, or the clone of an SSH remote (nil when using GitHub)
+	repoWatcher            *RepoWatcher // Detects changes to the local repository for hot reload
+	bobaRelease            *ReleaseCheckMsg // Latest BOBA release of the update channel, shown on the update screen
+	startupWarning         string // Binary integrity or health warning shown under the main menu
+	skipKnownFailing       bool // Install Everything leaves out skipped and known-failing tools
+	retryCooldown          bool // Install Everything retries tools whose install failed within the cooldown
+	resultsCollapsed       map[string]bool // Results screen sections collapsed or expanded by the user, by phase
+	resultsFailuresFirst   bool // Results screen lists failed results before successful ones
+	resultsTitle           string // Results screen title, when not the default one
+	junitReportPath        string // --junit flag: write run results as JUnit XML here instead of the configured path
+	advisories             *AdvisoryCheckMsg // Security advisories affecting the installed tools, once checked
+	communityIndex         *CommunityIndexMsg // Community tools index shown on the community tools screen
+	validation             *ValidationMsg // Report shown on the repository validation screen
+	historyOrder           string // Order of the installation history screen, one of config.HistoryOrders
+	pendingRestorePoint    *config.RestorePoint // Restore point awaiting the roll back confirmation
+	restoreStatus          string // Outcome of the last roll back
+	pendingCleanup         string // Unused tool awaiting the uninstall confirmation
+	cleanupStatus          string // Outcome of the last cleanup action
+	runningOperation       *config.Operation // Operation running on this machine, as the queue screen last read it
+	queuedOperations       []config.Operation // Operations waiting for their turn, as the queue screen last read them
+	operationQueueStatus   string // Outcome of the last cancellation
+	paletteOpen            bool // Command palette shown over the current screen
+	paletteQuery           string // Command palette search text
+	paletteCursor          int // Selected command palette match
+	sessionTracking        bool // Save the navigation state to session.json as it changes
+	savedSession           string // Navigation state last saved to session.json
+	sessionNotice          string // Restored session notice shown on the Install Everything screen
+	startupCmd             tea.Cmd // Command started with the program, such as fetching the restored menu's items
+	environmentPreview     *EnvironmentPreviewMsg // Environment files shown before applying it
+	toolScripts            *ToolScriptsMsg // Tool scripts shown on the tool scripts screen
+	viewer                 codeViewer // Files shown on the environment preview and tool scripts screens
+	runVariables           map[string]string // Script variables entered for the next Install Everything run
+	runVariableEditing     bool // A script variable value is being typed
+	runVariableName        string // Script variable being edited, empty when adding one
+	runVariableInput       string // Script variable value (or NAME=value) being typed
+	runVariableError       string // Invalid script variable entry
+	paramForm              parameterForm // Parameter values chosen before installing a tool
+	runPlan                *config.ExecutionPlan // Plan of the running Install Everything, saved when all of it succeeds
+	planUnchanged          *PlanUnchangedMsg // Run waiting for confirmation because its plan is unchanged
+	fileConflicts          *FileConflictsMsg // Run waiting for an owner or merge of the home files its environments share
+	editedFiles            *EditedFilesMsg // Run waiting for the user's edited home files to be kept, replaced or merged
+	shellDefinitions       []installer.ShellDefinition // Aliases, PATH entries and exports of the managed snippets
+	shellDefinitionsErr    error // Failure reading the shell rc files for the shell definitions screen
+	planNotice             string // Outcome of a skipped run shown on the Install Everything screen
+	maintenanceNotice      string // Summary of the maintenance run started with the UI, shown on the main menu
 }
 
 // MenuItem represents a menu option
@@ -65,6 +145,7 @@ type InstallationResult struct {
 	Success  bool
 	Message  string
 	Error    error
+	Phase    string // Results screen section: dependencies, tools, environments or hooks
 }
 
 // EnvironmentApplicationResult represents the result of environment application
@@ -94,6 +175,10 @@ type InstallEverythingPhaseMsg struct {
 	Phase        string // "tools" or "environments"
 	Tools        []parser.Tool
 	Environments []parser.Environment
+	Skipped      []InstallationResult // Tools left out of the run, reported with the results
+	ToolResults  []InstallationResult // Tool results after the convergence re-check, when moving to environments
+	Plan         *config.ExecutionPlan // Resolved plan of the run, nil when it could not be built
+	PlanChanges  []config.PlanChange // Differences from the last successful plan
 }
 
 type InstallationStartMsg struct {
@@ -118,6 +203,11 @@ type InstallationCompleteMsg struct {
 	Results []InstallationResult
 }
 
+type RepoSyncMsg struct {
+	Result *github.SyncResult
+	Err    error
+}
+
 type SystemInstallationStartMsg struct{}
 
 type SystemInstallationCompleteMsg struct {
@@ -126,7 +216,7 @@ type SystemInstallationCompleteMsg struct {
 
 // Init is called when the program starts
 func (m MenuModel) Init() tea.Cmd {
-	return nil
+	return tea.Batch(heartbeat(), watchConfig(), watchRepository(), m.startupCmd, m.startMaintenance())
 }
 
 // Getter methods for testing and external access
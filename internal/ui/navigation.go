@@ -10,6 +10,11 @@ func (m *MenuModel) navigateToMenu(menuType MenuType) {
 
 // navigateBack returns to the previous menu
 func (m *MenuModel) navigateBack() {
+	// Leaving a menu is a critical transition: persist batched config changes
+	if m.configManager != nil {
+		m.configManager.Flush()
+	}
+	
 	if len(m.menuStack) > 0 {
 		// Pop the last menu from the stack
 		m.currentMenu = m.menuStack[len(m.menuStack)-1]
@@ -19,6 +24,14 @@ func (m *MenuModel) navigateBack() {
 	}
 }
 
+// clampCursor keeps the cursor on the menu after its choices shrank, such as a reloaded list or
+// a restored session
+func (m *MenuModel) clampCursor() {
+	if m.cursor >= len(m.choices) {
+		m.cursor = max(len(m.choices)-1, 0)
+	}
+}
+
 // requiresAuthentication checks if the current menu action requires GitHub auth
 func (m *MenuModel) requiresAuthentication() bool {
 	switch m.currentMenu {
@@ -1,9 +1,21 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/advisory"
+	"boba/internal/config"
+	"boba/internal/github"
+	"boba/internal/installer"
 	"boba/internal/parser"
+	"boba/internal/version"
 )
 
 func TestInstallationProgressNavigationReset(t *testing.T) {
@@ -196,4 +208,1706 @@ func TestResultsScreenKeyPressNavigation(t *testing.T) {
 	} else {
 		t.Fatal("Expected MenuModel type after update")
 	}
-}
\ No newline at end of file
+}
+func TestRepoSyncConflictNavigation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	model := MenuModel{
+		configManager:     config.NewConfigManager(),
+		currentMenu:       RepositoryConfigMenu,
+		menuStack:         []MenuType{MainMenu, ConfigurationMenu},
+		isLoading:         true,
+		toolInstallStatus: make(map[string]bool),
+	}
+	
+	// Local changes open the stash/discard menu
+	updated, _ := model.Update(RepoSyncMsg{
+		Result: &github.SyncResult{LocalChanges: []string{" M tools/git/tool.yaml"}},
+		Err:    github.ErrLocalChanges,
+	})
+	model = updated.(MenuModel)
+	if model.currentMenu != RepoSyncConflictMenu {
+		t.Fatalf("Expected RepoSyncConflictMenu, got %v", model.currentMenu)
+	}
+	if model.isLoading {
+		t.Error("Expected loading to stop after sync result")
+	}
+	if !strings.Contains(model.getMenuTitle(), "tools/git/tool.yaml") {
+		t.Errorf("Expected modified files in the title, got %q", model.getMenuTitle())
+	}
+	
+	// A failed sync after choosing a strategy returns to the repository menu with the error
+	updated, _ = model.Update(RepoSyncMsg{Err: errors.New("cannot fast-forward")})
+	model = updated.(MenuModel)
+	if model.currentMenu != RepositoryConfigMenu {
+		t.Errorf("Expected to return to RepositoryConfigMenu, got %v", model.currentMenu)
+	}
+	if !model.showingResults || model.installationResults[0].Success {
+		t.Error("Expected failed sync result to be shown")
+	}
+}
+
+func TestRepoTrustConfirmation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	// The config directory may be shared (/tmp/.boba in containers): use a repository name never trusted before
+	owner := fmt.Sprintf("someone%d", time.Now().UnixNano())
+	model := MenuModel{
+		configManager:     configManager,
+		githubClient:      github.NewGitHubClient("token", owner, "boba-config"),
+		currentMenu:       MainMenu,
+		menuStack:         []MenuType{},
+		toolInstallStatus: make(map[string]bool),
+	}
+	if model.isRepositoryTrusted() {
+		t.Fatal("Expected a repository never seen before to be quarantined")
+	}
+	
+	info := &github.RepositoryInfo{FullName: owner + "/boba-config", Owner: owner, OwnerType: "User", Visibility: "public"}
+	updated, _ := model.Update(RepoTrustInfoMsg{Info: info, Scripts: 12})
+	model = updated.(MenuModel)
+	if model.currentMenu != RepoTrustMenu {
+		t.Fatalf("Expected RepoTrustMenu, got %v", model.currentMenu)
+	}
+	title := model.getMenuTitle()
+	for _, want := range []string{owner + "/boba-config", owner + " (user)", "public", "12"} {
+		if !strings.Contains(title, want) {
+			t.Errorf("Expected trust confirmation to mention %q, got:\n%s", want, title)
+		}
+	}
+	
+	// Trusting the repository lifts the quarantine and returns to the previous menu
+	model.cursor = 0
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != MainMenu {
+		t.Errorf("Expected to return to MainMenu, got %v", model.currentMenu)
+	}
+	if !model.isRepositoryTrusted() {
+		t.Error("Expected repository to be trusted after confirmation")
+	}
+}
+
+func TestConfigChangedRefreshesMenu(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	model := MenuModel{
+		configManager:     configManager,
+		currentMenu:       RepositoryConfigMenu,
+		menuStack:         []MenuType{MainMenu, ConfigurationMenu},
+		toolInstallStatus: make(map[string]bool),
+	}
+	model.choices = model.getMenuChoices()
+	
+	// Simulate another process changing the repository
+	repoURL := fmt.Sprintf("someone/boba-config-%d", time.Now().UnixNano())
+	configManager.SetRepositoryURL(repoURL)
+	
+	updated, cmd := model.Update(ConfigChangedMsg{Change: config.ConfigChange{External: true}})
+	model = updated.(MenuModel)
+	if cmd != nil {
+		t.Error("Expected no command for a config change")
+	}
+	if !strings.Contains(model.choices[0], repoURL) {
+		t.Errorf("Expected menu to show the new repository, got %q", model.choices[0])
+	}
+	
+	// The config file check always schedules the next check
+	if _, cmd := model.Update(configWatchMsg(time.Now())); cmd == nil {
+		t.Error("Expected the next config check to be scheduled")
+	}
+}
+
+func TestRepoWatchReloadsLists(t *testing.T) {
+	dir := t.TempDir()
+	toolDir := filepath.Join(dir, "tools", "git")
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatalf("Failed to create tool directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(toolDir, "tool.yaml"), []byte("name: git\ndescription: Version control\n"), 0644); err != nil {
+		t.Fatalf("Failed to write tool.yaml: %v", err)
+	}
+	
+	localRepo := github.NewLocalRepository(dir)
+	model := MenuModel{
+		localRepo:         localRepo,
+		repoParser:        parser.NewRepositoryParserFromSource(localRepo),
+		repoWatcher:       &RepoWatcher{},
+		currentMenu:       ToolsListMenu,
+		availableTools:    []parser.Tool{{Name: "git", Description: "Version control"}},
+		toolInstallStatus: make(map[string]bool),
+	}
+	
+	fingerprint := func() tea.Msg {
+		_, cmd := model.Update(repoWatchMsg(time.Now()))
+		if cmd == nil {
+			t.Fatal("Expected a fingerprint command")
+		}
+		return cmd()
+	}
+	
+	// The first fingerprint is the baseline: nothing to reload
+	updated, _ := model.Update(fingerprint())
+	model = updated.(MenuModel)
+	
+	// Editing a manifest reloads the tools list
+	if err := os.WriteFile(filepath.Join(toolDir, "tool.yaml"), []byte("name: git\ndescription: Edited\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit tool.yaml: %v", err)
+	}
+	msg := fingerprint()
+	if msg.(repoFingerprintMsg).Fingerprint == model.repoWatcher.fingerprint {
+		t.Fatal("Expected the fingerprint to change after editing a manifest")
+	}
+	_, cmd := model.Update(msg)
+	if cmd == nil {
+		t.Fatal("Expected reload commands")
+	}
+	
+	var reloaded *ToolsListMsg
+	for _, batched := range cmd().(tea.BatchMsg) {
+		if toolsMsg, ok := batched().(ToolsListMsg); ok {
+			reloaded = &toolsMsg
+			break
+		}
+	}
+	if reloaded == nil || len(reloaded.Tools) != 1 || reloaded.Tools[0].Description != "Edited" {
+		t.Errorf("Expected the tools list to be reloaded with the edit, got %+v", reloaded)
+	}
+}
+
+func TestSelfUpdateReleaseNotes(t *testing.T) {
+	original := version.Version
+	defer func() { version.Version = original }()
+	version.Version = "v1.2.0"
+	
+	model := MenuModel{
+		currentMenu:       SelfUpdateMenu,
+		menuStack:         []MenuType{MainMenu, ConfigurationMenu},
+		toolInstallStatus: make(map[string]bool),
+		isLoading:         true,
+	}
+	
+	release := &github.Release{Version: "v1.3.0", Name: "Faster installs", Notes: "- Parallel downloads\n- New BOBA_LIB helpers"}
+	updated, _ := model.Update(ReleaseCheckMsg{Release: release})
+	model = updated.(MenuModel)
+	if model.isLoading {
+		t.Error("Expected the release check to end the loading state")
+	}
+	
+	title := model.getMenuTitle()
+	for _, want := range []string{"v1.2.0", "v1.3.0 is available", "Faster installs", "Parallel downloads"} {
+		if !strings.Contains(title, want) {
+			t.Errorf("Expected update screen to mention %q, got:\n%s", want, title)
+		}
+	}
+	if len(model.choices) == 0 || model.choices[0] != "⬆️ Update to v1.3.0" {
+		t.Errorf("Expected the update to be offered first, got %v", model.choices)
+	}
+	
+	// Already on the latest release: only the channel, check and back entries remain
+	version.Version = "v1.3.0"
+	model.choices = model.getMenuChoices()
+	if len(model.choices) != 3 || !strings.Contains(model.getMenuTitle(), "latest stable release") {
+		t.Errorf("Expected no update to be offered on the latest release, got %v", model.choices)
+	}
+}
+
+func TestBinaryIntegrityWarning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	systemInstaller, err := installer.NewSystemInstaller()
+	if err != nil {
+		t.Skipf("System installer unavailable: %v", err)
+	}
+	
+	model := MenuModel{configManager: configManager, systemInstaller: systemInstaller, currentMenu: MainMenu}
+	if checkBinaryIntegrity(model).startupWarning != "" {
+		t.Error("Expected no warning without a recorded checksum")
+	}
+	
+	checksum, err := installer.FileChecksum(systemInstaller.BinaryPath())
+	if err != nil {
+		t.Fatalf("FileChecksum failed: %v", err)
+	}
+	model.recordBinaryChecksum(systemInstaller.BinaryPath(), checksum)
+	if checkBinaryIntegrity(model).startupWarning != "" {
+		t.Error("Expected no warning for an unchanged binary")
+	}
+	
+	model.recordBinaryChecksum(systemInstaller.BinaryPath(), strings.Repeat("0", 64))
+	if warning := checkBinaryIntegrity(model).startupWarning; !strings.Contains(warning, systemInstaller.BinaryPath()) {
+		t.Errorf("Expected a warning naming the changed binary, got %q", warning)
+	}
+}
+
+func TestHealthSuggestions(t *testing.T) {
+	if suggestions := healthSuggestions(config.HealthStats{ConsecutiveFailedRuns: 1, TokenFailures: 1}); len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions below the thresholds, got %v", suggestions)
+	}
+	
+	suggestions := healthSuggestions(config.HealthStats{
+		ConsecutiveFailedRuns: 3,
+		TokenFailures:         2,
+		ScriptFailures:        map[string]int{"neovim": 4, "ripgrep": 1},
+	})
+	joined := strings.Join(suggestions, "\n")
+	for _, want := range []string{"Sync Repository", "GitHub Authentication", "neovim failed to install 4 times"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected suggestions to mention %q, got:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "ripgrep") {
+		t.Errorf("Expected tools below the threshold to be left out, got:\n%s", joined)
+	}
+}
+
+func TestSkipListManagement(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	// The config directory may be shared (/tmp/.boba in containers): use a tool name never seen before
+	toolName := fmt.Sprintf("flaky%d", time.Now().UnixNano())
+	defer configManager.ForgetToolFailures(toolName)
+	
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         github.NewLocalRepository(t.TempDir()),
+		currentMenu:       ToolsListMenu,
+		menuStack:         []MenuType{MainMenu},
+		availableTools:    []parser.Tool{{Name: toolName, Description: "Flaky tool"}},
+		toolInstallStatus: make(map[string]bool),
+	}
+	
+	// "s" puts the tool on the skip list
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	model = updated.(MenuModel)
+	if !configManager.IsToolSkipped(toolName) || !strings.Contains(model.choices[0], "skipped") {
+		t.Fatalf("Expected the tool to be skipped, got %v", model.choices)
+	}
+	
+	// The skip list screen lists it, and selecting it removes it
+	model.navigateToMenu(SkipListMenu)
+	found := -1
+	for i, choice := range model.choices {
+		if strings.Contains(choice, toolName) {
+			found = i
+		}
+	}
+	if found < 0 {
+		t.Fatalf("Expected the skip list to show %s, got %v", toolName, model.choices)
+	}
+	model.cursor = found
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if configManager.IsToolSkipped(toolName) {
+		t.Error("Expected selecting the tool to remove it from the skip list")
+	}
+}
+
+func TestFilterSkippedTools(t *testing.T) {
+	tools := []parser.Tool{
+		{Name: "git"},
+		{Name: "node", Dependencies: []string{"git"}},
+		{Name: "yarn", Dependencies: []string{"node"}},
+		{Name: "ripgrep"},
+	}
+	
+	kept, skipped := filterSkippedTools(tools, map[string]string{"git": "⏳ Not retried"})
+	if len(kept) != 1 || kept[0].Name != "ripgrep" {
+		t.Errorf("Expected tools depending on a skipped tool to be left out, kept %v", kept)
+	}
+	if len(skipped) != 3 || skipped[0].Message != "⏳ Not retried" || !strings.Contains(skipped[2].Message, "depends on node") {
+		t.Errorf("Expected a result for each tool left out, got %+v", skipped)
+	}
+	
+	if kept, skipped := filterSkippedTools(tools, nil); len(kept) != 4 || skipped != nil {
+		t.Errorf("Expected nothing to be left out, got %v and %v", kept, skipped)
+	}
+}
+
+func TestResultsGroupedByPhase(t *testing.T) {
+	tools := []parser.Tool{
+		{Name: "git"},
+		{Name: "node", Dependencies: []string{"git"}},
+	}
+	if phase := toolResultPhase(tools, "git"); phase != ResultPhaseDependencies {
+		t.Errorf("Expected git to be reported as a dependency, got %q", phase)
+	}
+	if phase := toolResultPhase(tools, "node"); phase != ResultPhaseTools {
+		t.Errorf("Expected node to be reported as a tool, got %q", phase)
+	}
+	
+	// Install Everything finishing its environments phase reports the tool results too
+	model := MenuModel{
+		installEverythingMode: true,
+		installationResults: []InstallationResult{
+			{ToolName: "git", Success: true, Phase: ResultPhaseDependencies},
+			{ToolName: "node", Success: true, Phase: ResultPhaseTools},
+			{ToolName: "yarn", Success: false, Message: "Installation failed", Phase: ResultPhaseTools},
+		},
+		toolInstallStatus: make(map[string]bool),
+	}
+	updated, cmd := model.Update(InstallationCompleteMsg{Results: []InstallationResult{
+		{ToolName: "zsh-setup", Success: true, Phase: ResultPhaseEnvironments},
+	}})
+	model = updated.(MenuModel)
+	if cmd != nil || !model.showingResults || len(model.installationResults) != 4 {
+		t.Fatalf("Expected the run to complete with all results, got %d results", len(model.installationResults))
+	}
+	
+	groups := groupResults(model.installationResults, false)
+	if len(groups) != 3 || groups[1].Phase != ResultPhaseTools || groups[1].Succeeded != 1 || len(groups[1].Results) != 2 {
+		t.Fatalf("Expected dependencies, tools and environments sections, got %+v", groups)
+	}
+	
+	// Fully successful sections start collapsed, failing ones expanded
+	view := model.renderResultsScreen()
+	if !strings.Contains(view, "Tools (1/2 succeeded)") || !strings.Contains(view, "yarn") || strings.Contains(view, "zsh-setup") {
+		t.Errorf("Expected only the failing section to be expanded, got %s", view)
+	}
+	
+	// Number keys toggle sections, "f" lists failures first
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	model = updated.(MenuModel)
+	if !strings.Contains(model.renderResultsScreen(), "zsh-setup") {
+		t.Error("Expected pressing 3 to expand the environments section")
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	model = updated.(MenuModel)
+	if groups := groupResults(model.installationResults, model.resultsFailuresFirst); groups[1].Results[0].ToolName != "yarn" {
+		t.Errorf("Expected failures first after pressing f, got %+v", groups[1].Results)
+	}
+	if !model.showingResults {
+		t.Fatal("Expected section and sort keys to keep the results screen open")
+	}
+	
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(MenuModel)
+	if model.showingResults || model.resultsCollapsed != nil || model.resultsFailuresFirst {
+		t.Error("Expected any other key to return to the menu and reset the results screen")
+	}
+}
+
+func TestLastRunResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	// The config directory may be shared (/tmp/.boba in containers): use a tool name never seen before
+	toolName := fmt.Sprintf("lastrun%d", time.Now().UnixNano())
+	defer os.Remove(configManager.GetRunReportPath())
+	junitPath := filepath.Join(t.TempDir(), "boba.xml")
+	
+	model := MenuModel{
+		configManager:     configManager,
+		junitReportPath:   junitPath,
+		localRepo:         github.NewLocalRepository(t.TempDir()),
+		currentMenu:       MainMenu,
+		toolInstallStatus: make(map[string]bool),
+	}
+	updated, _ := model.Update(InstallationCompleteMsg{Results: []InstallationResult{
+		{ToolName: toolName, Success: false, Message: "Installation failed", Phase: ResultPhaseTools},
+	}})
+	model = updated.(MenuModel)
+	if data, err := os.ReadFile(junitPath); err != nil || !strings.Contains(string(data), toolName) {
+		t.Errorf("Expected the run to be exported as JUnit XML, got %q (%v)", data, err)
+	}
+	
+	// Dismissing the results screen keeps the run available from the main menu
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(MenuModel)
+	if model.showingResults || len(model.installationResults) != 0 {
+		t.Fatal("Expected any key to dismiss the results screen")
+	}
+	choices := model.getMenuChoices()
+	if choices[len(choices)-1] != lastRunChoice {
+		t.Fatalf("Expected the main menu to offer the last run, got %v", choices)
+	}
+	
+	model.cursor = len(choices) - 1
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	view := model.renderResultsScreen()
+	if !model.showingResults || len(model.installationResults) != 1 || model.installationResults[0].ToolName != toolName {
+		t.Fatalf("Expected the last run results to be shown again, got %+v", model.installationResults)
+	}
+	if !strings.Contains(view, "Last Run") || !strings.Contains(view, "1 of 1 failed") {
+		t.Errorf("Expected the last run title, got %s", view)
+	}
+}
+
+func TestSecurityAdvisories(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         github.NewLocalRepository(t.TempDir()),
+		currentMenu:       MainMenu,
+		availableTools:    []parser.Tool{{Name: "prettier", Description: "Formatter"}},
+		toolInstallStatus: map[string]bool{"prettier": true},
+	}
+	
+	updated, _ := model.Update(AdvisoryCheckMsg{Advisories: map[string][]advisory.Advisory{
+		"prettier": {{ID: "GHSA-1", Summary: "Code execution", Aliases: []string{"CVE-2024-0001"}, Fixed: []string{"3.0.1"}}},
+	}})
+	model = updated.(MenuModel)
+	
+	// The main menu shows a badge leading to the advisories screen
+	found := -1
+	for i, choice := range model.choices {
+		if choice == advisoriesChoicePrefix+" (1)" {
+			found = i
+		}
+	}
+	if found < 0 {
+		t.Fatalf("Expected an advisories badge in the main menu, got %v", model.choices)
+	}
+	model.cursor = found
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != AdvisoriesMenu {
+		t.Fatalf("Expected the advisories screen, got menu %v", model.currentMenu)
+	}
+	title := model.getMenuTitle()
+	if !strings.Contains(title, "GHSA-1 (CVE-2024-0001): Code execution") || !strings.Contains(title, "Fixed in 3.0.1") {
+		t.Errorf("Expected the advisory details, got %s", title)
+	}
+	if !strings.Contains(model.choices[0], "Update prettier") {
+		t.Errorf("Expected an update prompt for prettier, got %v", model.choices)
+	}
+	
+	model.currentMenu = ToolsListMenu
+	if choices := model.getMenuChoices(); !strings.Contains(choices[0], "🛡️ 1 advisories") {
+		t.Errorf("Expected an advisories marker in the tools list, got %v", choices)
+	}
+}
+
+func TestCommunityToolImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	repoDir := t.TempDir()
+	localRepo := github.NewLocalRepository(repoDir)
+	
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         localRepo,
+		repoParser:        parser.NewRepositoryParserFromSource(localRepo),
+		currentMenu:       CommunityMenu,
+		menuStack:         []MenuType{MainMenu, ConfigurationMenu},
+		availableTools:    []parser.Tool{{Name: "fzf", FolderName: "fzf"}},
+		toolInstallStatus: make(map[string]bool),
+	}
+	index := &github.CommunityIndex{Owner: "acme", Repo: "community", Commit: "0123abcdef4567", Tools: []github.CommunityTool{
+		{Name: "fzf", Description: "Fuzzy finder", Path: "tools/fzf"},
+		{Name: "ripgrep", Description: "Fast search", Path: "tools/ripgrep"},
+	}}
+	updated, _ := model.Update(CommunityIndexMsg{Index: index})
+	model = updated.(MenuModel)
+	if !strings.Contains(model.choices[0], "already in your repository") || !strings.HasPrefix(model.choices[1], "📥 ripgrep") {
+		t.Fatalf("Expected existing tools to be marked, got %v", model.choices)
+	}
+	if title := model.getMenuTitle(); !strings.Contains(title, "at 0123abcdef45") {
+		t.Errorf("Expected the index commit in the title, got %s", title)
+	}
+	
+	// Importing writes a tool.yaml pinned to the index commit and reloads the tools
+	manifest, err := github.PinnedManifest(nil, index.Tools[1], index.Source(index.Tools[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, cmd := model.Update(writeImportedTool(localRepo, index.Tools[1], manifest, index.Source(index.Tools[1])))
+	model = updated.(MenuModel)
+	if !model.showingResults || !model.installationResults[0].Success || cmd == nil {
+		t.Fatalf("Expected a successful import, got %+v", model.installationResults)
+	}
+	toolsMsg, ok := cmd().(ToolsListMsg)
+	if !ok || len(toolsMsg.Tools) != 1 || toolsMsg.Tools[0].Source != "acme/community/tools/ripgrep@0123abcdef4567" {
+		t.Errorf("Expected the imported tool with its pinned source, got %+v", toolsMsg)
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         github.NewLocalRepository(t.TempDir()),
+		currentMenu:       ToolsListMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+		availableTools:    []parser.Tool{{Name: "ripgrep"}, {Name: "fzf"}},
+	}
+	
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	model = updated.(MenuModel)
+	if !model.paletteOpen || !strings.Contains(model.View(), "Command Palette") {
+		t.Fatal("Expected Ctrl+P to open the command palette")
+	}
+	
+	// Keys bound to navigation elsewhere are typed into the query
+	for _, r := range "skip" {
+		updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = updated.(MenuModel)
+	}
+	matches := model.paletteMatches()
+	if model.paletteQuery != "skip" || len(matches) == 0 || matches[0].Title != "Settings: Skip List Management" {
+		t.Fatalf("Expected the skip list to be the best match, got query %q and %d matches", model.paletteQuery, len(matches))
+	}
+	
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(MenuModel)
+	if model.paletteOpen || model.currentMenu != SkipListMenu {
+		t.Fatalf("Expected the skip list to open, got menu %v", model.currentMenu)
+	}
+	if len(model.menuStack) != 2 || model.menuStack[0] != MainMenu || model.menuStack[1] != ConfigurationMenu {
+		t.Errorf("Expected going back to lead to the settings, got stack %v", model.menuStack)
+	}
+	
+	// Tools are offered by name, and Escape closes the palette without running anything
+	updated, _ = model.openPalette()
+	model = updated.(MenuModel)
+	model.paletteQuery = "inst rg"
+	if matches := model.paletteMatches(); len(matches) == 0 || matches[0].Title != "Install ripgrep" {
+		t.Errorf("Expected \"Install ripgrep\" to be the best match, got %+v", matches)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(MenuModel)
+	if model.paletteOpen || model.currentMenu != SkipListMenu {
+		t.Error("Expected Escape to close the palette and stay on the current screen")
+	}
+}
+
+func TestSessionRestoration(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	defer os.Remove(configManager.GetSessionPath())
+	
+	newModel := func() MenuModel {
+		localRepo := github.NewLocalRepository(t.TempDir())
+		return MenuModel{
+			configManager:     configManager,
+			localRepo:         localRepo,
+			repoParser:        parser.NewRepositoryParserFromSource(localRepo),
+			currentMenu:       MainMenu,
+			toolInstallStatus: make(map[string]bool),
+		}
+	}
+	
+	// Navigation is saved as it changes
+	model := newModel()
+	model.sessionTracking = true
+	model.navigateToMenu(ConfigurationMenu)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = updated.(MenuModel)
+	state, ok, err := configManager.LoadSession()
+	if !ok || err != nil || state.Menu != "configuration" || state.Cursor != 1 {
+		t.Fatalf("Expected the settings menu to be saved, got %+v (ok=%v, err=%v)", state, ok, err)
+	}
+	
+	restored, cmd := restoreSession(newModel())
+	if restored.currentMenu != ConfigurationMenu || restored.cursor != 1 || cmd != nil {
+		t.Errorf("Expected to return to the settings menu, got menu %v cursor %d", restored.currentMenu, restored.cursor)
+	}
+	if len(restored.menuStack) != 1 || restored.menuStack[0] != MainMenu || !restored.sessionTracking {
+		t.Errorf("Expected going back to lead to the main menu, got stack %v", restored.menuStack)
+	}
+	
+	// Menus listing repository items fetch them again
+	model.navigateToMenu(ToolOverrideMenu)
+	model = model.saveSession()
+	if restored, cmd := restoreSession(newModel()); restored.currentMenu != ToolOverrideMenu || !restored.isLoading || cmd == nil {
+		t.Errorf("Expected the tool overrides to be fetched again, got menu %v", restored.currentMenu)
+	}
+	
+	// An interrupted Install Everything run reopens its plan with a notice
+	model = newModel()
+	model.navigateToMenu(InstallEverythingMenu)
+	model.installationInProgress = true
+	model = model.saveSession()
+	restored, _ = restoreSession(newModel())
+	if restored.currentMenu != InstallEverythingMenu || !strings.Contains(restored.View(), "interrupted") {
+		t.Errorf("Expected the interrupted run to be reported on the Install Everything screen, got menu %v", restored.currentMenu)
+	}
+}
+
+func TestEnvironmentPreview(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	repoDir := t.TempDir()
+	envDir := filepath.Join(repoDir, "environments", "zsh")
+	if err := os.MkdirAll(envDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(envDir, "setup.sh"), []byte("#!/bin/bash\n# Link the shell config\ncp .zshrc \"$HOME/.zshrc\"\n"), 0644)
+	os.WriteFile(filepath.Join(envDir, ".zshrc"), []byte("export EDITOR=vim\n"), 0644)
+	
+	localRepo := github.NewLocalRepository(repoDir)
+	env := parser.Environment{
+		Name:        "zsh",
+		FolderName:  "zsh",
+		SetupScript: "environments/zsh/setup.sh",
+		ConfigFiles: []string{"environments/zsh/.zshrc"},
+	}
+	model := MenuModel{
+		configManager:         configManager,
+		localRepo:             localRepo,
+		installEngine:         installer.NewInstallationEngine(localRepo),
+		dependencyResolver:    installer.NewDependencyResolver(),
+		repoParser:            parser.NewRepositoryParserFromSource(localRepo),
+		currentMenu:           EnvironmentMenu,
+		menuStack:             []MenuType{MainMenu},
+		toolInstallStatus:     make(map[string]bool),
+		availableEnvironments: []parser.Environment{env},
+	}
+	
+	// Selecting an environment previews it instead of applying it right away
+	updated, cmd := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != EnvironmentPreviewMenu || cmd == nil {
+		t.Fatalf("Expected the environment preview to open, got menu %v", model.currentMenu)
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(MenuModel)
+	
+	view := model.View()
+	for _, expected := range []string{"environments/zsh/setup.sh", "cp .zshrc", "environments/zsh/.zshrc", "EDITOR=vim", "✅ Apply zsh"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the preview to show %q, got:\n%s", expected, view)
+		}
+	}
+	
+	// Applying returns to the environments list while the environment is applied
+	updated, cmd = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != EnvironmentMenu || !model.isLoading || cmd == nil {
+		t.Errorf("Expected the environment to be applied from the environments list, got menu %v", model.currentMenu)
+	}
+}
+
+func TestHighlightLine(t *testing.T) {
+	// Without a color profile (as in tests) highlighting must leave the text untouched
+	lines := map[string]string{
+		"setup.sh":    `export PATH="$HOME/bin:${GOPATH}/bin:$PATH" # prepend`,
+		".bashrc":     `echo 'unterminated $1 $ `,
+		"tool.yaml":   `  install_script: "install.sh"  # comment`,
+		"config.toml": `key = [1, 2] ; done`,
+	}
+	for file, line := range lines {
+		if highlighted := highlightLine(line, syntaxFor(file)); highlighted != line {
+			t.Errorf("Expected %s line %q to keep its text, got %q", file, line, highlighted)
+		}
+	}
+	if syntaxFor("environments/zsh/.zshrc").comment != "#" || !syntaxFor("tool.yaml").keys || syntaxFor("settings.json").comment != "" {
+		t.Error("Expected the syntax to be picked from the file name")
+	}
+}
+
+func TestCodeViewer(t *testing.T) {
+	var script strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&script, "echo step %d\n", i)
+	}
+	viewer := newCodeViewer([]installer.RepositoryFile{
+		{Path: "tools/demo/install.sh", Content: []byte(script.String())},
+		{Path: "tools/demo/uninstall.sh", Err: fmt.Errorf("not found")},
+	})
+	
+	view := viewer.View()
+	if !strings.Contains(view, " 1 │ echo step 1") || strings.Contains(view, "echo step 25") {
+		t.Errorf("Expected the first lines with line numbers, got:\n%s", view)
+	}
+	
+	// Searching scrolls to the first match and n moves to the next one
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("/")},
+		{Type: tea.KeyRunes, Runes: []rune("step 3")},
+		{Type: tea.KeyEnter},
+	} {
+		var handled bool
+		if viewer, handled = viewer.update(key); !handled {
+			t.Fatalf("Expected the viewer to handle %q", key.String())
+		}
+	}
+	if len(viewer.matches) != 11 || !strings.Contains(viewer.View(), "Match 1 of 11") {
+		t.Fatalf("Expected 11 matches for \"step 3\", got %d", len(viewer.matches))
+	}
+	viewer, _ = viewer.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	view = viewer.View()
+	if !strings.Contains(view, "▶30 │ echo step 30") || !strings.Contains(view, "Match 2 of 11") {
+		t.Errorf("Expected the second match to be shown, got:\n%s", view)
+	}
+	
+	viewer, _ = viewer.update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if view := viewer.View(); !strings.Contains(view, "Could not fetch this file: not found") {
+		t.Errorf("Expected the file that could not be fetched to be reported, got:\n%s", view)
+	}
+	if _, handled := viewer.update(tea.KeyMsg{Type: tea.KeyEnter}); handled {
+		t.Error("Expected Enter to be left to the menu when not searching")
+	}
+}
+
+func TestToolScripts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	repoDir := t.TempDir()
+	toolDir := filepath.Join(repoDir, "tools", "ripgrep")
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(toolDir, "install.sh"), []byte("#!/bin/bash\nbrew install ripgrep\n"), 0644)
+	
+	localRepo := github.NewLocalRepository(repoDir)
+	tool := parser.Tool{Name: "ripgrep", InstallScript: "tools/ripgrep/install.sh", UninstallScript: "tools/ripgrep/uninstall.sh"}
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         localRepo,
+		installEngine:     installer.NewInstallationEngine(localRepo),
+		currentMenu:       ToolsListMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+		availableTools:    []parser.Tool{tool},
+	}
+	
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	model = updated.(MenuModel)
+	if model.currentMenu != ToolScriptsMenu || cmd == nil {
+		t.Fatalf("Expected v to open the tool scripts, got menu %v", model.currentMenu)
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(MenuModel)
+	
+	// The missing uninstall script is left out
+	view := model.View()
+	if !strings.Contains(view, "brew install ripgrep") || strings.Contains(view, "uninstall.sh") || !strings.Contains(view, "📦 Install ripgrep") {
+		t.Errorf("Expected the install script only, got:\n%s", view)
+	}
+	
+	// Typing a search does not trigger the menu keys
+	for _, r := range "/q" {
+		updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = updated.(MenuModel)
+	}
+	if model.currentMenu != ToolScriptsMenu || !model.viewer.searching || model.viewer.query != "q" {
+		t.Errorf("Expected q to be typed into the search, got menu %v and query %q", model.currentMenu, model.viewer.query)
+	}
+}
+
+func TestRunScriptVariables(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	if err := configManager.SetScriptEnv(map[string]string{"CORP_PROXY": "http://proxy:3128"}); err != nil {
+		t.Fatal(err)
+	}
+	defer configManager.SetScriptEnv(nil)
+	
+	localRepo := github.NewLocalRepository(t.TempDir())
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         localRepo,
+		repoParser:        parser.NewRepositoryParserFromSource(localRepo),
+		installEngine:     installer.NewInstallationEngine(localRepo),
+		currentMenu:       InstallEverythingMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+	}
+	
+	choices := model.getMenuChoices()
+	for i, choice := range choices {
+		if choice == runVariablesChoice {
+			model.cursor = i
+		}
+	}
+	updated, _ := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != RunVariablesMenu || model.getMenuChoices()[0] != "CORP_PROXY = http://proxy:3128" {
+		t.Fatalf("Expected the configured variables, got menu %v with %v", model.currentMenu, model.getMenuChoices())
+	}
+	
+	// Add a variable for this run; menu keys are typed into the value
+	typeText := func(text string) {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+		model = updated.(MenuModel)
+	}
+	model.cursor = 1
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	typeText("bad entry")
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(MenuModel)
+	if !model.runVariableEditing || model.runVariableError == "" {
+		t.Fatal("Expected an entry without NAME=value to be rejected")
+	}
+	model.runVariableInput = ""
+	typeText("NPM_REGISTRY=https://npm.corp.example/q")
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(MenuModel)
+	if choices := model.getMenuChoices(); choices[1] != "NPM_REGISTRY = https://npm.corp.example/q  (this run)" {
+		t.Fatalf("Expected the run variable to be listed, got %v", choices)
+	}
+	
+	// Starting runs Install Everything, and the run variables are dropped once it completes
+	model.cursor = 3
+	updated, cmd := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != InstallEverythingMenu || !model.installationInProgress || cmd == nil {
+		t.Fatalf("Expected Install Everything to start, got menu %v", model.currentMenu)
+	}
+	updated, _ = model.Update(InstallationCompleteMsg{})
+	model = updated.(MenuModel)
+	if model.runVariables != nil || configManager.GetScriptEnv()["CORP_PROXY"] != "http://proxy:3128" {
+		t.Errorf("Expected only the run variables to be dropped, got %v", model.runVariables)
+	}
+}
+
+func TestToolParametersForm(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	// The config directory may be shared (/tmp/.boba in containers): use a tool name never seen before
+	toolName := fmt.Sprintf("java%d", time.Now().UnixNano())
+	defer configManager.SetToolParameters(toolName, nil)
+	
+	localRepo := github.NewLocalRepository(t.TempDir())
+	tool := parser.Tool{Name: toolName, Parameters: []parser.Parameter{
+		{Name: "distribution", Choices: []string{"temurin", "zulu"}},
+		{Name: "version", Type: parser.ParameterInt, Default: "21"},
+	}}
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         localRepo,
+		installEngine:     installer.NewInstallationEngine(localRepo),
+		currentMenu:       ToolsListMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+		availableTools:    []parser.Tool{tool},
+	}
+	
+	updated, _ := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != ToolParametersMenu || model.getMenuChoices()[0] != "distribution = temurin" {
+		t.Fatalf("Expected the parameters form with the defaults, got menu %v with %v", model.currentMenu, model.getMenuChoices())
+	}
+	
+	// Choices cycle, other values are typed and validated
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	model.cursor = 1
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyBackspace}, {Type: tea.KeyBackspace}, {Type: tea.KeyRunes, Runes: []rune("x")}, {Type: tea.KeyEnter}} {
+		updated, _ = model.Update(key)
+		model = updated.(MenuModel)
+	}
+	if model.paramForm.editing == "" || model.paramForm.err == "" {
+		t.Fatal("Expected a non-numeric version to be rejected")
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyBackspace}, {Type: tea.KeyRunes, Runes: []rune("17")}, {Type: tea.KeyEnter}} {
+		updated, _ = model.Update(key)
+		model = updated.(MenuModel)
+	}
+	if choices := model.getMenuChoices(); choices[0] != "distribution = zulu" || choices[1] != "version = 17" {
+		t.Fatalf("Expected the changed values, got %v", choices)
+	}
+	
+	// Installing remembers the values for later runs
+	model.cursor = 2
+	updated, cmd := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != ToolsListMenu || cmd == nil {
+		t.Errorf("Expected the install to start from the tools list, got menu %v", model.currentMenu)
+	}
+	if saved := configManager.GetToolParameters()[toolName]; saved["distribution"] != "zulu" || saved["version"] != "17" {
+		t.Errorf("Expected the values to be saved, got %v", saved)
+	}
+}
+
+func TestConvergenceRecheck(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	name := fmt.Sprintf("hollow-%d", time.Now().UnixNano())
+	configManager.RecordToolInstallationWithProvenance(name, "latest", "auto", nil)
+	defer configManager.RemoveInstalledTool(name)
+	
+	localRepo := github.NewLocalRepository(t.TempDir())
+	model := MenuModel{
+		configManager: configManager,
+		installEngine: installer.NewInstallationEngine(localRepo),
+	}
+	tools := []parser.Tool{
+		{Name: name, SatisfiedWhen: &parser.SatisfiedCheck{Command: "false"}},
+		{Name: "real", SatisfiedWhen: &parser.SatisfiedCheck{Command: "true"}},
+		{Name: "unchecked"},
+	}
+	results := []InstallationResult{
+		{ToolName: name, Success: true, Phase: ResultPhaseTools},
+		{ToolName: "real", Success: true, Phase: ResultPhaseTools},
+		{ToolName: "unchecked", Success: true, Phase: ResultPhaseTools},
+	}
+	
+	rechecked := model.recheckConvergence(tools, results)
+	if rechecked[0].Success || !strings.Contains(rechecked[0].Message, notConvergedMessage) {
+		t.Errorf("Expected the tool failing its check to be flagged, got %+v", rechecked[0])
+	}
+	if !rechecked[1].Success || !rechecked[2].Success {
+		t.Errorf("Expected the other tools to keep their results, got %+v", rechecked[1:])
+	}
+	if !results[0].Success {
+		t.Error("Expected the original results to be left untouched")
+	}
+	if _, installed := configManager.GetInstalledTool(name); installed {
+		t.Error("Expected the flagged tool to be removed from the installation records")
+	}
+}
+
+func TestUnchangedPlan(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	defer os.Remove(configManager.GetLastPlanPath())
+	
+	localRepo := github.NewLocalRepository(t.TempDir())
+	model := MenuModel{
+		configManager:     configManager,
+		localRepo:         localRepo,
+		repoParser:        parser.NewRepositoryParserFromSource(localRepo),
+		installEngine:     installer.NewInstallationEngine(localRepo),
+		currentMenu:       InstallEverythingMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+	}
+	tools := []parser.Tool{{Name: "git", FolderName: "git", Catalog: true, InstallInline: "echo git\n"}}
+	
+	// First run: nothing to compare with
+	phase, ok := model.comparePlan(InstallEverythingPhaseMsg{Phase: "tools", Tools: tools}).(InstallEverythingPhaseMsg)
+	if !ok || phase.Plan == nil || len(phase.PlanChanges) != 0 {
+		t.Fatalf("Expected the first run to start with its plan, got %+v", phase)
+	}
+	
+	// The plan is recorded once every operation succeeded
+	model.runPlan = phase.Plan
+	model.saveRunPlan([]InstallationResult{{ToolName: "git", Success: false}})
+	if _, saved, _ := configManager.LoadLastPlan(); saved {
+		t.Fatal("Expected a failed run not to record its plan")
+	}
+	model.runPlan = phase.Plan
+	model.saveRunPlan([]InstallationResult{{ToolName: "git", Success: true}})
+	if _, saved, _ := configManager.LoadLastPlan(); !saved {
+		t.Fatal("Expected a successful run to record its plan")
+	}
+	
+	// Same plan: ask before running it again
+	unchanged, ok := model.comparePlan(InstallEverythingPhaseMsg{Phase: "tools", Tools: tools}).(PlanUnchangedMsg)
+	if !ok {
+		t.Fatal("Expected an unchanged plan to be reported")
+	}
+	updated, _ := model.Update(unchanged)
+	model = updated.(MenuModel)
+	if model.currentMenu != PlanUnchangedMenu || !strings.Contains(model.getMenuTitle(), "1 operations") {
+		t.Fatalf("Expected the unchanged plan screen, got menu %v: %s", model.currentMenu, model.getMenuTitle())
+	}
+	model.cursor = 1
+	updated, cmd := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != InstallEverythingMenu || !model.installationInProgress || cmd == nil {
+		t.Fatalf("Expected Run anyway to start the run, got menu %v", model.currentMenu)
+	}
+	if rerun, ok := cmd().(InstallEverythingPhaseMsg); !ok || len(rerun.Tools) != 1 {
+		t.Errorf("Expected the pending run to start, got %+v", rerun)
+	}
+	
+	// Edited script: the run starts and reports what changed
+	tools[0].InstallInline = "echo git 2\n"
+	phase = model.comparePlan(InstallEverythingPhaseMsg{Phase: "tools", Tools: tools}).(InstallEverythingPhaseMsg)
+	updated, _ = model.Update(phase)
+	model = updated.(MenuModel)
+	if len(model.installationResults) != 1 || model.installationResults[0].Message != "~ tool git (scripts changed)" {
+		t.Errorf("Expected the plan changes in the results, got %+v", model.installationResults)
+	}
+}
+
+func TestSafeMode(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	model := SafeModeModel(config.SafeModeThreshold)
+	if model.currentMenu != SafeModeMenu || model.githubClient != nil || model.repoParser != nil {
+		t.Fatalf("Expected safe mode to start without GitHub or the repository, got menu %v", model.currentMenu)
+	}
+	if title := model.getMenuTitle(); !strings.Contains(title, "crashed during startup the last 2 times") {
+		t.Errorf("Unexpected safe mode title: %s", title)
+	}
+	
+	model.configManager.RecordCrash("boom", []byte("stack"))
+	model.cursor = 2 // View Crash Log
+	updated, _ := model.handleSafeModeSelection()
+	model = updated.(MenuModel)
+	if !strings.Contains(model.safeModeStatus, "panic: boom") {
+		t.Errorf("Expected the crash log to be shown, got %q", model.safeModeStatus)
+	}
+	
+	model.cursor = 4 // Continue in Safe Mode
+	updated, _ = model.handleSafeModeSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != MainMenu || model.safeModeStatus != "" {
+		t.Errorf("Expected the main menu, got menu %v", model.currentMenu)
+	}
+}
+
+func TestResetMenu(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	configManager.SetToolOverride("docker", true)
+	model := MenuModel{currentMenu: ConfigurationMenu, configManager: configManager}
+	
+	model.cursor = 8 // Reset & Wipe
+	updated, _ := model.handleConfigurationMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != ResetMenu || len(model.choices) != len(config.WipeScopes)+1 {
+		t.Fatalf("Expected the reset scopes, got menu %v: %v", model.currentMenu, model.choices)
+	}
+	
+	model.cursor = 2 // Overrides Only
+	updated, _ = model.handleResetSelection()
+	model = updated.(MenuModel)
+	if model.pendingWipe != config.WipeOverrides || model.cursor != 1 {
+		t.Fatalf("Expected a confirmation defaulting to Cancel, got %q at %d", model.pendingWipe, model.cursor)
+	}
+	updated, _ = model.handleResetSelection()
+	model = updated.(MenuModel)
+	if _, exists := configManager.GetToolOverride("docker"); !exists || model.pendingWipe != "" {
+		t.Fatal("Expected Cancel to keep the overrides")
+	}
+	
+	model.cursor = 2
+	updated, _ = model.handleResetSelection()
+	model = updated.(MenuModel)
+	model.cursor = 0 // Yes, back up and wipe
+	updated, _ = model.handleResetSelection()
+	model = updated.(MenuModel)
+	if _, exists := configManager.GetToolOverride("docker"); exists {
+		t.Error("Expected the overrides to be removed")
+	}
+	if !strings.Contains(model.resetStatus, configManager.GetBackupDir()) {
+		t.Errorf("Expected the backup path in the status, got %q", model.resetStatus)
+	}
+}
+
+func TestRestorePointsMenu(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("# before\n"), 0644)
+	model := MenuModel{currentMenu: ConfigurationMenu, configManager: configManager}
+	model.saveRestorePoint("Before applying zsh", nil)
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("# after\n"), 0644)
+	
+	model.cursor = 9 // Restore Points
+	updated, _ := model.handleConfigurationMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != RestorePointsMenu || len(model.choices) != 2 || !strings.HasPrefix(model.choices[0], "Before applying zsh") {
+		t.Fatalf("Expected the restore point, got menu %v: %v", model.currentMenu, model.choices)
+	}
+	
+	model.cursor = 0
+	updated, _ = model.handleRestorePointsSelection()
+	model = updated.(MenuModel)
+	if model.pendingRestorePoint == nil || model.cursor != 2 {
+		t.Fatalf("Expected a confirmation defaulting to Cancel, got cursor %d", model.cursor)
+	}
+	model.cursor = 0 // Yes, roll back
+	updated, _ = model.handleRestorePointsSelection()
+	model = updated.(MenuModel)
+	if content, _ := os.ReadFile(filepath.Join(home, ".zshrc")); string(content) != "# before\n" {
+		t.Errorf("Expected .zshrc to be rolled back, got %q", content)
+	}
+	if !strings.Contains(model.restoreStatus, "Restored: .zshrc") || len(model.choices) != 3 {
+		t.Errorf("Expected the roll back and the saved previous state, got %q: %v", model.restoreStatus, model.choices)
+	}
+}
+
+func TestFileConflictsMenu(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	engine := installer.NewInstallationEngine(nil)
+	resumed := false
+	model := MenuModel{currentMenu: MainMenu, configManager: configManager, installEngine: engine}
+	
+	updated, _ := model.Update(FileConflictsMsg{
+		Conflicts: []parser.FileConflict{
+			{File: ".profile", Environments: []string{"bash", "zsh"}},
+			{File: ".zshrc", Environments: []string{"zsh", "zsh-work"}},
+		},
+		Everything: true,
+		Resume:     func() tea.Msg { resumed = true; return nil },
+	})
+	model = updated.(MenuModel)
+	if model.currentMenu != FileConflictsMenu || len(model.choices) != 4 || !strings.Contains(model.getMenuTitle(), "~/.profile is managed by bash, zsh") {
+		t.Fatalf("Expected the first conflict, got menu %v: %v", model.currentMenu, model.choices)
+	}
+	
+	model.cursor = 2 // Merge
+	updated, cmd := model.handleFileConflictsSelection()
+	model = updated.(MenuModel)
+	if cmd != nil || model.currentMenu != FileConflictsMenu || model.choices[1] != "📄 zsh-work owns ~/.zshrc" {
+		t.Fatalf("Expected the second conflict, got %v", model.choices)
+	}
+	model.cursor = 1 // zsh-work owns it
+	updated, cmd = model.handleFileConflictsSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != MainMenu || !model.installationInProgress || cmd == nil {
+		t.Fatalf("Expected the run to resume, got menu %v", model.currentMenu)
+	}
+	cmd()
+	if !resumed {
+		t.Error("Expected the resume command to run")
+	}
+	if owner, _ := configManager.GetFileOwner(".profile"); owner != config.FileMergeStrategy {
+		t.Errorf("Expected .profile to be merged, got %q", owner)
+	}
+	if owner, _ := configManager.GetFileOwner(".zshrc"); owner != "zsh-work" {
+		t.Errorf("Expected zsh-work to own .zshrc, got %q", owner)
+	}
+	zsh := parser.Environment{Name: "zsh", ConfigFiles: []string{"environments/zsh/.zshrc"}}
+	work := parser.Environment{Name: "zsh-work", ConfigFiles: []string{"environments/zsh-work/.zshrc"}}
+	if err := engine.CheckFileConflicts([]parser.Environment{zsh, work}, nil); err != nil {
+		t.Errorf("Expected the engine to use the saved owners, got %v", err)
+	}
+}
+
+func TestEditedFilesMenu(t *testing.T) {
+	engine := installer.NewInstallationEngine(nil)
+	resumed := false
+	model := MenuModel{currentMenu: MainMenu, installEngine: engine}
+	
+	updated, _ := model.Update(EditedFilesMsg{
+		Files: []installer.EditedFile{
+			{File: ".zshrc", Environment: "zsh", AppliedBy: "zsh", Base: []byte("export EDITOR=vim\n"), Mine: []byte("export EDITOR=vim\nalias ll='ls -l'\n"), Repo: []byte("export EDITOR=nvim\n")},
+			{File: ".bashrc", Environment: "bash", AppliedBy: "bash", Base: []byte("set -o vi\n"), Mine: []byte("")},
+		},
+		Resume: func() tea.Msg { resumed = true; return nil },
+	})
+	model = updated.(MenuModel)
+	title := model.getMenuTitle()
+	if model.currentMenu != EditedFilesMenu || len(model.choices) != 4 || !strings.Contains(title, "~/.zshrc was edited since zsh applied it") {
+		t.Fatalf("Expected the first edited file, got menu %v: %s", model.currentMenu, title)
+	}
+	for _, want := range []string{"+ alias ll='ls -l'", "- export EDITOR=vim", "+ export EDITOR=nvim"} {
+		if !strings.Contains(title, want) {
+			t.Errorf("Expected %q in the diff, got:\n%s", want, title)
+		}
+	}
+	
+	model.cursor = 2 // Merge
+	updated, cmd := model.handleEditedFilesSelection()
+	model = updated.(MenuModel)
+	if cmd != nil || !strings.Contains(model.getMenuTitle(), "~/.bashrc") || !strings.Contains(model.getMenuTitle(), "could not be fetched") {
+		t.Fatalf("Expected the second edited file, got %s", model.getMenuTitle())
+	}
+	model.cursor = 0 // Keep mine
+	updated, cmd = model.handleEditedFilesSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != MainMenu || !model.isLoading || cmd == nil {
+		t.Fatalf("Expected the application to resume, got menu %v", model.currentMenu)
+	}
+	cmd()
+	if !resumed {
+		t.Error("Expected the resume command to run")
+	}
+	
+	// Cancelling leaves the run
+	updated, _ = model.Update(EditedFilesMsg{Files: []installer.EditedFile{{File: ".zshrc"}}})
+	model = updated.(MenuModel)
+	model.cursor = 3
+	updated, cmd = model.handleEditedFilesSelection()
+	if model = updated.(MenuModel); cmd != nil || model.currentMenu != MainMenu || model.editedFiles != nil {
+		t.Errorf("Expected cancel to go back without running, got menu %v", model.currentMenu)
+	}
+}
+
+func TestValidationMenu(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "tools", "node"), 0755)
+	os.WriteFile(filepath.Join(dir, "tools", "node", "tool.yaml"), []byte("name: node\ndescription: Runtime\ndependencies: [missing]\n"), 0644)
+	model := MenuModel{
+		configManager: config.NewConfigManager(),
+		currentMenu:   RepositoryConfigMenu,
+		repoParser:    parser.NewRepositoryParserFromSource(github.NewLocalRepository(dir)),
+	}
+	
+	model.cursor = 5 // Validate Repository
+	updated, cmd := model.handleRepositoryConfigMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != ValidationMenu || !model.isLoading || cmd == nil {
+		t.Fatalf("Expected the validation to start, got menu %v", model.currentMenu)
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(MenuModel)
+	if !strings.Contains(strings.Join(model.choices, "\n"), "depends on missing") {
+		t.Errorf("Expected the missing dependency to be listed, got %v", model.choices)
+	}
+	if !strings.Contains(model.getMenuTitle(), "Validated 1 tools") {
+		t.Errorf("Unexpected title: %s", model.getMenuTitle())
+	}
+	
+	model.cursor = len(model.choices) - 1
+	updated, _ = model.handleMenuSelection()
+	if updated.(MenuModel).currentMenu != RepositoryConfigMenu {
+		t.Error("Expected Back to return to the repository menu")
+	}
+}
+
+func TestExportPlan(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.HomeEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	
+	name := fmt.Sprintf("plan-tool-%d", time.Now().UnixNano())
+	dir := t.TempDir()
+	toolDir := filepath.Join(dir, "tools", name)
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	envDir := filepath.Join(dir, "environments", "shell")
+	os.MkdirAll(envDir, 0755)
+	os.WriteFile(filepath.Join(envDir, "environment.yaml"), []byte("name: shell\n"), 0644)
+	os.WriteFile(filepath.Join(envDir, "setup.sh"), []byte("#!/bin/bash\n"), 0644)
+	os.WriteFile(filepath.Join(toolDir, "tool.yaml"), []byte("name: "+name+"\nauto_install: true\n"), 0644)
+	os.WriteFile(filepath.Join(toolDir, "install.sh"), []byte("#!/bin/bash\necho install\n"), 0644)
+	
+	localRepo := github.NewLocalRepository(dir)
+	model := MenuModel{
+		configManager:      configManager,
+		localRepo:          localRepo,
+		repoParser:         parser.NewRepositoryParserFromSource(localRepo),
+		installEngine:      installer.NewInstallationEngine(localRepo),
+		dependencyResolver: installer.NewDependencyResolver(),
+		currentMenu:        InstallEverythingMenu,
+		menuStack:          []MenuType{MainMenu},
+		toolInstallStatus:  make(map[string]bool),
+	}
+	
+	for i, choice := range model.getMenuChoices() {
+		if choice == exportPlanChoice {
+			model.cursor = i
+		}
+	}
+	updated, cmd := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if cmd == nil {
+		t.Fatal("Expected the plan to be exported")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(MenuModel)
+	path, _ := filepath.Abs(planExportFile)
+	if !strings.Contains(model.planNotice, "boba apply "+path) {
+		t.Fatalf("Expected the export to tell how to apply it, got %q", model.planNotice)
+	}
+	plan, err := config.ReadPlanFile(path)
+	if err != nil || len(plan.Entries) != 1 || plan.Entries[0].Name != name {
+		t.Fatalf("Expected the plan of %s, got %+v (%v)", name, plan, err)
+	}
+}
+
+func TestInstallTargetChoice(t *testing.T) {
+	systemInstaller, err := installer.NewSystemInstaller()
+	if err != nil {
+		t.Fatalf("Failed to create system installer: %v", err)
+	}
+	if systemInstaller.IsSystemInstalled() {
+		t.Skip("BOBA is installed on this machine")
+	}
+	systemInstaller.SetTarget(installer.InstallTargetSystem)
+	model := MenuModel{
+		currentMenu:       SystemInstallMenu,
+		menuStack:         []MenuType{MainMenu},
+		toolInstallStatus: make(map[string]bool),
+		systemInstaller:   systemInstaller,
+	}
+	model.choices = model.getMenuChoices()
+	
+	// Choosing ~/.local/bin switches the target and offers /usr/local/bin back
+	for i, choice := range model.choices {
+		if choice == "🔀 Install to ~/.local/bin instead (no sudo)" {
+			model.cursor = i
+		}
+	}
+	updated, _ := model.handleSystemInstallMenuSelection()
+	model = updated.(MenuModel)
+	if systemInstaller.Target() != installer.InstallTargetUser {
+		t.Fatalf("Expected the user target, got %s", systemInstaller.Target())
+	}
+	joined := strings.Join(model.choices, "\n")
+	if !strings.Contains(joined, filepath.Join(".local", "bin", "boba")) || strings.Contains(joined, "Requires sudo") ||
+		!strings.Contains(joined, "Install to /usr/local/bin instead") {
+		t.Errorf("Expected the ~/.local/bin install without sudo, got:\n%s", joined)
+	}
+}
+
+func TestHistoryMenu(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	model := MenuModel{configManager: configManager, currentMenu: MainMenu}
+	for _, choice := range model.getMenuChoices() {
+		if choice == historyChoice {
+			t.Fatal("Expected no history entry before anything is installed")
+		}
+	}
+	
+	configManager.RecordToolInstallationWithProvenance("rg", "14.1.0", "auto", nil)
+	configManager.RecordToolInstallationWithProvenance("go", "1.24.0", "manual", nil)
+	model.choices = model.getMenuChoices()
+	model.cursor = -1
+	for i, choice := range model.choices {
+		if choice == historyChoice {
+			model.cursor = i
+		}
+	}
+	if model.cursor < 0 {
+		t.Fatalf("Expected the history entry on the main menu, got %v", model.choices)
+	}
+	updated, _ := model.handleMainMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != HistoryMenu || len(model.choices) != 4 || !strings.Contains(model.choices[0], "(manual)") {
+		t.Fatalf("Expected the records on the history screen, got %v", model.choices)
+	}
+	if !strings.Contains(model.getMenuTitle(), "2 tool(s)") {
+		t.Errorf("Expected the records to be counted, got %q", model.getMenuTitle())
+	}
+	
+	// The toggle cycles from install date to last update to name
+	model.cursor = 2
+	updated, _ = model.handleHistorySelection()
+	model = updated.(MenuModel)
+	updated, _ = model.handleHistorySelection()
+	model = updated.(MenuModel)
+	if model.historyOrder != config.HistoryByName || !strings.HasPrefix(model.choices[0], "go ") || !strings.Contains(model.choices[2], "install date") {
+		t.Errorf("Expected the records by name, got %v", model.choices)
+	}
+	model.cursor = 3
+	updated, _ = model.handleHistorySelection()
+	if updated.(MenuModel).currentMenu != MainMenu {
+		t.Error("Expected Back to return to the main menu")
+	}
+}
+
+func TestPurgeConfirmation(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	systemInstaller, err := installer.NewSystemInstaller()
+	if err != nil {
+		t.Fatalf("Failed to create system installer: %v", err)
+	}
+	configManager := config.NewConfigManager()
+	model := MenuModel{
+		configManager:   configManager,
+		currentMenu:     SystemInstallMenu,
+		menuStack:       []MenuType{MainMenu},
+		systemInstaller: systemInstaller,
+		confirmPurge:    true,
+	}
+	model.choices = model.getMenuChoices()
+	if len(model.choices) != 2 || !strings.Contains(model.choices[0], configManager.GetConfigDir()) {
+		t.Fatalf("Expected the removal of the BOBA directory to be confirmed, got %v", model.choices)
+	}
+	
+	// Cancel goes back to the installation options without uninstalling
+	model.cursor = 1
+	updated, cmd := model.handleSystemInstallMenuSelection()
+	model = updated.(MenuModel)
+	if cmd != nil || model.confirmPurge || model.currentMenu != SystemInstallMenu || model.isLoading {
+		t.Errorf("Expected Cancel to keep BOBA installed, got menu %v", model.currentMenu)
+	}
+	
+	// Once all BOBA data is removed nothing is saved any more, and any key quits
+	model.purging = true
+	configManager.DisablePersistence()
+	updated, _ = model.Update(SystemInstallationCompleteMsg{Result: &installer.SystemInstallationResult{Success: true, Message: "BOBA and its data were removed."}})
+	model = updated.(MenuModel)
+	if !model.dataRemoved || !configManager.PersistenceDisabled() {
+		t.Fatal("Expected persistence to stay disabled after the removal")
+	}
+	if choices := model.getMenuChoices(); choices[len(choices)-1] != "Press any key to quit BOBA" {
+		t.Errorf("Expected the result to offer quitting only, got %v", choices)
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Error("Expected a key to quit BOBA")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected a key to quit BOBA")
+	}
+	
+	// A failed removal lets BOBA save again
+	model.dataRemoved = false
+	model.purging = true
+	updated, _ = model.Update(SystemInstallationCompleteMsg{Result: &installer.SystemInstallationResult{Success: false}})
+	if updated.(MenuModel).dataRemoved || configManager.PersistenceDisabled() {
+		t.Error("Expected persistence to resume after a failed removal")
+	}
+}
+
+func TestCheckTerminal(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+	
+	err = checkTerminal(reader, writer)
+	if !errors.Is(err, ErrNotTerminal) || !strings.Contains(err.Error(), "stdin is redirected") || !strings.Contains(err.Error(), "boba install") {
+		t.Errorf("Expected a piped stdin to be refused with the commands to use instead, got %v", err)
+	}
+	
+	// A terminal stdin with stdout piped, e.g. boba | less, is refused too
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		if err := checkTerminal(tty, writer); !errors.Is(err, ErrNotTerminal) || !strings.Contains(err.Error(), "stdout") {
+			t.Errorf("Expected a piped stdout to be refused, got %v", err)
+		}
+	}
+}
+
+func TestShellDefinitionsMenu(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("alias mine=true\n# >>> boba environment zsh >>>\nalias ll='ls -la'\nexport PATH=\"$HOME/bin:$PATH\"\nexport EDITOR=vim\n# <<< boba environment zsh <<<\n"), 0644)
+	
+	model := MenuModel{
+		configManager:         configManager,
+		localRepo:             github.NewLocalRepository(t.TempDir()),
+		currentMenu:           EnvironmentMenu,
+		menuStack:             []MenuType{MainMenu},
+		availableEnvironments: []parser.Environment{{Name: "zsh"}},
+	}
+	model.choices = model.getMenuChoices()
+	model.cursor = len(model.choices) - 2
+	if model.choices[model.cursor] != shellDefinitionsChoice {
+		t.Fatalf("Expected the shell definitions in the environments list, got %v", model.choices)
+	}
+	updated, _ := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	title := model.getMenuTitle()
+	if model.currentMenu != ShellDefinitionsMenu {
+		t.Fatalf("Expected the shell definitions screen, got menu %v", model.currentMenu)
+	}
+	for _, want := range []string{"Aliases:", "ll = ls -la", "PATH entries:", "$HOME/bin", "EDITOR = vim", "environment zsh · ~/.zshrc:5"} {
+		if !strings.Contains(title, want) {
+			t.Errorf("Expected %q, got:\n%s", want, title)
+		}
+	}
+	if strings.Contains(title, "mine") {
+		t.Errorf("Expected the user's own alias to be left out, got:\n%s", title)
+	}
+	
+	// Refreshing reads the files again
+	os.Remove(filepath.Join(home, ".zshrc"))
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if !strings.Contains(model.getMenuTitle(), "Nothing yet") {
+		t.Errorf("Expected no definitions after refreshing, got:\n%s", model.getMenuTitle())
+	}
+	model.cursor = 1
+	updated, _ = model.handleMenuSelection()
+	if model = updated.(MenuModel); model.currentMenu != EnvironmentMenu {
+		t.Errorf("Expected to go back to the environments, got menu %v", model.currentMenu)
+	}
+}
+
+func TestCleanupMenu(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	binary := filepath.Join(home, "bin", "rg")
+	os.MkdirAll(filepath.Dir(binary), 0755)
+	os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755)
+	configManager.RecordToolInstallationWithProvenance("rg", "1.0", "script", &config.ToolProvenance{BinaryPaths: []string{binary}})
+	
+	model := MenuModel{
+		configManager: configManager,
+		currentMenu:   ConfigurationMenu,
+		menuStack:     []MenuType{MainMenu},
+	}
+	model.choices = model.getMenuChoices()
+	model.cursor = len(model.choices) - 3
+	if model.choices[model.cursor] != cleanupChoice {
+		t.Fatalf("Expected the cleanup suggestions in Installation Configuration, got %v", model.choices)
+	}
+	updated, _ := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if model.currentMenu != CleanupMenu || !strings.Contains(model.getMenuTitle(), "Usage tracking is off") {
+		t.Fatalf("Expected the cleanup screen with tracking off, got menu %v:\n%s", model.currentMenu, model.getMenuTitle())
+	}
+	
+	// Turning tracking on writes the shims and puts them on PATH
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if !configManager.UsageTrackingEnabled() || !strings.Contains(model.getMenuTitle(), "Usage tracking turned on") {
+		t.Fatalf("Expected usage tracking on, got:\n%s", model.getMenuTitle())
+	}
+	if _, err := os.Stat(filepath.Join(configManager.GetShimsDir(), "rg")); err != nil {
+		t.Errorf("Expected a shim for rg: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".profile")); !strings.Contains(string(content), "boba usage tracking") {
+		t.Errorf("Expected the shims on PATH in ~/.profile, got %q", content)
+	}
+	if len(model.choices) != 2 {
+		t.Errorf("Expected no suggestions right after tracking started, got %v", model.choices)
+	}
+	
+	// An unused tool is suggested, and uninstalling it asks first
+	line := unusedToolLine(config.UnusedTool{Name: "rg", Idle: config.UnusedAfter})
+	if line != "🗑️ rg · not used in the 90 days tracked" {
+		t.Errorf("Unexpected suggestion: %s", line)
+	}
+	model.pendingCleanup = "rg"
+	model.choices = model.getMenuChoices()
+	model.cursor = 1
+	if !strings.Contains(model.getMenuTitle(), "Uninstall rg?") {
+		t.Errorf("Expected a confirmation, got:\n%s", model.getMenuTitle())
+	}
+	updated, _ = model.handleMenuSelection()
+	if model = updated.(MenuModel); model.pendingCleanup != "" || model.isLoading {
+		t.Error("Expected cancelling to uninstall nothing")
+	}
+	
+	// Turning tracking off removes the shims
+	model.cursor = len(model.choices) - 2
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if configManager.UsageTrackingEnabled() {
+		t.Error("Expected usage tracking off")
+	}
+	if _, err := os.Stat(configManager.GetShimsDir()); err == nil {
+		t.Error("Expected the shims to be removed")
+	}
+	model.cursor = len(model.choices) - 1
+	updated, _ = model.handleMenuSelection()
+	if model = updated.(MenuModel); model.currentMenu != ConfigurationMenu {
+		t.Errorf("Expected to go back to Installation Configuration, got menu %v", model.currentMenu)
+	}
+}
+
+func TestMaintenanceNotice(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	model := MenuModel{configManager: configManager, currentMenu: MainMenu}
+	
+	cmd := model.startMaintenance()
+	if cmd == nil {
+		t.Fatal("Expected maintenance to start before its first run")
+	}
+	msg, ok := cmd().(MaintenanceMsg)
+	if !ok {
+		t.Fatalf("Expected a maintenance summary, got %T", msg)
+	}
+	updated, _ := model.Update(msg)
+	model = updated.(MenuModel)
+	if title := model.getMenuTitle(); !strings.HasPrefix(title, "🧹 Maintenance: ") {
+		t.Errorf("Expected the summary on the main menu, got:\n%s", title)
+	}
+	if model.startMaintenance() != nil {
+		t.Error("Expected no maintenance until the next week")
+	}
+}
+
+func TestOperationQueueMenu(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	end, err := configManager.BeginOperation(context.Background(), "install rg")
+	if err != nil {
+		t.Fatalf("Failed to begin an operation: %v", err)
+	}
+	defer end()
+	
+	// Another operation of this process waits behind it
+	waited := make(chan error, 1)
+	go func() {
+		_, err := configManager.BeginOperation(context.Background(), "apply zsh")
+		waited <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); len(configManager.QueuedOperations()) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the second operation to be queued")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	
+	model := MenuModel{
+		configManager: configManager,
+		currentMenu:   ConfigurationMenu,
+		menuStack:     []MenuType{MainMenu},
+	}
+	model.choices = model.getMenuChoices()
+	model.cursor = len(model.choices) - 2
+	if model.choices[model.cursor] != operationQueueChoice {
+		t.Fatalf("Expected the operation queue in Installation Configuration, got %v", model.choices)
+	}
+	updated, _ := model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if title := model.getMenuTitle(); !strings.Contains(title, "▶️ Running since") || !strings.Contains(title, "install rg") || !strings.Contains(title, "1 waiting") {
+		t.Errorf("Expected the running and queued operations, got:\n%s", title)
+	}
+	if len(model.choices) != 3 || !strings.Contains(model.choices[0], "apply zsh") {
+		t.Fatalf("Expected the queued operation to be listed, got %v", model.choices)
+	}
+	
+	// Selecting it cancels it
+	updated, _ = model.handleMenuSelection()
+	model = updated.(MenuModel)
+	if err := <-waited; !errors.Is(err, config.ErrOperationCanceled) {
+		t.Errorf("Expected the queued operation to be canceled, got %v", err)
+	}
+	if !strings.Contains(model.getMenuTitle(), "apply zsh canceled") || len(model.choices) != 2 {
+		t.Errorf("Expected the cancellation to be shown, got %v:\n%s", model.choices, model.getMenuTitle())
+	}
+	model.cursor = 1
+	updated, _ = model.handleMenuSelection()
+	if model = updated.(MenuModel); model.currentMenu != ConfigurationMenu {
+		t.Errorf("Expected to go back to Installation Configuration, got menu %v", model.currentMenu)
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// operationQueueChoice opens the operation queue from Installation Configuration
+const operationQueueChoice = "⏳ Operation Queue"
+
+// operationLine describes an operation and the process running it
+func operationLine(op config.Operation) string {
+	return fmt.Sprintf("%s · %s (pid %d)", op.Name, op.Command, op.PID)
+}
+
+// waitingLine tells that this process waits for an operation of another one, or returns ""
+func (m MenuModel) waitingLine() string {
+	if m.configManager == nil {
+		return ""
+	}
+	running := m.configManager.RunningOperation()
+	if running == nil || running.PID == os.Getpid() {
+		return ""
+	}
+	return fmt.Sprintf("⏳ Waiting for %s to finish, see Installation Configuration → %s", operationLine(*running), operationQueueChoice)
+}
+
+// showOperationQueue reads the operation queue and shows it
+func (m MenuModel) showOperationQueue() (tea.Model, tea.Cmd) {
+	m.loadOperationQueue()
+	m.navigateToMenu(OperationQueueMenu)
+	return m, nil
+}
+
+// loadOperationQueue reads the running and queued operations, which the screen shows until the
+// next refresh so that a selection cancels the operation it shows
+func (m *MenuModel) loadOperationQueue() {
+	m.runningOperation = m.configManager.RunningOperation()
+	m.queuedOperations = m.configManager.QueuedOperations()
+}
+
+// getOperationQueueTitle shows the operation running on this machine and the ones waiting
+func (m MenuModel) getOperationQueueTitle() string {
+	lines := []string{
+		"⏳ Operation Queue",
+		"   Installs, uninstalls and environments run one at a time, from the UI, the commands and automatic runs alike.",
+		"",
+	}
+	if running := m.runningOperation; running == nil {
+		lines = append(lines, "   Nothing running.")
+	} else {
+		lines = append(lines, fmt.Sprintf("   ▶️ Running since %s: %s", running.StartedAt.Format("15:04:05"), operationLine(*running)))
+	}
+	if len(m.queuedOperations) > 0 {
+		lines = append(lines, fmt.Sprintf("   %d waiting, select one to cancel it:", len(m.queuedOperations)))
+	}
+	if m.operationQueueStatus != "" {
+		lines = append(lines, "", m.operationQueueStatus)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getOperationQueueChoices lists the queued operations first in line first
+func (m MenuModel) getOperationQueueChoices() []string {
+	var choices []string
+	for i, op := range m.queuedOperations {
+		choices = append(choices, fmt.Sprintf("✖️ %d. %s", i+1, operationLine(op)))
+	}
+	return append(choices, "🔄 Refresh", "← Back")
+}
+
+// handleOperationQueueSelection cancels the selected operation, reads the queue again or goes back
+func (m MenuModel) handleOperationQueueSelection() (tea.Model, tea.Cmd) {
+	switch {
+	case m.cursor == len(m.queuedOperations)+1:
+		m.operationQueueStatus = ""
+		m.navigateBack()
+		return m, nil
+	case m.cursor < len(m.queuedOperations):
+		op := m.queuedOperations[m.cursor]
+		if err := m.configManager.CancelOperation(op.ID); err != nil {
+			m.operationQueueStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		} else {
+			m.operationQueueStatus = fmt.Sprintf("✖️ %s canceled.", op.Name)
+		}
+	default:
+		m.operationQueueStatus = ""
+	}
+	m.loadOperationQueue()
+	m.choices = m.getMenuChoices()
+	m.cursor = 0
+	return m, nil
+}
@@ -0,0 +1,246 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paletteMaxResults limits the matches shown in the command palette
+const paletteMaxResults = 10
+
+// paletteAction is a command palette entry
+type paletteAction struct {
+	Title string
+	Run   func(m MenuModel) (tea.Model, tea.Cmd)
+}
+
+// goToMenu opens a menu as if navigated to from the main menu through the given menus
+func (m MenuModel) goToMenu(path ...MenuType) MenuModel {
+	if m.configManager != nil {
+		m.configManager.Flush()
+	}
+	m.currentMenu = MainMenu
+	m.menuStack = nil
+	for _, menu := range path {
+		m.navigateToMenu(menu)
+	}
+	return m
+}
+
+// goToMenuAndFetch opens a menu whose content is fetched from the repository
+func goToMenuAndFetch(fetch func(MenuModel) (tea.Model, tea.Cmd), path ...MenuType) func(MenuModel) (tea.Model, tea.Cmd) {
+	return func(m MenuModel) (tea.Model, tea.Cmd) {
+		m = m.goToMenu(path...)
+		if !m.isGitHubAuthenticated() {
+			return m, nil
+		}
+		return fetch(m)
+	}
+}
+
+// goToMenuAction opens a menu without fetching anything
+func goToMenuAction(path ...MenuType) func(MenuModel) (tea.Model, tea.Cmd) {
+	return func(m MenuModel) (tea.Model, tea.Cmd) {
+		return m.goToMenu(path...), nil
+	}
+}
+
+// paletteActions lists every action of the palette: menus, settings screens and the loaded tools
+// and environments
+func (m MenuModel) paletteActions() []paletteAction {
+	actions := []paletteAction{
+		{"Install Everything", goToMenuAction(InstallEverythingMenu)},
+		{"Open tools list", goToMenuAndFetch(MenuModel.fetchAndDisplayTools, ToolsListMenu)},
+		{"Open environments", goToMenuAndFetch(MenuModel.fetchAndDisplayEnvironments, EnvironmentMenu)},
+		{"Open settings", goToMenuAction(ConfigurationMenu)},
+		{"Settings: Repository Configuration", goToMenuAction(ConfigurationMenu, RepositoryConfigMenu)},
+		{"Settings: Tool Override Management", goToMenuAndFetch(MenuModel.fetchAndDisplayTools, ConfigurationMenu, ToolOverrideMenu)},
+		{"Settings: Environment Override Management", goToMenuAndFetch(MenuModel.fetchAndDisplayEnvironments, ConfigurationMenu, EnvironmentOverrideMenu)},
+		{"Settings: BOBA Updates", func(m MenuModel) (tea.Model, tea.Cmd) {
+			m = m.goToMenu(ConfigurationMenu, SelfUpdateMenu)
+			m.isLoading = true
+			m.loadingMessage = "Checking for BOBA updates..."
+			return m, m.checkForBobaUpdate()
+		}},
+		{"Settings: Skip List Management", goToMenuAction(ConfigurationMenu, SkipListMenu)},
+		{"Browse community tools", func(m MenuModel) (tea.Model, tea.Cmd) {
+			return m.goToMenu(ConfigurationMenu).browseCommunityTools()
+		}},
+		{"Install BOBA to System", goToMenuAction(SystemInstallMenu)},
+	}
+	
+	if m.hasLastRun() {
+		actions = append(actions, paletteAction{"View last run results", MenuModel.showLastRun})
+	}
+	if m.advisoriesChoice() != "" {
+		actions = append(actions, paletteAction{"View security advisories", goToMenuAction(AdvisoriesMenu)})
+	}
+	if !m.isGitHubAuthenticated() {
+		actions = append(actions, paletteAction{"Authenticate with GitHub", MenuModel.startAuthentication})
+	}
+	
+	for _, tool := range m.availableTools {
+		tool := tool
+		actions = append(actions, paletteAction{fmt.Sprintf("Install %s", tool.Name), func(m MenuModel) (tea.Model, tea.Cmd) {
+			return m.goToMenu(ToolsListMenu).requestToolInstall(tool)
+		}})
+	}
+	for _, env := range m.availableEnvironments {
+		env := env
+		actions = append(actions, paletteAction{fmt.Sprintf("Apply environment %s", env.Name), func(m MenuModel) (tea.Model, tea.Cmd) {
+			return m.goToMenu(EnvironmentMenu).previewEnvironment(env)
+		}})
+	}
+	
+	actions = append(actions, paletteAction{"Quit BOBA", func(m MenuModel) (tea.Model, tea.Cmd) {
+		return m, tea.Quit
+	}})
+	return actions
+}
+
+// fuzzyScore reports whether every character of the query appears in order in the text, scoring
+// consecutive characters and characters at the start of words higher
+func fuzzyScore(query, text string) (int, bool) {
+	queryRunes := []rune(strings.ToLower(query))
+	textRunes := []rune(strings.ToLower(text))
+	
+	score, q, last := 0, 0, -2
+	for i, r := range textRunes {
+		if q == len(queryRunes) {
+			break
+		}
+		if unicode.IsSpace(queryRunes[q]) {
+			q++
+			continue
+		}
+		if r != queryRunes[q] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			score += 3
+		}
+		if i == 0 || !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]) {
+			score += 2
+		}
+		last = i
+		q++
+	}
+	for q < len(queryRunes) && unicode.IsSpace(queryRunes[q]) {
+		q++
+	}
+	return score, q == len(queryRunes)
+}
+
+// paletteMatches returns the actions matching the query, best matches first
+func (m MenuModel) paletteMatches() []paletteAction {
+	actions := m.paletteActions()
+	if strings.TrimSpace(m.paletteQuery) == "" {
+		return actions
+	}
+	
+	type match struct {
+		action paletteAction
+		score  int
+	}
+	var matches []match
+	for _, action := range actions {
+		if score, ok := fuzzyScore(m.paletteQuery, action.Title); ok {
+			matches = append(matches, match{action, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	
+	result := make([]paletteAction, len(matches))
+	for i, match := range matches {
+		result[i] = match.action
+	}
+	return result
+}
+
+// openPalette shows the command palette over the current screen
+func (m MenuModel) openPalette() (tea.Model, tea.Cmd) {
+	m.paletteOpen = true
+	m.paletteQuery = ""
+	m.paletteCursor = 0
+	return m, nil
+}
+
+// handlePaletteKey edits the query, moves the selection and runs the selected action
+func (m MenuModel) handlePaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc, tea.KeyCtrlP:
+		m.paletteOpen = false
+		return m, nil
+	case tea.KeyEnter:
+		matches := m.paletteMatches()
+		m.paletteOpen = false
+		if m.paletteCursor < len(matches) {
+			return matches[m.paletteCursor].Run(m)
+		}
+		return m, nil
+	case tea.KeyUp, tea.KeyCtrlK:
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+	case tea.KeyDown, tea.KeyCtrlJ, tea.KeyTab:
+		if m.paletteCursor < min(len(m.paletteMatches()), paletteMaxResults)-1 {
+			m.paletteCursor++
+		}
+	case tea.KeyBackspace:
+		if query := []rune(m.paletteQuery); len(query) > 0 {
+			m.paletteQuery = string(query[:len(query)-1])
+			m.paletteCursor = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.paletteQuery += string(msg.Runes)
+		if msg.Type == tea.KeySpace {
+			m.paletteQuery += " "
+		}
+		m.paletteCursor = 0
+	}
+	return m, nil
+}
+
+// renderPalette draws the command palette: the query and the best matches
+func (m MenuModel) renderPalette() string {
+	var s strings.Builder
+	s.WriteString(m.renderHeader())
+	s.WriteString("\n")
+	s.WriteString(titleStyle.Render("🔎 Command Palette"))
+	s.WriteString("\n")
+	
+	queryStyle := lipgloss.NewStyle().Foreground(primaryColor).Bold(true)
+	s.WriteString(queryStyle.Render("> " + m.paletteQuery + "▌"))
+	s.WriteString("\n\n")
+	
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		s.WriteString(helpStyle.Render("No matching actions"))
+		s.WriteString("\n")
+	}
+	for i, action := range matches {
+		if i == paletteMaxResults {
+			s.WriteString(helpStyle.Render(fmt.Sprintf("  … %d more, keep typing to narrow down", len(matches)-paletteMaxResults)))
+			s.WriteString("\n")
+			break
+		}
+		if i == m.paletteCursor {
+			s.WriteString(selectedMenuItemStyle.Render("▶ " + action.Title))
+		} else {
+			s.WriteString(menuItemStyle.Render("  " + action.Title))
+		}
+		s.WriteString("\n")
+	}
+	
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("Type to search • Navigate: ↑/↓ or Tab • Run: Enter • Close: Esc or Ctrl+P"))
+	return baseStyle.Render(s.String())
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/config"
+)
+
+// PlanUnchangedMsg is sent instead of starting Install Everything when the resolved plan is the
+// plan of the last successful run
+type PlanUnchangedMsg struct {
+	Phase InstallEverythingPhaseMsg // Run to start if the user runs the plan anyway
+	Last  config.ExecutionPlan
+}
+
+// comparePlan builds the execution plan of a resolved run and compares it with the last
+// successful one. It returns a PlanUnchangedMsg when nothing changed, the phase message with
+// the plan and its changes attached otherwise. Without a plan the run starts as before.
+func (m MenuModel) comparePlan(phase InstallEverythingPhaseMsg) tea.Msg {
+	if m.configManager == nil {
+		return phase
+	}
+	plan, err := m.installEngine.BuildPlan(phase.Tools, phase.Environments)
+	if err != nil {
+		return phase
+	}
+	phase.Plan = &plan
+	
+	last, ok, err := m.configManager.LoadLastPlan()
+	if err != nil || !ok {
+		return phase
+	}
+	if last.Hash() == plan.Hash() {
+		return PlanUnchangedMsg{Phase: phase, Last: last}
+	}
+	phase.PlanChanges = plan.Changes(last)
+	return phase
+}
+
+// planChangesResult reports the plan changes on the results screen
+func planChangesResult(changes []config.PlanChange) InstallationResult {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+	return InstallationResult{
+		ToolName: "Plan changes since the last successful run",
+		Success:  true,
+		Message:  strings.Join(lines, "\n"),
+	}
+}
+
+// planSucceeded reports whether every operation of the plan has a successful result
+func planSucceeded(plan config.ExecutionPlan, results []InstallationResult) bool {
+	succeeded := make(map[string]bool, len(results))
+	for _, result := range results {
+		if result.Success {
+			succeeded[result.ToolName] = true
+		}
+	}
+	for _, entry := range plan.Entries {
+		if !succeeded[entry.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// saveRunPlan records the plan of the finished run when all of it succeeded
+func (m *MenuModel) saveRunPlan(results []InstallationResult) {
+	if m.runPlan != nil && m.configManager != nil && !m.dryRun() && planSucceeded(*m.runPlan, results) {
+		m.configManager.SaveLastPlan(*m.runPlan)
+	}
+	m.runPlan = nil
+}
+
+// handlePlanUnchangedMsg asks whether to run an unchanged plan again
+func (m MenuModel) handlePlanUnchangedMsg(msg PlanUnchangedMsg) (tea.Model, tea.Cmd) {
+	m.installationInProgress = false
+	m.loadingMessage = ""
+	m.planUnchanged = &msg
+	m.navigateToMenu(PlanUnchangedMenu)
+	return m, nil
+}
+
+// getPlanUnchangedTitle describes the unchanged plan
+func (m MenuModel) getPlanUnchangedTitle() string {
+	if m.planUnchanged == nil {
+		return "✅ Plan Unchanged"
+	}
+	last := m.planUnchanged.Last
+	
+	var s strings.Builder
+	s.WriteString("✅ Plan unchanged since the last successful run\n")
+	s.WriteString(fmt.Sprintf("   %d operations, plan %s", len(last.Entries), last.Hash()[:12]))
+	if !last.SavedAt.IsZero() {
+		s.WriteString(fmt.Sprintf(", run %s", last.SavedAt.Format("2006-01-02 15:04")))
+	}
+	s.WriteString("\n   Tools, versions, scripts and order are the same: running again should change nothing.")
+	return s.String()
+}
+
+func (m MenuModel) getPlanUnchangedChoices() []string {
+	return []string{
+		"⏭️ Skip, nothing to do",
+		"🔁 Run anyway",
+		"← Back to Install Everything",
+	}
+}
+
+// handlePlanUnchangedSelection skips the run or starts it anyway
+func (m MenuModel) handlePlanUnchangedSelection() (tea.Model, tea.Cmd) {
+	pending := m.planUnchanged
+	runAnyway := m.cursor == 1
+	m.planUnchanged = nil
+	m.navigateBack()
+	if !runAnyway || pending == nil {
+		m.planNotice = "✅ Nothing to do: the plan is unchanged since the last successful run"
+		return m, nil
+	}
+	
+	m.installationInProgress = true
+	m.loadingMessage = "Installing tools..."
+	phase := pending.Phase
+	return m, func() tea.Msg { return phase }
+}
+
+// exportPlanChoice writes the plan of the next Install Everything run to a file for review
+const exportPlanChoice = "📤 Export Plan for Review"
+
+// planExportFile is where the exported plan is written, in the working directory
+const planExportFile = "boba-plan.yaml"
+
+// PlanExportedMsg reports the outcome of exporting the plan of the next run
+type PlanExportedMsg struct {
+	Path    string
+	Entries int
+	Err     error
+}
+
+// exportPlan resolves the next Install Everything run like starting it would, without running
+// it, and writes its plan to planExportFile
+func (m MenuModel) exportPlan() (tea.Model, tea.Cmd) {
+	if m.repoParser == nil || m.installEngine == nil {
+		return m, func() tea.Msg {
+			return "error_installation: Installation engine not initialized"
+		}
+	}
+	
+	m.planNotice = "Resolving the plan..."
+	return m, func() tea.Msg {
+		path, err := filepath.Abs(planExportFile)
+		if err != nil {
+			return PlanExportedMsg{Err: err}
+		}
+		phase, err := m.resolveInstallEverything()
+		if err != nil {
+			return PlanExportedMsg{Err: err}
+		}
+		plan, err := m.installEngine.BuildPlan(phase.Tools, phase.Environments)
+		if err != nil {
+			return PlanExportedMsg{Err: err}
+		}
+		if len(plan.Entries) == 0 {
+			return PlanExportedMsg{Err: fmt.Errorf("nothing to install: the plan is empty")}
+		}
+		if err := config.WritePlanFile(path, plan); err != nil {
+			return PlanExportedMsg{Err: err}
+		}
+		return PlanExportedMsg{Path: path, Entries: len(plan.Entries)}
+	}
+}
+
+// handlePlanExportedMsg tells where the plan was written and how to run it
+func (m MenuModel) handlePlanExportedMsg(msg PlanExportedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.planNotice = fmt.Sprintf("❌ Plan export failed: %v", msg.Err)
+	} else if m.installEngine != nil && m.installEngine.ApprovalRequired() {
+		m.planNotice = fmt.Sprintf("📤 Plan of %d operations written to %s\n   Have an admin approve it with boba plan approve, then run it with: boba apply %s", msg.Entries, msg.Path, msg.Path)
+	} else {
+		m.planNotice = fmt.Sprintf("📤 Plan of %d operations written to %s\n   Review it, then run it as reviewed with: boba apply %s", msg.Entries, msg.Path, msg.Path)
+	}
+	return m, nil
+}
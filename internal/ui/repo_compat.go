@@ -0,0 +1,32 @@
+package ui
+
+import "fmt"
+
+// checkRepositoryCompatibility validates the requirements the repository declares in boba.yaml
+// on connect. An incompatible repository is reported like an authentication problem, so nothing
+// is installed from it; a compatible one gets a badge on the main menu.
+func checkRepositoryCompatibility(model MenuModel) MenuModel {
+	model.repoBadge = ""
+	if model.repoParser == nil || model.installEngine == nil || model.authError != "" {
+		return model
+	}
+	
+	// A boba.yaml that can't be read is reported when the tools are loaded
+	info, err := model.repoParser.RepositoryInfo()
+	if err != nil || info == nil {
+		return model
+	}
+	
+	if err := model.installEngine.CheckRepository(info); err != nil {
+		model.authError = fmt.Sprintf("Incompatible repository: %v", err)
+		if info.Contact != "" {
+			model.authError += "\nContact the maintainers: " + info.Contact
+		}
+		return model
+	}
+	model.repoBadge = "✅ Repository compatible with this machine"
+	if info.Contact != "" {
+		model.repoBadge += fmt.Sprintf(" (maintainers: %s)", info.Contact)
+	}
+	return model
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/github"
+	"boba/internal/parser"
+)
+
+// RepoTrustInfoMsg carries what the user needs to decide whether to trust a repository
+type RepoTrustInfoMsg struct {
+	Client  github.RepositoryBackend
+	Info    *github.RepositoryInfo
+	Scripts int
+	Err     error
+}
+
+// isRepositoryTrusted reports whether the current repository has been confirmed as trusted.
+// Until it is, every tool and environment defaults to manual install (quarantine).
+func (m MenuModel) isRepositoryTrusted() bool {
+	backend := m.repositoryBackend()
+	if backend == nil || m.configManager == nil {
+		return true
+	}
+	return m.configManager.IsRepositoryTrusted(backend.GetFullRepoName())
+}
+
+// repositoryBackend returns the client of the hosted repository, or nil in local mode and
+// before authentication
+func (m MenuModel) repositoryBackend() github.RepositoryBackend {
+	if m.hostedClient != nil {
+		return m.hostedClient
+	}
+	if m.githubClient != nil {
+		return m.githubClient
+	}
+	return nil
+}
+
+// reviewRepositoryTrust fetches the owner, visibility and script count of a repository for the trust confirmation
+func (m MenuModel) reviewRepositoryTrust(client github.RepositoryBackend) tea.Cmd {
+	return func() tea.Msg {
+		info, err := client.GetRepositoryInfo()
+		if err != nil {
+			return RepoTrustInfoMsg{Client: client, Err: err}
+		}
+		scripts, err := parser.NewRepositoryParserFromSource(client).CountScripts()
+		return RepoTrustInfoMsg{Client: client, Info: info, Scripts: scripts, Err: err}
+	}
+}
+
+// handleRepoTrustInfoMsg shows the trust confirmation for a repository that is not trusted yet
+func (m MenuModel) handleRepoTrustInfoMsg(msg RepoTrustInfoMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	
+	if msg.Err != nil {
+		m.installationResults = []InstallationResult{{
+			ToolName: "Repository trust",
+			Success:  false,
+			Message:  fmt.Sprintf("Could not inspect the repository, it stays quarantined: %v", msg.Err),
+			Error:    msg.Err,
+		}}
+		m.showingResults = true
+		return m, nil
+	}
+	
+	m.repoTrustInfo = &msg
+	if m.currentMenu != RepoTrustMenu {
+		m.navigateToMenu(RepoTrustMenu)
+	}
+	return m, nil
+}
+
+func (m MenuModel) getRepoTrustChoices() []string {
+	return []string{
+		"✅ Trust this repository",
+		"← Keep quarantined (manual install only)",
+	}
+}
+
+// getRepoTrustTitle lists the repository details shown before trusting it
+func (m MenuModel) getRepoTrustTitle() string {
+	if m.repoTrustInfo == nil || m.repoTrustInfo.Info == nil {
+		return "🛡️ Repository Trust"
+	}
+	
+	info := m.repoTrustInfo.Info
+	var s strings.Builder
+	s.WriteString("🛡️ BOBA has not run scripts from this repository before:\n")
+	s.WriteString(fmt.Sprintf("   Repository: %s\n", info.FullName))
+	owner := info.Owner
+	if info.OwnerType != "" {
+		owner += fmt.Sprintf(" (%s)", strings.ToLower(info.OwnerType))
+	}
+	s.WriteString(fmt.Sprintf("   Owner:      %s\n", owner))
+	visibility := info.Visibility
+	if info.Fork {
+		visibility += ", fork"
+	}
+	s.WriteString(fmt.Sprintf("   Visibility: %s\n", visibility))
+	s.WriteString(fmt.Sprintf("   Scripts:    %d\n", m.repoTrustInfo.Scripts))
+	s.WriteString("Check the name carefully. Until trusted, all tools and environments are manual install only.")
+	return s.String()
+}
+
+func (m MenuModel) handleRepoTrustSelection() (tea.Model, tea.Cmd) {
+	if m.cursor == 0 && m.repoTrustInfo != nil && m.repoTrustInfo.Info != nil {
+		if err := m.configManager.TrustRepository(m.repoTrustInfo.Info.FullName); err != nil {
+			m.loadingMessage = fmt.Sprintf("Error: %v", err)
+			return m, nil
+		}
+	}
+	m.repoTrustInfo = nil
+	m.navigateBack()
+	return m, nil
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// repoWatchInterval is how often the local repository is checked for manifest and script changes
+const repoWatchInterval = 2 * time.Second
+
+// repoWatchPaths are the repository paths whose changes trigger a reload
+var repoWatchPaths = []string{"tools", "environments", "boba.yaml"}
+
+// repoWatchMsg is delivered to Update on every repository check tick
+type repoWatchMsg time.Time
+
+// repoFingerprintMsg carries the fingerprint of the watched repository
+type repoFingerprintMsg struct {
+	Dir         string
+	Fingerprint uint64
+}
+
+// RepoWatcher remembers the last fingerprint of the watched repository directory
+type RepoWatcher struct {
+	dir         string
+	fingerprint uint64
+}
+
+// watchRepository schedules the next repository check
+func watchRepository() tea.Cmd {
+	return tea.Tick(repoWatchInterval, func(t time.Time) tea.Msg {
+		return repoWatchMsg(t)
+	})
+}
+
+// watchedRepositoryDir returns the directory to watch: the local mode directory or the local clone
+func (m MenuModel) watchedRepositoryDir() string {
+	if m.localRepo != nil {
+		return m.localRepo.Dir()
+	}
+	if m.installEngine != nil {
+		return m.installEngine.GetRepositoryDir()
+	}
+	return ""
+}
+
+// repositoryFingerprint hashes the path, size and modification time of every watched file
+func repositoryFingerprint(dir string) uint64 {
+	hash := fnv.New64a()
+	for _, path := range repoWatchPaths {
+		filepath.WalkDir(filepath.Join(dir, path), func(current string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(hash, "%s|%d|%d\n", current, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return hash.Sum64()
+}
+
+// handleRepoWatchMsg fingerprints the watched repository off the UI loop and reloads the
+// tools and environments lists when manifests or scripts changed
+func (m MenuModel) handleRepoWatchMsg(msg tea.Msg) (MenuModel, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case repoWatchMsg:
+		dir := m.watchedRepositoryDir()
+		if dir == "" || m.repoWatcher == nil {
+			return m, watchRepository(), true
+		}
+		return m, func() tea.Msg {
+			return repoFingerprintMsg{Dir: dir, Fingerprint: repositoryFingerprint(dir)}
+		}, true
+	case repoFingerprintMsg:
+		watcher := m.repoWatcher
+		changed := watcher.dir == msg.Dir && watcher.fingerprint != msg.Fingerprint
+		watcher.dir = msg.Dir
+		watcher.fingerprint = msg.Fingerprint
+		
+		cmds := []tea.Cmd{watchRepository()}
+		if changed {
+			cmds = append(cmds, m.reloadRepositoryLists()...)
+		}
+		return m, tea.Batch(cmds...), true
+	}
+	return m, nil, false
+}
+
+// reloadRepositoryLists refetches the tools and environments lists that are currently loaded
+func (m MenuModel) reloadRepositoryLists() []tea.Cmd {
+	if m.repoParser == nil || m.isLoading || m.installationInProgress {
+		return nil
+	}
+	
+	parser := m.repoParser
+	parser.InvalidateCache()
+	
+	var cmds []tea.Cmd
+	if len(m.availableTools) > 0 {
+		cmds = append(cmds, func() tea.Msg {
+			tools, err := parser.FetchTools()
+			if err != nil {
+				return fmt.Sprintf("error_fetching_tools: %s", describeError(err))
+			}
+			return ToolsListMsg{Tools: tools}
+		})
+	}
+	if len(m.availableEnvironments) > 0 {
+		cmds = append(cmds, func() tea.Msg {
+			environments, err := parser.FetchEnvironments()
+			if err != nil {
+				return fmt.Sprintf("error_fetching_environments: %s", describeError(err))
+			}
+			return EnvironmentsListMsg{Environments: environments}
+		})
+	}
+	return cmds
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// resetScopeLabels names the reset scopes on the reset screen
+var resetScopeLabels = map[config.WipeScope]string{
+	config.WipeCredentials: "🔑 Credentials Only",
+	config.WipeCaches:      "🧹 Caches Only",
+	config.WipeOverrides:   "⚙️ Overrides Only",
+	config.WipeEverything:  "🧨 Everything",
+}
+
+// getResetChoices lists the reset scopes, or the confirmation of the selected one
+func (m MenuModel) getResetChoices() []string {
+	if m.pendingWipe != "" {
+		return []string{fmt.Sprintf("✅ Yes, back up and wipe %s", m.pendingWipe), "❌ Cancel"}
+	}
+	var choices []string
+	for _, scope := range config.WipeScopes {
+		choices = append(choices, fmt.Sprintf("%s - %s", resetScopeLabels[scope], scope.Description()))
+	}
+	return append(choices, "← Back")
+}
+
+// getResetTitle explains the reset screen, with the outcome of the last reset
+func (m MenuModel) getResetTitle() string {
+	title := "🧨 Reset & Wipe\n   The BOBA directory is backed up to a .tar.gz archive before anything is removed."
+	if m.pendingWipe != "" {
+		title = fmt.Sprintf("🧨 Remove %s?\n   A backup archive is written to %s first.", m.pendingWipe.Description(), m.configManager.GetBackupDir())
+	}
+	if m.resetStatus != "" {
+		title += "\n\n" + m.resetStatus
+	}
+	return title
+}
+
+// handleResetSelection selects a reset scope, then wipes it once confirmed
+func (m MenuModel) handleResetSelection() (tea.Model, tea.Cmd) {
+	if m.pendingWipe != "" {
+		scope := m.pendingWipe
+		m.pendingWipe = ""
+		if m.cursor == 0 {
+			m = m.wipe(scope)
+		}
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+		return m, nil
+	}
+	
+	if m.cursor >= len(config.WipeScopes) {
+		m.navigateBack()
+		return m, nil
+	}
+	m.pendingWipe = config.WipeScopes[m.cursor]
+	m.resetStatus = ""
+	m.choices = m.getMenuChoices()
+	m.cursor = 1 // Default to Cancel
+	return m, nil
+}
+
+// wipe backs up the BOBA directory and removes what the scope covers
+func (m MenuModel) wipe(scope config.WipeScope) MenuModel {
+	if m.configManager == nil {
+		return m
+	}
+	backup, err := m.configManager.Wipe(scope)
+	if err != nil {
+		m.resetStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		return m
+	}
+	
+	m.resetStatus = fmt.Sprintf("✅ Removed %s.\n   Backup: %s", scope.Description(), backup)
+	if scope == config.WipeCredentials || scope == config.WipeEverything {
+		// The GitHub client of this session still holds the removed token
+		m.resetStatus += "\n   Restart BOBA to set up again."
+	}
+	return m
+}
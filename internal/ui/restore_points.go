@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+	"boba/internal/log"
+	"boba/internal/parser"
+)
+
+// restorePointsChoice opens the restore points from Installation Configuration
+const restorePointsChoice = "⏪ Restore Points"
+
+// saveRestorePoint saves the managed state before a run that applies the given environments.
+// Dry runs change nothing, so they save none; a failure only skips the restore point.
+func (m MenuModel) saveRestorePoint(name string, environments []parser.Environment) {
+	if m.configManager == nil || m.dryRun() {
+		return
+	}
+	if _, err := m.configManager.CreateRestorePoint(name, parser.HomeFiles(environments)); err != nil {
+		log.Warn("Failed to save a restore point", "name", name, "error", err)
+	}
+}
+
+// restorePoints lists the saved restore points, newest first
+func (m MenuModel) restorePoints() []config.RestorePoint {
+	if m.configManager == nil {
+		return nil
+	}
+	points, _ := m.configManager.ListRestorePoints()
+	return points
+}
+
+// getRestorePointsChoices lists the restore points, or the actions on the selected one
+func (m MenuModel) getRestorePointsChoices() []string {
+	if m.pendingRestorePoint != nil {
+		return []string{"✅ Yes, roll back to this restore point", "🗑️ Delete this restore point", "❌ Cancel"}
+	}
+	var choices []string
+	for _, point := range m.restorePoints() {
+		choices = append(choices, restorePointLine(point))
+	}
+	return append(choices, "← Back")
+}
+
+// restorePointLine describes one restore point
+func restorePointLine(point config.RestorePoint) string {
+	return fmt.Sprintf("%s · %s · %d file(s), %d tool(s)", point.Name, historyDate(point.CreatedAt), savedFileCount(point), len(point.InstalledTools))
+}
+
+// savedFileCount counts the files that existed when the restore point was saved
+func savedFileCount(point config.RestorePoint) int {
+	count := 0
+	for _, file := range point.Files {
+		if file.Existed {
+			count++
+		}
+	}
+	return count
+}
+
+// getRestorePointsTitle explains the restore points screen, with the outcome of the last roll back
+func (m MenuModel) getRestorePointsTitle() string {
+	title := "⏪ Restore Points\n   Saved before Install Everything and environment application: shell rc files, environment config files and installation records."
+	if point := m.pendingRestorePoint; point != nil {
+		var files []string
+		for _, file := range point.Files {
+			if file.Existed {
+				files = append(files, "~/"+file.Path)
+			} else {
+				files = append(files, fmt.Sprintf("~/%s (absent, removed on roll back)", file.Path))
+			}
+		}
+		title = fmt.Sprintf("⏪ Roll back to %q from %s?\n   Files: %s\n   %d tool(s) installed, %d environment(s) applied\n   The current state is saved as a new restore point first. Installed tools are not uninstalled.",
+			point.Name, historyDate(point.CreatedAt), strings.Join(files, ", "), len(point.InstalledTools), len(point.AppliedEnvironments))
+	} else if len(m.restorePoints()) == 0 {
+		title += "\n   No restore points yet."
+	}
+	if m.restoreStatus != "" {
+		title += "\n\n" + m.restoreStatus
+	}
+	return title
+}
+
+// handleRestorePointsSelection selects a restore point, then rolls back to it or deletes it
+func (m MenuModel) handleRestorePointsSelection() (tea.Model, tea.Cmd) {
+	if point := m.pendingRestorePoint; point != nil {
+		m.pendingRestorePoint = nil
+		switch m.cursor {
+		case 0:
+			m = m.rollBack(*point)
+		case 1:
+			if err := m.configManager.DeleteRestorePoint(point.ID); err != nil {
+				m.restoreStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+			} else {
+				m.restoreStatus = fmt.Sprintf("🗑️ Deleted the restore point %q.", point.Name)
+			}
+		}
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+		return m, nil
+	}
+
+	points := m.restorePoints()
+	if m.cursor >= len(points) {
+		m.navigateBack()
+		return m, nil
+	}
+	m.pendingRestorePoint = &points[m.cursor]
+	m.restoreStatus = ""
+	m.choices = m.getMenuChoices()
+	m.cursor = 2 // Default to Cancel
+	return m, nil
+}
+
+// rollBack restores the files and applied environments of a restore point and reports what the
+// roll back could not undo
+func (m MenuModel) rollBack(point config.RestorePoint) MenuModel {
+	result, err := m.configManager.RollBack(point.ID)
+	if err != nil {
+		m.restoreStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		return m
+	}
+
+	lines := []string{fmt.Sprintf("✅ Rolled back to %q.", point.Name)}
+	if len(result.Restored) > 0 {
+		lines = append(lines, "   Restored: "+strings.Join(result.Restored, ", "))
+	}
+	if len(result.Removed) > 0 {
+		lines = append(lines, "   Removed: "+strings.Join(result.Removed, ", "))
+	}
+	if len(result.Restored)+len(result.Removed) == 0 {
+		lines = append(lines, "   No file changed since the restore point.")
+	}
+	if len(result.InstalledSince) > 0 {
+		lines = append(lines, "   Still installed, uninstall them from the tools list: "+strings.Join(result.InstalledSince, ", "))
+	}
+	if len(result.UninstalledSince) > 0 {
+		lines = append(lines, "   Uninstalled since, install them again from the tools list: "+strings.Join(result.UninstalledSince, ", "))
+	}
+	lines = append(lines, fmt.Sprintf("   The previous state was saved as %q.", result.Undo.Name))
+	m.restoreStatus = strings.Join(lines, "\n")
+	return m
+}
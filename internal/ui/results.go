@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"boba/internal/parser"
+)
+
+// Results screen phases, listed in the order they run
+const (
+	ResultPhaseDependencies = "dependencies"
+	ResultPhaseTools        = "tools"
+	ResultPhaseEnvironments = "environments"
+	ResultPhaseHooks        = "hooks"
+)
+
+// resultPhaseOrder is the order of the results screen sections; results without a phase come last
+var resultPhaseOrder = []string{ResultPhaseDependencies, ResultPhaseTools, ResultPhaseEnvironments, ResultPhaseHooks, ""}
+
+// resultPhaseTitles are the results screen section titles
+var resultPhaseTitles = map[string]string{
+	ResultPhaseDependencies: "🔗 Dependencies",
+	ResultPhaseTools:        "🛠️ Tools",
+	ResultPhaseEnvironments: "🌍 Environments",
+	ResultPhaseHooks:        "🪝 Hooks",
+	"":                      "📋 Other",
+}
+
+// resultGroup is one results screen section
+type resultGroup struct {
+	Phase     string
+	Results   []InstallationResult
+	Succeeded int
+}
+
+// toolResultPhase reports a tool as a dependency when another tool of the batch depends on it
+func toolResultPhase(tools []parser.Tool, toolName string) string {
+	for _, tool := range tools {
+		for _, dependency := range tool.Dependencies {
+			if dependency == toolName {
+				return ResultPhaseDependencies
+			}
+		}
+	}
+	return ResultPhaseTools
+}
+
+// groupResults splits the results into sections by phase, optionally listing failures first
+func groupResults(results []InstallationResult, failuresFirst bool) []resultGroup {
+	byPhase := make(map[string][]InstallationResult)
+	for _, result := range results {
+		byPhase[result.Phase] = append(byPhase[result.Phase], result)
+	}
+	
+	var groups []resultGroup
+	for _, phase := range resultPhaseOrder {
+		phaseResults, ok := byPhase[phase]
+		if !ok {
+			continue
+		}
+		if failuresFirst {
+			sort.SliceStable(phaseResults, func(i, j int) bool {
+				return !phaseResults[i].Success && phaseResults[j].Success
+			})
+		}
+		
+		group := resultGroup{Phase: phase, Results: phaseResults}
+		for _, result := range phaseResults {
+			if result.Success {
+				group.Succeeded++
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// isResultGroupCollapsed reports whether a section shows only its header; fully successful
+// sections start collapsed when there is more than one section
+func (m MenuModel) isResultGroupCollapsed(group resultGroup, groupCount int) bool {
+	if collapsed, ok := m.resultsCollapsed[group.Phase]; ok {
+		return collapsed
+	}
+	return groupCount > 1 && group.Succeeded == len(group.Results)
+}
+
+// handleResultsKey toggles sections with the number keys and the failures-first sort with "f";
+// any other key returns to the menu
+func (m MenuModel) handleResultsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	groups := groupResults(m.installationResults, m.resultsFailuresFirst)
+	key := msg.String()
+	
+	if key == "f" && len(m.installationResults) > 1 {
+		m.resultsFailuresFirst = !m.resultsFailuresFirst
+		return m, nil
+	}
+	if len(groups) > 1 && len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+		index := int(key[0] - '1')
+		if index < len(groups) {
+			collapsed := make(map[string]bool, len(m.resultsCollapsed)+1)
+			for phase, value := range m.resultsCollapsed {
+				collapsed[phase] = value
+			}
+			collapsed[groups[index].Phase] = !m.isResultGroupCollapsed(groups[index], len(groups))
+			m.resultsCollapsed = collapsed
+			return m, nil
+		}
+	}
+	
+	m.showingResults = false
+	m.installationResults = []InstallationResult{}
+	m.resultsCollapsed = nil
+	m.resultsFailuresFirst = false
+	m.resultsTitle = ""
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
+// renderResults lists the results, in collapsible sections by phase when they span several phases
+func (m MenuModel) renderResults() string {
+	var s strings.Builder
+	groups := groupResults(m.installationResults, m.resultsFailuresFirst)
+	if len(groups) == 1 {
+		for _, result := range groups[0].Results {
+			s.WriteString(renderResultEntry(result))
+		}
+		return s.String()
+	}
+	
+	sectionStyle := lipgloss.NewStyle().Foreground(primaryColor).Bold(true)
+	for i, group := range groups {
+		marker := "▼"
+		collapsed := m.isResultGroupCollapsed(group, len(groups))
+		if collapsed {
+			marker = "▶"
+		}
+		header := fmt.Sprintf("%s [%d] %s (%d/%d succeeded)", marker, i+1, resultPhaseTitles[group.Phase], group.Succeeded, len(group.Results))
+		if group.Succeeded == len(group.Results) {
+			s.WriteString(sectionStyle.Render(header))
+		} else {
+			s.WriteString(errorStyle.Render(header))
+		}
+		s.WriteString("\n\n")
+		
+		if collapsed {
+			continue
+		}
+		for _, result := range group.Results {
+			s.WriteString(renderResultEntry(result))
+		}
+	}
+	return s.String()
+}
+
+// renderResultEntry renders one result with its detailed message
+func renderResultEntry(result InstallationResult) string {
+	var s strings.Builder
+	var resultStyle lipgloss.Style
+	var icon string
+	
+	if result.Success {
+		resultStyle = successStyle
+		icon = "✅"
+	} else {
+		resultStyle = errorStyle
+		icon = "❌"
+	}
+	
+	resultText := fmt.Sprintf("%s %s", icon, result.ToolName)
+	s.WriteString(resultStyle.Render(resultText))
+	s.WriteString("\n")
+	
+	// Show detailed message
+	if result.Message != "" {
+		messageLines := strings.Split(result.Message, "\n")
+		for _, line := range messageLines {
+			if strings.TrimSpace(line) != "" {
+				s.WriteString(fmt.Sprintf("   %s\n", line))
+			}
+		}
+	}
+	s.WriteString("\n")
+	return s.String()
+}
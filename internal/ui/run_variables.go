@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runVariablesChoice opens the script variables of the next Install Everything run
+const runVariablesChoice = "🔧 Start with different script variables..."
+
+// scriptVariables returns the variables of the next run: the configured script_env values
+// overridden by the values entered for this run
+func (m MenuModel) scriptVariables() map[string]string {
+	vars := make(map[string]string)
+	if m.configManager != nil {
+		vars = m.configManager.GetScriptEnv()
+	}
+	for name, value := range m.runVariables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// scriptVariableNames returns the names of the next run's variables, sorted
+func (m MenuModel) scriptVariableNames() []string {
+	vars := m.scriptVariables()
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validVariableName reports whether a name can be used as an environment variable
+func validVariableName(name string) bool {
+	for i, r := range name {
+		if !(r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func (m MenuModel) getRunVariablesChoices() []string {
+	vars := m.scriptVariables()
+	var choices []string
+	for _, name := range m.scriptVariableNames() {
+		choice := fmt.Sprintf("%s = %s", name, vars[name])
+		if _, changed := m.runVariables[name]; changed {
+			choice += "  (this run)"
+		}
+		choices = append(choices, choice)
+	}
+	return append(choices,
+		"➕ Add a variable for this run",
+		"🚀 Start Installation Process",
+		"← Back to Install Everything",
+	)
+}
+
+// getRunVariablesTitle explains the screen and shows the variable being edited
+func (m MenuModel) getRunVariablesTitle() string {
+	title := "🔧 Script Variables for This Run\n" +
+		"   Set in every script of the next Install Everything run. Set permanent values in script_env in config.json."
+	if !m.runVariableEditing {
+		return title
+	}
+	
+	prompt := fmt.Sprintf("Value of %s: ", m.runVariableName)
+	if m.runVariableName == "" {
+		prompt = "New variable (NAME=value): "
+	}
+	title += "\n\n" + selectedMenuItemStyle.Render(prompt+m.runVariableInput+"▌")
+	if m.runVariableError != "" {
+		title += "\n" + errorStyle.Render(m.runVariableError)
+	}
+	return title
+}
+
+// handleRunVariablesSelection edits a variable, adds one, or starts the run with the variables
+func (m MenuModel) handleRunVariablesSelection() (tea.Model, tea.Cmd) {
+	names := m.scriptVariableNames()
+	switch {
+	case m.cursor < len(names):
+		name := names[m.cursor]
+		m.runVariableEditing = true
+		m.runVariableName = name
+		m.runVariableInput = m.scriptVariables()[name]
+	case m.cursor == len(names):
+		m.runVariableEditing = true
+		m.runVariableName = ""
+		m.runVariableInput = ""
+	case m.cursor == len(names)+1:
+		if m.installEngine != nil {
+			m.installEngine.SetRunVariables(m.runVariables)
+		}
+		m.navigateBack()
+		return m.startInstallEverythingWith(false, false)
+	default:
+		m.runVariables = nil
+		m.navigateBack()
+	}
+	m.runVariableError = ""
+	return m, nil
+}
+
+// handleRunVariableKey edits the value typed for a variable
+func (m MenuModel) handleRunVariableKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.runVariableEditing = false
+		m.runVariableError = ""
+	case tea.KeyEnter:
+		name, value := m.runVariableName, m.runVariableInput
+		if name == "" {
+			var found bool
+			name, value, found = strings.Cut(m.runVariableInput, "=")
+			name = strings.TrimSpace(name)
+			if !found || !validVariableName(name) {
+				m.runVariableError = "Enter the variable as NAME=value, with a name made of letters, digits and _"
+				return m, nil
+			}
+		}
+		if m.runVariables == nil {
+			m.runVariables = make(map[string]string)
+		}
+		m.runVariables[name] = value
+		m.runVariableEditing = false
+		m.runVariableError = ""
+		m.choices = m.getMenuChoices()
+	default:
+		m.runVariableInput = editInput(m.runVariableInput, msg)
+	}
+	return m, nil
+}
+
+// clearRunVariables goes back to the configured variables once a run is over
+func (m *MenuModel) clearRunVariables() {
+	if m.runVariables == nil {
+		return
+	}
+	m.runVariables = nil
+	if m.installEngine != nil {
+		m.installEngine.SetRunVariables(nil)
+	}
+}
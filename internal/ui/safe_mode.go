@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+)
+
+// Recovery actions of the safe mode screen
+const (
+	safeModeClearCache  = "🧹 Clear Repository Cache"
+	safeModeResetConfig = "♻️ Reset Configuration (keeps a backup)"
+	safeModeCrashLog    = "📜 View Crash Log"
+	safeModeStart       = "▶️ Start Normally"
+	safeModeContinue    = "🛟 Continue in Safe Mode"
+)
+
+// SafeModeModel creates the model of a safe mode start, after the previous startups crashed
+// during initialization: only local state is loaded, GitHub is not contacted and nothing is
+// fetched until the user starts normally
+func SafeModeModel(crashes int) MenuModel {
+	configManager := config.NewConfigManager()
+	configManager.LoadConfig()
+	configManager.LoadCredentials()
+	
+	return MenuModel{
+		currentMenu:       SafeModeMenu,
+		menuStack:         []MenuType{},
+		configManager:     configManager,
+		selected:          make(map[int]struct{}),
+		toolInstallStatus: make(map[string]bool),
+		watchdog:          NewWatchdog(),
+		repoWatcher:       &RepoWatcher{},
+		safeModeCrashes:   crashes,
+	}
+}
+
+func (m MenuModel) getSafeModeChoices() []string {
+	return []string{safeModeClearCache, safeModeResetConfig, safeModeCrashLog, safeModeStart, safeModeContinue}
+}
+
+// getSafeModeTitle explains why BOBA started in safe mode, with the outcome of the last action
+func (m MenuModel) getSafeModeTitle() string {
+	title := fmt.Sprintf("🛟 Safe mode: BOBA crashed during startup the last %d times.\nGitHub and the repository were not loaded. Try a recovery action, then start normally.", m.safeModeCrashes)
+	if m.safeModeStatus != "" {
+		title += "\n\n" + m.safeModeStatus
+	}
+	return title
+}
+
+// handleSafeModeSelection runs a recovery action or leaves safe mode
+func (m MenuModel) handleSafeModeSelection() (tea.Model, tea.Cmd) {
+	choices := m.getMenuChoices()
+	if m.cursor >= len(choices) || m.configManager == nil {
+		return m, nil
+	}
+	
+	switch choices[m.cursor] {
+	case safeModeClearCache:
+		if err := m.configManager.ClearCache(); err != nil {
+			m.safeModeStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		} else {
+			m.safeModeStatus = "✅ Repository cache cleared: the listing is fetched again on the next start."
+		}
+	case safeModeResetConfig:
+		backup, err := m.configManager.ResetConfig()
+		switch {
+		case err != nil:
+			m.safeModeStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		case backup != "":
+			m.safeModeStatus = fmt.Sprintf("✅ Configuration reset. The previous one was saved to %s.", backup)
+		default:
+			m.safeModeStatus = "✅ Configuration reset."
+		}
+	case safeModeCrashLog:
+		crash, err := m.configManager.LastCrash()
+		switch {
+		case err != nil:
+			m.safeModeStatus = errorStyle.Render(fmt.Sprintf("❌ %v", err))
+		case crash == "":
+			m.safeModeStatus = "No crash recorded in " + m.configManager.GetCrashLogPath()
+		default:
+			m.safeModeStatus = fmt.Sprintf("Last crash (%s):\n%s", m.configManager.GetCrashLogPath(), truncateLines(crash, 20))
+		}
+	case safeModeStart:
+		return m.leaveSafeMode(true)
+	case safeModeContinue:
+		return m.leaveSafeMode(false)
+	}
+	return m, nil
+}
+
+// leaveSafeMode goes to the main menu, running the skipped initialization when starting normally
+func (m MenuModel) leaveSafeMode(initialize bool) (tea.Model, tea.Cmd) {
+	m.safeModeCrashes = 0
+	m.safeModeStatus = ""
+	m.currentMenu = MainMenu
+	m.menuStack = []MenuType{}
+	m.cursor = 0
+	if initialize {
+		m = performInitialSetup(m)
+		m = checkRepositoryCompatibility(m)
+		m = checkBinaryIntegrity(m)
+	}
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
+// truncateLines keeps the first max lines of text
+func truncateLines(text string, max int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= max {
+		return text
+	}
+	return strings.Join(lines[:max], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-max)
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+	"boba/internal/github"
+	"boba/internal/installer"
+	"boba/internal/version"
+)
+
+// ReleaseCheckMsg carries the newest BOBA release of the configured update channel
+type ReleaseCheckMsg struct {
+	Release *github.Release
+	Err     error
+}
+
+// SelfUpdateMsg reports the outcome of replacing the BOBA binary with a release
+type SelfUpdateMsg struct {
+	Version  string
+	Path     string // Binary that was replaced
+	Checksum string // SHA-256 of the new binary
+	Err      error
+}
+
+// updateChannel returns the configured release channel for BOBA itself
+func (m MenuModel) updateChannel() string {
+	if m.configManager == nil {
+		return config.UpdateChannelStable
+	}
+	return m.configManager.GetUpdateChannel()
+}
+
+// checkForBobaUpdate fetches the newest BOBA release of the configured channel
+func (m MenuModel) checkForBobaUpdate() tea.Cmd {
+	includePrerelease := m.updateChannel() == config.UpdateChannelEdge
+	return func() tea.Msg {
+		releases, err := github.FetchBobaReleases()
+		if err != nil {
+			return ReleaseCheckMsg{Err: err}
+		}
+		return ReleaseCheckMsg{Release: github.LatestRelease(releases, includePrerelease)}
+	}
+}
+
+// handleReleaseCheckMsg stores the release check so the update screen can show its notes
+func (m MenuModel) handleReleaseCheckMsg(msg ReleaseCheckMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.bobaRelease = &msg
+	if m.currentMenu == SelfUpdateMenu {
+		m.choices = m.getMenuChoices()
+		m.cursor = 0
+	}
+	return m, nil
+}
+
+// bobaUpdateAvailable reports whether the checked release is newer than the running binary.
+// Development builds are never offered updates.
+func (m MenuModel) bobaUpdateAvailable() bool {
+	if m.bobaRelease == nil || m.bobaRelease.Release == nil || !version.IsRelease() {
+		return false
+	}
+	cmp, err := version.Compare(m.bobaRelease.Release.Version, version.Version)
+	return err == nil && cmp > 0
+}
+
+func (m MenuModel) getSelfUpdateChoices() []string {
+	var choices []string
+	if m.bobaUpdateAvailable() {
+		choices = append(choices, fmt.Sprintf("⬆️ Update to %s", m.bobaRelease.Release.Version))
+	}
+	other := config.UpdateChannelEdge
+	if m.updateChannel() == config.UpdateChannelEdge {
+		other = config.UpdateChannelStable
+	}
+	choices = append(choices,
+		fmt.Sprintf("📡 Channel: %s (switch to %s)", m.updateChannel(), other),
+		"🔄 Check Again",
+		"← Back",
+	)
+	return choices
+}
+
+// getSelfUpdateTitle shows the running version and the release notes of a newer release
+func (m MenuModel) getSelfUpdateTitle() string {
+	const maxNoteLines = 15
+	
+	var s strings.Builder
+	s.WriteString("⬆️ BOBA Updates\n")
+	s.WriteString(fmt.Sprintf("   Running: %s (%s channel)", version.Version, m.updateChannel()))
+	
+	switch {
+	case m.bobaRelease == nil:
+		return s.String()
+	case m.bobaRelease.Err != nil:
+		s.WriteString(fmt.Sprintf("\n❌ Could not check for updates: %v", m.bobaRelease.Err))
+		return s.String()
+	case m.bobaRelease.Release == nil:
+		s.WriteString(fmt.Sprintf("\n   No %s release has been published yet", m.updateChannel()))
+		return s.String()
+	case !version.IsRelease():
+		s.WriteString(fmt.Sprintf("\n   Latest release: %s (development builds are not updated)", m.bobaRelease.Release.Version))
+		return s.String()
+	case !m.bobaUpdateAvailable():
+		s.WriteString(fmt.Sprintf("\n✅ You are running the latest %s release", m.updateChannel()))
+		return s.String()
+	}
+	
+	release := m.bobaRelease.Release
+	s.WriteString(fmt.Sprintf("\n\n🎉 %s is available", release.Version))
+	if release.Name != "" && release.Name != release.Version {
+		s.WriteString(": " + release.Name)
+	}
+	if !release.PublishedAt.IsZero() {
+		s.WriteString(fmt.Sprintf(" (%s)", release.PublishedAt.Format("2006-01-02")))
+	}
+	s.WriteString("\n")
+	
+	notes := strings.Split(strings.TrimSpace(strings.ReplaceAll(release.Notes, "\r\n", "\n")), "\n")
+	for i, line := range notes {
+		if i == maxNoteLines {
+			s.WriteString(fmt.Sprintf("\n   ... see the full notes on github.com/%s/%s/releases", github.BobaReleaseOwner, github.BobaReleaseRepo))
+			break
+		}
+		s.WriteString("\n   " + line)
+	}
+	return s.String()
+}
+
+func (m MenuModel) handleSelfUpdateSelection() (tea.Model, tea.Cmd) {
+	selection := m.cursor
+	if !m.bobaUpdateAvailable() {
+		selection++ // No update entry
+	}
+	
+	switch selection {
+	case 0:
+		return m.startSelfUpdate()
+	case 1:
+		other := config.UpdateChannelEdge
+		if m.updateChannel() == config.UpdateChannelEdge {
+			other = config.UpdateChannelStable
+		}
+		if err := m.configManager.SetUpdateChannel(other); err != nil {
+			m.bobaRelease = &ReleaseCheckMsg{Err: err}
+			return m, nil
+		}
+		fallthrough
+	case 2:
+		m.isLoading = true
+		m.loadingMessage = "Checking for BOBA updates..."
+		return m, m.checkForBobaUpdate()
+	default:
+		m.bobaRelease = nil
+		m.navigateBack()
+	}
+	return m, nil
+}
+
+// startSelfUpdate replaces the running binary with the verified release archive for this platform
+func (m MenuModel) startSelfUpdate() (tea.Model, tea.Cmd) {
+	release := m.bobaRelease.Release
+	systemInstaller := m.systemInstaller
+	
+	m.isLoading = true
+	m.loadingMessage = fmt.Sprintf("Updating BOBA to %s...", release.Version)
+	return m, func() tea.Msg {
+		if systemInstaller == nil {
+			return SelfUpdateMsg{Version: release.Version, Err: fmt.Errorf("system installer not available")}
+		}
+		if err := systemInstaller.SelfUpdate(release.Assets); err != nil {
+			return SelfUpdateMsg{Version: release.Version, Err: err}
+		}
+		checksum, _ := installer.FileChecksum(systemInstaller.BinaryPath())
+		return SelfUpdateMsg{Version: release.Version, Path: systemInstaller.BinaryPath(), Checksum: checksum}
+	}
+}
+
+// handleSelfUpdateMsg shows the outcome of a self-update
+func (m MenuModel) handleSelfUpdateMsg(msg SelfUpdateMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	
+	result := InstallationResult{ToolName: "BOBA " + msg.Version, Success: msg.Err == nil, Error: msg.Err}
+	if msg.Err != nil {
+		result.Message = fmt.Sprintf("Update failed: %v", msg.Err)
+	} else {
+		m.recordBinaryChecksum(msg.Path, msg.Checksum)
+		result.Message = fmt.Sprintf("Updated to %s. Restart BOBA to use the new version.", msg.Version)
+	}
+	m.installationResults = []InstallationResult{result}
+	m.showingResults = true
+	return m, nil
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"encoding/json"
+	"time"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/config"
+)
+
+// interruptedInstallNotice is shown on the Install Everything screen after a run was interrupted
+const interruptedInstallNotice = "⚠️ The last Install Everything run was interrupted before it finished. Review the plan and start it again to install the remaining tools."
+
+// sessionMenuNames are the menus a restarted BOBA returns to, by their name in session.json.
+// Prompts (authentication, repository trust, sync conflicts) are not restored.
+var sessionMenuNames = map[MenuType]string{
+	MainMenu:                "main",
+	InstallEverythingMenu:   "install_everything",
+	UpdateEverythingMenu:    "update_everything",
+	ToolsListMenu:           "tools",
+	EnvironmentMenu:         "environments",
+	ConfigurationMenu:       "configuration",
+	RepositoryConfigMenu:    "repository",
+	ToolOverrideMenu:        "tool_overrides",
+	EnvironmentOverrideMenu: "environment_overrides",
+	SystemInstallMenu:       "system_install",
+	SelfUpdateMenu:          "self_update",
+	SkipListMenu:            "skip_list",
+	CommunityMenu:           "community",
+}
+
+// sessionMenu returns the menu saved under a name in session.json
+func sessionMenu(name string) (MenuType, bool) {
+	for menu, menuName := range sessionMenuNames {
+		if menuName == name {
+			return menu, true
+		}
+	}
+	return MainMenu, false
+}
+
+// sessionState returns the navigation state to save: the current menu, or the closest menu
+// leading to it when it is not restored
+func (m MenuModel) sessionState() config.SessionState {
+	menus := append(append([]MenuType{}, m.menuStack...), m.currentMenu)
+	cursor := m.cursor
+	for len(menus) > 1 {
+		if _, ok := sessionMenuNames[menus[len(menus)-1]]; ok {
+			break
+		}
+		menus = menus[:len(menus)-1]
+		cursor = 0
+	}
+	
+	state := config.SessionState{
+		Menu:               sessionMenuNames[menus[len(menus)-1]],
+		Cursor:             cursor,
+		InterruptedInstall: m.installationInProgress && m.currentMenu == InstallEverythingMenu,
+	}
+	if state.Menu == "" {
+		state.Menu = sessionMenuNames[MainMenu]
+	}
+	for _, menu := range menus[:len(menus)-1] {
+		if name, ok := sessionMenuNames[menu]; ok {
+			state.MenuStack = append(state.MenuStack, name)
+		}
+	}
+	return state
+}
+
+// saveSession writes the navigation state when it changed since it was last saved
+func (m MenuModel) saveSession() MenuModel {
+	if m.configManager == nil {
+		return m
+	}
+	
+	state := m.sessionState()
+	key, err := json.Marshal(state)
+	if err != nil || string(key) == m.savedSession {
+		return m
+	}
+	
+	state.SavedAt = time.Now()
+	if err := m.configManager.SaveSession(state); err == nil {
+		m.savedSession = string(key)
+	}
+	return m
+}
+
+// restoreSession returns to the menu of the last session, fetching the tools or environments it
+// shows, and keeps the navigation state saved from now on
+func restoreSession(model MenuModel) (MenuModel, tea.Cmd) {
+	if model.configManager == nil {
+		return model, nil
+	}
+	model.sessionTracking = true
+	
+	state, ok, err := model.configManager.LoadSession()
+	if err != nil || !ok || !model.isGitHubAuthenticated() {
+		return model, nil
+	}
+	menu, ok := sessionMenu(state.Menu)
+	if !ok || menu == MainMenu && !state.InterruptedInstall {
+		return model, nil
+	}
+	
+	stack := []MenuType{}
+	for _, name := range state.MenuStack {
+		if parent, ok := sessionMenu(name); ok {
+			stack = append(stack, parent)
+		}
+	}
+	if state.InterruptedInstall {
+		menu, stack = InstallEverythingMenu, []MenuType{MainMenu}
+		state.Cursor = 0
+		model.sessionNotice = interruptedInstallNotice
+	}
+	
+	model.currentMenu = menu
+	model.menuStack = stack
+	model.choices = model.getMenuChoices()
+	
+	var cmd tea.Cmd
+	var updated tea.Model = model
+	switch menu {
+	case ToolsListMenu, ToolOverrideMenu:
+		updated, cmd = model.fetchAndDisplayTools()
+	case EnvironmentMenu, EnvironmentOverrideMenu:
+		updated, cmd = model.fetchAndDisplayEnvironments()
+	case SelfUpdateMenu:
+		model.isLoading = true
+		model.loadingMessage = "Checking for BOBA updates..."
+		updated, cmd = model, model.checkForBobaUpdate()
+	case CommunityMenu:
+		updated, cmd = model.goToMenu(ConfigurationMenu).browseCommunityTools()
+	}
+	model = updated.(MenuModel)
+	
+	// Menus listing fetched items keep the cursor until the list arrives
+	if cmd == nil && state.Cursor >= len(model.choices) {
+		state.Cursor = 0
+	}
+	model.cursor = state.Cursor
+	return model, cmd
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/config"
+	"boba/internal/installer"
+)
+
+// shellDefinitionsChoice opens the shell definitions from the environments list
+const shellDefinitionsChoice = "🔎 Shell Definitions"
+
+// maxShellDefinitions is how many definitions the shell definitions screen lists
+const maxShellDefinitions = 30
+
+// showShellDefinitions reads what the managed snippets of the home directory define and shows it
+func (m MenuModel) showShellDefinitions() (tea.Model, tea.Cmd) {
+	m.loadShellDefinitions()
+	m.navigateToMenu(ShellDefinitionsMenu)
+	return m, nil
+}
+
+// loadShellDefinitions reads the aliases, PATH entries and exports of the blocks in the shell rc
+// files and of the home files environments wrote
+func (m *MenuModel) loadShellDefinitions() {
+	m.shellDefinitions, m.shellDefinitionsErr = installer.ManagedShellDefinitions(m.managedFiles())
+}
+
+// managedFiles returns the home files environments wrote, by file
+func (m MenuModel) managedFiles() map[string]config.ManagedFile {
+	if m.configManager == nil {
+		return nil
+	}
+	return m.configManager.GetConfig().ManagedFiles
+}
+
+// getShellDefinitionsTitle lists the aliases, PATH entries and exports, each with the tool or
+// environment it comes from
+func (m MenuModel) getShellDefinitionsTitle() string {
+	lines := []string{
+		"🔎 Shell Definitions",
+		"   What BOBA's snippets define in your shell, and the tool or environment each comes from.",
+	}
+	if m.shellDefinitionsErr != nil {
+		lines = append(lines, "", "   "+errorStyle.Render("❌ "+m.shellDefinitionsErr.Error()))
+	}
+	if len(m.shellDefinitions) == 0 {
+		return strings.Join(append(lines, "   Nothing yet: apply an environment first."), "\n")
+	}
+
+	shown := 0
+	sections := []struct{ kind, title string }{
+		{installer.ShellAlias, "Aliases"},
+		{installer.ShellPath, "PATH entries"},
+		{installer.ShellExport, "Exports"},
+	}
+	for _, section := range sections {
+		var entries []string
+		for _, d := range m.shellDefinitions {
+			if d.Kind != section.kind || shown == maxShellDefinitions {
+				continue
+			}
+			shown++
+			entries = append(entries, shellDefinitionLine(d))
+		}
+		if len(entries) > 0 {
+			lines = append(lines, "", "   "+section.title+":")
+			lines = append(lines, entries...)
+		}
+	}
+	if more := len(m.shellDefinitions) - shown; more > 0 {
+		lines = append(lines, "", fmt.Sprintf("   … %d more, listed by boba env show", more))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shellDefinitionLine describes one definition and where it is made
+func shellDefinitionLine(d installer.ShellDefinition) string {
+	what := d.Name
+	if d.Kind != installer.ShellPath {
+		what = fmt.Sprintf("%s = %s", d.Name, d.Value)
+	}
+	where := fmt.Sprintf("%s · ~/%s:%d", d.Source, d.File, d.Line)
+	if d.Overridden {
+		where += " · overridden later in the file"
+	}
+	return fmt.Sprintf("     %-40s %s", what, where)
+}
+
+// getShellDefinitionsChoices offers reading the files again
+func (m MenuModel) getShellDefinitionsChoices() []string {
+	return []string{"🔄 Refresh", "← Back"}
+}
+
+// handleShellDefinitionsSelection reads the files again or goes back to the environments
+func (m MenuModel) handleShellDefinitionsSelection() (tea.Model, tea.Cmd) {
+	if m.cursor == 0 {
+		m.loadShellDefinitions()
+		return m, nil
+	}
+	m.navigateBack()
+	return m, nil
+}
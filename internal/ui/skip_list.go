@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// knownFailingTools returns the tools Install Everything can skip: skipped by the user or failing repeatedly
+func (m MenuModel) knownFailingTools() []string {
+	if m.configManager == nil {
+		return nil
+	}
+	return m.configManager.KnownFailingTools()
+}
+
+// isToolSkipped reports whether the user put the tool on the Install Everything skip list
+func (m MenuModel) isToolSkipped(toolName string) bool {
+	return m.configManager != nil && m.configManager.IsToolSkipped(toolName)
+}
+
+// toggleToolSkipped adds the tool to or removes it from the skip list
+func (m MenuModel) toggleToolSkipped(toolName string) (tea.Model, tea.Cmd) {
+	if m.configManager == nil {
+		return m, nil
+	}
+	if err := m.configManager.SetToolSkipped(toolName, !m.isToolSkipped(toolName)); err != nil {
+		m.loadingMessage = fmt.Sprintf("Error: %v", err)
+	}
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
+// startInstallEverythingWith runs Install Everything, optionally without the known-failing tools
+// or retrying the tools still in their install cooldown
+func (m MenuModel) startInstallEverythingWith(skipKnownFailing, retryCooldown bool) (tea.Model, tea.Cmd) {
+	m.skipKnownFailing = skipKnownFailing
+	m.retryCooldown = retryCooldown
+	return m.startInstallEverything()
+}
+
+// toolsInCooldown returns the tools whose install failed less than the cooldown ago
+func (m MenuModel) toolsInCooldown(now time.Time) map[string]time.Time {
+	if m.configManager == nil {
+		return nil
+	}
+	return m.configManager.ToolsInCooldown(now)
+}
+
+// toolsToSkip returns the tools Install Everything leaves out, with the reason shown in the results
+func (m MenuModel) toolsToSkip(now time.Time) map[string]string {
+	skip := make(map[string]string)
+	if !m.retryCooldown {
+		for name, failedAt := range m.toolsInCooldown(now) {
+			skip[name] = fmt.Sprintf("⏳ Not retried: failed %s ago, waiting for the %s install cooldown",
+				shortDuration(now.Sub(failedAt)), shortDuration(m.configManager.GetInstallCooldown()))
+		}
+	}
+	if m.skipKnownFailing {
+		for _, name := range m.knownFailingTools() {
+			skip[name] = "⏭️ Skipped: on the skip list or failing repeatedly"
+		}
+	}
+	return skip
+}
+
+// shortDuration formats a duration in whole minutes, e.g. "15m" or "1h30m"
+func shortDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
+// filterSkippedTools removes the skipped tools and, transitively, the tools depending on them,
+// returning a result for each tool left out
+func filterSkippedTools(tools []parser.Tool, skip map[string]string) ([]parser.Tool, []InstallationResult) {
+	kept, reasons := installer.NewDependencyResolver().LeaveOutTools(tools, skip)
+	
+	var skipped []InstallationResult
+	for _, tool := range tools {
+		if reason, removed := reasons[tool.Name]; removed {
+			if _, direct := skip[tool.Name]; !direct {
+				reason = "⏭️ Skipped: " + reason
+			}
+			skipped = append(skipped, InstallationResult{ToolName: tool.Name, Success: false, Message: reason, Phase: toolResultPhase(tools, tool.Name)})
+		}
+	}
+	return kept, skipped
+}
+
+func (m MenuModel) getSkipListChoices() []string {
+	var choices []string
+	health := m.configManager.GetHealth()
+	for _, name := range m.knownFailingTools() {
+		if m.isToolSkipped(name) {
+			choices = append(choices, fmt.Sprintf("⏭️ %s - skipped by you", name))
+		} else {
+			choices = append(choices, fmt.Sprintf("❌ %s - failed %d times in a row", name, health.ScriptFailures[name]))
+		}
+	}
+	if len(choices) == 0 {
+		choices = append(choices, "✅ No skipped or known-failing tools")
+	} else {
+		choices = append(choices, "🧹 Clear Skip List")
+	}
+	choices = append(choices, "← Back to Configuration Menu")
+	return choices
+}
+
+// handleSkipListSelection removes the selected tool from the skip list, or clears the whole list
+func (m MenuModel) handleSkipListSelection() (tea.Model, tea.Cmd) {
+	currentChoices := m.getMenuChoices()
+	if m.cursor == len(currentChoices)-1 {
+		m.navigateBack()
+		return m, nil
+	}
+	
+	tools := m.knownFailingTools()
+	var err error
+	switch {
+	case m.cursor < len(tools):
+		err = m.configManager.ForgetToolFailures(tools[m.cursor])
+	case len(tools) > 0 && m.cursor == len(tools):
+		err = m.configManager.ClearSkipList()
+	}
+	if err != nil {
+		m.loadingMessage = fmt.Sprintf("Error: %v", err)
+	}
+	
+	m.choices = m.getMenuChoices()
+	if m.cursor >= len(m.choices) {
+		m.cursor = len(m.choices) - 1
+	}
+	return m, nil
+}
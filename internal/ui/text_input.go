@@ -0,0 +1,20 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editInput applies a typing key (characters, space, backspace) to a single line input
+func editInput(input string, msg tea.KeyMsg) string {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(input); len(runes) > 0 {
+			return string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		return input + string(msg.Runes)
+	case tea.KeySpace:
+		return input + " "
+	}
+	return input
+}
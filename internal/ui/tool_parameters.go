@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/parser"
+)
+
+// parameterForm holds the parameter values being chosen before installing a tool
+type parameterForm struct {
+	tool    parser.Tool
+	values  map[string]string
+	editing string // Parameter whose value is being typed, empty otherwise
+	input   string
+	err     string
+}
+
+// requestToolInstall installs a tool, asking for its parameters first when it declares any
+func (m MenuModel) requestToolInstall(tool parser.Tool) (tea.Model, tea.Cmd) {
+	if len(tool.Parameters) == 0 {
+		return m.installSingleTool(tool)
+	}
+	
+	var chosen map[string]string
+	if m.configManager != nil {
+		chosen = m.configManager.GetToolParameters()[tool.Name]
+	}
+	m.paramForm = parameterForm{tool: tool, values: tool.ParameterValues(chosen)}
+	m.navigateToMenu(ToolParametersMenu)
+	return m, nil
+}
+
+func (m MenuModel) getToolParametersChoices() []string {
+	var choices []string
+	for _, param := range m.paramForm.tool.Parameters {
+		choice := fmt.Sprintf("%s = %s", param.Name, m.paramForm.values[param.Name])
+		if param.Description != "" {
+			choice += "  — " + param.Description
+		}
+		choices = append(choices, choice)
+	}
+	return append(choices,
+		fmt.Sprintf("📦 Install %s", m.paramForm.tool.Name),
+		"← Back",
+	)
+}
+
+// getToolParametersTitle explains the form and shows the value being typed
+func (m MenuModel) getToolParametersTitle() string {
+	title := fmt.Sprintf("⚙️ Parameters: %s\n", m.paramForm.tool.Name) +
+		"   Select a parameter to change it: choices and true/false values cycle, other values are typed.\n" +
+		"   The values are remembered for Install Everything and updates."
+	if m.paramForm.editing != "" {
+		title += "\n\n" + selectedMenuItemStyle.Render(fmt.Sprintf("Value of %s: %s▌", m.paramForm.editing, m.paramForm.input))
+	}
+	if m.paramForm.err != "" {
+		title += "\n" + errorStyle.Render(m.paramForm.err)
+	}
+	return title
+}
+
+// nextChoice returns the value after the current one, wrapping around
+func nextChoice(choices []string, current string) string {
+	for i, choice := range choices {
+		if choice == current {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+// handleToolParametersSelection changes a parameter, or saves the values and installs the tool
+func (m MenuModel) handleToolParametersSelection() (tea.Model, tea.Cmd) {
+	params := m.paramForm.tool.Parameters
+	m.paramForm.err = ""
+	switch {
+	case m.cursor < len(params):
+		param := params[m.cursor]
+		switch param.Kind() {
+		case parser.ParameterChoice:
+			m.paramForm.values[param.Name] = nextChoice(param.Choices, m.paramForm.values[param.Name])
+		case parser.ParameterBool:
+			m.paramForm.values[param.Name] = nextChoice([]string{"true", "false"}, m.paramForm.values[param.Name])
+		default:
+			m.paramForm.editing = param.Name
+			m.paramForm.input = m.paramForm.values[param.Name]
+		}
+		m.choices = m.getMenuChoices()
+		return m, nil
+	case m.cursor == len(params):
+		tool, values := m.paramForm.tool, m.paramForm.values
+		if m.configManager != nil {
+			if err := m.configManager.SetToolParameters(tool.Name, values); err != nil {
+				m.paramForm.err = fmt.Sprintf("Could not save the parameters: %v", err)
+				return m, nil
+			}
+			if m.installEngine != nil {
+				m.installEngine.SetParameterValues(m.configManager.GetToolParameters())
+			}
+		}
+		m.navigateBack()
+		return m.installSingleTool(tool)
+	}
+	m.navigateBack()
+	return m, nil
+}
+
+// handleParameterKey edits the typed value of a parameter
+func (m MenuModel) handleParameterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.paramForm.editing = ""
+		m.paramForm.err = ""
+	case tea.KeyEnter:
+		for _, param := range m.paramForm.tool.Parameters {
+			if param.Name != m.paramForm.editing {
+				continue
+			}
+			value := strings.TrimSpace(m.paramForm.input)
+			if err := param.Validate(value); err != nil {
+				m.paramForm.err = err.Error()
+				return m, nil
+			}
+			m.paramForm.values[param.Name] = value
+		}
+		m.paramForm.editing = ""
+		m.paramForm.err = ""
+		m.choices = m.getMenuChoices()
+	default:
+		m.paramForm.input = editInput(m.paramForm.input, msg)
+	}
+	return m, nil
+}
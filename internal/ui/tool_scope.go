@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/installer"
+)
+
+// toolScopeLabel describes where an installed tool was found: in the user's home, or
+// system-wide where it is shared with the other users of the machine. A system-wide tool BOBA
+// has no record of was installed by an admin or another user.
+func (m MenuModel) toolScopeLabel(toolName string) string {
+	scope, ok := m.toolScopes[toolName]
+	if !ok {
+		return ""
+	}
+	if scope.Scope == installer.ScopeUser {
+		return " 👤 user"
+	}
+	if m.configManager != nil {
+		if _, recorded := m.configManager.GetInstalledTool(toolName); recorded {
+			return " 🖥️ system-wide"
+		}
+	}
+	return " 🖥️ system-wide, shared (installed by an admin or another user)"
+}
+
+// noSudoChoice is the Installation Configuration choice toggling the never-use-sudo mode
+func (m MenuModel) noSudoChoice() string {
+	if m.installEngine != nil && m.installEngine.NoSudo() {
+		return "🚫 Never Use sudo: On"
+	}
+	return "🚫 Never Use sudo: Off"
+}
+
+// toggleNoSudo turns the never-use-sudo mode on or off and saves it
+func (m MenuModel) toggleNoSudo() (tea.Model, tea.Cmd) {
+	if m.configManager == nil || m.installEngine == nil {
+		return m, nil
+	}
+	noSudo := !m.installEngine.NoSudo()
+	if err := m.configManager.SetNoSudo(noSudo); err != nil {
+		m.loadingMessage = fmt.Sprintf("Error: %v", err)
+		return m, nil
+	}
+	m.installEngine.SetNoSudo(noSudo)
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	
+	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/installer"
+	"boba/internal/parser"
+)
+
+// ToolScriptsMsg carries the install and uninstall scripts of a tool
+type ToolScriptsMsg struct {
+	Tool  parser.Tool
+	Files []installer.RepositoryFile
+	Err   error
+}
+
+// showToolScripts opens the read-only view of a tool's scripts and fetches them
+func (m MenuModel) showToolScripts(tool parser.Tool) (tea.Model, tea.Cmd) {
+	m.toolScripts = &ToolScriptsMsg{Tool: tool}
+	m.viewer = codeViewer{}
+	m.navigateToMenu(ToolScriptsMenu)
+	if m.installEngine == nil {
+		m.toolScripts.Err = fmt.Errorf("installation engine not initialized")
+		return m, nil
+	}
+	
+	m.isLoading = true
+	m.loadingMessage = fmt.Sprintf("Fetching the scripts of %s...", tool.Name)
+	engine := m.installEngine
+	return m, func() tea.Msg {
+		files, err := engine.ToolScripts(tool)
+		return ToolScriptsMsg{Tool: tool, Files: files, Err: err}
+	}
+}
+
+// handleToolScriptsMsg shows the fetched scripts
+func (m MenuModel) handleToolScriptsMsg(msg ToolScriptsMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.toolScripts = &msg
+	m.viewer = newCodeViewer(msg.Files)
+	m.choices = m.getMenuChoices()
+	return m, nil
+}
+
+// getToolScriptsTitle shows the visible part of the tool's scripts
+func (m MenuModel) getToolScriptsTitle() string {
+	if m.toolScripts == nil {
+		return "📜 Tool Scripts"
+	}
+	tool := m.toolScripts.Tool
+	
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("📜 Scripts: %s\n", tool.Name))
+	if tool.Description != "" {
+		s.WriteString("   " + tool.Description + "\n")
+	}
+	if tool.ScriptSource != nil {
+		s.WriteString("   Scripts from " + tool.ScriptSource.String() + "\n")
+	}
+	if m.toolScripts.Err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("❌ Could not fetch the tool scripts: %v", m.toolScripts.Err)))
+		return s.String()
+	}
+	
+	s.WriteString("\n")
+	s.WriteString(m.viewer.View())
+	return s.String()
+}
+
+func (m MenuModel) getToolScriptsChoices() []string {
+	if m.toolScripts == nil {
+		return []string{"← Back to Tools"}
+	}
+	return []string{
+		fmt.Sprintf("📦 Install %s", m.toolScripts.Tool.Name),
+		"← Back to Tools",
+	}
+}
+
+// handleToolScriptsSelection installs the tool or returns to the tools list
+func (m MenuModel) handleToolScriptsSelection() (tea.Model, tea.Cmd) {
+	choices := m.getMenuChoices()
+	if m.cursor >= len(choices)-1 || m.toolScripts == nil {
+		m.navigateBack()
+		return m, nil
+	}
+	
+	tool := m.toolScripts.Tool
+	m.navigateBack()
+	return m.requestToolInstall(tool)
+}
@@ -1,16 +1,48 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"boba/internal/github"
 	"boba/internal/installer"
+	"boba/internal/log"
 	"boba/internal/parser"
 )
 
-// Update handles user input and updates the model
+// Update handles user input and updates the model, saving the navigation state as it changes
 func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if updated, ok := model.(MenuModel); ok && updated.sessionTracking {
+		model = updated.saveSession()
+	}
+	return model, cmd
+}
+
+// update handles user input and updates the model
+func (m MenuModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Keep the watchdog informed before anything else can consume the message
+	if updated, cmd, handled := m.handleWatchdogMsg(msg); handled {
+		return updated, cmd
+	}
+	
+	// After all BOBA data was removed the result stays on screen until any key quits
+	if _, ok := msg.(tea.KeyMsg); ok && m.dataRemoved {
+		return m, tea.Quit
+	}
+	
+	// Pick up configuration changes from other processes and refresh the menu
+	if updated, cmd, handled := m.handleConfigMsg(msg); handled {
+		return updated, cmd
+	}
+	
+	// Hot reload tools and environments when the local repository changes
+	if updated, cmd, handled := m.handleRepoWatchMsg(msg); handled {
+		return updated, cmd
+	}
+	
 	// First handle authentication completion messages regardless of current menu
 	if strMsg, ok := msg.(string); ok {
 		switch strMsg {
@@ -24,24 +56,32 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Save the GitHub token to credentials
 				if err := m.configManager.SetGitHubToken(token); err != nil {
 					// Handle error but don't fail completely
-					fmt.Printf("Warning: Failed to save GitHub token: %v\n", err)
+					log.Warn("Failed to save GitHub token", "error", err)
 				}
 				
 				// Save the repository URL to config
 				if err := m.configManager.SetRepositoryURL(repoURL); err != nil {
 					// Handle error but don't fail completely
-					fmt.Printf("Warning: Failed to save repository URL: %v\n", err)
+					log.Warn("Failed to save repository URL", "error", err)
 				}
 				
+				m.recordTokenResult(true)
+				
 				// Set the GitHub client and initialize components
 				m.githubClient = client
-				m.repoParser = parser.NewRepositoryParser(m.githubClient)
-				m.installEngine = installer.NewInstallationEngine(m.githubClient)
+				m.repoParser = newRepositoryParser(m.githubClient, m.configManager)
+				m.installEngine = newInstallationEngine(m.githubClient, m.configManager)
+				m = checkRepositoryCompatibility(m)
 			}
 			m.currentMenu = MainMenu
 			m.menuStack = []MenuType{} // Clear the stack
 			m.choices = m.getMenuChoices()
 			m.cursor = 0
+			
+			// First time seeing this repository: ask the user to confirm they trust it
+			if !m.isRepositoryTrusted() {
+				return m, m.reviewRepositoryTrust(m.githubClient)
+			}
 			return m, nil
 		case "auth_cancelled":
 			// Authentication cancelled, go back to main menu
@@ -56,6 +96,11 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.menuStack = []MenuType{ConfigurationMenu} // Set proper navigation stack
 			m.choices = m.getMenuChoices()
 			m.cursor = 0
+			
+			// First time seeing this repository: ask the user to confirm they trust it
+			if m.authModel != nil && m.authModel.GetClient() != nil && !m.configManager.IsRepositoryTrusted(m.authModel.GetClient().GetFullRepoName()) {
+				return m, m.reviewRepositoryTrust(m.authModel.GetClient())
+			}
 			return m, nil
 		case "repo_config_cancelled":
 			// Repository configuration cancelled, go back to repository config menu
@@ -75,14 +120,25 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		
 		// Cache installation status for all tools to avoid repeated system calls
 		m.toolInstallStatus = make(map[string]bool)
+		m.toolScopes = make(map[string]installer.InstalledScope)
 		if m.installEngine != nil {
 			for _, tool := range toolsMsg.Tools {
-				m.toolInstallStatus[tool.Name] = m.installEngine.IsToolInstalled(tool)
+				scope, installed := m.installEngine.FindInstalledScope(tool)
+				m.toolInstallStatus[tool.Name] = installed
+				if installed {
+					m.toolScopes[tool.Name] = scope
+				}
 			}
 		}
 		
 		// Update the menu choices to show the tools
 		m.choices = m.getMenuChoices()
+		m.clampCursor()
+		
+		// Look up security advisories for the installed tools once per session, in the background
+		if m.advisories == nil {
+			return m, m.checkAdvisories()
+		}
 		return m, nil
 	}
 
@@ -94,13 +150,14 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		
 		// Update the menu choices to show the environments
 		m.choices = m.getMenuChoices()
+		m.clampCursor()
 		return m, nil
 	}
 
 	// Handle installation progress messages
 	if progressMsg, ok := msg.(InstallationProgressMsg); ok {
 		// Update installation status cache if installation was successful
-		if progressMsg.Success {
+		if progressMsg.Success && !m.dryRun() {
 			m.toolInstallStatus[progressMsg.ToolName] = true
 			
 			// Record successful installation for single tool installs
@@ -124,6 +181,11 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			Message:  progressMsg.Status,
 		}
 		
+		// End of an installation: persist the batched installation records
+		if m.configManager != nil {
+			m.configManager.Flush()
+		}
+		
 		// Show results screen instead of immediately returning to menu
 		m.isLoading = false
 		m.installationInProgress = false
@@ -134,17 +196,116 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle repository sync results
+	if trustMsg, ok := msg.(RepoTrustInfoMsg); ok {
+		return m.handleRepoTrustInfoMsg(trustMsg)
+	}
+	
+	// Handle community tools browsing and imports
+	if indexMsg, ok := msg.(CommunityIndexMsg); ok {
+		return m.handleCommunityIndexMsg(indexMsg)
+	}
+	if validationMsg, ok := msg.(ValidationMsg); ok {
+		return m.handleValidationMsg(validationMsg)
+	}
+	if previewMsg, ok := msg.(EnvironmentPreviewMsg); ok {
+		return m.handleEnvironmentPreviewMsg(previewMsg)
+	}
+	if scriptsMsg, ok := msg.(ToolScriptsMsg); ok {
+		return m.handleToolScriptsMsg(scriptsMsg)
+	}
+	if unchangedMsg, ok := msg.(PlanUnchangedMsg); ok {
+		return m.handlePlanUnchangedMsg(unchangedMsg)
+	}
+	if conflictsMsg, ok := msg.(FileConflictsMsg); ok {
+		return m.handleFileConflictsMsg(conflictsMsg)
+	}
+	if editedMsg, ok := msg.(EditedFilesMsg); ok {
+		return m.handleEditedFilesMsg(editedMsg)
+	}
+	if cleanupMsg, ok := msg.(CleanupUninstallMsg); ok {
+		return m.handleCleanupUninstallMsg(cleanupMsg)
+	}
+	if maintenanceMsg, ok := msg.(MaintenanceMsg); ok {
+		return m.handleMaintenanceMsg(maintenanceMsg)
+	}
+	if exportedMsg, ok := msg.(PlanExportedMsg); ok {
+		return m.handlePlanExportedMsg(exportedMsg)
+	}
+	if importMsg, ok := msg.(CommunityImportMsg); ok {
+		return m.handleCommunityImportMsg(importMsg)
+	}
+	
+	// Handle security advisory checks
+	if advisoryMsg, ok := msg.(AdvisoryCheckMsg); ok {
+		return m.handleAdvisoryCheckMsg(advisoryMsg)
+	}
+	
+	// Handle BOBA release checks and self-updates
+	if releaseMsg, ok := msg.(ReleaseCheckMsg); ok {
+		return m.handleReleaseCheckMsg(releaseMsg)
+	}
+	if selfUpdateMsg, ok := msg.(SelfUpdateMsg); ok {
+		return m.handleSelfUpdateMsg(selfUpdateMsg)
+	}
+	
+	if syncMsg, ok := msg.(RepoSyncMsg); ok {
+		m.isLoading = false
+		m.loadingMessage = ""
+		
+		// Local modifications: ask whether to stash or discard them
+		if errors.Is(syncMsg.Err, github.ErrLocalChanges) && syncMsg.Result != nil {
+			m.syncLocalChanges = syncMsg.Result.LocalChanges
+			if m.currentMenu != RepoSyncConflictMenu {
+				m.navigateToMenu(RepoSyncConflictMenu)
+			}
+			return m, nil
+		}
+		
+		// Leave the conflict menu once a choice has been applied
+		if m.currentMenu == RepoSyncConflictMenu {
+			m.syncLocalChanges = nil
+			m.navigateBack()
+		}
+		
+		result := InstallationResult{
+			ToolName: "Repository sync",
+			Success:  syncMsg.Err == nil,
+			Error:    syncMsg.Err,
+		}
+		if syncMsg.Err != nil {
+			result.Message = syncMsg.Err.Error()
+		} else {
+			result.Message = syncMsg.Result.Summary()
+			m.configManager.UpdateLastSync()
+			if m.repoParser != nil {
+				m.repoParser.InvalidateCache()
+			}
+		}
+		
+		m.showingResults = true
+		m.installationResults = []InstallationResult{result}
+		m.choices = m.getMenuChoices()
+		return m, nil
+	}
+
 	// Handle install everything phase messages
 	if phaseMsg, ok := msg.(InstallEverythingPhaseMsg); ok {
 		if phaseMsg.Phase == "tools" {
 			// Set Install Everything mode and store pending environments
 			m.installEverythingMode = true
 			m.pendingEnvironments = phaseMsg.Environments
+			m.installationResults = append([]InstallationResult{}, phaseMsg.Skipped...)
+			m.runPlan = phaseMsg.Plan
+			m.saveRestorePoint("Before Install Everything", phaseMsg.Environments)
+			if len(phaseMsg.PlanChanges) > 0 {
+				m.installationResults = append(m.installationResults, planChangesResult(phaseMsg.PlanChanges))
+			}
 			
 			if len(phaseMsg.Tools) > 0 {
 				// Start installing tools
 				m.loadingMessage = "Installing tools..."
-				return m, m.installNextTool(phaseMsg.Tools, 0, []InstallationResult{})
+				return m, m.installNextTool(phaseMsg.Tools, 0, append([]InstallationResult{}, m.installationResults...))
 			} else {
 				// No tools to install, move to environments phase
 				return m, func() tea.Msg {
@@ -156,6 +317,11 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		} else if phaseMsg.Phase == "environments" {
+			// The tool results stay in installationResults until the environments complete
+			m.pendingEnvironments = nil
+			if phaseMsg.ToolResults != nil {
+				m.installationResults = phaseMsg.ToolResults
+			}
 			if len(phaseMsg.Environments) > 0 {
 				// Start applying environments
 				m.loadingMessage = "Applying environment configurations..."
@@ -215,10 +381,21 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.systemInstallResult = sysCompleteMsg.Result
 		
 		if sysCompleteMsg.Result.Success {
+			m.recordBinaryChecksum(m.systemInstaller.InstallPath(), sysCompleteMsg.Result.BinaryChecksum)
 			m.loadingMessage = "System installation completed successfully"
 		} else {
 			m.loadingMessage = "System installation failed"
 		}
+		if m.purging {
+			m.purging = false
+			if sysCompleteMsg.Result.Success {
+				// The BOBA directory is gone: stop writing the log there too, and leave on the next key
+				m.dataRemoved = true
+				log.Close()
+			} else if m.configManager != nil {
+				m.configManager.EnablePersistence()
+			}
+		}
 		
 		m.choices = m.getMenuChoices()
 		return m, nil
@@ -226,6 +403,11 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Handle installation completion messages
 	if completeMsg, ok := msg.(InstallationCompleteMsg); ok {
+		// End of a batch: persist the batched installation records
+		if m.configManager != nil {
+			m.configManager.Flush()
+		}
+		
 		// Check if we're in Install Everything mode and have pending environments
 		if m.installEverythingMode && len(m.pendingEnvironments) > 0 {
 			// Store tool results and move to environments phase
@@ -248,14 +430,31 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-		// Normal completion (not Install Everything mode or no pending environments)
+		// Normal completion (not Install Everything mode or no pending environments): other
+		// BOBA processes can run their operations again
+		if m.installEngine != nil {
+			m.installEngine.EndBatch()
+		}
+		results := completeMsg.Results
+		if m.installEverythingMode {
+			// Environments phase done: report it together with the tool results
+			results = append(append([]InstallationResult{}, m.installationResults...), completeMsg.Results...)
+		}
 		m.installationInProgress = false
-		m.installationResults = completeMsg.Results
+		m.installationResults = results
+		m.resultsCollapsed = nil
+		m.resultsFailuresFirst = false
+		m.resultsTitle = ""
+		if err := m.saveRunReport(results); err != nil {
+			m.installationResults = append(m.installationResults, InstallationResult{ToolName: "JUnit report", Success: false, Message: err.Error()})
+		}
+		m.saveRunPlan(results)
 		m.isLoading = false
 		m.showingResults = true // Show results screen instead of immediately returning to menu
 		m.loadingMessage = "" // Clear loading message
 		m.installEverythingMode = false
 		m.pendingEnvironments = nil
+		m.clearRunVariables()
 		
 		// Update installation status cache based on results
 		for _, result := range completeMsg.Results {
@@ -325,12 +524,31 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle results screen - any key returns to menu
+		// The command palette takes all keys while open
+		if m.paletteOpen {
+			return m.handlePaletteKey(msg)
+		}
+		if m.runVariableEditing && m.currentMenu == RunVariablesMenu {
+			return m.handleRunVariableKey(msg)
+		}
+		if m.paramForm.editing != "" && m.currentMenu == ToolParametersMenu {
+			return m.handleParameterKey(msg)
+		}
+		if msg.String() == "ctrl+p" && !m.isLoading && !m.installationInProgress {
+			return m.openPalette()
+		}
+		
+		// Handle results screen - section and sort keys, any other key returns to menu
 		if m.showingResults {
-			m.showingResults = false
-			m.installationResults = []InstallationResult{}
-			m.choices = m.getMenuChoices()
-			return m, nil
+			return m.handleResultsKey(msg)
+		}
+		
+		// Scroll and search keys of the screens showing files
+		if (m.currentMenu == EnvironmentPreviewMenu || m.currentMenu == ToolScriptsMenu) && !m.isLoading {
+			if viewer, handled := m.viewer.update(msg); handled {
+				m.viewer = viewer
+				return m, nil
+			}
 		}
 		
 		switch msg.String() {
@@ -376,6 +594,21 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter", " ":
 			return m.handleMenuSelection()
+		case "f":
+			// Show what the selected tool put on the system
+			if m.currentMenu == ToolsListMenu && m.cursor < len(m.availableTools) {
+				return m.showToolFiles(m.availableTools[m.cursor])
+			}
+		case "v":
+			// Read the selected tool's scripts
+			if m.currentMenu == ToolsListMenu && m.cursor < len(m.availableTools) {
+				return m.showToolScripts(m.availableTools[m.cursor])
+			}
+		case "s":
+			// Skip the selected tool in Install Everything runs (or stop skipping it)
+			if m.currentMenu == ToolsListMenu && m.cursor < len(m.availableTools) {
+				return m.toggleToolSkipped(m.availableTools[m.cursor].Name)
+			}
 		}
 	}
 	return m, nil
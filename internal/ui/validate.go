@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	
+	tea "github.com/charmbracelet/bubbletea"
+
+	"boba/internal/parser"
+)
+
+// ValidationMsg carries the validation report of the repository
+type ValidationMsg struct {
+	Report *parser.ValidationReport
+	Err    error
+}
+
+// validateRepository opens the validation screen and checks every manifest of the repository
+func (m MenuModel) validateRepository() (tea.Model, tea.Cmd) {
+	m.navigateToMenu(ValidationMenu)
+	if m.repoParser == nil {
+		m.validation = &ValidationMsg{Err: fmt.Errorf("no repository connected")}
+		m.choices = m.getMenuChoices()
+		return m, nil
+	}
+	m.validation = nil
+	m.isLoading = true
+	m.loadingMessage = "Validating repository..."
+	repoParser := m.repoParser
+	return m, func() tea.Msg {
+		report, err := repoParser.Validate()
+		return ValidationMsg{Report: report, Err: err}
+	}
+}
+
+// handleValidationMsg shows the validation report
+func (m MenuModel) handleValidationMsg(msg ValidationMsg) (tea.Model, tea.Cmd) {
+	m.isLoading = false
+	m.loadingMessage = ""
+	m.validation = &msg
+	m.choices = m.getMenuChoices()
+	m.cursor = 0
+	return m, nil
+}
+
+// getValidationChoices lists the issues found, errors first
+func (m MenuModel) getValidationChoices() []string {
+	var choices []string
+	if m.validation != nil && m.validation.Report != nil {
+		for _, issue := range m.validation.Report.Issues {
+			icon := "⚠️"
+			if issue.Severity == parser.ValidationError {
+				icon = "❌"
+			}
+			choices = append(choices, fmt.Sprintf("%s %s: %s", icon, issue.Path, issue.Message))
+		}
+	}
+	return append(choices, "🔄 Validate Again", "← Back")
+}
+
+// getValidationTitle summarizes the validation
+func (m MenuModel) getValidationTitle() string {
+	title := "🔎 Repository Validation"
+	switch {
+	case m.validation == nil:
+		return title
+	case m.validation.Err != nil:
+		return title + fmt.Sprintf("\n❌ Could not validate the repository: %v", m.validation.Err)
+	case len(m.validation.Report.Issues) == 0:
+		return title + "\n✅ " + m.validation.Report.Summary()
+	}
+	return title + "\n   " + m.validation.Report.Summary()
+}
+
+// handleValidationSelection validates again or goes back; the issues are only listed
+func (m MenuModel) handleValidationSelection() (tea.Model, tea.Cmd) {
+	choices := m.getMenuChoices()
+	switch m.cursor {
+	case len(choices) - 1:
+		m.navigateBack()
+	case len(choices) - 2:
+		m.navigateBack()
+		return m.validateRepository()
+	}
+	return m, nil
+}
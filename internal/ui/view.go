@@ -6,6 +6,8 @@ import (
 	
 	"github.com/charmbracelet/lipgloss"
 	"github.com/common-nighthawk/go-figure"
+	"boba/internal/config"
+	"boba/internal/installer"
 )
 
 // Enhanced styling constants and styles
@@ -85,7 +87,20 @@ var (
 func (m MenuModel) getMenuTitle() string {
 	switch m.currentMenu {
 	case MainMenu:
-		return "Select an option:"
+		title := "Select an option:"
+		if m.dryRun() {
+			title = dryRunBadge + "\n" + title
+		}
+		if m.repoBadge != "" {
+			title = m.repoBadge + "\n" + title
+		}
+		if m.maintenanceNotice != "" {
+			title = m.maintenanceNotice + "\n" + title
+		}
+		if config.IsEphemeral() {
+			title = "🧪 Ephemeral session: settings and records are discarded on exit\n" + title
+		}
+		return title
 	case InstallEverythingMenu:
 		return "🚀 Install Everything"
 	case ToolsListMenu:
@@ -102,16 +117,81 @@ func (m MenuModel) getMenuTitle() string {
 		return "🌍 Environment Override Management"
 	case GitHubAuthMenu:
 		return "🔐 GitHub Authentication"
+	case RepoSyncConflictMenu:
+		return m.getRepoSyncConflictTitle()
+	case RepoTrustMenu:
+		return m.getRepoTrustTitle()
+	case SelfUpdateMenu:
+		return m.getSelfUpdateTitle()
+	case SkipListMenu:
+		return "⏭️ Skip List Management\nTools left out when Install Everything skips known-failing tools. Select one to remove it."
+	case AdvisoriesMenu:
+		return m.getAdvisoriesTitle()
+	case CommunityMenu:
+		return m.getCommunityTitle()
+	case EnvironmentPreviewMenu:
+		return m.getEnvironmentPreviewTitle()
+	case ToolScriptsMenu:
+		return m.getToolScriptsTitle()
+	case RunVariablesMenu:
+		return m.getRunVariablesTitle()
+	case ToolParametersMenu:
+		return m.getToolParametersTitle()
+	case PlanUnchangedMenu:
+		return m.getPlanUnchangedTitle()
+	case FileConflictsMenu:
+		return m.getFileConflictsTitle()
+	case EditedFilesMenu:
+		return m.getEditedFilesTitle()
+	case ShellDefinitionsMenu:
+		return m.getShellDefinitionsTitle()
+	case CleanupMenu:
+		return m.getCleanupTitle()
+	case OperationQueueMenu:
+		return m.getOperationQueueTitle()
+	case SafeModeMenu:
+		return m.getSafeModeTitle()
+	case ResetMenu:
+		return m.getResetTitle()
+	case ValidationMenu:
+		return m.getValidationTitle()
+	case HistoryMenu:
+		return m.getHistoryTitle()
+	case RestorePointsMenu:
+		return m.getRestorePointsTitle()
 	default:
 		return "Menu"
 	}
 }
 
+// getRepoSyncConflictTitle lists the local modifications that block a repository sync
+func (m MenuModel) getRepoSyncConflictTitle() string {
+	const maxListed = 8
+	
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("⚠️ The local clone has %d modified file(s):", len(m.syncLocalChanges)))
+	for i, change := range m.syncLocalChanges {
+		if i == maxListed {
+			s.WriteString(fmt.Sprintf("\n   ... and %d more", len(m.syncLocalChanges)-maxListed))
+			break
+		}
+		s.WriteString("\n   " + change)
+	}
+	s.WriteString("\nHow should they be handled before syncing?")
+	return s.String()
+}
+
 // getHelpText returns context-appropriate help text
 func (m MenuModel) getHelpText() string {
 	helpText := ""
 	if m.currentMenu == MainMenu {
-		helpText = "Navigate: ↑/↓ or j/k • Select: Enter/Space • Quit: q or Ctrl+C"
+		helpText = "Navigate: ↑/↓ or j/k • Select: Enter/Space • Command palette: Ctrl+P • Quit: q or Ctrl+C"
+	} else if m.currentMenu == RunVariablesMenu && m.runVariableEditing || m.currentMenu == ToolParametersMenu && m.paramForm.editing != "" {
+		helpText = "Type the value • Save: Enter • Cancel: Esc"
+	} else if m.currentMenu == EnvironmentPreviewMenu || m.currentMenu == ToolScriptsMenu {
+		helpText = "Scroll: PgUp/PgDn • Search: / then n/N • Navigate: ↑/↓ or j/k • Select: Enter/Space • Back: esc/b • Quit: q or Ctrl+C"
+	} else if m.currentMenu == ToolsListMenu {
+		helpText = "Navigate: ↑/↓ or j/k • Install: Enter/Space • View scripts: v • Installed files: f • Skip in Install Everything: s • Back: esc/b • Quit: q or Ctrl+C"
 	} else {
 		helpText = "Navigate: ↑/↓ or j/k • Select: Enter/Space • Back: esc/b • Quit: q or Ctrl+C"
 	}
@@ -126,6 +206,11 @@ func (m MenuModel) getHelpText() string {
 
 // View renders the UI with enhanced styling
 func (m MenuModel) View() string {
+	// Plain status view after repeated UI stalls
+	if m.minimalStatus {
+		return m.renderMinimalStatus()
+	}
+	
 	// Handle authentication screen
 	if m.currentMenu == GitHubAuthMenu && m.authModel != nil {
 		return m.renderAuthScreen()
@@ -141,6 +226,11 @@ func (m MenuModel) View() string {
 		return m.renderInstallationScreen()
 	}
 	
+	// Command palette over the current screen
+	if m.paletteOpen {
+		return m.renderPalette()
+	}
+	
 	// Handle showing results
 	if m.showingResults {
 		return m.renderResultsScreen()
@@ -168,6 +258,22 @@ func (m MenuModel) View() string {
 		s.WriteString("\n")
 		s.WriteString(errorStyle.Render(m.authError))
 	}
+	if m.sessionNotice != "" && m.currentMenu == InstallEverythingMenu {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(m.sessionNotice))
+	}
+	if m.planNotice != "" && m.currentMenu == InstallEverythingMenu {
+		style := successStyle
+		if strings.HasPrefix(m.planNotice, "❌") {
+			style = errorStyle
+		}
+		s.WriteString("\n")
+		s.WriteString(style.Render(m.planNotice))
+	}
+	if m.startupWarning != "" && m.currentMenu == MainMenu {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(m.startupWarning))
+	}
 	
 	return baseStyle.Render(s.String())
 }
@@ -223,6 +329,10 @@ func (m MenuModel) renderLoadingScreen() string {
 	
 	s.WriteString(loadingStyle.Render(loadingMsg))
 	s.WriteString("\n")
+	if waiting := m.waitingLine(); waiting != "" {
+		s.WriteString(helpStyle.Render(waiting))
+		s.WriteString("\n")
+	}
 	
 	// Loading help text
 	loadingHelp := "Please wait while we fetch the latest configuration..."
@@ -263,6 +373,11 @@ func (m MenuModel) renderInstallationScreen() string {
 		}
 	}
 	
+	if waiting := m.waitingLine(); waiting != "" {
+		s.WriteString(helpStyle.Render(waiting))
+		s.WriteString("\n")
+	}
+	
 	// Installation help text
 	installHelp := "Installation is running... Press 'q' to cancel (may leave partial installations)"
 	s.WriteString(helpStyle.Render(installHelp))
@@ -280,47 +395,60 @@ func (m MenuModel) renderResultsScreen() string {
 	
 	// Results title
 	resultsTitle := "📋 Operation Results"
+	if m.resultsTitle != "" {
+		resultsTitle = m.resultsTitle
+	}
 	s.WriteString(titleStyle.Render(resultsTitle))
 	s.WriteString("\n\n")
 	
 	// Show results
 	if len(m.installationResults) > 0 {
-		for _, result := range m.installationResults {
-			var resultStyle lipgloss.Style
-			var icon string
-			
-			if result.Success {
-				resultStyle = successStyle
-				icon = "✅"
-			} else {
-				resultStyle = errorStyle
-				icon = "❌"
-			}
-			
-			resultText := fmt.Sprintf("%s %s", icon, result.ToolName)
-			s.WriteString(resultStyle.Render(resultText))
-			s.WriteString("\n")
-			
-			// Show detailed message
-			if result.Message != "" {
-				messageLines := strings.Split(result.Message, "\n")
-				for _, line := range messageLines {
-					if strings.TrimSpace(line) != "" {
-						s.WriteString(fmt.Sprintf("   %s\n", line))
-					}
-				}
-			}
-			s.WriteString("\n")
-		}
+		s.WriteString(m.renderResults())
 	}
 	
+	// Required follow-up actions (reboot, re-login, new shell)
+	s.WriteString(m.renderFollowUpActions())
+	
 	// Instructions
 	instructionText := "Press any key to return to the main menu"
+	if len(groupResults(m.installationResults, false)) > 1 {
+		instructionText = "1-9: expand/collapse section • f: failures first • any other key: return to the main menu"
+	} else if len(m.installationResults) > 1 {
+		instructionText = "f: failures first • any other key: return to the main menu"
+	}
 	s.WriteString(helpStyle.Render(instructionText))
 	
 	return baseStyle.Render(s.String())
 }
 
+// renderFollowUpActions lists follow-up actions requested by scripts during the last run
+func (m MenuModel) renderFollowUpActions() string {
+	if m.installEngine == nil {
+		return ""
+	}
+	
+	followUps := m.installEngine.PendingFollowUps()
+	if len(followUps) == 0 {
+		return ""
+	}
+	
+	var s strings.Builder
+	warningTitleStyle := lipgloss.NewStyle().Foreground(warningColor).Bold(true)
+	s.WriteString(warningTitleStyle.Render("⚠️  Required follow-up actions:"))
+	s.WriteString("\n")
+	
+	for _, action := range installer.FollowUpActionOrder {
+		sources, exists := followUps[action]
+		if !exists {
+			continue
+		}
+		s.WriteString(fmt.Sprintf("   • %s (requested by: %s)\n", action.Description(), strings.Join(sources, ", ")))
+	}
+	s.WriteString("\n")
+	
+	return s.String()
+}
+
 // renderAuthScreen shows authentication screen with enhanced styling
 func (m MenuModel) renderAuthScreen() string {
 	var s strings.Builder
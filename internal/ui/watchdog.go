@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	heartbeatInterval       = time.Second      // How often the UI loop reports that it is alive
+	watchdogStallThreshold  = 15 * time.Second // How long the UI may go without a heartbeat during background work
+	watchdogMaxRepaintTries = 1                // Repaint attempts before dropping to the minimal status view
+)
+
+// heartbeatMsg is delivered to Update on every heartbeat tick
+type heartbeatMsg time.Time
+
+// watchdogRecoverMsg asks the UI to recover from a stall
+type watchdogRecoverMsg struct {
+	Stalled time.Duration
+}
+
+// Watchdog detects when the UI stops processing messages during background operations
+// (for example while a long git clone keeps the Windows console busy) and asks it to recover
+type Watchdog struct {
+	mu         sync.Mutex
+	lastBeat   time.Time
+	busy       bool
+	recovering bool
+	stallCount int
+	threshold  time.Duration
+}
+
+// NewWatchdog creates a watchdog with the default stall threshold
+func NewWatchdog() *Watchdog {
+	return &Watchdog{
+		lastBeat:  time.Now(),
+		threshold: watchdogStallThreshold,
+	}
+}
+
+// heartbeat schedules the next heartbeat tick
+func heartbeat() tea.Cmd {
+	return tea.Tick(heartbeatInterval, func(t time.Time) tea.Msg {
+		return heartbeatMsg(t)
+	})
+}
+
+// Beat records that the UI loop is alive and whether background work is running
+func (w *Watchdog) Beat(busy bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastBeat = time.Now()
+	w.busy = busy
+	w.recovering = false
+	if !busy {
+		w.stallCount = 0
+	}
+}
+
+// checkStall reports how long the UI has been stalled, or zero if it is healthy.
+// A stall is only reported once until the UI beats again.
+func (w *Watchdog) checkStall(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stalled := now.Sub(w.lastBeat)
+	if !w.busy || w.recovering || stalled < w.threshold {
+		return 0
+	}
+	w.recovering = true
+	w.stallCount++
+	return stalled
+}
+
+// StallCount returns the number of stalls detected during the current background operation
+func (w *Watchdog) StallCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stallCount
+}
+
+// Start monitors the program until the returned stop function is called
+func (w *Watchdog) Start(p *tea.Program) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if stalled := w.checkStall(now); stalled > 0 {
+					// Send blocks until the UI loop reads it, so never block the watchdog itself
+					go p.Send(watchdogRecoverMsg{Stalled: stalled})
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// handleWatchdogMsg processes heartbeat and recovery messages
+func (m MenuModel) handleWatchdogMsg(msg tea.Msg) (MenuModel, tea.Cmd, bool) {
+	switch msg.(type) {
+	case heartbeatMsg:
+		busy := m.isLoading || m.installationInProgress ||
+			(m.currentMenu == GitHubAuthMenu && m.authModel != nil && m.authModel.IsValidating())
+		if m.watchdog != nil {
+			m.watchdog.Beat(busy)
+		}
+		// Leave the minimal status view once the background work is over
+		if !busy && m.minimalStatus {
+			m.minimalStatus = false
+			return m, tea.Batch(tea.EnterAltScreen, heartbeat()), true
+		}
+		return m, heartbeat(), true
+	case watchdogRecoverMsg:
+		if m.watchdog != nil && m.watchdog.StallCount() > watchdogMaxRepaintTries {
+			// Repainting did not help: drop to a plain status view on the normal screen,
+			// which keeps working on consoles where the alternate screen misbehaves
+			m.minimalStatus = true
+			return m, tea.Batch(tea.ExitAltScreen, tea.ClearScreen), true
+		}
+		return m, tea.ClearScreen, true
+	}
+	return m, nil, false
+}
+
+// renderMinimalStatus renders an unstyled single-screen status used after repeated UI stalls
+func (m MenuModel) renderMinimalStatus() string {
+	status := m.loadingMessage
+	if status == "" {
+		status = "Working..."
+	}
+	if m.currentMenu == GitHubAuthMenu {
+		status = "Validating credentials and cloning repository..."
+	}
+	return "BOBA - " + status + "\n(The display was unresponsive; showing minimal status. Press ctrl+c to quit.)\n"
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchdogStallDetection(t *testing.T) {
+	w := NewWatchdog()
+	now := time.Now()
+	
+	// Idle UI is never reported as stalled
+	w.Beat(false)
+	if stalled := w.checkStall(now.Add(time.Minute)); stalled != 0 {
+		t.Errorf("Expected no stall while idle, got %v", stalled)
+	}
+	
+	// Busy UI within the threshold is healthy
+	w.Beat(true)
+	if stalled := w.checkStall(time.Now().Add(w.threshold / 2)); stalled != 0 {
+		t.Errorf("Expected no stall within threshold, got %v", stalled)
+	}
+	
+	// Busy UI past the threshold is reported once until it beats again
+	if stalled := w.checkStall(time.Now().Add(2 * w.threshold)); stalled == 0 {
+		t.Error("Expected stall past threshold")
+	}
+	if stalled := w.checkStall(time.Now().Add(3 * w.threshold)); stalled != 0 {
+		t.Error("Expected stall to be reported only once")
+	}
+	if w.StallCount() != 1 {
+		t.Errorf("Expected stall count 1, got %d", w.StallCount())
+	}
+	
+	w.Beat(true)
+	if stalled := w.checkStall(time.Now().Add(2 * w.threshold)); stalled == 0 {
+		t.Error("Expected new stall after recovering")
+	}
+	
+	// Finishing background work resets the stall count
+	w.Beat(false)
+	if w.StallCount() != 0 {
+		t.Errorf("Expected stall count reset, got %d", w.StallCount())
+	}
+}
+
+func TestWatchdogRecovery(t *testing.T) {
+	model := MenuModel{
+		isLoading:         true,
+		loadingMessage:    "Cloning repository...",
+		toolInstallStatus: make(map[string]bool),
+		watchdog:          NewWatchdog(),
+	}
+	
+	// Heartbeats keep ticking
+	updated, cmd := model.Update(heartbeatMsg(time.Now()))
+	model = updated.(MenuModel)
+	if cmd == nil {
+		t.Fatal("Expected heartbeat to schedule the next tick")
+	}
+	
+	// First stall only repaints
+	model.watchdog.checkStall(time.Now().Add(2 * model.watchdog.threshold))
+	updated, cmd = model.Update(watchdogRecoverMsg{Stalled: time.Minute})
+	model = updated.(MenuModel)
+	if cmd == nil || model.minimalStatus {
+		t.Error("Expected first stall to trigger a repaint without minimal status")
+	}
+	
+	// Repeated stalls drop to the minimal status view
+	model.watchdog.recovering = false
+	model.watchdog.checkStall(time.Now().Add(2 * model.watchdog.threshold))
+	updated, _ = model.Update(watchdogRecoverMsg{Stalled: time.Minute})
+	model = updated.(MenuModel)
+	if !model.minimalStatus {
+		t.Fatal("Expected repeated stalls to enable minimal status")
+	}
+	if view := model.View(); !strings.Contains(view, "Cloning repository...") {
+		t.Errorf("Expected minimal status to show the loading message, got: %s", view)
+	}
+	
+	// Leaving background work restores the full UI
+	model.isLoading = false
+	updated, _ = model.Update(heartbeatMsg(time.Now()))
+	model = updated.(MenuModel)
+	if model.minimalStatus {
+		t.Error("Expected minimal status to end after background work finishes")
+	}
+}
@@ -0,0 +1,78 @@
+// Package version holds the version of the running BOBA binary and compares release versions
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Build information, set at build time with
+// -ldflags "-X boba/internal/version.Version=v1.2.3 -X boba/internal/version.BuildTime=... -X boba/internal/version.GitCommit=..."
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
+// Parse parses a release version such as "v1.2.3", "1.2" or a git describe output
+// such as "v1.2.3-4-gabcdef-dirty" into its major, minor and patch numbers
+func Parse(v string) ([3]int, error) {
+	var parts [3]int
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	fields := strings.Split(s, ".")
+	if s == "" || len(fields) > 3 {
+		return parts, fmt.Errorf("invalid version '%s'", v)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version '%s'", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// Compare returns -1, 0 or 1 when release version a is older than, equal to or newer than b
+func Compare(a, b string) (int, error) {
+	pa, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// IsRelease reports whether the running binary was built from a release version.
+// Development builds ("dev", bare commit hashes) satisfy every version requirement.
+func IsRelease() bool {
+	_, err := Parse(Version)
+	return err == nil
+}
+
+// Satisfies reports whether the running binary is at least the required release version
+func Satisfies(required string) (bool, error) {
+	if _, err := Parse(required); err != nil {
+		return false, err
+	}
+	if !IsRelease() {
+		return true, nil
+	}
+	cmp, err := Compare(Version, required)
+	return cmp >= 0, err
+}
@@ -0,0 +1,49 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v1.2.3-4-gabcdef-dirty", "1.2.4", -1},
+		{"2", "1.99", 1},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	
+	for _, invalid := range []string{"", "dev", "abc1234", "1.2.3.4", "1.x"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	original := Version
+	defer func() { Version = original }()
+	
+	Version = "v1.4.0"
+	if ok, _ := Satisfies("1.3"); !ok {
+		t.Error("Expected v1.4.0 to satisfy 1.3")
+	}
+	if ok, _ := Satisfies("1.5.0"); ok {
+		t.Error("Expected v1.4.0 not to satisfy 1.5.0")
+	}
+	if _, err := Satisfies("latest"); err == nil {
+		t.Error("Expected an invalid requirement to fail")
+	}
+	
+	Version = "dev"
+	if ok, _ := Satisfies("99.0"); !ok {
+		t.Error("Expected development builds to satisfy every requirement")
+	}
+}
@@ -1,16 +1,161 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	
+	"boba/internal/bootstrap"
+	"boba/internal/ci"
+	"boba/internal/cli"
+	"boba/internal/config"
+	"boba/internal/doctor"
+	"boba/internal/exitcode"
+	"boba/internal/installer"
+	"boba/internal/log"
+	"boba/internal/preview"
+	"boba/internal/sbom"
+	"boba/internal/scaffold"
 	"boba/internal/ui"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs BOBA and returns the exit code, so that an ephemeral session is removed on every exit
+func run() int {
+	// Global flags: boba [--ephemeral] [--dry-run] [--verbose | --quiet] [command]
+	consoleLevel := log.LevelWarn
+	for len(os.Args) > 1 && (os.Args[1] == "--ephemeral" || os.Args[1] == "--dry-run" || os.Args[1] == "--verbose" || os.Args[1] == "--quiet") {
+		name := os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		switch name {
+		case "--verbose":
+			// Every log record on the console, not just the warnings
+			consoleLevel = log.LevelDebug
+			continue
+		case "--quiet":
+			consoleLevel = log.LevelError
+			continue
+		case "--dry-run":
+			// Dry run: scripts are downloaded and described in order, nothing is executed
+			os.Setenv(installer.DryRunEnv, "1")
+			continue
+		}
+		
+		// Ephemeral session: all state is kept in a temporary directory
+		dir, err := config.StartEphemeral()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.Failure
+		}
+		defer config.EndEphemeral(dir)
+	}
+	
+	// Logs go to ~/.boba/logs (the ephemeral directory of an ephemeral session) and, from the
+	// console level on, to stderr
+	if err := log.Init(config.NewConfigManager().GetLogsDir(), consoleLevel); err != nil {
+		log.Warn("Logging to the console only", "error", err)
+	}
+	defer log.Close()
+	
+	// Author preview: boba preview [--run | --container image] <tools/name>
+	if len(os.Args) > 1 && os.Args[1] == "preview" {
+		return preview.Run(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	
+	// Software bill of materials: boba sbom [--format cyclonedx|spdx] [--output file]
+	if len(os.Args) > 1 && os.Args[1] == "sbom" {
+		return sbom.Run(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	
+	// Repository scaffolding: boba init [--tools a,b] [--envs a,b] [--create] [dir], boba new tool <name>
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		return scaffold.Run(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "new" {
+		return scaffold.RunNew(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	
+	// Diagnostics: boba doctor
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		return doctor.Run(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	
+	// Non-interactive commands: boba install <tool>..., boba uninstall <tool>..., boba list [--json], boba env apply|restore <environment>..., boba env resolve <file> <environment|merge>, boba env show [--json],
+	// boba plan export|keygen|approve|verify, boba sync, boba status [--json], boba metrics [--json], boba history [--sort installed|updated|name], boba search <query>,
+	// boba reset credentials|caches|overrides|everything --yes, boba validate [--json] [dir], boba self-update [--check] [--force],
+	// boba migrate export|import, boba maintain [--force] [--json], boba apply [--junit file] <plan file>
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "apply":
+			return cli.Apply(os.Args[2:], os.Stdout, os.Stderr)
+		case "install":
+			return cli.Install(os.Args[2:], os.Stdout, os.Stderr)
+		case "list":
+			return cli.List(os.Args[2:], os.Stdout, os.Stderr)
+		case "env":
+			return cli.Env(os.Args[2:], os.Stdout, os.Stderr)
+		case "plan":
+			return cli.Plan(os.Args[2:], os.Stdout, os.Stderr)
+		case "uninstall":
+			return cli.Uninstall(os.Args[2:], os.Stdout, os.Stderr)
+		case "sync":
+			return cli.Sync(os.Args[2:], os.Stdout, os.Stderr)
+		case "status":
+			return cli.Status(os.Args[2:], os.Stdout, os.Stderr)
+		case "metrics":
+			return cli.Metrics(os.Args[2:], os.Stdout, os.Stderr)
+		case "history":
+			return cli.History(os.Args[2:], os.Stdout, os.Stderr)
+		case "search":
+			return cli.Search(os.Args[2:], os.Stdout, os.Stderr)
+		case "reset":
+			return cli.Reset(os.Args[2:], os.Stdout, os.Stderr)
+		case "validate":
+			return cli.Validate(os.Args[2:], os.Stdout, os.Stderr)
+		case "self-update":
+			return cli.SelfUpdate(os.Args[2:], os.Stdout, os.Stderr)
+		case "migrate":
+			return cli.Migrate(os.Args[2:], os.Stdout, os.Stderr)
+		case "maintain":
+			return cli.Maintain(os.Args[2:], os.Stdout, os.Stderr)
+		}
+	}
+	
+	// Automatic mode, e.g. in a Dockerfile: install the profile from the environment, print JSON and exit
+	info, err := os.Stdin.Stat()
+	terminal := err == nil && info.Mode()&os.ModeCharDevice != 0
+	if len(os.Args) == 1 && cli.AutoMode(terminal) {
+		return cli.Auto(os.Stdout, os.Stderr)
+	}
+	
+	junit := flag.String("junit", "", "write the results of each run as JUnit XML to this `file`")
+	flag.Parse()
+	
+	// CI has no one at the terminal, even when it allocates one: the UI and prompts would wait forever
+	if provider := ci.Detect(); provider != nil {
+		fmt.Fprintf(os.Stderr, "Error: the terminal UI can't run in %s: use boba install, boba env apply or BOBA_AUTO=1\n", provider.Name)
+		return exitcode.Usage
+	}
+	
+	// Missing git, curl or bash: offer to install them while sudo can still prompt on the terminal
+	if terminal && !installer.DefaultOptions().DryRun {
+		bootstrap.Offer(os.Stdin, os.Stdout, os.Stderr)
+	}
+	
+	// The UI owns the screen: records only go to the log file
+	log.SetConsole(nil, consoleLevel)
 	uiManager := ui.NewUIManager()
-	if err := uiManager.Start(); err != nil {
+	uiManager.JUnitReportPath = *junit
+	if err := uiManager.Start(); errors.Is(err, ui.ErrNotTerminal) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.Usage
+	} else if err != nil {
 		fmt.Printf("Error running application: %v\n", err)
-		os.Exit(1)
+		return exitcode.Failure
 	}
+	return exitcode.OK
 }
\ No newline at end of file